@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/apidoc"
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/generator"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+	"github.com/thdev01/wsdl2api/pkg/typescript"
+)
+
+// Flags of the export command
+var (
+	exportFormat string
+	exportOutput string
+	exportAsync  bool
+	openapiOut   string
+	specVersion  string
+	tsFlavor     string
+	tsZod        bool
+	generateTS   bool
+	tsOutputDir  string
+	apiTitle     string
+	apiVersion   string
+	serverURLs   []string
+	securityOpts []string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export WSDL to OpenAPI/Swagger specification or GraphQL schema",
+	Long:  `Parse WSDL and export as OpenAPI 3.0/3.1 or Swagger 2.0 specification, or as GraphQL schema`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
+		if err := validateDecimalType(); err != nil {
+			return err
+		}
+		if err := validateTSFlavor(); err != nil {
+			return err
+		}
+		format, specName, err := exportFormats()
+		if err != nil {
+			return err
+		}
+		targets, err := exportTargets(specName, format)
+		if err != nil {
+			return err
+		}
+		schemes, err := securitySchemes()
+		if err != nil {
+			return err
+		}
+
+		slog.Info("parsing WSDL", "path", wsdlPath)
+
+		// Parse WSDL
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+		if definitions, err = selectService(definitions, slog.Default()); err != nil {
+			return err
+		}
+
+		routeCfg, err := routeConfig()
+		if err != nil {
+			return err
+		}
+		if err := routeCfg.Check(definitions); err != nil {
+			return fmt.Errorf("invalid routes: %w", err)
+		}
+
+		slog.Info("converting to OpenAPI", "spec_version", specVersion)
+
+		// Convert to OpenAPI
+		spec, err := exporter.ConvertWSDLToOpenAPIWithRoutes(definitions, routeCfg)
+		if err != nil {
+			return fmt.Errorf("failed to convert to OpenAPI: %w", err)
+		}
+		if decimalType != generator.DecimalTypeFloat {
+			spec.DecimalsAsStrings()
+		}
+		customizeSpec(spec, schemes, definitions)
+		logRenames(slog.Default(), naming.Operations(definitions).Renames())
+
+		// Render every document before writing any, so that a failure
+		// leaves no partial output
+		output, err := renderSpec(definitions, spec, format)
+		if err != nil {
+			return fmt.Errorf("failed to export: %w", err)
+		}
+		var asyncOutput string
+		if exportAsync {
+			async := exporter.ConvertWSDLToAsyncAPI(definitions)
+			if decimalType != generator.DecimalTypeFloat {
+				async.DecimalsAsStrings()
+			}
+			if len(async.Channels) == 0 {
+				slog.Warn("no one-way, notification or solicit-response operations to export as AsyncAPI")
+			}
+			if format == "yaml" || format == "yml" {
+				asyncOutput, err = async.ExportToYAML()
+			} else {
+				asyncOutput, err = async.ExportToJSON()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to export AsyncAPI: %w", err)
+			}
+		}
+
+		// Write to file or stdout
+		if targets.spec == "" {
+			fmt.Println(output)
+		} else {
+			if err := writeExport(targets.spec, output); err != nil {
+				return err
+			}
+			slog.Info("spec exported", "file", targets.spec)
+		}
+
+		// Generate TypeScript client if requested, from the spec in memory
+		if targets.typescript != "" {
+			slog.Info("generating TypeScript client", "output", targets.typescript)
+			tsGen := typescript.NewGenerator(targets.typescript, spec)
+			tsGen.SetFlavor(tsFlavor)
+			tsGen.SetZod(tsZod)
+			if err := tsGen.Generate(); err != nil {
+				return fmt.Errorf("failed to generate TypeScript client: %w", err)
+			}
+			slog.Info("TypeScript client generated", "output", targets.typescript)
+		}
+
+		// Export messaging operations as AsyncAPI if requested
+		if targets.asyncapi != "" {
+			if err := writeExport(targets.asyncapi, asyncOutput); err != nil {
+				return err
+			}
+			slog.Info("AsyncAPI spec exported", "file", targets.asyncapi)
+		}
+
+		return nil
+	},
+}
+
+// validateTSFlavor checks the --ts-flavor flag
+func validateTSFlavor() error {
+	switch tsFlavor {
+	case typescript.FlavorFetch, typescript.FlavorAxios, typescript.FlavorReactQuery:
+		return nil
+	default:
+		return fmt.Errorf("unsupported TypeScript flavor: %s (use %s, %s or %s)", tsFlavor,
+			typescript.FlavorFetch, typescript.FlavorAxios, typescript.FlavorReactQuery)
+	}
+}
+
+// exportFormats checks --format and --spec-version, returning the format
+// in lower case, with yml as a spelling of yaml, and the name of the spec
+// file
+func exportFormats() (format, specName string, err error) {
+	format = strings.ToLower(exportFormat)
+	switch format {
+	case "json", "yaml", "yml":
+	case "graphql":
+		return format, "schema", nil
+	case "html":
+		return format, "index", nil
+	default:
+		return "", "", fmt.Errorf("unsupported export format: %s (use json, yaml, graphql or html)", exportFormat)
+	}
+	switch specVersion {
+	case "2.0":
+		return format, "swagger", nil
+	case "3.0", "3.1":
+		return format, "openapi", nil
+	default:
+		return "", "", fmt.Errorf("unsupported spec version: %s (use 2.0, 3.0 or 3.1)", specVersion)
+	}
+}
+
+// exportPaths are the destinations of export: the spec file, empty for
+// stdout, and the TypeScript directory and AsyncAPI file, empty when not
+// requested
+type exportPaths struct {
+	spec       string
+	typescript string
+	asyncapi   string
+}
+
+// exportTargets resolves the destinations of export. The spec goes to
+// --openapi-out, - being stdout, or else to <output>/<specName>.<format>,
+// or stdout without --output. The TypeScript client goes to --ts-output and
+// the AsyncAPI spec next to the spec, both defaulting to the directory of
+// --output or else of --openapi-out; requesting them with neither is an
+// error rather than a write to the working directory.
+func exportTargets(specName, format string) (exportPaths, error) {
+	var paths exportPaths
+	dir := exportOutput
+	if dir == "-" {
+		dir = ""
+	}
+	switch {
+	case openapiOut == "-":
+	case openapiOut != "":
+		paths.spec = openapiOut
+		if dir == "" {
+			dir = filepath.Dir(openapiOut)
+		}
+	case dir != "":
+		paths.spec = filepath.Join(dir, specName+"."+format)
+	}
+
+	if generateTS {
+		paths.typescript = tsOutputDir
+		if paths.typescript == "" {
+			if dir == "" {
+				return paths, fmt.Errorf("--typescript with the spec on stdout needs --ts-output, or --output for its default directory")
+			}
+			paths.typescript = filepath.Join(dir, "typescript")
+		}
+	}
+	if exportAsync {
+		if dir == "" {
+			return paths, fmt.Errorf("--asyncapi with the spec on stdout needs --output or --openapi-out for its directory")
+		}
+		ext := "json"
+		if format == "yaml" || format == "yml" {
+			ext = format
+		}
+		paths.asyncapi = filepath.Join(dir, "asyncapi."+ext)
+	}
+	return paths, nil
+}
+
+// renderSpec renders spec, exported from def, in format, as the
+// --spec-version chosen. The html format documents the REST proxy at the
+// first --server-url, for the generated Go client of --package.
+func renderSpec(def *models.Definitions, spec *exporter.OpenAPISpec, format string) (string, error) {
+	yaml := format == "yaml" || format == "yml"
+	switch {
+	case format == "graphql":
+		return exporter.ConvertOpenAPIToGraphQL(spec).ExportToSDL(), nil
+	case format == "html":
+		opts := apidoc.Options{Package: packageName}
+		if len(serverURLs) > 0 {
+			opts.BaseURL = serverURLs[0]
+		}
+		return apidoc.New(def, spec, opts).HTML()
+	case specVersion == "2.0":
+		swagger := exporter.ConvertOpenAPIToSwagger(spec)
+		if yaml {
+			return swagger.ExportToYAML()
+		}
+		return swagger.ExportToJSON()
+	case specVersion == "3.1":
+		spec31 := exporter.ConvertOpenAPIToV31(spec)
+		if yaml {
+			return spec31.ExportToYAML()
+		}
+		return spec31.ExportToJSON()
+	default:
+		if yaml {
+			return spec.ExportToYAML()
+		}
+		return spec.ExportToJSON()
+	}
+}
+
+// writeExport writes an exported document, creating its directory
+func writeExport(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// securitySchemes parses the --security schemes
+func securitySchemes() ([]exporter.SecurityScheme, error) {
+	schemes := make([]exporter.SecurityScheme, 0, len(securityOpts))
+	for _, opt := range securityOpts {
+		scheme, err := exporter.ParseSecurityScheme(opt)
+		if err != nil {
+			return nil, err
+		}
+		schemes = append(schemes, scheme)
+	}
+	return schemes, nil
+}
+
+// customizeSpec applies --title, --api-version, --server-url and
+// --security to an exported spec
+func customizeSpec(spec *exporter.OpenAPISpec, schemes []exporter.SecurityScheme, def *models.Definitions) {
+	if apiTitle != "" {
+		spec.Info.Title = apiTitle
+	}
+	if apiVersion != "" {
+		spec.Info.Version = apiVersion
+	}
+	if len(serverURLs) > 0 {
+		spec.Servers = nil
+		for _, u := range serverURLs {
+			spec.Servers = append(spec.Servers, exporter.OpenAPIServer{URL: u, Description: "wsdl2api REST proxy"})
+		}
+	}
+	for _, scheme := range schemes {
+		for _, pattern := range scheme.Operations.Unused(def) {
+			slog.Warn("security operation pattern matches no operation", "pattern", pattern)
+		}
+	}
+	spec.AddSecurity(schemes)
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output directory (empty or - for the spec on stdout)")
+	exportCmd.Flags().StringVar(&openapiOut, "openapi-out", "", "File to write the spec to, - for stdout (default: <output>/<openapi|swagger|schema>.<format>)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "Export format (json, yaml/yml, graphql, or html for a documentation page)")
+	exportCmd.Flags().StringVarP(&packageName, "package", "p", "client", "Go package of the generated client in the examples of --format html")
+	exportCmd.Flags().StringVar(&wsdlService, "service", "", "WSDL service to export (default: all)")
+	exportCmd.Flags().StringVar(&wsdlPort, "wsdl-port", "", "WSDL port to export, with its binding's operations and address")
+	exportCmd.Flags().StringVar(&wsdlPort, "port", "", "WSDL port to export")
+	exportCmd.Flags().MarkDeprecated("port", "use --wsdl-port instead")
+	exportCmd.Flags().StringSliceVar(&includeOps, "include-ops", nil, "Operations to keep, comma-separated globs such as Get* or regular expressions such as /^(Get|List)/ (default all)")
+	exportCmd.Flags().StringSliceVar(&excludeOps, "exclude-ops", nil, "Operations to drop, as globs or /regular expressions/, applied after --include-ops")
+	exportCmd.Flags().StringVar(&specVersion, "spec-version", "3.0", "Specification version (3.0 or 3.1 for OpenAPI, 2.0 for Swagger)")
+	exportCmd.Flags().StringVar(&decimalType, "decimal-type", generator.DecimalTypeFloat, "Decimal type used by generated clients; anything but float64 exports xs:decimal values as strings")
+	exportCmd.Flags().BoolVar(&generateTS, "typescript", false, "Generate TypeScript client")
+	exportCmd.Flags().StringVar(&tsOutputDir, "ts-output", "", "TypeScript output directory (default: typescript in --output, or next to --openapi-out)")
+	exportCmd.Flags().StringVar(&tsFlavor, "ts-flavor", typescript.FlavorFetch, "TypeScript client flavor (fetch, axios or react-query)")
+	exportCmd.Flags().BoolVar(&tsZod, "ts-zod", false, "Emit zod schemas and validate responses at runtime in the TypeScript client")
+	exportCmd.Flags().StringVar(&routesFile, "routes", "", "YAML file overriding the REST method and path of operations")
+	exportCmd.Flags().BoolVar(&restVerbs, "rest-verbs", false, "Derive REST methods from operation names: Get*, List*, Find* and Search* use GET, Delete* and Remove* DELETE")
+	exportCmd.Flags().BoolVar(&exportAsync, "asyncapi", false, "Also export one-way, notification and solicit-response operations as AsyncAPI 2.6 (asyncapi.json)")
+	exportCmd.Flags().StringVar(&apiTitle, "title", "", "API title (default: the WSDL name)")
+	exportCmd.Flags().StringVar(&apiVersion, "api-version", "", "API version (default 1.0.0)")
+	exportCmd.Flags().StringSliceVar(&serverURLs, "server-url", nil, "Public URL of the REST proxy, replacing the SOAP addresses as servers (repeatable)")
+	exportCmd.Flags().StringArrayVar(&securityOpts, "security", nil, "Security scheme: basic, bearer[:format] or apikey:[header|query|cookie:]name, optionally =Op*,... to require it for those operations only (repeatable)")
+	_ = exportCmd.MarkFlagRequired("wsdl")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// chdir changes the working directory to dir for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestExportStdout(t *testing.T) {
+	wsdl, err := filepath.Abs(calculatorWSDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, t.TempDir())
+
+	// Without --output the spec goes to stdout, whatever the defaults of
+	// the flags of other commands
+	out, err := run(t, "export", "--wsdl", wsdl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &spec); err != nil || spec["openapi"] == nil {
+		t.Errorf("export printed %.200s, want the spec: %v", out, err)
+	}
+	if files, _ := os.ReadDir("."); len(files) != 0 {
+		t.Errorf("export wrote %v to the working directory", files)
+	}
+}
+
+func TestExportStdoutTypeScript(t *testing.T) {
+	wsdl, err := filepath.Abs(calculatorWSDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, work := t.TempDir(), t.TempDir()
+	chdir(t, work)
+
+	// The spec goes to stdout and the client to --ts-output
+	ts := filepath.Join(dir, "ts")
+	out, err := execute(t, "export", "--wsdl", wsdl, "--output", "-", "--typescript", "--ts-output", ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &spec); err != nil || spec["openapi"] == nil {
+		t.Errorf("export printed %.200s, want the spec alone: %v", out, err)
+	}
+	if files, _ := os.ReadDir(ts); len(files) == 0 {
+		t.Errorf("the TypeScript client wasn't written to %s", ts)
+	}
+
+	// Without a directory for the client nothing is written
+	out, err = execute(t, "export", "--wsdl", wsdl, "--output", "-", "--typescript")
+	if err == nil || out != "" {
+		t.Errorf("export --typescript to stdout without --ts-output = %q, %v, want an error", out, err)
+	}
+	if files, _ := os.ReadDir(work); len(files) != 0 {
+		t.Errorf("export wrote %v to the working directory", files)
+	}
+
+	// The client and the AsyncAPI spec default to the directory of
+	// --openapi-out, and yml is kept as the extension
+	specFile := filepath.Join(dir, "api", "calculator.yml")
+	if _, err := execute(t, "export", "--wsdl", wsdl, "--openapi-out", specFile, "--format", "yml", "--typescript", "--asyncapi"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{specFile, filepath.Join(dir, "api", "asyncapi.yml"), filepath.Join(dir, "api", "typescript")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestExportTypeScriptFlavor(t *testing.T) {
+	dir := t.TempDir()
+
+	// The flavor picks the HTTP client and adds its hooks
+	out := filepath.Join(dir, "react-query")
+	if _, err := execute(t, "export", "--wsdl", calculatorWSDL, "--output", filepath.Join(dir, "openapi.json"),
+		"--typescript", "--ts-output", out, "--ts-flavor", "react-query"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "hooks.ts")); err != nil {
+		t.Errorf("export --ts-flavor react-query didn't write the hooks: %v", err)
+	}
+
+	// Unknown flavors are rejected before anything is written
+	out = filepath.Join(dir, "jquery")
+	if _, err := execute(t, "export", "--wsdl", calculatorWSDL, "--output", filepath.Join(dir, "openapi.json"),
+		"--typescript", "--ts-output", out, "--ts-flavor", "jquery"); err == nil || !strings.Contains(err.Error(), "unsupported TypeScript flavor") {
+		t.Errorf("export --ts-flavor jquery = %v, want an unsupported flavor error", err)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Errorf("export --ts-flavor jquery wrote %s", out)
+	}
+}
+
+func TestExportTypeScriptZod(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "ts")
+	if _, err := execute(t, "export", "--wsdl", calculatorWSDL, "--output", filepath.Join(dir, "openapi.json"),
+		"--typescript", "--ts-output", out, "--ts-zod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "schemas.ts")); err != nil {
+		t.Errorf("export --ts-zod didn't write the schemas: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(out, "client.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(client), "Schemas.AddResponseSchema.parse(response)") {
+		t.Errorf("export --ts-zod client doesn't validate responses:\n%s", client)
+	}
+}
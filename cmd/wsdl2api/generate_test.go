@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/pkg/generator"
+)
+
+func TestGenerateWSDLDir(t *testing.T) {
+	dir := t.TempDir()
+	contracts, output := filepath.Join(dir, "contracts"), filepath.Join(dir, "out")
+	for name, src := range map[string]string{
+		"Calculator.wsdl":               calculatorWSDL,
+		"legacy/temperature.WSDL":       "../../examples/temperature.wsdl",
+		"legacy/number-conversion.wsdl": "../../examples/numberconversion.wsdl",
+	} {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(contracts, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(contracts, "README.md"), []byte("not a WSDL"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each WSDL is generated into a package named after it, under the
+	// directory of the WSDL
+	if _, err := execute(t, "generate", "--wsdl-dir", contracts, "--output", output, "--jobs", "2"); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"calculator/client.go", "legacy/temperature/client.go", "legacy/numberconversion/client.go"} {
+		data, err := os.ReadFile(filepath.Join(output, file))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if pkg := filepath.Base(filepath.Dir(file)); !strings.Contains(string(data), "package "+pkg+"\n") {
+			t.Errorf("%s isn't in package %s", file, pkg)
+		}
+	}
+
+	// The errors of the WSDLs that fail are reported together, after the
+	// others are generated
+	broken := filepath.Join(contracts, "broken.wsdl")
+	if err := os.WriteFile(broken, []byte("<definitions"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(output)
+	_, err := execute(t, "generate", "--wsdl-dir", contracts, "--output", output)
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 WSDLs failed") || !strings.Contains(err.Error(), broken) {
+		t.Errorf("generate of a broken WSDL = %v, want it reported", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "calculator", "client.go")); err != nil {
+		t.Errorf("the valid WSDLs weren't generated: %v", err)
+	}
+
+	if _, err := execute(t, "generate", "--wsdl-dir", filepath.Join(contracts, "legacy", "missing"), "--output", output); err == nil {
+		t.Error("generate of a missing directory succeeded")
+	}
+}
+
+// plugin answers a generator.PluginRequest on stdin with a file naming
+// the service, in a file named after the package
+func plugin() int {
+	var request generator.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	json.NewEncoder(os.Stdout).Encode(generator.PluginResponse{Files: []generator.PluginFile{
+		{Name: request.PackageName + ".txt", Content: request.Definitions.Name},
+	}})
+	return 0
+}
+
+func TestGeneratePlugin(t *testing.T) {
+	t.Setenv("WSDL2API_PLUGIN", "1")
+	output := t.TempDir()
+	if _, err := execute(t, "generate", "--wsdl", calculatorWSDL, "--output", output, "--package", "calc", "--plugin", os.Args[0]); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(output, "calc.txt")); err != nil || string(data) != "Calculator" {
+		t.Errorf("calc.txt = %q, %v, want the service of the WSDL", data, err)
+	}
+
+	if _, err := execute(t, "generate", "--wsdl", calculatorWSDL, "--output", output, "--plugin", "wsdl2api-missing-plugin"); err == nil {
+		t.Error("generate with a missing plugin succeeded")
+	}
+}
+
+func TestGenerateArtifacts(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "no example",
+			args: []string{"--no-example"},
+			want: []string{"client.go", "fake_client.go", "operators.go", "types.go"},
+		},
+		{
+			name: "artifacts",
+			args: []string{"--artifacts", "client,types"},
+			want: []string{"client.go", "types.go"},
+		},
+		{
+			name: "artifacts and mock",
+			args: []string{"--artifacts", "types", "--mock"},
+			want: []string{"mock_server.go", "types.go"},
+		},
+		{
+			name: "artifacts without example",
+			args: []string{"--artifacts", "types,example", "--no-example"},
+			want: []string{"types.go"},
+		},
+		{
+			name:    "unknown artifact",
+			args:    []string{"--artifacts", "types,docs"},
+			wantErr: `unknown artifact "docs"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := t.TempDir()
+			_, err := execute(t, append([]string{"generate", "--wsdl", calculatorWSDL, "--output", output}, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("generate %v = %v, want %s", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := os.ReadDir(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				if filepath.Ext(entry.Name()) == ".go" {
+					got = append(got, entry.Name())
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("generate %v wrote %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateWSDLPort(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(config, []byte("port: NumberConversionSoap12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: `soapVersion: "1.1"`},
+		{name: "wsdl-port", args: []string{"--wsdl-port", "NumberConversionSoap12"}, want: `soapVersion: "1.2"`},
+		{name: "deprecated port", args: []string{"--port", "NumberConversionSoap12"}, want: `soapVersion: "1.2"`},
+		// The port of serve in a shared config isn't a WSDL port
+		{name: "config port", args: []string{"--config", config}, want: `soapVersion: "1.1"`},
+		{name: "unknown port", args: []string{"--wsdl-port", "Missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := t.TempDir()
+			_, err := execute(t, append([]string{"generate", "--wsdl", "../../examples/numberconversion.wsdl", "--output", output}, tt.args...)...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("generate %v succeeded, want an error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := os.ReadFile(filepath.Join(output, "client.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(client), tt.want) {
+				t.Errorf("generate %v client lacks %s", tt.args, tt.want)
+			}
+		})
+	}
+
+	// export selects ports the same way
+	for _, flag := range []string{"--wsdl-port", "--port"} {
+		if _, err := execute(t, "export", "--wsdl", "../../examples/numberconversion.wsdl", "--output", dir, flag, "Missing"); err == nil {
+			t.Errorf("export %s Missing succeeded, want an error", flag)
+		}
+	}
+}
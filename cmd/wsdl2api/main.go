@@ -1,35 +1,162 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/addressing"
+	"github.com/thdev01/wsdl2api/pkg/apidoc"
+	"github.com/thdev01/wsdl2api/pkg/bench"
+	"github.com/thdev01/wsdl2api/pkg/bundle"
+	"github.com/thdev01/wsdl2api/pkg/console"
+	"github.com/thdev01/wsdl2api/pkg/contract"
+	"github.com/thdev01/wsdl2api/pkg/deploy"
+	"github.com/thdev01/wsdl2api/pkg/describe"
+	"github.com/thdev01/wsdl2api/pkg/diff"
+	"github.com/thdev01/wsdl2api/pkg/discovery"
 	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/filter"
 	"github.com/thdev01/wsdl2api/pkg/generator"
+	"github.com/thdev01/wsdl2api/pkg/naming"
 	"github.com/thdev01/wsdl2api/pkg/parser"
+	"github.com/thdev01/wsdl2api/pkg/recorder"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/script"
+	"github.com/thdev01/wsdl2api/pkg/security"
 	"github.com/thdev01/wsdl2api/pkg/server"
-	"github.com/thdev01/wsdl2api/pkg/typescript"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+	"github.com/thdev01/wsdl2api/pkg/validator"
 )
 
 var (
-	wsdlPath         string
-	outputDir        string
-	packageName      string
-	port             int
-	host             string
-	exportFormat     string
-	generateMock     bool
-	soapVersion      string
-	generateTS       bool
-	tsOutputDir      string
+	wsdlPath     string
+	wsdlPaths    []string
+	outputDir    string
+	packageName  string
+	port         int
+	host         string
+	generateMock bool
+	withTests    bool
+	noExample    bool
+	artifacts    []string
+	soapVersion  string
+	wsAddressing bool
+	genServer    bool
+	soapEndpoint string
+	tlsCert      string
+	tlsKey       string
+	configFile   string
+	backendAuth  string
+	backendUser  string
+	backendPass  string
+	backendTLS   security.TLSOptions
+	backendOAuth security.OAuth2Config
+	backendPool  = server.DefaultPool()
+	compression  string
+	breaker      server.Breaker
+	affinity     server.Affinity
+	batch        server.Batch
+	rateLimit    float64
+	rateBurst    int
+	routeRate    float64
+	routeBurst   int
+	maxInFlight  int
+	logLevel     string
+	logFormat    string
+	timeType     string
+	decimalType  string
+	typeMapFile  string
+	jsonNaming   string
+	splitOutput  bool
+	initModule   string
+	standalone   bool
+	stableOutput bool
+	goGenerate   bool
+	checkOutput  bool
+	wsdlDir      string
+	parallelism  int
+	verifyCode   bool
+	serveGraphQL bool
+	recordTarget string
+	recordDir    string
+	recordPort   int
+	replay       bool
+	strict       bool
+	failBreaking bool
+	descFormat   string
+	plugins      []string
+	routesFile   string
+	restVerbs    bool
+	noValidate   bool
+	mexURLs      []string
+	contractDir  string
+	listFormat   string
+	opName       string
+	inputData    string
+	outFormat    string
+	dumpDir      string
+	testOps      []string
+	includeOps   []string
+	excludeOps   []string
+	wsdlService  string
+	wsdlPort     string
+	junitReport  string
+	concurrency  int
+	benchTime    time.Duration
+	maxRequests  int
+	proxyURL     string
+	withDocker   bool
+	withK8s      bool
+	withDocs     bool
+	dockerDir    string
+	dockerImage  string
+	ingressHost  string
+	binaryPath   string
+	buildSource  string
+	buildDir     string
+
+	// WSDL fetch options shared by all commands
+	wsdlTimeout  time.Duration
+	wsdlAuthUser string
+	wsdlAuthPass string
+	wsdlHeaders  []string
+	wsdlCert     string
+	wsdlKey      string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "wsdl2api",
 	Short: "Convert WSDL to REST API",
 	Long:  `WSDL2API converts legacy SOAP/WSDL services into modern REST APIs`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		return setupLogger()
+	},
 }
 
 var generateCmd = &cobra.Command{
@@ -37,61 +164,417 @@ var generateCmd = &cobra.Command{
 	Short: "Generate Go client code from WSDL",
 	Long:  `Parse WSDL and generate complete Go client structures`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if wsdlPath == "" {
+		if wsdlPath == "" && wsdlDir == "" {
 			return fmt.Errorf("wsdl path is required")
 		}
+		if wsdlDir != "" && initModule != "" {
+			return fmt.Errorf("--init-module scaffolds the module of a single WSDL and doesn't apply to --wsdl-dir")
+		}
+		if timeType != generator.TimeTypeString && timeType != generator.TimeTypeTime {
+			return fmt.Errorf("unsupported time type: %s (use %s or %s)", timeType, generator.TimeTypeString, generator.TimeTypeTime)
+		}
+		if jsonNaming != generator.JSONNamingXML && jsonNaming != generator.JSONNamingCamel {
+			return fmt.Errorf("unsupported JSON naming: %s (use %s or %s)", jsonNaming, generator.JSONNamingXML, generator.JSONNamingCamel)
+		}
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+		if err := validateDecimalType(); err != nil {
+			return err
+		}
+		opts, err := generateOptions()
+		if err != nil {
+			return err
+		}
 
-		fmt.Printf("Parsing WSDL: %s\n", wsdlPath)
+		var mapping *generator.TypeMapping
+		if typeMapFile != "" {
+			if mapping, err = generator.LoadTypeMapping(typeMapFile); err != nil {
+				return err
+			}
+		}
 
-		// Parse WSDL
-		p := parser.NewParser()
-		definitions, err := p.Parse(wsdlPath)
+		if wsdlDir == "" {
+			if checkOutput {
+				// The outdated files are the output; don't repeat them
+				// as usage errors
+				cmd.SilenceUsage = true
+			}
+			return generateClient(cmd, generateJob{wsdl: wsdlPath, output: outputDir, pkg: packageName, log: slog.Default()}, opts, mapping)
+		}
+
+		jobs, err := wsdlDirJobs(wsdlDir, outputDir)
 		if err != nil {
-			return fmt.Errorf("failed to parse WSDL: %w", err)
+			return err
 		}
+		// The errors are the ones of the WSDLs from here on
+		cmd.SilenceUsage = true
+		return generateAll(cmd, jobs, opts, mapping)
+	},
+}
 
-		fmt.Printf("Found %d services\n", len(definitions.Services))
+// generateJob is a client the generate command writes
+type generateJob struct {
+	wsdl    string // WSDL path or URL
+	output  string // Output directory
+	pkg     string // Go package name
+	service string // Service of the WSDL split into a package of its own
+	log     *slog.Logger
+}
 
-		// Generate code
-		g := generator.NewGenerator(outputDir, packageName)
-		if generateMock {
-			if err := g.GenerateWithMock(definitions); err != nil {
-				return fmt.Errorf("failed to generate code: %w", err)
-			}
-		} else {
-			if err := g.Generate(definitions); err != nil {
-				return fmt.Errorf("failed to generate code: %w", err)
-			}
+// generateClient parses a WSDL and generates its client, or checks the
+// client with --check. The services of WSDLs whose services bind
+// different port types are generated into a package each, named after the
+// service under the output directory, unless --service or --wsdl-port selects
+// one.
+func generateClient(cmd *cobra.Command, job generateJob, opts generator.Options, mapping *generator.TypeMapping) error {
+	job.log.Info("parsing WSDL", "path", job.wsdl)
+
+	// Parse WSDL
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	definitions, err := p.Parse(job.wsdl)
+	if err != nil {
+		return fmt.Errorf("failed to parse WSDL: %w", err)
+	}
+	defs, err := selectServices(definitions, job.log)
+	if err != nil {
+		return err
+	}
+
+	job.log.Info("parsed WSDL", "services", len(definitions.Services))
+
+	if len(defs) == 1 {
+		return generateDefinitions(cmd, job, defs[0], opts, mapping)
+	}
+	if initModule != "" {
+		return fmt.Errorf("--init-module scaffolds a single package, and %s has %d services of different operations: select one with --service", job.wsdl, len(defs))
+	}
+	job.log.Info("generating a package per service", "services", len(defs))
+	for _, def := range defs {
+		pkg := goPackage(def.Name)
+		service := generateJob{
+			wsdl:    job.wsdl,
+			output:  filepath.Join(job.output, pkg),
+			pkg:     pkg,
+			service: def.Name,
+			log:     job.log.With("service", def.Name),
 		}
+		if err := generateDefinitions(cmd, service, def, opts, mapping); err != nil {
+			return fmt.Errorf("service %s: %w", def.Name, err)
+		}
+	}
+	return nil
+}
+
+// generateDefinitions generates the client of parsed definitions, or
+// checks it with --check
+func generateDefinitions(cmd *cobra.Command, job generateJob, definitions *models.Definitions, opts generator.Options, mapping *generator.TypeMapping) error {
+	if mapping != nil {
+		if err := mapping.Check(definitions); err != nil {
+			return err
+		}
+	}
+
+	// Generate code
+	g := generator.NewGenerator(job.output, job.pkg)
+	g.SetTimeType(timeType)
+	g.SetDecimalType(decimalType)
+	g.SetTypeMapping(mapping)
+	g.SetJSONNaming(jsonNaming)
+	g.SetSplit(splitOutput)
+	g.SetModule(initModule)
+	g.SetStandalone(standalone)
+	g.SetDeterministic(stableOutput)
+	if goGenerate {
+		command, err := goGenerateCommand(cmd, job)
+		if err != nil {
+			return err
+		}
+		g.SetGoGenerate(command)
+	}
+	g.SetSOAPVersion(soapVersion)
+
+	// Plugins are told the package of the client, so each client gets a
+	// registry of its own
+	registry := generator.NewRegistry()
+	for _, gen := range generator.DefaultRegistry.Generators() {
+		registry.Register(gen)
+	}
+	for _, commandLine := range plugins {
+		plugin, err := generator.NewPlugin(commandLine, job.pkg)
+		if err != nil {
+			return err
+		}
+		registry.Register(plugin)
+	}
+	g.SetRegistry(registry)
+	g.SetOptions(opts)
+	if checkOutput {
+		return checkGenerated(g, definitions, job.output)
+	}
+	if err := g.Generate(definitions); err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	logRenames(job.log, g.Renames(definitions))
+	job.log.Info("code generated", "output", job.output)
+	if withDocs {
+		if err := writeDocs(job, definitions); err != nil {
+			return err
+		}
+	}
+	if withDocker || withK8s {
+		var flags []deploy.Flag
+		if soapVersion != "" {
+			flags = append(flags, deploy.Flag{Name: "soap-version", Value: soapVersion})
+		}
+		if job.service != "" {
+			flags = append(flags, deploy.Flag{Name: "service", Value: job.service})
+		}
+		transforms, err := transformConfig(definitions)
+		if err != nil {
+			return err
+		}
+		scripts, _, err := scriptConfig(definitions)
+		if err != nil {
+			return err
+		}
+		if err := writeDeployment(job.output, []string{job.wsdl}, definitions, flags, transforms, scripts); err != nil {
+			return err
+		}
+	}
+	if initModule != "" {
+		job.log.Info("run go mod tidy in the output directory to resolve the module's requirements", "module", initModule)
+	}
+
+	if verifyCode {
+		job.log.Info("verifying generated code", "output", job.output)
+		if err := g.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		fmt.Printf("Code generated successfully in: %s\n", outputDir)
+// wsdlDirJobs returns the clients of the WSDLs under dir: each is
+// generated into a package named after its file, in the directory of that
+// name under output that mirrors its directory under dir
+func wsdlDirJobs(dir, output string) ([]generateJob, error) {
+	var jobs []generateJob
+	wsdls := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".wsdl") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		pkg := wsdlPackage(path)
+		job := generateJob{
+			wsdl:   path,
+			output: filepath.Join(output, filepath.Dir(rel), pkg),
+			pkg:    pkg,
+			log:    slog.With("wsdl", path),
+		}
+		if other, ok := wsdls[job.output]; ok {
+			return fmt.Errorf("%s and %s would both be generated into %s", other, path, job.output)
+		}
+		wsdls[job.output] = path
+		jobs = append(jobs, job)
 		return nil
-	},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("no .wsdl files in %s", dir)
+	}
+	return jobs, nil
+}
+
+// wsdlPackage returns the package name of a WSDL of --wsdl-dir: its file
+// name in lower case without the characters invalid in identifiers, such
+// as "orderservice" for Order-Service.wsdl
+func wsdlPackage(path string) string {
+	return goPackage(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+}
+
+// goPackage returns a package name made of a name in lower case without
+// the characters invalid in identifiers
+func goPackage(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	pkg := b.String()
+	if !naming.IsIdentifier(pkg) || token.IsKeyword(pkg) {
+		pkg = "wsdl" + pkg
+	}
+	return pkg
+}
+
+// generateAll generates the clients of --wsdl-dir, --jobs at a time. A
+// WSDL that fails doesn't stop the others; the errors are reported
+// together.
+func generateAll(cmd *cobra.Command, jobs []generateJob, opts generator.Options, mapping *generator.TypeMapping) error {
+	workers := parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = generateClient(cmd, job, opts, mapping)
+		}()
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", jobs[i].wsdl, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d WSDLs failed:\n%w", len(failed), len(jobs), errors.Join(failed...))
+	}
+	slog.Info("generated clients", "wsdls", len(jobs), "output", outputDir)
+	return nil
 }
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start REST API server",
-	Long:  `Parse WSDL, generate code, and start REST API server`,
+	Long:  `Parse one or more WSDLs, generate code, and start REST API server`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if wsdlPath == "" {
+		if len(wsdlPaths) == 0 {
 			return fmt.Errorf("wsdl path is required")
 		}
+		if (wsdlService != "" || wsdlPort != "") && len(wsdlPaths) > 1 {
+			return fmt.Errorf("--service and --wsdl-port select a service of a single WSDL")
+		}
 
-		fmt.Printf("Parsing WSDL: %s\n", wsdlPath)
-
-		// Parse WSDL
-		p := parser.NewParser()
-		definitions, err := p.Parse(wsdlPath)
+		// Parse WSDLs
+		p, err := newParser()
 		if err != nil {
-			return fmt.Errorf("failed to parse WSDL: %w", err)
+			return err
+		}
+		var defs []*models.Definitions
+		for _, path := range wsdlPaths {
+			slog.Info("parsing WSDL", "path", path)
+
+			definitions, err := p.Parse(path)
+			if err != nil {
+				return fmt.Errorf("failed to parse WSDL %s: %w", path, err)
+			}
+			services, err := selectServices(definitions, slog.With("path", path))
+			if err != nil {
+				return err
+			}
+
+			slog.Info("parsed WSDL", "path", path, "services", len(definitions.Services))
+			defs = append(defs, services...)
 		}
 
-		fmt.Printf("Found %d services\n", len(definitions.Services))
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+		if err := validateDecimalType(); err != nil {
+			return err
+		}
+		if (tlsCert == "") != (tlsKey == "") {
+			return fmt.Errorf("--tls-cert and --tls-key must be set together")
+		}
+		transforms, err := transformConfig(defs...)
+		if err != nil {
+			return err
+		}
+		scripts, hooks, err := scriptConfig(defs...)
+		if err != nil {
+			return err
+		}
+		if withDocker || withK8s {
+			return writeDeployment(dockerDir, wsdlPaths, defs[0], serveFlags(cmd), transforms, scripts)
+		}
 
 		// Start server
-		srv := server.NewServer(definitions, host, port)
-		fmt.Printf("Starting REST API server on %s:%d\n", host, port)
+		var srv *server.Server
+		if len(defs) == 1 {
+			srv = server.NewServer(defs[0], host, port)
+		} else {
+			if soapEndpoint != "" {
+				return fmt.Errorf("--soap-endpoint cannot be used with multiple WSDLs")
+			}
+			srv, err = server.NewMultiServer(defs, host, port)
+			if err != nil {
+				return err
+			}
+		}
+		routeCfg, err := routeConfig()
+		if err != nil {
+			return err
+		}
+		if err := srv.SetRoutes(routeCfg); err != nil {
+			return fmt.Errorf("invalid routes: %w", err)
+		}
+		if err := srv.SetTransforms(transforms); err != nil {
+			return fmt.Errorf("invalid transforms: %w", err)
+		}
+		if hooks != nil {
+			srv.SetHooks(hooks.Request, hooks.Response)
+		}
+		srv.SetDecimalsAsStrings(decimalType != generator.DecimalTypeFloat)
+		srv.SetGraphQL(serveGraphQL)
+		srv.SetRequestValidation(!noValidate)
+		if tlsCert != "" {
+			srv.SetTLS(tlsCert, tlsKey)
+		}
+		if err := configureBackend(srv); err != nil {
+			return err
+		}
+		if err := srv.SetSOAPDump(dumpDir); err != nil {
+			return err
+		}
+		srv.SetBatch(batch)
+		if breaker.Threshold < 0 || breaker.Threshold > 1 {
+			return fmt.Errorf("--breaker-threshold must be between 0 and 1")
+		}
+		if breaker.Threshold > 0 {
+			srv.SetBreaker(breaker)
+		}
+		if affinity.KeyHeader != "" {
+			if err := srv.SetAffinity(affinity); err != nil {
+				return err
+			}
+		} else if len(affinity.SessionHeaders) > 0 {
+			return fmt.Errorf("--session-header requires --session-key-header")
+		}
+		if rateLimit > 0 || routeRate > 0 || maxInFlight > 0 {
+			srv.SetLimits(server.Limits{
+				GlobalRate:  rateLimit,
+				GlobalBurst: rateBurst,
+				RouteRate:   routeRate,
+				RouteBurst:  routeBurst,
+				MaxInFlight: maxInFlight,
+			})
+		}
+		slog.Info("starting REST API server", "host", host, "port", port, "tls", tlsCert != "")
 
 		if err := srv.Start(); err != nil {
 			return fmt.Errorf("failed to start server: %w", err)
@@ -101,101 +584,1386 @@ var serveCmd = &cobra.Command{
 	},
 }
 
-var exportCmd = &cobra.Command{
-	Use:   "export",
-	Short: "Export WSDL to OpenAPI/Swagger specification",
-	Long:  `Parse WSDL and export as OpenAPI 3.0 specification`,
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record SOAP calls to replay them offline",
+	Long:  `Proxy SOAP calls to the live endpoint and save each request and response, or replay the saved responses with --replay`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := fmt.Sprintf("%s:%d", host, recordPort)
+
+		if replay {
+			replayer, err := recorder.Load(recordDir)
+			if err != nil {
+				return fmt.Errorf("failed to load recordings: %w", err)
+			}
+			replayer.SetStrict(strict)
+			slog.Info("replaying SOAP calls", "addr", addr, "dir", recordDir, "recordings", replayer.Len())
+			return http.ListenAndServe(addr, replayer)
+		}
+
+		target := recordTarget
+		if target == "" && wsdlPath != "" {
+			p, err := newParser()
+			if err != nil {
+				return err
+			}
+			slog.Info("parsing WSDL", "path", wsdlPath)
+			definitions, err := p.Parse(wsdlPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse WSDL: %w", err)
+			}
+			target = serviceEndpoint(definitions)
+		}
+		if target == "" {
+			return fmt.Errorf("--target or a WSDL with a service endpoint is required")
+		}
+
+		slog.Info("recording SOAP calls", "addr", addr, "target", target, "dir", recordDir)
+		return http.ListenAndServe(addr, recorder.NewRecorder(target, recordDir))
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a WSDL for errors",
+	Long:  `Parse WSDL and report unresolved references, recursive types, unsupported binding styles and missing soapAction values. Exits non-zero when errors are found.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if wsdlPath == "" {
 			return fmt.Errorf("wsdl path is required")
 		}
 
-		fmt.Printf("Parsing WSDL: %s\n", wsdlPath)
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+
+		issues := validator.Validate(definitions)
+		for _, issue := range issues {
+			fmt.Printf("%s: %s\n", wsdlPath, issue)
+		}
+
+		failed := validator.HasErrors(issues) || strict && len(issues) > 0
+		if failed {
+			// The issues are the output; don't repeat them as usage errors
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s: %d issues found", wsdlPath, len(issues))
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%s: no issues found\n", wsdlPath)
+		}
+		return nil
+	},
+}
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Describe the services and operations of a WSDL",
+	Long:  `Parse WSDL and print its services, ports, bindings, operations, SOAP actions and message shapes as JSON or tables, without generating anything`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
 
-		// Parse WSDL
-		p := parser.NewParser()
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
 		definitions, err := p.Parse(wsdlPath)
 		if err != nil {
 			return fmt.Errorf("failed to parse WSDL: %w", err)
 		}
 
-		fmt.Printf("Converting to OpenAPI...\n")
+		desc := describe.Describe(definitions)
+		switch descFormat {
+		case "json":
+			data, err := json.MarshalIndent(desc, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		case "table":
+			return desc.WriteTable(os.Stdout)
+		default:
+			return fmt.Errorf("unsupported format: %s (use json or table)", descFormat)
+		}
+	},
+}
 
-		// Convert to OpenAPI
-		spec, err := exporter.ConvertWSDLToOpenAPI(definitions)
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-wsdl> <new-wsdl>",
+	Short: "Compare two versions of a WSDL",
+	Long:  `Parse two versions of a WSDL and report added and removed operations, changed message parts and changed types, flagging breaking changes`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := newParser()
 		if err != nil {
-			return fmt.Errorf("failed to convert to OpenAPI: %w", err)
+			return err
+		}
+		var defs []*models.Definitions
+		for _, path := range args {
+			definitions, err := p.Parse(path)
+			if err != nil {
+				return fmt.Errorf("failed to parse WSDL %s: %w", path, err)
+			}
+			defs = append(defs, definitions)
 		}
 
-		// Export based on format
-		var output string
-		if exportFormat == "yaml" || exportFormat == "yml" {
-			output, err = spec.ExportToYAML()
-		} else {
-			output, err = spec.ExportToJSON()
+		changes := diff.Compare(defs[0], defs[1])
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		if len(changes) == 0 {
+			fmt.Println("no changes")
+		}
+
+		if failBreaking && diff.HasBreaking(changes) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("breaking changes found")
+		}
+		return nil
+	},
+}
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover [address...]",
+	Short: "Discover the WSDL contracts of live services",
+	Long:  `Retrieve the contracts of services from WS-MetadataExchange endpoints (--mex), or by probing their addresses for a ?singleWsdl, a ?wsdl and a MEX endpoint. The WSDLs and schemas of each contract, such as the ?wsdl=wsdl0 and ?xsd=xsd0 parts of WCF services, are bundled into a single WSDL. Lists the contracts, and writes them to --output for generate and serve.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && len(mexURLs) == 0 {
+			return fmt.Errorf("a service address or --mex is required")
 		}
+		if listFormat != "table" && listFormat != "json" {
+			return fmt.Errorf("unsupported format: %s (use json or table)", listFormat)
+		}
+		cmd.SilenceUsage = true
 
+		client, err := newDiscoveryClient()
 		if err != nil {
-			return fmt.Errorf("failed to export: %w", err)
+			return err
+		}
+		var found []*discovery.Contract
+		for _, address := range args {
+			slog.Info("probing service", "address", address)
+			contract, err := client.Discover(cmd.Context(), address)
+			if err != nil {
+				return err
+			}
+			found = append(found, contract)
+		}
+		for _, endpoint := range mexURLs {
+			slog.Info("requesting metadata", "mex", endpoint)
+			contract, err := client.MEX(cmd.Context(), endpoint)
+			if err != nil {
+				return fmt.Errorf("failed to get metadata from %s: %w", endpoint, err)
+			}
+			found = append(found, contract)
 		}
 
-		// Write to file or stdout
-		if outputDir == "" || outputDir == "-" {
-			fmt.Println(output)
-		} else {
-			filename := fmt.Sprintf("%s/openapi.%s", outputDir, exportFormat)
-			if err := os.WriteFile(filename, []byte(output), 0644); err != nil {
-				return fmt.Errorf("failed to write file: %w", err)
+		contracts, err := listContracts(found, contractDir)
+		if err != nil {
+			return err
+		}
+		if listFormat == "json" {
+			data, err := json.MarshalIndent(contracts, "", "  ")
+			if err != nil {
+				return err
 			}
-			fmt.Printf("OpenAPI spec exported to: %s\n", filename)
+			fmt.Println(string(data))
+			return nil
+		}
+		return writeContractTable(os.Stdout, contracts)
+	},
+}
+
+var consoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Explore and call the operations of a SOAP service interactively",
+	Long:  `Parse WSDL and open an interactive console listing its operations. Entering an operation prompts for the fields of its input message, calls the service and shows the raw SOAP envelopes sent and received along with the response as JSON.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+		if err := validateDecimalType(); err != nil {
+			return err
+		}
+
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+
+		// The console makes the backend calls of the REST proxy without
+		// serving it
+		srv := server.NewServer(definitions, "", 0)
+		srv.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))) // Errors are shown by the console
+		srv.SetDecimalsAsStrings(decimalType != generator.DecimalTypeFloat)
+		srv.SetRequestValidation(!noValidate)
+		if err := configureBackend(srv); err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		return console.New(definitions, srv, os.Stdin, os.Stdout).Run(cmd.Context())
+	},
+}
+
+var callCmd = &cobra.Command{
+	Use:   "call",
+	Short: "Call an operation of a SOAP service",
+	Long:  `Parse WSDL and call one operation with input given as JSON, printing the response as JSON, or as the raw SOAP envelope with --format xml. Exits non-zero on SOAP faults and failed calls, so scripts can call SOAP services without generating code.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
+		if outFormat != "json" && outFormat != "xml" {
+			return fmt.Errorf("unsupported format: %s (use json or xml)", outFormat)
+		}
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+		if err := validateDecimalType(); err != nil {
+			return err
+		}
+		input, err := readInput(inputData)
+		if err != nil {
+			return err
+		}
+
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+
+		srv := server.NewServer(definitions, "", 0)
+		srv.SetDecimalsAsStrings(decimalType != generator.DecimalTypeFloat)
+		srv.SetRequestValidation(!noValidate)
+		if err := configureBackend(srv); err != nil {
+			return err
 		}
+		cmd.SilenceUsage = true
 
-		// Generate TypeScript client if requested
-		if generateTS {
-			tsDir := tsOutputDir
-			if tsDir == "" {
-				tsDir = filepath.Join(outputDir, "typescript")
+		ex, err := srv.Call(cmd.Context(), opName, input)
+		var fault *server.Fault
+		if err != nil && !errors.As(err, &fault) {
+			return err
+		}
+		if outFormat == "xml" {
+			fmt.Println(strings.TrimSpace(string(ex.Response)))
+		} else {
+			var result interface{} = ex.Result
+			if fault != nil {
+				result = fault
+			}
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
 			}
+			fmt.Println(string(data))
+		}
+		// Faults are the output, and an error for the exit status
+		return err
+	},
+}
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Smoke test the operations of a live SOAP service",
+	Long:  `Parse WSDL and call every operation on the endpoint with the minimal valid request of its schema, checking that the response parses and is not a SOAP fault. Writes a JUnit XML report with --junit and exits non-zero when an operation fails, for CI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+		endpoint := soapEndpoint
+		if endpoint == "" {
+			endpoint = serviceEndpoint(definitions)
+		}
+		if endpoint == "" {
+			return fmt.Errorf("--endpoint or a WSDL with a service endpoint is required")
+		}
+
+		srv := server.NewServer(definitions, "", 0)
+		srv.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))) // Failures are in the report
+		if err := configureBackend(srv); err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
 
-			fmt.Printf("Generating TypeScript client in: %s\n", tsDir)
-			tsGen := typescript.NewGenerator(tsDir, spec)
-			if err := tsGen.Generate(); err != nil {
-				return fmt.Errorf("failed to generate TypeScript client: %w", err)
+		report := contract.Run(cmd.Context(), definitions, srv, endpoint, testOps)
+		for _, res := range report.Results {
+			switch {
+			case res.Skipped != "":
+				fmt.Printf("SKIP  %s: %s\n", res.Operation, res.Skipped)
+			case res.Failure != "":
+				fmt.Printf("FAIL  %s: %s\n", res.Operation, res.Failure)
+			case res.Error != "":
+				fmt.Printf("ERROR %s: %s\n", res.Operation, res.Error)
+			default:
+				fmt.Printf("PASS  %s (%s)\n", res.Operation, res.Duration.Round(time.Millisecond))
 			}
-			fmt.Printf("TypeScript client generated successfully in: %s\n", tsDir)
 		}
+		noun := "operations"
+		if len(report.Results) == 1 {
+			noun = "operation"
+		}
+		fmt.Printf("\n%d %s, %d failed, in %s\n", len(report.Results), noun, report.Failed(), report.Duration.Round(time.Millisecond))
 
+		if junitReport != "" {
+			f, err := os.Create(junitReport)
+			if err != nil {
+				return fmt.Errorf("failed to create JUnit report: %w", err)
+			}
+			if err := report.WriteJUnit(f); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write JUnit report: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write JUnit report: %w", err)
+			}
+		}
+		if report.Failed() > 0 {
+			return fmt.Errorf("%d of %d operations failed", report.Failed(), len(report.Results))
+		}
 		return nil
 	},
 }
 
-func init() {
-	// Generate command flags
-	generateCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Load test an operation on the SOAP backend or the REST proxy",
+	Long:  `Parse WSDL and call one operation from concurrent workers for a duration, on the SOAP backend or, with --proxy, through the REST proxy, then report the latency percentiles and error rate. The input is --data, or the minimal valid request of the operation's schema.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+		if benchTime <= 0 && maxRequests <= 0 {
+			return fmt.Errorf("--duration or --requests is required")
+		}
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+		input, err := readInput(inputData)
+		if err != nil {
+			return err
+		}
+
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+
+		srv := server.NewServer(definitions, "", 0)
+		srv.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))) // Errors are counted in the report
+		srv.SetRequestValidation(!noValidate)
+		known := false
+		for _, pt := range definitions.PortTypes {
+			for _, op := range pt.Operations {
+				known = known || op.Name == opName
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown operation: %s", opName)
+		}
+		if input == nil {
+			if input = srv.MinimalRequest(opName); input == nil {
+				return fmt.Errorf("no input can be derived for operation %s, pass --data", opName)
+			}
+		}
+
+		var call bench.Call
+		target := soapEndpoint
+		if proxyURL != "" {
+			cfg, err := routeConfig()
+			if err != nil {
+				return err
+			}
+			client := &http.Client{
+				Timeout:   backendPool.Timeout,
+				Transport: &http.Transport{MaxIdleConnsPerHost: concurrency},
+			}
+			if call, err = bench.REST(client, proxyURL, cfg.Route(opName), input); err != nil {
+				return err
+			}
+			target = proxyURL
+		} else {
+			if err := configureBackend(srv); err != nil {
+				return err
+			}
+			if target == "" {
+				target = serviceEndpoint(definitions)
+			}
+			call = bench.SOAP(srv, opName, input)
+		}
+		cmd.SilenceUsage = true
+
+		// Ctrl-C stops early, still reporting the calls made
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		slog.Info("benchmarking", "operation", opName, "target", target, "concurrency", concurrency, "duration", benchTime)
+		result := bench.Run(ctx, bench.Options{Concurrency: concurrency, Duration: benchTime, Requests: maxRequests}, call)
+		return result.Write(os.Stdout)
+	},
+}
+
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the REST proxy into a binary with the WSDL embedded",
+	Long:  `Parse WSDL and compile a standalone proxy binary serving it as serve does, with the parsed definitions embedded so it runs where the WSDL isn't reachable. The go command is required; GOOS and GOARCH select the target platform.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if wsdlPath == "" {
+			return fmt.Errorf("wsdl path is required")
+		}
+		if err := validateSOAPVersion(); err != nil {
+			return err
+		}
+		if err := validateDecimalType(); err != nil {
+			return err
+		}
+		version := generator.Version()
+		if version == "" && buildSource == "" {
+			return fmt.Errorf("this is a development build of wsdl2api: pass --source with the checkout to build the proxy with")
+		}
+
+		p, err := newParser()
+		if err != nil {
+			return err
+		}
+		definitions, err := p.Parse(wsdlPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse WSDL: %w", err)
+		}
+		routeCfg, err := routeConfig()
+		if err != nil {
+			return err
+		}
+		if routeCfg != nil {
+			if err := routeCfg.Check(definitions); err != nil {
+				return fmt.Errorf("invalid routes: %w", err)
+			}
+		}
+		transforms, err := transformConfig(definitions)
+		if err != nil {
+			return err
+		}
+		scripts, _, err := scriptConfig(definitions)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		slog.Info("building proxy", "output", binaryPath)
+		err = bundle.Build(cmd.Context(), definitions, buildDir, binaryPath, bundle.Options{
+			Version:           version,
+			Source:            buildSource,
+			WSDL:              wsdlPath,
+			SOAPEndpoint:      soapEndpoint,
+			SOAPVersion:       soapVersion,
+			Routes:            routeCfg,
+			Transforms:        transforms,
+			Scripts:           scripts,
+			DecimalsAsStrings: decimalType != generator.DecimalTypeFloat,
+			SkipValidation:    noValidate,
+			GraphQL:           serveGraphQL,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build the proxy: %w", err)
+		}
+		slog.Info("proxy built", "output", binaryPath)
+		return nil
+	},
+}
+
+// loadConfig applies values from the --config file to every flag of cmd that
+// was not set on the command line. Keys are flag names, e.g. "port" or
+// "wsdl-timeout"; deprecated names are ignored, so that the "port" of serve
+// doesn't select the WSDL port of generate.
+func loadConfig(cmd *cobra.Command) error {
+	if configFile == "" {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Deprecated != "" || !v.IsSet(f.Name) {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			err = sv.Replace(v.GetStringSlice(f.Name))
+			return
+		}
+		err = cmd.Flags().Set(f.Name, v.GetString(f.Name))
+	})
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	return nil
+}
+
+// setupLogger installs the default structured logger from the log flags.
+// Logs go to stderr so exported specs can be piped from stdout.
+func setupLogger() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("invalid log level: %s (use debug, info, warn or error)", logLevel)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch logFormat {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, opts)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, opts)))
+	default:
+		return fmt.Errorf("invalid log format: %s (use text or json)", logFormat)
+	}
+
+	// Gin's route listing is only useful when debugging
+	if level > slog.LevelDebug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	return nil
+}
+
+// routeConfig returns the REST routes from the --routes file and the
+// --rest-verbs flag, or nil for POST /{Operation} routes
+func routeConfig() (*routes.Config, error) {
+	cfg := &routes.Config{}
+	if routesFile != "" {
+		var err error
+		if cfg, err = routes.Load(routesFile); err != nil {
+			return nil, err
+		}
+	}
+	if restVerbs {
+		cfg.Heuristics = true
+	}
+	if !cfg.Heuristics && len(cfg.Operations) == 0 {
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// transformConfig returns the response transforms of the config file, none
+// without one, checking that defs declare their operations
+func transformConfig(defs ...*models.Definitions) (transform.Config, error) {
+	if configFile == "" {
+		return nil, nil
+	}
+	cfg, err := transform.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Check(defs...); err != nil {
+		return nil, fmt.Errorf("invalid transforms: %w", err)
+	}
+	return cfg, nil
+}
+
+// scriptConfig returns the scripts of the config file and their hooks, none
+// without one, checking that defs declare their operations
+func scriptConfig(defs ...*models.Definitions) (map[string]script.Script, *script.Hooks, error) {
+	if configFile == "" {
+		return nil, nil, nil
+	}
+	scripts, err := script.ReadConfig(configFile)
+	if err != nil || scripts == nil {
+		return nil, nil, err
+	}
+	hooks, err := script.Compile(scripts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := hooks.Check(defs...); err != nil {
+		return nil, nil, fmt.Errorf("invalid scripts: %w", err)
+	}
+	return scripts, hooks, nil
+}
+
+// writeDeployment writes the Dockerfile, docker-compose.yaml and, with
+// --with-k8s, the Kubernetes manifests serving wsdls as the proxy to dir
+func writeDeployment(dir string, wsdls []string, def *models.Definitions, flags []deploy.Flag, transforms transform.Config, scripts map[string]script.Script) error {
+	name := def.Name
+	if len(def.Services) > 0 {
+		name = def.Services[0].Name
+	}
+	files, err := deploy.Write(dir, deploy.Options{
+		Name:        name,
+		Image:       dockerImage,
+		Version:     generator.Version(),
+		WSDLs:       wsdls,
+		Flags:       flags,
+		Transforms:  transforms,
+		Scripts:     scripts,
+		Port:        port,
+		Kubernetes:  withK8s,
+		IngressHost: ingressHost,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write deployment files: %w", err)
+	}
+	slog.Info("deployment files written", "dir", dir, "files", strings.Join(files, ", "))
+	return nil
+}
+
+// writeDocs writes the Markdown documentation of the operations of def to
+// the docs directory of the client, with examples calling its package
+func writeDocs(job generateJob, def *models.Definitions) error {
+	spec, err := exporter.ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		return fmt.Errorf("failed to document operations: %w", err)
+	}
+	dir := filepath.Join(job.output, "docs")
+	files, err := apidoc.New(def, spec, apidoc.Options{Package: job.pkg}).WriteMarkdown(dir)
+	if err != nil {
+		return fmt.Errorf("failed to write documentation: %w", err)
+	}
+	job.log.Info("documentation written", "dir", dir, "files", len(files))
+	return nil
+}
+
+// serveFlags returns the serve flags set on the command line or in the
+// config file, for the proxy packaged by --with-docker to run the same.
+// The flags of the packaging, the WSDLs, the address and the config file
+// itself are left out.
+func serveFlags(cmd *cobra.Command) []deploy.Flag {
+	skip := map[string]bool{
+		"wsdl": true, "host": true, "port": true, "config": true,
+		"with-docker": true, "with-k8s": true, "docker-dir": true, "docker-image": true, "ingress-host": true,
+	}
+	var flags []deploy.Flag
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if skip[f.Name] {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, value := range sv.GetSlice() {
+				flags = append(flags, deploy.Flag{Name: f.Name, Value: value})
+			}
+			return
+		}
+		flags = append(flags, deploy.Flag{Name: f.Name, Value: f.Value.String()})
+	})
+	return flags
+}
+
+// configureBackend applies the backend flags to a server: the endpoint,
+// SOAP version, connections, compression, WS-Addressing and credentials
+// of its SOAP calls
+func configureBackend(srv *server.Server) error {
+	if soapVersion != "" {
+		srv.SetSOAPVersion(soapVersion)
+	}
+	if soapEndpoint != "" {
+		srv.SetSOAPEndpoint(soapEndpoint)
+	}
+	if err := srv.SetPool(backendPool); err != nil {
+		return fmt.Errorf("invalid connection pool settings: %w", err)
+	}
+	if err := srv.SetCompression(compression); err != nil {
+		return err
+	}
+	if !backendTLS.IsZero() {
+		if err := srv.SetBackendTLS(backendTLS); err != nil {
+			return fmt.Errorf("invalid backend TLS settings: %w", err)
+		}
+	}
+	if wsAddressing {
+		srv.SetAddressing(&addressing.WSAddressing{})
+	}
+	if backendOAuth.TokenURL != "" {
+		if mode := server.CredentialMode(backendAuth); mode == server.CredentialsBasic || mode == server.CredentialsNTLM {
+			return fmt.Errorf("--oauth-token-url can't be combined with --backend-auth %s", mode)
+		}
+		if err := srv.SetOAuth2(backendOAuth); err != nil {
+			return fmt.Errorf("invalid OAuth2 settings: %w", err)
+		}
+	}
+	switch mode := server.CredentialMode(backendAuth); mode {
+	case server.CredentialsNone:
+	case server.CredentialsBasic, server.CredentialsWSSecurity, server.CredentialsWSSecurityDigest, server.CredentialsNTLM:
+		srv.SetCredentials(&server.Credentials{
+			Mode:     mode,
+			Username: backendUser,
+			Password: backendPass,
+		})
+	default:
+		return fmt.Errorf("unsupported backend auth: %s (use basic, ntlm, wssecurity or wssecurity-digest)", backendAuth)
+	}
+	return nil
+}
+
+// readInput returns the input fields of the --data flag: a JSON object,
+// or @file or - for stdin to read one. No data is no fields.
+func readInput(data string) (map[string]interface{}, error) {
+	var err error
+	raw := []byte(data)
+	switch {
+	case data == "-":
+		raw, err = io.ReadAll(os.Stdin)
+	case strings.HasPrefix(data, "@"):
+		raw, err = os.ReadFile(data[1:])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the input: %w", err)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+
+	var input map[string]interface{}
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return nil, fmt.Errorf("invalid --data, a JSON object is expected: %w", err)
+	}
+	return input, nil
+}
+
+// generateOptions returns the files to generate from the --artifacts,
+// --no-example, --mock, --with-tests and --server flags
+func generateOptions() (generator.Options, error) {
+	opts := generator.DefaultOptions()
+	if len(artifacts) > 0 {
+		var err error
+		if opts, err = generator.ParseArtifacts(artifacts); err != nil {
+			return opts, err
+		}
+	}
+	if noExample {
+		opts.Example = false
+	}
+	opts.Mock = opts.Mock || generateMock
+	opts.Tests = opts.Tests || withTests
+	opts.Server = opts.Server || genServer
+	return opts, nil
+}
+
+// checkGenerated prints the generated files that are out of date, failing
+// if there are any
+func checkGenerated(g *generator.Generator, definitions *models.Definitions, output string) error {
+	drift, err := g.Check(definitions)
+	if err != nil {
+		return fmt.Errorf("failed to check generated code: %w", err)
+	}
+	for _, d := range drift {
+		fmt.Printf("%s: %s\n", filepath.Join(output, filepath.FromSlash(d.File)), d.Reason)
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("generated code in %s is out of date: run generate without --check", output)
+	}
+	fmt.Printf("%s: up to date\n", output)
+	return nil
+}
+
+// goGenerateCommand returns the command line of the //go:generate
+// directive, which repeats the generate command from the output directory:
+// the output is ".", and the WSDL and the other files named by flags are
+// relative to it. Flags at their default values, the config file, whose
+// values the flags already hold, and credentials, which don't belong in
+// source files, are left out. The clients of --wsdl-dir are regenerated
+// from their WSDL each, and those of the services of a WSDL split into a
+// package per service from their service each.
+func goGenerateCommand(cmd *cobra.Command, job generateJob) (string, error) {
+	args := []string{"wsdl2api", "generate"}
+
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Value.String() == f.DefValue {
+			return
+		}
+		switch f.Name {
+		case "output", "config", "check", "wsdl-dir", "jobs", "wsdl-auth-user", "wsdl-auth-pass", "wsdl-header":
+			return
+		case "package":
+			if wsdlDir != "" || job.service != "" {
+				return
+			}
+		}
+
+		var values []string
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			values = sv.GetSlice()
+		} else {
+			values = []string{f.Value.String()}
+		}
+		for _, value := range values {
+			switch {
+			case f.Value.Type() == "bool" && value == "true":
+				args = append(args, "--"+f.Name)
+				continue
+			case f.Name == "wsdl" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://"),
+				f.Name == "type-map", f.Name == "wsdl-cert", f.Name == "wsdl-key":
+				if value, err = relativeTo(job.output, value); err != nil {
+					return
+				}
+			}
+			args = append(args, "--"+f.Name, quoteArg(value))
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if wsdlDir != "" {
+		wsdl, err := relativeTo(job.output, job.wsdl)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "--package", job.pkg, "--wsdl", quoteArg(wsdl))
+	}
+	if job.service != "" {
+		args = append(args, "--service", quoteArg(job.service))
+		if wsdlDir == "" {
+			args = append(args, "--package", job.pkg)
+		}
+	}
+	return strings.Join(append(args, "--output", "."), " "), nil
+}
+
+// relativeTo returns path relative to dir, with forward slashes
+func relativeTo(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// quoteArg quotes an argument of a go:generate directive if it contains
+// spaces or quotes, which would split it
+func quoteArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\"") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+// validateSOAPVersion checks the --soap-version flag. Empty uses the
+// version of the WSDL binding.
+func validateSOAPVersion() error {
+	if soapVersion != "" && soapVersion != "1.1" && soapVersion != "1.2" {
+		return fmt.Errorf("unsupported SOAP version: %s (use 1.1 or 1.2)", soapVersion)
+	}
+	return nil
+}
+
+// validateDecimalType checks the --decimal-type flag
+func validateDecimalType() error {
+	switch decimalType {
+	case generator.DecimalTypeFloat, generator.DecimalTypeString, generator.DecimalTypeBigRat, generator.DecimalTypeShopspring:
+		return nil
+	default:
+		return fmt.Errorf("unsupported decimal type: %s (use %s, %s, %s or %s)", decimalType,
+			generator.DecimalTypeFloat, generator.DecimalTypeString, generator.DecimalTypeBigRat, generator.DecimalTypeShopspring)
+	}
+}
+
+// serviceEndpoint returns the address of the first service port
+func serviceEndpoint(def *models.Definitions) string {
+	for _, svc := range def.Services {
+		for _, port := range svc.Ports {
+			if port.Address != "" {
+				return port.Address
+			}
+		}
+	}
+	return ""
+}
+
+// operationFilter returns the filter of --include-ops and --exclude-ops,
+// warning of patterns that match no operation of def
+func operationFilter(def *models.Definitions, log *slog.Logger) (*filter.Filter, error) {
+	f, err := filter.New(includeOps, excludeOps)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range f.Unused(def) {
+		log.Warn("operation pattern matches no operation", "pattern", pattern)
+	}
+	return f, nil
+}
+
+// selectService trims def to the service and port selected by --service
+// and --wsdl-port, and to the operations of --include-ops and --exclude-ops
+func selectService(def *models.Definitions, log *slog.Logger) (*models.Definitions, error) {
+	f, err := operationFilter(def, log)
+	if err != nil {
+		return nil, err
+	}
+	if def, err = filter.Select(def, wsdlService, wsdlPort); err != nil {
+		return nil, err
+	}
+	return f.Apply(def)
+}
+
+// selectServices is selectService, but for WSDLs whose services bind
+// different port types, which without a selection are split into a copy
+// per service. Services the operation filters leave without operations
+// are dropped.
+func selectServices(def *models.Definitions, log *slog.Logger) ([]*models.Definitions, error) {
+	if wsdlService != "" || wsdlPort != "" {
+		def, err := selectService(def, log)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Definitions{def}, nil
+	}
+
+	f, err := operationFilter(def, log)
+	if err != nil {
+		return nil, err
+	}
+	defs := filter.Split(def)
+	if len(defs) == 1 {
+		def, err := f.Apply(def)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Definitions{def}, nil
+	}
+	if _, err := f.Apply(def); err != nil {
+		return nil, err
+	}
+	var kept []*models.Definitions
+	for _, service := range defs {
+		if service, err := f.Apply(service); err == nil {
+			kept = append(kept, service)
+		}
+	}
+	return kept, nil
+}
+
+// logRenames reports the WSDL names that were renamed to become valid,
+// unique identifiers in the generated code
+func logRenames(log *slog.Logger, renames []naming.Rename) {
+	for _, r := range renames {
+		log.Warn("renamed identifier", "kind", r.Kind, "original", r.Original, "name", r.Name)
+	}
+}
+
+// newParser creates a WSDL parser configured from the fetch flags
+func newParser() (*parser.Parser, error) {
+	client, headers, err := fetchSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.NewParserWithHTTPClient(client)
+	if wsdlAuthUser != "" {
+		p.SetBasicAuth(wsdlAuthUser, wsdlAuthPass)
+	}
+	for key := range headers {
+		p.SetHeader(key, headers.Get(key))
+	}
+
+	return p, nil
+}
+
+// newDiscoveryClient creates a contract discovery client configured from
+// the fetch flags
+func newDiscoveryClient() (*discovery.Client, error) {
+	httpClient, headers, err := fetchSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	client := discovery.NewClient(httpClient)
+	client.Header = headers
+	client.Username = wsdlAuthUser
+	client.Password = wsdlAuthPass
+	return client, nil
+}
+
+// fetchSettings returns the HTTP client and headers of the fetch flags
+func fetchSettings() (*http.Client, http.Header, error) {
+	client, err := parser.NewHTTPClient(parser.FetchOptions{
+		Timeout:  wsdlTimeout,
+		CertFile: wsdlCert,
+		KeyFile:  wsdlKey,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := make(http.Header)
+	for _, header := range wsdlHeaders {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid header %q (expected \"Key: Value\")", header)
+		}
+		headers.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return client, headers, nil
+}
+
+// listedContract is a contract as the discover command lists it
+type listedContract struct {
+	Name       string   `json:"name"`
+	Method     string   `json:"method"`
+	Source     string   `json:"source"`
+	Documents  int      `json:"documents"`
+	Operations []string `json:"operations"`
+	File       string   `json:"file,omitempty"`
+}
+
+// listContracts bundles and parses discovered contracts, writing the
+// bundles to dir when it isn't empty
+func listContracts(found []*discovery.Contract, dir string) ([]listedContract, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	var contracts []listedContract
+	files := make(map[string]bool)
+	for i, contract := range found {
+		bundle, err := contract.Bundle()
+		if err != nil {
+			return nil, err
+		}
+		def, err := parser.NewParser().ParseReader(bytes.NewReader(bundle))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the contract of %s: %w", contract.Source, err)
+		}
+
+		listed := listedContract{
+			Name:       contractName(def, i),
+			Method:     contract.Method,
+			Source:     contract.Source,
+			Documents:  len(contract.Documents),
+			Operations: []string{},
+		}
+		for _, portType := range def.PortTypes {
+			for _, op := range portType.Operations {
+				listed.Operations = append(listed.Operations, op.Name)
+			}
+		}
+
+		if dir != "" {
+			base := listed.Name
+			for n := 2; files[strings.ToLower(base)]; n++ {
+				base = fmt.Sprintf("%s-%d", listed.Name, n)
+			}
+			files[strings.ToLower(base)] = true
+			listed.File = filepath.Join(dir, base+".wsdl")
+			if err := os.WriteFile(listed.File, bundle, 0644); err != nil {
+				return nil, err
+			}
+		}
+		contracts = append(contracts, listed)
+	}
+	return contracts, nil
+}
+
+// contractName returns the name of a discovered contract, usable as a
+// file name: the name of its definitions or first service
+func contractName(def *models.Definitions, index int) string {
+	name := def.Name
+	if name == "" && len(def.Services) > 0 {
+		name = def.Services[0].Name
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.') {
+			return r
+		}
+		return -1
+	}, name)
+	if strings.Trim(name, ".") == "" {
+		return fmt.Sprintf("service%d", index+1)
+	}
+	return name
+}
+
+// writeContractTable lists discovered contracts, and how to generate and
+// serve the ones written
+func writeContractTable(w io.Writer, contracts []listedContract) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTRACT\tMETHOD\tSOURCE\tDOCUMENTS\tOPERATIONS\tFILE")
+	var files []string
+	for _, c := range contracts {
+		file := c.File
+		if file == "" {
+			file = "-"
+		} else {
+			files = append(files, c.File)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n", c.Name, c.Method, c.Source, c.Documents, len(c.Operations), file)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(files) > 0 {
+		fmt.Fprintf(w, "\nGenerate clients:  wsdl2api generate --wsdl-dir %s\n", filepath.Dir(files[0]))
+		fmt.Fprintf(w, "Serve them:        wsdl2api serve --wsdl %s\n", strings.Join(files, " --wsdl "))
+	}
+	return nil
+}
+
+// addBackendFlags registers the flags of the backend SOAP calls of serve,
+// console and call
+func addBackendFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&wsAddressing, "ws-addressing", false, "Add WS-Addressing headers to backend SOAP calls")
+	flags.StringVar(&soapEndpoint, "soap-endpoint", "", "Override the SOAP endpoint from the WSDL")
+	flags.StringVar(&soapVersion, "soap-version", "", "SOAP version for backend calls (1.1 or 1.2, default from the WSDL binding)")
+	flags.StringVar(&backendAuth, "backend-auth", "", "Backend authentication: basic, ntlm, wssecurity or wssecurity-digest")
+	flags.StringVar(&backendUser, "backend-user", "", "Static backend username (serve forwards inbound Basic credentials without one)")
+	flags.StringVar(&backendPass, "backend-pass", "", "Static backend password")
+	flags.StringVar(&backendOAuth.TokenURL, "oauth-token-url", "", "OAuth2 token endpoint; backend calls send a bearer token from the client credentials flow")
+	flags.StringVar(&backendOAuth.ClientID, "oauth-client-id", "", "OAuth2 client ID")
+	flags.StringVar(&backendOAuth.ClientSecret, "oauth-client-secret", "", "OAuth2 client secret")
+	flags.StringSliceVar(&backendOAuth.Scopes, "oauth-scopes", nil, "OAuth2 scopes to request")
+	flags.BoolVar(&backendOAuth.CredentialsInBody, "oauth-credentials-in-body", false, "Send the OAuth2 client ID and secret as form values instead of HTTP Basic auth")
+	flags.StringVar(&backendTLS.CAFile, "backend-ca", "", "PEM bundle of the CAs trusted for the SOAP backend instead of the system roots")
+	flags.StringVar(&backendTLS.CertFile, "backend-cert", "", "Client certificate file for mutual TLS with the SOAP backend")
+	flags.StringVar(&backendTLS.KeyFile, "backend-key", "", "Client key file for mutual TLS with the SOAP backend")
+	flags.BoolVar(&backendTLS.InsecureSkipVerify, "backend-insecure", false, "Skip verifying the SOAP backend's certificate (insecure, for testing only)")
+	flags.StringVar(&backendTLS.MinVersion, "backend-tls-min", "", "Minimum TLS version for the SOAP backend (1.0, 1.1, 1.2 or 1.3)")
+	flags.DurationVar(&backendPool.Timeout, "backend-timeout", backendPool.Timeout, "Timeout for a backend SOAP call (0 for none)")
+	flags.DurationVar(&backendPool.DialTimeout, "backend-dial-timeout", backendPool.DialTimeout, "Timeout for connecting to the SOAP backend")
+	flags.IntVar(&backendPool.MaxIdleConnsPerHost, "backend-max-idle-conns", backendPool.MaxIdleConnsPerHost, "Idle keep-alive connections kept open to the SOAP backend")
+	flags.IntVar(&backendPool.MaxConnsPerHost, "backend-max-conns", 0, "Max connections to the SOAP backend (0 for unlimited)")
+	flags.DurationVar(&backendPool.IdleConnTimeout, "backend-idle-timeout", backendPool.IdleConnTimeout, "How long idle connections to the SOAP backend are kept open")
+	flags.BoolVar(&backendPool.DisableKeepAlives, "backend-no-keep-alive", false, "Open a new connection to the SOAP backend for every call")
+	flags.StringVar(&compression, "backend-compression", "", "Compress backend SOAP requests and accept compressed responses (gzip or deflate)")
+}
+
+func init() {
+	// Config file
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (e.g. wsdl2api.yaml) with defaults for any flag")
+
+	// Logging flags
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn or error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format (text or json)")
+
+	// WSDL fetch flags
+	rootCmd.PersistentFlags().DurationVar(&wsdlTimeout, "wsdl-timeout", parser.DefaultFetchTimeout, "Timeout for fetching remote WSDLs")
+	rootCmd.PersistentFlags().StringVar(&wsdlAuthUser, "wsdl-auth-user", "", "Basic auth username for fetching remote WSDLs")
+	rootCmd.PersistentFlags().StringVar(&wsdlAuthPass, "wsdl-auth-pass", "", "Basic auth password for fetching remote WSDLs")
+	rootCmd.PersistentFlags().StringArrayVar(&wsdlHeaders, "wsdl-header", nil, "HTTP header for fetching remote WSDLs (\"Key: Value\", repeatable)")
+	rootCmd.PersistentFlags().StringVar(&wsdlCert, "wsdl-cert", "", "Client certificate file for fetching remote WSDLs")
+	rootCmd.PersistentFlags().StringVar(&wsdlKey, "wsdl-key", "", "Client key file for fetching remote WSDLs")
+
+	// Generate command flags
+	generateCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (this or --wsdl-dir is required)")
+	generateCmd.Flags().StringVar(&wsdlDir, "wsdl-dir", "", "Directory whose .wsdl files to generate, each into a package named after the file under --output")
+	generateCmd.Flags().IntVar(&parallelism, "jobs", 0, "WSDLs of --wsdl-dir to generate in parallel (default the number of CPUs)")
 	generateCmd.Flags().StringVarP(&outputDir, "output", "o", "./generated", "Output directory")
 	generateCmd.Flags().StringVarP(&packageName, "package", "p", "client", "Go package name")
+	generateCmd.Flags().StringVar(&wsdlService, "service", "", "WSDL service to generate (default: a package per service when the services have different operations)")
+	generateCmd.Flags().StringVar(&wsdlPort, "wsdl-port", "", "WSDL port to call, selecting its binding and SOAP version (default: the first SOAP port of the service)")
+	generateCmd.Flags().StringVar(&wsdlPort, "port", "", "WSDL port to call")
+	generateCmd.Flags().MarkDeprecated("port", "use --wsdl-port instead")
+	generateCmd.Flags().StringSliceVar(&includeOps, "include-ops", nil, "Operations to keep, comma-separated globs such as Get* or regular expressions such as /^(Get|List)/ (default all)")
+	generateCmd.Flags().StringSliceVar(&excludeOps, "exclude-ops", nil, "Operations to drop, as globs or /regular expressions/, applied after --include-ops")
 	generateCmd.Flags().BoolVar(&generateMock, "mock", false, "Generate mock server")
-	generateCmd.Flags().StringVar(&soapVersion, "soap-version", "1.1", "SOAP version (1.1 or 1.2)")
-	_ = generateCmd.MarkFlagRequired("wsdl")
+	generateCmd.Flags().BoolVar(&withTests, "with-tests", false, "Generate round-trip tests against the mock server (implies --mock)")
+	generateCmd.Flags().BoolVar(&noExample, "no-example", false, "Don't generate example.go")
+	generateCmd.Flags().StringSliceVar(&artifacts, "artifacts", nil, "Files to generate, comma-separated: client, types, operators, fake, example, mock, tests, server (default all but mock, tests and server)")
+	generateCmd.Flags().BoolVar(&genServer, "server", false, "Generate REST server skeleton")
+	generateCmd.Flags().StringVar(&soapVersion, "soap-version", "", "SOAP version of the client (1.1 or 1.2, default from the WSDL binding)")
+	generateCmd.Flags().StringVar(&timeType, "time-type", generator.TimeTypeString, "Go type for xs:dateTime, xs:date and xs:time (string or time.Time)")
+	generateCmd.Flags().StringVar(&decimalType, "decimal-type", generator.DecimalTypeFloat, "Go type for xs:decimal (float64, string, *big.Rat or shopspring/decimal)")
+	generateCmd.Flags().StringVar(&jsonNaming, "json-naming", generator.JSONNamingXML, "Names of the json tags of generated fields (xml, as in the OpenAPI spec, or camel)")
+	generateCmd.Flags().StringVar(&typeMapFile, "type-map", "", "YAML file (e.g. typemap.yaml) mapping XSD types and elements to existing Go types")
+	generateCmd.Flags().BoolVar(&splitOutput, "split", false, "Write the types and operators of each port type to files of their own, indexed by doc.go")
+	generateCmd.Flags().StringVar(&initModule, "init-module", "", "Scaffold a standalone module with this path (e.g. github.com/acme/foo-client): go.mod, LICENSE and doc.go")
+	generateCmd.Flags().BoolVar(&standalone, "standalone", false, "Copy the runtime packages the client imports into the output's internal directory instead of importing wsdl2api")
+	generateCmd.Flags().BoolVar(&stableOutput, "deterministic", false, "Generate in name order with generated code headers, so that regenerating gives clean diffs")
+	generateCmd.Flags().BoolVar(&goGenerate, "go-generate", false, "Add a //go:generate directive repeating this command to doc.go")
+	generateCmd.Flags().StringArrayVar(&plugins, "plugin", nil, "Plugin command generating extra artifacts from the model as JSON (repeatable)")
+	generateCmd.Flags().BoolVar(&checkOutput, "check", false, "Check that the generated code is up to date instead of writing it, failing if it isn't")
+	generateCmd.Flags().BoolVar(&withDocs, "with-docs", false, "Also write Markdown documentation of the operations to docs/: an index and a page per operation")
+	generateCmd.Flags().BoolVar(&withDocker, "with-docker", false, "Also write a Dockerfile and docker-compose.yaml packaging the WSDL's REST proxy")
+	generateCmd.Flags().BoolVar(&withK8s, "with-k8s", false, "Also write kubernetes.yaml with a Deployment, Service and Ingress of the proxy (implies --with-docker)")
+	generateCmd.Flags().StringVar(&dockerImage, "docker-image", "", "Image name of --with-docker (default: wsdl2api-<service>)")
+	generateCmd.Flags().StringVar(&ingressHost, "ingress-host", "", "Host the Ingress of --with-k8s routes (default: every host)")
+	generateCmd.Flags().BoolVar(&verifyCode, "verify", false, "Type-check the generated code (the output must be inside a Go module)")
+	generateCmd.MarkFlagsOneRequired("wsdl", "wsdl-dir")
+	generateCmd.MarkFlagsMutuallyExclusive("wsdl", "wsdl-dir")
 
 	// Serve command flags
-	serveCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	serveCmd.Flags().StringArrayVarP(&wsdlPaths, "wsdl", "w", nil, "WSDL file path or URL (required, repeatable)")
+	serveCmd.Flags().StringVar(&wsdlService, "service", "", "WSDL service to serve (default: each service under /api/{service} when the services have different operations)")
+	serveCmd.Flags().StringVar(&wsdlPort, "wsdl-port", "", "WSDL port to call, selecting its binding and SOAP version (default: the first SOAP port of the service)")
+	serveCmd.Flags().StringSliceVar(&includeOps, "include-ops", nil, "Operations to keep, comma-separated globs such as Get* or regular expressions such as /^(Get|List)/ (default all)")
+	serveCmd.Flags().StringSliceVar(&excludeOps, "exclude-ops", nil, "Operations to drop, as globs or /regular expressions/, applied after --include-ops")
 	serveCmd.Flags().IntVar(&port, "port", 8080, "Server port")
 	serveCmd.Flags().StringVar(&host, "host", "localhost", "Server host")
+	serveCmd.Flags().StringVar(&decimalType, "decimal-type", generator.DecimalTypeFloat, "Decimal type used by generated clients; anything but float64 returns xs:decimal values as JSON strings")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "TLS certificate file to serve HTTPS")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "TLS key file to serve HTTPS")
+	addBackendFlags(serveCmd.Flags())
+	serveCmd.Flags().Float64Var(&breaker.Threshold, "breaker-threshold", 0, "Failure ratio (0-1) of backend calls opening the circuit breaker (0 disables it)")
+	serveCmd.Flags().IntVar(&breaker.MinRequests, "breaker-min-requests", 10, "Backend calls in a window before the circuit breaker can open")
+	serveCmd.Flags().DurationVar(&breaker.Window, "breaker-window", time.Minute, "Period the circuit breaker counts backend calls over")
+	serveCmd.Flags().DurationVar(&breaker.Cooldown, "breaker-cooldown", 30*time.Second, "Time the circuit stays open before a trial call")
+	serveCmd.Flags().BoolVar(&breaker.PerOperation, "breaker-per-operation", false, "Keep a circuit per operation instead of per backend endpoint")
+	serveCmd.Flags().StringVar(&affinity.KeyHeader, "session-key-header", "", "Inbound header identifying clients, such as X-API-Key, to keep a backend session per client")
+	serveCmd.Flags().StringSliceVar(&affinity.SessionHeaders, "session-header", nil, "Backend response header carrying a session ID, replayed on the client's next calls (repeatable)")
+	serveCmd.Flags().DurationVar(&affinity.IdleTimeout, "session-idle-timeout", 30*time.Minute, "Time an unused backend session is kept")
+	serveCmd.Flags().IntVar(&batch.Concurrency, "batch-concurrency", 4, "Backend calls made at once for a /_batch request")
+	serveCmd.Flags().IntVar(&batch.MaxItems, "batch-max-items", 100, "Max operation calls in a /_batch request")
+	serveCmd.Flags().Float64Var(&rateLimit, "rate-limit", 0, "Max requests per second across all operations (0 for unlimited)")
+	serveCmd.Flags().IntVar(&rateBurst, "rate-burst", 0, "Burst size for --rate-limit (default: the rate)")
+	serveCmd.Flags().Float64Var(&routeRate, "route-rate-limit", 0, "Max requests per second per operation (0 for unlimited)")
+	serveCmd.Flags().IntVar(&routeBurst, "route-rate-burst", 0, "Burst size for --route-rate-limit (default: the rate)")
+	serveCmd.Flags().IntVar(&maxInFlight, "max-in-flight", 0, "Max concurrent backend SOAP calls (0 for unlimited)")
+	serveCmd.Flags().BoolVar(&serveGraphQL, "graphql", false, "Serve the operations as a GraphQL API at /graphql")
+	serveCmd.Flags().StringVar(&routesFile, "routes", "", "YAML file overriding the REST method and path of operations")
+	serveCmd.Flags().BoolVar(&restVerbs, "rest-verbs", false, "Derive REST methods from operation names: Get*, List*, Find* and Search* use GET, Delete* and Remove* DELETE")
+	serveCmd.Flags().BoolVar(&noValidate, "no-validate", false, "Forward requests without checking them against the WSDL input messages")
+	serveCmd.Flags().BoolVar(&withDocker, "with-docker", false, "Write a Dockerfile and docker-compose.yaml running the proxy with these flags to --docker-dir instead of serving")
+	serveCmd.Flags().BoolVar(&withK8s, "with-k8s", false, "Also write kubernetes.yaml with a Deployment, Service and Ingress of the proxy (implies --with-docker)")
+	serveCmd.Flags().StringVar(&dockerDir, "docker-dir", ".", "Directory --with-docker writes to")
+	serveCmd.Flags().StringVar(&dockerImage, "docker-image", "", "Image name of --with-docker (default: wsdl2api-<service>)")
+	serveCmd.Flags().StringVar(&ingressHost, "ingress-host", "", "Host the Ingress of --with-k8s routes (default: every host)")
+	serveCmd.Flags().StringVar(&dumpDir, "dump-soap", "", "Directory to write the SOAP envelopes of backend calls to, with passwords and tokens redacted")
 	_ = serveCmd.MarkFlagRequired("wsdl")
 
-	// Export command flags
-	exportCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
-	exportCmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory (empty for stdout)")
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "Export format (json or yaml)")
-	exportCmd.Flags().BoolVar(&generateTS, "typescript", false, "Generate TypeScript client")
-	exportCmd.Flags().StringVar(&tsOutputDir, "ts-output", "", "TypeScript output directory (default: <output>/typescript)")
-	_ = exportCmd.MarkFlagRequired("wsdl")
+	// Validate command flags
+	validateCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	validateCmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero on warnings too")
+	_ = validateCmd.MarkFlagRequired("wsdl")
+
+	// Describe command flags
+	describeCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	describeCmd.Flags().StringVarP(&descFormat, "format", "f", "table", "Output format (json or table)")
+	_ = describeCmd.MarkFlagRequired("wsdl")
+
+	// Discover command flags
+	discoverCmd.Flags().StringArrayVar(&mexURLs, "mex", nil, "WS-MetadataExchange endpoint to request the contract from (repeatable)")
+	discoverCmd.Flags().StringVarP(&contractDir, "output", "o", "", "Directory to write each contract to as a single WSDL")
+	discoverCmd.Flags().StringVarP(&listFormat, "format", "f", "table", "Output format (json or table)")
+
+	// Console command flags
+	consoleCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	consoleCmd.Flags().StringVar(&decimalType, "decimal-type", generator.DecimalTypeFloat, "Decimal type used by generated clients; anything but float64 shows xs:decimal values as JSON strings")
+	consoleCmd.Flags().BoolVar(&noValidate, "no-validate", false, "Send input that doesn't match the WSDL input messages")
+	addBackendFlags(consoleCmd.Flags())
+	_ = consoleCmd.MarkFlagRequired("wsdl")
+
+	// Call command flags
+	callCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	callCmd.Flags().StringVar(&opName, "operation", "", "Operation to call (required)")
+	callCmd.Flags().StringVarP(&inputData, "data", "d", "", "Input fields as a JSON object, @file to read them from a file or - from stdin")
+	callCmd.Flags().StringVarP(&outFormat, "format", "f", "json", "Output format: json for the response as JSON, xml for the raw SOAP envelope")
+	callCmd.Flags().StringVar(&decimalType, "decimal-type", generator.DecimalTypeFloat, "Decimal type used by generated clients; anything but float64 prints xs:decimal values as JSON strings")
+	callCmd.Flags().BoolVar(&noValidate, "no-validate", false, "Send input that doesn't match the WSDL input messages")
+	addBackendFlags(callCmd.Flags())
+	_ = callCmd.MarkFlagRequired("wsdl")
+	_ = callCmd.MarkFlagRequired("operation")
+
+	// Bench command flags
+	benchCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	benchCmd.Flags().StringVar(&opName, "operation", "", "Operation to call (required)")
+	benchCmd.Flags().StringVarP(&inputData, "data", "d", "", "Input fields as a JSON object, @file or - for stdin (default: the minimal valid request)")
+	benchCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 10, "Concurrent workers")
+	benchCmd.Flags().DurationVar(&benchTime, "duration", 10*time.Second, "How long to call for (0 for --requests only)")
+	benchCmd.Flags().IntVarP(&maxRequests, "requests", "n", 0, "Stop after this many calls (0 for no limit)")
+	benchCmd.Flags().StringVar(&proxyURL, "proxy", "", "Base URL of the REST API of a running proxy to call instead of the SOAP backend, such as http://localhost:8080/api")
+	benchCmd.Flags().StringVar(&routesFile, "routes", "", "YAML file overriding the REST method and path of operations, as given to serve")
+	benchCmd.Flags().BoolVar(&restVerbs, "rest-verbs", false, "Derive REST methods from operation names, as serve --rest-verbs")
+	benchCmd.Flags().BoolVar(&noValidate, "no-validate", false, "Send input that doesn't match the WSDL input messages")
+	addBackendFlags(benchCmd.Flags())
+	_ = benchCmd.MarkFlagRequired("wsdl")
+	_ = benchCmd.MarkFlagRequired("operation")
+
+	// Build command flags
+	buildCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	buildCmd.Flags().StringVarP(&binaryPath, "output", "o", "proxy", "Binary to write")
+	buildCmd.Flags().StringVar(&soapEndpoint, "soap-endpoint", "", "Default SOAP endpoint of the proxy, its -soap-endpoint flag overrides it (default: the endpoint from the WSDL)")
+	buildCmd.Flags().StringVar(&soapVersion, "soap-version", "", "Default SOAP version of backend calls: \"1.1\" or \"1.2\" (default: from the WSDL binding)")
+	buildCmd.Flags().StringVar(&routesFile, "routes", "", "YAML file overriding the REST method and path of operations, embedded in the binary")
+	buildCmd.Flags().BoolVar(&restVerbs, "rest-verbs", false, "Derive REST methods from operation names, as serve --rest-verbs")
+	buildCmd.Flags().BoolVar(&noValidate, "no-validate", false, "Forward requests without checking them against the WSDL input messages")
+	buildCmd.Flags().BoolVar(&serveGraphQL, "graphql", false, "Also serve the operations as a GraphQL API at /graphql")
+	buildCmd.Flags().StringVar(&decimalType, "decimal-type", generator.DecimalTypeFloat, "Decimal type used by generated clients; anything but float64 returns xs:decimal values as JSON strings")
+	buildCmd.Flags().StringVar(&buildSource, "source", "", "wsdl2api checkout to build with instead of the release of this binary, required for development builds")
+	buildCmd.Flags().StringVar(&buildDir, "build-dir", "", "Keep the Go program of the proxy in this directory (default: a temporary directory)")
+	_ = buildCmd.MarkFlagRequired("wsdl")
+
+	// Test command flags
+	testCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL (required)")
+	testCmd.Flags().StringVar(&soapEndpoint, "endpoint", "", "SOAP endpoint to test (default: the endpoint from the WSDL)")
+	testCmd.Flags().StringSliceVar(&testOps, "operation", nil, "Operations to test (default: all)")
+	testCmd.Flags().StringVar(&junitReport, "junit", "", "Write a JUnit XML report to this file")
+	addBackendFlags(testCmd.Flags())
+	_ = testCmd.Flags().MarkHidden("soap-endpoint")
+	_ = testCmd.MarkFlagRequired("wsdl")
+
+	// Diff command flags
+	diffCmd.Flags().BoolVar(&failBreaking, "fail-on-breaking", false, "Exit non-zero when there are breaking changes")
+
+	// Record command flags
+	recordCmd.Flags().StringVarP(&wsdlPath, "wsdl", "w", "", "WSDL file path or URL whose service endpoint is recorded")
+	recordCmd.Flags().StringVar(&recordTarget, "target", "", "SOAP endpoint to record (default: the endpoint from the WSDL)")
+	recordCmd.Flags().StringVar(&recordDir, "dir", "./recordings", "Directory the recordings are saved in and replayed from")
+	recordCmd.Flags().BoolVar(&replay, "replay", false, "Replay the recordings instead of calling the endpoint")
+	recordCmd.Flags().BoolVar(&strict, "strict", false, "When replaying, answer requests that were not recorded as is with a fault listing their differences")
+	recordCmd.Flags().IntVar(&recordPort, "port", 8081, "Proxy port")
+	recordCmd.Flags().StringVar(&host, "host", "localhost", "Proxy host")
 
 	// Add commands to root
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(serveCmd)
-	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(recordCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(discoverCmd)
+	rootCmd.AddCommand(consoleCmd)
+	rootCmd.AddCommand(callCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(buildCmd)
 }
 
 func main() {
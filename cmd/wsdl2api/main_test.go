@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// calculatorWSDL is a document/literal WSDL with an Add operation
+const calculatorWSDL = "../../examples/calculator.wsdl"
+
+// TestMain runs the command line of the test binary itself when
+// WSDL2API_MAIN is set, for run, and the plugin of TestGeneratePlugin when
+// WSDL2API_PLUGIN is
+func TestMain(m *testing.M) {
+	if os.Getenv("WSDL2API_MAIN") != "" {
+		main()
+		os.Exit(0)
+	}
+	if os.Getenv("WSDL2API_PLUGIN") != "" {
+		os.Exit(plugin())
+	}
+	os.Exit(m.Run())
+}
+
+// run runs the command line args in a new process, with the flags as
+// registered, and returns what it printed to stdout
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "WSDL2API_MAIN=1")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return string(out), fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return string(out), nil
+}
+
+// execute runs the command line args with every flag of its command at
+// its default, and returns what it printed to stdout
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	rootCmd.PersistentFlags().VisitAll(reset)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	w.Close()
+	return <-out, err
+}
+
+func TestCall(t *testing.T) {
+	var request *http.Request
+	var body string
+	status := http.StatusOK
+	response := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <AddResponse xmlns="http://tempuri.org/"><AddResult>3</AddResult></AddResponse>
+</soap:Body></soap:Envelope>`
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		request, body = r, string(data)
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	defer backend.Close()
+
+	call := func(args ...string) (string, error) {
+		return execute(t, append([]string{"call", "--wsdl", calculatorWSDL, "--operation", "Add", "--soap-endpoint", backend.URL}, args...)...)
+	}
+
+	out, err := call("--data", `{"intA": 1, "intB": 2}`, "--backend-auth", "basic", "--backend-user", "user", "--backend-pass", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &result); err != nil || result["AddResult"] != 3.0 {
+		t.Errorf("call printed %s, want the result as JSON", out)
+	}
+	if request.Header.Get("SOAPAction") != `"http://tempuri.org/Add"` || !strings.Contains(body, "<intA>1</intA><intB>2</intB>") {
+		t.Errorf("the backend got SOAPAction %s and\n%s", request.Header.Get("SOAPAction"), body)
+	}
+	if user, pass, ok := request.BasicAuth(); !ok || user != "user" || pass != "secret" {
+		t.Errorf("the backend got credentials %q, %q", user, pass)
+	}
+
+	// SOAP 1.2 and the raw response envelope
+	out, err = call("--data", `{"intA": 1, "intB": 2}`, "--soap-version", "1.2", "--format", "xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != strings.TrimSpace(response)+"\n" {
+		t.Errorf("call --format xml printed %s, want the response envelope", out)
+	}
+	if !strings.HasPrefix(request.Header.Get("Content-Type"), "application/soap+xml") {
+		t.Errorf("SOAP 1.2 call sent Content-Type %s", request.Header.Get("Content-Type"))
+	}
+
+	// Faults are printed and fail the command
+	status = http.StatusInternalServerError
+	response = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>
+  <faultcode>soap:Client</faultcode><faultstring>intA is too large</faultstring>
+</soap:Fault></soap:Body></soap:Envelope>`
+	out, err = call("--data", `{"intA": 1, "intB": 2}`)
+	if err == nil || !strings.Contains(out, `"message": "intA is too large"`) {
+		t.Errorf("call of a fault = %s, %v, want the fault and an error", out, err)
+	}
+
+	// Input that doesn't match the WSDL isn't sent
+	request = nil
+	if _, err := call("--data", `{"intA": "one", "intB": 2}`); err == nil || request != nil {
+		t.Errorf("call sent invalid input: %v", err)
+	}
+	if _, err := call("--data", `{"intA": 1, "intB": 2}`, "--format", "csv"); err == nil {
+		t.Error("call accepted --format csv")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	var contentType string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <AddResponse xmlns="http://tempuri.org/"><AddResult>3</AddResult></AddResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	config := filepath.Join(t.TempDir(), "wsdl2api.yaml")
+	if err := os.WriteFile(config, []byte("soap-endpoint: "+backend.URL+"\nsoap-version: \"1.2\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	call := func(args ...string) error {
+		_, err := execute(t, append([]string{"call", "--config", config, "--wsdl", calculatorWSDL, "--operation", "Add", "--data", `{"intA": 1, "intB": 2}`}, args...)...)
+		return err
+	}
+
+	// The config file sets the flags the command line doesn't
+	contentType = ""
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(contentType, "application/soap+xml") {
+		t.Errorf("call with soap-version 1.2 in the config sent Content-Type %q", contentType)
+	}
+	contentType = ""
+	if err := call("--soap-version", "1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(contentType, "text/xml") {
+		t.Errorf("call with --soap-version 1.1 sent Content-Type %q, want the command line to win", contentType)
+	}
+
+	if err := os.WriteFile(config, []byte("soap-version: \"3\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(); err == nil {
+		t.Error("call accepted soap-version 3 from the config")
+	}
+	if _, err := execute(t, "call", "--config", filepath.Join(t.TempDir(), "missing.yaml"), "--wsdl", calculatorWSDL, "--operation", "Add"); err == nil || !strings.Contains(err.Error(), "failed to read config") {
+		t.Errorf("call with a missing config = %v, want an error", err)
+	}
+}
+
+func TestServeTLS(t *testing.T) {
+	var contentType string
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", "application/soap+xml")
+		w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>
+  <AddResponse xmlns="http://tempuri.org/"><AddResult>3</AddResult></AddResponse>
+</env:Body></env:Envelope>`))
+	}))
+	defer backend.Close()
+
+	// The proxy serves HTTPS with the certificate of the backend, which
+	// the client of the backend trusts
+	dir := t.TempDir()
+	key, err := x509.MarshalPKCS8PrivateKey(backend.TLS.Certificates[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile, config := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "wsdl2api.yaml")
+	for name, data := range map[string][]byte{
+		certFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw}),
+		keyFile:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}),
+		config:   []byte(fmt.Sprintf("soap-endpoint: %s\nsoap-version: \"1.2\"\ntls-cert: %s\ntls-key: %s\nbackend-insecure: true\n", backend.URL, certFile, keyFile)),
+	} {
+		if err := os.WriteFile(name, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	_, port, _ := net.SplitHostPort(addr)
+
+	cmd := exec.Command(os.Args[0], "serve", "--config", config, "--wsdl", calculatorWSDL, "--host", "127.0.0.1", "--port", port)
+	cmd.Env = append(os.Environ(), "WSDL2API_MAIN=1")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	client := backend.Client()
+	var resp *http.Response
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(50 * time.Millisecond) {
+		if resp, err = client.Post("https://"+addr+"/api/Add", "application/json", strings.NewReader(`{"intA": 1, "intB": 2}`)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("POST https://%s/api/Add: %v", addr, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"AddResult":3`) {
+		t.Errorf("POST /api/Add = %d %s, want the result of the backend", resp.StatusCode, body)
+	}
+	if !strings.HasPrefix(contentType, "application/soap+xml") {
+		t.Errorf("the backend got Content-Type %q, want SOAP 1.2 from the config", contentType)
+	}
+
+	// The certificate and key go together
+	if _, err := run(t, "serve", "--wsdl", calculatorWSDL, "--tls-cert", certFile); err == nil || !strings.Contains(err.Error(), "--tls-cert and --tls-key must be set together") {
+		t.Errorf("serve --tls-cert without --tls-key = %v, want an error", err)
+	}
+}
+
+func TestLogFlags(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	for args, want := range map[string]string{
+		"--log-level=loud": "invalid log level",
+		"--log-format=xml": "invalid log format",
+	} {
+		if _, err := execute(t, "validate", "--wsdl", calculatorWSDL, args); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("validate %s = %v, want %q", args, err, want)
+		}
+	}
+
+	// JSON logs at the chosen level
+	if _, err := execute(t, "validate", "--wsdl", calculatorWSDL, "--log-format", "json", "--log-level", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.Default()
+	if _, ok := logger.Handler().(*slog.JSONHandler); !ok || logger.Enabled(context.Background(), slog.LevelInfo) || !logger.Enabled(context.Background(), slog.LevelWarn) {
+		t.Errorf("--log-format json --log-level warn installed %T, info enabled %v", logger.Handler(), logger.Enabled(context.Background(), slog.LevelInfo))
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+// TypeName returns the type of an element declaration. Anonymous types
+// are named after their element.
+func (e Element) TypeName() string {
+	if e.Type == "" {
+		return e.Name
+	}
+	return e.Type
+}
+
+// Element returns the global element named by a qualified or local name,
+// or nil if it isn't declared
+func (d *Definitions) Element(name string) *Element {
+	name = LocalName(name)
+	for i := range d.Elements {
+		if d.Elements[i].Name == name {
+			return &d.Elements[i]
+		}
+	}
+	return nil
+}
+
+// ElementType returns the type of the global element named by a qualified
+// or local name, its name for anonymous types, or "" if it isn't declared
+func (d *Definitions) ElementType(name string) string {
+	if elem := d.Element(name); elem != nil {
+		return elem.TypeName()
+	}
+	return ""
+}
+
+// Message returns the message named by a qualified or local name, or nil
+func (d *Definitions) Message(name string) *Message {
+	name = LocalName(name)
+	if name == "" {
+		return nil
+	}
+	for i := range d.Messages {
+		if LocalName(d.Messages[i].Name) == name {
+			return &d.Messages[i]
+		}
+	}
+	return nil
+}
+
+// SoapAction returns the SOAPAction of an operation, from the first of its
+// bindings that declares one
+func (d *Definitions) SoapAction(operation string) string {
+	for _, binding := range d.Bindings {
+		for _, op := range binding.Operations {
+			if op.Name == operation && op.SoapAction != "" {
+				return op.SoapAction
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package models
+
+import "testing"
+
+// lookupDefinitions declares an operation bound twice, a named and an
+// anonymous element and a prefixed message
+var lookupDefinitions = &Definitions{
+	Elements: []Element{
+		{Name: "GetOrder", Type: "tns:GetOrderType", Documentation: "Gets an order"},
+		{Name: "GetOrderResponse"},
+	},
+	Messages: []Message{
+		{Name: "GetOrderIn"},
+		{Name: "tns:GetOrderOut"},
+	},
+	Bindings: []Binding{
+		{Name: "OrdersHTTP", Operations: []BindingOperation{{Name: "GetOrder"}}},
+		{Name: "OrdersSOAP", Operations: []BindingOperation{{Name: "GetOrder", SoapAction: "urn:orders#GetOrder"}}},
+	},
+}
+
+func TestElementType(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "GetOrder", want: "tns:GetOrderType"},
+		{name: "tns:GetOrder", want: "tns:GetOrderType"},
+		{name: "{urn:orders}GetOrder", want: "tns:GetOrderType"},
+		{name: "GetOrderResponse", want: "GetOrderResponse"},
+		{name: "Missing", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := lookupDefinitions.ElementType(tt.name); got != tt.want {
+			t.Errorf("ElementType(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if elem := lookupDefinitions.Element("tns:GetOrder"); elem == nil || elem.Documentation != "Gets an order" {
+		t.Errorf("Element(tns:GetOrder) = %+v, want the GetOrder declaration", elem)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "GetOrderIn", want: "GetOrderIn"},
+		{name: "tns:GetOrderIn", want: "GetOrderIn"},
+		{name: "GetOrderOut", want: "tns:GetOrderOut"},
+		{name: "Missing", want: ""},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		var got string
+		if msg := lookupDefinitions.Message(tt.name); msg != nil {
+			got = msg.Name
+		}
+		if got != tt.want {
+			t.Errorf("Message(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSoapAction(t *testing.T) {
+	// The binding without a SOAPAction doesn't hide the one that has it
+	if got := lookupDefinitions.SoapAction("GetOrder"); got != "urn:orders#GetOrder" {
+		t.Errorf("SoapAction(GetOrder) = %q, want urn:orders#GetOrder", got)
+	}
+	if got := lookupDefinitions.SoapAction("Missing"); got != "" {
+		t.Errorf("SoapAction(Missing) = %q, want none", got)
+	}
+}
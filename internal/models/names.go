@@ -0,0 +1,15 @@
+package models
+
+import "strings"
+
+// LocalName strips the namespace prefix from a qualified name such as
+// tns:Order, or the namespace from one in {namespace}Order notation
+func LocalName(qname string) string {
+	if i := strings.LastIndex(qname, "}"); i >= 0 {
+		qname = qname[i+1:]
+	}
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
@@ -9,12 +9,15 @@ type Definitions struct {
 	PortTypes       []PortType
 	Messages        []Message
 	Types           []Type
+	SimpleTypes     []SimpleType
+	Elements        []Element // Global elements declared in wsdl:types
 }
 
 // Service represents a WSDL service
 type Service struct {
-	Name  string
-	Ports []Port
+	Name          string
+	Documentation string
+	Ports         []Port
 }
 
 // Port represents a service port
@@ -26,37 +29,63 @@ type Port struct {
 
 // Binding represents a WSDL binding
 type Binding struct {
-	Name       string
-	Type       string
-	Operations []BindingOperation
+	Name        string
+	Type        string
+	Style       string // "document" or "rpc" from soap:binding
+	Transport   string // Transport URI from soap:binding, such as SOAP over HTTP or JMS
+	SOAPVersion string // "1.1" or "1.2" from the namespace of soap:binding, empty for other bindings
+	HTTPVerb    string // "GET" or "POST" from http:binding, empty for SOAP bindings
+	Operations  []BindingOperation
 }
 
 // BindingOperation represents an operation in a binding
 type BindingOperation struct {
-	Name      string
+	Name       string
 	SoapAction string
-	Input     BindingMessage
-	Output    BindingMessage
+	Style      string // Overrides the binding style when set
+	Location   string // Relative URL of the operation in HTTP bindings
+	Input      BindingMessage
+	Output     BindingMessage
 }
 
 // BindingMessage represents input/output binding
 type BindingMessage struct {
-	Use       string
-	Namespace string
+	Use           string // "literal" or "encoded"
+	Namespace     string
+	EncodingStyle string
+	Encoding      string // HTTP bindings: "urlEncoded", "urlReplacement" or a MIME type
 }
 
 // PortType represents a WSDL port type
 type PortType struct {
-	Name       string
-	Operations []Operation
+	Name          string
+	Documentation string
+	Operations    []Operation
 }
 
+// Message exchange patterns of an operation, named after the WSDL 1.1
+// transmission primitives
+const (
+	PatternRequestResponse = "request-response" // Input followed by output
+	PatternOneWay          = "one-way"          // Input only
+	PatternSolicitResponse = "solicit-response" // Output followed by input
+	PatternNotification    = "notification"     // Output only
+)
+
 // Operation represents a WSDL operation
 type Operation struct {
 	Name          string
 	Documentation string
+	Pattern       string // Message exchange pattern, one of the Pattern constants
 	Input         Message
 	Output        Message
+	Faults        []Fault
+}
+
+// Fault represents a wsdl:fault declared by an operation
+type Fault struct {
+	Name    string
+	Message string
 }
 
 // Message represents a WSDL message
@@ -72,25 +101,72 @@ type Part struct {
 	Type    string
 }
 
-// Type represents a WSDL/XSD type
+// Type represents a WSDL/XSD type. Anonymous complex types declared inline
+// on an element are named after that element.
 type Type struct {
-	Name       string
-	Elements   []Element
-	Attributes []Attribute
+	Name          string
+	Namespace     string // Target namespace of the declaring schema
+	Documentation string // xs:documentation of the type
+	Elements      []Element
+	Attributes    []Attribute
+	Choices       []Choice // xs:choice groups referenced by Element.Choice
+	Any           bool     // Content model contains xs:any
+	// SimpleContent is the type of the character data of a type with
+	// simple content, which has attributes but no elements
+	SimpleContent string
+	Mixed         bool // Character data may appear between the elements
+	Abstract      bool // Only types derived from it appear in documents
+	// Base is the complex type this type derives from by Derivation. An
+	// extension holds the elements, choices and attributes of its base
+	// first, followed by its own.
+	Base       string
+	Derivation string
 }
 
-// Element represents an XSD element
-type Element struct {
-	Name      string
-	Type      string
+// Derivations of a complex type from its base
+const (
+	DerivationExtension   = "extension"
+	DerivationRestriction = "restriction"
+)
+
+// Choice represents an xs:choice group. Exactly one of its elements must
+// be present, or at most one when MinOccurs is "0".
+type Choice struct {
 	MinOccurs string
-	MaxOccurs string
-	Nillable  bool
 }
 
-// Attribute represents an XSD attribute
+// SimpleType represents an XSD simple type restriction with its facets
+type SimpleType struct {
+	Name          string
+	Namespace     string
+	Documentation string
+	Base          string
+	Enumeration   []string
+	Pattern       string
+}
+
+// Element represents an XSD element. Namespace is set for global elements
+// and for local elements that are qualified.
+type Element struct {
+	Name          string
+	Namespace     string
+	Documentation string // xs:documentation of the element declaration
+	Type          string
+	MinOccurs     string
+	MaxOccurs     string
+	Nillable      bool
+	Choice        int // 1-based index into Type.Choices, 0 outside a choice
+	// Global elements may substitute the head of the substitution group
+	// they name. Abstract ones only appear through their substitutes.
+	SubstitutionGroup string
+	Abstract          bool
+}
+
+// Attribute represents an XSD attribute. Namespace is set for references
+// to global attributes, which are qualified.
 type Attribute struct {
-	Name string
-	Type string
-	Use  string
+	Name      string
+	Namespace string
+	Type      string
+	Use       string
 }
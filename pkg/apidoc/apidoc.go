@@ -0,0 +1,334 @@
+// Package apidoc builds static documentation of the REST API a WSDL is
+// converted to: its operations grouped by port type, with their routes,
+// SOAP actions, request and response fields, and example calls with curl
+// and the generated Go and TypeScript clients, and the SOAP envelopes the
+// proxy exchanges with the service. Documents render to a self-contained
+// HTML page that can be published without Swagger UI, or to Markdown files
+// committed next to a generated client.
+package apidoc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/typescript"
+)
+
+// DefaultBaseURL is the URL of the REST proxy in examples when Options
+// don't set one: that of wsdl2api serve on its default port
+const DefaultBaseURL = "http://localhost:8080"
+
+// Options tune the examples of a document
+type Options struct {
+	BaseURL string // URL of the REST proxy in curl examples, DefaultBaseURL when empty
+	Package string // Go package of the generated client, "client" when empty
+}
+
+// Document is the documentation of an API
+type Document struct {
+	Title       string
+	Description string
+	Version     string
+	BaseURL     string
+	Endpoint    string // Address of the SOAP service
+	Groups      []Group
+}
+
+// Group is the operations of a port type
+type Group struct {
+	Name        string
+	Description string
+	Operations  []Operation
+}
+
+// Operation documents an operation
+type Operation struct {
+	Name        string // WSDL name
+	ID          string // operationId, the Go method name
+	Anchor      string // Fragment identifier, unique in the document
+	Description string
+	Method      string
+	Path        string
+	SOAPAction  string
+	Parameters  []Field // Path and query parameters
+	Request     []Field // Fields of the JSON request body
+	Response    []Field // Fields of the JSON response
+	Faults      []string
+	Security    []string // Names of the schemes, any of which is accepted
+
+	Curl       string
+	Go         string
+	TypeScript string
+
+	// Example SOAP envelopes the proxy exchanges with the service
+	RequestEnvelope  string
+	ResponseEnvelope string
+}
+
+// Field describes a request or response field. Nested fields have dotted
+// names, with [] marking list items.
+type Field struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+	Depth       int
+}
+
+// New builds the document of spec, the OpenAPI spec exported from def.
+// Operations are in the order of the port types of def.
+func New(def *models.Definitions, spec *exporter.OpenAPISpec, opts Options) *Document {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+	doc := &Document{
+		Title:       spec.Info.Title,
+		Description: spec.Info.Description,
+		Version:     spec.Info.Version,
+		BaseURL:     strings.TrimSuffix(opts.BaseURL, "/"),
+		Endpoint:    endpoint(def),
+	}
+
+	// Spec operations by WSDL name, which is their summary
+	type route struct {
+		method, path string
+		op           *exporter.OpenAPIOperation
+	}
+	routes := make(map[string]route)
+	for path, item := range spec.Paths {
+		for _, m := range item.Operations() {
+			routes[m.Operation.Summary] = route{m.Method, path, m.Operation}
+		}
+	}
+
+	methods := typescript.MethodNames(spec)
+	anchors := make(map[string]bool)
+	for _, pt := range def.PortTypes {
+		group := Group{Name: pt.Name, Description: pt.Documentation}
+		for _, wsdlOp := range pt.Operations {
+			r, ok := routes[wsdlOp.Name]
+			if !ok {
+				continue
+			}
+			op := Operation{
+				Name:        wsdlOp.Name,
+				ID:          r.op.OperationID,
+				Description: r.op.Description,
+				Method:      r.method,
+				Path:        r.path,
+				SOAPAction:  def.SoapAction(wsdlOp.Name),
+				Security:    security(spec, r.op),
+			}
+			op.Anchor = anchor(pt.Name+"-"+op.ID, anchors)
+			for _, fault := range wsdlOp.Faults {
+				op.Faults = append(op.Faults, fault.Name)
+			}
+			for _, param := range r.op.Parameters {
+				op.Parameters = append(op.Parameters, fields(param.Name, param.Schema, param.Required, 0)...)
+			}
+			var request interface{}
+			if r.op.RequestBody != nil {
+				media := r.op.RequestBody.Content["application/json"]
+				op.Request = objectFields(media.Schema, "", 0)
+				request = media.Example
+			}
+			var response interface{}
+			if resp, ok := r.op.Responses["200"]; ok {
+				media := resp.Content["application/json"]
+				op.Response = objectFields(media.Schema, "", 0)
+				response = media.Example
+			}
+			op.RequestEnvelope, op.ResponseEnvelope = envelopes(def, wsdlOp, requestExample(r.op), response)
+
+			op.Curl = curlExample(doc.BaseURL, r.method, r.path, r.op, request, spec)
+			op.TypeScript = typeScriptExample(doc.BaseURL, methods[op.ID], r.op)
+			// The Go client has types for request-response operations only
+			if _, ok := r.op.Responses["200"]; ok && wsdlOp.Input.Name != "" {
+				op.Go = goExample(opts.Package, doc.Endpoint, op, r.op)
+			}
+			group.Operations = append(group.Operations, op)
+		}
+		if len(group.Operations) > 0 {
+			doc.Groups = append(doc.Groups, group)
+		}
+	}
+	return doc
+}
+
+// endpoint returns the address of the first port of def
+func endpoint(def *models.Definitions) string {
+	for _, svc := range def.Services {
+		for _, port := range svc.Ports {
+			if port.Address != "" {
+				return port.Address
+			}
+		}
+	}
+	return ""
+}
+
+// security returns the names of the security schemes of op, its own or
+// else those of the spec
+func security(spec *exporter.OpenAPISpec, op *exporter.OpenAPIOperation) []string {
+	reqs := op.Security
+	if reqs == nil {
+		reqs = spec.Security
+	}
+	var names []string
+	for _, req := range reqs {
+		for name := range req {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// anchor returns a fragment identifier for name not in used
+func anchor(name string, used map[string]bool) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, name)
+	for base, n := id, 2; used[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	used[id] = true
+	return id
+}
+
+// objectFields flattens the properties of an object schema, in name order
+func objectFields(schema *exporter.OpenAPISchema, prefix string, depth int) []Field {
+	if schema == nil {
+		return nil
+	}
+	var out []Field
+	for _, name := range propertyNames(schema) {
+		out = append(out, fields(prefix+name, properties(schema)[name], required(schema, name), depth)...)
+	}
+	return out
+}
+
+// fields returns the field of a schema and its nested fields
+func fields(name string, schema *exporter.OpenAPISchema, isRequired bool, depth int) []Field {
+	if schema == nil {
+		return []Field{{Name: name, Type: "object", Required: isRequired, Depth: depth}}
+	}
+	f := Field{Name: name, Type: typeName(schema), Required: isRequired, Description: schema.Description, Depth: depth}
+	out := []Field{f}
+
+	item := schema
+	if schema.Type == "array" && schema.Items != nil {
+		item = schema.Items
+		name += "[]"
+		if f.Description == "" {
+			out[0].Description = item.Description
+		}
+	}
+	return append(out, objectFields(item, name+".", depth+1)...)
+}
+
+// properties returns the properties of an object schema, merging those of
+// allOf parts and oneOf alternatives
+func properties(schema *exporter.OpenAPISchema) map[string]*exporter.OpenAPISchema {
+	props := make(map[string]*exporter.OpenAPISchema)
+	for _, part := range append(append([]*exporter.OpenAPISchema{}, schema.AllOf...), schema.OneOf...) {
+		for name, prop := range properties(part) {
+			props[name] = prop
+		}
+	}
+	for name, prop := range schema.Properties {
+		props[name] = prop
+	}
+	return props
+}
+
+// propertyNames returns the sorted names of the properties of schema
+func propertyNames(schema *exporter.OpenAPISchema) []string {
+	props := properties(schema)
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// required reports whether a property is required by schema or by all of
+// its oneOf alternatives
+func required(schema *exporter.OpenAPISchema, name string) bool {
+	for _, r := range schema.Required {
+		if r == name {
+			return true
+		}
+	}
+	for _, part := range schema.AllOf {
+		if required(part, name) {
+			return true
+		}
+	}
+	for i, alt := range schema.OneOf {
+		if !required(alt, name) {
+			return false
+		}
+		if i == len(schema.OneOf)-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName describes the type of a schema, as "integer (int32)", "array of
+// string" or "string: open | closed"
+func typeName(schema *exporter.OpenAPISchema) string {
+	if schema.Ref != "" {
+		return schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+	}
+	if len(schema.OneOf) > 0 {
+		var alts []string
+		for _, alt := range schema.OneOf {
+			if schema.Discriminator != nil {
+				if prop := properties(alt)[schema.Discriminator.PropertyName]; prop != nil && len(prop.Enum) == 1 {
+					alts = append(alts, fmt.Sprint(prop.Enum[0]))
+					continue
+				}
+			}
+			alts = append(alts, typeName(alt))
+		}
+		return "one of " + strings.Join(alts, ", ")
+	}
+
+	t := schema.Type
+	if t == "" {
+		t = "object"
+	}
+	switch {
+	case t == "array" && schema.Items != nil:
+		t = "array of " + typeName(schema.Items)
+	case schema.Format != "":
+		t += " (" + schema.Format + ")"
+	}
+	if len(schema.Enum) > 0 {
+		values := make([]string, len(schema.Enum))
+		for i, v := range schema.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		t += ": " + strings.Join(values, " | ")
+	}
+	if schema.Nullable {
+		t += ", nullable"
+	}
+	return t
+}
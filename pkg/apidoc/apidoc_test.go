@@ -0,0 +1,221 @@
+package apidoc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+)
+
+// document builds the document of an order service with a GET route, a
+// fault and an API key
+func document(t *testing.T) *Document {
+	t.Helper()
+	def := &models.Definitions{
+		Name:     "Orders",
+		Services: []models.Service{{Name: "OrderService", Ports: []models.Port{{Name: "OrdersSoap", Binding: "tns:OrdersSoap", Address: "http://erp/orders"}}}},
+		Bindings: []models.Binding{{Name: "OrdersSoap", Operations: []models.BindingOperation{
+			{Name: "PlaceOrder", SoapAction: "urn:erp/PlaceOrder"},
+			{Name: "GetOrder", SoapAction: "urn:erp/GetOrder"},
+		}}},
+		PortTypes: []models.PortType{{Name: "Orders", Documentation: "Order operations", Operations: []models.Operation{
+			{Name: "PlaceOrder", Documentation: "Places an order", Input: models.Message{Name: "tns:PlaceOrderIn"}, Output: models.Message{Name: "tns:PlaceOrderOut"},
+				Faults: []models.Fault{{Name: "OutOfStock", Message: "tns:OutOfStockFault"}}},
+			{Name: "GetOrder", Input: models.Message{Name: "tns:GetOrderIn"}, Output: models.Message{Name: "tns:PlaceOrderOut"}},
+		}}},
+		Messages: []models.Message{
+			{Name: "PlaceOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrder"}}},
+			{Name: "PlaceOrderOut", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrderResponse"}}},
+			{Name: "GetOrderIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "OutOfStockFault", Parts: []models.Part{{Name: "detail", Type: "xs:string"}}},
+		},
+		Types: []models.Type{
+			{Name: "PlaceOrder", Elements: []models.Element{
+				{Name: "customer", Type: "xs:string", Documentation: "Customer <id>"},
+				{Name: "rush", Type: "xs:boolean", MinOccurs: "0"},
+				{Name: "item", Type: "tns:Item", MaxOccurs: "unbounded"},
+			}},
+			{Name: "Item", Elements: []models.Element{{Name: "sku", Type: "xs:string"}, {Name: "status", Type: "tns:Status"}}},
+			{Name: "PlaceOrderResponse", Elements: []models.Element{{Name: "total", Type: "xs:double"}}},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"open", "closed"}}},
+		Elements: []models.Element{
+			{Name: "PlaceOrder", Type: "tns:PlaceOrder"},
+			{Name: "PlaceOrderResponse", Type: "tns:PlaceOrderResponse"},
+		},
+	}
+
+	spec, err := exporter.ConvertWSDLToOpenAPIWithRoutes(def, &routes.Config{Operations: map[string]routes.Route{"GetOrder": {Method: "GET", Path: "/orders/{id}"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheme, err := exporter.ParseSecurityScheme("apikey:X-API-Key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.AddSecurity([]exporter.SecurityScheme{scheme})
+	return New(def, spec, Options{BaseURL: "https://api.example.com/", Package: "erp"})
+}
+
+func TestNew(t *testing.T) {
+	doc := document(t)
+	if doc.Title != "Orders" || doc.BaseURL != "https://api.example.com" || doc.Endpoint != "http://erp/orders" {
+		t.Errorf("document = %+v", doc)
+	}
+	if len(doc.Groups) != 1 || doc.Groups[0].Description != "Order operations" || len(doc.Groups[0].Operations) != 2 {
+		t.Fatalf("groups = %+v", doc.Groups)
+	}
+
+	place := doc.Groups[0].Operations[0]
+	if place.Anchor != "orders-placeorder" || place.SOAPAction != "urn:erp/PlaceOrder" || place.Method != "POST" ||
+		!reflect.DeepEqual(place.Faults, []string{"OutOfStock"}) || !reflect.DeepEqual(place.Security, []string{"apiKeyAuth"}) {
+		t.Errorf("PlaceOrder = %+v", place)
+	}
+	want := []Field{
+		{Name: "customer", Type: "string", Required: true, Description: "Customer <id>"},
+		{Name: "item", Type: "array of object", Required: true},
+		{Name: "item[].sku", Type: "string", Required: true, Depth: 1},
+		{Name: "item[].status", Type: "string: open | closed", Required: true, Depth: 1},
+		{Name: "rush", Type: "boolean"},
+	}
+	if !reflect.DeepEqual(place.Request, want) {
+		t.Errorf("PlaceOrder request = %+v, want %+v", place.Request, want)
+	}
+
+	get := doc.Groups[0].Operations[1]
+	wantCurl := "curl -X GET 'https://api.example.com/api/orders/42' \\\n  -H 'X-API-Key: <api-key>'"
+	if get.Curl != wantCurl {
+		t.Errorf("GetOrder curl = %q, want %q", get.Curl, wantCurl)
+	}
+	if !strings.Contains(place.Curl, `-d '{"customer":"customer","item":[{"sku":"sku","status":"open"}],"rush":true}'`) {
+		t.Errorf("PlaceOrder curl = %s", place.Curl)
+	}
+
+	// Optional and nested fields are left to the reader in Go
+	for _, s := range []string{`erp.NewClient("http://erp/orders")`, `"urn:erp/PlaceOrder", &erp.PlaceOrderRequest{`, `Customer: "customer",`, "// Also: Item, Rush"} {
+		if !strings.Contains(place.Go, s) {
+			t.Errorf("PlaceOrder Go example lacks %s:\n%s", s, place.Go)
+		}
+	}
+	if !strings.Contains(get.TypeScript, "client.getOrder({\n  \"id\": 42\n})") {
+		t.Errorf("GetOrder TypeScript example = %s", get.TypeScript)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	page, err := document(t).HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<a href="#orders-placeorder">PlaceOrder</a>`,
+		`<section class="operation" id="orders-getorder">`,
+		`<span class="method get">GET</span> /api/orders/{id}`,
+		"<p>Places an order</p>",
+		"<td>Customer &lt;id&gt;</td>",
+		"<code>urn:erp/PlaceOrder</code>",
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("page lacks %s", s)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	files, err := document(t).Markdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("files = %v", reflect.ValueOf(files).MapKeys())
+	}
+	for name, want := range map[string][]string{
+		"index.md": {
+			"| [PlaceOrder](orders-placeorder.md) | `POST /api/PlaceOrder` | Places an order |",
+			"| [GetOrder](orders-getorder.md) | `GET /api/orders/{id}` |  |",
+		},
+		"orders-placeorder.md": {
+			"| `customer` | string | yes | Customer &lt;id> |",
+			"| &nbsp;&nbsp;`item[].status` | string: open \\| closed | yes |  |",
+			"- Faults: `OutOfStock`",
+			"<tns:PlaceOrder>\n      <customer>customer</customer>\n      <rush>true</rush>\n      <item>\n        <sku>sku</sku>\n        <status>open</status>\n      </item>\n    </tns:PlaceOrder>",
+		},
+		"orders-getorder.md": {
+			"| `id` | integer (int32) | yes |  |",
+			"<tns:GetOrder>\n      <id>42</id>\n    </tns:GetOrder>",
+			"<tns:PlaceOrderResponse>\n      <total>3.14</total>\n    </tns:PlaceOrderResponse>",
+		},
+	} {
+		for _, s := range want {
+			if !strings.Contains(files[name], s) {
+				t.Errorf("%s lacks %s:\n%s", name, s, files[name])
+			}
+		}
+	}
+}
+
+func TestEnvelopes(t *testing.T) {
+	def := &models.Definitions{
+		TargetNamespace: "urn:erp",
+		Bindings:        []models.Binding{{Name: "Soap12", SOAPVersion: "1.2", Operations: []models.BindingOperation{{Name: "Rate"}}}},
+		PortTypes: []models.PortType{{Name: "Rates", Operations: []models.Operation{
+			{Name: "Rate", Input: models.Message{Name: "tns:RateIn"}, Output: models.Message{Name: "tns:RateOut"}},
+		}}},
+		Messages: []models.Message{
+			{Name: "RateIn", Parts: []models.Part{{Name: "parameters", Element: "tns:Rate"}}},
+			{Name: "RateOut", Parts: []models.Part{{Name: "parameters", Element: "tns:RateResponse"}}},
+		},
+		Types: []models.Type{
+			{Name: "Rate", Elements: []models.Element{
+				{Name: "amount", Namespace: "urn:rates", Type: "tns:Amount"},
+				{Name: "next", Namespace: "urn:rates", Type: "tns:Rate", MinOccurs: "0"},
+				{Name: "byCode", Namespace: "urn:rates", Type: "xs:string", Choice: 1},
+				{Name: "byName", Namespace: "urn:rates", Type: "xs:string", Choice: 1},
+			}},
+			{Name: "Amount", SimpleContent: "xs:decimal", Attributes: []models.Attribute{{Name: "currency", Type: "xs:string"}}},
+			{Name: "RateResponse", Elements: []models.Element{{Name: "rate", Namespace: "urn:rates", Type: "xs:decimal"}}},
+		},
+		Elements: []models.Element{
+			{Name: "Rate", Namespace: "urn:rates", Type: "tns:Rate"},
+			{Name: "RateResponse", Namespace: "urn:rates", Type: "tns:RateResponse"},
+		},
+	}
+	op := def.PortTypes[0].Operations[0]
+
+	// The envelopes carry the JSON examples in the order of the sequences
+	request, response := envelopes(def, op,
+		map[string]interface{}{"byCode": "byCode", "amount": map[string]interface{}{"@currency": "EUR", "#text": 19.99}},
+		map[string]interface{}{"rate": 3.14})
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<soap12:Envelope xmlns:soap12="http://www.w3.org/2003/05/soap-envelope" xmlns:tns="urn:erp">
+  <soap12:Body>
+    <tns:Rate xmlns:tns="urn:rates" xmlns="urn:rates">
+      <amount currency="EUR">19.99</amount>
+      <byCode>byCode</byCode>
+    </tns:Rate>
+  </soap12:Body>
+</soap12:Envelope>`
+	if request != want {
+		t.Errorf("request envelope = %s, want %s", request, want)
+	}
+	want = `<?xml version="1.0" encoding="utf-8"?>
+<soap12:Envelope xmlns:soap12="http://www.w3.org/2003/05/soap-envelope" xmlns:tns="urn:erp">
+  <soap12:Body>
+    <tns:RateResponse xmlns:tns="urn:rates" xmlns="urn:rates">
+      <rate>3.14</rate>
+    </tns:RateResponse>
+  </soap12:Body>
+</soap12:Envelope>`
+	if response != want {
+		t.Errorf("response envelope = %s, want %s", response, want)
+	}
+
+	// One-way operations have no response
+	op.Output = models.Message{}
+	if _, response := envelopes(def, op, nil, nil); response != "" {
+		t.Errorf("one-way response envelope = %s, want none", response)
+	}
+}
@@ -0,0 +1,44 @@
+package apidoc
+
+import (
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// envelopes returns the example request and response envelopes of an
+// operation, empty for messages the operation doesn't have. They carry the
+// JSON examples of the page, request and response, encoded as the proxy
+// encodes the requests it sends, so that the two show the same values.
+func envelopes(def *models.Definitions, op models.Operation, request, response interface{}) (string, string) {
+	enc := sample.Encoder{Def: def, Indent: "  "}
+	version := soapVersion(def, op.Name)
+	envelope := func(output bool, example interface{}) string {
+		fields, _ := example.(map[string]interface{})
+		body, err := enc.Body(op.Name, output, version, fields)
+		if err != nil {
+			return ""
+		}
+		return sample.Envelope(sample.TargetNamespace(def), version, "", body)
+	}
+
+	var requestEnvelope, responseEnvelope string
+	if op.Input.Name != "" {
+		requestEnvelope = envelope(false, request)
+	}
+	if op.Output.Name != "" {
+		responseEnvelope = envelope(true, response)
+	}
+	return requestEnvelope, responseEnvelope
+}
+
+// soapVersion returns the SOAP version of the binding of an operation
+func soapVersion(def *models.Definitions, name string) string {
+	for _, b := range def.Bindings {
+		for _, op := range b.Operations {
+			if op.Name == name && b.HTTPVerb == "" {
+				return b.SOAPVersion
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// curlExample returns the curl command calling an operation through the
+// REST proxy with the example of its request, and the credentials of its
+// first security scheme as placeholders
+func curlExample(baseURL, method, path string, op *exporter.OpenAPIOperation, request interface{}, spec *exporter.OpenAPISpec) string {
+	query := url.Values{}
+	for _, param := range op.Parameters {
+		switch value := param.Example.(type) {
+		case nil, map[string]interface{}:
+		case []interface{}:
+			for _, item := range value {
+				query.Add(param.Name, fmt.Sprint(item))
+			}
+		default:
+			if param.In == "path" {
+				path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(fmt.Sprint(value)))
+			} else {
+				query.Add(param.Name, fmt.Sprint(value))
+			}
+		}
+	}
+
+	var args []string
+	if names := security(spec, op); len(names) > 0 && spec.Components != nil {
+		if scheme := spec.Components.SecuritySchemes[names[0]]; scheme != nil {
+			switch {
+			case scheme.Type == "http" && scheme.Scheme == "basic":
+				args = append(args, "-u '<user>:<password>'")
+			case scheme.Type == "http":
+				args = append(args, "-H 'Authorization: Bearer <token>'")
+			case scheme.In == "header":
+				args = append(args, fmt.Sprintf("-H '%s: <api-key>'", scheme.Name))
+			case scheme.In == "cookie":
+				args = append(args, fmt.Sprintf("-b '%s=<api-key>'", scheme.Name))
+			case scheme.In == "query":
+				query.Add(scheme.Name, "<api-key>")
+			}
+		}
+	}
+
+	u := baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	lines := []string{fmt.Sprintf("curl -X %s %s", method, shellQuote(u))}
+	lines = append(lines, args...)
+	if op.RequestBody != nil {
+		body, _ := json.Marshal(request)
+		if request == nil {
+			body = []byte("{}")
+		}
+		lines = append(lines, "-H 'Content-Type: application/json'", "-d "+shellQuote(string(body)))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// goExample returns the call of an operation with the generated Go client.
+// CallContext takes the request type of any operation, whereas the
+// operators' parameters depend on the operation's style. The literal sets
+// the required fields of basic types; the others, pointers or types of
+// their own, are listed in a comment.
+func goExample(pkg, endpoint string, doc Operation, op *exporter.OpenAPIOperation) string {
+	values := requestExample(op)
+	schema := op.RequestSchema()
+
+	var set, others []string
+	if schema != nil {
+		for _, name := range propertyNames(schema) {
+			field := naming.Pascal(name)
+			if strings.HasPrefix(name, "@") || strings.HasPrefix(name, "#") {
+				others = append(others, field)
+				continue
+			}
+			literal, ok := goLiteral(properties(schema)[name], values[name])
+			if !ok || !required(schema, name) {
+				others = append(others, field)
+				continue
+			}
+			set = append(set, fmt.Sprintf("\t%s: %s,\n", field, literal))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "soapClient := %s.NewClient(%q)\n", pkg, endpoint)
+	b.WriteString("ctx := context.Background()\n\n")
+	fmt.Fprintf(&b, "var response %s.%sResponse\n", pkg, doc.ID)
+	fmt.Fprintf(&b, "err := soapClient.CallContext(ctx, %q, &%s.%sRequest{", doc.SOAPAction, pkg, doc.ID)
+	if len(set) > 0 || len(others) > 0 {
+		b.WriteString("\n")
+		for _, line := range set {
+			b.WriteString(line)
+		}
+		if len(others) > 0 {
+			fmt.Fprintf(&b, "\t// Also: %s\n", strings.Join(others, ", "))
+		}
+	}
+	b.WriteString("}, &response)")
+	return b.String()
+}
+
+// goLiteral returns the Go literal of the example value of a field of a
+// basic type: integers, floats, booleans, and strings other than dates
+func goLiteral(schema *exporter.OpenAPISchema, value interface{}) (string, bool) {
+	if schema == nil || value == nil || schema.Nullable {
+		return "", false
+	}
+	switch v := value.(type) {
+	case string:
+		switch schema.Format {
+		case "date", "date-time", "time", "decimal":
+			return "", false
+		}
+		return strconv.Quote(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, float64:
+		if schema.Format == "decimal" {
+			return "", false
+		}
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// typeScriptExample returns the call of an operation with the generated
+// TypeScript client, whose request holds the body and parameters alike
+func typeScriptExample(baseURL, method string, op *exporter.OpenAPIOperation) string {
+	body, _ := json.MarshalIndent(requestExample(op), "", "  ")
+
+	var b strings.Builder
+	b.WriteString("import { APIClient } from './client';\n\n")
+	fmt.Fprintf(&b, "const client = new APIClient({ baseURL: '%s' });\n", baseURL)
+	fmt.Fprintf(&b, "const response = await client.%s(%s);", method, body)
+	return b.String()
+}
+
+// requestExample returns the example values of the request fields of op,
+// from its body and parameters
+func requestExample(op *exporter.OpenAPIOperation) map[string]interface{} {
+	values := make(map[string]interface{})
+	if op.RequestBody != nil {
+		if obj, ok := op.RequestBody.Content["application/json"].Example.(map[string]interface{}); ok {
+			for name, value := range obj {
+				values[name] = value
+			}
+		}
+	}
+	for _, param := range op.Parameters {
+		if param.Example != nil {
+			values[param.Name] = param.Example
+		}
+	}
+	return values
+}
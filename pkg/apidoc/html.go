@@ -0,0 +1,133 @@
+package apidoc
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+)
+
+// htmlTemplate is a self-contained page: styles are inline and there are
+// no scripts, so the page can be served by any static host
+var htmlTemplate = template.Must(template.New("doc").Funcs(template.FuncMap{
+	"paragraphs": paragraphs,
+	"lower":      strings.ToLower,
+	"indent":     func(depth int) int { return depth * 16 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} API</title>
+<style>
+body { margin: 0; font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; }
+nav { position: fixed; top: 0; bottom: 0; width: 260px; overflow-y: auto; padding: 16px; box-sizing: border-box; background: #f6f8fa; border-right: 1px solid #d0d7de; }
+nav h2 { font-size: 13px; text-transform: uppercase; color: #656d76; margin: 16px 0 4px; }
+nav a { display: block; padding: 2px 0; color: #1f2328; text-decoration: none; font-size: 14px; }
+nav a:hover { color: #0969da; }
+main { margin-left: 260px; padding: 24px 40px; max-width: 960px; }
+section.operation { border-top: 1px solid #d0d7de; padding-top: 8px; margin-top: 32px; }
+.route { font-family: ui-monospace, Menlo, monospace; font-size: 14px; }
+.method { display: inline-block; min-width: 56px; padding: 1px 6px; border-radius: 4px; color: #fff; font-weight: 600; text-align: center; }
+.get { background: #1a7f37; } .post { background: #0969da; } .put { background: #9a6700; } .patch { background: #8250df; } .delete { background: #cf222e; }
+table { border-collapse: collapse; width: 100%; margin: 8px 0 16px; font-size: 14px; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #d8dee4; vertical-align: top; }
+th { background: #f6f8fa; }
+code, pre { font-family: ui-monospace, Menlo, monospace; font-size: 13px; }
+pre { background: #f6f8fa; padding: 12px; border-radius: 6px; overflow-x: auto; }
+dt { font-weight: 600; } dd { margin: 0 0 8px; }
+.required { color: #cf222e; }
+</style>
+</head>
+<body>
+<nav>
+<strong>{{.Title}}</strong>
+{{- range .Groups}}
+<h2>{{.Name}}</h2>
+{{- range .Operations}}
+<a href="#{{.Anchor}}">{{.Name}}</a>
+{{- end}}
+{{- end}}
+</nav>
+<main>
+<h1>{{.Title}} <small>{{.Version}}</small></h1>
+{{paragraphs .Description}}
+<dl>
+<dt>REST API</dt><dd><code>{{.BaseURL}}</code></dd>
+{{- if .Endpoint}}
+<dt>SOAP service</dt><dd><code>{{.Endpoint}}</code></dd>
+{{- end}}
+</dl>
+{{- range .Groups}}
+<h2>{{.Name}}</h2>
+{{paragraphs .Description}}
+{{- range .Operations}}
+<section class="operation" id="{{.Anchor}}">
+<h3>{{.Name}}</h3>
+<p class="route"><span class="method {{lower .Method}}">{{.Method}}</span> {{.Path}}</p>
+{{paragraphs .Description}}
+<dl>
+{{- if .SOAPAction}}
+<dt>SOAP action</dt><dd><code>{{.SOAPAction}}</code></dd>
+{{- end}}
+{{- if .Security}}
+<dt>Security</dt><dd>{{range $i, $s := .Security}}{{if $i}} or {{end}}<code>{{$s}}</code>{{end}}</dd>
+{{- end}}
+{{- if .Faults}}
+<dt>Faults</dt><dd>{{range $i, $f := .Faults}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}; client faults return 400, others 502</dd>
+{{- end}}
+</dl>
+{{- if .Parameters}}
+<h4>Parameters</h4>
+{{template "fields" .Parameters}}
+{{- end}}
+{{- if .Request}}
+<h4>Request body</h4>
+{{template "fields" .Request}}
+{{- end}}
+{{- if .Response}}
+<h4>Response</h4>
+{{template "fields" .Response}}
+{{- end}}
+<h4>curl</h4>
+<pre><code>{{.Curl}}</code></pre>
+{{- if .Go}}
+<h4>Go client</h4>
+<pre><code>{{.Go}}</code></pre>
+{{- end}}
+{{- if .TypeScript}}
+<h4>TypeScript client</h4>
+<pre><code>{{.TypeScript}}</code></pre>
+{{- end}}
+</section>
+{{- end}}
+{{- end}}
+</main>
+</body>
+</html>
+{{define "fields"}}<table>
+<tr><th>Field</th><th>Type</th><th>Description</th></tr>
+{{- range .}}
+<tr><td style="padding-left: {{indent .Depth}}px"><code>{{.Name}}</code>{{if .Required}} <span class="required" title="required">*</span>{{end}}</td><td>{{.Type}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>{{end}}`))
+
+// HTML renders the document as a self-contained HTML page
+func (doc *Document) HTML() (string, error) {
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, doc); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// paragraphs renders documentation, whose paragraphs are separated by
+// blank lines, as HTML paragraphs
+func paragraphs(text string) template.HTML {
+	var b strings.Builder
+	for _, p := range strings.Split(text, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			b.WriteString("<p>" + template.HTMLEscapeString(p) + "</p>\n")
+		}
+	}
+	return template.HTML(b.String())
+}
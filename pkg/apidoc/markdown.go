@@ -0,0 +1,181 @@
+package apidoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// markdownTemplate renders the index and the pages of the operations
+var markdownTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"cell":    cell,
+	"summary": summary,
+	"file":    markdownFile,
+	"join":    strings.Join,
+	"depth":   func(depth int) string { return strings.Repeat("&nbsp;&nbsp;", depth) },
+}).Parse(`# {{.Title}} API
+{{- if .Version}}
+
+Version {{.Version}}
+{{- end}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+
+- REST API: ` + "`{{.BaseURL}}`" + `
+{{- if .Endpoint}}
+- SOAP service: ` + "`{{.Endpoint}}`" + `
+{{- end}}
+{{- range .Groups}}
+
+## {{.Name}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+
+| Operation | Route | Description |
+| --- | --- | --- |
+{{- range .Operations}}
+| [{{.Name}}]({{file .}}) | ` + "`{{.Method}} {{.Path}}`" + ` | {{cell (summary .Description)}} |
+{{- end}}
+{{- end}}
+{{define "operation"}}# {{.Name}}
+
+` + "`{{.Method}} {{.Path}}`" + `
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+{{- if .SOAPAction}}
+
+- SOAP action: ` + "`{{.SOAPAction}}`" + `
+{{- end}}
+{{- if .Security}}
+- Security: {{range $i, $s := .Security}}{{if $i}} or {{end}}` + "`{{$s}}`" + `{{end}}
+{{- end}}
+{{- if .Faults}}
+- Faults: {{range $i, $f := .Faults}}{{if $i}}, {{end}}` + "`{{$f}}`" + `{{end}}; client faults return 400, others 502
+{{- end}}
+{{- if .Parameters}}
+
+## Parameters
+
+{{template "fields" .Parameters}}
+{{- end}}
+{{- if .Request}}
+
+## Request body
+
+{{template "fields" .Request}}
+{{- end}}
+{{- if .Response}}
+
+## Response
+
+{{template "fields" .Response}}
+{{- end}}
+
+## Examples
+
+` + "```sh\n{{.Curl}}\n```" + `
+{{- if .Go}}
+
+Go client:
+
+` + "```go\n{{.Go}}\n```" + `
+{{- end}}
+{{- if .TypeScript}}
+
+TypeScript client:
+
+` + "```ts\n{{.TypeScript}}\n```" + `
+{{- end}}
+{{- if .RequestEnvelope}}
+
+## SOAP request
+
+` + "```xml\n{{.RequestEnvelope}}\n```" + `
+{{- end}}
+{{- if .ResponseEnvelope}}
+
+## SOAP response
+
+` + "```xml\n{{.ResponseEnvelope}}\n```" + `
+{{- end}}
+
+[Back to the index](index.md)
+{{end}}
+{{define "fields"}}| Field | Type | Required | Description |
+| --- | --- | --- | --- |
+{{- range .}}
+| {{depth .Depth}}` + "`{{.Name}}`" + ` | {{cell .Type}} | {{if .Required}}yes{{else}}no{{end}} | {{cell .Description}} |
+{{- end}}{{end}}`))
+
+// Markdown renders the document as Markdown files by name: index.md,
+// listing the operations, and a page per operation with its fields,
+// example calls and SOAP envelopes
+func (doc *Document) Markdown() (map[string]string, error) {
+	files := make(map[string]string)
+	var b bytes.Buffer
+	if err := markdownTemplate.Execute(&b, doc); err != nil {
+		return nil, err
+	}
+	files["index.md"] = b.String()
+	for _, group := range doc.Groups {
+		for _, op := range group.Operations {
+			b.Reset()
+			if err := markdownTemplate.ExecuteTemplate(&b, "operation", op); err != nil {
+				return nil, err
+			}
+			files[markdownFile(op)] = b.String()
+		}
+	}
+	return files, nil
+}
+
+// WriteMarkdown writes the Markdown files of the document to dir, and
+// returns their names
+func (doc *Document) WriteMarkdown(dir string) ([]string, error) {
+	files, err := doc.Markdown()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(files[name]), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// markdownFile returns the name of the page of an operation, made unique
+// by its anchor
+func markdownFile(op Operation) string {
+	return op.Anchor + ".md"
+}
+
+// cell escapes text for a table cell, which holds a single line and where
+// angle brackets would start HTML
+func cell(text string) string {
+	text = strings.NewReplacer("|", `\|`, "<", "&lt;").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// summary returns the first paragraph of documentation
+func summary(text string) string {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n\n")
+	return text
+}
@@ -0,0 +1,276 @@
+// Package bench load tests an operation, on the SOAP backend or on the REST
+// proxy in front of it, and reports latency percentiles and error rates
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/server"
+)
+
+// Call makes one request to the target under load
+type Call func(ctx context.Context) error
+
+// Options are the load to drive
+type Options struct {
+	Concurrency int           // Workers calling in a loop, 1 when not positive
+	Duration    time.Duration // How long to call for
+	Requests    int           // Stop after this many calls, 0 for no limit
+}
+
+// Result summarizes a load test
+type Result struct {
+	Requests   int
+	Errors     int
+	ErrorKinds map[string]int // Number of errors by message
+	Elapsed    time.Duration
+	Latencies  []time.Duration // Of every call, sorted
+}
+
+// Run calls call from opts.Concurrency workers until opts.Duration has
+// elapsed, opts.Requests calls were made, or ctx is done, and measures the
+// latency of every call. Calls in flight when the time is up are canceled
+// and not counted.
+func Run(ctx context.Context, opts Options, call Call) *Result {
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var (
+		mu     sync.Mutex
+		result = &Result{ErrorKinds: make(map[string]int)}
+		issued int
+		wg     sync.WaitGroup
+	)
+	// next reserves a call when the request limit allows one more
+	next := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if opts.Requests > 0 && issued >= opts.Requests {
+			return false
+		}
+		issued++
+		return true
+	}
+
+	start := time.Now()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var latencies []time.Duration
+			errs := make(map[string]int)
+			for ctx.Err() == nil && next() {
+				callStart := time.Now()
+				err := call(ctx)
+				if ctx.Err() != nil {
+					break
+				}
+				latencies = append(latencies, time.Since(callStart))
+				if err != nil {
+					errs[err.Error()]++
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Latencies = append(result.Latencies, latencies...)
+			for kind, n := range errs {
+				result.ErrorKinds[kind] += n
+				result.Errors += n
+			}
+		}()
+	}
+	wg.Wait()
+
+	result.Elapsed = time.Since(start)
+	result.Requests = len(result.Latencies)
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+	return result
+}
+
+// Percentile returns the latency p percent of the calls were faster than
+// or as fast as, using the nearest rank
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(r.Latencies)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(r.Latencies) {
+		rank = len(r.Latencies)
+	}
+	return r.Latencies[rank-1]
+}
+
+// Mean returns the mean latency
+func (r *Result) Mean() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, l := range r.Latencies {
+		total += l
+	}
+	return total / time.Duration(len(r.Latencies))
+}
+
+// Throughput returns the calls made per second
+func (r *Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// ErrorRate returns the share of calls that failed, from 0 to 1
+func (r *Result) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// Write prints the result as a table, followed by the errors by message,
+// the most frequent first
+func (r *Result) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Requests:\t%d in %s\n", r.Requests, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Throughput:\t%.1f/s\n", r.Throughput())
+	fmt.Fprintf(tw, "Errors:\t%d (%.2f%%)\n", r.Errors, 100*r.ErrorRate())
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "Latency\tmin\tmean\tp50\tp90\tp95\tp99\tmax\n")
+	if len(r.Latencies) > 0 {
+		fmt.Fprintf(tw, "\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			round(r.Latencies[0]), round(r.Mean()), round(r.Percentile(50)), round(r.Percentile(90)),
+			round(r.Percentile(95)), round(r.Percentile(99)), round(r.Latencies[len(r.Latencies)-1]))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.ErrorKinds) == 0 {
+		return nil
+	}
+	kinds := make([]string, 0, len(r.ErrorKinds))
+	for kind := range r.ErrorKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if r.ErrorKinds[kinds[i]] != r.ErrorKinds[kinds[j]] {
+			return r.ErrorKinds[kinds[i]] > r.ErrorKinds[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	fmt.Fprintln(w, "\nErrors:")
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "  %6d  %s\n", r.ErrorKinds[kind], kind)
+	}
+	return nil
+}
+
+// round rounds a latency for display
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// SOAP returns a Call invoking an operation on the SOAP backend srv is
+// configured with. SOAP faults count as errors, by fault code.
+func SOAP(srv *server.Server, operation string, params map[string]interface{}) Call {
+	return func(ctx context.Context) error {
+		_, err := srv.Call(ctx, operation, params)
+		var fault *server.Fault
+		if errors.As(err, &fault) {
+			return fmt.Errorf("SOAP fault %s", fault.Code)
+		}
+		return err
+	}
+}
+
+// REST returns a Call invoking an operation through the REST proxy whose
+// API is at baseURL, such as http://localhost:8080/api, on the route of
+// the operation. Path parameters are taken from params; GET and DELETE
+// routes send the other params in the query string, other routes as the
+// JSON body. Responses other than 2xx count as errors, by status.
+func REST(client *http.Client, baseURL string, route routes.Route, params map[string]interface{}) (Call, error) {
+	path := route.Path
+	rest := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		rest[name] = value
+	}
+	for _, name := range route.PathParams() {
+		value, ok := rest[name]
+		if !ok {
+			return nil, fmt.Errorf("path parameter %s is not in the input", name)
+		}
+		path = strings.Replace(path, "{"+name+"}", url.PathEscape(fmt.Sprint(value)), 1)
+		delete(rest, name)
+	}
+	target := strings.TrimSuffix(baseURL, "/") + path
+
+	var body []byte
+	if route.HasBody() {
+		var err error
+		if body, err = json.Marshal(rest); err != nil {
+			return nil, err
+		}
+	} else if len(rest) > 0 {
+		query := url.Values{}
+		for name, value := range rest {
+			query.Set(name, fmt.Sprint(value))
+		}
+		target += "?" + query.Encode()
+	}
+
+	return func(ctx context.Context) error {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, route.Method, target, reader)
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		// Drain the body so the connection is reused
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		return nil
+	}, nil
+}
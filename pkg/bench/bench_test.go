@@ -0,0 +1,112 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thdev01/wsdl2api/pkg/routes"
+)
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int64
+	result := Run(context.Background(), Options{Concurrency: 4, Requests: 100}, func(ctx context.Context) error {
+		if calls.Add(1)%10 == 0 {
+			return errors.New("HTTP 503")
+		}
+		return nil
+	})
+	if result.Requests != 100 || calls.Load() != 100 {
+		t.Fatalf("Run() made %d calls, counted %d, want 100", calls.Load(), result.Requests)
+	}
+	if result.Errors != 10 || result.ErrorKinds["HTTP 503"] != 10 || result.ErrorRate() != 0.1 {
+		t.Errorf("Run() errors = %d %v", result.Errors, result.ErrorKinds)
+	}
+
+	var out strings.Builder
+	if err := result.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Requests:    100 in", "Errors:      10 (10.00%)", "p99", "      10  HTTP 503"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Write() lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	start := time.Now()
+	result = Run(context.Background(), Options{Concurrency: 2, Duration: 50 * time.Millisecond}, func(ctx context.Context) error {
+		select {
+		case <-time.After(5 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	if elapsed := time.Since(start); elapsed > time.Second || result.Requests == 0 {
+		t.Errorf("Run() for 50ms made %d calls in %s", result.Requests, elapsed)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	r := &Result{}
+	for i := 1; i <= 100; i++ {
+		r.Latencies = append(r.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[float64]time.Duration{50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond, 0: time.Millisecond} {
+		if got := r.Percentile(p); got != want {
+			t.Errorf("Percentile(%v) = %s, want %s", p, got, want)
+		}
+	}
+	if r.Mean() != 50500*time.Microsecond {
+		t.Errorf("Mean() = %s", r.Mean())
+	}
+	if (&Result{}).Percentile(50) != 0 {
+		t.Error("Percentile() of no calls is not 0")
+	}
+}
+
+func TestREST(t *testing.T) {
+	var got []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, r.Method+" "+r.URL.String()+" "+string(body))
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer proxy.Close()
+
+	params := map[string]interface{}{"id": "a b", "verbose": true}
+	tests := []struct {
+		route   routes.Route
+		want    string
+		wantErr string
+	}{
+		{routes.Route{Method: "POST", Path: "/Add"}, `POST /api/Add {"id":"a b","verbose":true}`, ""},
+		{routes.Route{Method: "GET", Path: "/users/{id}"}, "GET /api/users/a%20b?verbose=true ", ""},
+		{routes.Route{Method: "DELETE", Path: "/users/{id}"}, "DELETE /api/users/a%20b?verbose=true ", "HTTP 502"},
+	}
+	for _, tt := range tests {
+		got = nil
+		call, err := REST(proxy.Client(), proxy.URL+"/api/", tt.route, params)
+		if err != nil {
+			t.Fatalf("REST(%s) error = %v", tt.route, err)
+		}
+		err = call(context.Background())
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("call of %s: error = %v, want %q", tt.route, err, tt.wantErr)
+		}
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("call of %s = %q, want %q", tt.route, got, tt.want)
+		}
+	}
+
+	if _, err := REST(proxy.Client(), proxy.URL, routes.Route{Method: "GET", Path: "/orders/{orderId}"}, params); err == nil {
+		t.Error("REST() with a missing path parameter succeeded")
+	}
+}
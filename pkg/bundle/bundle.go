@@ -0,0 +1,344 @@
+// Package bundle builds the REST proxy of a WSDL into a self-contained
+// binary. The parsed definitions are embedded in the binary, so it serves
+// without reading or fetching the WSDL, as when the WSDL URL isn't
+// reachable from production.
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/script"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+)
+
+// wsdl2apiModule is the module the proxy program imports the server from
+const wsdl2apiModule = "github.com/thdev01/wsdl2api"
+
+// goVersion is the go directive of the proxy program, the one
+// wsdl2apiModule requires
+const goVersion = "1.23.0"
+
+// Options configure the proxy. The SOAP endpoint and version are defaults
+// the binary's flags override; the other settings are fixed when it is
+// built.
+type Options struct {
+	// Version is the version of wsdl2api to build the proxy with
+	Version string
+	// Source is the directory of a wsdl2api checkout to build the proxy
+	// with instead of Version, for development builds
+	Source string
+	// WSDL is where the definitions were parsed from, noted in main.go
+	WSDL string
+
+	SOAPEndpoint string // Default: the address of the WSDL's first port
+	SOAPVersion  string // "1.1" or "1.2", default from the WSDL binding
+
+	Routes            *routes.Config           // POST /api/{Operation} when nil
+	Transforms        transform.Config         // Response transforms, embedded
+	Scripts           map[string]script.Script // Scripts by operation, embedded
+	DecimalsAsStrings bool
+	SkipValidation    bool
+	GraphQL           bool
+}
+
+// Write writes the Go program of the proxy to dir: go.mod, main.go, the
+// definitions as definitions.json and, with opts.Routes, opts.Transforms
+// and opts.Scripts, routes.yaml, transforms.yaml and scripts.json. It
+// returns the names of the files written.
+func Write(dir string, def *models.Definitions, opts Options) ([]string, error) {
+	if opts.Version == "" && opts.Source == "" {
+		return nil, fmt.Errorf("a wsdl2api version or source directory is required")
+	}
+	if opts.SOAPVersion != "" && opts.SOAPVersion != "1.1" && opts.SOAPVersion != "1.2" {
+		return nil, fmt.Errorf("unsupported SOAP version: %s", opts.SOAPVersion)
+	}
+
+	gomod, err := goMod(opts)
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := json.Marshal(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the definitions: %w", err)
+	}
+	program, err := mainProgram(def, opts)
+	if err != nil {
+		return nil, err
+	}
+	files := []file{
+		{"go.mod", gomod},
+		{"main.go", program},
+		{"definitions.json", definitions},
+	}
+	if opts.Routes != nil {
+		data, err := yaml.Marshal(opts.Routes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the routes: %w", err)
+		}
+		files = append(files, file{"routes.yaml", data})
+	}
+	if len(opts.Transforms) > 0 {
+		data, err := yaml.Marshal(opts.Transforms)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the transforms: %w", err)
+		}
+		files = append(files, file{"transforms.yaml", data})
+	}
+	if len(opts.Scripts) > 0 {
+		data, err := json.Marshal(opts.Scripts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the scripts: %w", err)
+		}
+		files = append(files, file{"scripts.json", data})
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), f.data, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, f.name)
+	}
+	return written, nil
+}
+
+// file is a file of the proxy program
+type file struct {
+	name string
+	data []byte
+}
+
+// Build writes the proxy program to dir, a temporary directory removed
+// afterwards when empty, and compiles it to the output binary with the go
+// command. The target platform is the one of GOOS and GOARCH, as for go
+// build.
+func Build(ctx context.Context, def *models.Definitions, dir, output string, opts Options) error {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("the go command is required to build the proxy: %w", err)
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return err
+	}
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "wsdl2api-build-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+	}
+	if _, err := Write(dir, def, opts); err != nil {
+		return err
+	}
+
+	for _, args := range [][]string{
+		{"mod", "tidy"},
+		{"build", "-trimpath", "-ldflags=-s -w", "-o", output, "."},
+	} {
+		cmd := exec.CommandContext(ctx, goCmd, args...)
+		cmd.Dir = dir
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("go %s failed: %w\n%s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return nil
+}
+
+// goMod returns the go.mod of the proxy program, requiring wsdl2api at
+// opts.Version or replaced by opts.Source
+func goMod(opts Options) ([]byte, error) {
+	f := new(modfile.File)
+	if err := f.AddModuleStmt("proxy"); err != nil {
+		return nil, err
+	}
+	if err := f.AddGoStmt(goVersion); err != nil {
+		return nil, err
+	}
+	version := opts.Version
+	if opts.Source != "" {
+		source, err := filepath.Abs(opts.Source)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(filepath.Join(source, "go.mod")); err != nil {
+			return nil, fmt.Errorf("%s is not a wsdl2api checkout: %w", opts.Source, err)
+		}
+		version = "v0.0.0"
+		if err := f.AddReplace(wsdl2apiModule, "", source, ""); err != nil {
+			return nil, err
+		}
+	}
+	f.AddNewRequire(wsdl2apiModule, version, false)
+	return f.Format()
+}
+
+// mainProgram returns main.go of the proxy program
+func mainProgram(def *models.Definitions, opts Options) ([]byte, error) {
+	name := def.Name
+	if len(def.Services) > 0 {
+		name = def.Services[0].Name
+	}
+	var b bytes.Buffer
+	err := mainTemplate.Execute(&b, struct {
+		Options
+		Name string
+	}{opts, name})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format main.go: %w", err)
+	}
+	return src, nil
+}
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`// Code generated by wsdl2api build{{if .WSDL}} from {{.WSDL}}{{end}}. DO NOT EDIT.
+
+// Command proxy serves the {{.Name}} SOAP service as a REST API, as
+// wsdl2api serve does. The definitions of the WSDL are embedded, so it
+// doesn't need the WSDL to start.
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+{{if .Routes}}
+	"github.com/thdev01/wsdl2api/pkg/routes"
+{{- end}}
+{{- if .Scripts}}
+	"github.com/thdev01/wsdl2api/pkg/script"
+{{- end}}
+	"github.com/thdev01/wsdl2api/pkg/server"
+{{- if .Transforms}}
+	"github.com/thdev01/wsdl2api/pkg/transform"
+{{- end}}
+	"github.com/thdev01/wsdl2api/pkg/wsdl2api"
+)
+
+//go:embed definitions.json
+var definitions []byte
+{{if .Routes}}
+//go:embed routes.yaml
+var routesConfig []byte
+{{end}}
+{{- if .Transforms}}
+//go:embed transforms.yaml
+var transformsConfig []byte
+{{end}}
+{{- if .Scripts}}
+//go:embed scripts.json
+var scriptsConfig []byte
+{{end}}
+func main() {
+	host := flag.String("host", "localhost", "Server host")
+	port := flag.Int("port", 8080, "Server port")
+	soapEndpoint := flag.String("soap-endpoint", {{printf "%q" .SOAPEndpoint}}, "SOAP endpoint, empty for the address in the WSDL")
+	soapVersion := flag.String("soap-version", {{printf "%q" .SOAPVersion}}, "SOAP version of backend calls: 1.1 or 1.2, empty for the version of the WSDL binding")
+	backendAuth := flag.String("backend-auth", "", "Backend authentication: basic, ntlm, wssecurity or wssecurity-digest")
+	backendUser := flag.String("backend-user", "", "Static backend username (inbound Basic credentials are forwarded without one)")
+	backendPass := flag.String("backend-pass", "", "Static backend password (default: $BACKEND_PASS)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file to serve HTTPS")
+	tlsKey := flag.String("tls-key", "", "TLS key file to serve HTTPS")
+	flag.Parse()
+	if *backendPass == "" {
+		*backendPass = os.Getenv("BACKEND_PASS")
+	}
+
+	if err := run(*host, *port, *tlsCert, *tlsKey, wsdl2api.ProxyOptions{
+		SOAPEndpoint: *soapEndpoint,
+		SOAPVersion:  *soapVersion,
+		Credentials:  credentials(*backendAuth, *backendUser, *backendPass),
+	}); err != nil {
+		slog.Error("proxy failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// credentials returns the backend credentials of the flags, none without
+// an authentication mode
+func credentials(mode, username, password string) *server.Credentials {
+	if mode == "" {
+		return nil
+	}
+	return &server.Credentials{Mode: server.CredentialMode(mode), Username: username, Password: password}
+}
+
+func run(host string, port int, tlsCert, tlsKey string, opts wsdl2api.ProxyOptions) error {
+	if (tlsCert == "") != (tlsKey == "") {
+		return fmt.Errorf("-tls-cert and -tls-key must be set together")
+	}
+	var def wsdl2api.Definitions
+	if err := json.Unmarshal(definitions, &def); err != nil {
+		return fmt.Errorf("invalid embedded definitions: %w", err)
+	}
+{{- if .Routes}}
+	cfg, err := routes.Parse(routesConfig)
+	if err != nil {
+		return fmt.Errorf("invalid embedded routes: %w", err)
+	}
+	opts.Routes = cfg
+{{- end}}
+{{- if .Transforms}}
+	transforms, err := transform.Parse(transformsConfig)
+	if err != nil {
+		return fmt.Errorf("invalid embedded transforms: %w", err)
+	}
+	opts.Transforms = transforms
+{{- end}}
+{{- if .Scripts}}
+	var scripts map[string]script.Script
+	if err := json.Unmarshal(scriptsConfig, &scripts); err != nil {
+		return fmt.Errorf("invalid embedded scripts: %w", err)
+	}
+	hooks, err := script.Compile(scripts)
+	if err != nil {
+		return fmt.Errorf("invalid embedded scripts: %w", err)
+	}
+	opts.Scripts = hooks
+{{- end}}
+{{- if .DecimalsAsStrings}}
+	opts.DecimalsAsStrings = true
+{{- end}}
+{{- if .SkipValidation}}
+	opts.SkipValidation = true
+{{- end}}
+{{- if .GraphQL}}
+	opts.GraphQL = true
+{{- end}}
+
+	handler, err := wsdl2api.NewProxy(&def, opts)
+	if err != nil {
+		return err
+	}
+	addr := fmt.Sprintf("%s:%d", host, port)
+	slog.Info("starting REST API server", "service", def.Name, "addr", addr, "tls", tlsCert != "")
+	if tlsCert != "" {
+		return http.ListenAndServeTLS(addr, tlsCert, tlsKey, handler)
+	}
+	return http.ListenAndServe(addr, handler)
+}
+`))
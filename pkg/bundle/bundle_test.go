@@ -0,0 +1,131 @@
+package bundle
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	wsdlparser "github.com/thdev01/wsdl2api/pkg/parser"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/script"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+)
+
+func TestWrite(t *testing.T) {
+	def, err := wsdlparser.NewParser().Parse("../../examples/calculator.wsdl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	written, err := Write(dir, def, Options{
+		Source:       "../..",
+		WSDL:         "calculator.wsdl",
+		SOAPEndpoint: "http://backend.internal/calculator.asmx",
+		Routes:       &routes.Config{Heuristics: true},
+		Transforms:   transform.Config{"Add": {Rename: map[string]string{"AddResult": "sum"}}},
+		Scripts:      map[string]script.Script{"Add": {Name: "add.star", Source: []byte("def request(operation, body):\n    pass\n")}},
+		GraphQL:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go.mod", "main.go", "definitions.json", "routes.yaml", "transforms.yaml", "scripts.json"}; !reflect.DeepEqual(written, want) {
+		t.Errorf("Write() wrote %v, want %v", written, want)
+	}
+
+	// The proxy serves the definitions as parsed
+	data, _ := os.ReadFile(filepath.Join(dir, "definitions.json"))
+	var embedded models.Definitions
+	if err := json.Unmarshal(data, &embedded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&embedded, def) {
+		t.Error("the embedded definitions differ from the parsed ones")
+	}
+
+	data, _ = os.ReadFile(filepath.Join(dir, "routes.yaml"))
+	if cfg, err := routes.Parse(data); err != nil || !cfg.Heuristics {
+		t.Errorf("routes.yaml = %q, %v", data, err)
+	}
+
+	data, _ = os.ReadFile(filepath.Join(dir, "transforms.yaml"))
+	if cfg, err := transform.Parse(data); err != nil || cfg["Add"].Rename["AddResult"] != "sum" {
+		t.Errorf("transforms.yaml = %q, %v", data, err)
+	}
+
+	data, _ = os.ReadFile(filepath.Join(dir, "scripts.json"))
+	var scripts map[string]script.Script
+	if err := json.Unmarshal(data, &scripts); err != nil || scripts["Add"].Name != "add.star" {
+		t.Errorf("scripts.json = %q, %v", data, err)
+	}
+
+	data, _ = os.ReadFile(filepath.Join(dir, "go.mod"))
+	gomod, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, _ := filepath.Abs("../..")
+	if len(gomod.Replace) != 1 || gomod.Replace[0].New.Path != source {
+		t.Errorf("go.mod doesn't replace wsdl2api with the source:\n%s", data)
+	}
+
+	data, _ = os.ReadFile(filepath.Join(dir, "main.go"))
+	f, err := parser.ParseFile(token.NewFileSet(), "main.go", data, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("main.go doesn't parse: %v\n%s", err, data)
+	}
+	var imports []string
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imports = append(imports, path)
+	}
+	if !slices.Contains(imports, "github.com/thdev01/wsdl2api/pkg/routes") || !slices.Contains(imports, "github.com/thdev01/wsdl2api/pkg/transform") || !slices.Contains(imports, "github.com/thdev01/wsdl2api/pkg/script") {
+		t.Errorf("main.go doesn't import the routes, transforms and scripts: %v", imports)
+	}
+	for _, line := range []string{
+		"// Code generated by wsdl2api build from calculator.wsdl. DO NOT EDIT.",
+		`flag.String("soap-endpoint", "http://backend.internal/calculator.asmx",`,
+		"opts.GraphQL = true",
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("main.go lacks %s:\n%s", line, data)
+		}
+	}
+	if strings.Contains(string(data), "SkipValidation") {
+		t.Error("main.go skips validation")
+	}
+}
+
+func TestWriteVersion(t *testing.T) {
+	def := &models.Definitions{Name: "Calculator"}
+	dir := t.TempDir()
+	if _, err := Write(dir, def, Options{Version: "v1.4.0"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.Contains(string(data), "require github.com/thdev01/wsdl2api v1.4.0") || strings.Contains(string(data), "replace") {
+		t.Errorf("go.mod doesn't require the release:\n%s", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "main.go"))
+	if strings.Contains(string(data), "routes") {
+		t.Errorf("main.go uses routes without a config:\n%s", data)
+	}
+
+	if _, err := Write(t.TempDir(), def, Options{}); err == nil {
+		t.Error("Write() without a version or source succeeded")
+	}
+	if _, err := Write(t.TempDir(), def, Options{Version: "v1.4.0", SOAPVersion: "2.0"}); err == nil {
+		t.Error("Write() with SOAP version 2.0 succeeded")
+	}
+}
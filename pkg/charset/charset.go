@@ -0,0 +1,212 @@
+// Package charset converts SOAP responses of legacy services encoded in
+// ISO-8859-1, ISO-8859-15 or windows-1252 to UTF-8, the only encoding
+// encoding/xml reads. It is used by generated clients and by the REST
+// proxy for its backend calls.
+package charset
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// peekSize is the number of bytes read ahead to find the XML declaration
+// and check that a body without a declared charset is UTF-8
+const peekSize = 1024
+
+// declaration matches the encoding of an XML declaration
+var declaration = regexp.MustCompile(`^(?:\xEF\xBB\xBF)?<\?xml[^>]*?\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// windows1252 maps the bytes 0x80-0x9F of windows-1252 to runes. The five
+// bytes it leaves undefined map to the C1 controls, as in ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// latin9 maps the bytes where ISO-8859-15 differs from ISO-8859-1
+var latin9 = map[byte]rune{
+	0xA4: '€', 0xA6: 'Š', 0xA8: 'š', 0xB4: 'Ž', 0xB8: 'ž', 0xBC: 'Œ', 0xBD: 'œ', 0xBE: 'Ÿ',
+}
+
+// table returns the runes of the bytes 0x80-0xFF of a charset, nil for
+// UTF-8 and ASCII, which need no conversion. ISO-8859-1 is read as
+// windows-1252, its superset in practice: services declaring it often
+// send the euro sign or curly quotes of windows-1252.
+func table(label string) (*[128]rune, error) {
+	var t [128]rune
+	for i := range t {
+		t[i] = rune(0x80 + i)
+	}
+	switch strings.ToLower(strings.Trim(strings.TrimSpace(label), `"'`)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return nil, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1", "cp819",
+		"windows-1252", "cp1252", "x-cp1252":
+		copy(t[:32], windows1252[:])
+	case "iso-8859-15", "iso8859-15", "iso_8859-15", "latin-9", "latin9", "l9":
+		for b, r := range latin9 {
+			t[b-0x80] = r
+		}
+	default:
+		return nil, fmt.Errorf("unsupported charset %q (use UTF-8, ISO-8859-1, ISO-8859-15 or windows-1252)", label)
+	}
+	return &t, nil
+}
+
+// Decode replaces the body of a response in another charset than UTF-8
+// with its UTF-8 content. The charset is the charset parameter of the
+// Content-Type, else the encoding of the XML declaration; a body declaring
+// neither that isn't valid UTF-8 is read as windows-1252. The XML
+// declaration and the Content-Type are rewritten to declare UTF-8, and the
+// Content-Length, which no longer applies, is removed. Bodies that need no
+// conversion are left as they are.
+func Decode(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	body, converted, err := newReader(resp.Body, contentType)
+	if err != nil {
+		return err
+	}
+	resp.Body = &decoded{Reader: body, body: resp.Body}
+	if !converted {
+		return nil
+	}
+
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		params["charset"] = "utf-8"
+		resp.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	}
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	return nil
+}
+
+// NewReader returns a reader of the UTF-8 content of r, a message with the
+// Content-Type contentType, converted as Decode does
+func NewReader(r io.Reader, contentType string) (io.Reader, error) {
+	body, _, err := newReader(r, contentType)
+	return body, err
+}
+
+// newReader returns a reader of the UTF-8 content of r, and whether it
+// differs from r
+func newReader(r io.Reader, contentType string) (io.Reader, bool, error) {
+	in := bufio.NewReaderSize(r, peekSize)
+	head, _ := in.Peek(peekSize)
+
+	label := contentTypeCharset(contentType)
+	var declared []int
+	if m := declaration.FindSubmatchIndex(head); m != nil {
+		declared = m[2:4]
+		if label == "" {
+			label = string(head[declared[0]:declared[1]])
+		}
+	}
+	t, err := table(label)
+	if err != nil {
+		return nil, false, err
+	}
+	if label == "" && !validUTF8(head) {
+		t, _ = table("windows-1252")
+	}
+
+	// Declare UTF-8 in place of the encoding the content had
+	var src io.Reader = in
+	rewrite := declared != nil && !isUTF8(string(head[declared[0]:declared[1]]))
+	if rewrite {
+		var b bytes.Buffer
+		b.Write(head[:declared[0]])
+		b.WriteString("UTF-8")
+		b.Write(head[declared[1]:])
+		in.Discard(len(head))
+		src = io.MultiReader(&b, in)
+	}
+	if t == nil {
+		return src, rewrite, nil
+	}
+	return &decoder{r: bufio.NewReader(src), table: t}, true, nil
+}
+
+// contentTypeCharset returns the charset parameter of a Content-Type
+func contentTypeCharset(contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		return params["charset"]
+	}
+	return ""
+}
+
+// isUTF8 reports whether a charset label names UTF-8
+func isUTF8(label string) bool {
+	label = strings.ToLower(label)
+	return label == "utf-8" || label == "utf8"
+}
+
+// validUTF8 reports whether data is valid UTF-8 but for a rune cut at its
+// end
+func validUTF8(data []byte) bool {
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// decoder converts a single-byte charset to UTF-8
+type decoder struct {
+	r       *bufio.Reader
+	table   *[128]rune
+	pending []byte // Encoded rune not yet returned
+	scratch [utf8.UTFMax]byte
+}
+
+// Read implements io.Reader
+func (d *decoder) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			c := copy(p[n:], d.pending)
+			d.pending = d.pending[c:]
+			n += c
+			continue
+		}
+		// Return what is converted rather than wait for more input
+		if n > 0 && d.r.Buffered() == 0 {
+			break
+		}
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if b < utf8.RuneSelf {
+			p[n] = b
+			n++
+			continue
+		}
+		d.pending = utf8.AppendRune(d.scratch[:0], d.table[b-0x80])
+	}
+	return n, nil
+}
+
+// decoded is a converted response body, which closes the body it reads
+// from
+type decoded struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+// Close implements io.Closer
+func (d *decoded) Close() error {
+	return d.body.Close()
+}
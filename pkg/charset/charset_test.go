@@ -0,0 +1,121 @@
+package charset
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// response returns a response with body and Content-Type contentType
+func response(contentType string, body []byte) *http.Response {
+	resp := &http.Response{
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if contentType != "" {
+		resp.Header.Set("Content-Type", contentType)
+	}
+	return resp
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		wantType    string
+	}{
+		{
+			name:        "Content-Type charset",
+			contentType: "text/xml; charset=ISO-8859-1",
+			body:        "<city>S\xe3o Paulo</city>",
+			want:        "<city>São Paulo</city>",
+			wantType:    "text/xml; charset=utf-8",
+		},
+		{
+			name:        "XML declaration",
+			contentType: "text/xml",
+			body:        "<?xml version=\"1.0\" encoding='windows-1252'?><price>\x8010 \x93net\x94</price>",
+			want:        "<?xml version=\"1.0\" encoding='UTF-8'?><price>€10 “net”</price>",
+			wantType:    "text/xml",
+		},
+		{
+			name:        "ISO-8859-15",
+			contentType: `application/soap+xml; charset="iso-8859-15"; action="urn:x"`,
+			body:        "<price>\xa45</price>",
+			want:        "<price>€5</price>",
+			wantType:    `application/soap+xml; action="urn:x"; charset=utf-8`,
+		},
+		{
+			name:        "Content-Type overrides the declaration",
+			contentType: "text/xml; charset=utf-8",
+			body:        "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><city>São Paulo</city>",
+			want:        "<?xml version=\"1.0\" encoding=\"UTF-8\"?><city>São Paulo</city>",
+			wantType:    "text/xml; charset=utf-8",
+		},
+		{
+			name:        "undeclared invalid UTF-8",
+			contentType: "text/xml",
+			body:        "<city>Bras\xedlia</city>",
+			want:        "<city>Brasília</city>",
+			wantType:    "text/xml",
+		},
+		{
+			name:        "UTF-8",
+			contentType: "text/xml; charset=utf-8",
+			body:        "<?xml version=\"1.0\" encoding=\"utf-8\"?><city>São Paulo</city>",
+			want:        "<?xml version=\"1.0\" encoding=\"utf-8\"?><city>São Paulo</city>",
+			wantType:    "text/xml; charset=utf-8",
+		},
+	}
+	for _, tt := range tests {
+		resp := response(tt.contentType, []byte(tt.body))
+		if err := Decode(resp); err != nil {
+			t.Fatalf("%s: Decode() error = %v", tt.name, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil || string(body) != tt.want {
+			t.Errorf("%s: body = %q, %v, want %q", tt.name, body, err, tt.want)
+		}
+		if got := resp.Header.Get("Content-Type"); got != tt.wantType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, got, tt.wantType)
+		}
+		if converted := tt.want != tt.body; converted != (resp.ContentLength < 0) {
+			t.Errorf("%s: ContentLength = %d", tt.name, resp.ContentLength)
+		}
+	}
+
+	if err := Decode(response("text/xml; charset=utf-16", []byte("<a/>"))); err == nil || !strings.Contains(err.Error(), "unsupported charset") {
+		t.Errorf("Decode() of UTF-16 error = %v", err)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	// Long enough to need several reads, with the declaration encoding/xml
+	// would reject without conversion
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="ISO-8859-1"?><names>`)
+	for i := 0; i < 1000; i++ {
+		b.WriteString("<name>Jo\xe3o</name>")
+	}
+	b.WriteString("</names>")
+
+	r, err := NewReader(&b, "text/xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names struct {
+		Name []string `xml:"name"`
+	}
+	if err := xml.NewDecoder(r).Decode(&names); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(names.Name) != 1000 || names.Name[999] != "João" {
+		t.Errorf("names = %d, last %q", len(names.Name), names.Name[len(names.Name)-1])
+	}
+}
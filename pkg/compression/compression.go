@@ -0,0 +1,114 @@
+// Package compression compresses SOAP requests and decompresses responses
+// with the HTTP content codings legacy SOAP stacks support, gzip and
+// deflate. It is used by generated clients and by the REST proxy for its
+// backend calls.
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Content codings
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+)
+
+// AcceptEncoding is the Accept-Encoding header of compressed calls
+const AcceptEncoding = "gzip, deflate"
+
+// Check returns an error unless encoding is a supported content coding or
+// empty, for no compression
+func Check(encoding string) error {
+	switch encoding {
+	case "", Gzip, Deflate:
+		return nil
+	}
+	return fmt.Errorf("unsupported compression: %s (use gzip or deflate)", encoding)
+}
+
+// Compress returns data compressed with encoding. Deflate data is in the
+// zlib format, as HTTP specifies.
+func Compress(encoding string, data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case Gzip:
+		w = gzip.NewWriter(&b)
+	case Deflate:
+		w = zlib.NewWriter(&b)
+	default:
+		return nil, Check(encoding)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decompress replaces the body of a response compressed with gzip or
+// deflate with its decompressed content, and removes the Content-Encoding
+// and Content-Length headers that no longer apply. Deflate bodies may be
+// in the zlib format or raw, as some servers send them. Other responses
+// are left as they are.
+func Decompress(resp *http.Response) error {
+	var body io.Reader
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case Gzip, "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress response: %w", err)
+		}
+		body = r
+	case Deflate:
+		in := bufio.NewReader(resp.Body)
+		header, _ := in.Peek(2)
+		if isZlib(header) {
+			r, err := zlib.NewReader(in)
+			if err != nil {
+				return fmt.Errorf("failed to decompress response: %w", err)
+			}
+			body = r
+		} else {
+			body = flate.NewReader(in)
+		}
+	default:
+		return nil
+	}
+
+	resp.Body = &decompressed{Reader: body, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// isZlib reports whether data starts with a zlib header: the deflate
+// method and a header checksum
+func isZlib(header []byte) bool {
+	return len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decompressed is a decompressed response body, which closes the body it
+// reads from
+type decompressed struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+// Close implements io.Closer
+func (d *decompressed) Close() error {
+	return d.body.Close()
+}
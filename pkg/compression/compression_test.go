@@ -0,0 +1,87 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const envelope = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body/></soap:Envelope>`
+
+// response returns a response with body and Content-Encoding encoding
+func response(encoding string, body []byte) *http.Response {
+	resp := &http.Response{
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if encoding != "" {
+		resp.Header.Set("Content-Encoding", encoding)
+	}
+	return resp
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, encoding := range []string{Gzip, Deflate} {
+		compressed, err := Compress(encoding, []byte(envelope))
+		if err != nil {
+			t.Fatalf("Compress(%s) error = %v", encoding, err)
+		}
+		resp := response(encoding, compressed)
+		if err := Decompress(resp); err != nil {
+			t.Fatalf("Decompress(%s) error = %v", encoding, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil || string(body) != envelope {
+			t.Errorf("%s: body = %q, %v", encoding, body, err)
+		}
+		if resp.Header.Get("Content-Encoding") != "" || resp.ContentLength != -1 {
+			t.Errorf("%s: Content-Encoding %q and length %d kept", encoding, resp.Header.Get("Content-Encoding"), resp.ContentLength)
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	// Raw deflate, as some servers send
+	var raw bytes.Buffer
+	w, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	w.Write([]byte(envelope))
+	w.Close()
+	resp := response(Deflate, raw.Bytes())
+	if err := Decompress(resp); err != nil {
+		t.Fatalf("Decompress() error = %v", err)
+	}
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != envelope {
+		t.Errorf("raw deflate: body = %q, %v", body, err)
+	}
+
+	// Uncompressed responses are left as they are
+	resp = response("", []byte(envelope))
+	if err := Decompress(resp); err != nil {
+		t.Fatalf("Decompress() error = %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != envelope {
+		t.Errorf("identity: body = %q", body)
+	}
+
+	if err := Decompress(response(Gzip, []byte(envelope))); err == nil || !strings.Contains(err.Error(), "decompress") {
+		t.Errorf("Decompress() of invalid gzip error = %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, encoding := range []string{"", Gzip, Deflate} {
+		if err := Check(encoding); err != nil {
+			t.Errorf("Check(%q) error = %v", encoding, err)
+		}
+	}
+	if err := Check("br"); err == nil {
+		t.Error("Check(br) accepted an unsupported coding")
+	}
+	if _, err := Compress("br", nil); err == nil {
+		t.Error("Compress(br) accepted an unsupported coding")
+	}
+}
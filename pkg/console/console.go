@@ -0,0 +1,259 @@
+// Package console is an interactive console to explore and call the
+// operations of a SOAP service from a terminal. It lists the operations of
+// a WSDL, prompts for the fields of their input message, and shows the SOAP
+// envelopes exchanged with the service along with the response as JSON,
+// which helps with legacy services that are poorly documented.
+package console
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/describe"
+	"github.com/thdev01/wsdl2api/pkg/server"
+)
+
+// help lists the commands of the operation prompt
+const help = `Enter an operation number or name to call it, optionally followed by its
+input as a JSON object to skip the prompts:  Add {"intA": 1, "intB": 2}
+  ?     list the operations
+  q     quit`
+
+// Console reads commands from a terminal and calls operations through a
+// server, which builds the SOAP envelopes and parses the responses as the
+// REST proxy does
+type Console struct {
+	desc   *describe.Description
+	server *server.Server
+	in     *bufio.Scanner
+	out    io.Writer
+}
+
+// New creates a console for the operations of def, calling them through
+// srv, which is configured with the endpoint and credentials of the service
+func New(def *models.Definitions, srv *server.Server, in io.Reader, out io.Writer) *Console {
+	return &Console{
+		desc:   describe.Describe(def),
+		server: srv,
+		in:     bufio.NewScanner(in),
+		out:    out,
+	}
+}
+
+// Run lists the operations and calls the ones entered until the input ends
+// or the user quits
+func (c *Console) Run(ctx context.Context) error {
+	name := c.desc.Name
+	if len(c.desc.Services) > 0 {
+		name = c.desc.Services[0].Name
+	}
+	count := fmt.Sprintf("%d operations", len(c.desc.Operations))
+	if len(c.desc.Operations) == 1 {
+		count = "1 operation"
+	}
+	fmt.Fprintf(c.out, "%s: %s\n\n", name, count)
+	c.list()
+	fmt.Fprintln(c.out, "\n"+help)
+
+	for {
+		line, ok := c.prompt("\noperation> ")
+		if !ok {
+			return c.in.Err()
+		}
+		command, body, _ := strings.Cut(line, " ")
+		switch command {
+		case "":
+			continue
+		case "q", "quit", "exit":
+			return nil
+		case "?", "help", "ls", "list":
+			c.list()
+			fmt.Fprintln(c.out, "\n"+help)
+			continue
+		}
+
+		op := c.operation(command)
+		if op == nil {
+			fmt.Fprintf(c.out, "unknown operation %q, enter ? to list them\n", command)
+			continue
+		}
+		var params map[string]interface{}
+		if body = strings.TrimSpace(body); body != "" {
+			if err := json.Unmarshal([]byte(body), &params); err != nil {
+				fmt.Fprintf(c.out, "invalid input, a JSON object is expected: %v\n", err)
+				continue
+			}
+		} else if params, ok = c.params(op); !ok {
+			return c.in.Err()
+		}
+		c.call(ctx, op, params)
+	}
+}
+
+// list prints the operations, numbered, with the shape of their input
+func (c *Console) list() {
+	for i, op := range c.desc.Operations {
+		fmt.Fprintf(c.out, "%3d  %s(%s)\n", i+1, op.Name, inputShape(op))
+		if op.Documentation != "" {
+			fmt.Fprintf(c.out, "     %s\n", firstLine(op.Documentation))
+		}
+	}
+}
+
+// operation finds an operation by number or, ignoring case, by name
+func (c *Console) operation(command string) *describe.Operation {
+	if n, err := strconv.Atoi(command); err == nil {
+		if n < 1 || n > len(c.desc.Operations) {
+			return nil
+		}
+		return &c.desc.Operations[n-1]
+	}
+	for i := range c.desc.Operations {
+		if strings.EqualFold(c.desc.Operations[i].Name, command) {
+			return &c.desc.Operations[i]
+		}
+	}
+	return nil
+}
+
+// params prompts for the input fields of an operation. Empty answers leave
+// fields out. It returns false when the input ends.
+func (c *Console) params(op *describe.Operation) (map[string]interface{}, bool) {
+	params := make(map[string]interface{})
+	for _, f := range inputFields(op) {
+		label := "  " + f.Name + " (" + fieldType(f)
+		if repeated(f) || len(f.Fields) > 0 {
+			label += " as JSON"
+		}
+		if f.MinOccurs == "0" {
+			label += ", optional"
+		}
+		label += "): "
+
+		for {
+			text, ok := c.prompt(label)
+			if !ok {
+				return nil, false
+			}
+			if text == "" {
+				break
+			}
+			value, err := c.value(f, text)
+			if err != nil {
+				fmt.Fprintf(c.out, "  %v\n", err)
+				continue
+			}
+			params[f.Name] = value
+			break
+		}
+	}
+	return params, true
+}
+
+// value converts the text entered for a field: JSON for lists and complex
+// types, and the XSD type of other fields
+func (c *Console) value(f describe.Field, text string) (interface{}, error) {
+	if repeated(f) || len(f.Fields) > 0 || strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		var v interface{}
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		return v, nil
+	}
+	return c.server.ParseValue(f.Type, text)
+}
+
+// call calls an operation and prints the exchange with the service
+func (c *Console) call(ctx context.Context, op *describe.Operation, params map[string]interface{}) {
+	start := time.Now()
+	ex, err := c.server.Call(ctx, op.Name, params)
+	if ex != nil && ex.Request != "" {
+		fmt.Fprintf(c.out, "\n--- request: POST %s\n%s\n", ex.Endpoint, strings.TrimSpace(ex.Request))
+	}
+	if ex != nil && ex.Status != 0 {
+		fmt.Fprintf(c.out, "\n--- response: %d %s in %s\n%s\n", ex.Status, http.StatusText(ex.Status),
+			time.Since(start).Round(time.Millisecond), strings.TrimSpace(string(ex.Response)))
+	}
+
+	var fault *server.Fault
+	switch {
+	case errors.As(err, &fault):
+		data, _ := json.MarshalIndent(fault, "", "  ")
+		fmt.Fprintf(c.out, "\n--- fault\n%s\n", data)
+	case err != nil:
+		fmt.Fprintf(c.out, "\nerror: %v\n", err)
+	default:
+		data, _ := json.MarshalIndent(ex.Result, "", "  ")
+		fmt.Fprintf(c.out, "\n--- result\n%s\n", data)
+	}
+}
+
+// prompt prints a prompt and reads the line entered, returning false when
+// the input ends
+func (c *Console) prompt(prompt string) (string, bool) {
+	fmt.Fprint(c.out, prompt)
+	if !c.in.Scan() {
+		fmt.Fprintln(c.out)
+		return "", false
+	}
+	return strings.TrimSpace(c.in.Text()), true
+}
+
+// inputFields returns the fields entered for an operation: the child
+// elements of a document style input element, or else the message parts,
+// as the REST routes take them
+func inputFields(op *describe.Operation) []describe.Field {
+	if op.Input == nil {
+		return nil
+	}
+	parts := op.Input.Parts
+	if len(parts) == 1 && parts[0].Element != "" {
+		return parts[0].Fields
+	}
+	fields := make([]describe.Field, 0, len(parts))
+	for _, p := range parts {
+		fields = append(fields, describe.Field{Name: p.Name, Type: p.Type, Fields: p.Fields})
+	}
+	return fields
+}
+
+// inputShape formats the input fields of an operation on one line
+func inputShape(op describe.Operation) string {
+	var fields []string
+	for _, f := range inputFields(&op) {
+		fields = append(fields, f.Name+" "+fieldType(f))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// fieldType formats the type of a field, marking lists with []
+func fieldType(f describe.Field) string {
+	t := f.Type
+	if i := strings.LastIndex(t, ":"); i >= 0 {
+		t = t[i+1:]
+	}
+	if repeated(f) {
+		t += "[]"
+	}
+	return t
+}
+
+// repeated reports whether a field is a list
+func repeated(f describe.Field) bool {
+	return f.MaxOccurs != "" && f.MaxOccurs != "0" && f.MaxOccurs != "1"
+}
+
+// firstLine returns the first line of a text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,92 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/pkg/parser"
+	"github.com/thdev01/wsdl2api/pkg/server"
+)
+
+func TestConsole(t *testing.T) {
+	var requests []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+<AddResponse xmlns="http://tempuri.org/"><AddResult>5</AddResult></AddResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def, err := parser.NewParser().Parse("../../examples/calculator.wsdl")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	srv := server.NewServer(def, "", 0)
+	srv.SetSOAPEndpoint(backend.URL)
+
+	// Add by number with prompts, a typo, an invalid integer, then Add by
+	// name with a JSON body
+	input := strings.Join([]string{"1", "2", "x", "3", "Ad", `add {"intA": 4, "intB": 1}`, "q"}, "\n")
+	var out bytes.Buffer
+	if err := New(def, srv, strings.NewReader(input), &out).Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("backend received %d requests, want 2\n%s", len(requests), out.String())
+	}
+	for i, want := range []string{"<intA>2</intA><intB>3</intB>", "<intA>4</intA><intB>1</intB>"} {
+		if !strings.Contains(requests[i], want) {
+			t.Errorf("request %d = %s, want %s", i, requests[i], want)
+		}
+	}
+	for _, want := range []string{
+		"Add(intA int, intB int)",
+		"  intA (int): ",
+		`"x" is not a valid int`,
+		`unknown operation "Ad"`,
+		"--- request: POST " + backend.URL,
+		"--- response: 200 OK",
+		"<AddResult>5</AddResult>",
+		`"AddResult": 5`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConsoleFault(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>
+<faultcode>soap:Client</faultcode><faultstring>Overflow</faultstring>
+</soap:Fault></soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def, err := parser.NewParser().Parse("../../examples/calculator.wsdl")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	srv := server.NewServer(def, "", 0)
+	srv.SetSOAPEndpoint(backend.URL)
+
+	// The input ends without quitting
+	var out bytes.Buffer
+	if err := New(def, srv, strings.NewReader(`Add {"intA": 1, "intB": 2}`), &out).Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, want := range []string{"--- response: 500 Internal Server Error", "--- fault", "Overflow"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q\n%s", want, out.String())
+		}
+	}
+}
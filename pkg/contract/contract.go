@@ -0,0 +1,200 @@
+package contract
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/security"
+	"github.com/thdev01/wsdl2api/pkg/server"
+)
+
+// Result is the outcome of the smoke test of an operation
+type Result struct {
+	Operation string
+	PortType  string
+	Duration  time.Duration
+	Skipped   string // Why the operation was not called
+	Failure   string // Why the test failed: a fault or an invalid response
+	Error     string // Why the call could not be made or answered
+	Exchange  *server.Exchange
+}
+
+// Passed reports whether the operation answered a valid response
+func (r *Result) Passed() bool {
+	return r.Skipped == "" && r.Failure == "" && r.Error == ""
+}
+
+// Report is the outcome of the smoke tests of a service
+type Report struct {
+	Name      string
+	Endpoint  string
+	Timestamp time.Time
+	Duration  time.Duration
+	Results   []Result
+}
+
+// Failed returns the number of operations that failed or errored
+func (r *Report) Failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Failure != "" || res.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Run calls every operation of def on the backend srv is configured with,
+// sending the minimal valid request of its schema, and checks that the
+// response parses and is not a fault. Operations the backend initiates,
+// which have no request, are skipped; so are operations not in only, when
+// it is not empty. Operations are called one after the other.
+func Run(ctx context.Context, def *models.Definitions, srv *server.Server, endpoint string, only []string) *Report {
+	report := &Report{Name: def.Name, Endpoint: endpoint, Timestamp: time.Now()}
+	seen := make(map[string]bool)
+	for _, pt := range def.PortTypes {
+		for _, op := range pt.Operations {
+			// Bindings of several SOAP versions share operations
+			if seen[op.Name] || len(only) > 0 && !slices.Contains(only, op.Name) {
+				continue
+			}
+			seen[op.Name] = true
+			report.Results = append(report.Results, run(ctx, srv, pt.Name, op))
+		}
+	}
+	report.Duration = time.Since(report.Timestamp)
+	return report
+}
+
+// run tests one operation
+func run(ctx context.Context, srv *server.Server, portType string, op models.Operation) Result {
+	res := Result{Operation: op.Name, PortType: portType}
+	if op.Pattern == models.PatternNotification || op.Pattern == models.PatternSolicitResponse {
+		res.Skipped = "the service initiates " + op.Pattern + " operations"
+		return res
+	}
+	request := srv.MinimalRequest(op.Name)
+	if request == nil {
+		res.Skipped = "the input message can't be resolved"
+		return res
+	}
+
+	start := time.Now()
+	ex, err := srv.Call(ctx, op.Name, request)
+	res.Duration, res.Exchange = time.Since(start), ex
+
+	var fault *server.Fault
+	switch {
+	case errors.As(err, &fault):
+		res.Failure = fault.Error()
+	case op.Pattern == models.PatternOneWay && ex != nil && ex.Status >= 200 && ex.Status < 300:
+		// One-way operations answer with an empty 202 Accepted
+	case ex != nil && ex.Status >= http.StatusBadRequest:
+		res.Failure = fmt.Sprintf("HTTP status %d", ex.Status)
+	case err != nil && ex != nil && ex.Status != 0:
+		res.Failure = err.Error()
+	case err != nil:
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// WriteJUnit writes the report as JUnit XML, the format CI servers read
+// test results in. Each operation is a test case of the port type's class;
+// the SOAP envelopes exchanged, with credentials redacted, are its output.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitSuite{
+		Name:      r.Name,
+		Tests:     len(r.Results),
+		Time:      seconds(r.Duration),
+		Timestamp: r.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+		Hostname:  r.Endpoint,
+	}
+	if suite.Name == "" {
+		suite.Name = "wsdl2api"
+	}
+	for _, res := range r.Results {
+		c := junitCase{Name: res.Operation, ClassName: res.PortType, Time: seconds(res.Duration)}
+		switch {
+		case res.Skipped != "":
+			suite.Skipped++
+			c.Skipped = &junitMessage{Message: res.Skipped}
+		case res.Failure != "":
+			suite.Failures++
+			c.Failure = &junitMessage{Message: res.Failure, Type: "failure"}
+		case res.Error != "":
+			suite.Errors++
+			c.Error = &junitMessage{Message: res.Error, Type: "error"}
+		}
+		if ex := res.Exchange; ex != nil && ex.Request != "" {
+			var out strings.Builder
+			fmt.Fprintf(&out, "POST %s\n%s\n", ex.Endpoint, security.Redact([]byte(ex.Request)))
+			if ex.Status != 0 {
+				fmt.Fprintf(&out, "\nHTTP %d\n%s\n", ex.Status, security.Redact(ex.Response))
+			}
+			c.SystemOut = &junitOutput{Text: out.String()}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSuites is the root element of JUnit XML reports
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Hostname  string      `xml:"hostname,attr,omitempty"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *junitMessage `xml:"skipped"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	SystemOut *junitOutput  `xml:"system-out"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// seconds formats a duration as JUnit does
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
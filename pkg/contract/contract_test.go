@@ -0,0 +1,78 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/server"
+)
+
+func TestRun(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/xml")
+		if strings.Contains(string(body), "Divide") {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>Division by zero</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+			return
+		}
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><AddResponse xmlns="urn:calc"><result>84</result></AddResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Calculator",
+		TargetNamespace: "urn:calc",
+		Messages: []models.Message{
+			{Name: "In", Parts: []models.Part{{Name: "a", Type: "xs:int"}, {Name: "b", Type: "xs:int"}}},
+			{Name: "Out", Parts: []models.Part{{Name: "result", Type: "xs:int"}}},
+		},
+		PortTypes: []models.PortType{{Name: "CalculatorSoap", Operations: []models.Operation{
+			{Name: "Add", Pattern: models.PatternRequestResponse, Input: models.Message{Name: "tns:In"}, Output: models.Message{Name: "tns:Out"}},
+			{Name: "Divide", Pattern: models.PatternRequestResponse, Input: models.Message{Name: "tns:In"}, Output: models.Message{Name: "tns:Out"}},
+			{Name: "Overflow", Pattern: models.PatternNotification, Output: models.Message{Name: "tns:Out"}},
+		}}},
+	}
+	srv := server.NewServer(def, "", 0)
+	srv.SetSOAPEndpoint(backend.URL)
+
+	report := Run(context.Background(), def, srv, backend.URL, nil)
+	if len(report.Results) != 3 || report.Failed() != 1 {
+		t.Fatalf("Run() = %+v, want 3 results and 1 failure", report.Results)
+	}
+	if add := report.Results[0]; !add.Passed() || !strings.Contains(add.Exchange.Request, "<a>42</a>") {
+		t.Errorf("Add result = %+v, want it passed with a sample request", add)
+	}
+	if divide := report.Results[1]; divide.Failure != "SOAP fault soap:Server: Division by zero" {
+		t.Errorf("Divide failure = %q", divide.Failure)
+	}
+	if overflow := report.Results[2]; overflow.Skipped == "" || overflow.Exchange != nil {
+		t.Errorf("Overflow result = %+v, want it skipped", overflow)
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuite name="Calculator" tests="3" failures="1" errors="0" skipped="1"`,
+		`<testcase name="Add" classname="CalculatorSoap"`,
+		`<failure message="SOAP fault soap:Server: Division by zero" type="failure"></failure>`,
+		`<skipped message="the service initiates notification operations"></skipped>`,
+		"HTTP 500\n<soap:Envelope",
+	} {
+		if !strings.Contains(junit.String(), want) {
+			t.Errorf("JUnit report lacks %s:\n%s", want, junit.String())
+		}
+	}
+
+	if report := Run(context.Background(), def, srv, backend.URL, []string{"Add"}); len(report.Results) != 1 {
+		t.Errorf("Run() of Add only = %d results", len(report.Results))
+	}
+}
@@ -0,0 +1,699 @@
+// Package deploy writes the files packaging the REST proxy of the serve
+// command as a container: a Dockerfile, a docker-compose.yaml and
+// optionally Kubernetes manifests, so it deploys without hand-written ops
+// files.
+package deploy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/pkg/script"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+)
+
+// Paths the proxy finds its files at in the image
+const (
+	wsdlDir    = "/app/wsdl"
+	configDir  = "/app/config"
+	secretsDir = "/app/secrets"
+)
+
+// secretFlags are the serve flags holding credentials. Their values are
+// not written in the files but supplied when deploying, from environment
+// variables for Docker Compose and from a Secret for Kubernetes.
+var secretFlags = map[string]bool{
+	"backend-pass":        true,
+	"oauth-client-secret": true,
+	"wsdl-auth-pass":      true,
+}
+
+// keyFlags are the serve flags naming private key files, which are mounted
+// into the container rather than copied into the image
+var keyFlags = map[string]bool{
+	"tls-key":     true,
+	"backend-key": true,
+	"wsdl-key":    true,
+}
+
+// fileFlags are the serve flags naming other files, copied into the image
+var fileFlags = map[string]bool{
+	"routes":       true,
+	"tls-cert":     true,
+	"backend-ca":   true,
+	"backend-cert": true,
+	"wsdl-cert":    true,
+}
+
+// Flag is a serve flag and its value, such as soap-version and 1.2
+type Flag struct {
+	Name  string
+	Value string
+}
+
+// Options describe the proxy to package
+type Options struct {
+	// Name names the image and the Kubernetes objects, such as the service
+	Name string
+	// Image is the image name, wsdl2api-<name> by default
+	Image string
+	// Version is the version of wsdl2api the image installs, latest when
+	// empty
+	Version string
+	// WSDLs are the files or URLs the proxy serves. Files are copied into
+	// the image along with the files they import by relative location;
+	// URLs are fetched when the proxy starts.
+	WSDLs []string
+	// Flags are the other serve flags
+	Flags []Flag
+	// Transforms are the response transforms of the config file and
+	// Scripts its scripts by operation name, written to a config file of
+	// their own in the image along with copies of the scripts
+	Transforms transform.Config
+	Scripts    map[string]script.Script
+	// Port is the port the proxy listens on, 8080 when 0
+	Port int
+	// Kubernetes also writes kubernetes.yaml: a Deployment, a Service and
+	// an Ingress routing IngressHost, or every host when empty
+	Kubernetes  bool
+	IngressHost string
+}
+
+// packager holds the files and arguments of the container
+type packager struct {
+	opts    Options
+	name    string            // DNS-1123 label from opts.Name
+	args    []string          // serve arguments baked into the image
+	secrets []Flag            // Secret flags, valued with environment variable names
+	keys    []Flag            // Key flags, valued with their host paths
+	tls     bool              // The proxy serves HTTPS
+	copies  map[string]string // Source file by build context path
+	files   map[string][]byte // Generated file by build context path
+}
+
+// Write writes the Dockerfile, .dockerignore, docker-compose.yaml and, with
+// opts.Kubernetes, kubernetes.yaml to dir, and copies the WSDL files and
+// the files named by flags into wsdl/ and config/ there. It returns the
+// names of the files written, relative to dir.
+func Write(dir string, opts Options) ([]string, error) {
+	if len(opts.WSDLs) == 0 {
+		return nil, fmt.Errorf("a WSDL is required")
+	}
+	if opts.Port == 0 {
+		opts.Port = 8080
+	}
+	p := &packager{opts: opts, name: dnsLabel(opts.Name), copies: make(map[string]string), files: make(map[string][]byte)}
+	if p.name == "" {
+		p.name = "wsdl2api"
+	}
+	if p.opts.Image == "" {
+		p.opts.Image = "wsdl2api-" + p.name
+		if p.name == "wsdl2api" {
+			p.opts.Image = p.name
+		}
+	}
+	if err := p.plan(); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	write := func(name string, data []byte) error {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+		written = append(written, filepath.ToSlash(name))
+		return nil
+	}
+
+	targets := make([]string, 0, len(p.copies)+len(p.files))
+	for target := range p.copies {
+		targets = append(targets, target)
+	}
+	for target := range p.files {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		data, ok := p.files[target]
+		if !ok {
+			var err error
+			if data, err = os.ReadFile(p.copies[target]); err != nil {
+				return nil, fmt.Errorf("failed to copy %s: %w", p.copies[target], err)
+			}
+		}
+		if err := write(target, data); err != nil {
+			return nil, err
+		}
+	}
+
+	compose, err := p.compose()
+	if err != nil {
+		return nil, err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"Dockerfile", p.dockerfile()},
+		{".dockerignore", []byte("*\n!wsdl/\n!config/\n")},
+		{"docker-compose.yaml", compose},
+	}
+	if opts.Kubernetes {
+		manifests, err := p.kubernetes()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"kubernetes.yaml", manifests})
+	}
+	for _, f := range files {
+		if err := write(f.name, f.data); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
+}
+
+// plan sorts the WSDLs and flags into the arguments baked into the image,
+// the secrets, the mounted keys and the files to copy
+func (p *packager) plan() error {
+	// A directory of WSDLs becomes wsdl/, or wsdl/<n>/ when there are more
+	var dirs []string
+	for _, wsdl := range p.opts.WSDLs {
+		if isURL(wsdl) {
+			continue
+		}
+		if dir := filepath.Dir(wsdl); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, wsdl := range p.opts.WSDLs {
+		if isURL(wsdl) {
+			p.args = append(p.args, "--wsdl", wsdl)
+			continue
+		}
+		if _, err := os.Stat(wsdl); err != nil {
+			return fmt.Errorf("failed to read WSDL: %w", err)
+		}
+		target := "wsdl"
+		if len(dirs) > 1 {
+			for i, dir := range dirs {
+				if dir == filepath.Dir(wsdl) {
+					target = fmt.Sprintf("wsdl/%d", i+1)
+				}
+			}
+		}
+		if err := p.copyWSDL(wsdl, target); err != nil {
+			return err
+		}
+		p.args = append(p.args, "--wsdl", wsdlDir+strings.TrimPrefix(target, "wsdl")+"/"+filepath.Base(wsdl))
+	}
+
+	if len(p.opts.Transforms) > 0 || len(p.opts.Scripts) > 0 {
+		if err := p.planConfig(); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range p.opts.Flags {
+		p.tls = p.tls || f.Name == "tls-cert"
+		switch {
+		case secretFlags[f.Name]:
+			p.secrets = append(p.secrets, Flag{Name: f.Name, Value: envName(f.Name)})
+		case keyFlags[f.Name]:
+			path, err := filepath.Abs(f.Value)
+			if err != nil {
+				return err
+			}
+			p.keys = append(p.keys, Flag{Name: f.Name, Value: path})
+		case fileFlags[f.Name]:
+			// Files of the same name get the flag name as a prefix
+			name := filepath.Base(f.Value)
+			if source, ok := p.copies["config/"+name]; ok && source != f.Value || p.files["config/"+name] != nil {
+				name = f.Name + "-" + name
+			}
+			p.copies["config/"+name] = f.Value
+			p.args = append(p.args, "--"+f.Name+"="+configDir+"/"+name)
+		default:
+			p.args = append(p.args, "--"+f.Name+"="+f.Value)
+		}
+	}
+	return nil
+}
+
+// planConfig plans the config file of the transforms and scripts, and the
+// copies of the scripts in config/scripts/
+func (p *packager) planConfig() error {
+	operations := make([]string, 0, len(p.opts.Scripts))
+	for operation := range p.opts.Scripts {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	scripts := make(map[string]string, len(operations))
+	for i, operation := range operations {
+		// Scripts of the same name get their position as a prefix
+		name := filepath.Base(p.opts.Scripts[operation].Name)
+		if _, ok := p.files["config/scripts/"+name]; ok {
+			name = fmt.Sprintf("%d-%s", i+1, name)
+		}
+		p.files["config/scripts/"+name] = p.opts.Scripts[operation].Source
+		scripts[operation] = configDir + "/scripts/" + name
+	}
+
+	var b bytes.Buffer
+	err := encodeYAML(&b, struct {
+		Transforms transform.Config  `yaml:"transforms,omitempty"`
+		Scripts    map[string]string `yaml:"scripts,omitempty"`
+	}{p.opts.Transforms, scripts})
+	if err != nil {
+		return err
+	}
+	p.files["config/wsdl2api.yaml"] = b.Bytes()
+	p.args = append(p.args, "--config="+configDir+"/wsdl2api.yaml")
+	return nil
+}
+
+// copyWSDL plans copying a WSDL file to target along with the files it
+// imports or includes by relative location, which keep their paths
+// relative to it
+func (p *packager) copyWSDL(path, target string) error {
+	root := filepath.Dir(path)
+	pending := []string{path}
+	for len(pending) > 0 {
+		file := pending[0]
+		pending = pending[1:]
+		rel, err := filepath.Rel(root, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s imports %s, which is outside its directory", path, file)
+		}
+		name := target + "/" + filepath.ToSlash(rel)
+		if _, ok := p.copies[name]; ok {
+			continue
+		}
+		p.copies[name] = file
+
+		refs, err := references(file)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			pending = append(pending, filepath.Join(filepath.Dir(file), filepath.FromSlash(ref)))
+		}
+	}
+	return nil
+}
+
+// references returns the relative locations of the files a WSDL or schema
+// file imports, includes or redefines
+func references(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WSDL: %w", err)
+	}
+	var refs []string
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return refs, nil
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "import" && start.Name.Local != "include" && start.Name.Local != "redefine" {
+			continue
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Local != "location" && attr.Name.Local != "schemaLocation" {
+				continue
+			}
+			if location := attr.Value; location != "" && !strings.Contains(location, ":") && !strings.HasPrefix(location, "/") {
+				refs = append(refs, location)
+			}
+		}
+	}
+}
+
+// hasCopies reports whether files are copied or generated to a build
+// context directory
+func (p *packager) hasCopies(dir string) bool {
+	for target := range p.copies {
+		if strings.HasPrefix(target, dir+"/") {
+			return true
+		}
+	}
+	for target := range p.files {
+		if strings.HasPrefix(target, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// keyPath returns the path a key file is mounted at in the container
+func keyPath(key Flag) string {
+	return secretsDir + "/" + key.Name
+}
+
+// dockerfile returns the Dockerfile installing wsdl2api and the files of
+// the proxy in a minimal image
+func (p *packager) dockerfile() []byte {
+	version := p.opts.Version
+	if version == "" {
+		version = "latest"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by wsdl2api. The REST proxy of %s:\n", p.opts.Name)
+	fmt.Fprintf(&b, "#\n#   docker build -t %s .\n", p.opts.Image)
+	if len(p.secrets) > 0 || len(p.keys) > 0 {
+		b.WriteString("#\n# Credentials are not in the image: docker-compose.yaml and kubernetes.yaml\n# supply them.\n")
+	}
+	b.WriteString("FROM golang:1.23-alpine AS build\n")
+	fmt.Fprintf(&b, "RUN CGO_ENABLED=0 go install github.com/thdev01/wsdl2api/cmd/wsdl2api@%s\n\n", version)
+	b.WriteString("FROM alpine:3.20\n")
+	b.WriteString("RUN apk add --no-cache ca-certificates\n")
+	b.WriteString("COPY --from=build /go/bin/wsdl2api /usr/local/bin/wsdl2api\n")
+	if p.hasCopies("wsdl") {
+		fmt.Fprintf(&b, "COPY wsdl/ %s/\n", wsdlDir)
+	}
+	if p.hasCopies("config") {
+		fmt.Fprintf(&b, "COPY config/ %s/\n", configDir)
+	}
+	b.WriteString("WORKDIR /app\n")
+	b.WriteString("USER 65534\n")
+	fmt.Fprintf(&b, "EXPOSE %d\n", p.opts.Port)
+	if p.tls {
+		fmt.Fprintf(&b, "HEALTHCHECK CMD wget -qO- --no-check-certificate https://localhost:%d/health >/dev/null || exit 1\n", p.opts.Port)
+	} else {
+		fmt.Fprintf(&b, "HEALTHCHECK CMD wget -qO- http://localhost:%d/health >/dev/null || exit 1\n", p.opts.Port)
+	}
+	fmt.Fprintf(&b, "ENTRYPOINT %s\n", execForm(p.entrypoint()))
+	fmt.Fprintf(&b, "CMD %s\n", execForm(p.args))
+	return []byte(b.String())
+}
+
+// entrypoint returns the command of the container
+func (p *packager) entrypoint() []string {
+	return []string{"wsdl2api", "serve", "--host", "0.0.0.0", "--port", fmt.Sprint(p.opts.Port)}
+}
+
+// compose returns docker-compose.yaml, building the image and passing the
+// secrets from environment variables
+func (p *packager) compose() ([]byte, error) {
+	type service struct {
+		Build       string   `yaml:"build"`
+		Image       string   `yaml:"image"`
+		Command     []string `yaml:"command,omitempty"`
+		Ports       []string `yaml:"ports"`
+		Volumes     []string `yaml:"volumes,omitempty"`
+		Restart     string   `yaml:"restart"`
+		Environment []string `yaml:"environment,omitempty"`
+	}
+	svc := service{
+		Build:   ".",
+		Image:   p.opts.Image,
+		Ports:   []string{fmt.Sprintf("%d:%d", p.opts.Port, p.opts.Port)},
+		Restart: "unless-stopped",
+	}
+	// The command replaces the CMD of the image, so it repeats it
+	if len(p.secrets) > 0 || len(p.keys) > 0 {
+		for _, arg := range p.args {
+			svc.Command = append(svc.Command, strings.ReplaceAll(arg, "$", "$$"))
+		}
+		for _, secret := range p.secrets {
+			svc.Command = append(svc.Command, fmt.Sprintf("--%s=${%s:?%s is required}", secret.Name, secret.Value, secret.Value))
+		}
+		for _, key := range p.keys {
+			svc.Command = append(svc.Command, "--"+key.Name+"="+keyPath(key))
+			svc.Volumes = append(svc.Volumes, key.Value+":"+keyPath(key)+":ro")
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Generated by wsdl2api. Start the proxy with:\n#\n")
+	prefix := ""
+	for _, secret := range p.secrets {
+		prefix += secret.Value + "=... "
+	}
+	fmt.Fprintf(&b, "#   %sdocker compose up -d\n", prefix)
+	if err := encodeYAML(&b, map[string]interface{}{"services": map[string]service{p.name: svc}}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Kubernetes objects, with the fields the manifests use in their usual
+// order
+type (
+	object struct {
+		APIVersion string      `yaml:"apiVersion"`
+		Kind       string      `yaml:"kind"`
+		Metadata   metadata    `yaml:"metadata"`
+		Spec       interface{} `yaml:"spec"`
+	}
+	metadata struct {
+		Name   string            `yaml:"name,omitempty"`
+		Labels map[string]string `yaml:"labels,omitempty"`
+	}
+	deploymentSpec struct {
+		Replicas int `yaml:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `yaml:"matchLabels"`
+		} `yaml:"selector"`
+		Template struct {
+			Metadata metadata `yaml:"metadata"`
+			Spec     podSpec  `yaml:"spec"`
+		} `yaml:"template"`
+	}
+	podSpec struct {
+		Containers []container `yaml:"containers"`
+		Volumes    []volume    `yaml:"volumes,omitempty"`
+	}
+	container struct {
+		Name           string        `yaml:"name"`
+		Image          string        `yaml:"image"`
+		Args           []string      `yaml:"args,omitempty"`
+		Env            []envVar      `yaml:"env,omitempty"`
+		Ports          []port        `yaml:"ports"`
+		VolumeMounts   []volumeMount `yaml:"volumeMounts,omitempty"`
+		ReadinessProbe probe         `yaml:"readinessProbe"`
+		LivenessProbe  probe         `yaml:"livenessProbe"`
+	}
+	envVar struct {
+		Name      string `yaml:"name"`
+		ValueFrom struct {
+			SecretKeyRef keyRef `yaml:"secretKeyRef"`
+		} `yaml:"valueFrom"`
+	}
+	keyRef struct {
+		Name string `yaml:"name"`
+		Key  string `yaml:"key"`
+	}
+	port struct {
+		Name          string `yaml:"name"`
+		ContainerPort int    `yaml:"containerPort,omitempty"`
+		Port          int    `yaml:"port,omitempty"`
+		TargetPort    string `yaml:"targetPort,omitempty"`
+	}
+	volumeMount struct {
+		Name      string `yaml:"name"`
+		MountPath string `yaml:"mountPath"`
+		ReadOnly  bool   `yaml:"readOnly"`
+	}
+	volume struct {
+		Name   string `yaml:"name"`
+		Secret struct {
+			SecretName string `yaml:"secretName"`
+			Items      []struct {
+				Key  string `yaml:"key"`
+				Path string `yaml:"path"`
+			} `yaml:"items"`
+		} `yaml:"secret"`
+	}
+	probe struct {
+		HTTPGet struct {
+			Path   string `yaml:"path"`
+			Port   string `yaml:"port"`
+			Scheme string `yaml:"scheme,omitempty"`
+		} `yaml:"httpGet"`
+	}
+	serviceSpec struct {
+		Selector map[string]string `yaml:"selector"`
+		Ports    []port            `yaml:"ports"`
+	}
+	ingressSpec struct {
+		Rules []ingressRule `yaml:"rules"`
+	}
+	ingressRule struct {
+		Host string `yaml:"host,omitempty"`
+		HTTP struct {
+			Paths []ingressPath `yaml:"paths"`
+		} `yaml:"http"`
+	}
+	ingressPath struct {
+		Path     string `yaml:"path"`
+		PathType string `yaml:"pathType"`
+		Backend  struct {
+			Service struct {
+				Name string `yaml:"name"`
+				Port struct {
+					Name string `yaml:"name"`
+				} `yaml:"port"`
+			} `yaml:"service"`
+		} `yaml:"backend"`
+	}
+)
+
+// kubernetes returns the Deployment, Service and Ingress of the proxy.
+// Secrets come from the Secret named after the proxy, which the comment
+// at the top tells how to create.
+func (p *packager) kubernetes() ([]byte, error) {
+	labels := map[string]string{"app": p.name}
+	meta := metadata{Name: p.name, Labels: labels}
+	secretName := p.name + "-secrets"
+
+	c := container{
+		Name:  "proxy",
+		Image: p.opts.Image,
+		Ports: []port{{Name: "http", ContainerPort: p.opts.Port}},
+	}
+	c.ReadinessProbe.HTTPGet.Path, c.ReadinessProbe.HTTPGet.Port = "/health", "http"
+	if p.tls {
+		c.ReadinessProbe.HTTPGet.Scheme = "HTTPS"
+	}
+	c.LivenessProbe = c.ReadinessProbe
+	var pod podSpec
+	if len(p.secrets) > 0 || len(p.keys) > 0 {
+		// Kubernetes expands $(VAR) in args, so literal ones are escaped
+		for _, arg := range p.args {
+			c.Args = append(c.Args, strings.ReplaceAll(arg, "$(", "$$("))
+		}
+		for _, secret := range p.secrets {
+			c.Args = append(c.Args, fmt.Sprintf("--%s=$(%s)", secret.Name, secret.Value))
+			env := envVar{Name: secret.Value}
+			env.ValueFrom.SecretKeyRef = keyRef{Name: secretName, Key: secret.Name}
+			c.Env = append(c.Env, env)
+		}
+		if len(p.keys) > 0 {
+			v := volume{Name: "secrets"}
+			v.Secret.SecretName = secretName
+			for _, key := range p.keys {
+				c.Args = append(c.Args, "--"+key.Name+"="+keyPath(key))
+				v.Secret.Items = append(v.Secret.Items, struct {
+					Key  string `yaml:"key"`
+					Path string `yaml:"path"`
+				}{key.Name, key.Name})
+			}
+			c.VolumeMounts = []volumeMount{{Name: "secrets", MountPath: secretsDir, ReadOnly: true}}
+			pod.Volumes = []volume{v}
+		}
+	}
+	pod.Containers = []container{c}
+
+	deployment := deploymentSpec{Replicas: 2}
+	deployment.Selector.MatchLabels = labels
+	deployment.Template.Metadata = metadata{Labels: labels}
+	deployment.Template.Spec = pod
+
+	rule := ingressRule{Host: p.opts.IngressHost}
+	path := ingressPath{Path: "/", PathType: "Prefix"}
+	path.Backend.Service.Name = p.name
+	path.Backend.Service.Port.Name = "http"
+	rule.HTTP.Paths = []ingressPath{path}
+
+	manifests := []object{
+		{APIVersion: "apps/v1", Kind: "Deployment", Metadata: meta, Spec: deployment},
+		{APIVersion: "v1", Kind: "Service", Metadata: meta, Spec: serviceSpec{
+			Selector: labels,
+			Ports:    []port{{Name: "http", Port: 80, TargetPort: "http"}},
+		}},
+		{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Metadata: meta, Spec: ingressSpec{Rules: []ingressRule{rule}}},
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Generated by wsdl2api. Push the image built from the Dockerfile as\n# %s, then:\n#\n", p.opts.Image)
+	if len(p.secrets) > 0 || len(p.keys) > 0 {
+		fmt.Fprintf(&b, "#   kubectl create secret generic %s", secretName)
+		for _, secret := range p.secrets {
+			fmt.Fprintf(&b, " \\\n#     --from-literal=%s=...", secret.Name)
+		}
+		for _, key := range p.keys {
+			fmt.Fprintf(&b, " \\\n#     --from-file=%s=%s", key.Name, key.Value)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("#   kubectl apply -f kubernetes.yaml\n")
+	for i, manifest := range manifests {
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		if err := encodeYAML(&b, manifest); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// encodeYAML writes a YAML document indented by two spaces
+func encodeYAML(b *bytes.Buffer, v interface{}) error {
+	encoder := yaml.NewEncoder(b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// execForm formats a command in the exec form of Dockerfile instructions,
+// a JSON array
+func execForm(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = fmt.Sprintf("%q", arg)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// envName returns the environment variable supplying a secret flag
+func envName(flag string) string {
+	return strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// dnsLabel makes a name a valid Kubernetes object name: lowercase letters,
+// digits and dashes
+func dnsLabel(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	label := b.String()
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+// isURL reports whether a WSDL location is a URL rather than a file
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
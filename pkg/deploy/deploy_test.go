@@ -0,0 +1,185 @@
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/pkg/script"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+)
+
+func TestWrite(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"orders.wsdl":        `<definitions><types><xs:schema><xs:import schemaLocation="xsd/common.xsd"/></xs:schema></types><import location="http://example.com/other.wsdl"/></definitions>`,
+		"xsd/common.xsd":     `<xs:schema><xs:include schemaLocation="types.xsd"/></xs:schema>`,
+		"xsd/types.xsd":      `<xs:schema/>`,
+		"unrelated.wsdl":     `<definitions/>`,
+		"routes.yaml":        "heuristics: true\n",
+		"certs/backend.key":  "key",
+		"certs/backend.cert": "cert",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	written, err := Write(dir, Options{
+		Name:    "Order Service",
+		Version: "v1.4.0",
+		WSDLs:   []string{filepath.Join(src, "orders.wsdl"), "https://example.com/billing?wsdl"},
+		Flags: []Flag{
+			{Name: "soap-version", Value: "1.2"},
+			{Name: "routes", Value: filepath.Join(src, "routes.yaml")},
+			{Name: "backend-pass", Value: "s3cret"},
+			{Name: "backend-cert", Value: filepath.Join(src, "certs/backend.cert")},
+			{Name: "backend-key", Value: filepath.Join(src, "certs/backend.key")},
+			{Name: "dump-soap", Value: "$(pwd)"},
+		},
+		Transforms: transform.Config{"GetOrder": {Drop: []string{"card"}}},
+		Scripts: map[string]script.Script{
+			"GetOrder":           {Name: filepath.Join(src, "scripts/order.star"), Source: []byte("def response(operation, body):\n    pass\n")},
+			script.AllOperations: {Name: filepath.Join(src, "order.star"), Source: []byte("def request(operation, body):\n    pass\n")},
+		},
+		Kubernetes:  true,
+		IngressHost: "orders.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"config/backend.cert", "config/routes.yaml", "config/scripts/2-order.star", "config/scripts/order.star", "config/wsdl2api.yaml", "wsdl/orders.wsdl", "wsdl/xsd/common.xsd", "wsdl/xsd/types.xsd",
+		"Dockerfile", ".dockerignore", "docker-compose.yaml", "kubernetes.yaml",
+	}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("Write() wrote %v, want %v", written, want)
+	}
+
+	var all strings.Builder
+	for _, name := range written {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		all.Write(data)
+	}
+	if strings.Contains(all.String(), "s3cret") || strings.Contains(all.String(), "\nkey") {
+		t.Error("the secrets were written")
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "config/wsdl2api.yaml"))
+	if transforms, err := transform.Load(filepath.Join(dir, "config/wsdl2api.yaml")); err != nil || len(transforms["GetOrder"].Drop) != 1 {
+		t.Errorf("config/wsdl2api.yaml = %s, %v", data, err)
+	}
+	for _, line := range []string{`'*': /app/config/scripts/order.star`, "GetOrder: /app/config/scripts/2-order.star"} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("config/wsdl2api.yaml lacks %s:\n%s", line, data)
+		}
+	}
+
+	dockerfile, _ := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	for _, line := range []string{
+		"RUN CGO_ENABLED=0 go install github.com/thdev01/wsdl2api/cmd/wsdl2api@v1.4.0\n",
+		"COPY wsdl/ /app/wsdl/\nCOPY config/ /app/config/\n",
+		`ENTRYPOINT ["wsdl2api", "serve", "--host", "0.0.0.0", "--port", "8080"]`,
+		`CMD ["--wsdl", "/app/wsdl/orders.wsdl", "--wsdl", "https://example.com/billing?wsdl", "--config=/app/config/wsdl2api.yaml", "--soap-version=1.2", "--routes=/app/config/routes.yaml", "--backend-cert=/app/config/backend.cert", "--dump-soap=$(pwd)"]`,
+	} {
+		if !strings.Contains(string(dockerfile), line) {
+			t.Errorf("Dockerfile lacks %s:\n%s", line, dockerfile)
+		}
+	}
+
+	var compose struct {
+		Services map[string]struct {
+			Image   string
+			Command []string
+			Volumes []string
+		}
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "docker-compose.yaml"))
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		t.Fatalf("docker-compose.yaml: %v", err)
+	}
+	svc := compose.Services["order-service"]
+	if svc.Image != "wsdl2api-order-service" || len(svc.Command) != 11 {
+		t.Fatalf("compose service = %+v", svc)
+	}
+	if got := svc.Command[8:]; !reflect.DeepEqual(got, []string{
+		"--dump-soap=$$(pwd)",
+		"--backend-pass=${BACKEND_PASS:?BACKEND_PASS is required}",
+		"--backend-key=/app/secrets/backend-key",
+	}) {
+		t.Errorf("compose command ends with %q", got)
+	}
+	if len(svc.Volumes) != 1 || svc.Volumes[0] != filepath.Join(src, "certs/backend.key")+":/app/secrets/backend-key:ro" {
+		t.Errorf("compose volumes = %v", svc.Volumes)
+	}
+
+	data, _ = os.ReadFile(filepath.Join(dir, "kubernetes.yaml"))
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var kinds []string
+	for {
+		var manifest struct {
+			Kind string
+			Spec struct {
+				Template struct {
+					Spec struct {
+						Containers []struct {
+							Args []string
+							Env  []struct {
+								Name      string
+								ValueFrom struct {
+									SecretKeyRef struct{ Name, Key string } `yaml:"secretKeyRef"`
+								} `yaml:"valueFrom"`
+							}
+						}
+					}
+				}
+				Rules []struct{ Host string }
+			}
+		}
+		if err := decoder.Decode(&manifest); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("kubernetes.yaml: %v", err)
+		}
+		kinds = append(kinds, manifest.Kind)
+		switch manifest.Kind {
+		case "Deployment":
+			c := manifest.Spec.Template.Spec.Containers[0]
+			if c.Args[8] != "--dump-soap=$$(pwd)" || c.Args[9] != "--backend-pass=$(BACKEND_PASS)" {
+				t.Errorf("Deployment args = %q", c.Args)
+			}
+			if len(c.Env) != 1 || c.Env[0].Name != "BACKEND_PASS" || c.Env[0].ValueFrom.SecretKeyRef.Name != "order-service-secrets" {
+				t.Errorf("Deployment env = %+v", c.Env)
+			}
+		case "Ingress":
+			if manifest.Spec.Rules[0].Host != "orders.example.com" {
+				t.Errorf("Ingress rules = %+v", manifest.Spec.Rules)
+			}
+		}
+	}
+	if !reflect.DeepEqual(kinds, []string{"Deployment", "Service", "Ingress"}) {
+		t.Errorf("kubernetes.yaml has %v", kinds)
+	}
+}
+
+func TestWriteOutsideImport(t *testing.T) {
+	src := t.TempDir()
+	wsdl := filepath.Join(src, "service", "service.wsdl")
+	os.MkdirAll(filepath.Dir(wsdl), 0755)
+	os.WriteFile(wsdl, []byte(`<definitions><import location="../shared/types.wsdl"/></definitions>`), 0644)
+
+	if _, err := Write(t.TempDir(), Options{WSDLs: []string{wsdl}}); err == nil || !strings.Contains(err.Error(), "outside its directory") {
+		t.Errorf("Write() error = %v, want the import outside the directory", err)
+	}
+}
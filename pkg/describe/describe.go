@@ -0,0 +1,305 @@
+package describe
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Description is a summary of parsed WSDL definitions meant for scripting
+// and review
+type Description struct {
+	Name            string      `json:"name,omitempty"`
+	TargetNamespace string      `json:"targetNamespace"`
+	Services        []Service   `json:"services"`
+	Operations      []Operation `json:"operations"`
+}
+
+// Service describes a service and its ports
+type Service struct {
+	Name  string `json:"name"`
+	Ports []Port `json:"ports"`
+}
+
+// Port describes an endpoint and its binding
+type Port struct {
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	Binding     string `json:"binding"`
+	Style       string `json:"style,omitempty"`
+	Transport   string `json:"transport,omitempty"`
+	SOAPVersion string `json:"soapVersion,omitempty"`
+}
+
+// Operation describes a port type operation and how it is bound
+type Operation struct {
+	Name          string             `json:"name"`
+	PortType      string             `json:"portType"`
+	Pattern       string             `json:"pattern,omitempty"`
+	Documentation string             `json:"documentation,omitempty"`
+	Bindings      []OperationBinding `json:"bindings,omitempty"`
+	Input         *Message           `json:"input,omitempty"`
+	Output        *Message           `json:"output,omitempty"`
+	Faults        []Fault            `json:"faults,omitempty"`
+}
+
+// OperationBinding describes how a binding encodes an operation
+type OperationBinding struct {
+	Binding    string `json:"binding"`
+	SOAPAction string `json:"soapAction"`
+	Style      string `json:"style"`
+	Use        string `json:"use"`
+}
+
+// Fault describes a fault declared by an operation
+type Fault struct {
+	Name    string   `json:"name"`
+	Message *Message `json:"message,omitempty"`
+}
+
+// Message describes the shape of a message
+type Message struct {
+	Name  string `json:"name"`
+	Parts []Part `json:"parts"`
+}
+
+// Part describes a message part and the fields of its element or type
+type Part struct {
+	Name    string  `json:"name"`
+	Element string  `json:"element,omitempty"`
+	Type    string  `json:"type,omitempty"`
+	Fields  []Field `json:"fields,omitempty"`
+}
+
+// Field describes an element or attribute of a complex type. Fields of a
+// recursive type are not repeated below the first occurrence.
+type Field struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	MinOccurs string  `json:"minOccurs,omitempty"`
+	MaxOccurs string  `json:"maxOccurs,omitempty"`
+	Attribute bool    `json:"attribute,omitempty"`
+	Fields    []Field `json:"fields,omitempty"`
+}
+
+// describer holds the lookups used to describe definitions
+type describer struct {
+	def      *models.Definitions
+	visiting map[string]bool
+}
+
+// Describe summarizes WSDL definitions
+func Describe(def *models.Definitions) *Description {
+	d := &describer{def: def, visiting: make(map[string]bool)}
+	desc := &Description{
+		Name:            def.Name,
+		TargetNamespace: def.TargetNamespace,
+		Services:        []Service{},
+		Operations:      []Operation{},
+	}
+
+	for _, svc := range def.Services {
+		service := Service{Name: svc.Name, Ports: []Port{}}
+		for _, p := range svc.Ports {
+			port := Port{Name: p.Name, Address: p.Address, Binding: models.LocalName(p.Binding)}
+			if b := d.binding(p.Binding); b != nil {
+				port.Style = styleOrDefault(b.Style)
+				port.Transport = b.Transport
+				port.SOAPVersion = b.SOAPVersion
+			}
+			service.Ports = append(service.Ports, port)
+		}
+		desc.Services = append(desc.Services, service)
+	}
+
+	for _, pt := range def.PortTypes {
+		for _, op := range pt.Operations {
+			operation := Operation{
+				Name:          op.Name,
+				PortType:      pt.Name,
+				Pattern:       op.Pattern,
+				Documentation: strings.TrimSpace(op.Documentation),
+				Input:         d.message(op.Input.Name),
+				Output:        d.message(op.Output.Name),
+			}
+			for _, b := range def.Bindings {
+				if models.LocalName(b.Type) != pt.Name {
+					continue
+				}
+				for _, bop := range b.Operations {
+					if bop.Name != op.Name {
+						continue
+					}
+					style := bop.Style
+					if style == "" {
+						style = b.Style
+					}
+					use := bop.Input.Use
+					if use == "" {
+						use = "literal"
+					}
+					operation.Bindings = append(operation.Bindings, OperationBinding{
+						Binding:    b.Name,
+						SOAPAction: bop.SoapAction,
+						Style:      styleOrDefault(style),
+						Use:        use,
+					})
+				}
+			}
+			for _, f := range op.Faults {
+				operation.Faults = append(operation.Faults, Fault{Name: f.Name, Message: d.message(f.Message)})
+			}
+			desc.Operations = append(desc.Operations, operation)
+		}
+	}
+
+	return desc
+}
+
+// binding finds a binding by qualified name
+func (d *describer) binding(name string) *models.Binding {
+	name = models.LocalName(name)
+	for i := range d.def.Bindings {
+		if d.def.Bindings[i].Name == name {
+			return &d.def.Bindings[i]
+		}
+	}
+	return nil
+}
+
+// message describes a message, or returns nil when it is not defined
+func (d *describer) message(name string) *Message {
+	if name == "" {
+		return nil
+	}
+	name = models.LocalName(name)
+	for _, msg := range d.def.Messages {
+		if msg.Name != name {
+			continue
+		}
+		message := &Message{Name: msg.Name, Parts: []Part{}}
+		for _, p := range msg.Parts {
+			part := Part{Name: p.Name, Element: p.Element, Type: p.Type}
+			typeName := p.Type
+			if p.Element != "" {
+				typeName = d.def.ElementType(p.Element)
+			}
+			part.Fields = d.fields(typeName)
+			message.Parts = append(message.Parts, part)
+		}
+		return message
+	}
+	return nil
+}
+
+// fields returns the fields of a complex type, or nil for simple and
+// unknown types
+func (d *describer) fields(typeName string) []Field {
+	name := models.LocalName(typeName)
+	if name == "" || d.visiting[name] {
+		return nil
+	}
+	for _, t := range d.def.Types {
+		if t.Name != name {
+			continue
+		}
+		d.visiting[name] = true
+		defer delete(d.visiting, name)
+
+		fields := []Field{}
+		for _, e := range t.Elements {
+			fields = append(fields, Field{
+				Name:      e.Name,
+				Type:      e.Type,
+				MinOccurs: e.MinOccurs,
+				MaxOccurs: e.MaxOccurs,
+				Fields:    d.fields(e.Type),
+			})
+		}
+		for _, a := range t.Attributes {
+			field := Field{Name: a.Name, Type: a.Type, Attribute: true}
+			if a.Use != "required" {
+				field.MinOccurs = "0"
+			}
+			fields = append(fields, field)
+		}
+		return fields
+	}
+	return nil
+}
+
+// WriteTable writes the services and operations as aligned text tables
+func (desc *Description) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SERVICE\tPORT\tBINDING\tSTYLE\tADDRESS")
+	for _, svc := range desc.Services {
+		for _, port := range svc.Ports {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", svc.Name, port.Name, port.Binding, port.Style, port.Address)
+		}
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "OPERATION\tPATTERN\tSOAP ACTION\tINPUT\tOUTPUT")
+	for _, op := range desc.Operations {
+		action := ""
+		if len(op.Bindings) > 0 {
+			action = op.Bindings[0].SOAPAction
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", op.Name, op.Pattern, action, op.Input.shape(), op.Output.shape())
+	}
+
+	return tw.Flush()
+}
+
+// shape formats the parts of a message on one line, such as
+// parameters: Add{intA: int, intB: int}
+func (m *Message) shape() string {
+	if m == nil {
+		return "-"
+	}
+	var parts []string
+	for _, p := range m.Parts {
+		ref := p.Type
+		if p.Element != "" {
+			ref = p.Element
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s%s", p.Name, models.LocalName(ref), fieldsShape(p.Fields)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fieldsShape formats fields in braces, marking optional fields with ?
+// and repeated ones with []
+func fieldsShape(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var shapes []string
+	for _, f := range fields {
+		name := f.Name
+		if f.Attribute {
+			name = "@" + name
+		}
+		if f.MinOccurs == "0" {
+			name += "?"
+		}
+		typeName := models.LocalName(f.Type)
+		if f.MaxOccurs != "" && f.MaxOccurs != "1" {
+			typeName += "[]"
+		}
+		shapes = append(shapes, fmt.Sprintf("%s: %s%s", name, typeName, fieldsShape(f.Fields)))
+	}
+	return "{" + strings.Join(shapes, ", ") + "}"
+}
+
+// styleOrDefault returns a binding style, defaulting to document
+func styleOrDefault(style string) string {
+	if style == "" {
+		return "document"
+	}
+	return style
+}
@@ -0,0 +1,83 @@
+package describe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func testDefinitions() *models.Definitions {
+	return &models.Definitions{
+		Name:            "Tree",
+		TargetNamespace: "urn:tree",
+		Services: []models.Service{{Name: "TreeService", Ports: []models.Port{
+			{Name: "TreePort", Binding: "tns:TreeBinding", Address: "http://localhost/tree"},
+		}}},
+		Bindings: []models.Binding{{
+			Name:       "TreeBinding",
+			Type:       "tns:TreePortType",
+			Style:      "rpc",
+			Operations: []models.BindingOperation{{Name: "GetNode", SoapAction: "urn:tree/GetNode"}},
+		}},
+		PortTypes: []models.PortType{{Name: "TreePortType", Operations: []models.Operation{{
+			Name:    "GetNode",
+			Pattern: models.PatternRequestResponse,
+			Input:   models.Message{Name: "tns:GetNodeIn"},
+			Output:  models.Message{Name: "tns:GetNodeOut"},
+		}}}},
+		Messages: []models.Message{
+			{Name: "GetNodeIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "GetNodeOut", Parts: []models.Part{{Name: "node", Element: "tns:Node"}}},
+		},
+		Elements: []models.Element{{Name: "Node", Type: "tns:Node"}},
+		Types: []models.Type{{
+			Name: "Node",
+			Elements: []models.Element{
+				{Name: "value", Type: "xs:string"},
+				{Name: "children", Type: "tns:Node", MinOccurs: "0", MaxOccurs: "unbounded"},
+			},
+			Attributes: []models.Attribute{{Name: "id", Type: "xs:int", Use: "required"}},
+		}},
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	desc := Describe(testDefinitions())
+
+	if len(desc.Services) != 1 || desc.Services[0].Ports[0].Style != "rpc" {
+		t.Fatalf("Services = %+v, want one rpc port", desc.Services)
+	}
+	if len(desc.Operations) != 1 {
+		t.Fatalf("Operations = %+v, want GetNode", desc.Operations)
+	}
+	op := desc.Operations[0]
+	if len(op.Bindings) != 1 || op.Bindings[0].SOAPAction != "urn:tree/GetNode" || op.Bindings[0].Use != "literal" {
+		t.Errorf("Bindings = %+v", op.Bindings)
+	}
+
+	// The recursive children field is not expanded again
+	fields := op.Output.Parts[0].Fields
+	if len(fields) != 3 || fields[1].Name != "children" || fields[1].Fields != nil {
+		t.Errorf("Fields = %+v, want value, children without fields and id", fields)
+	}
+	if !fields[2].Attribute || fields[2].MinOccurs != "" {
+		t.Errorf("id = %+v, want a required attribute", fields[2])
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var b strings.Builder
+	if err := Describe(testDefinitions()).WriteTable(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"TreeService  TreePort  TreeBinding  rpc    http://localhost/tree",
+		"GetNode    request-response  urn:tree/GetNode  id: int  node: Node{value: string, children?: Node[], @id: int}",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Change kinds
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is a difference between two versions of WSDL definitions.
+// Breaking changes can make existing clients fail.
+type Change struct {
+	Kind     string
+	Location string // Definition that changed, such as operation "Add"
+	Message  string
+	Breaking bool
+}
+
+// String formats the change, prefixing breaking changes with BREAKING
+func (c Change) String() string {
+	prefix := "         "
+	if c.Breaking {
+		prefix = "BREAKING "
+	}
+	return fmt.Sprintf("%s%s: %s", prefix, c.Location, c.Message)
+}
+
+// HasBreaking reports whether any of the changes is breaking
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// differ accumulates the changes between two definitions
+type differ struct {
+	old, new *models.Definitions
+	changes  []Change
+}
+
+// Compare returns the changes from old to new definitions: added and
+// removed operations, changed message parts, SOAP actions and faults, and
+// changed schema types. Operations and types are matched by name.
+func Compare(old, new *models.Definitions) []Change {
+	d := &differ{old: old, new: new}
+	d.compareOperations()
+	d.compareTypes()
+	d.compareSimpleTypes()
+	return d.changes
+}
+
+// add records a change
+func (d *differ) add(kind, location string, breaking bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Kind:     kind,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+		Breaking: breaking,
+	})
+}
+
+// compareOperations compares the operations of all port types
+func (d *differ) compareOperations() {
+	oldOps, oldNames := operations(d.old)
+	newOps, newNames := operations(d.new)
+
+	for _, name := range oldNames {
+		location := fmt.Sprintf("operation %q", name)
+		newOp, ok := newOps[name]
+		if !ok {
+			d.add(Removed, location, true, "operation removed")
+			continue
+		}
+		oldOp := oldOps[name]
+
+		d.compareMessages(location+" input", oldOp.Input.Name, newOp.Input.Name, true)
+		d.compareMessages(location+" output", oldOp.Output.Name, newOp.Output.Name, false)
+		d.compareFaults(location, oldOp, newOp)
+
+		if oldAction, newAction := d.old.SoapAction(name), d.new.SoapAction(name); oldAction != newAction {
+			d.add(Changed, location, true, "soapAction changed from %q to %q", oldAction, newAction)
+		}
+	}
+	for _, name := range newNames {
+		if _, ok := oldOps[name]; !ok {
+			d.add(Added, fmt.Sprintf("operation %q", name), false, "operation added")
+		}
+	}
+}
+
+// compareMessages compares the parts of an operation's input or output.
+// New parts only break requests, since clients ignore unknown response
+// parts.
+func (d *differ) compareMessages(location, oldName, newName string, input bool) {
+	oldMsg := d.old.Message(oldName)
+	newMsg := d.new.Message(newName)
+	switch {
+	case oldMsg == nil && newMsg == nil:
+		return
+	case oldMsg == nil:
+		d.add(Added, location, input, "message added")
+		return
+	case newMsg == nil:
+		d.add(Removed, location, true, "message removed")
+		return
+	}
+
+	newParts := make(map[string]models.Part)
+	for _, part := range newMsg.Parts {
+		newParts[part.Name] = part
+	}
+	oldParts := make(map[string]bool)
+	for _, part := range oldMsg.Parts {
+		oldParts[part.Name] = true
+		newPart, ok := newParts[part.Name]
+		if !ok {
+			d.add(Removed, location, true, "part %s removed", part.Name)
+			continue
+		}
+		if oldRef, newRef := partRef(part), partRef(newPart); unqualified(oldRef) != unqualified(newRef) {
+			d.add(Changed, location, true, "part %s changed from %s to %s", part.Name, oldRef, newRef)
+		}
+	}
+	for _, part := range newMsg.Parts {
+		if !oldParts[part.Name] {
+			d.add(Added, location, input, "part %s added", part.Name)
+		}
+	}
+}
+
+// compareFaults compares the faults declared by an operation. New faults
+// are not breaking since clients handle unknown faults generically.
+func (d *differ) compareFaults(location string, oldOp, newOp models.Operation) {
+	newFaults := make(map[string]bool)
+	for _, f := range newOp.Faults {
+		newFaults[f.Name] = true
+	}
+	oldFaults := make(map[string]bool)
+	for _, f := range oldOp.Faults {
+		oldFaults[f.Name] = true
+		if !newFaults[f.Name] {
+			d.add(Removed, location, false, "fault %s removed", f.Name)
+		}
+	}
+	for _, f := range newOp.Faults {
+		if !oldFaults[f.Name] {
+			d.add(Added, location, false, "fault %s added", f.Name)
+		}
+	}
+}
+
+// compareTypes compares complex types. Types may be used in both requests
+// and responses, so any change to an existing element is breaking, and so
+// is a new required element.
+func (d *differ) compareTypes() {
+	newTypes := make(map[string]models.Type)
+	for _, t := range d.new.Types {
+		newTypes[t.Name] = t
+	}
+	oldTypes := make(map[string]bool)
+
+	for _, oldType := range d.old.Types {
+		oldTypes[oldType.Name] = true
+		location := fmt.Sprintf("complexType %q", oldType.Name)
+		newType, ok := newTypes[oldType.Name]
+		if !ok {
+			d.add(Removed, location, true, "type removed")
+			continue
+		}
+
+		newElems := make(map[string]models.Element)
+		for _, e := range newType.Elements {
+			newElems[e.Name] = e
+		}
+		oldElems := make(map[string]bool)
+		for _, e := range oldType.Elements {
+			oldElems[e.Name] = true
+			newElem, ok := newElems[e.Name]
+			if !ok {
+				d.add(Removed, location, true, "element %s removed", e.Name)
+				continue
+			}
+			d.compareElement(location, e, newElem)
+		}
+		for _, e := range newType.Elements {
+			if !oldElems[e.Name] {
+				required := e.MinOccurs != "0"
+				d.add(Added, location, required, "%s element %s added", occurrence(required), e.Name)
+			}
+		}
+
+		newAttrs := make(map[string]models.Attribute)
+		for _, a := range newType.Attributes {
+			newAttrs[a.Name] = a
+		}
+		oldAttrs := make(map[string]bool)
+		for _, a := range oldType.Attributes {
+			oldAttrs[a.Name] = true
+			newAttr, ok := newAttrs[a.Name]
+			if !ok {
+				d.add(Removed, location, true, "attribute %s removed", a.Name)
+				continue
+			}
+			if models.LocalName(a.Type) != models.LocalName(newAttr.Type) {
+				d.add(Changed, location, true, "attribute %s type changed from %s to %s", a.Name, a.Type, newAttr.Type)
+			}
+		}
+		for _, a := range newType.Attributes {
+			if !oldAttrs[a.Name] {
+				required := a.Use == "required"
+				d.add(Added, location, required, "%s attribute %s added", occurrence(required), a.Name)
+			}
+		}
+	}
+
+	for _, t := range d.new.Types {
+		if !oldTypes[t.Name] {
+			d.add(Added, fmt.Sprintf("complexType %q", t.Name), false, "type added")
+		}
+	}
+}
+
+// compareElement compares two versions of an element of a complex type
+func (d *differ) compareElement(location string, oldElem, newElem models.Element) {
+	if models.LocalName(oldElem.Type) != models.LocalName(newElem.Type) {
+		d.add(Changed, location, true, "element %s type changed from %s to %s", oldElem.Name, oldElem.Type, newElem.Type)
+	}
+	if minOccurs(oldElem) != minOccurs(newElem) {
+		d.add(Changed, location, true, "element %s minOccurs changed from %s to %s", oldElem.Name, minOccurs(oldElem), minOccurs(newElem))
+	}
+	if maxOccurs(oldElem) != maxOccurs(newElem) {
+		d.add(Changed, location, true, "element %s maxOccurs changed from %s to %s", oldElem.Name, maxOccurs(oldElem), maxOccurs(newElem))
+	}
+	if oldElem.Nillable != newElem.Nillable {
+		d.add(Changed, location, true, "element %s nillable changed to %t", oldElem.Name, newElem.Nillable)
+	}
+}
+
+// compareSimpleTypes compares simple types. Removed enumeration values
+// break clients sending them; added ones are compatible.
+func (d *differ) compareSimpleTypes() {
+	newTypes := make(map[string]models.SimpleType)
+	for _, st := range d.new.SimpleTypes {
+		newTypes[st.Name] = st
+	}
+	oldTypes := make(map[string]bool)
+
+	for _, oldType := range d.old.SimpleTypes {
+		oldTypes[oldType.Name] = true
+		location := fmt.Sprintf("simpleType %q", oldType.Name)
+		newType, ok := newTypes[oldType.Name]
+		if !ok {
+			d.add(Removed, location, true, "type removed")
+			continue
+		}
+		if models.LocalName(oldType.Base) != models.LocalName(newType.Base) {
+			d.add(Changed, location, true, "base type changed from %s to %s", oldType.Base, newType.Base)
+		}
+		if oldType.Pattern != newType.Pattern {
+			d.add(Changed, location, true, "pattern changed from %q to %q", oldType.Pattern, newType.Pattern)
+		}
+
+		newValues := make(map[string]bool)
+		for _, value := range newType.Enumeration {
+			newValues[value] = true
+		}
+		oldValues := make(map[string]bool)
+		for _, value := range oldType.Enumeration {
+			oldValues[value] = true
+			if !newValues[value] {
+				d.add(Removed, location, true, "enumeration value %q removed", value)
+			}
+		}
+		for _, value := range newType.Enumeration {
+			if !oldValues[value] {
+				d.add(Added, location, false, "enumeration value %q added", value)
+			}
+		}
+	}
+
+	for _, st := range d.new.SimpleTypes {
+		if !oldTypes[st.Name] {
+			d.add(Added, fmt.Sprintf("simpleType %q", st.Name), false, "type added")
+		}
+	}
+}
+
+// operations returns the operations of all port types by name, with the
+// names in declaration order. The first operation of a name wins.
+func operations(def *models.Definitions) (map[string]models.Operation, []string) {
+	ops := make(map[string]models.Operation)
+	var names []string
+	for _, pt := range def.PortTypes {
+		for _, op := range pt.Operations {
+			if _, ok := ops[op.Name]; !ok {
+				ops[op.Name] = op
+				names = append(names, op.Name)
+			}
+		}
+	}
+	return ops, names
+}
+
+// partRef returns the element or type a part references
+func partRef(part models.Part) string {
+	if part.Element != "" {
+		return "element " + part.Element
+	}
+	return "type " + part.Type
+}
+
+// unqualified strips the namespace prefix from the name a part references
+func unqualified(ref string) string {
+	kind, name, _ := strings.Cut(ref, " ")
+	return kind + " " + models.LocalName(name)
+}
+
+// minOccurs returns an element's minOccurs with the default applied
+func minOccurs(e models.Element) string {
+	if e.MinOccurs == "" {
+		return "1"
+	}
+	return e.MinOccurs
+}
+
+// maxOccurs returns an element's maxOccurs with the default applied
+func maxOccurs(e models.Element) string {
+	if e.MaxOccurs == "" {
+		return "1"
+	}
+	return e.MaxOccurs
+}
+
+// occurrence describes whether an added element or attribute is required
+func occurrence(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
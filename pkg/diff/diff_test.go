@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// calculator returns definitions of a calculator service; the modify
+// function changes them into a new version
+func calculator(modify func(def *models.Definitions)) *models.Definitions {
+	def := &models.Definitions{
+		Bindings: []models.Binding{{Name: "CalcBinding", Operations: []models.BindingOperation{
+			{Name: "Add", SoapAction: "urn:calc/Add"},
+			{Name: "Sub", SoapAction: "urn:calc/Sub"},
+		}}},
+		PortTypes: []models.PortType{{Name: "Calc", Operations: []models.Operation{
+			{Name: "Add", Input: models.Message{Name: "tns:AddIn"}, Output: models.Message{Name: "tns:AddOut"}},
+			{Name: "Sub", Input: models.Message{Name: "tns:SubIn"}, Output: models.Message{Name: "tns:SubOut"}},
+		}}},
+		Messages: []models.Message{
+			{Name: "AddIn", Parts: []models.Part{{Name: "parameters", Element: "tns:Add"}}},
+			{Name: "AddOut", Parts: []models.Part{{Name: "result", Type: "xs:int"}}},
+			{Name: "SubIn", Parts: []models.Part{{Name: "a", Type: "xs:int"}, {Name: "b", Type: "xs:int"}}},
+			{Name: "SubOut", Parts: []models.Part{{Name: "result", Type: "xs:int"}}},
+		},
+		Types: []models.Type{{Name: "Add", Elements: []models.Element{
+			{Name: "a", Type: "xs:int"},
+			{Name: "b", Type: "xs:int"},
+		}}},
+		SimpleTypes: []models.SimpleType{{Name: "Mode", Base: "xs:string", Enumeration: []string{"exact", "rounded"}}},
+	}
+	if modify != nil {
+		modify(def)
+	}
+	return def
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	if changes := Compare(calculator(nil), calculator(nil)); len(changes) != 0 {
+		t.Errorf("Compare() = %v, want no changes", changes)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(def *models.Definitions)
+		want   string
+	}{
+		{
+			name:   "operation removed",
+			modify: func(def *models.Definitions) { def.PortTypes[0].Operations = def.PortTypes[0].Operations[:1] },
+			want:   `BREAKING operation "Sub": operation removed`,
+		},
+		{
+			name: "operation added",
+			modify: func(def *models.Definitions) {
+				def.PortTypes[0].Operations = append(def.PortTypes[0].Operations, models.Operation{Name: "Mul"})
+			},
+			want: `         operation "Mul": operation added`,
+		},
+		{
+			name:   "request part type changed",
+			modify: func(def *models.Definitions) { def.Messages[2].Parts[1].Type = "xs:long" },
+			want:   `BREAKING operation "Sub" input: part b changed from type xs:int to type xs:long`,
+		},
+		{
+			name: "response part added",
+			modify: func(def *models.Definitions) {
+				def.Messages[3].Parts = append(def.Messages[3].Parts, models.Part{Name: "overflow", Type: "xs:boolean"})
+			},
+			want: `         operation "Sub" output: part overflow added`,
+		},
+		{
+			name: "request part added",
+			modify: func(def *models.Definitions) {
+				def.Messages[2].Parts = append(def.Messages[2].Parts, models.Part{Name: "mode", Type: "tns:Mode"})
+			},
+			want: `BREAKING operation "Sub" input: part mode added`,
+		},
+		{
+			name:   "soapAction changed",
+			modify: func(def *models.Definitions) { def.Bindings[0].Operations[0].SoapAction = "urn:calc2/Add" },
+			want:   `BREAKING operation "Add": soapAction changed from "urn:calc/Add" to "urn:calc2/Add"`,
+		},
+		{
+			name:   "element made repeated",
+			modify: func(def *models.Definitions) { def.Types[0].Elements[0].MaxOccurs = "unbounded" },
+			want:   `BREAKING complexType "Add": element a maxOccurs changed from 1 to unbounded`,
+		},
+		{
+			name: "optional element added",
+			modify: func(def *models.Definitions) {
+				def.Types[0].Elements = append(def.Types[0].Elements, models.Element{Name: "c", Type: "xs:int", MinOccurs: "0"})
+			},
+			want: `         complexType "Add": optional element c added`,
+		},
+		{
+			name:   "enumeration value removed",
+			modify: func(def *models.Definitions) { def.SimpleTypes[0].Enumeration = []string{"exact"} },
+			want:   `BREAKING simpleType "Mode": enumeration value "rounded" removed`,
+		},
+		{
+			name: "namespace prefix changed",
+			modify: func(def *models.Definitions) {
+				def.Messages[0].Parts[0].Element = "calc:Add"
+				def.Types[0].Elements[0].Type = "xsd:int"
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Compare(calculator(nil), calculator(tt.modify))
+			if tt.want == "" {
+				if len(changes) != 0 {
+					t.Errorf("Compare() = %v, want no changes", changes)
+				}
+				return
+			}
+			if len(changes) != 1 || changes[0].String() != tt.want {
+				t.Fatalf("Compare() = %q, want [%s]", changes, tt.want)
+			}
+			if HasBreaking(changes) != changes[0].Breaking {
+				t.Errorf("HasBreaking() = %v", HasBreaking(changes))
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// wsdlNamespace is the WSDL 1.1 namespace
+const wsdlNamespace = "http://schemas.xmlsoap.org/wsdl/"
+
+// Bundle returns the contract as a single WSDL document, which generate,
+// serve and the other commands read: the WSDL declaring the service, with
+// the declarations of the WSDLs it imports and the schemas of the contract
+// added to it. A contract of one WSDL is returned as it is.
+func (c *Contract) Bundle() ([]byte, error) {
+	var wsdls, schemas []Document
+	for _, doc := range c.Documents {
+		switch doc.Dialect {
+		case DialectWSDL:
+			wsdls = append(wsdls, doc)
+		case DialectSchema:
+			schemas = append(schemas, doc)
+		}
+	}
+	if len(wsdls) == 0 {
+		return nil, fmt.Errorf("no WSDL found at %s", c.Source)
+	}
+
+	// The WSDL with the service is the one bundled into
+	main := 0
+	for i, doc := range wsdls {
+		if declaresService(doc.Content) {
+			main = i
+			break
+		}
+	}
+	if len(wsdls) == 1 && len(schemas) == 0 {
+		return wsdls[main].Content, nil
+	}
+
+	root, err := fragments(wsdls[main].Content)
+	if err != nil || len(root) != 1 {
+		return nil, fmt.Errorf("invalid WSDL %s: %v", wsdls[main].Location, err)
+	}
+	end := bytes.LastIndex(root[0].data, []byte("</"))
+	if end < 0 {
+		// An empty definitions element
+		return nil, fmt.Errorf("invalid WSDL %s: no declarations", wsdls[main].Location)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.Write(root[0].data[:end])
+	if len(schemas) > 0 {
+		b.WriteString("<wsdl:types xmlns:wsdl=\"" + wsdlNamespace + "\">")
+		for _, doc := range schemas {
+			schema, err := fragments(doc.Content)
+			if err != nil || len(schema) != 1 {
+				return nil, fmt.Errorf("invalid schema %s: %v", doc.Location, err)
+			}
+			b.Write(schema[0].data)
+		}
+		b.WriteString("</wsdl:types>")
+	}
+	for i, doc := range wsdls {
+		if i == main {
+			continue
+		}
+		declarations, err := fragments(doc.Content, "definitions")
+		if err != nil {
+			return nil, fmt.Errorf("invalid WSDL %s: %w", doc.Location, err)
+		}
+		for _, f := range declarations {
+			if f.start.Name.Local != "import" && f.start.Name.Local != "documentation" {
+				b.Write(f.data)
+			}
+		}
+	}
+	b.Write(root[0].data[end:])
+	return b.Bytes(), nil
+}
+
+// declaresService reports whether a WSDL declares a service
+func declaresService(wsdl []byte) bool {
+	declarations, _ := fragments(wsdl, "definitions")
+	for _, f := range declarations {
+		if f.start.Name.Local == "service" {
+			return true
+		}
+	}
+	return false
+}
+
+// fragment is an element of a document as it appears in the source, with
+// the namespace declarations of its ancestors added so that it stands
+// alone
+type fragment struct {
+	start xml.StartElement // With the prefix of names as Space
+	data  []byte
+}
+
+// attr returns the value of an attribute of the fragment by local name
+func (f fragment) attr(name string) string {
+	for _, attr := range f.start.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// fragments returns the elements of doc whose ancestors have the local
+// names of path, from the root element down: the root element itself when
+// the path is empty
+func fragments(doc []byte, path ...string) ([]fragment, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var open []xml.StartElement
+	var result []fragment
+	for {
+		offset := d.InputOffset()
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !onPath(open, path) {
+				open = append(open, t.Copy())
+				continue
+			}
+			if err := skip(d); err != nil {
+				return nil, err
+			}
+			result = append(result, fragment{
+				start: t.Copy(),
+				data:  standalone(doc[offset:d.InputOffset()], t, open),
+			})
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+}
+
+// onPath reports whether the open elements are the ones of path
+func onPath(open []xml.StartElement, path []string) bool {
+	if len(open) != len(path) {
+		return false
+	}
+	for i, start := range open {
+		if start.Name.Local != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// skip reads tokens up to the end of the element just started
+func skip(d *xml.Decoder) error {
+	for depth := 1; depth > 0; {
+		tok, err := d.RawToken()
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
+
+// standalone adds to the start tag of an element's source the namespace
+// declarations of its ancestors it doesn't make itself
+func standalone(data []byte, start xml.StartElement, ancestors []xml.StartElement) []byte {
+	declared := make(map[string]bool)
+	for _, attr := range start.Attr {
+		if prefix, ok := namespacePrefix(attr); ok {
+			declared[prefix] = true
+		}
+	}
+	inherited := make(map[string]string)
+	for _, ancestor := range ancestors {
+		for _, attr := range ancestor.Attr {
+			if prefix, ok := namespacePrefix(attr); ok && !declared[prefix] {
+				inherited[prefix] = attr.Value
+			}
+		}
+	}
+	if len(inherited) == 0 {
+		return data
+	}
+
+	prefixes := make([]string, 0, len(inherited))
+	for prefix := range inherited {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var b bytes.Buffer
+	name := start.Name.Local
+	if start.Name.Space != "" {
+		name = start.Name.Space + ":" + name
+	}
+	b.Write(data[:1+len(name)])
+	for _, prefix := range prefixes {
+		attr := "xmlns"
+		if prefix != "" {
+			attr += ":" + prefix
+		}
+		b.WriteString(" " + attr + "=\"")
+		xml.EscapeText(&b, []byte(inherited[prefix]))
+		b.WriteString("\"")
+	}
+	b.Write(data[1+len(name):])
+	return b.Bytes()
+}
+
+// namespacePrefix returns the prefix an attribute declares, "" for the
+// default namespace, if it is a namespace declaration
+func namespacePrefix(attr xml.Attr) (string, bool) {
+	switch {
+	case attr.Name.Space == "xmlns":
+		return attr.Name.Local, true
+	case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+		return "", true
+	}
+	return "", false
+}
@@ -0,0 +1,318 @@
+// Package discovery retrieves the WSDL contracts of live services, for
+// services that don't publish their WSDL as a single file: it follows the
+// imports of ?wsdl documents, such as the ?wsdl=wsdl0 and ?xsd=xsd0 parts of
+// WCF services, and queries WS-MetadataExchange (MEX) endpoints. Contracts
+// are bundled into a single WSDL the parser reads.
+package discovery
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/addressing"
+)
+
+// Dialects of the documents of a contract, as MEX names them
+const (
+	DialectWSDL   = "http://schemas.xmlsoap.org/wsdl/"
+	DialectSchema = "http://www.w3.org/2001/XMLSchema"
+)
+
+// Methods contracts are discovered with
+const (
+	MethodWSDL = "wsdl" // A WSDL URL and the documents it imports
+	MethodMEX  = "mex"  // A WS-MetadataExchange Get request
+)
+
+// getAction is the action of WS-Transfer Get requests, with which MEX
+// endpoints return the metadata of a service
+const getAction = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Get"
+
+// maxDocument bounds the size of each document retrieved
+const maxDocument = 32 << 20
+
+// Document is a WSDL or XML Schema document of a contract
+type Document struct {
+	Dialect  string // DialectWSDL or DialectSchema
+	Location string // URL, or MEX identifier, of the document
+	Content  []byte
+}
+
+// Contract is the metadata of a service: its WSDLs and the schemas they
+// import
+type Contract struct {
+	Source    string // URL the contract was retrieved from
+	Method    string // MethodWSDL or MethodMEX
+	Documents []Document
+}
+
+// Client retrieves contracts over HTTP
+type Client struct {
+	HTTPClient *http.Client
+	Header     http.Header // Sent with every request
+	Username   string      // HTTP basic auth, when set
+	Password   string
+}
+
+// NewClient creates a client retrieving contracts with httpClient
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{HTTPClient: httpClient, Header: make(http.Header)}
+}
+
+// Discover retrieves the contract of a service from its address: a WSDL
+// URL such as https://host/Service.svc?wsdl, a MEX endpoint, or the base
+// address of the service, which is probed for a ?singleWsdl (WCF), a ?wsdl
+// and a MEX endpoint at the address and at /mex, in that order.
+func (c *Client) Discover(ctx context.Context, address string) (*Contract, error) {
+	type probe struct {
+		method string
+		url    string
+	}
+	probes := []probe{{MethodWSDL, address}, {MethodMEX, address}}
+	if !strings.Contains(address, "?") {
+		probes = []probe{
+			{MethodWSDL, address + "?singleWsdl"},
+			{MethodWSDL, address + "?wsdl"},
+			{MethodMEX, address},
+			{MethodMEX, strings.TrimSuffix(address, "/") + "/mex"},
+		}
+	}
+
+	var errs []error
+	for _, p := range probes {
+		var contract *Contract
+		var err error
+		if p.method == MethodMEX {
+			contract, err = c.MEX(ctx, p.url)
+		} else {
+			contract, err = c.WSDL(ctx, p.url)
+		}
+		if err == nil {
+			return contract, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s %s: %w", p.method, p.url, err))
+	}
+	return nil, fmt.Errorf("no contract found at %s:\n%w", address, errors.Join(errs...))
+}
+
+// WSDL retrieves a WSDL and the WSDLs and schemas it imports, resolving
+// relative locations against the document importing them
+func (c *Client) WSDL(ctx context.Context, location string) (*Contract, error) {
+	contract := &Contract{Source: location, Method: MethodWSDL}
+	seen := map[string]bool{location: true}
+	pending := []string{location}
+	for len(pending) > 0 {
+		location := pending[0]
+		pending = pending[1:]
+
+		data, err := c.get(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := newDocument(location, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(contract.Documents) == 0 && doc.Dialect != DialectWSDL {
+			return nil, fmt.Errorf("%s is not a WSDL", location)
+		}
+		contract.Documents = append(contract.Documents, doc)
+
+		for _, ref := range imports(doc) {
+			resolved, err := resolve(location, ref)
+			if err != nil {
+				return nil, fmt.Errorf("invalid import %q in %s: %w", ref, location, err)
+			}
+			if !seen[resolved] {
+				seen[resolved] = true
+				pending = append(pending, resolved)
+			}
+		}
+	}
+	return contract, nil
+}
+
+// MEX retrieves the metadata of a service from a WS-MetadataExchange
+// endpoint with a WS-Transfer Get request. Sections referring to their
+// document by location, or to another MEX endpoint, are retrieved too.
+func (c *Client) MEX(ctx context.Context, endpoint string) (*Contract, error) {
+	header, err := addressing.NewAddressingHeader(&addressing.WSAddressing{}, endpoint, getAction).Marshal()
+	if err != nil {
+		return nil, err
+	}
+	envelope := `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Header>` + header + `</s:Header><s:Body/></s:Envelope>`
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(envelope))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", `application/soap+xml; charset=utf-8; action="`+getAction+`"`)
+	data, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := fragments(data, "Envelope", "Body", "Metadata")
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	contract := &Contract{Source: endpoint, Method: MethodMEX}
+	for _, section := range sections {
+		if section.start.Name.Local != "MetadataSection" {
+			continue
+		}
+		dialect := section.attr("Dialect")
+		if dialect != DialectWSDL && dialect != DialectSchema {
+			continue // Such as WS-Policy
+		}
+		content, err := fragments(section.data, "MetadataSection")
+		if err != nil || len(content) == 0 {
+			return nil, fmt.Errorf("invalid metadata section %s: %v", section.attr("Identifier"), err)
+		}
+
+		doc := Document{Dialect: dialect, Location: section.attr("Identifier"), Content: content[0].data}
+		switch content[0].start.Name.Local {
+		case "MetadataReference":
+			address, _ := fragments(content[0].data, "MetadataReference")
+			if len(address) == 0 || address[0].start.Name.Local != "Address" {
+				return nil, fmt.Errorf("invalid metadata reference in %s", endpoint)
+			}
+			reference := text(address[0].data)
+			if reference == endpoint {
+				continue
+			}
+			referenced, err := c.MEX(ctx, reference)
+			if err != nil {
+				return nil, err
+			}
+			contract.Documents = append(contract.Documents, referenced.Documents...)
+			continue
+		case "Location":
+			location := text(content[0].data)
+			if doc.Content, err = c.get(ctx, location); err != nil {
+				return nil, err
+			}
+			doc.Location = location
+		}
+		contract.Documents = append(contract.Documents, doc)
+	}
+	if len(contract.Documents) == 0 {
+		return nil, fmt.Errorf("no WSDL or schema in the metadata of %s", endpoint)
+	}
+	return contract, nil
+}
+
+// get retrieves a document
+func (c *Client) get(ctx context.Context, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// do sends a request and returns the body of its successful response
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocument+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocument {
+		return nil, fmt.Errorf("%s is larger than %d bytes", req.URL, maxDocument)
+	}
+	return data, nil
+}
+
+// newDocument returns a retrieved document, telling its dialect from its
+// root element
+func newDocument(location string, data []byte) (Document, error) {
+	root, err := fragments(data)
+	if err != nil || len(root) == 0 {
+		return Document{}, fmt.Errorf("%s is not XML: %v", location, err)
+	}
+	doc := Document{Location: location, Content: data}
+	switch root[0].start.Name.Local {
+	case "definitions", "description":
+		doc.Dialect = DialectWSDL
+	case "schema":
+		doc.Dialect = DialectSchema
+	default:
+		return Document{}, fmt.Errorf("%s is neither a WSDL nor a schema but <%s>", location, root[0].start.Name.Local)
+	}
+	return doc, nil
+}
+
+// imports returns the locations of the WSDLs and schemas a document
+// imports or includes
+func imports(doc Document) []string {
+	var refs []string
+	add := func(elements []fragment, attr string) {
+		for _, f := range elements {
+			name := f.start.Name.Local
+			if name != "import" && name != "include" {
+				continue
+			}
+			if location := f.attr(attr); location != "" {
+				refs = append(refs, location)
+			}
+		}
+	}
+
+	if doc.Dialect == DialectSchema {
+		children, _ := fragments(doc.Content, "schema")
+		add(children, "schemaLocation")
+		return refs
+	}
+	children, _ := fragments(doc.Content, "definitions")
+	add(children, "location")
+	schemas, _ := fragments(doc.Content, "definitions", "types", "schema")
+	add(schemas, "schemaLocation")
+	return refs
+}
+
+// resolve resolves a reference against the location of the document
+// making it
+func resolve(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+// text returns the character data of an element's source
+func text(data []byte) string {
+	var v struct {
+		Text string `xml:",chardata"`
+	}
+	_ = xml.Unmarshal(data, &v)
+	return strings.TrimSpace(v.Text)
+}
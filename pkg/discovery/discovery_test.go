@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/pkg/parser"
+)
+
+// serviceWSDL is the WSDL of a WCF service at ?wsdl: the service, importing
+// the port type and binding from ?wsdl=wsdl0
+const serviceWSDL = `<?xml version="1.0" encoding="utf-8"?>
+<wsdl:definitions name="Calculator" targetNamespace="http://tempuri.org/"
+    xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/" xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/" xmlns:i0="urn:calc">
+  <wsdl:import namespace="urn:calc" location="Service.svc?wsdl=wsdl0"/>
+  <wsdl:types/>
+  <wsdl:service name="Calculator">
+    <wsdl:port name="CalculatorSoap" binding="i0:CalculatorSoap">
+      <soap:address location="http://localhost/Service.svc"/>
+    </wsdl:port>
+  </wsdl:service>
+</wsdl:definitions>`
+
+// contractWSDL is the port type and binding at ?wsdl=wsdl0, importing its
+// schema from ?xsd=xsd0
+const contractWSDL = `<wsdl:definitions targetNamespace="urn:calc" xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/" xmlns:tns="urn:calc" xmlns:xsd="http://www.w3.org/2001/XMLSchema">
+  <wsdl:types>
+    <xsd:schema targetNamespace="urn:calc/imports"><xsd:import schemaLocation="Service.svc?xsd=xsd0" namespace="urn:calc"/></xsd:schema>
+  </wsdl:types>
+  <wsdl:message name="AddIn"><wsdl:part name="parameters" element="tns:Add"/></wsdl:message>
+  <wsdl:message name="AddOut"><wsdl:part name="parameters" element="tns:AddResponse"/></wsdl:message>
+  <wsdl:portType name="Calculator">
+    <wsdl:operation name="Add"><wsdl:input message="tns:AddIn"/><wsdl:output message="tns:AddOut"/></wsdl:operation>
+  </wsdl:portType>
+  <wsdl:binding name="CalculatorSoap" type="tns:Calculator">
+    <soap:binding transport="http://schemas.xmlsoap.org/soap/http"/>
+    <wsdl:operation name="Add">
+      <soap:operation soapAction="urn:calc/Add" style="document"/>
+      <wsdl:input><soap:body use="literal"/></wsdl:input>
+      <wsdl:output><soap:body use="literal"/></wsdl:output>
+    </wsdl:operation>
+  </wsdl:binding>
+</wsdl:definitions>`
+
+// contractSchema is the schema at ?xsd=xsd0
+const contractSchema = `<xs:schema targetNamespace="urn:calc" elementFormDefault="qualified" xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Add"><xs:complexType><xs:sequence>
+    <xs:element name="a" type="xs:int"/><xs:element name="b" type="xs:int"/>
+  </xs:sequence></xs:complexType></xs:element>
+  <xs:element name="AddResponse"><xs:complexType><xs:sequence>
+    <xs:element name="AddResult" type="xs:int"/>
+  </xs:sequence></xs:complexType></xs:element>
+</xs:schema>`
+
+// metadata is the MEX response of the service. The sections use the wsdl
+// and xs prefixes declared on the envelope.
+var metadata = `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/" xmlns:xs="http://www.w3.org/2001/XMLSchema">
+<s:Body><wsx:Metadata xmlns:wsx="http://schemas.xmlsoap.org/ws/2004/09/mex">
+  <wsx:MetadataSection Dialect="http://schemas.xmlsoap.org/ws/2004/09/policy" Identifier="urn:policy"><Policy/></wsx:MetadataSection>
+  <wsx:MetadataSection Dialect="http://schemas.xmlsoap.org/wsdl/" Identifier="urn:calc">` + contractWSDL + `</wsx:MetadataSection>
+  <wsx:MetadataSection Dialect="http://schemas.xmlsoap.org/wsdl/" Identifier="http://tempuri.org/">` + serviceWSDL[strings.Index(serviceWSDL, "<wsdl:definitions"):] + `</wsx:MetadataSection>
+  <wsx:MetadataSection Dialect="http://www.w3.org/2001/XMLSchema" Identifier="urn:calc"><xs:schema targetNamespace="urn:calc" elementFormDefault="qualified">` +
+	contractSchema[strings.Index(contractSchema, ">")+1:] + `</wsx:MetadataSection>
+</wsx:Metadata></s:Body></s:Envelope>`
+
+// newService serves the WSDLs of a WCF service, and its metadata at /mex
+func newService(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/Service.svc/mex" && r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			if !bytes.Contains(body, []byte(getAction)) {
+				t.Errorf("MEX request without the Get action: %s", body)
+			}
+			w.Header().Set("Content-Type", "application/soap+xml")
+			w.Write([]byte(metadata))
+		case r.URL.Path != "/Service.svc" || r.Method != http.MethodGet:
+			http.NotFound(w, r)
+		case r.URL.RawQuery == "wsdl":
+			w.Write([]byte(serviceWSDL))
+		case r.URL.RawQuery == "wsdl=wsdl0":
+			w.Write([]byte(contractWSDL))
+		case r.URL.RawQuery == "xsd=xsd0":
+			w.Write([]byte(contractSchema))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// checkBundle checks that the bundle of a contract parses to the
+// calculator
+func checkBundle(t *testing.T, contract *Contract) {
+	t.Helper()
+	bundle, err := contract.Bundle()
+	if err != nil {
+		t.Fatalf("Bundle() error = %v", err)
+	}
+	def, err := parser.NewParser().ParseReader(bytes.NewReader(bundle))
+	if err != nil {
+		t.Fatalf("parsing the bundle: %v\n%s", err, bundle)
+	}
+	if len(def.Services) != 1 || len(def.Bindings) != 1 || len(def.PortTypes) != 1 || len(def.PortTypes[0].Operations) != 1 {
+		t.Fatalf("bundle = %d services, %d bindings, %d port types\n%s", len(def.Services), len(def.Bindings), len(def.PortTypes), bundle)
+	}
+	var add bool
+	for _, elem := range def.Elements {
+		add = add || elem.Name == "Add" && elem.Namespace == "urn:calc"
+	}
+	if !add {
+		t.Errorf("bundle lacks the Add element of the schema\n%s", bundle)
+	}
+}
+
+func TestDiscoverWSDL(t *testing.T) {
+	srv := newService(t)
+	defer srv.Close()
+
+	contract, err := NewClient(srv.Client()).Discover(context.Background(), srv.URL+"/Service.svc")
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if contract.Method != MethodWSDL || contract.Source != srv.URL+"/Service.svc?wsdl" {
+		t.Errorf("Discover() = %s %s, want the ?wsdl", contract.Method, contract.Source)
+	}
+	if len(contract.Documents) != 3 {
+		t.Fatalf("Discover() found %d documents, want 3", len(contract.Documents))
+	}
+	checkBundle(t, contract)
+}
+
+func TestDiscoverMEX(t *testing.T) {
+	srv := newService(t)
+	defer srv.Close()
+
+	contract, err := NewClient(srv.Client()).MEX(context.Background(), srv.URL+"/Service.svc/mex")
+	if err != nil {
+		t.Fatalf("MEX() error = %v", err)
+	}
+	if len(contract.Documents) != 3 {
+		t.Fatalf("MEX() found %d documents, want 3 without the policy", len(contract.Documents))
+	}
+	checkBundle(t, contract)
+}
+
+func TestDiscoverNothing(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := NewClient(srv.Client()).Discover(context.Background(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "no contract found") || !strings.Contains(err.Error(), "/mex") {
+		t.Errorf("Discover() error = %v, want the probes listed", err)
+	}
+}
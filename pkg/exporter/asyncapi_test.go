@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestConvertWSDLToAsyncAPI(t *testing.T) {
+	def := &models.Definitions{
+		Name: "Orders",
+		Messages: []models.Message{
+			{Name: "OrderMsg", Parts: []models.Part{{Name: "id", Type: "xs:string"}}},
+			{Name: "AckMsg", Parts: []models.Part{{Name: "ok", Type: "xs:boolean"}}},
+		},
+		Bindings: []models.Binding{{Name: "OrdersJMS", Transport: "http://www.w3.org/2010/soapjms/"}},
+		Services: []models.Service{{Name: "Orders", Ports: []models.Port{
+			{Name: "OrdersPort", Binding: "tns:OrdersJMS", Address: "queue://orders"},
+		}}},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "Submit", Pattern: models.PatternOneWay, Input: models.Message{Name: "tns:OrderMsg"}},
+			{Name: "Shipped", Pattern: models.PatternNotification, Output: models.Message{Name: "tns:OrderMsg"}},
+			{Name: "Confirm", Pattern: models.PatternSolicitResponse, Input: models.Message{Name: "tns:AckMsg"}, Output: models.Message{Name: "tns:OrderMsg"}},
+			{Name: "Get", Input: models.Message{Name: "tns:OrderMsg"}, Output: models.Message{Name: "tns:AckMsg"}},
+		}}},
+	}
+
+	spec := ConvertWSDLToAsyncAPI(def)
+
+	if len(spec.Channels) != 4 {
+		t.Fatalf("expected 4 channels, got %v", spec.Channels)
+	}
+	if submit := spec.Channels["Submit"]; submit.Publish == nil || submit.Subscribe != nil || submit.Publish.Message.Name != "OrderMsg" {
+		t.Errorf("unexpected Submit channel: %+v", submit)
+	}
+	if shipped := spec.Channels["Shipped"]; shipped.Subscribe == nil || shipped.Publish != nil {
+		t.Errorf("unexpected Shipped channel: %+v", shipped)
+	}
+	if confirm := spec.Channels["Confirm"]; confirm.Subscribe == nil || confirm.Subscribe.Message.Name != "OrderMsg" {
+		t.Errorf("unexpected Confirm channel: %+v", confirm)
+	}
+	if reply := spec.Channels["Confirm/response"]; reply.Publish == nil || reply.Publish.Message.Payload.Properties["ok"] == nil {
+		t.Errorf("unexpected Confirm/response channel: %+v", reply)
+	}
+	if _, ok := spec.Channels["Get"]; ok {
+		t.Error("request-response operation should not become a channel")
+	}
+
+	// The queue:// scheme is unknown, so the protocol comes from the JMS transport
+	if server := spec.Servers["OrdersPort"]; server.Protocol != "jms" {
+		t.Errorf("unexpected server: %+v", server)
+	}
+}
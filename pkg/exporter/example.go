@@ -0,0 +1,145 @@
+package exporter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// decimalExample is the sample decimal of number schemas
+var decimalExample, _ = strconv.ParseFloat(sample.Decimal, 64)
+
+// addExamples sets the examples of the request bodies, parameters and
+// successful responses of the operations of spec, from their schemas
+func (spec *OpenAPISpec) addExamples() {
+	for _, item := range spec.Paths {
+		for _, m := range item.Operations() {
+			op := m.Operation
+			for i, param := range op.Parameters {
+				op.Parameters[i].Example = exampleValue(param.Schema, param.Name)
+			}
+			if op.RequestBody != nil {
+				addMediaExamples(op.RequestBody.Content)
+			}
+			if resp, ok := op.Responses["200"]; ok {
+				addMediaExamples(resp.Content)
+			}
+		}
+	}
+}
+
+// addMediaExamples sets the example of each media type of content
+func addMediaExamples(content map[string]OpenAPIMediaType) {
+	for mediaType, media := range content {
+		media.Example = exampleValue(media.Schema, "")
+		content[mediaType] = media
+	}
+}
+
+// exampleValue returns an example JSON value of a schema, with the values
+// the generated mock server also uses and the element or attribute name for
+// strings.
+// Arrays get one item, enumerations their first value, choices their first
+// element and oneOf the first alternative carrying its discriminator, so
+// that derived types come before their base. It returns nil when the value is better left out, as
+// for references and the plain objects that stop recursive types.
+func exampleValue(schema *OpenAPISchema, name string) interface{} {
+	if schema == nil || schema.Ref != "" {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	if len(schema.OneOf) > 0 {
+		return exampleValue(alternative(schema), name)
+	}
+	if len(schema.AllOf) > 0 {
+		obj := make(map[string]interface{})
+		for _, part := range schema.AllOf {
+			if fields, ok := exampleValue(part, name).(map[string]interface{}); ok {
+				for field, value := range fields {
+					obj[field] = value
+				}
+			}
+		}
+		return obj
+	}
+
+	switch schema.Type {
+	case "object":
+		if schema.Properties == nil {
+			return nil
+		}
+		others := make(map[string]bool)
+		for _, members := range schema.choices {
+			for i, member := range members {
+				others[member] = i > 0
+			}
+		}
+		obj := make(map[string]interface{}, len(schema.Properties))
+		for field, prop := range schema.Properties {
+			if others[field] {
+				continue
+			}
+			// The text of simple content is named after its element
+			fieldName := field
+			if field == "#text" {
+				fieldName = name
+			}
+			if value := exampleValue(prop, fieldName); value != nil {
+				obj[field] = value
+			}
+		}
+		return obj
+	case "array":
+		item := exampleValue(schema.Items, name)
+		if item == nil {
+			return nil
+		}
+		return []interface{}{item}
+	case "integer":
+		return sample.Integer
+	case "number":
+		if schema.Format == "decimal" {
+			return decimalExample
+		}
+		return sample.Float
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return sample.DateTime
+		case "date":
+			return sample.Date
+		case "time":
+			return sample.Time
+		case "decimal":
+			return sample.Decimal
+		}
+		return strings.TrimPrefix(name, "@")
+	}
+	return nil
+}
+
+// alternative returns the first alternative of a oneOf that requires the
+// discriminator property, which the base type of a hierarchy doesn't, or
+// the first alternative when none does
+func alternative(schema *OpenAPISchema) *OpenAPISchema {
+	if schema.Discriminator != nil {
+		for _, alt := range schema.OneOf {
+			own := alt
+			if len(alt.AllOf) > 0 {
+				own = alt.AllOf[len(alt.AllOf)-1]
+			}
+			if isRequired(own, schema.Discriminator.PropertyName) {
+				return alt
+			}
+		}
+	}
+	return schema.OneOf[0]
+}
@@ -0,0 +1,516 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// GraphQL scalars used besides the built-in ones
+const (
+	// GraphQLLong holds 64-bit integers, which don't fit GraphQL's Int
+	GraphQLLong = "Long"
+	// GraphQLJSON holds values without a known structure
+	GraphQLJSON = "JSON"
+)
+
+// readPrefixes are the operation name prefixes of read-style operations,
+// which become queries
+var readPrefixes = []string{
+	"get", "list", "find", "search", "query", "read", "fetch", "lookup",
+	"check", "count", "consulta", "consult", "is", "has",
+}
+
+// GraphQLSchema is a GraphQL schema of the REST facade. Read-style
+// operations are queries and the others mutations; each takes the REST
+// request body as its input argument and returns the REST response.
+type GraphQLSchema struct {
+	// Name is the service name the _service placeholder query returns
+	Name        string
+	Description string
+	Queries     []GraphQLField
+	Mutations   []GraphQLField
+	// Types are the object, input, union and enum types in declaration
+	// order
+	Types []GraphQLType
+	// Scalars are the custom scalars the types use
+	Scalars []string
+}
+
+// GraphQLType is an object or input type, a union when it has members or
+// an enum when it has values
+type GraphQLType struct {
+	Name   string
+	Input  bool
+	Fields []GraphQLField
+	// Members are the object types of a union, told apart by the value of
+	// the Discriminator property of the REST body
+	Members       []GraphQLUnionMember
+	Discriminator string
+	Values        []GraphQLEnumValue
+}
+
+// GraphQLUnionMember is an object type of a union, with the value of the
+// discriminator property of its REST bodies. The first member is used for
+// bodies without a known value.
+type GraphQLUnionMember struct {
+	Type  string
+	Value string
+}
+
+// GraphQLEnumValue is a value of an enum, with the value of the REST body
+// it stands for
+type GraphQLEnumValue struct {
+	Name  string
+	Value string
+}
+
+// GraphQLField is a field of an object or input type, or a query or
+// mutation
+type GraphQLField struct {
+	Name string
+	// JSONName is the property of the REST body the field maps to
+	JSONName string
+	// Method and Path are the REST route of the operation a query or
+	// mutation calls
+	Method      string
+	Path        string
+	Description string
+	Type        GraphQLTypeRef
+	Args        []GraphQLField
+}
+
+// GraphQLTypeRef references a named type, possibly as a list
+type GraphQLTypeRef struct {
+	Name        string
+	NonNull     bool
+	List        bool
+	ItemNonNull bool
+}
+
+// String formats the reference in SDL, such as [Item!]!
+func (ref GraphQLTypeRef) String() string {
+	s := ref.Name
+	if ref.List {
+		if ref.ItemNonNull {
+			s += "!"
+		}
+		s = "[" + s + "]"
+	}
+	if ref.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// ConvertOpenAPIToGraphQL converts the operations of an OpenAPI spec to a
+// GraphQL schema. Nested objects become types named after their parent
+// and property, and names are made valid GraphQL names.
+func ConvertOpenAPIToGraphQL(spec *OpenAPISpec) *GraphQLSchema {
+	c := &graphQLConverter{
+		schema:  &GraphQLSchema{Name: spec.Info.Title, Description: spec.Info.Description},
+		types:   naming.NewNamer("GraphQL type", graphQLTypeName),
+		scalars: make(map[string]bool),
+		enums:   make(map[string]string),
+	}
+	c.types.Reserve("Query", "Mutation", GraphQLLong, GraphQLJSON)
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fields := naming.NewNamer("GraphQL field", graphQLFieldName)
+	for _, path := range paths {
+		for _, m := range spec.Paths[path].Operations() {
+			c.convertOperation(fields, m.Method, path, m.Operation)
+		}
+	}
+
+	// GraphQL requires a query, so services without read-style operations
+	// get one returning the service name
+	if len(c.schema.Queries) == 0 {
+		c.schema.Queries = append(c.schema.Queries, GraphQLField{
+			Name:        "_service",
+			Description: "Name of the service",
+			Type:        GraphQLTypeRef{Name: "String", NonNull: true},
+		})
+	}
+
+	for scalar := range c.scalars {
+		c.schema.Scalars = append(c.schema.Scalars, scalar)
+	}
+	sort.Strings(c.schema.Scalars)
+
+	return c.schema
+}
+
+// convertOperation adds the query or mutation calling a REST operation.
+// GET operations and those with read-style names become queries.
+func (c *graphQLConverter) convertOperation(fields *naming.Namer, method, path string, op *OpenAPIOperation) {
+	field := GraphQLField{
+		Name:        fields.Name(naming.Camel(op.OperationID)),
+		Method:      method,
+		Path:        path,
+		Description: op.Description,
+		Type:        GraphQLTypeRef{Name: GraphQLJSON},
+	}
+
+	if request := op.RequestSchema(); request != nil && len(request.Properties) > 0 {
+		input := c.typeRef(op.OperationID+"Input", request, true)
+		input.NonNull = true
+		field.Args = []GraphQLField{{Name: "input", Type: input}}
+	}
+	if resp, ok := op.Responses["200"]; ok {
+		if content, ok := resp.Content["application/json"]; ok {
+			field.Type = c.typeRef(op.OperationID+"Result", content.Schema, false)
+		}
+	}
+	if field.Type.Name == GraphQLJSON {
+		c.scalars[GraphQLJSON] = true
+	}
+
+	if method == "GET" || isReadOperation(op.Summary) {
+		c.schema.Queries = append(c.schema.Queries, field)
+	} else {
+		c.schema.Mutations = append(c.schema.Mutations, field)
+	}
+}
+
+// graphQLConverter converts OpenAPI schemas to GraphQL types
+type graphQLConverter struct {
+	schema  *GraphQLSchema
+	types   *naming.Namer
+	scalars map[string]bool
+	enums   map[string]string // Enum types by their values, which they share
+}
+
+// typeRef returns the reference to the GraphQL type of a schema, declaring
+// object types named name. Input fields are non-null when required; output
+// fields are nullable since backends may leave out required elements.
+// Derived types are objects with the fields of their base, and string
+// enumerations enums.
+func (c *graphQLConverter) typeRef(name string, schema *OpenAPISchema, input bool) GraphQLTypeRef {
+	if schema == nil {
+		c.scalars[GraphQLJSON] = true
+		return GraphQLTypeRef{Name: GraphQLJSON}
+	}
+	if len(schema.AllOf) > 0 {
+		return c.typeRef(name, mergeAllOf(schema), input)
+	}
+	if len(schema.OneOf) > 0 {
+		return c.unionRef(name, schema, input)
+	}
+
+	switch schema.Type {
+	case "array":
+		item := c.typeRef(name, schema.Items, input)
+		return GraphQLTypeRef{Name: item.Name, List: true, ItemNonNull: schema.Items != nil && !schema.Items.Nullable}
+	case "object":
+		if len(schema.Properties) == 0 {
+			c.scalars[GraphQLJSON] = true
+			return GraphQLTypeRef{Name: GraphQLJSON}
+		}
+		return GraphQLTypeRef{Name: c.objectType(name, schema, input)}
+	case "integer":
+		if schema.Format == "int64" {
+			c.scalars[GraphQLLong] = true
+			return GraphQLTypeRef{Name: GraphQLLong}
+		}
+		return GraphQLTypeRef{Name: "Int"}
+	case "number":
+		return GraphQLTypeRef{Name: "Float"}
+	case "boolean":
+		return GraphQLTypeRef{Name: "Boolean"}
+	default:
+		if len(schema.Enum) > 0 {
+			return GraphQLTypeRef{Name: c.enumType(name, schema.Enum)}
+		}
+		return GraphQLTypeRef{Name: "String"}
+	}
+}
+
+// unionRef returns the reference to the type of alternatives. Outputs are
+// unions of an object type per alternative. GraphQL has no input unions,
+// so inputs are an input type with the optional fields of every
+// alternative, as are alternatives that aren't all objects.
+func (c *graphQLConverter) unionRef(name string, schema *OpenAPISchema, input bool) GraphQLTypeRef {
+	var discriminator string
+	if schema.Discriminator != nil {
+		discriminator = schema.Discriminator.PropertyName
+	}
+	alts := make([]*OpenAPISchema, len(schema.OneOf))
+	objects := true
+	for i, alt := range schema.OneOf {
+		if alt != nil && len(alt.AllOf) > 0 {
+			alt = mergeAllOf(alt)
+		}
+		alts[i] = alt
+		objects = objects && alt != nil && alt.Type == "object" && len(alt.Properties) > 0
+	}
+
+	if input || !objects {
+		merged := &OpenAPISchema{Type: "object", Description: schema.Description, Properties: make(map[string]*OpenAPISchema)}
+		for _, alt := range alts {
+			if alt == nil {
+				continue
+			}
+			for prop, propSchema := range alt.Properties {
+				merged.Properties[prop] = propSchema
+			}
+		}
+		if discriminator != "" {
+			merged.Properties[discriminator] = &OpenAPISchema{Type: "string"}
+		}
+		return c.typeRef(name, merged, input)
+	}
+
+	typeName := c.types.Next(name)
+	index := len(c.schema.Types)
+	c.schema.Types = append(c.schema.Types, GraphQLType{Name: typeName, Discriminator: discriminator})
+
+	// The discriminator of each alternative has its only value, which names
+	// the member and is a plain string of its fields
+	var members []GraphQLUnionMember
+	for i, alt := range alts {
+		var value string
+		if prop := alt.Properties[discriminator]; prop != nil && len(prop.Enum) == 1 {
+			value = fmt.Sprint(prop.Enum[0])
+			alt = withProperty(alt, discriminator, &OpenAPISchema{Type: "string"})
+		}
+		member := value
+		if member == "" {
+			member = strconv.Itoa(i + 1)
+		}
+		members = append(members, GraphQLUnionMember{Type: c.objectType(typeName+naming.Pascal(member), alt, false), Value: value})
+	}
+	c.schema.Types[index].Members = members
+
+	return GraphQLTypeRef{Name: typeName}
+}
+
+// enumType declares an enum type for the values of a string enumeration,
+// or returns the one declared for the same values, and returns its name.
+// Values that aren't GraphQL names are renamed.
+func (c *graphQLConverter) enumType(name string, values []interface{}) string {
+	key := fmt.Sprintf("%q", values)
+	if typeName, ok := c.enums[key]; ok {
+		return typeName
+	}
+
+	typeName := c.types.Next(name)
+	names := naming.NewNamer(typeName+" value", graphQLFieldName)
+	names.Reserve("true", "false", "null")
+	enum := GraphQLType{Name: typeName}
+	for _, v := range values {
+		value := fmt.Sprint(v)
+		enum.Values = append(enum.Values, GraphQLEnumValue{Name: names.Next(value), Value: value})
+	}
+	c.schema.Types = append(c.schema.Types, enum)
+	c.enums[key] = typeName
+
+	return typeName
+}
+
+// mergeAllOf returns an object schema with the properties and required
+// properties of the parts of an allOf, merged in order
+func mergeAllOf(schema *OpenAPISchema) *OpenAPISchema {
+	merged := &OpenAPISchema{
+		Type:        "object",
+		Description: schema.Description,
+		Properties:  make(map[string]*OpenAPISchema),
+	}
+	parts := append(append([]*OpenAPISchema{}, schema.AllOf...), schema)
+	for _, part := range parts {
+		if part == nil {
+			continue
+		}
+		if part != schema && len(part.AllOf) > 0 {
+			part = mergeAllOf(part)
+		}
+		for prop, propSchema := range part.Properties {
+			merged.Properties[prop] = propSchema
+		}
+		for _, required := range part.Required {
+			if !isRequired(merged, required) {
+				merged.Required = append(merged.Required, required)
+			}
+		}
+	}
+	return merged
+}
+
+// withProperty returns a copy of an object schema with the schema of a
+// property replaced
+func withProperty(schema *OpenAPISchema, prop string, propSchema *OpenAPISchema) *OpenAPISchema {
+	copied := *schema
+	copied.Properties = make(map[string]*OpenAPISchema, len(schema.Properties))
+	for name, s := range schema.Properties {
+		copied.Properties[name] = s
+	}
+	copied.Properties[prop] = propSchema
+	return &copied
+}
+
+// objectType declares an object or input type for a schema with properties
+// and returns its name
+func (c *graphQLConverter) objectType(name string, schema *OpenAPISchema, input bool) string {
+	typeName := c.types.Next(name)
+	index := len(c.schema.Types)
+	c.schema.Types = append(c.schema.Types, GraphQLType{Name: typeName, Input: input})
+
+	fields := naming.NewNamer(typeName+" field", graphQLFieldName)
+	var declared []GraphQLField
+	for _, prop := range sortedProperties(schema) {
+		propSchema := schema.Properties[prop]
+		ref := c.typeRef(typeName+naming.Pascal(prop), propSchema, input)
+		if input && isRequired(schema, prop) && !propSchema.Nullable {
+			ref.NonNull = true
+		}
+		declared = append(declared, GraphQLField{
+			Name:     fields.Next(prop),
+			JSONName: prop,
+			Type:     ref,
+		})
+	}
+	c.schema.Types[index].Fields = declared
+
+	return typeName
+}
+
+// sortedProperties returns the property names of a schema in a stable order
+func sortedProperties(schema *OpenAPISchema) []string {
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	return props
+}
+
+// isRequired reports whether a property of an object schema is required
+func isRequired(schema *OpenAPISchema, prop string) bool {
+	for _, required := range schema.Required {
+		if required == prop {
+			return true
+		}
+	}
+	return false
+}
+
+// isReadOperation reports whether an operation name starts with a read
+// prefix followed by a new word, as in getUser or Get_User
+func isReadOperation(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range readPrefixes {
+		if !strings.HasPrefix(lower, prefix) {
+			continue
+		}
+		rest := []rune(name[len(prefix):])
+		if len(rest) == 0 || !unicode.IsLower(rest[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLFieldName makes a name a valid GraphQL name, which is limited to
+// ASCII letters, digits and underscores
+func graphQLFieldName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_'):
+			b.WriteRune(r)
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// graphQLTypeName converts a name to a GraphQL type name
+func graphQLTypeName(name string) string {
+	return graphQLFieldName(naming.Pascal(name))
+}
+
+// ExportToSDL renders the schema in the GraphQL schema definition language
+func (schema *GraphQLSchema) ExportToSDL() string {
+	var b strings.Builder
+
+	if schema.Description != "" {
+		writeGraphQLDescription(&b, "", schema.Description)
+		b.WriteString("schema {\n  query: Query\n")
+		if len(schema.Mutations) > 0 {
+			b.WriteString("  mutation: Mutation\n")
+		}
+		b.WriteString("}\n\n")
+	}
+
+	writeGraphQLObject(&b, "type", "Query", schema.Queries)
+	if len(schema.Mutations) > 0 {
+		writeGraphQLObject(&b, "type", "Mutation", schema.Mutations)
+	}
+	for _, t := range schema.Types {
+		switch {
+		case len(t.Members) > 0:
+			members := make([]string, len(t.Members))
+			for i, member := range t.Members {
+				members[i] = member.Type
+			}
+			b.WriteString(fmt.Sprintf("union %s = %s\n\n", t.Name, strings.Join(members, " | ")))
+		case len(t.Values) > 0:
+			b.WriteString(fmt.Sprintf("enum %s {\n", t.Name))
+			for _, value := range t.Values {
+				b.WriteString(fmt.Sprintf("  %s\n", value.Name))
+			}
+			b.WriteString("}\n\n")
+		case t.Input:
+			writeGraphQLObject(&b, "input", t.Name, t.Fields)
+		default:
+			writeGraphQLObject(&b, "type", t.Name, t.Fields)
+		}
+	}
+	for _, scalar := range schema.Scalars {
+		b.WriteString(fmt.Sprintf("scalar %s\n\n", scalar))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeGraphQLObject writes an object or input type definition
+func writeGraphQLObject(b *strings.Builder, kind, name string, fields []GraphQLField) {
+	b.WriteString(fmt.Sprintf("%s %s {\n", kind, name))
+	for _, field := range fields {
+		if field.Description != "" {
+			writeGraphQLDescription(b, "  ", field.Description)
+		}
+
+		var args []string
+		for _, arg := range field.Args {
+			args = append(args, fmt.Sprintf("%s: %s", arg.Name, arg.Type))
+		}
+		if len(args) > 0 {
+			b.WriteString(fmt.Sprintf("  %s(%s): %s\n", field.Name, strings.Join(args, ", "), field.Type))
+		} else {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", field.Name, field.Type))
+		}
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeGraphQLDescription writes a block string description
+func writeGraphQLDescription(b *strings.Builder, indent, description string) {
+	description = strings.ReplaceAll(description, `"""`, `\"""`)
+	b.WriteString(fmt.Sprintf("%s\"\"\"\n%s%s\n%s\"\"\"\n", indent, indent, description, indent))
+}
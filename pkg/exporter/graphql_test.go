@@ -0,0 +1,134 @@
+package exporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestConvertOpenAPIToGraphQL(t *testing.T) {
+	def := &models.Definitions{
+		Name: "Users",
+		Types: []models.Type{
+			{Name: "Address", Elements: []models.Element{{Name: "city", Type: "xs:string"}}},
+			{Name: "User", Elements: []models.Element{
+				{Name: "id", Type: "xs:long"},
+				{Name: "address", Type: "tns:Address", MinOccurs: "0"},
+				{Name: "tag", Type: "xs:string", MinOccurs: "0", MaxOccurs: "unbounded"},
+			}},
+		},
+		Messages: []models.Message{
+			{Name: "GetUserIn", Parts: []models.Part{{Name: "user-id", Type: "xs:long"}}},
+			{Name: "GetUserOut", Parts: []models.Part{{Name: "user", Type: "tns:User"}}},
+			{Name: "SaveUserIn", Parts: []models.Part{{Name: "user", Type: "tns:User"}}},
+			{Name: "SaveUserOut", Parts: []models.Part{{Name: "ok", Type: "xs:boolean"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:GetUserIn"}, Output: models.Message{Name: "tns:GetUserOut"}},
+			{Name: "Getaway", Input: models.Message{Name: "tns:SaveUserIn"}, Output: models.Message{Name: "tns:SaveUserOut"}},
+			{Name: "SaveUser", Input: models.Message{Name: "tns:SaveUserIn"}, Output: models.Message{Name: "tns:SaveUserOut"}},
+		}}},
+	}
+
+	spec, err := ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatalf("ConvertWSDLToOpenAPI() error = %v", err)
+	}
+	schema := ConvertOpenAPIToGraphQL(spec)
+
+	if len(schema.Queries) != 1 || schema.Queries[0].Name != "getUser" || schema.Queries[0].Path != "/api/GetUser" {
+		t.Errorf("unexpected queries: %+v", schema.Queries)
+	}
+	if len(schema.Mutations) != 2 || schema.Mutations[0].Name != "getaway" || schema.Mutations[1].Name != "saveUser" {
+		t.Errorf("unexpected mutations: %+v", schema.Mutations)
+	}
+
+	sdl := schema.ExportToSDL()
+	for _, want := range []string{
+		"getUser(input: GetUserInput!): GetUserResult",
+		"input GetUserInput {\n  user_id: Long!\n}",
+		"type GetUserResultUser {\n  address: GetUserResultUserAddress\n  id: Long\n  tag: [String!]\n}",
+		"input SaveUserInputUser {",
+		"scalar Long",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL missing %q:\n%s", want, sdl)
+		}
+	}
+
+	for _, field := range schema.Types[0].Fields {
+		if field.Name == "user_id" && field.JSONName != "user-id" {
+			t.Errorf("JSONName = %q, want \"user-id\"", field.JSONName)
+		}
+	}
+}
+
+func TestGraphQLUnionsAndEnums(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "Vehicle", Elements: []models.Element{{Name: "plate", Type: "xs:string"}, {Name: "status", Type: "tns:Status"}}},
+			{Name: "Car", Base: "tns:Vehicle", Derivation: models.DerivationExtension, Elements: []models.Element{
+				{Name: "plate", Type: "xs:string"}, {Name: "status", Type: "tns:Status"}, {Name: "doors", Type: "xs:int"},
+			}},
+			{Name: "GetVehicleResponse", Elements: []models.Element{{Name: "vehicle", Type: "tns:Vehicle"}}},
+			{Name: "SaveVehicle", Elements: []models.Element{{Name: "vehicle", Type: "tns:Vehicle"}, {Name: "status", Type: "tns:Status"}}},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"active", "in-repair", "null"}}},
+		Elements: []models.Element{
+			{Name: "GetVehicleResponse", Type: "tns:GetVehicleResponse"},
+			{Name: "SaveVehicle", Type: "tns:SaveVehicle"},
+		},
+		Messages: []models.Message{
+			{Name: "GetVehicleIn", Parts: []models.Part{{Name: "plate", Type: "xs:string"}}},
+			{Name: "GetVehicleOut", Parts: []models.Part{{Name: "parameters", Element: "tns:GetVehicleResponse"}}},
+			{Name: "SaveVehicleIn", Parts: []models.Part{{Name: "parameters", Element: "tns:SaveVehicle"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetVehicle", Input: models.Message{Name: "tns:GetVehicleIn"}, Output: models.Message{Name: "tns:GetVehicleOut"}},
+			{Name: "SaveVehicle", Input: models.Message{Name: "tns:SaveVehicleIn"}},
+		}}},
+	}
+
+	spec, err := ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatalf("ConvertWSDLToOpenAPI() error = %v", err)
+	}
+	schema := ConvertOpenAPIToGraphQL(spec)
+
+	// Derived types merge their base into one object, alternatives are
+	// unions for outputs and merged input types for inputs, and enums are
+	// shared by the fields using them
+	sdl := schema.ExportToSDL()
+	for _, want := range []string{
+		"union GetVehicleResultVehicle = GetVehicleResultVehicleVehicle | GetVehicleResultVehicleCar",
+		"type GetVehicleResultVehicleCar {\n  _xsi_type: String\n  doors: Int\n  plate: String\n  status: GetVehicleResultVehicleVehicleStatus\n}",
+		"enum GetVehicleResultVehicleVehicleStatus {\n  active\n  in_repair\n  null2\n}",
+		"input SaveVehicleInput {\n  status: GetVehicleResultVehicleVehicleStatus!\n  vehicle: SaveVehicleInputVehicle!\n}",
+		"input SaveVehicleInputVehicle {\n  _xsi_type: String\n  doors: Int\n  plate: String\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL missing %q:\n%s", want, sdl)
+		}
+	}
+
+	for _, typ := range schema.Types {
+		if typ.Name == "GetVehicleResultVehicle" {
+			want := []GraphQLUnionMember{{Type: "GetVehicleResultVehicleVehicle", Value: "Vehicle"}, {Type: "GetVehicleResultVehicleCar", Value: "Car"}}
+			if typ.Discriminator != "@xsi:type" || !reflect.DeepEqual(typ.Members, want) {
+				t.Errorf("union = %+v, want members %+v told apart by @xsi:type", typ, want)
+			}
+		}
+		if len(typ.Values) > 0 && typ.Values[1] != (GraphQLEnumValue{Name: "in_repair", Value: "in-repair"}) {
+			t.Errorf("enum value = %+v, want in_repair for in-repair", typ.Values[1])
+		}
+	}
+}
+
+func TestGraphQLServicePlaceholder(t *testing.T) {
+	schema := ConvertOpenAPIToGraphQL(&OpenAPISpec{Info: OpenAPIInfo{Title: "Calc"}})
+	if len(schema.Queries) != 1 || schema.Queries[0].Name != "_service" {
+		t.Errorf("expected _service placeholder query, got %+v", schema.Queries)
+	}
+}
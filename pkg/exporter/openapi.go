@@ -3,18 +3,29 @@ package exporter
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+	"github.com/thdev01/wsdl2api/pkg/routes"
 )
 
 // OpenAPISpec represents an OpenAPI 3.0 specification
 type OpenAPISpec struct {
-	OpenAPI string                 `json:"openapi"`
-	Info    OpenAPIInfo            `json:"info"`
-	Servers []OpenAPIServer        `json:"servers,omitempty"`
-	Paths   map[string]OpenAPIPath `json:"paths"`
-	Components *OpenAPIComponents  `json:"components,omitempty"`
+	OpenAPI    string                       `json:"openapi"`
+	Info       OpenAPIInfo                  `json:"info"`
+	Servers    []OpenAPIServer              `json:"servers,omitempty"`
+	Tags       []OpenAPITag                 `json:"tags,omitempty"`
+	Paths      map[string]OpenAPIPath       `json:"paths"`
+	Components *OpenAPIComponents           `json:"components,omitempty"`
+	Security   []OpenAPISecurityRequirement `json:"security,omitempty"`
+}
+
+// OpenAPITag groups operations: there is one per port type
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
 }
 
 // OpenAPIInfo contains API metadata
@@ -32,18 +43,96 @@ type OpenAPIServer struct {
 
 // OpenAPIPath describes operations on a path
 type OpenAPIPath struct {
-	Post *OpenAPIOperation `json:"post,omitempty"`
-	Get  *OpenAPIOperation `json:"get,omitempty"`
+	Post   *OpenAPIOperation `json:"post,omitempty"`
+	Get    *OpenAPIOperation `json:"get,omitempty"`
+	Put    *OpenAPIOperation `json:"put,omitempty"`
+	Patch  *OpenAPIOperation `json:"patch,omitempty"`
+	Delete *OpenAPIOperation `json:"delete,omitempty"`
+}
+
+// OpenAPIMethodOperation is an operation of a path and its HTTP method
+type OpenAPIMethodOperation struct {
+	Method    string
+	Operation *OpenAPIOperation
+}
+
+// Operations returns the operations of the path with their upper-case
+// methods, in a fixed order
+func (item OpenAPIPath) Operations() []OpenAPIMethodOperation {
+	var ops []OpenAPIMethodOperation
+	for _, m := range []OpenAPIMethodOperation{
+		{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch}, {"DELETE", item.Delete},
+	} {
+		if m.Operation != nil {
+			ops = append(ops, m)
+		}
+	}
+	return ops
+}
+
+// set sets the operation of an upper-case HTTP method
+func (item *OpenAPIPath) set(method string, op *OpenAPIOperation) {
+	switch method {
+	case "GET":
+		item.Get = op
+	case "PUT":
+		item.Put = op
+	case "PATCH":
+		item.Patch = op
+	case "DELETE":
+		item.Delete = op
+	default:
+		item.Post = op
+	}
 }
 
 // OpenAPIOperation describes a single operation
 type OpenAPIOperation struct {
-	Summary     string                        `json:"summary,omitempty"`
-	Description string                        `json:"description,omitempty"`
-	OperationID string                        `json:"operationId,omitempty"`
-	RequestBody *OpenAPIRequestBody           `json:"requestBody,omitempty"`
-	Responses   map[string]OpenAPIResponse    `json:"responses"`
-	Tags        []string                      `json:"tags,omitempty"`
+	Summary     string                       `json:"summary,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	OperationID string                       `json:"operationId,omitempty"`
+	Parameters  []OpenAPIParameter           `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse   `json:"responses"`
+	Tags        []string                     `json:"tags,omitempty"`
+	Security    []OpenAPISecurityRequirement `json:"security,omitempty"`
+}
+
+// OpenAPIParameter describes a path or query parameter
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema,omitempty"`
+	Example  interface{}    `json:"example,omitempty"`
+}
+
+// RequestSchema returns the object schema of every input field of the
+// operation, from the request body and the parameters, or nil when the
+// operation has no input
+func (op *OpenAPIOperation) RequestSchema() *OpenAPISchema {
+	var body *OpenAPISchema
+	if op.RequestBody != nil {
+		body = op.RequestBody.Content["application/json"].Schema
+	}
+	if len(op.Parameters) == 0 {
+		return body
+	}
+
+	schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	if body != nil {
+		for name, prop := range body.Properties {
+			schema.Properties[name] = prop
+		}
+		schema.Required = append(schema.Required, body.Required...)
+	}
+	for _, param := range op.Parameters {
+		schema.Properties[param.Name] = param.Schema
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+	return schema
 }
 
 // OpenAPIRequestBody describes a request body
@@ -61,30 +150,70 @@ type OpenAPIResponse struct {
 
 // OpenAPIMediaType describes a media type
 type OpenAPIMediaType struct {
-	Schema *OpenAPISchema `json:"schema,omitempty"`
+	Schema  *OpenAPISchema `json:"schema,omitempty"`
+	Example interface{}    `json:"example,omitempty"`
 }
 
 // OpenAPISchema describes a schema
 type OpenAPISchema struct {
-	Type       string                    `json:"type,omitempty"`
-	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
-	Items      *OpenAPISchema            `json:"items,omitempty"`
-	Ref        string                    `json:"$ref,omitempty"`
-	Format     string                    `json:"format,omitempty"`
+	Type          string                    `json:"type,omitempty"`
+	Description   string                    `json:"description,omitempty"`
+	Properties    map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Items         *OpenAPISchema            `json:"items,omitempty"`
+	Ref           string                    `json:"$ref,omitempty"`
+	Format        string                    `json:"format,omitempty"`
+	Enum          []interface{}             `json:"enum,omitempty"`
+	Pattern       string                    `json:"pattern,omitempty"`
+	Nullable      bool                      `json:"nullable,omitempty"`
+	Example       interface{}               `json:"example,omitempty"`
+	OneOf         []*OpenAPISchema          `json:"oneOf,omitempty"`
+	AllOf         []*OpenAPISchema          `json:"allOf,omitempty"`
+	Discriminator *OpenAPIDiscriminator     `json:"discriminator,omitempty"`
+	Required      []string                  `json:"required,omitempty"`
+	// choices are the properties of each xs:choice, of which examples set
+	// the first only
+	choices [][]string
 }
 
+// OpenAPIDiscriminator names the property telling the alternatives of a
+// oneOf apart
+type OpenAPIDiscriminator struct {
+	PropertyName string `json:"propertyName"`
+}
+
+// Properties telling apart the alternatives of elements in the JSON of the
+// REST proxy: the xsi:type of polymorphic elements, and the name of the
+// element substituting the head of a substitution group
+const (
+	xsiTypeProperty = "@xsi:type"
+	elementProperty = "#element"
+)
+
 // OpenAPIComponents contains reusable components
 type OpenAPIComponents struct {
-	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+	Schemas         map[string]*OpenAPISchema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
 }
 
-// ConvertWSDLToOpenAPI converts WSDL definitions to OpenAPI spec
+// ConvertWSDLToOpenAPI converts WSDL definitions to OpenAPI spec, with
+// every operation at POST /api/{Operation}
 func ConvertWSDLToOpenAPI(def *models.Definitions) (*OpenAPISpec, error) {
+	return ConvertWSDLToOpenAPIWithRoutes(def, nil)
+}
+
+// ConvertWSDLToOpenAPIWithRoutes converts WSDL definitions to OpenAPI spec
+// with the operation routes of cfg. Input fields of GET and DELETE routes
+// become query parameters, and those named in the path path parameters.
+// The wsdl:documentation of the services, port types and operations and
+// the xs:documentation of the schema become descriptions, with a tag per
+// port type. Requests, parameters and successful responses get examples
+// with the values of the mock server's fixtures.
+func ConvertWSDLToOpenAPIWithRoutes(def *models.Definitions, cfg *routes.Config) (*OpenAPISpec, error) {
 	spec := &OpenAPISpec{
 		OpenAPI: "3.0.0",
 		Info: OpenAPIInfo{
 			Title:       def.Name,
-			Description: fmt.Sprintf("API converted from WSDL: %s", def.TargetNamespace),
+			Description: serviceDescription(def),
 			Version:     "1.0.0",
 		},
 		Paths: make(map[string]OpenAPIPath),
@@ -105,32 +234,45 @@ func ConvertWSDLToOpenAPI(def *models.Definitions) (*OpenAPISpec, error) {
 		}
 	}
 
-	// Convert operations
+	// Convert operations. The operationIds are the Go method names of the
+	// generated client, which are valid and unique identifiers.
+	names := naming.Operations(def)
+	tagged := make(map[string]bool)
 	for _, portType := range def.PortTypes {
+		if !tagged[portType.Name] {
+			tagged[portType.Name] = true
+			spec.Tags = append(spec.Tags, OpenAPITag{Name: portType.Name, Description: portType.Documentation})
+		}
 		for _, op := range portType.Operations {
-			path := fmt.Sprintf("/api/%s", op.Name)
+			route := cfg.Route(op.Name)
+			path := "/api" + route.Path
 
 			// Find input/output messages
-			inputMsg := findMessage(def, op.Input.Name)
-			outputMsg := findMessage(def, op.Output.Name)
+			inputMsg := def.Message(op.Input.Name)
+			outputMsg := def.Message(op.Output.Name)
 
 			operation := &OpenAPIOperation{
 				Summary:     op.Name,
 				Description: op.Documentation,
-				OperationID: op.Name,
+				OperationID: names.Name(op.Name),
 				Responses:   make(map[string]OpenAPIResponse),
+				Tags:        []string{portType.Name},
 			}
 
-			// Add request body
+			// Add parameters and request body
 			if inputMsg != nil {
-				operation.RequestBody = &OpenAPIRequestBody{
-					Description: fmt.Sprintf("Request for %s operation", op.Name),
-					Required:    true,
-					Content: map[string]OpenAPIMediaType{
-						"application/json": {
-							Schema: convertMessageToSchema(inputMsg),
+				schema := convertMessageToSchema(def, inputMsg)
+				operation.Parameters = routeParameters(schema, route)
+				if route.HasBody() {
+					operation.RequestBody = &OpenAPIRequestBody{
+						Description: fmt.Sprintf("Request for %s operation", op.Name),
+						Required:    true,
+						Content: map[string]OpenAPIMediaType{
+							"application/json": {
+								Schema: schema,
+							},
 						},
-					},
+					}
 				}
 			}
 
@@ -140,63 +282,423 @@ func ConvertWSDLToOpenAPI(def *models.Definitions) (*OpenAPISpec, error) {
 					Description: fmt.Sprintf("Successful response for %s", op.Name),
 					Content: map[string]OpenAPIMediaType{
 						"application/json": {
-							Schema: convertMessageToSchema(outputMsg),
+							Schema: convertMessageToSchema(def, outputMsg),
 						},
 					},
 				}
 			}
 
-			// Add error response
-			operation.Responses["500"] = OpenAPIResponse{
-				Description: "SOAP Fault",
-				Content: map[string]OpenAPIMediaType{
-					"application/json": {
-						Schema: &OpenAPISchema{
-							Type: "object",
-							Properties: map[string]*OpenAPISchema{
-								"faultcode":   {Type: "string"},
-								"faultstring": {Type: "string"},
-								"detail":      {Type: "string"},
-							},
-						},
-					},
-				},
+			// Add fault responses: client faults map to 400, all others to 502
+			detail := faultDetailSchema(def, spec, op)
+			operation.Responses["400"] = faultResponse("SOAP Client fault", detail)
+			operation.Responses["502"] = faultResponse("SOAP Server fault", detail)
+
+			item := spec.Paths[path]
+			item.set(route.Method, operation)
+			spec.Paths[path] = item
+		}
+	}
+	spec.addExamples()
+
+	return spec, nil
+}
+
+// serviceDescription returns the documentation of the services of def, or
+// names the WSDL when they have none
+func serviceDescription(def *models.Definitions) string {
+	var docs []string
+	for _, svc := range def.Services {
+		if svc.Documentation != "" {
+			docs = append(docs, svc.Documentation)
+		}
+	}
+	if len(docs) == 0 {
+		return fmt.Sprintf("API converted from WSDL: %s", def.TargetNamespace)
+	}
+	return strings.Join(docs, "\n\n")
+}
+
+// routeParameters moves the input fields named in the route's path out of
+// the request schema into path parameters, which name attributes without
+// their @. Without a request body, the other fields become query
+// parameters.
+func routeParameters(schema *OpenAPISchema, route routes.Route) []OpenAPIParameter {
+	var params []OpenAPIParameter
+	for _, name := range route.PathParams() {
+		param := OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"}}
+		field := name
+		if _, ok := schema.Properties[field]; !ok {
+			field = "@" + name
+		}
+		if prop, ok := schema.Properties[field]; ok {
+			param.Schema = prop
+			delete(schema.Properties, field)
+		}
+		schema.Required = removeString(schema.Required, field)
+		params = append(params, param)
+	}
+
+	if route.HasBody() {
+		return params
+	}
+	for _, name := range sortedProperties(schema) {
+		params = append(params, OpenAPIParameter{
+			Name:     name,
+			In:       "query",
+			Required: isRequired(schema, name),
+			Schema:   schema.Properties[name],
+		})
+	}
+	return params
+}
+
+// removeString returns list without s
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// faultDetailSchema returns the schema of the fault detail of op. Declared
+// wsdl:faults are added to the components and referenced, as oneOf when there
+// are several.
+func faultDetailSchema(def *models.Definitions, spec *OpenAPISpec, op models.Operation) *OpenAPISchema {
+	var refs []*OpenAPISchema
+	for _, fault := range op.Faults {
+		msg := def.Message(fault.Message)
+		if msg == nil {
+			continue
+		}
+		spec.Components.Schemas[fault.Name] = faultMessageSchema(def, msg)
+		refs = append(refs, &OpenAPISchema{Ref: "#/components/schemas/" + fault.Name})
+	}
+
+	switch len(refs) {
+	case 0:
+		return &OpenAPISchema{Type: "object"}
+	case 1:
+		return refs[0]
+	default:
+		return &OpenAPISchema{OneOf: refs}
+	}
+}
+
+// faultMessageSchema converts a fault message to the schema of its detail,
+// keyed by the detail element name
+func faultMessageSchema(def *models.Definitions, msg *models.Message) *OpenAPISchema {
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*OpenAPISchema),
+	}
+
+	for _, part := range msg.Parts {
+		if part.Element == "" {
+			schema.Properties[part.Name] = xsdTypeToOpenAPISchema(def, part.Type)
+			continue
+		}
+
+		name := models.LocalName(part.Element)
+		schema.Properties[name] = complexTypeToOpenAPISchema(def, def.ElementType(part.Element))
+	}
+
+	return schema
+}
+
+// complexTypeToOpenAPISchema converts a named complex type to an object
+// schema. Simple and unknown types fall back to xsdTypeToOpenAPISchema.
+func complexTypeToOpenAPISchema(def *models.Definitions, typeName string) *OpenAPISchema {
+	return complexTypeSchema(def, typeName, make(map[string]bool))
+}
+
+// complexTypeSchema converts a complex type, inlining nested complex types.
+// Attributes are "@name" properties and simple or mixed content the "#text"
+// property, as the REST proxy reads and writes them. Types already being
+// converted are left as plain objects to stop recursion. Extensions of
+// another complex type are allOf the base type and their own elements and
+// attributes.
+func complexTypeSchema(def *models.Definitions, typeName string, visiting map[string]bool) *OpenAPISchema {
+	name := models.LocalName(typeName)
+	for _, t := range def.Types {
+		if t.Name != name {
+			continue
+		}
+		if visiting[name] {
+			return &OpenAPISchema{Type: "object"}
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+
+		elements, attributes, text := t.Elements, t.Attributes, t.SimpleContent != "" || t.Mixed
+		base := findType(def, t.Base)
+		extends := base != nil && t.Derivation == models.DerivationExtension &&
+			len(base.Elements) <= len(elements) && len(base.Attributes) <= len(attributes) &&
+			(len(base.Elements) > 0 || len(base.Attributes) > 0 || base.SimpleContent != "")
+		if extends {
+			elements, attributes = elements[len(base.Elements):], attributes[len(base.Attributes):]
+			text = text && base.SimpleContent == "" && !base.Mixed
+		}
+
+		schema := &OpenAPISchema{
+			Type:       "object",
+			Properties: make(map[string]*OpenAPISchema),
+		}
+		for _, elem := range elements {
+			schema.Properties[elem.Name] = elementSchema(def, elem, visiting)
+			switch {
+			case elem.Choice > 0 && elem.Choice <= len(t.Choices):
+				// Members of a choice are each optional, one of them set
+				if schema.choices == nil {
+					schema.choices = make([][]string, len(t.Choices))
+				}
+				schema.choices[elem.Choice-1] = append(schema.choices[elem.Choice-1], elem.Name)
+			case elem.MinOccurs != "0":
+				schema.Required = append(schema.Required, elem.Name)
+			}
+		}
+		for _, attr := range attributes {
+			schema.Properties["@"+attr.Name] = xsdTypeToOpenAPISchema(def, attr.Type)
+			if attr.Use == "required" {
+				schema.Required = append(schema.Required, "@"+attr.Name)
+			}
+		}
+		if text {
+			content := &OpenAPISchema{Type: "string"}
+			if t.SimpleContent != "" {
+				content = xsdTypeToOpenAPISchema(def, t.SimpleContent)
+			}
+			schema.Properties["#text"] = content
+		}
+
+		if extends {
+			return &OpenAPISchema{
+				Type:        "object",
+				Description: t.Documentation,
+				AllOf:       []*OpenAPISchema{complexTypeSchema(def, base.Name, visiting), schema},
 			}
+		}
+		schema.Description = t.Documentation
+		return schema
+	}
+	return xsdTypeToOpenAPISchema(def, typeName)
+}
 
-			spec.Paths[path] = OpenAPIPath{
-				Post: operation,
+// polymorphicSchema converts an element whose type other types derive from
+// to oneOf the concrete types, told apart by their xsi:type. Only derived
+// types carry xsi:type, so the base type is the alternative without it.
+func polymorphicSchema(def *models.Definitions, base models.Type, visiting map[string]bool) *OpenAPISchema {
+	schema := &OpenAPISchema{Discriminator: &OpenAPIDiscriminator{PropertyName: xsiTypeProperty}}
+	for _, t := range append([]models.Type{base}, derivedTypes(def, base.Name)...) {
+		if t.Abstract {
+			continue
+		}
+		alt := complexTypeSchema(def, t.Name, visiting)
+		discriminate(alt, xsiTypeProperty, t.Name, t.Name != base.Name)
+		schema.OneOf = append(schema.OneOf, alt)
+	}
+	return schema
+}
+
+// substitutionSchema converts an element referencing the head of a
+// substitution group to oneOf its members, told apart by their element
+// name. Members of simple types keep their value in #text.
+func substitutionSchema(def *models.Definitions, members []models.Element, visiting map[string]bool) *OpenAPISchema {
+	schema := &OpenAPISchema{Discriminator: &OpenAPIDiscriminator{PropertyName: elementProperty}}
+	for _, member := range members {
+		alt := complexTypeSchema(def, member.Type, visiting)
+		if alt.Type != "object" {
+			alt = &OpenAPISchema{
+				Type:       "object",
+				Properties: map[string]*OpenAPISchema{"#text": alt},
+				Required:   []string{"#text"},
 			}
 		}
+		discriminate(alt, elementProperty, member.Name, true)
+		schema.OneOf = append(schema.OneOf, alt)
 	}
+	return schema
+}
 
-	return spec, nil
+// discriminate adds the discriminator property of an alternative of a
+// oneOf, with its only value. Derived types get it with their own
+// properties.
+func discriminate(alt *OpenAPISchema, property, value string, required bool) {
+	if len(alt.AllOf) > 0 {
+		alt = alt.AllOf[len(alt.AllOf)-1]
+	}
+	if alt.Properties == nil {
+		alt.Properties = make(map[string]*OpenAPISchema)
+	}
+	alt.Properties[property] = &OpenAPISchema{Type: "string", Enum: []interface{}{value}}
+	if required {
+		alt.Required = append(alt.Required, property)
+	}
+}
+
+// substitutes returns the elements that may appear in place of an element
+// referencing the head of a substitution group, the head first unless it is
+// abstract. It returns nil for other elements.
+func substitutes(def *models.Definitions, elem models.Element) []models.Element {
+	for _, head := range def.Elements {
+		if head.Name != elem.Name || head.Namespace != elem.Namespace {
+			continue
+		}
+		group := []models.Element{head}
+		seen := map[string]bool{head.Name: true}
+		for i := 0; i < len(group); i++ {
+			for _, el := range def.Elements {
+				if el.SubstitutionGroup != "" && models.LocalName(el.SubstitutionGroup) == group[i].Name && !seen[el.Name] {
+					seen[el.Name] = true
+					group = append(group, el)
+				}
+			}
+		}
+		if len(group) == 1 {
+			return nil
+		}
+
+		var members []models.Element
+		for _, el := range group {
+			if !el.Abstract {
+				members = append(members, el)
+			}
+		}
+		return members
+	}
+	return nil
+}
+
+// derivedTypes returns the types derived from a complex type, directly or
+// not, in declaration order
+func derivedTypes(def *models.Definitions, name string) []models.Type {
+	var derived []models.Type
+	for _, t := range def.Types {
+		seen := map[string]bool{t.Name: true}
+		for base := findType(def, t.Base); base != nil && !seen[base.Name]; base = findType(def, base.Base) {
+			if base.Name == name {
+				derived = append(derived, t)
+				break
+			}
+			seen[base.Name] = true
+		}
+	}
+	return derived
+}
+
+// findType finds a complex type by name, or returns nil
+func findType(def *models.Definitions, name string) *models.Type {
+	if name == "" {
+		return nil
+	}
+	name = models.LocalName(name)
+	for i := range def.Types {
+		if def.Types[i].Name == name {
+			return &def.Types[i]
+		}
+	}
+	return nil
+}
+
+// elementSchema converts an element: repeated elements become arrays and
+// nillable ones are nullable
+func elementSchema(def *models.Definitions, elem models.Element, visiting map[string]bool) *OpenAPISchema {
+	var schema *OpenAPISchema
+	if members := substitutes(def, elem); len(members) > 0 {
+		schema = substitutionSchema(def, members, visiting)
+	} else if t := findType(def, elem.Type); t != nil && len(derivedTypes(def, t.Name)) > 0 {
+		schema = polymorphicSchema(def, *t, visiting)
+	} else {
+		schema = complexTypeSchema(def, elem.Type, visiting)
+	}
+	schema.Nullable = elem.Nillable
+
+	if elem.MaxOccurs == "unbounded" || (elem.MaxOccurs != "" && elem.MaxOccurs != "1") {
+		return &OpenAPISchema{Type: "array", Description: elem.Documentation, Items: schema}
+	}
+	if elem.Documentation != "" {
+		schema.Description = elem.Documentation
+	}
+	return schema
+}
+
+// elementDocumentation returns the documentation of a global element
+func elementDocumentation(def *models.Definitions, element string) string {
+	if elem := def.Element(element); elem != nil {
+		return elem.Documentation
+	}
+	return ""
+}
+
+// faultResponse builds a fault response with the REST proxy's error body
+func faultResponse(description string, detail *OpenAPISchema) OpenAPIResponse {
+	return OpenAPIResponse{
+		Description: description,
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {
+				Schema: &OpenAPISchema{
+					Type: "object",
+					Properties: map[string]*OpenAPISchema{
+						"code":    {Type: "string"},
+						"message": {Type: "string"},
+						"detail":  detail,
+					},
+				},
+			},
+		},
+	}
 }
 
-// convertMessageToSchema converts a WSDL message to OpenAPI schema
-func convertMessageToSchema(msg *models.Message) *OpenAPISchema {
+// convertMessageToSchema converts a WSDL message to OpenAPI schema. A
+// message with a single element part is the element itself, as the REST
+// proxy sends and returns the element's children.
+func convertMessageToSchema(def *models.Definitions, msg *models.Message) *OpenAPISchema {
 	if len(msg.Parts) == 0 {
 		return &OpenAPISchema{Type: "object"}
 	}
 
+	if len(msg.Parts) == 1 && msg.Parts[0].Element != "" {
+		if schema := complexTypeToOpenAPISchema(def, def.ElementType(msg.Parts[0].Element)); schema.Type == "object" {
+			if doc := elementDocumentation(def, msg.Parts[0].Element); doc != "" {
+				schema.Description = doc
+			}
+			return schema
+		}
+	}
+
 	schema := &OpenAPISchema{
 		Type:       "object",
 		Properties: make(map[string]*OpenAPISchema),
 	}
 
 	for _, part := range msg.Parts {
-		schema.Properties[part.Name] = xsdTypeToOpenAPISchema(part.Type)
+		if part.Element != "" {
+			schema.Properties[part.Name] = complexTypeToOpenAPISchema(def, def.ElementType(part.Element))
+		} else {
+			schema.Properties[part.Name] = complexTypeToOpenAPISchema(def, part.Type)
+		}
+		schema.Required = append(schema.Required, part.Name)
 	}
 
 	return schema
 }
 
 // xsdTypeToOpenAPISchema converts XSD type to OpenAPI schema
-func xsdTypeToOpenAPISchema(xsdType string) *OpenAPISchema {
+func xsdTypeToOpenAPISchema(def *models.Definitions, xsdType string) *OpenAPISchema {
 	// Remove namespace prefix
 	if idx := strings.LastIndex(xsdType, ":"); idx != -1 {
 		xsdType = xsdType[idx+1:]
 	}
 
+	// Simple types carry their facets on top of the base type
+	for _, st := range def.SimpleTypes {
+		if st.Name == xsdType {
+			return simpleTypeToOpenAPISchema(def, st)
+		}
+	}
+
 	typeMap := map[string]OpenAPISchema{
 		"string":   {Type: "string"},
 		"int":      {Type: "integer", Format: "int32"},
@@ -206,7 +708,7 @@ func xsdTypeToOpenAPISchema(xsdType string) *OpenAPISchema {
 		"boolean":  {Type: "boolean"},
 		"float":    {Type: "number", Format: "float"},
 		"double":   {Type: "number", Format: "double"},
-		"decimal":  {Type: "number"},
+		"decimal":  {Type: "number", Format: "decimal"},
 		"dateTime": {Type: "string", Format: "date-time"},
 		"date":     {Type: "string", Format: "date"},
 		"time":     {Type: "string", Format: "time"},
@@ -220,23 +722,127 @@ func xsdTypeToOpenAPISchema(xsdType string) *OpenAPISchema {
 	return &OpenAPISchema{Type: "string"}
 }
 
-// findMessage finds a message by name
-func findMessage(def *models.Definitions, name string) *models.Message {
-	// Remove namespace prefix
-	if idx := strings.LastIndex(name, ":"); idx != -1 {
-		name = name[idx+1:]
+// simpleTypeToOpenAPISchema converts an XSD simple type restriction
+func simpleTypeToOpenAPISchema(def *models.Definitions, st models.SimpleType) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "string"}
+	if models.LocalName(st.Base) != st.Name {
+		schema = xsdTypeToOpenAPISchema(def, st.Base)
+	}
+
+	schema.Pattern = st.Pattern
+	if st.Documentation != "" {
+		schema.Description = st.Documentation
 	}
+	for _, value := range st.Enumeration {
+		schema.Enum = append(schema.Enum, enumValue(schema.Type, value))
+	}
+
+	return schema
+}
 
-	for _, msg := range def.Messages {
-		msgName := msg.Name
-		if idx := strings.LastIndex(msgName, ":"); idx != -1 {
-			msgName = msgName[idx+1:]
+// enumValue converts an enumeration facet to a JSON value of the schema type
+func enumValue(schemaType, value string) interface{} {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
 		}
-		if msgName == name {
-			return &msg
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
 		}
 	}
-	return nil
+	return value
+}
+
+// DecimalsAsStrings changes xs:decimal values (format decimal) from numbers
+// to strings, for clients that must not lose precision, examples included
+func (spec *OpenAPISpec) DecimalsAsStrings() {
+	spec.eachSchema(decimalsAsStrings)
+	spec.addExamples()
+}
+
+// PrefixSchemas renames the component schemas to prefix_name and points
+// their $refs at the new names, for specs combined with others
+func (spec *OpenAPISpec) PrefixSchemas(prefix string) {
+	if spec.Components == nil || len(spec.Components.Schemas) == 0 {
+		return
+	}
+	const components = "#/components/schemas/"
+	// Responses share schemas, which must be renamed once
+	renamed := make(map[*OpenAPISchema]bool)
+	spec.eachSchema(func(schema *OpenAPISchema) {
+		walkSchema(schema, func(s *OpenAPISchema) {
+			if !renamed[s] && strings.HasPrefix(s.Ref, components) {
+				renamed[s] = true
+				s.Ref = components + prefix + "_" + strings.TrimPrefix(s.Ref, components)
+			}
+		})
+	})
+	schemas := make(map[string]*OpenAPISchema, len(spec.Components.Schemas))
+	for name, schema := range spec.Components.Schemas {
+		schemas[prefix+"_"+name] = schema
+	}
+	spec.Components.Schemas = schemas
+}
+
+// eachSchema calls f with the schemas of the parameters, bodies and
+// responses of the operations and the component schemas
+func (spec *OpenAPISpec) eachSchema(f func(*OpenAPISchema)) {
+	for _, item := range spec.Paths {
+		for _, m := range item.Operations() {
+			op := m.Operation
+			for _, param := range op.Parameters {
+				f(param.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					f(media.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, media := range resp.Content {
+					f(media.Schema)
+				}
+			}
+		}
+	}
+	if spec.Components != nil {
+		for _, schema := range spec.Components.Schemas {
+			f(schema)
+		}
+	}
+}
+
+// walkSchema calls f with schema and the schemas nested in it
+func walkSchema(schema *OpenAPISchema, f func(*OpenAPISchema)) {
+	if schema == nil {
+		return
+	}
+	f(schema)
+	walkSchema(schema.Items, f)
+	for _, prop := range schema.Properties {
+		walkSchema(prop, f)
+	}
+	for _, alt := range schema.OneOf {
+		walkSchema(alt, f)
+	}
+	for _, part := range schema.AllOf {
+		walkSchema(part, f)
+	}
+}
+
+// decimalsAsStrings rewrites decimal schemas nested in schema
+func decimalsAsStrings(schema *OpenAPISchema) {
+	walkSchema(schema, func(s *OpenAPISchema) {
+		if s.Format == "decimal" {
+			s.Type = "string"
+		}
+	})
 }
 
 // ExportToJSON exports OpenAPI spec as JSON
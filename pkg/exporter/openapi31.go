@@ -0,0 +1,274 @@
+package exporter
+
+import (
+	"encoding/json"
+)
+
+// JSONSchemaDialect is the JSON Schema dialect used by OpenAPI 3.1 documents
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// OpenAPI31Spec represents an OpenAPI 3.1 specification
+type OpenAPI31Spec struct {
+	OpenAPI           string                       `json:"openapi"`
+	JSONSchemaDialect string                       `json:"jsonSchemaDialect,omitempty"`
+	Info              OpenAPIInfo                  `json:"info"`
+	Servers           []OpenAPIServer              `json:"servers,omitempty"`
+	Tags              []OpenAPITag                 `json:"tags,omitempty"`
+	Paths             map[string]OpenAPI31Path     `json:"paths"`
+	Components        *OpenAPI31Components         `json:"components,omitempty"`
+	Security          []OpenAPISecurityRequirement `json:"security,omitempty"`
+}
+
+// OpenAPI31Path describes operations on a path
+type OpenAPI31Path struct {
+	Post   *OpenAPI31Operation `json:"post,omitempty"`
+	Get    *OpenAPI31Operation `json:"get,omitempty"`
+	Put    *OpenAPI31Operation `json:"put,omitempty"`
+	Patch  *OpenAPI31Operation `json:"patch,omitempty"`
+	Delete *OpenAPI31Operation `json:"delete,omitempty"`
+}
+
+// OpenAPI31Operation describes a single operation
+type OpenAPI31Operation struct {
+	Summary     string                       `json:"summary,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	OperationID string                       `json:"operationId,omitempty"`
+	Parameters  []OpenAPI31Parameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPI31RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPI31Response `json:"responses"`
+	Tags        []string                     `json:"tags,omitempty"`
+	Security    []OpenAPISecurityRequirement `json:"security,omitempty"`
+}
+
+// OpenAPI31Parameter describes a path or query parameter
+type OpenAPI31Parameter struct {
+	Name     string                      `json:"name"`
+	In       string                      `json:"in"`
+	Required bool                        `json:"required,omitempty"`
+	Schema   *JSONSchema                 `json:"schema,omitempty"`
+	Examples map[string]OpenAPI31Example `json:"examples,omitempty"`
+}
+
+// OpenAPI31RequestBody describes a request body
+type OpenAPI31RequestBody struct {
+	Description string                        `json:"description,omitempty"`
+	Required    bool                          `json:"required,omitempty"`
+	Content     map[string]OpenAPI31MediaType `json:"content"`
+}
+
+// OpenAPI31Response describes a response
+type OpenAPI31Response struct {
+	Description string                        `json:"description"`
+	Content     map[string]OpenAPI31MediaType `json:"content,omitempty"`
+}
+
+// OpenAPI31MediaType describes a media type
+type OpenAPI31MediaType struct {
+	Schema   *JSONSchema                 `json:"schema,omitempty"`
+	Examples map[string]OpenAPI31Example `json:"examples,omitempty"`
+}
+
+// OpenAPI31Example is a named example of a media type or parameter
+type OpenAPI31Example struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// OpenAPI31Components contains reusable components
+type OpenAPI31Components struct {
+	Schemas         map[string]*JSONSchema            `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// JSONSchema describes a JSON Schema 2020-12 schema
+type JSONSchema struct {
+	Type        interface{}            `json:"type,omitempty"` // string or []string
+	Description string                 `json:"description,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Ref         string                 `json:"$ref,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Enum        []interface{}          `json:"enum,omitempty"`
+	Const       interface{}            `json:"const,omitempty"`
+	Pattern     string                 `json:"pattern,omitempty"`
+	Examples    []interface{}          `json:"examples,omitempty"`
+	OneOf       []*JSONSchema          `json:"oneOf,omitempty"`
+	AllOf       []*JSONSchema          `json:"allOf,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+
+	Discriminator *OpenAPIDiscriminator `json:"discriminator,omitempty"`
+}
+
+// ConvertOpenAPIToV31 converts an OpenAPI 3.0 spec to OpenAPI 3.1
+func ConvertOpenAPIToV31(spec *OpenAPISpec) *OpenAPI31Spec {
+	out := &OpenAPI31Spec{
+		OpenAPI:           "3.1.0",
+		JSONSchemaDialect: JSONSchemaDialect,
+		Info:              spec.Info,
+		Servers:           spec.Servers,
+		Tags:              spec.Tags,
+		Paths:             make(map[string]OpenAPI31Path),
+		Security:          spec.Security,
+	}
+
+	if spec.Components != nil {
+		out.Components = &OpenAPI31Components{
+			Schemas:         make(map[string]*JSONSchema),
+			SecuritySchemes: spec.Components.SecuritySchemes,
+		}
+		for name, schema := range spec.Components.Schemas {
+			out.Components.Schemas[name] = toJSONSchema(schema)
+		}
+	}
+
+	for path, item := range spec.Paths {
+		out.Paths[path] = OpenAPI31Path{
+			Post:   toOpenAPI31Operation(item.Post),
+			Get:    toOpenAPI31Operation(item.Get),
+			Put:    toOpenAPI31Operation(item.Put),
+			Patch:  toOpenAPI31Operation(item.Patch),
+			Delete: toOpenAPI31Operation(item.Delete),
+		}
+	}
+
+	return out
+}
+
+// toOpenAPI31Operation converts an OpenAPI 3.0 operation
+func toOpenAPI31Operation(op *OpenAPIOperation) *OpenAPI31Operation {
+	if op == nil {
+		return nil
+	}
+
+	operation := &OpenAPI31Operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.OperationID,
+		Responses:   make(map[string]OpenAPI31Response),
+		Tags:        op.Tags,
+		Security:    op.Security,
+	}
+
+	for _, param := range op.Parameters {
+		operation.Parameters = append(operation.Parameters, OpenAPI31Parameter{
+			Name:     param.Name,
+			In:       param.In,
+			Required: param.Required,
+			Schema:   toJSONSchema(param.Schema),
+			Examples: toOpenAPI31Examples(param.Example),
+		})
+	}
+
+	if op.RequestBody != nil {
+		operation.RequestBody = &OpenAPI31RequestBody{
+			Description: op.RequestBody.Description,
+			Required:    op.RequestBody.Required,
+			Content:     toOpenAPI31Content(op.RequestBody.Content),
+		}
+	}
+
+	for code, resp := range op.Responses {
+		operation.Responses[code] = OpenAPI31Response{
+			Description: resp.Description,
+			Content:     toOpenAPI31Content(resp.Content),
+		}
+	}
+
+	return operation
+}
+
+// toOpenAPI31Content converts media type content
+func toOpenAPI31Content(content map[string]OpenAPIMediaType) map[string]OpenAPI31MediaType {
+	if content == nil {
+		return nil
+	}
+
+	out := make(map[string]OpenAPI31MediaType, len(content))
+	for mediaType, media := range content {
+		out[mediaType] = OpenAPI31MediaType{
+			Schema:   toJSONSchema(media.Schema),
+			Examples: toOpenAPI31Examples(media.Example),
+		}
+	}
+	return out
+}
+
+// toOpenAPI31Examples converts an example to the examples map OpenAPI 3.1
+// prefers, with the example as its default entry
+func toOpenAPI31Examples(example interface{}) map[string]OpenAPI31Example {
+	if example == nil {
+		return nil
+	}
+	return map[string]OpenAPI31Example{"default": {Summary: "Example", Value: example}}
+}
+
+// toJSONSchema converts an OpenAPI 3.0 schema to JSON Schema 2020-12:
+// nullable becomes a type array, example becomes examples and a single
+// allowed value becomes const
+func toJSONSchema(schema *OpenAPISchema) *JSONSchema {
+	if schema == nil {
+		return nil
+	}
+
+	out := &JSONSchema{
+		Description: schema.Description,
+		Ref:         schema.Ref,
+		Format:      schema.Format,
+		Pattern:     schema.Pattern,
+		Items:       toJSONSchema(schema.Items),
+		Required:    schema.Required,
+	}
+
+	if schema.Type != "" {
+		if schema.Nullable {
+			out.Type = []string{schema.Type, "null"}
+		} else {
+			out.Type = schema.Type
+		}
+	}
+
+	if len(schema.Enum) == 1 {
+		out.Const = schema.Enum[0]
+	} else {
+		out.Enum = schema.Enum
+	}
+
+	if schema.Example != nil {
+		out.Examples = []interface{}{schema.Example}
+	}
+
+	if schema.Properties != nil {
+		out.Properties = make(map[string]*JSONSchema, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			out.Properties[name] = toJSONSchema(prop)
+		}
+	}
+
+	for _, alt := range schema.OneOf {
+		out.OneOf = append(out.OneOf, toJSONSchema(alt))
+	}
+	for _, part := range schema.AllOf {
+		out.AllOf = append(out.AllOf, toJSONSchema(part))
+	}
+	out.Discriminator = schema.Discriminator
+
+	return out
+}
+
+// ExportToJSON exports OpenAPI 3.1 spec as JSON
+func (spec *OpenAPI31Spec) ExportToJSON() (string, error) {
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ExportToYAML exports OpenAPI 3.1 spec as YAML (simplified)
+func (spec *OpenAPI31Spec) ExportToYAML() (string, error) {
+	json, err := spec.ExportToJSON()
+	if err != nil {
+		return "", err
+	}
+	return "# OpenAPI YAML export (use a YAML converter for proper formatting)\n" + json, nil
+}
@@ -0,0 +1,486 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+)
+
+func TestSimpleTypeFacets(t *testing.T) {
+	def := &models.Definitions{
+		SimpleTypes: []models.SimpleType{
+			{Name: "Color", Base: "xs:string", Enumeration: []string{"red", "green"}},
+			{Name: "Level", Base: "xs:int", Enumeration: []string{"1", "2"}},
+			{Name: "Code", Base: "xs:string", Pattern: "[A-Z]{3}"},
+			{Name: "Fixed", Base: "xs:string", Enumeration: []string{"only"}},
+		},
+	}
+
+	color := xsdTypeToOpenAPISchema(def, "tns:Color")
+	if color.Type != "string" || !reflect.DeepEqual(color.Enum, []interface{}{"red", "green"}) {
+		t.Errorf("unexpected Color schema: %+v", color)
+	}
+
+	level := xsdTypeToOpenAPISchema(def, "tns:Level")
+	if level.Type != "integer" || !reflect.DeepEqual(level.Enum, []interface{}{int64(1), int64(2)}) {
+		t.Errorf("unexpected Level schema: %+v", level)
+	}
+
+	code := xsdTypeToOpenAPISchema(def, "tns:Code")
+	if code.Pattern != "[A-Z]{3}" {
+		t.Errorf("unexpected Code schema: %+v", code)
+	}
+
+	fixed := toJSONSchema(xsdTypeToOpenAPISchema(def, "tns:Fixed"))
+	if fixed.Const != "only" || fixed.Enum != nil {
+		t.Errorf("expected const for single-value enum, got %+v", fixed)
+	}
+}
+
+func TestJSONSchemaNullable(t *testing.T) {
+	schema := toJSONSchema(&OpenAPISchema{Type: "string", Nullable: true, Example: "x"})
+
+	if !reflect.DeepEqual(schema.Type, []string{"string", "null"}) {
+		t.Errorf("expected type array, got %v", schema.Type)
+	}
+	if !reflect.DeepEqual(schema.Examples, []interface{}{"x"}) {
+		t.Errorf("expected examples, got %v", schema.Examples)
+	}
+}
+
+func TestDeclaredFaultResponses(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "CalcFault", Elements: []models.Element{{Name: "code", Type: "xs:int"}}},
+		},
+		Elements: []models.Element{{Name: "CalcFault", Type: "tns:CalcFault"}},
+		Messages: []models.Message{
+			{Name: "AddIn"},
+			{Name: "AddOut"},
+			{Name: "CalcFaultMsg", Parts: []models.Part{{Name: "detail", Element: "tns:CalcFault"}}},
+			{Name: "AuthFaultMsg", Parts: []models.Part{{Name: "user", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{{
+			Name:   "Add",
+			Input:  models.Message{Name: "tns:AddIn"},
+			Output: models.Message{Name: "tns:AddOut"},
+			Faults: []models.Fault{
+				{Name: "CalcFault", Message: "tns:CalcFaultMsg"},
+				{Name: "AuthFault", Message: "tns:AuthFaultMsg"},
+			},
+		}}}},
+	}
+
+	spec, err := ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatalf("ConvertWSDLToOpenAPI() error = %v", err)
+	}
+
+	op := spec.Paths["/api/Add"].Post
+	for _, status := range []string{"400", "502"} {
+		detail := op.Responses[status].Content["application/json"].Schema.Properties["detail"]
+		if len(detail.OneOf) != 2 || detail.OneOf[0].Ref != "#/components/schemas/CalcFault" {
+			t.Errorf("unexpected %s detail schema: %+v", status, detail)
+		}
+	}
+	if _, ok := op.Responses["500"]; ok {
+		t.Error("unexpected generic 500 response")
+	}
+
+	calc := spec.Components.Schemas["CalcFault"].Properties["CalcFault"]
+	if calc == nil || calc.Properties["code"].Type != "integer" {
+		t.Errorf("unexpected CalcFault schema: %+v", spec.Components.Schemas["CalcFault"])
+	}
+}
+
+func TestMessageSchemaOptionality(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "Lookup", Elements: []models.Element{
+				{Name: "id", Type: "xs:int"},
+				{Name: "filter", Type: "tns:Filter", MinOccurs: "0"},
+				{Name: "note", Type: "xs:string", Nillable: true},
+			}},
+			{Name: "Filter", Elements: []models.Element{
+				{Name: "tags", Type: "xs:string", MinOccurs: "0", MaxOccurs: "unbounded"},
+				{Name: "parent", Type: "tns:Filter", MinOccurs: "0"},
+			}},
+		},
+		Elements: []models.Element{{Name: "Lookup", Type: "Lookup"}},
+	}
+	msg := &models.Message{Name: "LookupIn", Parts: []models.Part{{Name: "parameters", Element: "tns:Lookup"}}}
+
+	schema := convertMessageToSchema(def, msg)
+	if !reflect.DeepEqual(schema.Required, []string{"id", "note"}) {
+		t.Errorf("unexpected required properties: %v", schema.Required)
+	}
+	if !schema.Properties["note"].Nullable {
+		t.Error("expected nillable element to be nullable")
+	}
+
+	filter := schema.Properties["filter"]
+	if filter.Type != "object" || filter.Required != nil {
+		t.Errorf("unexpected filter schema: %+v", filter)
+	}
+	if tags := filter.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("unexpected tags schema: %+v", tags)
+	}
+	if parent := filter.Properties["parent"]; parent.Type != "object" || parent.Properties != nil {
+		t.Errorf("expected recursive type to stop at a plain object, got %+v", parent)
+	}
+
+	rpc := convertMessageToSchema(def, &models.Message{Parts: []models.Part{{Name: "a", Type: "xs:int"}}})
+	if !reflect.DeepEqual(rpc.Required, []string{"a"}) {
+		t.Errorf("expected rpc parts to be required, got %v", rpc.Required)
+	}
+}
+
+func TestDerivedTypeSchemas(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "Vehicle", Abstract: true, Elements: []models.Element{{Name: "plate", Type: "xs:string"}}},
+			{Name: "Car", Base: "tns:Vehicle", Derivation: models.DerivationExtension, Elements: []models.Element{
+				{Name: "plate", Type: "xs:string"},
+				{Name: "doors", Type: "xs:int"},
+			}},
+			{Name: "Fleet", Elements: []models.Element{{Name: "vehicle", Type: "tns:Vehicle"}}},
+		},
+	}
+
+	car := complexTypeToOpenAPISchema(def, "tns:Car")
+	if len(car.AllOf) != 2 || car.AllOf[0].Properties["plate"] == nil || car.AllOf[1].Properties["plate"] != nil ||
+		car.AllOf[1].Properties["doors"] == nil {
+		t.Errorf("expected Car to be allOf Vehicle and its own elements, got %+v", car)
+	}
+
+	vehicle := complexTypeToOpenAPISchema(def, "tns:Fleet").Properties["vehicle"]
+	if vehicle.Discriminator == nil || vehicle.Discriminator.PropertyName != "@xsi:type" || len(vehicle.OneOf) != 1 {
+		t.Fatalf("expected a discriminated oneOf of the concrete types, got %+v", vehicle)
+	}
+	own := vehicle.OneOf[0].AllOf[1]
+	if xsiType := own.Properties["@xsi:type"]; xsiType == nil || !reflect.DeepEqual(xsiType.Enum, []interface{}{"Car"}) ||
+		!reflect.DeepEqual(own.Required, []string{"doors", "@xsi:type"}) {
+		t.Errorf("unexpected Car alternative: %+v", own)
+	}
+}
+
+func TestSubstitutionGroupSchemas(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "Circle", Elements: []models.Element{{Name: "radius", Type: "xs:double"}}},
+			{Name: "Drawing", Elements: []models.Element{{Name: "Shape", Namespace: "urn:shapes", Type: "tns:Circle"}}},
+		},
+		Elements: []models.Element{
+			{Name: "Shape", Namespace: "urn:shapes", Type: "tns:Circle", Abstract: true},
+			{Name: "Circle", Namespace: "urn:shapes", Type: "tns:Circle", SubstitutionGroup: "tns:Shape"},
+			{Name: "Note", Namespace: "urn:shapes", Type: "xs:string", SubstitutionGroup: "tns:Shape"},
+		},
+	}
+
+	shape := complexTypeToOpenAPISchema(def, "tns:Drawing").Properties["Shape"]
+	if shape.Discriminator == nil || shape.Discriminator.PropertyName != "#element" || len(shape.OneOf) != 2 {
+		t.Fatalf("expected a discriminated oneOf of the members, got %+v", shape)
+	}
+	circle, note := shape.OneOf[0], shape.OneOf[1]
+	if circle.Properties["radius"] == nil || !reflect.DeepEqual(circle.Properties["#element"].Enum, []interface{}{"Circle"}) {
+		t.Errorf("unexpected Circle alternative: %+v", circle)
+	}
+	if note.Properties["#text"].Type != "string" || !reflect.DeepEqual(note.Required, []string{"#text", "#element"}) {
+		t.Errorf("unexpected Note alternative: %+v", note)
+	}
+}
+
+func TestAttributeSchemas(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "Money", SimpleContent: "xs:decimal", Attributes: []models.Attribute{{Name: "currency", Type: "xs:string", Use: "required"}}},
+			{Name: "Price", Base: "tns:Money", Derivation: models.DerivationExtension, SimpleContent: "xs:decimal", Attributes: []models.Attribute{
+				{Name: "currency", Type: "xs:string", Use: "required"},
+				{Name: "net", Type: "xs:boolean"},
+			}},
+			{Name: "Note", Mixed: true, Elements: []models.Element{{Name: "b", Type: "xs:string"}}},
+			{Name: "Quote", Elements: []models.Element{{Name: "total", Type: "tns:Money"}}, Attributes: []models.Attribute{{Name: "version", Type: "xs:int"}}},
+		},
+		Elements: []models.Element{{Name: "Quote", Type: "tns:Quote"}},
+		Messages: []models.Message{{Name: "QuoteIn", Parts: []models.Part{{Name: "parameters", Element: "tns:Quote"}}}},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetQuote", Input: models.Message{Name: "tns:QuoteIn"}, Output: models.Message{Name: "tns:QuoteIn"}},
+		}}},
+	}
+
+	tests := []struct {
+		typeName string
+		want     *OpenAPISchema
+	}{
+		{"tns:Money", &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{
+			"@currency": {Type: "string"},
+			"#text":     {Type: "number", Format: "decimal"},
+		}, Required: []string{"@currency"}}},
+		// Extensions inherit the attributes and content of their base
+		{"tns:Price", &OpenAPISchema{Type: "object", AllOf: []*OpenAPISchema{
+			complexTypeToOpenAPISchema(def, "tns:Money"),
+			{Type: "object", Properties: map[string]*OpenAPISchema{"@net": {Type: "boolean"}}},
+		}}},
+		{"tns:Note", &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{
+			"b":     {Type: "string"},
+			"#text": {Type: "string"},
+		}, Required: []string{"b"}}},
+	}
+	for _, tt := range tests {
+		if got := complexTypeToOpenAPISchema(def, tt.typeName); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("complexTypeToOpenAPISchema(%s) = %+v, want %+v", tt.typeName, got, tt.want)
+		}
+	}
+
+	// Path parameters name attributes without their @
+	spec, err := ConvertWSDLToOpenAPIWithRoutes(def, &routes.Config{Operations: map[string]routes.Route{"GetQuote": {Method: "GET", Path: "/quotes/{version}"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := spec.Paths["/api/quotes/{version}"].Get.Parameters
+	if len(params) != 2 || params[0].Name != "version" || params[0].Schema.Type != "integer" || params[1].Name != "total" {
+		t.Errorf("parameters = %+v", params)
+	}
+	response := spec.Paths["/api/quotes/{version}"].Get.Responses["200"].Content["application/json"].Example
+	want := map[string]interface{}{"@version": 42, "total": map[string]interface{}{
+		"@xsi:type": "Price",
+		"@currency": "currency",
+		"@net":      true,
+		"#text":     19.99,
+	}}
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("response example = %#v, want %#v", response, want)
+	}
+}
+
+func TestDecimalsAsStrings(t *testing.T) {
+	def := &models.Definitions{
+		Messages: []models.Message{
+			{Name: "PayIn", Parts: []models.Part{{Name: "amount", Type: "xs:decimal"}, {Name: "rate", Type: "xs:double"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{{
+			Name:  "Pay",
+			Input: models.Message{Name: "tns:PayIn"},
+		}}}},
+	}
+
+	spec, err := ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatalf("ConvertWSDLToOpenAPI() error = %v", err)
+	}
+
+	props := spec.Paths["/api/Pay"].Post.RequestBody.Content["application/json"].Schema.Properties
+	if amount := props["amount"]; amount.Type != "number" || amount.Format != "decimal" {
+		t.Errorf("unexpected amount schema: %+v", amount)
+	}
+
+	spec.DecimalsAsStrings()
+	if amount := props["amount"]; amount.Type != "string" || amount.Format != "decimal" {
+		t.Errorf("unexpected amount schema after DecimalsAsStrings: %+v", amount)
+	}
+	if rate := props["rate"]; rate.Type != "number" {
+		t.Errorf("expected double to stay a number, got %+v", rate)
+	}
+}
+
+func TestConvertWithRoutes(t *testing.T) {
+	def := &models.Definitions{
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}, {Name: "fields", Type: "xs:string"}}},
+			{Name: "UserOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+			{Name: "UpdateUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+			{Name: "DeleteUser", Input: models.Message{Name: "tns:UserIn"}},
+		}}},
+	}
+	cfg := &routes.Config{Heuristics: true, Operations: map[string]routes.Route{
+		"GetUser":    {Path: "/users/{id}"},
+		"UpdateUser": {Method: "PUT", Path: "/users/{id}"},
+	}}
+
+	spec, err := ConvertWSDLToOpenAPIWithRoutes(def, cfg)
+	if err != nil {
+		t.Fatalf("ConvertWSDLToOpenAPIWithRoutes() error = %v", err)
+	}
+
+	users := spec.Paths["/api/users/{id}"]
+	if users.Get == nil || users.Put == nil || users.Post != nil {
+		t.Fatalf("unexpected /api/users/{id} operations: %+v", users)
+	}
+	want := []OpenAPIParameter{
+		{Name: "id", In: "path", Required: true, Schema: &OpenAPISchema{Type: "integer", Format: "int32"}, Example: 42},
+		{Name: "fields", In: "query", Required: true, Schema: &OpenAPISchema{Type: "string"}, Example: "fields"},
+	}
+	if !reflect.DeepEqual(users.Get.Parameters, want) || users.Get.RequestBody != nil {
+		t.Errorf("unexpected GET parameters: %+v", users.Get.Parameters)
+	}
+
+	// The path parameter leaves the PUT body
+	body := users.Put.RequestBody.Content["application/json"].Schema
+	if len(users.Put.Parameters) != 1 || body.Properties["id"] != nil || !reflect.DeepEqual(body.Required, []string{"fields"}) {
+		t.Errorf("unexpected PUT request: %+v, body %+v", users.Put.Parameters, body)
+	}
+	if request := users.Put.RequestSchema(); len(request.Properties) != 2 || len(request.Required) != 2 {
+		t.Errorf("unexpected PUT request schema: %+v", request)
+	}
+
+	if del := spec.Paths["/api/DeleteUser"].Delete; del == nil || len(del.Parameters) != 2 {
+		t.Errorf("expected DeleteUser at DELETE /api/DeleteUser with query parameters, got %+v", spec.Paths["/api/DeleteUser"])
+	}
+
+	swagger := ConvertOpenAPIToSwagger(spec)
+	if param := swagger.Paths["/api/users/{id}"].Get.Parameters[0]; param.Type != "integer" || param.Schema != nil {
+		t.Errorf("unexpected Swagger path parameter: %+v", param)
+	}
+}
+
+func TestDocumentation(t *testing.T) {
+	def := &models.Definitions{
+		Name:     "Orders",
+		Services: []models.Service{{Name: "OrderService", Documentation: "Manages orders"}},
+		PortTypes: []models.PortType{{Name: "OrdersPort", Documentation: "Order operations", Operations: []models.Operation{
+			{Name: "GetOrder", Documentation: "Returns an order", Input: models.Message{Name: "tns:GetOrderIn"}, Output: models.Message{Name: "tns:GetOrderOut"}},
+		}}},
+		Messages: []models.Message{
+			{Name: "GetOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:GetOrder"}}},
+			{Name: "GetOrderOut", Parts: []models.Part{{Name: "parameters", Element: "tns:Order"}}},
+		},
+		Types: []models.Type{
+			{Name: "GetOrder", Elements: []models.Element{{Name: "id", Type: "xs:int", Documentation: "Order number"}}},
+			{Name: "Order", Documentation: "An order", Elements: []models.Element{
+				{Name: "status", Type: "tns:Status"},
+				{Name: "line", Type: "xs:string", MaxOccurs: "unbounded", Documentation: "Order lines"},
+			}},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Documentation: "Fulfilment status"}},
+		Elements: []models.Element{
+			{Name: "GetOrder", Type: "tns:GetOrder", Documentation: "Order lookup"},
+			{Name: "Order", Type: "tns:Order"},
+		},
+	}
+
+	spec, err := ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Info.Description != "Manages orders" {
+		t.Errorf("info description = %q", spec.Info.Description)
+	}
+	if !reflect.DeepEqual(spec.Tags, []OpenAPITag{{Name: "OrdersPort", Description: "Order operations"}}) {
+		t.Errorf("tags = %+v", spec.Tags)
+	}
+
+	op := spec.Paths["/api/GetOrder"].Post
+	if op.Description != "Returns an order" || !reflect.DeepEqual(op.Tags, []string{"OrdersPort"}) {
+		t.Errorf("operation description = %q, tags %v", op.Description, op.Tags)
+	}
+	request := op.RequestBody.Content["application/json"].Schema
+	if request.Description != "Order lookup" || request.Properties["id"].Description != "Order number" {
+		t.Errorf("request schema = %+v", request)
+	}
+	response := op.Responses["200"].Content["application/json"].Schema
+	if response.Description != "An order" {
+		t.Errorf("response description = %q", response.Description)
+	}
+	if got := response.Properties["status"].Description; got != "Fulfilment status" {
+		t.Errorf("status description = %q", got)
+	}
+	if lines := response.Properties["line"]; lines.Type != "array" || lines.Description != "Order lines" {
+		t.Errorf("line schema = %+v", lines)
+	}
+
+	v31 := ConvertOpenAPIToV31(spec)
+	if len(v31.Tags) != 1 || v31.Paths["/api/GetOrder"].Post.RequestBody.Content["application/json"].Schema.Properties["id"].Description != "Order number" {
+		t.Errorf("OpenAPI 3.1 dropped the documentation")
+	}
+}
+
+func TestExamples(t *testing.T) {
+	def := &models.Definitions{
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "PlaceOrder", Input: models.Message{Name: "tns:PlaceOrderIn"}, Output: models.Message{Name: "tns:PlaceOrderOut"}},
+			{Name: "GetOrder", Input: models.Message{Name: "tns:GetOrderIn"}, Output: models.Message{Name: "tns:PlaceOrderOut"}},
+		}}},
+		Messages: []models.Message{
+			{Name: "PlaceOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrder"}}},
+			{Name: "PlaceOrderOut", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrderResponse"}}},
+			{Name: "GetOrderIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}, {Name: "since", Type: "xs:date"}}},
+		},
+		Types: []models.Type{
+			{Name: "PlaceOrder", Elements: []models.Element{
+				{Name: "customer", Type: "xs:string"},
+				{Name: "total", Type: "xs:decimal"},
+				{Name: "status", Type: "tns:Status"},
+				{Name: "item", Type: "tns:Item", MaxOccurs: "unbounded"},
+				{Name: "card", Type: "xs:string", Choice: 1},
+				{Name: "iban", Type: "xs:string", Choice: 1},
+			}, Choices: []models.Choice{{}}},
+			{Name: "Item", Elements: []models.Element{{Name: "sku", Type: "xs:string"}, {Name: "parent", Type: "tns:Item", MinOccurs: "0"}}},
+			{Name: "PlaceOrderResponse", Elements: []models.Element{{Name: "payment", Type: "tns:Payment"}}},
+			{Name: "Payment", Elements: []models.Element{{Name: "amount", Type: "xs:double"}}},
+			{Name: "CardPayment", Base: "tns:Payment", Derivation: models.DerivationExtension, Elements: []models.Element{
+				{Name: "amount", Type: "xs:double"}, {Name: "verified", Type: "xs:boolean"},
+			}},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"open", "closed"}}},
+		Elements: []models.Element{
+			{Name: "PlaceOrder", Type: "tns:PlaceOrder"},
+			{Name: "PlaceOrderResponse", Type: "tns:PlaceOrderResponse"},
+		},
+	}
+
+	spec, err := ConvertWSDLToOpenAPIWithRoutes(def, &routes.Config{Operations: map[string]routes.Route{"GetOrder": {Method: "GET", Path: "/orders/{id}"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := spec.Paths["/api/PlaceOrder"].Post.RequestBody.Content["application/json"].Example
+	want := map[string]interface{}{
+		"customer": "customer",
+		"total":    19.99,
+		"status":   "open",
+		// The recursive parent is left out
+		"item": []interface{}{map[string]interface{}{"sku": "sku"}},
+		// Only the first element of a choice is set
+		"card": "card",
+	}
+	if !reflect.DeepEqual(request, want) {
+		t.Errorf("request example = %#v, want %#v", request, want)
+	}
+	if required := spec.Paths["/api/PlaceOrder"].Post.RequestBody.Content["application/json"].Schema.Required; !reflect.DeepEqual(required, []string{"customer", "total", "status", "item"}) {
+		t.Errorf("request required = %v, want the elements outside the choice", required)
+	}
+
+	// The derived type is preferred so that xsi:type is shown
+	response := spec.Paths["/api/PlaceOrder"].Post.Responses["200"].Content["application/json"].Example
+	want = map[string]interface{}{"payment": map[string]interface{}{"@xsi:type": "CardPayment", "amount": 3.14, "verified": true}}
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("response example = %#v, want %#v", response, want)
+	}
+	if fault := spec.Paths["/api/PlaceOrder"].Post.Responses["502"].Content["application/json"].Example; fault != nil {
+		t.Errorf("fault example = %#v", fault)
+	}
+
+	params := spec.Paths["/api/orders/{id}"].Get.Parameters
+	if len(params) != 2 || params[0].Example != 42 || params[1].Example != "2024-01-15" {
+		t.Errorf("parameters = %+v", params)
+	}
+
+	spec.DecimalsAsStrings()
+	if total := spec.Paths["/api/PlaceOrder"].Post.RequestBody.Content["application/json"].Example.(map[string]interface{})["total"]; total != "19.99" {
+		t.Errorf("total example after DecimalsAsStrings = %#v", total)
+	}
+
+	v31 := ConvertOpenAPIToV31(spec).Paths["/api/PlaceOrder"].Post.Responses["200"].Content["application/json"]
+	if !reflect.DeepEqual(v31.Examples["default"].Value, response) {
+		t.Errorf("OpenAPI 3.1 examples = %+v", v31.Examples)
+	}
+	swagger := ConvertOpenAPIToSwagger(spec).Paths["/api/PlaceOrder"].Post
+	if swagger.Parameters[0].Schema.Example == nil || !reflect.DeepEqual(swagger.Responses["200"].Examples["application/json"], response) {
+		t.Errorf("Swagger examples = %+v, %+v", swagger.Parameters[0].Schema.Example, swagger.Responses["200"].Examples)
+	}
+}
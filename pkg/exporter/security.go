@@ -0,0 +1,133 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/filter"
+)
+
+// SecurityScheme is an authentication scheme declared in exported specs,
+// required by every operation or by those its filter matches
+type SecurityScheme struct {
+	Type         string         // apiKey, bearer or basic
+	In           string         // header, query or cookie, for apiKey
+	Param        string         // Name of the header, query parameter or cookie, for apiKey
+	BearerFormat string         // Format hint such as JWT, for bearer
+	Operations   *filter.Filter // Operations requiring the scheme, nil for all
+}
+
+// ParseSecurityScheme parses a scheme of the form type[:options][=operations]:
+// basic, bearer or bearer:JWT, and apikey:name or apikey:in:name, where in
+// is header (the default), query or cookie. Operations are comma-separated
+// globs or /regular expressions/ as those of filter.New.
+func ParseSecurityScheme(s string) (SecurityScheme, error) {
+	spec, ops, scoped := strings.Cut(s, "=")
+	fields := strings.Split(spec, ":")
+	var scheme SecurityScheme
+	switch strings.ToLower(fields[0]) {
+	case "basic":
+		if len(fields) > 1 {
+			return scheme, fmt.Errorf("invalid security scheme %s: basic takes no options", s)
+		}
+		scheme.Type = "basic"
+	case "bearer":
+		if len(fields) > 2 {
+			return scheme, fmt.Errorf("invalid security scheme %s: use bearer or bearer:format", s)
+		}
+		scheme.Type = "bearer"
+		if len(fields) == 2 {
+			scheme.BearerFormat = fields[1]
+		}
+	case "apikey":
+		scheme.Type = "apiKey"
+		switch len(fields) {
+		case 2:
+			scheme.In, scheme.Param = "header", fields[1]
+		case 3:
+			scheme.In, scheme.Param = strings.ToLower(fields[1]), fields[2]
+		default:
+			return scheme, fmt.Errorf("invalid security scheme %s: use apikey:name or apikey:in:name", s)
+		}
+		if scheme.In != "header" && scheme.In != "query" && scheme.In != "cookie" {
+			return scheme, fmt.Errorf("invalid security scheme %s: API keys go in a header, query or cookie", s)
+		}
+		if scheme.Param == "" {
+			return scheme, fmt.Errorf("invalid security scheme %s: missing API key name", s)
+		}
+	default:
+		return scheme, fmt.Errorf("invalid security scheme %s: type must be apikey, bearer or basic", s)
+	}
+
+	if scoped {
+		f, err := filter.New(strings.Split(ops, ","), nil)
+		if err != nil {
+			return scheme, fmt.Errorf("invalid security scheme %s: %w", s, err)
+		}
+		scheme.Operations = f
+	}
+	return scheme, nil
+}
+
+// OpenAPISecurityScheme describes an authentication scheme
+type OpenAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// OpenAPISecurityRequirement names the schemes a request must satisfy, with
+// their scopes
+type OpenAPISecurityRequirement map[string][]string
+
+// AddSecurity declares schemes in the components of spec, named after
+// their type (bearerAuth, basicAuth, apiKeyAuth, numbered when repeated).
+// Schemes without operations apply to the whole API. Operations matched by
+// schemes of their own require one of those instead.
+func (spec *OpenAPISpec) AddSecurity(schemes []SecurityScheme) {
+	if len(schemes) == 0 {
+		return
+	}
+	if spec.Components == nil {
+		spec.Components = &OpenAPIComponents{}
+	}
+	if spec.Components.SecuritySchemes == nil {
+		spec.Components.SecuritySchemes = make(map[string]*OpenAPISecurityScheme)
+	}
+
+	names := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		name := scheme.Type + "Auth"
+		for n := 2; spec.Components.SecuritySchemes[name] != nil; n++ {
+			name = fmt.Sprintf("%sAuth%d", scheme.Type, n)
+		}
+		names[i] = name
+		spec.Components.SecuritySchemes[name] = scheme.openAPI()
+		if scheme.Operations == nil {
+			spec.Security = append(spec.Security, OpenAPISecurityRequirement{name: {}})
+		}
+	}
+
+	// Operations are matched by their WSDL name, which is their summary
+	for _, item := range spec.Paths {
+		for _, m := range item.Operations() {
+			for i, scheme := range schemes {
+				if scheme.Operations != nil && scheme.Operations.Match(m.Operation.Summary) {
+					m.Operation.Security = append(m.Operation.Security, OpenAPISecurityRequirement{names[i]: {}})
+				}
+			}
+		}
+	}
+}
+
+// openAPI converts a scheme to its OpenAPI 3 form
+func (scheme SecurityScheme) openAPI() *OpenAPISecurityScheme {
+	switch scheme.Type {
+	case "apiKey":
+		return &OpenAPISecurityScheme{Type: "apiKey", In: scheme.In, Name: scheme.Param}
+	default:
+		return &OpenAPISecurityScheme{Type: "http", Scheme: scheme.Type, BearerFormat: scheme.BearerFormat}
+	}
+}
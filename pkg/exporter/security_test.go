@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestParseSecurityScheme(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SecurityScheme
+	}{
+		{"basic", SecurityScheme{Type: "basic"}},
+		{"bearer:JWT", SecurityScheme{Type: "bearer", BearerFormat: "JWT"}},
+		{"apikey:X-API-Key", SecurityScheme{Type: "apiKey", In: "header", Param: "X-API-Key"}},
+		{"APIKey:Query:key", SecurityScheme{Type: "apiKey", In: "query", Param: "key"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSecurityScheme(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSecurityScheme(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	got, err := ParseSecurityScheme("bearer=Delete*,/^Update/")
+	if err != nil || got.Operations == nil || !got.Operations.Match("DeleteUser") || !got.Operations.Match("UpdateUser") || got.Operations.Match("GetUser") {
+		t.Errorf("ParseSecurityScheme() of scoped scheme = %+v, %v", got, err)
+	}
+
+	for _, in := range []string{"oauth2", "basic:x", "apikey", "apikey:body:key", "apikey:header:", "bearer=[Get"} {
+		if _, err := ParseSecurityScheme(in); err == nil || !strings.Contains(err.Error(), in) {
+			t.Errorf("ParseSecurityScheme(%q) error = %v", in, err)
+		}
+	}
+}
+
+func TestAddSecurity(t *testing.T) {
+	def := &models.Definitions{
+		Messages: []models.Message{{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}}},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}},
+			{Name: "DeleteUser", Input: models.Message{Name: "tns:UserIn"}},
+		}}},
+	}
+	spec, err := ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schemes []SecurityScheme
+	for _, s := range []string{"apikey:X-API-Key", "apikey:cookie:session", "bearer:JWT=Delete*"} {
+		scheme, err := ParseSecurityScheme(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schemes = append(schemes, scheme)
+	}
+	spec.AddSecurity(schemes)
+
+	want := map[string]*OpenAPISecurityScheme{
+		"apiKeyAuth":  {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		"apiKeyAuth2": {Type: "apiKey", In: "cookie", Name: "session"},
+		"bearerAuth":  {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	if !reflect.DeepEqual(spec.Components.SecuritySchemes, want) {
+		t.Errorf("security schemes = %+v", spec.Components.SecuritySchemes)
+	}
+	if global := []OpenAPISecurityRequirement{{"apiKeyAuth": {}}, {"apiKeyAuth2": {}}}; !reflect.DeepEqual(spec.Security, global) {
+		t.Errorf("security = %v", spec.Security)
+	}
+	if get := spec.Paths["/api/GetUser"].Post; get.Security != nil {
+		t.Errorf("GetUser security = %v, want the global one", get.Security)
+	}
+	if del := spec.Paths["/api/DeleteUser"].Post; !reflect.DeepEqual(del.Security, []OpenAPISecurityRequirement{{"bearerAuth": {}}}) {
+		t.Errorf("DeleteUser security = %v", del.Security)
+	}
+
+	v31 := ConvertOpenAPIToV31(spec)
+	if len(v31.Components.SecuritySchemes) != 3 || len(v31.Security) != 2 || v31.Paths["/api/DeleteUser"].Post.Security == nil {
+		t.Errorf("OpenAPI 3.1 dropped the security schemes")
+	}
+
+	// Swagger 2.0 has neither bearer nor cookie schemes
+	swagger := ConvertOpenAPIToSwagger(spec)
+	if bearer := swagger.SecurityDefinitions["bearerAuth"]; bearer == nil || bearer.Type != "apiKey" || bearer.Name != "Authorization" {
+		t.Errorf("Swagger bearer scheme = %+v", bearer)
+	}
+	if _, ok := swagger.SecurityDefinitions["apiKeyAuth2"]; ok || !reflect.DeepEqual(swagger.Security, []OpenAPISecurityRequirement{{"apiKeyAuth": {}}}) {
+		t.Errorf("Swagger security = %v, definitions %v", swagger.Security, swagger.SecurityDefinitions)
+	}
+}
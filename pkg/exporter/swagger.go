@@ -0,0 +1,276 @@
+package exporter
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// SwaggerSpec represents a Swagger 2.0 specification
+type SwaggerSpec struct {
+	Swagger     string                    `json:"swagger"`
+	Info        OpenAPIInfo               `json:"info"`
+	Host        string                    `json:"host,omitempty"`
+	BasePath    string                    `json:"basePath,omitempty"`
+	Schemes     []string                  `json:"schemes,omitempty"`
+	Consumes    []string                  `json:"consumes,omitempty"`
+	Produces    []string                  `json:"produces,omitempty"`
+	Tags        []OpenAPITag              `json:"tags,omitempty"`
+	Paths       map[string]SwaggerPath    `json:"paths"`
+	Definitions map[string]*OpenAPISchema `json:"definitions,omitempty"`
+
+	SecurityDefinitions map[string]*SwaggerSecurityScheme `json:"securityDefinitions,omitempty"`
+	Security            []OpenAPISecurityRequirement      `json:"security,omitempty"`
+}
+
+// SwaggerSecurityScheme describes an authentication scheme
+type SwaggerSecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// SwaggerPath describes operations on a path
+type SwaggerPath struct {
+	Post   *SwaggerOperation `json:"post,omitempty"`
+	Get    *SwaggerOperation `json:"get,omitempty"`
+	Put    *SwaggerOperation `json:"put,omitempty"`
+	Patch  *SwaggerOperation `json:"patch,omitempty"`
+	Delete *SwaggerOperation `json:"delete,omitempty"`
+}
+
+// SwaggerOperation describes a single operation
+type SwaggerOperation struct {
+	Summary     string                       `json:"summary,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	OperationID string                       `json:"operationId,omitempty"`
+	Parameters  []SwaggerParameter           `json:"parameters,omitempty"`
+	Responses   map[string]SwaggerResponse   `json:"responses"`
+	Tags        []string                     `json:"tags,omitempty"`
+	Security    []OpenAPISecurityRequirement `json:"security,omitempty"`
+}
+
+// SwaggerParameter describes an operation parameter. Body parameters have
+// a schema, path and query parameters a type of their own.
+type SwaggerParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+	Type        string         `json:"type,omitempty"`
+	Format      string         `json:"format,omitempty"`
+	Items       *OpenAPISchema `json:"items,omitempty"`
+	Enum        []interface{}  `json:"enum,omitempty"`
+	XExample    interface{}    `json:"x-example,omitempty"`
+}
+
+// SwaggerResponse describes a response
+type SwaggerResponse struct {
+	Description string                 `json:"description"`
+	Schema      *OpenAPISchema         `json:"schema,omitempty"`
+	Examples    map[string]interface{} `json:"examples,omitempty"`
+}
+
+// ConvertOpenAPIToSwagger converts an OpenAPI 3.0 spec to Swagger 2.0
+func ConvertOpenAPIToSwagger(spec *OpenAPISpec) *SwaggerSpec {
+	swagger := &SwaggerSpec{
+		Swagger:  "2.0",
+		Info:     spec.Info,
+		Consumes: []string{"application/json"},
+		Produces: []string{"application/json"},
+		Tags:     spec.Tags,
+		Paths:    make(map[string]SwaggerPath),
+	}
+
+	// Swagger 2.0 has a single host instead of a server list
+	if len(spec.Servers) > 0 {
+		if u, err := url.Parse(spec.Servers[0].URL); err == nil {
+			swagger.Host = u.Host
+			swagger.BasePath = u.Path
+			if u.Scheme != "" {
+				swagger.Schemes = []string{u.Scheme}
+			}
+		}
+	}
+
+	// Convert component schemas to definitions
+	if spec.Components != nil && len(spec.Components.Schemas) > 0 {
+		swagger.Definitions = make(map[string]*OpenAPISchema)
+		for name, schema := range spec.Components.Schemas {
+			swagger.Definitions[name] = toSwaggerSchema(schema)
+		}
+	}
+
+	// Convert security schemes. Swagger 2.0 has no bearer scheme, so bearer
+	// tokens are API keys of the Authorization header, and no cookie API
+	// keys, which are left out along with the requirements naming them.
+	if spec.Components != nil && len(spec.Components.SecuritySchemes) > 0 {
+		swagger.SecurityDefinitions = make(map[string]*SwaggerSecurityScheme)
+		for name, scheme := range spec.Components.SecuritySchemes {
+			switch {
+			case scheme.Type == "http" && scheme.Scheme == "basic":
+				swagger.SecurityDefinitions[name] = &SwaggerSecurityScheme{Type: "basic"}
+			case scheme.Type == "http":
+				swagger.SecurityDefinitions[name] = &SwaggerSecurityScheme{
+					Type:        "apiKey",
+					Description: "Bearer token, sent as \"Bearer <token>\"",
+					In:          "header",
+					Name:        "Authorization",
+				}
+			case scheme.In != "cookie":
+				swagger.SecurityDefinitions[name] = &SwaggerSecurityScheme{Type: "apiKey", In: scheme.In, Name: scheme.Name}
+			}
+		}
+	}
+	swagger.Security = swagger.requirements(spec.Security)
+
+	// Convert paths
+	for path, item := range spec.Paths {
+		out := SwaggerPath{
+			Post:   toSwaggerOperation(item.Post),
+			Get:    toSwaggerOperation(item.Get),
+			Put:    toSwaggerOperation(item.Put),
+			Patch:  toSwaggerOperation(item.Patch),
+			Delete: toSwaggerOperation(item.Delete),
+		}
+		for _, op := range []*SwaggerOperation{out.Post, out.Get, out.Put, out.Patch, out.Delete} {
+			if op != nil {
+				op.Security = swagger.requirements(op.Security)
+			}
+		}
+		swagger.Paths[path] = out
+	}
+
+	return swagger
+}
+
+// requirements returns the security requirements naming only defined
+// schemes
+func (spec *SwaggerSpec) requirements(reqs []OpenAPISecurityRequirement) []OpenAPISecurityRequirement {
+	var out []OpenAPISecurityRequirement
+	for _, req := range reqs {
+		defined := true
+		for name := range req {
+			defined = defined && spec.SecurityDefinitions[name] != nil
+		}
+		if defined {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// toSwaggerOperation converts an OpenAPI 3.0 operation
+func toSwaggerOperation(op *OpenAPIOperation) *SwaggerOperation {
+	if op == nil {
+		return nil
+	}
+
+	operation := &SwaggerOperation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.OperationID,
+		Responses:   make(map[string]SwaggerResponse),
+		Tags:        op.Tags,
+		Security:    op.Security,
+	}
+
+	// Parameters carry the type of their schema, since only body parameters
+	// have schemas. Objects, which can't be described, are strings. Swagger
+	// 2.0 has no parameter examples, so they go in the x-example extension
+	// Swagger UI reads.
+	for _, param := range op.Parameters {
+		out := SwaggerParameter{Name: param.Name, In: param.In, Required: param.Required, Type: "string", XExample: param.Example}
+		if schema := param.Schema; schema != nil && schema.Type != "" && schema.Type != "object" {
+			out.Type = schema.Type
+			out.Format = schema.Format
+			out.Items = toSwaggerSchema(schema.Items)
+			out.Enum = schema.Enum
+		}
+		operation.Parameters = append(operation.Parameters, out)
+	}
+
+	// Request bodies become a single body parameter, whose example goes in
+	// its schema
+	if op.RequestBody != nil {
+		media := op.RequestBody.Content["application/json"]
+		schema := toSwaggerSchema(media.Schema)
+		if schema != nil && media.Example != nil {
+			schema.Example = media.Example
+		}
+		operation.Parameters = append(operation.Parameters, SwaggerParameter{
+			Name:        "body",
+			In:          "body",
+			Description: op.RequestBody.Description,
+			Required:    op.RequestBody.Required,
+			Schema:      schema,
+		})
+	}
+
+	for code, resp := range op.Responses {
+		media := resp.Content["application/json"]
+		out := SwaggerResponse{
+			Description: resp.Description,
+			Schema:      toSwaggerSchema(media.Schema),
+		}
+		if media.Example != nil {
+			out.Examples = map[string]interface{}{"application/json": media.Example}
+		}
+		operation.Responses[code] = out
+	}
+
+	return operation
+}
+
+// toSwaggerSchema copies a schema, rewriting component references to
+// Swagger 2.0 definitions
+func toSwaggerSchema(schema *OpenAPISchema) *OpenAPISchema {
+	if schema == nil {
+		return nil
+	}
+
+	// Swagger 2.0 has no nullable or oneOf keywords, and its discriminator
+	// only applies to allOf hierarchies of definitions
+	out := *schema
+	out.Nullable = false
+	out.Discriminator = nil
+	if len(schema.OneOf) > 0 {
+		out.OneOf = nil
+		out.Type = "object"
+	}
+	out.Ref = strings.Replace(schema.Ref, "#/components/schemas/", "#/definitions/", 1)
+	out.Items = toSwaggerSchema(schema.Items)
+	out.AllOf = nil
+	for _, part := range schema.AllOf {
+		out.AllOf = append(out.AllOf, toSwaggerSchema(part))
+	}
+
+	if schema.Properties != nil {
+		out.Properties = make(map[string]*OpenAPISchema, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			out.Properties[name] = toSwaggerSchema(prop)
+		}
+	}
+
+	return &out
+}
+
+// ExportToJSON exports Swagger spec as JSON
+func (spec *SwaggerSpec) ExportToJSON() (string, error) {
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ExportToYAML exports Swagger spec as YAML (simplified)
+func (spec *SwaggerSpec) ExportToYAML() (string, error) {
+	json, err := spec.ExportToJSON()
+	if err != nil {
+		return "", err
+	}
+	return "# Swagger YAML export (use a YAML converter for proper formatting)\n" + json, nil
+}
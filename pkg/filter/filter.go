@@ -0,0 +1,324 @@
+// Package filter trims parsed WSDL definitions to a subset of their
+// operations, so the clients, specs and proxies of WSDLs with hundreds of
+// operations only cover the ones in use, or to one of their services and
+// ports. The schema types, elements and messages only the dropped
+// operations use are dropped with them.
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Filter selects operations by name. Patterns are globs such as Get*, with
+// the syntax of path.Match, or regular expressions between slashes such as
+// /^(Get|List)/. Names are matched case-sensitively.
+type Filter struct {
+	include []pattern
+	exclude []pattern
+}
+
+// pattern is a compiled glob or regular expression
+type pattern struct {
+	source string
+	re     *regexp.Regexp // Nil for globs
+}
+
+// match reports whether a pattern matches an operation name
+func (p pattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	ok, _ := path.Match(p.source, name)
+	return ok
+}
+
+// New returns a filter keeping the operations that match one of the
+// include patterns, or all when there are none, unless they match one of
+// the exclude patterns. It returns nil when both lists are empty.
+func New(include, exclude []string) (*Filter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	f := &Filter{}
+	var err error
+	if f.include, err = compile(include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compile(exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// compile compiles patterns
+func compile(sources []string) ([]pattern, error) {
+	patterns := make([]pattern, 0, len(sources))
+	for _, source := range sources {
+		p := pattern{source: source}
+		if len(source) > 1 && strings.HasPrefix(source, "/") && strings.HasSuffix(source, "/") {
+			re, err := regexp.Compile(source[1 : len(source)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid operation pattern %s: %w", source, err)
+			}
+			p.re = re
+		} else if _, err := path.Match(source, ""); err != nil {
+			return nil, fmt.Errorf("invalid operation pattern %s: %w", source, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Match reports whether the filter keeps an operation. A nil filter keeps
+// all.
+func (f *Filter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchAny(f.include, name) {
+		return false
+	}
+	return !matchAny(f.exclude, name)
+}
+
+// matchAny reports whether one of patterns matches name
+func matchAny(patterns []pattern, name string) bool {
+	for _, p := range patterns {
+		if p.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unused returns the patterns that match none of the operations of def,
+// likely misspelled
+func (f *Filter) Unused(def *models.Definitions) []string {
+	if f == nil {
+		return nil
+	}
+	var unused []string
+	for _, p := range append(append([]pattern(nil), f.include...), f.exclude...) {
+		used := false
+		for _, pt := range def.PortTypes {
+			for _, op := range pt.Operations {
+				used = used || p.match(op.Name)
+			}
+		}
+		if !used {
+			unused = append(unused, p.source)
+		}
+	}
+	return unused
+}
+
+// Apply returns a copy of def with the operations the filter keeps, and
+// the messages, types, simple types and global elements they use. Port
+// types left without operations are dropped; bindings, which give the
+// SOAP version of the services, are kept with the operations left. It is
+// an error for no operation to be left. A nil filter returns def.
+func (f *Filter) Apply(def *models.Definitions) (*models.Definitions, error) {
+	if f == nil {
+		return def, nil
+	}
+
+	out := *def
+	out.PortTypes = nil
+	for _, pt := range def.PortTypes {
+		var ops []models.Operation
+		for _, op := range pt.Operations {
+			if f.Match(op.Name) {
+				ops = append(ops, op)
+			}
+		}
+		if len(ops) > 0 {
+			pt.Operations = ops
+			out.PortTypes = append(out.PortTypes, pt)
+		}
+	}
+	if len(out.PortTypes) == 0 {
+		return nil, fmt.Errorf("no operations of %s match the operation filters", describe(def))
+	}
+
+	out.Bindings = make([]models.Binding, len(def.Bindings))
+	for i, binding := range def.Bindings {
+		var ops []models.BindingOperation
+		for _, op := range binding.Operations {
+			if f.Match(op.Name) {
+				ops = append(ops, op)
+			}
+		}
+		binding.Operations = ops
+		out.Bindings[i] = binding
+	}
+
+	prune(def, &out)
+	return &out, nil
+}
+
+// describe names a WSDL in errors
+func describe(def *models.Definitions) string {
+	if def.Name != "" {
+		return def.Name
+	}
+	return "the WSDL"
+}
+
+// prune sets the messages, types, simple types and global elements of out
+// to those of def the port type operations of out use
+func prune(def, out *models.Definitions) {
+	used := newUsage(def)
+	for _, pt := range out.PortTypes {
+		for _, op := range pt.Operations {
+			used.message(op.Input)
+			used.message(op.Output)
+			for _, fault := range op.Faults {
+				used.message(models.Message{Name: fault.Message})
+			}
+		}
+	}
+	used.resolve()
+
+	out.Messages = nil
+	for _, msg := range def.Messages {
+		if used.messages[msg.Name] {
+			out.Messages = append(out.Messages, msg)
+		}
+	}
+	out.Types = nil
+	for _, t := range def.Types {
+		if used.types[t.Name] {
+			out.Types = append(out.Types, t)
+		}
+	}
+	out.SimpleTypes = nil
+	for _, st := range def.SimpleTypes {
+		if used.types[st.Name] {
+			out.SimpleTypes = append(out.SimpleTypes, st)
+		}
+	}
+	out.Elements = nil
+	for _, elem := range def.Elements {
+		if used.elements[elem.Name] {
+			out.Elements = append(out.Elements, elem)
+		}
+	}
+}
+
+// usage collects the local names of the messages, types and global
+// elements reachable from the operations kept. Names are compared without
+// their prefixes, so types of the same name in other namespaces are kept
+// too.
+type usage struct {
+	def      *models.Definitions
+	messages map[string]bool
+	types    map[string]bool
+	elements map[string]bool
+	pending  []string // Types and elements to visit
+}
+
+func newUsage(def *models.Definitions) *usage {
+	return &usage{
+		def:      def,
+		messages: make(map[string]bool),
+		types:    make(map[string]bool),
+		elements: make(map[string]bool),
+	}
+}
+
+// message marks an operation message and the types of its parts. Operation
+// messages only carry parts when the WSDL declares them inline.
+func (u *usage) message(msg models.Message) {
+	name := models.LocalName(msg.Name)
+	if name == "" {
+		return
+	}
+	u.messages[name] = true
+	parts := msg.Parts
+	for _, m := range u.def.Messages {
+		if m.Name == name {
+			parts = append(parts, m.Parts...)
+		}
+	}
+	for _, part := range parts {
+		u.element(part.Element)
+		u.typ(part.Type)
+	}
+}
+
+// element marks a global element and, as anonymous types are named after
+// their elements, the type of its name
+func (u *usage) element(qname string) {
+	name := models.LocalName(qname)
+	if name == "" || u.elements[name] {
+		return
+	}
+	u.elements[name] = true
+	u.typ(name)
+	for _, elem := range u.def.Elements {
+		if elem.Name == name {
+			u.typ(elem.Type)
+		}
+	}
+}
+
+// typ marks a type to visit
+func (u *usage) typ(qname string) {
+	name := models.LocalName(qname)
+	if name == "" || u.types[name] {
+		return
+	}
+	u.types[name] = true
+	u.pending = append(u.pending, name)
+}
+
+// resolve marks everything the marked types use, their derived types,
+// which may replace them through xsi:type, and the members of the
+// substitution groups of the marked elements
+func (u *usage) resolve() {
+	for {
+		for len(u.pending) > 0 {
+			name := u.pending[0]
+			u.pending = u.pending[1:]
+			for _, t := range u.def.Types {
+				if t.Name != name {
+					continue
+				}
+				u.typ(t.Base)
+				u.typ(t.SimpleContent)
+				for _, elem := range t.Elements {
+					u.typ(elem.Type)
+					// Local elements may be references to global ones
+					u.element(elem.Name)
+				}
+				for _, attr := range t.Attributes {
+					u.typ(attr.Type)
+				}
+			}
+			for _, st := range u.def.SimpleTypes {
+				if st.Name == name {
+					u.typ(st.Base)
+				}
+			}
+		}
+
+		for _, t := range u.def.Types {
+			if t.Base != "" && u.types[models.LocalName(t.Base)] {
+				u.typ(t.Name)
+			}
+		}
+		for _, elem := range u.def.Elements {
+			if elem.SubstitutionGroup != "" && u.elements[models.LocalName(elem.SubstitutionGroup)] {
+				u.element(elem.Name)
+			}
+		}
+		if len(u.pending) == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// definitions describes a service with user and order operations
+func definitions() *models.Definitions {
+	return &models.Definitions{
+		Name: "Shop",
+		Bindings: []models.Binding{{Name: "ShopSoap", SOAPVersion: "1.2", Operations: []models.BindingOperation{
+			{Name: "GetUser"}, {Name: "ListOrders"}, {Name: "DeleteOrder"},
+		}}},
+		PortTypes: []models.PortType{
+			{Name: "Users", Operations: []models.Operation{
+				{Name: "GetUser", Input: models.Message{Name: "tns:GetUserIn"}, Output: models.Message{Name: "tns:GetUserOut"}},
+			}},
+			{Name: "Orders", Operations: []models.Operation{
+				{Name: "ListOrders", Input: models.Message{Name: "tns:ListOrdersIn"}, Output: models.Message{Name: "tns:ListOrdersOut"},
+					Faults: []models.Fault{{Name: "NotFound", Message: "tns:NotFoundFault"}}},
+				{Name: "DeleteOrder", Input: models.Message{Name: "tns:DeleteOrderIn"}},
+			}},
+		},
+		Messages: []models.Message{
+			{Name: "GetUserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "GetUserOut", Parts: []models.Part{{Name: "user", Type: "tns:User"}}},
+			{Name: "ListOrdersIn", Parts: []models.Part{{Name: "parameters", Element: "tns:ListOrders"}}},
+			{Name: "ListOrdersOut", Parts: []models.Part{{Name: "parameters", Element: "tns:ListOrdersResponse"}}},
+			{Name: "NotFoundFault", Parts: []models.Part{{Name: "detail", Element: "tns:NotFound"}}},
+			{Name: "DeleteOrderIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+		},
+		Types: []models.Type{
+			{Name: "User", Elements: []models.Element{{Name: "name", Type: "xs:string"}, {Name: "address", Type: "tns:Address"}}},
+			{Name: "Address", Elements: []models.Element{{Name: "country", Type: "tns:Country"}}},
+			{Name: "ListOrders", Elements: []models.Element{{Name: "status", Type: "tns:Status"}}},
+			{Name: "ListOrdersResponse", Elements: []models.Element{{Name: "order", Type: "tns:Order", MaxOccurs: "unbounded"}}},
+			{Name: "Order", Elements: []models.Element{{Name: "id", Type: "xs:int"}}},
+			{Name: "RushOrder", Base: "tns:Order", Derivation: models.DerivationExtension},
+			{Name: "NotFound", Elements: []models.Element{{Name: "id", Type: "xs:int"}}},
+		},
+		SimpleTypes: []models.SimpleType{
+			{Name: "Country", Base: "xs:string"},
+			{Name: "Status", Base: "xs:string", Enumeration: []string{"open", "closed"}},
+		},
+		Elements: []models.Element{
+			{Name: "ListOrders"}, {Name: "ListOrdersResponse"}, {Name: "NotFound"},
+		},
+	}
+}
+
+// names returns the names of the operations, messages, types, simple types
+// and elements of def
+func names(def *models.Definitions) map[string][]string {
+	got := make(map[string][]string)
+	for _, pt := range def.PortTypes {
+		for _, op := range pt.Operations {
+			got["operations"] = append(got["operations"], op.Name)
+		}
+	}
+	for _, b := range def.Bindings {
+		for _, op := range b.Operations {
+			got["bindings"] = append(got["bindings"], op.Name)
+		}
+	}
+	for _, m := range def.Messages {
+		got["messages"] = append(got["messages"], m.Name)
+	}
+	for _, t := range def.Types {
+		got["types"] = append(got["types"], t.Name)
+	}
+	for _, st := range def.SimpleTypes {
+		got["simpleTypes"] = append(got["simpleTypes"], st.Name)
+	}
+	for _, e := range def.Elements {
+		got["elements"] = append(got["elements"], e.Name)
+	}
+	return got
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             map[string][]string
+	}{
+		{
+			name:    "glob",
+			include: []string{"Get*"},
+			want: map[string][]string{
+				"operations":  {"GetUser"},
+				"bindings":    {"GetUser"},
+				"messages":    {"GetUserIn", "GetUserOut"},
+				"types":       {"User", "Address"},
+				"simpleTypes": {"Country"},
+			},
+		},
+		{
+			name:    "regular expression and exclusion",
+			include: []string{"/Order/"},
+			exclude: []string{"Delete*"},
+			want: map[string][]string{
+				"operations":  {"ListOrders"},
+				"bindings":    {"ListOrders"},
+				"messages":    {"ListOrdersIn", "ListOrdersOut", "NotFoundFault"},
+				"types":       {"ListOrders", "ListOrdersResponse", "Order", "RushOrder", "NotFound"},
+				"simpleTypes": {"Status"},
+				"elements":    {"ListOrders", "ListOrdersResponse", "NotFound"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		f, err := New(tt.include, tt.exclude)
+		if err != nil {
+			t.Fatalf("%s: New() error = %v", tt.name, err)
+		}
+		def := definitions()
+		got, err := f.Apply(def)
+		if err != nil {
+			t.Fatalf("%s: Apply() error = %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(names(got), tt.want) {
+			t.Errorf("%s: Apply() = %v, want %v", tt.name, names(got), tt.want)
+		}
+		if len(def.PortTypes[1].Operations) != 2 || got.Bindings[0].SOAPVersion != "1.2" {
+			t.Errorf("%s: Apply() changed its input or dropped the binding", tt.name)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	if f, err := New(nil, nil); f != nil || err != nil {
+		t.Errorf("New(nil, nil) = %v, %v, want nil", f, err)
+	}
+	var f *Filter
+	if !f.Match("Anything") {
+		t.Error("nil filter doesn't match")
+	}
+
+	for _, pattern := range []string{"[Get", "/(Get/"} {
+		if _, err := New([]string{pattern}, nil); err == nil || !strings.Contains(err.Error(), pattern) {
+			t.Errorf("New(%q) error = %v", pattern, err)
+		}
+	}
+
+	f, _ = New([]string{"Get*", "Fetch*"}, []string{"/^GetAdmin/"})
+	if unused := f.Unused(definitions()); !reflect.DeepEqual(unused, []string{"Fetch*", "/^GetAdmin/"}) {
+		t.Errorf("Unused() = %v", unused)
+	}
+	f, _ = New([]string{"Fetch*"}, nil)
+	if _, err := f.Apply(definitions()); err == nil || !strings.Contains(err.Error(), "no operations") {
+		t.Errorf("Apply() without matches error = %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Select returns a copy of def with the service named service, or the
+// service of the port when service is empty, and when port is set that port
+// only. The bindings and port types the ports kept don't use are dropped,
+// along with the messages and types only they use, so that clients and
+// proxies call the operations of the service selected at its own endpoint.
+// Port names are unique across a WSDL, so port alone selects a service. A
+// def is returned as it is when both are empty.
+func Select(def *models.Definitions, service, port string) (*models.Definitions, error) {
+	if service == "" && port == "" {
+		return def, nil
+	}
+
+	var candidates []models.Service
+	for _, svc := range def.Services {
+		if service == "" || svc.Name == service {
+			candidates = append(candidates, svc)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%s has no service %s (services: %s)", describe(def), service, strings.Join(serviceNames(def), ", "))
+	}
+
+	selected := candidates[0]
+	if port != "" {
+		var found bool
+		var names []string
+		for _, svc := range candidates {
+			for _, p := range svc.Ports {
+				names = append(names, p.Name)
+				if p.Name == port && !found {
+					selected = svc
+					selected.Ports = []models.Port{p}
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no port %s (ports: %s)", describe(def), port, strings.Join(names, ", "))
+		}
+	}
+
+	out := *def
+	out.Services = []models.Service{selected}
+	keepPorts(def, &out, selected.Ports)
+	return &out, nil
+}
+
+// Split returns a copy of def per service, named after it and trimmed as
+// Select does, when the services of def bind different port types, such
+// as the order and the customer services of an ERP. WSDLs with a single
+// service, or whose services expose the same operations at different
+// addresses, are returned as they are. Services without ports are left
+// out.
+func Split(def *models.Definitions) []*models.Definitions {
+	var services []models.Service
+	operations := make(map[string]bool)
+	for _, svc := range def.Services {
+		if len(svc.Ports) == 0 {
+			continue
+		}
+		services = append(services, svc)
+		operations[strings.Join(portTypes(def, svc.Ports), " ")] = true
+	}
+	if len(services) < 2 || len(operations) < 2 {
+		return []*models.Definitions{def}
+	}
+
+	defs := make([]*models.Definitions, 0, len(services))
+	for _, svc := range services {
+		out := *def
+		out.Name = svc.Name
+		out.Services = []models.Service{svc}
+		keepPorts(def, &out, svc.Ports)
+		defs = append(defs, &out)
+	}
+	return defs
+}
+
+// keepPorts sets the bindings and port types of out to those of def that
+// ports use, pruning the rest as Apply does. Bindings are left as they are
+// when none of the ports resolves, as with bindings of WSDLs that aren't
+// imported; port types likewise.
+func keepPorts(def, out *models.Definitions, ports []models.Port) {
+	bindings := make(map[string]bool)
+	for _, p := range ports {
+		bindings[models.LocalName(p.Binding)] = true
+	}
+	var keptBindings []models.Binding
+	types := make(map[string]bool)
+	for _, b := range def.Bindings {
+		if bindings[b.Name] {
+			keptBindings = append(keptBindings, b)
+			types[models.LocalName(b.Type)] = true
+		}
+	}
+	if len(keptBindings) == 0 {
+		return
+	}
+	out.Bindings = keptBindings
+
+	var keptTypes []models.PortType
+	for _, pt := range def.PortTypes {
+		if types[pt.Name] {
+			keptTypes = append(keptTypes, pt)
+		}
+	}
+	if len(keptTypes) == 0 {
+		return
+	}
+	out.PortTypes = keptTypes
+	prune(def, out)
+}
+
+// portTypes returns the sorted names of the port types bound by ports
+func portTypes(def *models.Definitions, ports []models.Port) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range ports {
+		name := models.LocalName(p.Binding)
+		for _, b := range def.Bindings {
+			if b.Name == name {
+				name = models.LocalName(b.Type)
+				break
+			}
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// serviceNames returns the names of the services of def
+func serviceNames(def *models.Definitions) []string {
+	names := make([]string, 0, len(def.Services))
+	for _, svc := range def.Services {
+		names = append(names, svc.Name)
+	}
+	return names
+}
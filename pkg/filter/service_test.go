@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// services describes the definitions of filter_test.go served by a user
+// service with SOAP 1.1 and 1.2 ports and an order service
+func services() *models.Definitions {
+	def := definitions()
+	def.Bindings = []models.Binding{
+		{Name: "UsersSoap", Type: "tns:Users", SOAPVersion: "1.1", Operations: []models.BindingOperation{{Name: "GetUser"}}},
+		{Name: "UsersSoap12", Type: "tns:Users", SOAPVersion: "1.2", Operations: []models.BindingOperation{{Name: "GetUser"}}},
+		{Name: "OrdersSoap", Type: "tns:Orders", SOAPVersion: "1.1", Operations: []models.BindingOperation{{Name: "ListOrders"}, {Name: "DeleteOrder"}}},
+	}
+	def.Services = []models.Service{
+		{Name: "UserService", Ports: []models.Port{
+			{Name: "UsersSoap", Binding: "tns:UsersSoap", Address: "http://users/soap"},
+			{Name: "UsersSoap12", Binding: "tns:UsersSoap12", Address: "http://users/soap12"},
+		}},
+		{Name: "OrderService", Ports: []models.Port{
+			{Name: "OrdersSoap", Binding: "tns:OrdersSoap", Address: "http://orders/soap"},
+		}},
+	}
+	return def
+}
+
+func TestSelect(t *testing.T) {
+	got, err := Select(services(), "OrderService", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"operations":  {"ListOrders", "DeleteOrder"},
+		"bindings":    {"ListOrders", "DeleteOrder"},
+		"messages":    {"ListOrdersIn", "ListOrdersOut", "NotFoundFault", "DeleteOrderIn"},
+		"types":       {"ListOrders", "ListOrdersResponse", "Order", "RushOrder", "NotFound"},
+		"simpleTypes": {"Status"},
+		"elements":    {"ListOrders", "ListOrdersResponse", "NotFound"},
+	}
+	if !reflect.DeepEqual(names(got), want) {
+		t.Errorf("Select(OrderService) = %v, want %v", names(got), want)
+	}
+	if len(got.Services) != 1 || got.Services[0].Name != "OrderService" || got.Name != "Shop" {
+		t.Errorf("Select(OrderService) services = %+v, name %s", got.Services, got.Name)
+	}
+
+	got, err = Select(services(), "", "UsersSoap12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Services) != 1 || !reflect.DeepEqual(got.Services[0].Ports, []models.Port{services().Services[0].Ports[1]}) {
+		t.Errorf("Select(UsersSoap12) services = %+v", got.Services)
+	}
+	if len(got.Bindings) != 1 || got.Bindings[0].SOAPVersion != "1.2" || len(got.PortTypes) != 1 || got.PortTypes[0].Name != "Users" {
+		t.Errorf("Select(UsersSoap12) bindings = %+v, port types %+v", got.Bindings, got.PortTypes)
+	}
+
+	for _, tt := range []struct{ service, port, want string }{
+		{"Billing", "", "services: UserService, OrderService"},
+		{"OrderService", "UsersSoap", "ports: OrdersSoap"},
+		{"", "Soap", "ports: UsersSoap, UsersSoap12, OrdersSoap"},
+	} {
+		if _, err := Select(services(), tt.service, tt.port); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Select(%q, %q) error = %v, want %s", tt.service, tt.port, err, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	defs := Split(services())
+	if len(defs) != 2 {
+		t.Fatalf("Split() = %d definitions, want 2", len(defs))
+	}
+	for i, want := range []struct {
+		name       string
+		operations []string
+		ports      int
+	}{
+		{"UserService", []string{"GetUser"}, 2},
+		{"OrderService", []string{"ListOrders", "DeleteOrder"}, 1},
+	} {
+		def := defs[i]
+		if def.Name != want.name || !reflect.DeepEqual(names(def)["operations"], want.operations) || len(def.Services[0].Ports) != want.ports {
+			t.Errorf("Split()[%d] = %s with operations %v and %d ports", i, def.Name, names(def)["operations"], len(def.Services[0].Ports))
+		}
+	}
+
+	// Test and production services of the same port type
+	def := services()
+	def.Services[1].Ports = []models.Port{{Name: "UsersTest", Binding: "tns:UsersSoap", Address: "http://test/users"}}
+	if defs := Split(def); len(defs) != 1 || defs[0] != def {
+		t.Errorf("Split() of services of the same operations = %d definitions", len(defs))
+	}
+}
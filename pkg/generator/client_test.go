@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/pkg/parser"
+)
+
+// testGeneratedClient generates the client of testdata/quotes.wsdl and
+// runs the test files of testdata/clienttests named by tests, with the
+// quote service of service_test.go. configure adjusts the generator.
+func testGeneratedClient(t *testing.T, configure func(g *Generator), tests ...string) {
+	t.Helper()
+	testGenerated(t, "quotes", "clienttests", configure, append(tests, "service_test.go")...)
+}
+
+// testGenerated generates the package of testdata/<name>.wsdl into a
+// directory under testdata, adds the test files of testdata/<testDir>
+// named by tests and runs them with the race detector. configure adjusts
+// the generator.
+func testGenerated(t *testing.T, name, testDir string, configure func(g *Generator), tests ...string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiles generated code")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	def, err := parser.NewParser().Parse(filepath.Join("testdata", name+".wsdl"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	// The package is inside this module, so that it imports the runtime
+	// packages of this checkout
+	dir, err := os.MkdirTemp("testdata", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	g := NewGenerator(dir, name)
+	if configure != nil {
+		configure(g)
+	}
+	if err := g.Generate(def); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile(filepath.Join("testdata", testDir, test))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, test), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := []string{"test", "-count=1"}
+	if os.Getenv("CGO_ENABLED") != "0" {
+		args = append(args, "-race")
+	}
+	cmd := exec.Command("go", append(args, "./"+filepath.ToSlash(dir))...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test of the generated package failed: %v\n%s", err, out)
+	}
+}
+
+func TestGeneratedClientAddressing(t *testing.T) {
+	testGeneratedClient(t, nil, "addressing_test.go")
+}
+
+func TestGeneratedClientCache(t *testing.T) {
+	testGeneratedClient(t, nil, "cache_test.go")
+}
+
+func TestGeneratedClientContext(t *testing.T) {
+	testGeneratedClient(t, nil, "context_test.go")
+}
+
+func TestGeneratedClientEndpoints(t *testing.T) {
+	testGeneratedClient(t, nil, "endpoints_test.go")
+}
+
+func TestGeneratedClientTransport(t *testing.T) {
+	testGeneratedClient(t, nil, "transport_test.go")
+}
+
+func TestGeneratedClientMiddleware(t *testing.T) {
+	testGeneratedClient(t, nil, "middleware_test.go")
+}
+
+func TestGeneratedClientOptions(t *testing.T) {
+	testGeneratedClient(t, nil, "options_test.go")
+}
+
+func TestGeneratedClientStreaming(t *testing.T) {
+	testGeneratedClient(t, nil, "streaming_test.go")
+}
+
+// TestGeneratedClientConcurrency runs the concurrency test of the mock
+// server tests too
+func TestGeneratedClientConcurrency(t *testing.T) {
+	testGeneratedClient(t, func(g *Generator) { g.SetWithTests(true) }, "concurrency_test.go")
+}
+
+// TestGeneratedSubstitutionGroups decodes the members of a substitution
+// group next to xs:any
+func TestGeneratedSubstitutionGroups(t *testing.T) {
+	testGenerated(t, "zoo", "zootests", func(g *Generator) { g.SetWithTests(true) }, "substitution_test.go")
+}
+
+func TestGenerateStreaming(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="GetQuote"><xs:complexType><xs:sequence>
+        <xs:element name="symbol" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetQuoteResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="xs:double"/>
+      </xs:sequence></xs:complexType></xs:element>`, "GetQuote")
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{
+			name: "threshold",
+			want: []string{
+				"streamThreshold int64",
+				"const DefaultStreamThreshold = 1 << 20",
+				"streamThreshold:  DefaultStreamThreshold,",
+				"func WithStreamThreshold(threshold int64) Option {",
+			},
+		},
+		{
+			name: "streamed responses",
+			want: []string{
+				"if c.streamThreshold < 0 || s.debug != nil {",
+				"return resp.ContentLength < 0 || resp.ContentLength > c.streamThreshold",
+				"if err := xml.NewDecoder(body).Decode(&responseEnvelope); err != nil {",
+			},
+		},
+		{
+			name: "call stream",
+			want: []string{
+				"func (c *Client) CallStream(ctx context.Context, soapAction string, request interface{}) (io.ReadCloser, error) {",
+			},
+		},
+	}
+
+	files := render(t, wsdl, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, files, "client.go", tt.want)
+		})
+	}
+}
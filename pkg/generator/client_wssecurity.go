@@ -2,127 +2,1379 @@ package generator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/thdev01/wsdl2api/internal/models"
 )
 
+// SetSOAPVersion sets the SOAP version the generated client uses by
+// default, "1.1" or "1.2". By default it is the version of the WSDL binding.
+func (g *Generator) SetSOAPVersion(version string) {
+	g.soapVersion = version
+}
+
 // generateClientWithSecurity generates a SOAP client with WS-Security support
 func (g *Generator) generateClientWithSecurity(def *models.Definitions) error {
 	endpoint := g.findServiceEndpoint(def)
+	endpoints := goStrings(g.findSOAPEndpoints(def))
 
 	content := fmt.Sprintf(`package %s
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/thdev01/wsdl2api/pkg/addressing"
+	"github.com/thdev01/wsdl2api/pkg/charset"
+	"github.com/thdev01/wsdl2api/pkg/compression"
 	"github.com/thdev01/wsdl2api/pkg/security"
 )
 
-// Client represents a SOAP client with WS-Security support
+// Client represents a SOAP client with WS-Security support. A Client is
+// safe for concurrent use by multiple goroutines, setters included: each
+// call works on a copy of the settings taken when it starts. Create one
+// per service and share it, rather than one per call, so that its calls
+// reuse the keep-alive connections of its transport. The options of
+// NewClient configure what no setter changes.
 type Client struct {
-	URL        string
-	HTTPClient *http.Client
-	Headers    map[string]string
-	Security   *security.WSSecurity
-	SOAPVersion string // "1.1" or "1.2"
+	// streamThreshold is the size in bytes above which responses are
+	// decoded as they are read instead of being buffered first. Responses
+	// of unknown length are always decoded as read, and a negative
+	// threshold buffers every response.
+	streamThreshold int64
+
+	// balancing is how calls choose among the endpoints set with
+	// SetEndpoints, and endpointCooldown how long an endpoint that failed
+	// is passed over
+	balancing        Balancing
+	endpointCooldown time.Duration
+
+	// mu guards the settings, and url, httpClient and endpoints, which
+	// setters change too
+	mu         sync.RWMutex
+	url        string
+	httpClient *http.Client
+	settings   settings
+	endpoints  *endpoints
+	transport  transportOptions
+}
+
+// settings are the settings of the options and setters, read with the
+// accessors. Setters replace them under the client's lock rather than
+// change them in place, maps and slices included, so that the copy a call
+// takes stays as it was.
+type settings struct {
+	headers      map[string]string
+	wsSecurity   *security.WSSecurity
+	signer       *security.X509Signer
+	token        security.Token
+	oauth2       *security.TokenSource
+	wsAddressing *addressing.WSAddressing
+	soapVersion  string // "1.1" or "1.2"
+	compression  string // Content coding of requests: "gzip", "deflate" or "" for none
+	middleware   []Middleware
+	debug        io.Writer
+	soapHeaders  soapHeaders
+	session      *session
+	cache        *responseCache
+}
+
+// snapshot is what a call reads of its client, copied when it starts
+type snapshot struct {
+	settings
+	url        string
+	httpClient *http.Client
+	endpoints  *endpoints
+}
+
+// snapshot returns a copy of the settings and endpoints of the client
+func (c *Client) snapshot() *snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return &snapshot{settings: c.settings, url: c.url, httpClient: c.httpClient, endpoints: c.endpoints}
+}
+
+// update changes the settings under the client's lock
+func (c *Client) update(change func(s *settings)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	change(&c.settings)
+}
+
+// DefaultStreamThreshold is the stream threshold of clients created
+// without WithStreamThreshold
+const DefaultStreamThreshold = 1 << 20
+
+// DefaultEndpointCooldown is the endpoint cooldown of clients created
+// without WithEndpointCooldown
+const DefaultEndpointCooldown = 30 * time.Second
+
+// DefaultEndpoints are the addresses of the SOAP ports of the service in
+// the WSDL. Clients created without a URL call them with failover when
+// there are several.
+var DefaultEndpoints = %s
+
+// CallFunc performs a SOAP call. It is the unit wrapped by Middleware.
+type CallFunc func(ctx context.Context, soapAction string, request, response interface{}) error
+
+// Middleware wraps a CallFunc to add behavior such as logging, metrics,
+// signing or header injection
+type Middleware func(next CallFunc) CallFunc
+
+// headersKey is the context key for per-call HTTP headers
+type headersKey struct{}
+
+// WithHeader returns a context that adds an HTTP header to calls made with
+// it, so middleware can inject headers per call
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	headers := http.Header{}
+	if prev, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		headers = prev.Clone()
+	}
+	headers.Set(key, value)
+	return context.WithValue(ctx, headersKey{}, headers)
 }
 
-// NewClient creates a new SOAP client
-func NewClient(url string) *Client {
+// NewClient creates a new SOAP client, configured by opts
+func NewClient(url string, opts ...Option) *Client {
+	c := newClient(url, opts)
+	if c.transport.set && !c.transport.custom {
+		c.httpClient = c.transport.client(nil)
+	}
+	return c
+}
+
+// newClient creates a client with the options applied, but not their
+// transport settings
+func newClient(url string, opts []Option) *Client {
+	c := &Client{
+		url:        url,
+		httpClient: &http.Client{},
+		settings: settings{
+			headers:     make(map[string]string),
+			soapVersion: "%s",
+		},
+
+		streamThreshold:  DefaultStreamThreshold,
+		endpointCooldown: DefaultEndpointCooldown,
+	}
 	if url == "" {
-		url = "%s"
+		c.url = "%s"
+		c.SetEndpoints(DefaultEndpoints)
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
-	return &Client{
-		URL:         url,
-		HTTPClient:  &http.Client{},
-		Headers:     make(map[string]string),
-		SOAPVersion: "1.1",
+	return c
+}
+
+// NewClientWithTLS creates a SOAP client whose connections use the TLS
+// options: custom CAs, a client certificate for mutual TLS or a minimum
+// TLS version
+func NewClientWithTLS(url string, tlsOpts security.TLSOptions, opts ...Option) (*Client, error) {
+	transport, err := tlsOpts.Transport()
+	if err != nil {
+		return nil, err
+	}
+	c := newClient(url, opts)
+	c.httpClient = c.transport.client(transport)
+	return c, nil
+}
+
+// Option configures a client created with NewClient
+type Option func(*Client)
+
+// transportOptions are the HTTP transport settings of the options
+type transportOptions struct {
+	set                   bool // Whether any option set them
+	custom                bool // Whether WithHTTPClient replaced the HTTP client
+	proxySet              bool
+	proxy                 *url.URL
+	http2                 *bool
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	timeout               time.Duration
+}
+
+// client returns an HTTP client using base with the settings applied, or
+// a copy of the default transport when base is nil
+func (t *transportOptions) client(base *http.Transport) *http.Client {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if t.proxySet {
+		base.Proxy = nil
+		if t.proxy != nil {
+			base.Proxy = http.ProxyURL(t.proxy)
+		}
+	}
+	if t.http2 != nil {
+		base.ForceAttemptHTTP2 = *t.http2
+		if !*t.http2 {
+			// A non-nil empty map turns HTTP/2 off
+			base.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+	if t.dialTimeout > 0 {
+		base.DialContext = (&net.Dialer{Timeout: t.dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if t.tlsHandshakeTimeout > 0 {
+		base.TLSHandshakeTimeout = t.tlsHandshakeTimeout
+	}
+	if t.responseHeaderTimeout > 0 {
+		base.ResponseHeaderTimeout = t.responseHeaderTimeout
+	}
+	return &http.Client{Transport: base, Timeout: t.timeout}
+}
+
+// WithProxy sends requests through an HTTP proxy, or directly when proxy
+// is nil. By default the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables choose the proxy.
+func WithProxy(proxy *url.URL) Option {
+	return func(c *Client) {
+		c.transport.set = true
+		c.transport.proxySet = true
+		c.transport.proxy = proxy
+	}
+}
+
+// WithHTTP2 negotiates HTTP/2 with TLS endpoints that support it, or
+// forces HTTP/1.1 when enabled is false, for services behind proxies that
+// mishandle HTTP/2
+func WithHTTP2(enabled bool) Option {
+	return func(c *Client) {
+		c.transport.set = true
+		c.transport.http2 = &enabled
+	}
+}
+
+// WithDialTimeout bounds the time connecting to the service takes
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.set = true
+		c.transport.dialTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout bounds the time the TLS handshake takes
+func WithTLSHandshakeTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.set = true
+		c.transport.tlsHandshakeTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout bounds the time waiting for the response
+// headers once the request is sent, the time the service takes to answer
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.set = true
+		c.transport.responseHeaderTimeout = d
+	}
+}
+
+// WithTimeout bounds the time a whole call takes, reading the response
+// included
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.set = true
+		c.transport.timeout = d
+	}
+}
+
+// WithHTTPClient makes calls with client, such as one whose transport
+// logs or traces requests, in place of a client of the transport options.
+// NewClientWithTLS replaces it with a client of its TLS transport.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+		c.transport.custom = true
+	}
+}
+
+// WithStreamThreshold sets the size in bytes above which responses are
+// decoded as they are read instead of being buffered first,
+// DefaultStreamThreshold by default. Responses of unknown length are
+// always decoded as read, and a negative threshold buffers every response.
+func WithStreamThreshold(threshold int64) Option {
+	return func(c *Client) {
+		c.streamThreshold = threshold
+	}
+}
+
+// WithHeaders adds HTTP headers to every request, as SetHeader does
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for key, value := range headers {
+			c.SetHeader(key, value)
+		}
+	}
+}
+
+// WithBasicAuth authenticates requests with a WS-Security UsernameToken
+// carrying the password, as SetBasicAuth does
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.SetBasicAuth(username, password)
+	}
+}
+
+// WithDigestAuth authenticates requests with a WS-Security UsernameToken
+// carrying a password digest, as SetDigestAuth does
+func WithDigestAuth(username, password string) Option {
+	return func(c *Client) {
+		c.SetDigestAuth(username, password)
+	}
+}
+
+// WithSigner signs requests with an X.509 certificate, as SetX509Signing
+// does with the signer it loads
+func WithSigner(signer *security.X509Signer) Option {
+	return func(c *Client) {
+		c.update(func(s *settings) { s.signer = signer })
+	}
+}
+
+// WithOAuth2 authenticates requests with the bearer tokens of source, as
+// SetOAuth2 does with the source it creates
+func WithOAuth2(source *security.TokenSource) Option {
+	return func(c *Client) {
+		c.update(func(s *settings) { s.oauth2 = source })
+	}
+}
+
+// WithAddressing adds WS-Addressing headers to every request, as
+// EnableAddressing does
+func WithAddressing() Option {
+	return func(c *Client) {
+		c.EnableAddressing()
+	}
+}
+
+// WithSOAPVersion sets the SOAP version, "1.1" or "1.2", in place of the
+// one of the WSDL binding
+func WithSOAPVersion(version string) Option {
+	return func(c *Client) {
+		c.SetSOAPVersion(version)
+	}
+}
+
+// WithCompression compresses requests with encoding, "gzip" or "deflate",
+// as SetCompression does. Calls fail with other encodings.
+func WithCompression(encoding string) Option {
+	return func(c *Client) {
+		c.update(func(s *settings) { s.compression = encoding })
+	}
+}
+
+// WithDebug writes the SOAP envelopes of calls to w, as SetDebug does
+func WithDebug(w io.Writer) Option {
+	return func(c *Client) {
+		c.SetDebug(w)
+	}
+}
+
+// WithSessions keeps the session of stateful services, as EnableSessions
+// does
+func WithSessions(headers ...string) Option {
+	return func(c *Client) {
+		c.EnableSessions(headers...)
+	}
+}
+
+// WithSOAPHeader adds a header block to the SOAP Header of every request,
+// as AddSOAPHeader does
+func WithSOAPHeader(v interface{}) Option {
+	return func(c *Client) {
+		c.AddSOAPHeader(v)
+	}
+}
+
+// WithMiddleware wraps calls with middleware, as Use does
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.Use(middleware...)
+	}
+}
+
+// WithEndpoints spreads calls over several endpoints, as SetEndpoints
+// does, in place of the URL of NewClient
+func WithEndpoints(urls ...string) Option {
+	return func(c *Client) {
+		c.SetEndpoints(urls)
+	}
+}
+
+// WithBalancing sets how calls choose among the endpoints set with
+// WithEndpoints or SetEndpoints, Failover by default
+func WithBalancing(balancing Balancing) Option {
+	return func(c *Client) {
+		c.balancing = balancing
+	}
+}
+
+// WithEndpointCooldown sets how long an endpoint that failed is passed
+// over, DefaultEndpointCooldown by default
+func WithEndpointCooldown(cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.endpointCooldown = cooldown
+	}
+}
+
+// WithCache serves the responses of idempotent operations from a cache,
+// as EnableCache does
+func WithCache(opts CacheOptions) Option {
+	return func(c *Client) {
+		c.EnableCache(opts)
 	}
 }
 
 // SetBasicAuth sets basic authentication (WS-Security UsernameToken)
 func (c *Client) SetBasicAuth(username, password string) {
-	c.Security = &security.WSSecurity{
-		Username:  username,
-		Password:  password,
-		UseDigest: false,
-	}
+	c.update(func(s *settings) {
+		s.wsSecurity = &security.WSSecurity{
+			Username:  username,
+			Password:  password,
+			UseDigest: false,
+		}
+	})
 }
 
 // SetDigestAuth sets digest authentication (WS-Security UsernameToken with digest)
 func (c *Client) SetDigestAuth(username, password string) {
-	c.Security = &security.WSSecurity{
-		Username:  username,
-		Password:  password,
-		UseDigest: true,
+	c.update(func(s *settings) {
+		s.wsSecurity = &security.WSSecurity{
+			Username:  username,
+			Password:  password,
+			UseDigest: true,
+		}
+	})
+}
+
+// SetNTLMAuth authenticates requests with NTLMv2 at the HTTP level, as
+// WCF services with Windows authentication require
+func (c *Client) SetNTLMAuth(username, password, domain string) {
+	c.setAuthTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &security.NTLMTransport{
+			Username: username,
+			Password: password,
+			Domain:   domain,
+			Base:     base,
+		}
+	})
+}
+
+// SetSPNEGO authenticates requests with Kerberos through SPNEGO, taking
+// the tokens from provider. An empty spn uses HTTP/ and the host of the
+// endpoint.
+func (c *Client) SetSPNEGO(provider security.SPNEGOProvider, spn string) {
+	c.setAuthTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &security.SPNEGOTransport{
+			Provider: provider,
+			SPN:      spn,
+			Base:     base,
+		}
+	})
+}
+
+// setAuthTransport replaces the HTTP client with a copy whose transport
+// wraps its transport without NTLM or SPNEGO authentication, so
+// setting either replaces the other. The HTTP client is copied rather
+// than changed, as calls may be using it, and it may be shared.
+func (c *Client) setAuthTransport(wrap func(base http.RoundTripper) http.RoundTripper) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	base := c.httpClient.Transport
+	switch t := base.(type) {
+	case *security.NTLMTransport:
+		base = t.Base
+	case *security.SPNEGOTransport:
+		base = t.Base
+	}
+	client := *c.httpClient
+	client.Transport = wrap(base)
+	c.httpClient = &client
+}
+
+// SetX509Signing signs requests with the certificate and private key in
+// the PEM files: the certificate is sent as a BinarySecurityToken and the
+// Body, Timestamp and UsernameToken are signed
+func (c *Client) SetX509Signing(certFile, keyFile string) error {
+	signer, err := security.LoadX509Signer(certFile, keyFile)
+	if err != nil {
+		return err
 	}
+	c.update(func(s *settings) { s.signer = signer })
+	return nil
+}
+
+// SetSecurityToken embeds a token such as a SAML assertion in the
+// WS-Security header of every request, next to any UsernameToken. The token
+// is a single element declaring the namespace prefixes it uses; nil removes
+// it.
+func (c *Client) SetSecurityToken(token security.Token) error {
+	if token != nil {
+		if err := token.Validate(); err != nil {
+			return err
+		}
+	}
+	c.update(func(s *settings) { s.token = token })
+	return nil
+}
+
+// SetOAuth2 authenticates requests with a bearer token acquired with the
+// OAuth2 client credentials flow. Tokens are cached and renewed shortly
+// before they expire.
+func (c *Client) SetOAuth2(config security.OAuth2Config) error {
+	source, err := security.NewTokenSource(config, nil)
+	if err != nil {
+		return err
+	}
+	c.update(func(s *settings) { s.oauth2 = source })
+	return nil
+}
+
+// securityHeader returns the WS-Security header of requests, or nil when
+// neither credentials nor a security token are set
+func (s *settings) securityHeader() *security.SecurityHeader {
+	if s.token == nil {
+		return security.NewSecurityHeader(s.wsSecurity)
+	}
+	ws := s.wsSecurity
+	if ws == nil {
+		ws = &security.WSSecurity{}
+	}
+	header := security.NewSecurityHeader(ws)
+	header.AddToken(s.token)
+	return header
+}
+
+// EnableAddressing adds WS-Addressing headers (To, Action, MessageID,
+// ReplyTo) to every request, as required by many WCF endpoints
+func (c *Client) EnableAddressing() {
+	c.update(func(s *settings) {
+		if s.wsAddressing == nil {
+			s.wsAddressing = &addressing.WSAddressing{}
+		}
+	})
+}
+
+// SetAddressing sets the WS-Addressing To, ReplyTo and RelatesTo values
+func (c *Client) SetAddressing(to, replyTo, relatesTo string) {
+	c.update(func(s *settings) {
+		s.wsAddressing = &addressing.WSAddressing{
+			To:        to,
+			ReplyTo:   replyTo,
+			RelatesTo: relatesTo,
+		}
+	})
 }
 
 // SetSOAPVersion sets the SOAP version (1.1 or 1.2)
 func (c *Client) SetSOAPVersion(version string) {
-	c.SOAPVersion = version
+	c.update(func(s *settings) { s.soapVersion = version })
+}
+
+// SetCompression compresses requests with encoding, "gzip" or "deflate",
+// and accepts responses compressed with either; "" turns compression off.
+// Only use it with services that accept compressed requests.
+func (c *Client) SetCompression(encoding string) error {
+	if err := compression.Check(encoding); err != nil {
+		return err
+	}
+	c.update(func(s *settings) { s.compression = encoding })
+	return nil
+}
+
+// SetDebug writes the SOAP envelopes the client sends and receives to w,
+// with passwords and tokens redacted, to debug calls; nil turns it off.
+// Responses are read whole before being decoded while debugging, except
+// for the ones of CallStream, which aren't written. w must be safe for
+// concurrent use if the client is.
+func (c *Client) SetDebug(w io.Writer) {
+	c.update(func(s *settings) { s.debug = w })
+}
+
+// dump writes an envelope to the debug writer, after a line describing it
+func (s *settings) dump(title string, envelope []byte) {
+	if s.debug == nil {
+		return
+	}
+	var b bytes.Buffer
+	b.WriteString(title + "\n")
+	if len(envelope) > 0 {
+		b.Write(bytes.TrimSpace(security.Redact(envelope)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	s.debug.Write(b.Bytes())
 }
 
 // SetHeader sets a custom HTTP header
 func (c *Client) SetHeader(key, value string) {
-	c.Headers[key] = value
+	c.update(func(s *settings) {
+		headers := make(map[string]string, len(s.headers)+1)
+		for k, v := range s.headers {
+			headers[k] = v
+		}
+		headers[key] = value
+		s.headers = headers
+	})
+}
+
+// Headers returns a copy of the HTTP headers set with SetHeader and
+// WithHeaders
+func (c *Client) Headers() map[string]string {
+	s := c.snapshot()
+	headers := make(map[string]string, len(s.headers))
+	for key, value := range s.headers {
+		headers[key] = value
+	}
+	return headers
+}
+
+// Security returns a copy of the WS-Security credentials set with
+// SetBasicAuth or SetDigestAuth, or nil
+func (c *Client) Security() *security.WSSecurity {
+	s := c.snapshot()
+	if s.wsSecurity == nil {
+		return nil
+	}
+	ws := *s.wsSecurity
+	return &ws
+}
+
+// SecurityToken returns the token set with SetSecurityToken, or nil
+func (c *Client) SecurityToken() security.Token {
+	return c.snapshot().token
+}
+
+// Addressing returns a copy of the WS-Addressing settings, or nil when
+// WS-Addressing is off
+func (c *Client) Addressing() *addressing.WSAddressing {
+	s := c.snapshot()
+	if s.wsAddressing == nil {
+		return nil
+	}
+	wsa := *s.wsAddressing
+	return &wsa
+}
+
+// URL returns the endpoint of calls, the first of SetEndpoints when there
+// are several
+func (c *Client) URL() string {
+	return c.snapshot().url
+}
+
+// HTTPClient returns the HTTP client making the calls
+func (c *Client) HTTPClient() *http.Client {
+	return c.snapshot().httpClient
+}
+
+// SOAPVersion returns the SOAP version of requests, "1.1" or "1.2"
+func (c *Client) SOAPVersion() string {
+	return c.snapshot().soapVersion
+}
+
+// Compression returns the content coding of requests, empty when they
+// aren't compressed
+func (c *Client) Compression() string {
+	return c.snapshot().compression
+}
+
+// AddSOAPHeader adds a header block, such as a session token or a locale,
+// to the SOAP Header of every request. v is marshaled with encoding/xml:
+// give it an XMLName field to set the element name and namespace.
+func (c *Client) AddSOAPHeader(v interface{}) {
+	c.update(func(s *settings) {
+		s.soapHeaders = append(s.soapHeaders[:len(s.soapHeaders):len(s.soapHeaders)], v)
+	})
+}
+
+// soapHeaders are the header blocks added with AddSOAPHeader
+type soapHeaders []interface{}
+
+// MarshalXML implements xml.Marshaler, encoding each block as an element
+// named after its XMLName or type
+func (h soapHeaders) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	for _, v := range h {
+		if err := e.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableSessions keeps the session of stateful services: the cookies they
+// set, and the values of the named response headers carrying a session ID,
+// are sent back on the next requests. Clients sharing a session with
+// concurrent calls get the cookies and headers of the last response.
+func (c *Client) EnableSessions(headers ...string) {
+	c.update(func(s *settings) { s.session = newSession(headers) })
+}
+
+// ResetSession forgets the cookies and session headers kept since
+// EnableSessions, to start a new session. Calls under way keep the session
+// they started with.
+func (c *Client) ResetSession() {
+	c.update(func(s *settings) {
+		if s.session != nil {
+			s.session = newSession(s.session.names)
+		}
+	})
+}
+
+// SessionHeader returns the value of a session header kept since
+// EnableSessions, empty when the service hasn't sent it
+func (c *Client) SessionHeader(name string) string {
+	session := c.snapshot().session
+	if session == nil {
+		return ""
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.headers.Get(name)
+}
+
+// session holds the cookies and session headers of a client
+type session struct {
+	jar     *cookiejar.Jar
+	names   []string
+	mu      sync.Mutex
+	headers http.Header
+}
+
+func newSession(names []string) *session {
+	// cookiejar.New fails only for invalid options
+	jar, _ := cookiejar.New(nil)
+	return &session{jar: jar, names: names, headers: http.Header{}}
+}
+
+// apply adds the cookies and headers of the session to a request
+func (s *session) apply(req *http.Request) {
+	if s == nil {
+		return
+	}
+	for _, cookie := range s.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, values := range s.headers {
+		req.Header[name] = values
+	}
+}
+
+// capture keeps the cookies and session headers of a response
+func (s *session) capture(req *http.Request, resp *http.Response) {
+	if s == nil {
+		return
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		s.jar.SetCookies(req.URL, cookies)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range s.names {
+		if value := resp.Header.Get(name); value != "" {
+			s.headers.Set(name, value)
+		}
+	}
+}
+
+// DefaultCacheTTL is how long EnableCache keeps responses when the TTL
+// of CacheOptions is zero
+const DefaultCacheTTL = time.Minute
+
+// DefaultCacheEntries is the number of responses EnableCache keeps when
+// the MaxEntries of CacheOptions is zero
+const DefaultCacheEntries = 1000
+
+// CacheOptions configure the response cache of EnableCache
+type CacheOptions struct {
+	// TTL is how long a response is served from the cache
+	TTL time.Duration
+	// MaxEntries bounds the responses kept; the ones expiring first are
+	// dropped to make room
+	MaxEntries int
+	// Key returns the key the response of a call is cached under, and
+	// false for calls whose responses aren't cached. Calls with the same
+	// key share a response, so keys must tell apart everything the
+	// response depends on, such as a tenant carried by ctx. Nil is
+	// DefaultCacheKey.
+	Key func(ctx context.Context, soapAction string, request interface{}) (string, bool)
+}
+
+// DefaultCacheKey caches the responses of idempotent operations, those
+// whose names start with Get, List, Find, Search or another read verb,
+// under their SOAP action and request. Custom keys may call it and add to
+// the key, or cache other operations.
+func DefaultCacheKey(ctx context.Context, soapAction string, request interface{}) (string, bool) {
+	if _, ok := request.(interface{ idempotent() }); !ok {
+		return "", false
+	}
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return "", false
+	}
+	return soapAction + "\n" + string(data), true
+}
+
+// EnableCache serves the responses of calls from a cache for opts.TTL,
+// sparing the service repeated reads in pure-Go integrations. Only
+// successful responses are cached, and not those larger than the stream
+// threshold: chunked responses, of unknown length, are cached once read if
+// they turn out small enough. Middleware runs for cached responses
+// too. Enabling the cache again starts an empty one.
+func (c *Client) EnableCache(opts CacheOptions) {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCacheTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultCacheEntries
+	}
+	if opts.Key == nil {
+		opts.Key = DefaultCacheKey
+	}
+	cache := &responseCache{opts: opts, entries: make(map[string]cacheEntry)}
+	c.update(func(s *settings) { s.cache = cache })
+}
+
+// ClearCache drops the responses cached since EnableCache, after the
+// service changed the data they hold
+func (c *Client) ClearCache() {
+	if cache := c.snapshot().cache; cache != nil {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		cache.entries = make(map[string]cacheEntry)
+	}
+}
+
+// responseCache keeps the response envelopes of calls by key
+type responseCache struct {
+	opts    CacheOptions
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a cached response envelope and the time it expires
+type cacheEntry struct {
+	envelope []byte
+	expires  time.Time
+}
+
+// key returns the key of a call, and false when its response isn't cached
+// or the cache is off
+func (rc *responseCache) key(ctx context.Context, soapAction string, request interface{}) (string, bool) {
+	if rc == nil {
+		return "", false
+	}
+	return rc.opts.Key(ctx, soapAction, request)
+}
+
+// get returns the envelope cached under key, unless it expired
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.envelope, true
+}
+
+// put caches an envelope under key, dropping the expired entries, or the
+// one expiring first, when the cache is full
+func (rc *responseCache) put(key string, envelope []byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	now := time.Now()
+	if _, ok := rc.entries[key]; !ok && len(rc.entries) >= rc.opts.MaxEntries {
+		var first string
+		for k, entry := range rc.entries {
+			if !now.Before(entry.expires) {
+				delete(rc.entries, k)
+			} else if first == "" || entry.expires.Before(rc.entries[first].expires) {
+				first = k
+			}
+		}
+		if len(rc.entries) >= rc.opts.MaxEntries {
+			delete(rc.entries, first)
+		}
+	}
+	rc.entries[key] = cacheEntry{envelope: envelope, expires: now.Add(rc.opts.TTL)}
+}
+
+// Balancing chooses the endpoint of each call among the ones set with
+// SetEndpoints
+type Balancing int
+
+const (
+	// Failover calls the first healthy endpoint, in the order they were set
+	Failover Balancing = iota
+	// RoundRobin spreads calls over the healthy endpoints in turn
+	RoundRobin
+)
+
+// SetEndpoints spreads calls over several endpoints of the service, such
+// as the addresses of its ports, as WithBalancing says. An endpoint
+// failing with a connection error or a 502, 503 or 504 response is marked
+// down and passed over for the cooldown of WithEndpointCooldown, and the
+// call is retried on the next one; when all are down they are still tried,
+// the soonest back first. URL is set to the first endpoint; fewer than two
+// endpoints calls URL alone again.
+func (c *Client) SetEndpoints(urls []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints = nil
+	if len(urls) > 0 {
+		c.url = urls[0]
+	}
+	if len(urls) > 1 {
+		c.endpoints = &endpoints{
+			urls: append([]string(nil), urls...),
+			down: make([]time.Time, len(urls)),
+		}
+	}
+}
+
+// HealthyEndpoints returns the endpoints set with SetEndpoints that aren't
+// marked down, or URL when there are none
+func (c *Client) HealthyEndpoints() []string {
+	s := c.snapshot()
+	if s.endpoints == nil {
+		return []string{s.url}
+	}
+	return s.endpoints.healthy(time.Now())
+}
+
+// endpoints are the endpoints of a client and the time each is down until
+type endpoints struct {
+	urls []string
+	mu   sync.Mutex
+	down []time.Time
+	next int // Index of the next healthy endpoint of RoundRobin
+}
+
+// healthy returns the endpoints that aren't down at now
+func (e *endpoints) healthy(now time.Time) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var urls []string
+	for i, url := range e.urls {
+		if !now.Before(e.down[i]) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// order returns the indexes of the endpoints in the order a call tries
+// them: the healthy ones as balancing says, then the ones down
+func (e *endpoints) order(balancing Balancing, now time.Time) []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var healthy, down []int
+	for i := range e.urls {
+		if now.Before(e.down[i]) {
+			down = append(down, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	if balancing == RoundRobin && len(healthy) > 0 {
+		start := e.next %% len(healthy)
+		e.next = start + 1
+		healthy = append(healthy[start:], healthy[:start]...)
+	}
+	for i := 1; i < len(down); i++ {
+		for j := i; j > 0 && e.down[down[j]].Before(e.down[down[j-1]]); j-- {
+			down[j], down[j-1] = down[j-1], down[j]
+		}
+	}
+	return append(healthy, down...)
+}
+
+// mark records whether a call to endpoint i failed, marking it down until
+// the end of cooldown, or up
+func (e *endpoints) mark(i int, failed bool, cooldown time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if failed {
+		e.down[i] = time.Now().Add(cooldown)
+	} else {
+		e.down[i] = time.Time{}
+	}
+}
+
+// unavailable reports whether a response status means the endpoint can't
+// serve calls, so they fail over to the next one
+func unavailable(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// transportError is a failure to get a response from an endpoint
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("failed to execute request: %%v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
 }
 
 // Call makes a SOAP call
 func (c *Client) Call(soapAction string, request, response interface{}) error {
+	return c.CallContext(context.Background(), soapAction, request, response)
+}
+
+// Use appends middleware to the call chain. Middleware added first runs
+// outermost.
+func (c *Client) Use(middleware ...Middleware) {
+	c.update(func(s *settings) {
+		s.middleware = append(s.middleware[:len(s.middleware):len(s.middleware)], middleware...)
+	})
+}
+
+// CallContext makes a SOAP call through the middleware chain that is
+// canceled when ctx is done. The call uses the settings of the client when
+// it starts: setters called meanwhile apply to the next calls.
+func (c *Client) CallContext(ctx context.Context, soapAction string, request, response interface{}) error {
+	s := c.snapshot()
+	call := CallFunc(func(ctx context.Context, soapAction string, request, response interface{}) error {
+		return c.call(ctx, s, soapAction, request, response)
+	})
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		call = s.middleware[i](call)
+	}
+	return call(ctx, soapAction, request, response)
+}
+
+// call makes the SOAP HTTP request
+func (c *Client) call(ctx context.Context, s *snapshot, soapAction string, request, response interface{}) error {
+	key, cached := s.cache.key(ctx, soapAction, request)
+	if cached {
+		if respData, ok := s.cache.get(key); ok {
+			s.dump("< cached", respData)
+			return decodeResponse(&http.Response{StatusCode: http.StatusOK}, respData, response)
+		}
+	}
+
+	resp, err := c.send(ctx, s, soapAction, request)
+	if err != nil {
+		return err
+	}
+	defer drain(resp.Body)
+
+	// Decode large responses as they are read rather than buffering them.
+	// Responses of unknown length are kept as they are read too, to cache
+	// them when they turn out no larger than the stream threshold.
+	if c.streams(s, resp) {
+		var body io.Reader = resp.Body
+		var kept *cappedBuffer
+		if cached && resp.StatusCode == http.StatusOK && resp.ContentLength < 0 {
+			kept = &cappedBuffer{limit: c.streamThreshold}
+			body = io.TeeReader(resp.Body, kept)
+		}
+		responseEnvelope := ResponseEnvelope{Body: ResponseBody{Content: response}}
+		if err := xml.NewDecoder(body).Decode(&responseEnvelope); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %%w", err)
+		}
+		if responseEnvelope.Body.Fault != nil {
+			return responseEnvelope.Body.Fault
+		}
+		if kept != nil && !kept.overflow {
+			s.cache.put(key, kept.Bytes())
+		}
+		return nil
+	}
+
+	// Read response
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %%w", err)
+	}
+	s.dump("< "+resp.Status, respData)
+	if err := decodeResponse(resp, respData, response); err != nil {
+		return err
+	}
+	if cached && resp.StatusCode == http.StatusOK {
+		s.cache.put(key, respData)
+	}
+	return nil
+}
+
+// cappedBuffer keeps what is written to it up to limit bytes, and drops
+// it all once more is written
+type cappedBuffer struct {
+	bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+// Write implements io.Writer, never failing so that the reads it copies
+// go on
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if !b.overflow && int64(b.Len()+len(p)) > b.limit {
+		b.overflow = true
+		b.Reset()
+	}
+	if !b.overflow {
+		b.Buffer.Write(p)
+	}
+	return len(p), nil
+}
+
+// maxDrain bounds what drain reads of a response body left unread
+const maxDrain = 64 << 10
+
+// drain reads what is left of a response body, up to maxDrain, and closes
+// it, so that its connection goes back to the transport's pool to be
+// reused rather than being closed
+func drain(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrain))
+	body.Close()
+}
+
+// CallStream makes a SOAP call and returns the content of the response
+// Body as it is read, for bulk-data operations whose responses are too
+// large to decode at once. The caller must close the reader. A fault is
+// returned as a *SOAPFault error. Namespace prefixes declared on the
+// Envelope or Body aren't declared in the content. The middleware, which
+// wraps calls decoding a response, doesn't run.
+func (c *Client) CallStream(ctx context.Context, soapAction string, request interface{}) (io.ReadCloser, error) {
+	s := c.snapshot()
+	resp, err := c.send(ctx, s, soapAction, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		defer resp.Body.Close()
+		respData, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %%w", err)
+		}
+		s.dump("< "+resp.Status, respData)
+		return nil, decodeResponse(resp, respData, nil)
+	}
+	s.dump("< "+resp.Status+" (streamed)", nil)
+
+	body, err := newBodyReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return body, nil
+}
+
+// streams reports whether a response is decoded as it is read: successful
+// responses larger than the stream threshold or of unknown length, unless
+// debugging
+func (c *Client) streams(s *snapshot, resp *http.Response) bool {
+	if c.streamThreshold < 0 || s.debug != nil {
+		return false
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return false
+	}
+	return resp.ContentLength < 0 || resp.ContentLength > c.streamThreshold
+}
+
+// send sends a SOAP request and returns the HTTP response, failing over
+// between the endpoints set with SetEndpoints
+func (c *Client) send(ctx context.Context, s *snapshot, soapAction string, request interface{}) (*http.Response, error) {
+	// Reject requests that violate xs:choice constraints before sending
+	if v, ok := request.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid request: %%w", err)
+		}
+	}
+
+	pool := s.endpoints
+	if pool == nil {
+		return c.sendTo(ctx, s, s.url, soapAction, request)
+	}
+	order := pool.order(c.balancing, time.Now())
+	for n, i := range order {
+		resp, err := c.sendTo(ctx, s, pool.urls[i], soapAction, request)
+		var terr *transportError
+		if err != nil && (!errors.As(err, &terr) || ctx.Err() != nil) {
+			return nil, err
+		}
+		failed := err != nil || unavailable(resp.StatusCode)
+		pool.mark(i, failed, c.endpointCooldown)
+		if !failed || n == len(order)-1 {
+			return resp, err
+		}
+		if resp != nil {
+			drain(resp.Body)
+		}
+	}
+	return nil, errors.New("no endpoints")
+}
+
+// sendTo sends a SOAP request to an endpoint and returns the HTTP response
+func (c *Client) sendTo(ctx context.Context, s *snapshot, url, soapAction string, request interface{}) (*http.Response, error) {
 	// Build SOAP envelope based on version
 	var envelope interface{}
-	var contentType string
-
-	if c.SOAPVersion == "1.2" {
-		envelope = c.buildSOAP12Envelope(request)
-		contentType = "application/soap+xml; charset=utf-8"
+	if s.soapVersion == "1.2" {
+		envelope = s.buildSOAP12Envelope(url, soapAction, request)
 	} else {
-		envelope = c.buildSOAP11Envelope(request)
-		contentType = "text/xml; charset=utf-8"
+		envelope = s.buildSOAP11Envelope(url, soapAction, request)
 	}
 
-	// Marshal to XML
-	xmlData, err := xml.MarshalIndent(envelope, "", "  ")
+	// Marshal to XML; indenting would add whitespace to mixed content
+	xmlData, err := xml.Marshal(envelope)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %%w", err)
+		return nil, fmt.Errorf("failed to marshal request: %%w", err)
 	}
 
 	// Add XML header
 	requestBody := []byte(xml.Header + string(xmlData))
 
+	// Sign the envelope last, so the signature covers it as sent
+	if s.signer != nil {
+		if requestBody, err = s.signer.Sign(requestBody); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %%w", err)
+		}
+	}
+
+	title := "> POST " + url
+	if soapAction != "" {
+		title += " SOAPAction: " + soapAction
+	}
+	s.dump(title, requestBody)
+
+	// Compress the envelope as sent
+	if s.compression != "" {
+		if requestBody, err = compression.Compress(s.compression, requestBody); err != nil {
+			return nil, fmt.Errorf("failed to compress request: %%w", err)
+		}
+	}
+
 	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", c.URL, bytes.NewReader(requestBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(requestBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %%w", err)
+		return nil, fmt.Errorf("failed to create request: %%w", err)
+	}
+	if s.compression != "" {
+		httpReq.Header.Set("Content-Encoding", s.compression)
+		httpReq.Header.Set("Accept-Encoding", compression.AcceptEncoding)
 	}
 
-	// Set headers
-	httpReq.Header.Set("Content-Type", contentType)
-	if c.SOAPVersion == "1.1" {
+	// Set headers; SOAP 1.2 carries the action in the Content-Type
+	httpReq.Header.Set("Content-Type", soapContentType(s.soapVersion, soapAction))
+	if s.soapVersion != "1.2" {
 		httpReq.Header.Set("SOAPAction", fmt.Sprintf("\"%%s\"", soapAction))
 	}
-	for key, value := range c.Headers {
+	for key, value := range s.headers {
 		httpReq.Header.Set(key, value)
 	}
+	if headers, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for key, values := range headers {
+			httpReq.Header[key] = values
+		}
+	}
+	var bearer string
+	if s.oauth2 != nil {
+		if bearer, err = s.oauth2.Token(ctx); err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+bearer)
+	}
 
 	// Execute request
-	resp, err := c.HTTPClient.Do(httpReq)
+	session := s.session
+	session.apply(httpReq)
+	resp, err := s.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %%w", err)
+		return nil, &transportError{err: err}
 	}
-	defer resp.Body.Close()
+	session.capture(httpReq, resp)
 
-	// Read response
-	respData, err := io.ReadAll(resp.Body)
+	// A rejected token may have been revoked; request a new one next time
+	if s.oauth2 != nil && resp.StatusCode == http.StatusUnauthorized {
+		s.oauth2.Invalidate(bearer)
+	}
+
+	if err := compression.Decompress(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if err := charset.Decode(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// A login or error page answered instead of the service can't be decoded
+	contentType := resp.Header.Get("Content-Type")
+	if (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted) && !xmlContentType(contentType) {
+		drain(resp.Body)
+		return nil, fmt.Errorf("unexpected response Content-Type %%q", contentType)
+	}
+	return resp, nil
+}
+
+// soapContentType returns the Content-Type of SOAP requests of a version.
+// SOAP 1.2 has no SOAPAction header: the action is the action parameter of
+// its Content-Type.
+func soapContentType(version, action string) string {
+	if version != "1.2" {
+		return "text/xml; charset=utf-8"
+	}
+	if action == "" {
+		return "application/soap+xml; charset=utf-8"
+	}
+	return "application/soap+xml; charset=utf-8; action=\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(action) + "\""
+}
+
+// xmlContentType reports whether a response Content-Type may carry a SOAP
+// envelope: text/xml, application/soap+xml or another XML type, with any
+// parameters. Missing and text/plain types, which some legacy services
+// send, are accepted too.
+func xmlContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %%w", err)
+		// Tolerate malformed parameters such as unquoted actions
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	switch mediaType {
+	case "text/xml", "application/xml", "application/soap+xml", "text/plain":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+xml")
+}
+
+// decodeResponse decodes a buffered SOAP 1.1 or 1.2 response into
+// response, returning faults as *SOAPFault
+func decodeResponse(resp *http.Response, respData []byte, response interface{}) error {
+	responseEnvelope := ResponseEnvelope{Body: ResponseBody{Content: response}}
+	parseErr := xml.Unmarshal(respData, &responseEnvelope)
+	if parseErr == nil && responseEnvelope.Body.Fault != nil {
+		return responseEnvelope.Body.Fault
 	}
 
 	// Check for HTTP errors
@@ -130,24 +1382,15 @@ func (c *Client) Call(soapAction string, request, response interface{}) error {
 		return fmt.Errorf("SOAP request failed with status %%d: %%s", resp.StatusCode, string(respData))
 	}
 
-	// Parse SOAP response
-	var responseEnvelope SOAPEnvelope
-	responseEnvelope.Body.Content = response
-
-	if err := xml.Unmarshal(respData, &responseEnvelope); err != nil {
-		// Try SOAP 1.2 format
-		var responseEnvelope12 SOAP12Envelope
-		responseEnvelope12.Body.Content = response
-		if err := xml.Unmarshal(respData, &responseEnvelope12); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %%w", err)
-		}
+	if parseErr != nil {
+		return fmt.Errorf("failed to unmarshal response: %%w", parseErr)
 	}
 
 	return nil
 }
 
 // buildSOAP11Envelope builds a SOAP 1.1 envelope
-func (c *Client) buildSOAP11Envelope(request interface{}) *SOAPEnvelope {
+func (s *settings) buildSOAP11Envelope(url, soapAction string, request interface{}) *SOAPEnvelope {
 	envelope := &SOAPEnvelope{
 		EnvNamespace: "http://schemas.xmlsoap.org/soap/envelope/",
 		Body: SOAPBody{
@@ -155,10 +1398,12 @@ func (c *Client) buildSOAP11Envelope(request interface{}) *SOAPEnvelope {
 		},
 	}
 
-	// Add WS-Security header if configured
-	if c.Security != nil {
+	// Add WS-Security, WS-Addressing and custom headers if configured
+	if s.wsSecurity != nil || s.token != nil || s.wsAddressing != nil || len(s.soapHeaders) > 0 {
 		envelope.Header = &SOAPHeader{
-			Security: security.NewSecurityHeader(c.Security),
+			Security: s.securityHeader(),
+			Header:   addressing.NewAddressingHeader(s.wsAddressing, url, soapAction),
+			Blocks:   s.soapHeaders,
 		}
 	}
 
@@ -166,7 +1411,7 @@ func (c *Client) buildSOAP11Envelope(request interface{}) *SOAPEnvelope {
 }
 
 // buildSOAP12Envelope builds a SOAP 1.2 envelope
-func (c *Client) buildSOAP12Envelope(request interface{}) *SOAP12Envelope {
+func (s *settings) buildSOAP12Envelope(url, soapAction string, request interface{}) *SOAP12Envelope {
 	envelope := &SOAP12Envelope{
 		EnvNamespace: "http://www.w3.org/2003/05/soap-envelope",
 		Body: SOAP12Body{
@@ -174,10 +1419,12 @@ func (c *Client) buildSOAP12Envelope(request interface{}) *SOAP12Envelope {
 		},
 	}
 
-	// Add WS-Security header if configured
-	if c.Security != nil {
+	// Add WS-Security, WS-Addressing and custom headers if configured
+	if s.wsSecurity != nil || s.token != nil || s.wsAddressing != nil || len(s.soapHeaders) > 0 {
 		envelope.Header = &SOAP12Header{
-			Security: security.NewSecurityHeader(c.Security),
+			Security: s.securityHeader(),
+			Header:   addressing.NewAddressingHeader(s.wsAddressing, url, soapAction),
+			Blocks:   s.soapHeaders,
 		}
 	}
 
@@ -195,6 +1442,8 @@ type SOAPEnvelope struct {
 type SOAPHeader struct {
 	XMLName  xml.Name                ` + "`xml:\"soap:Header\"`" + `
 	Security *security.SecurityHeader ` + "`xml:\",omitempty\"`" + `
+	*addressing.Header
+	Blocks soapHeaders ` + "`xml:\",omitempty\"`" + `
 }
 
 type SOAPBody struct {
@@ -213,6 +1462,8 @@ type SOAP12Envelope struct {
 type SOAP12Header struct {
 	XMLName  xml.Name                ` + "`xml:\"env:Header\"`" + `
 	Security *security.SecurityHeader ` + "`xml:\",omitempty\"`" + `
+	*addressing.Header
+	Blocks soapHeaders ` + "`xml:\",omitempty\"`" + `
 }
 
 type SOAP12Body struct {
@@ -220,15 +1471,238 @@ type SOAP12Body struct {
 	Content interface{} ` + "`xml:\",innerxml\"`" + `
 }
 
-// SOAPFault represents a SOAP fault
+// ResponseEnvelope decodes SOAP 1.1 and 1.2 response envelopes
+type ResponseEnvelope struct {
+	Body ResponseBody ` + "`xml:\"Body\"`" + `
+}
+
+// ResponseBody decodes the first element of a SOAP body into Content, or
+// into Fault when the body carries a fault
+type ResponseBody struct {
+	Fault   *SOAPFault
+	Content interface{}
+}
+
+// UnmarshalXML implements xml.Unmarshaler
+func (b *ResponseBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "Fault":
+				b.Fault = &SOAPFault{}
+				if err := d.DecodeElement(b.Fault, &t); err != nil {
+					return err
+				}
+				b.Fault.normalize()
+			case b.Content != nil:
+				if err := d.DecodeElement(b.Content, &t); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// SOAPFault represents a SOAP fault. SOAP 1.2 faults are mapped onto the
+// SOAP 1.1 fields.
 type SOAPFault struct {
-	XMLName xml.Name ` + "`xml:\"Fault\"`" + `
-	Code    string   ` + "`xml:\"faultcode\"`" + `
-	String  string   ` + "`xml:\"faultstring\"`" + `
-	Actor   string   ` + "`xml:\"faultactor\"`" + `
-	Detail  string   ` + "`xml:\"detail\"`" + `
+	XMLName xml.Name    ` + "`xml:\"Fault\"`" + `
+	Code    string      ` + "`xml:\"faultcode\"`" + `
+	String  string      ` + "`xml:\"faultstring\"`" + `
+	Actor   string      ` + "`xml:\"faultactor\"`" + `
+	Detail  FaultDetail ` + "`xml:\"detail\"`" + `
+
+	// SOAP 1.2 fields
+	Value    string      ` + "`xml:\"Code>Value\"`" + `
+	Reason   string      ` + "`xml:\"Reason>Text\"`" + `
+	Detail12 FaultDetail ` + "`xml:\"Detail\"`" + `
+}
+
+// FaultDetail holds the raw content of a fault detail
+type FaultDetail struct {
+	Content []byte ` + "`xml:\",innerxml\"`" + `
+}
+
+// Error implements the error interface
+func (f *SOAPFault) Error() string {
+	return fmt.Sprintf("SOAP fault %%s: %%s", f.Code, f.String)
+}
+
+// DecodeDetail unmarshals the first element of the fault detail into v
+func (f *SOAPFault) DecodeDetail(v interface{}) error {
+	return xml.Unmarshal(f.Detail.Content, v)
+}
+
+// normalize maps SOAP 1.2 fault fields onto the SOAP 1.1 ones
+func (f *SOAPFault) normalize() {
+	if f.Code == "" {
+		f.Code = f.Value
+		f.String = f.Reason
+		f.Detail = f.Detail12
+	}
 }
-`, g.packageName, endpoint)
 
-	return os.WriteFile(filepath.Join(g.outputDir, "client.go"), []byte(content), 0644)
+// bodyReader returns the content of a SOAP Body as the response is read.
+// The decoder finds where the content ends; the bytes it read are kept
+// until they are returned.
+type bodyReader struct {
+	body    io.ReadCloser
+	in      *readBuffer
+	decoder *xml.Decoder
+	pos     int64 // Offset of the next byte to return
+	end     int64 // Offset of the end tag of the Body, -1 until read
+	depth   int   // Depth of the decoder in the content
+	err     error // Error once the decoder stopped
+}
+
+// newBodyReader reads a response up to the content of its Body, returning
+// a fault as a *SOAPFault error
+func newBodyReader(body io.ReadCloser) (*bodyReader, error) {
+	in := &readBuffer{r: body}
+	r := &bodyReader{body: body, in: in, decoder: xml.NewDecoder(in), end: -1}
+
+	// Find the Body of the envelope
+	for depth := 0; depth != 2; {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %%w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && t.Name.Local != "Body" {
+				if err := r.decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("failed to read response: %%w", err)
+				}
+				depth--
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	r.pos = r.decoder.InputOffset()
+	in.discard(r.pos)
+
+	// Return a fault rather than its content
+	for {
+		offset := r.decoder.InputOffset()
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %%w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Fault" {
+				fault := &SOAPFault{}
+				if err := r.decoder.DecodeElement(fault, &t); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal response: %%w", err)
+				}
+				fault.normalize()
+				return nil, fault
+			}
+			r.depth = 1
+			return r, nil
+		case xml.EndElement:
+			r.end = offset
+			r.err = io.EOF
+			return r, nil
+		}
+	}
+}
+
+// Read implements io.Reader
+func (r *bodyReader) Read(p []byte) (int, error) {
+	for r.err == nil && r.pos >= r.decoder.InputOffset() {
+		r.advance()
+	}
+	limit := r.decoder.InputOffset()
+	if r.end >= 0 {
+		limit = r.end
+	}
+	n := copy(p, r.in.bytes(r.pos, limit))
+	r.pos += int64(n)
+	r.in.discard(r.pos)
+	if n == 0 {
+		return 0, r.err
+	}
+	return n, nil
+}
+
+// advance decodes the next token of the content
+func (r *bodyReader) advance() {
+	offset := r.decoder.InputOffset()
+	token, err := r.decoder.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		r.err = err
+		return
+	}
+	switch token.(type) {
+	case xml.StartElement:
+		r.depth++
+	case xml.EndElement:
+		if r.depth == 0 {
+			r.end = offset
+			r.err = io.EOF
+		}
+		r.depth--
+	}
+}
+
+// Close implements io.Closer
+func (r *bodyReader) Close() error {
+	return r.body.Close()
+}
+
+// readBuffer keeps the bytes read from r, from offset base on, until they
+// are discarded
+type readBuffer struct {
+	r    io.Reader
+	buf  []byte
+	base int64
+}
+
+// Read implements io.Reader
+func (r *readBuffer) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	return n, err
+}
+
+// bytes returns the bytes kept from offset from to offset to
+func (r *readBuffer) bytes(from, to int64) []byte {
+	return r.buf[from-r.base : to-r.base]
+}
+
+// discard drops the bytes before offset to
+func (r *readBuffer) discard(to int64) {
+	r.buf = r.buf[to-r.base:]
+	r.base = to
+}
+`, g.packageName, endpoints, g.findSOAPVersion(def), endpoint)
+
+	return g.writeGoFile("client.go", content)
+}
+
+// goStrings returns the Go literal of a string slice
+func goStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
 }
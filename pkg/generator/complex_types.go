@@ -2,15 +2,47 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
 )
 
 // ComplexTypeGenerator handles complex type generation
 type ComplexTypeGenerator struct {
 	targetNamespace string
 	generatedTypes  map[string]bool
+	imports         map[string]bool
+	usesAny         bool
+	timeType        string
+	timeTypes       map[string]bool
+	decimalType     string
+	usesDecimal     bool
+	jsonNaming      string
+	qualified       bool
+	renames         []naming.Rename
+	// usesJSONProperty is set when generated JSON methods need the
+	// withJSONProperty helper
+	usesJSONProperty bool
+	// cyclic holds the fields, keyed by type and element name, that close
+	// a cycle of struct values and must be pointers
+	cyclic map[string]bool
+	// types holds the complex types by name; derived the types derived
+	// from each of them, directly or not, in declaration order
+	types   map[string]models.Type
+	derived map[string][]models.Type
+	bases   []string        // Base types in declaration order
+	holders map[string]bool // Base types whose holder fields refer to
+	// groups holds the substitution groups and wrappers the Go names of
+	// the wrappers of their members, by element name
+	groups   []*substitutionGroup
+	wrappers map[string]string
+	// mappedTypes and mappedElements hold the Go types of the type
+	// mapping, by local name; mappedImports the packages of those used
+	mappedTypes    map[string]GoType
+	mappedElements map[string]GoType
+	mappedImports  map[string]bool
 }
 
 // NewComplexTypeGenerator creates a new complex type generator
@@ -18,48 +50,547 @@ func NewComplexTypeGenerator(targetNS string) *ComplexTypeGenerator {
 	return &ComplexTypeGenerator{
 		targetNamespace: targetNS,
 		generatedTypes:  make(map[string]bool),
+		imports:         make(map[string]bool),
+		timeType:        TimeTypeString,
+		timeTypes:       make(map[string]bool),
+		decimalType:     DecimalTypeFloat,
+		jsonNaming:      JSONNamingXML,
+		cyclic:          make(map[string]bool),
+		types:           make(map[string]models.Type),
+		derived:         make(map[string][]models.Type),
+		holders:         make(map[string]bool),
+		mappedTypes:     make(map[string]GoType),
+		mappedElements:  make(map[string]GoType),
+		mappedImports:   make(map[string]bool),
 	}
 }
 
-// GenerateComplexType generates Go code for a complex type
+// SetTypes records the complex types of the schema. A struct can't contain
+// itself, so the fields through which a type contains itself by value, such
+// as a Department with a parent Department, become pointers. Elements of a
+// type that others derive from hold any of them, see GenerateHolders.
+func (ctg *ComplexTypeGenerator) SetTypes(types []models.Type) {
+	byName := ctg.types
+	for _, t := range types {
+		if _, ok := byName[t.Name]; !ok {
+			byName[t.Name] = t
+		}
+	}
+	for _, t := range types {
+		seen := map[string]bool{t.Name: true}
+		for base, ok := byName[models.LocalName(t.Base)]; ok && !seen[base.Name]; base, ok = byName[models.LocalName(base.Base)] {
+			seen[base.Name] = true
+			if len(ctg.derived[base.Name]) == 0 {
+				ctg.bases = append(ctg.bases, base.Name)
+			}
+			ctg.derived[base.Name] = append(ctg.derived[base.Name], t)
+		}
+	}
+	ctg.nameGroups()
+
+	// Fields back to a type still being visited close a cycle
+	const visiting, visited = 1, 2
+	state := make(map[string]int)
+	for name := range ctg.mappedTypes {
+		// Mapped types are not generated
+		state[name] = visited
+	}
+	var visit func(t models.Type)
+	visit = func(t models.Type) {
+		state[t.Name] = visiting
+		for _, elem := range t.Elements {
+			target, ok := byName[models.LocalName(elem.Type)]
+			if !ok || ctg.getFieldType(t, elem) != ctg.goType(elem.Type) {
+				continue
+			}
+			switch state[target.Name] {
+			case 0:
+				visit(target)
+			case visiting:
+				ctg.cyclic[t.Name+" "+elem.Name] = true
+			}
+		}
+		state[t.Name] = visited
+	}
+	for _, t := range types {
+		if state[t.Name] == 0 {
+			visit(t)
+		}
+	}
+}
+
+// orderTypes returns types with every type after the types of its fields,
+// keeping the declaration order otherwise. Types in a cycle follow the
+// order in which they are reached.
+func orderTypes(types []models.Type) []models.Type {
+	byName := make(map[string]int, len(types))
+	for i := len(types) - 1; i >= 0; i-- {
+		byName[types[i].Name] = i
+	}
+
+	ordered := make([]models.Type, 0, len(types))
+	seen := make([]bool, len(types))
+	var visit func(i int)
+	visit = func(i int) {
+		seen[i] = true
+		for _, elem := range types[i].Elements {
+			if j, ok := byName[models.LocalName(elem.Type)]; ok && !seen[j] {
+				visit(j)
+			}
+		}
+		ordered = append(ordered, types[i])
+	}
+	for i := range types {
+		if !seen[i] {
+			visit(i)
+		}
+	}
+	return ordered
+}
+
+// GenerateComplexType generates Go code for a complex type. The struct has no
+// XMLName since it is used as a field type and takes the field's element name.
 func (ctg *ComplexTypeGenerator) GenerateComplexType(t models.Type) string {
-	if ctg.generatedTypes[t.Name] {
+	typeName := toPascalCase(t.Name)
+	if _, ok := ctg.mappedTypes[t.Name]; ok || ctg.generatedTypes[typeName] {
 		return ""
 	}
 
 	var b strings.Builder
-	typeName := toPascalCase(t.Name)
 
-	b.WriteString(fmt.Sprintf("// %s represents a complex type from WSDL\n", typeName))
+	if base, ok := ctg.types[models.LocalName(t.Base)]; ok {
+		b.WriteString(fmt.Sprintf("// %s represents a complex type from WSDL, derived from %s by %s\n", typeName, toPascalCase(base.Name), t.Derivation))
+	} else {
+		b.WriteString(fmt.Sprintf("// %s represents a complex type from WSDL\n", typeName))
+	}
 	b.WriteString(fmt.Sprintf("type %s struct {\n", typeName))
-	b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s %s\"`\n", ctg.targetNamespace, t.Name))
+	b.WriteString(ctg.GenerateFields(typeName, t))
+	b.WriteString("}\n\n")
+	b.WriteString(ctg.GenerateValidate(typeName, t))
+	b.WriteString(ctg.GenerateAnyUnmarshal(typeName, t))
+
+	ctg.generatedTypes[typeName] = true
+	return b.String()
+}
+
+// GenerateFields generates the struct fields for a complex type's elements
+// and attributes. Field names are unique within the struct and don't clash
+// with the reserved names of other fields and methods of the type.
+func (ctg *ComplexTypeGenerator) GenerateFields(typeName string, t models.Type, reserved ...string) string {
+	var b strings.Builder
+
+	elemNames, attrNames, namer := ctg.fieldNames(typeName, t, reserved...)
+	ctg.renames = append(ctg.renames, namer.Renames()...)
+	elemJSON, attrJSON := ctg.jsonNames(t)
 
-	// Generate fields for elements
-	for _, elem := range t.Elements {
-		fieldName := toPascalCase(elem.Name)
-		fieldType := ctg.getFieldType(elem)
+	// Character data of simple or mixed content
+	switch field := contentField(t); {
+	case t.SimpleContent != "":
+		b.WriteString(fmt.Sprintf("\t%s %s `xml:\",chardata\"%s`\n", field, ctg.goType(t.SimpleContent), jsonTag(jsonText, false)))
+	case t.Mixed:
+		b.WriteString(fmt.Sprintf("\t%s string `xml:\",chardata\"%s`\n", field, jsonTag(jsonText, true)))
+	}
+
+	// Generate fields for elements. Fields inherited from a base type are
+	// flattened into the struct and marked with the type declaring them.
+	// The members of a substitution group have names of their own, so the
+	// first field holding one takes the elements no other field matches;
+	// with xs:any taking them, GenerateAnyUnmarshal moves them to the field.
+	anyTaken := t.Any
+	for i, elem := range t.Elements {
+		fieldType := ctg.getFieldType(t, elem)
 		xmlTag := ctg.buildXMLTag(elem)
+		if ctg.substitutionGroup(elem) != nil && !anyTaken {
+			xmlTag, anyTaken = ",any", true
+		}
 
-		b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"`\n", fieldName, fieldType, xmlTag))
+		b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"%s`%s\n", elemNames[i], fieldType, xmlTag, jsonTag(elemJSON[i], elem.MinOccurs == "0"), inheritedComment(ctg.elementOrigin(t, i))))
 	}
 
 	// Generate fields for attributes
-	for _, attr := range t.Attributes {
-		fieldName := toPascalCase(attr.Name)
-		fieldType := mapXSDTypeToGo(attr.Type)
+	for i, attr := range t.Attributes {
+		fieldType := ctg.goType(attr.Type)
+
+		b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"%s`%s\n", attrNames[i], fieldType, buildAttrTag(attr), jsonTag(attrJSON[i], attr.Use != "required"), inheritedComment(ctg.attributeOrigin(t, attr))))
+	}
+
+	// Keep elements matched by xs:any instead of dropping them
+	if t.Any {
+		ctg.usesAny = true
+		b.WriteString("\tAny []AnyElement `xml:\",any\" json:\"-\"`\n")
+	}
+
+	return b.String()
+}
+
+// elementOrigin returns the name of the base type declaring the i-th element
+// of an extension, or an empty string for the type's own elements
+func (ctg *ComplexTypeGenerator) elementOrigin(t models.Type, i int) string {
+	origin := ""
+	for depth := 0; depth < len(ctg.types) && t.Derivation == models.DerivationExtension; depth++ {
+		base, ok := ctg.types[models.LocalName(t.Base)]
+		if !ok || i >= len(base.Elements) {
+			break
+		}
+		origin, t = base.Name, base
+	}
+	return origin
+}
+
+// attributeOrigin returns the name of the base type declaring an attribute
+// of a derived type, or an empty string for the type's own attributes
+func (ctg *ComplexTypeGenerator) attributeOrigin(t models.Type, attr models.Attribute) string {
+	origin := ""
+	for depth := 0; depth < len(ctg.types); depth++ {
+		base, ok := ctg.types[models.LocalName(t.Base)]
+		if !ok || !hasAttribute(base, attr) {
+			break
+		}
+		origin, t = base.Name, base
+	}
+	return origin
+}
+
+// hasAttribute reports whether t declares or inherits attr
+func hasAttribute(t models.Type, attr models.Attribute) bool {
+	for _, a := range t.Attributes {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritedComment returns the line comment of a field inherited from a
+// base type
+func inheritedComment(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	return " // From " + toPascalCase(origin)
+}
+
+// fieldNames returns the Go field names of a type's elements and
+// attributes, unique within the struct
+func (ctg *ComplexTypeGenerator) fieldNames(typeName string, t models.Type, reserved ...string) ([]string, []string, *naming.Namer) {
+	namer := naming.NewNamer(typeName+" field", naming.Pascal)
+	namer.Reserve("XMLName")
+	namer.Reserve(reserved...)
+	if t.Any {
+		namer.Reserve("Any")
+	}
+	if field := contentField(t); field != "" {
+		namer.Reserve(field)
+	}
+	if len(t.Choices) > 0 {
+		namer.Reserve("Validate")
+	}
+
+	elemNames := make([]string, len(t.Elements))
+	for i, elem := range t.Elements {
+		elemNames[i] = namer.Next(elem.Name)
+	}
+	attrNames := make([]string, len(t.Attributes))
+	for i, attr := range t.Attributes {
+		attrNames[i] = namer.Next(attr.Name)
+	}
+	return elemNames, attrNames, namer
+}
+
+// GenerateValidate generates a Validate method checking the xs:choice
+// groups of a complex type. It returns an empty string for types without
+// choices.
+func (ctg *ComplexTypeGenerator) GenerateValidate(typeName string, t models.Type) string {
+	if len(t.Choices) == 0 {
+		return ""
+	}
+	ctg.imports["errors"] = true
+
+	var b strings.Builder
+	fieldNames, _, _ := ctg.fieldNames(typeName, t)
+
+	b.WriteString("// Validate checks that the xs:choice constraints are met\n")
+	b.WriteString(fmt.Sprintf("func (v %s) Validate() error {\n", typeName))
+
+	for i, choice := range t.Choices {
+		var names []string
+		for j, elem := range t.Elements {
+			if elem.Choice == i+1 {
+				names = append(names, fieldNames[j])
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		set := "set"
+		if len(t.Choices) > 1 {
+			set = fmt.Sprintf("set%d", i+1)
+		}
+		b.WriteString(fmt.Sprintf("\t%s := 0\n", set))
+		for j, elem := range t.Elements {
+			if elem.Choice != i+1 {
+				continue
+			}
+			check := fmt.Sprintf("v.%s != nil", fieldNames[j])
+			if strings.HasPrefix(ctg.getFieldType(t, elem), "[]") {
+				check = fmt.Sprintf("len(v.%s) > 0", fieldNames[j])
+			}
+			b.WriteString(fmt.Sprintf("\tif %s {\n\t\t%s++\n\t}\n", check, set))
+		}
+
+		if choice.MinOccurs == "0" {
+			b.WriteString(fmt.Sprintf("\tif %s > 1 {\n", set))
+			b.WriteString(fmt.Sprintf("\t\treturn errors.New(%q)\n", fmt.Sprintf("%s: at most one of %s may be set", typeName, strings.Join(names, ", "))))
+		} else {
+			b.WriteString(fmt.Sprintf("\tif %s != 1 {\n", set))
+			b.WriteString(fmt.Sprintf("\t\treturn errors.New(%q)\n", fmt.Sprintf("%s: exactly one of %s must be set", typeName, strings.Join(names, ", "))))
+		}
+		b.WriteString("\t}\n")
+	}
+
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// GenerateHolders generates the holder types of elements whose type other
+// types derive from. A holder keeps the base type or any derived type, told
+// apart by xsi:type in XML. Abstract base types only appear as derived
+// types. It returns an empty string when no element has such a type.
+func (ctg *ComplexTypeGenerator) GenerateHolders() string {
+	var b strings.Builder
+
+	for _, name := range ctg.bases {
+		if !ctg.holders[name] {
+			continue
+		}
+		ctg.imports["fmt"] = true
+		ctg.imports["strings"] = true
+		base := ctg.types[name]
+		baseName := toPascalCase(base.Name)
+		holder := holderName(base.Name)
+
+		b.WriteString(fmt.Sprintf("// %s holds a %s or a type derived from it\n", holder, baseName))
+		b.WriteString(fmt.Sprintf("type %s struct {\n", holder))
+		b.WriteString("\tValue interface{}\n")
+		b.WriteString("}\n\n")
+
+		b.WriteString("// MarshalXML writes the value, with the xsi:type of derived types\n")
+		b.WriteString(fmt.Sprintf("func (h %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", holder))
+		b.WriteString("\tvar typeName, typeNS string\n")
+		b.WriteString("\tswitch h.Value.(type) {\n")
+		b.WriteString("\tcase nil:\n\t\treturn nil\n")
+		if !base.Abstract {
+			b.WriteString(fmt.Sprintf("\tcase %s, *%s:\n", baseName, baseName))
+		}
+		for _, t := range ctg.derived[name] {
+			if t.Abstract {
+				continue
+			}
+			ns := t.Namespace
+			if ns == "" {
+				ns = ctg.targetNamespace
+			}
+			b.WriteString(fmt.Sprintf("\tcase %s, *%s:\n", toPascalCase(t.Name), toPascalCase(t.Name)))
+			b.WriteString(fmt.Sprintf("\t\ttypeName, typeNS = %q, %q\n", t.Name, ns))
+		}
+		b.WriteString("\tdefault:\n")
+		b.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: unexpected value of type %%T\", h.Value)\n", holder))
+		b.WriteString("\t}\n")
+		b.WriteString("\tif typeName != \"\" {\n")
+		b.WriteString("\t\tstart.Attr = append(start.Attr,\n")
+		b.WriteString("\t\t\txml.Attr{Name: xml.Name{Local: \"xmlns:xsi\"}, Value: \"http://www.w3.org/2001/XMLSchema-instance\"},\n")
+		b.WriteString("\t\t\txml.Attr{Name: xml.Name{Local: \"xmlns:derived\"}, Value: typeNS},\n")
+		b.WriteString("\t\t\txml.Attr{Name: xml.Name{Local: \"xsi:type\"}, Value: \"derived:\" + typeName})\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn e.EncodeElement(h.Value, start)\n")
+		b.WriteString("}\n\n")
+
+		b.WriteString("// UnmarshalXML reads the type named by xsi:type, the base type otherwise\n")
+		b.WriteString(fmt.Sprintf("func (h *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", holder))
+		b.WriteString("\tvar typeName string\n")
+		b.WriteString("\tfor _, attr := range start.Attr {\n")
+		b.WriteString("\t\tif attr.Name.Space == \"http://www.w3.org/2001/XMLSchema-instance\" && attr.Name.Local == \"type\" {\n")
+		b.WriteString("\t\t\ttypeName = attr.Value[strings.Index(attr.Value, \":\")+1:]\n")
+		b.WriteString("\t\t}\n")
+		b.WriteString("\t}\n")
+		b.WriteString("\tswitch typeName {\n")
+		for _, t := range ctg.derived[name] {
+			if t.Abstract {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("\tcase %q:\n", t.Name))
+			b.WriteString(fmt.Sprintf("\t\tvar v %s\n", toPascalCase(t.Name)))
+			b.WriteString("\t\terr := d.DecodeElement(&v, &start)\n")
+			b.WriteString("\t\th.Value = v\n")
+			b.WriteString("\t\treturn err\n")
+		}
+		b.WriteString("\t}\n")
+		b.WriteString(fmt.Sprintf("\tvar v %s\n", baseName))
+		b.WriteString("\terr := d.DecodeElement(&v, &start)\n")
+		b.WriteString("\th.Value = v\n")
+		b.WriteString("\treturn err\n")
+		b.WriteString("}\n\n")
+
+		b.WriteString(ctg.generateHolderJSON(holder, base))
+	}
+
+	return b.String()
+}
+
+// generateHolderJSON generates the JSON methods of a holder, which tell the
+// types apart by @xsi:type like the OpenAPI spec
+func (ctg *ComplexTypeGenerator) generateHolderJSON(holder string, base models.Type) string {
+	var b strings.Builder
+	ctg.imports["encoding/json"] = true
+	ctg.usesJSONProperty = true
+
+	b.WriteString("// MarshalJSON writes the value, with the @xsi:type of derived types\n")
+	b.WriteString(fmt.Sprintf("func (h %s) MarshalJSON() ([]byte, error) {\n", holder))
+	b.WriteString("\tvar typeName string\n")
+	b.WriteString("\tswitch h.Value.(type) {\n")
+	for _, t := range ctg.derived[base.Name] {
+		if t.Abstract {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\tcase %s, *%s:\n", toPascalCase(t.Name), toPascalCase(t.Name)))
+		b.WriteString(fmt.Sprintf("\t\ttypeName = %q\n", t.Name))
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("\tdata, err := json.Marshal(h.Value)\n")
+	b.WriteString("\tif err != nil || typeName == \"\" {\n\t\treturn data, err\n\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn withJSONProperty(data, %q, typeName), nil\n", jsonXSIType))
+	b.WriteString("}\n\n")
+
+	b.WriteString("// UnmarshalJSON reads the type named by @xsi:type, the base type otherwise\n")
+	b.WriteString(fmt.Sprintf("func (h *%s) UnmarshalJSON(data []byte) error {\n", holder))
+	b.WriteString("\tif string(data) == \"null\" {\n\t\th.Value = nil\n\t\treturn nil\n\t}\n")
+	b.WriteString("\tvar typed struct {\n")
+	b.WriteString(fmt.Sprintf("\t\tType string `json:%q`\n", jsonXSIType))
+	b.WriteString("\t}\n")
+	b.WriteString("\tif err := json.Unmarshal(data, &typed); err != nil {\n\t\treturn err\n\t}\n")
+	b.WriteString("\tswitch typed.Type {\n")
+	for _, t := range ctg.derived[base.Name] {
+		if t.Abstract {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\tcase %q:\n", t.Name))
+		b.WriteString(fmt.Sprintf("\t\tvar v %s\n", toPascalCase(t.Name)))
+		b.WriteString("\t\terr := json.Unmarshal(data, &v)\n")
+		b.WriteString("\t\th.Value = v\n")
+		b.WriteString("\t\treturn err\n")
+	}
+	b.WriteString("\t}\n")
+	b.WriteString(fmt.Sprintf("\tvar v %s\n", toPascalCase(base.Name)))
+	b.WriteString("\terr := json.Unmarshal(data, &v)\n")
+	b.WriteString("\th.Value = v\n")
+	b.WriteString("\treturn err\n")
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// holder returns the base type and the types derived from it of a holder
+// type's Go name
+func (ctg *ComplexTypeGenerator) holder(goType string) (models.Type, []models.Type, bool) {
+	for _, name := range ctg.bases {
+		if holderName(name) == goType {
+			return ctg.types[name], ctg.derived[name], true
+		}
+	}
+	return models.Type{}, nil, false
+}
 
-		b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s,attr\"`\n", fieldName, fieldType, attr.Name))
+// holderName returns the Go name of the holder type of a base type
+func holderName(base string) string {
+	return "Any" + toPascalCase(base)
+}
+
+// GenerateAnyElement generates the AnyElement type used for xs:any content.
+// It returns an empty string when no type contains xs:any.
+func (ctg *ComplexTypeGenerator) GenerateAnyElement() string {
+	if !ctg.usesAny {
+		return ""
 	}
 
+	var b strings.Builder
+
+	b.WriteString("// AnyElement holds an element matched by xs:any as raw XML\n")
+	b.WriteString("type AnyElement struct {\n")
+	b.WriteString("\tXMLName xml.Name\n")
+	b.WriteString("\tAttrs   []xml.Attr `xml:\",any,attr\"`\n")
+	b.WriteString("\tContent []byte     `xml:\",innerxml\"`\n")
+	b.WriteString("}\n\n")
+
+	// encoding/xml mangles namespace declarations captured as attributes,
+	// so write them back verbatim for prefixes used in the raw content
+	b.WriteString("// MarshalXML writes the element back with its original attributes\n")
+	b.WriteString("func (a AnyElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n")
+	b.WriteString("\tstart = xml.StartElement{Name: a.XMLName}\n")
+	b.WriteString("\tfor _, attr := range a.Attrs {\n")
+	b.WriteString("\t\tswitch {\n")
+	b.WriteString("\t\tcase attr.Name.Space == \"xmlns\":\n")
+	b.WriteString("\t\t\tattr.Name = xml.Name{Local: \"xmlns:\" + attr.Name.Local}\n")
+	b.WriteString("\t\tcase attr.Name.Space == \"\" && attr.Name.Local == \"xmlns\":\n")
+	b.WriteString("\t\t\tcontinue\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tstart.Attr = append(start.Attr, attr)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn e.EncodeElement(struct {\n")
+	b.WriteString("\t\tContent []byte `xml:\",innerxml\"`\n")
+	b.WriteString("\t}{a.Content}, start)\n")
 	b.WriteString("}\n\n")
 
-	ctg.generatedTypes[t.Name] = true
 	return b.String()
 }
 
-// getFieldType determines the Go type for an element
-func (ctg *ComplexTypeGenerator) getFieldType(elem models.Element) string {
-	baseType := mapXSDTypeToGo(elem.Type)
+// Imports returns the packages the generated types need besides
+// encoding/xml, sorted by path
+func (ctg *ComplexTypeGenerator) Imports() []string {
+	imports := make([]string, 0, len(ctg.imports))
+	for path := range ctg.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// goType maps an XSD type to Go, applying the time and decimal type options
+func (ctg *ComplexTypeGenerator) goType(xsdType string) string {
+	if goType, ok := ctg.mappedType(xsdType); ok {
+		return goType
+	}
+	if goType, ok := ctg.timeGoType(xsdType); ok {
+		return goType
+	}
+	if goType, ok := ctg.decimalGoType(xsdType); ok {
+		return goType
+	}
+	return mapXSDTypeToGo(xsdType)
+}
+
+// Renames returns the fields renamed to avoid collisions so far
+func (ctg *ComplexTypeGenerator) Renames() []naming.Rename {
+	return ctg.renames
+}
+
+// Reserve marks a Go type name as already declared so that a complex type
+// with the same name is not generated
+func (ctg *ComplexTypeGenerator) Reserve(typeName string) {
+	ctg.generatedTypes[typeName] = true
+}
+
+// getFieldType determines the Go type for an element of t
+func (ctg *ComplexTypeGenerator) getFieldType(t models.Type, elem models.Element) string {
+	baseType, ok := ctg.mappedElement(t, elem)
+	if !ok {
+		baseType = ctg.valueType(elem)
+	}
+	if ctg.cyclic[t.Name+" "+elem.Name] {
+		return "*" + baseType
+	}
 
 	// Handle arrays (maxOccurs > 1 or "unbounded")
 	if elem.MaxOccurs == "unbounded" || (elem.MaxOccurs != "" && elem.MaxOccurs != "1") {
@@ -82,9 +613,37 @@ func (ctg *ComplexTypeGenerator) getFieldType(elem models.Element) string {
 	return baseType
 }
 
+// valueType returns the Go type of an element's value: its mapped type,
+// the holder of its substitution group or of the types derived from its
+// type, or else its type
+func (ctg *ComplexTypeGenerator) valueType(elem models.Element) string {
+	if goType, ok := ctg.mappedType(elem.Type); ok {
+		return goType
+	}
+	if group := ctg.substitutionGroup(elem); group != nil {
+		group.used = true
+		return group.holder
+	}
+	if name := models.LocalName(elem.Type); len(ctg.derived[name]) > 0 {
+		ctg.holders[name] = true
+		return holderName(name)
+	}
+	return ctg.goType(elem.Type)
+}
+
+// SetQualified qualifies element tags with their namespace. This is needed
+// when schemas span several namespaces, since unqualified tags inherit the
+// namespace of the parent element when marshaling.
+func (ctg *ComplexTypeGenerator) SetQualified(qualified bool) {
+	ctg.qualified = qualified
+}
+
 // buildXMLTag builds the XML tag for an element
 func (ctg *ComplexTypeGenerator) buildXMLTag(elem models.Element) string {
 	tag := elem.Name
+	if ctg.qualified && elem.Namespace != "" {
+		tag = elem.Namespace + " " + elem.Name
+	}
 
 	// Add omitempty for optional elements
 	if elem.MinOccurs == "0" {
@@ -94,14 +653,41 @@ func (ctg *ComplexTypeGenerator) buildXMLTag(elem models.Element) string {
 	return tag
 }
 
+// buildAttrTag builds the XML tag for an attribute. Optional attributes are
+// left out when they have the zero value.
+func buildAttrTag(attr models.Attribute) string {
+	tag := attr.Name + ",attr"
+	if attr.Namespace != "" {
+		tag = attr.Namespace + " " + tag
+	}
+	if attr.Use != "required" {
+		tag += ",omitempty"
+	}
+	return tag
+}
+
+// contentField returns the name of the field holding the character data of
+// a type with simple or mixed content, or an empty string for other types
+func contentField(t models.Type) string {
+	switch {
+	case t.SimpleContent != "":
+		return "Value"
+	case t.Mixed:
+		return "Text"
+	}
+	return ""
+}
+
 // IsComplexType checks if a type is a complex type (not a primitive)
 func IsComplexType(typeName string) bool {
 	primitives := map[string]bool{
 		"string": true, "int": true, "integer": true, "long": true,
 		"short": true, "byte": true, "boolean": true, "bool": true,
+		"unsignedLong": true, "unsignedInt": true, "unsignedShort": true, "unsignedByte": true,
 		"float": true, "double": true, "decimal": true,
 		"dateTime": true, "date": true, "time": true,
 		"base64Binary": true, "hexBinary": true,
+		"anyType": true, "anySimpleType": true,
 	}
 
 	// Remove namespace prefix
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecursiveTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+		// order lists type declarations in the order they must appear
+		order []string
+	}{
+		{
+			name: "self reference",
+			schema: `<xs:complexType name="Node"><xs:sequence>
+        <xs:element name="parent" type="tns:Node"/>
+        <xs:element name="children" type="tns:Node" maxOccurs="unbounded"/>
+      </xs:sequence></xs:complexType>`,
+			want: []string{
+				"Parent   *Node  `xml:\"parent\" json:\"parent\"`",
+				"Children []Node `xml:\"children\" json:\"children\"`",
+			},
+		},
+		{
+			name: "mutual references",
+			schema: `<xs:complexType name="Employee"><xs:sequence>
+        <xs:element name="department" type="tns:Department"/>
+      </xs:sequence></xs:complexType>
+      <xs:complexType name="Department"><xs:sequence>
+        <xs:element name="head" type="tns:Employee"/>
+      </xs:sequence></xs:complexType>`,
+			want: []string{
+				"Department Department `xml:\"department\" json:\"department\"`",
+				"Head *Employee `xml:\"head\" json:\"head\"`",
+			},
+			order: []string{"type Department struct", "type Employee struct"},
+		},
+		{
+			name: "fields declared later",
+			schema: `<xs:complexType name="Tree"><xs:sequence>
+        <xs:element name="root" type="tns:Branch"/>
+      </xs:sequence></xs:complexType>
+      <xs:complexType name="Branch"><xs:sequence>
+        <xs:element name="leaf" type="tns:Leaf"/>
+      </xs:sequence></xs:complexType>
+      <xs:complexType name="Leaf"><xs:sequence>
+        <xs:element name="value" type="xs:string"/>
+      </xs:sequence></xs:complexType>`,
+			want:  []string{"Root Branch `xml:\"root\" json:\"root\"`", "Leaf Leaf `xml:\"leaf\" json:\"leaf\"`"},
+			order: []string{"type Leaf struct", "type Branch struct", "type Tree struct"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, testWSDL(tt.schema), func(g *Generator) {
+				g.SetOptions(Options{Types: true})
+			})
+			assertContains(t, files, "types.go", append(tt.want, tt.order...))
+			code, last := files["types.go"], -1
+			for _, decl := range tt.order {
+				i := strings.Index(code, decl)
+				if i < last {
+					t.Errorf("%s declared out of order, want %v", decl, tt.order)
+				}
+				last = i
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package generator
+
+import "github.com/thdev01/wsdl2api/internal/models"
+
+// Go types for xs:decimal
+const (
+	// DecimalTypeFloat maps decimals to float64, which may lose precision
+	DecimalTypeFloat = "float64"
+	// DecimalTypeString maps decimals to their lexical form as strings
+	DecimalTypeString = "string"
+	// DecimalTypeBigRat maps decimals to a generated wrapper around *big.Rat
+	DecimalTypeBigRat = "*big.Rat"
+	// DecimalTypeShopspring maps decimals to github.com/shopspring/decimal
+	DecimalTypeShopspring = "shopspring/decimal"
+)
+
+// xsdDecimalCode is the generated wrapper used for DecimalTypeBigRat.
+// big.Rat marshals as a fraction, so the wrapper writes the exact decimal.
+const xsdDecimalCode = `// XSDDecimal is an xs:decimal backed by an exact *big.Rat. A nil Rat is
+// written as 0.
+type XSDDecimal struct {
+	*big.Rat
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d XSDDecimal) MarshalText() ([]byte, error) {
+	if d.Rat == nil {
+		return []byte("0"), nil
+	}
+
+	// Scale until the value is an integer to find the digits needed;
+	// values that don't terminate are rounded at 100 digits
+	scaled := new(big.Rat).Set(d.Rat)
+	ten := big.NewRat(10, 1)
+	prec := 0
+	for !scaled.IsInt() && prec < 100 {
+		scaled.Mul(scaled, ten)
+		prec++
+	}
+	return []byte(d.FloatString(prec)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *XSDDecimal) UnmarshalText(data []byte) error {
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		d.Rat = nil
+		return nil
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return fmt.Errorf("invalid decimal %q", value)
+	}
+	d.Rat = rat
+	return nil
+}
+
+`
+
+// decimalGoType returns the Go type for xs:decimal under the decimal type
+// option, recording the imports and helper type it needs
+func (ctg *ComplexTypeGenerator) decimalGoType(xsdType string) (string, bool) {
+	if models.LocalName(xsdType) != "decimal" {
+		return "", false
+	}
+
+	switch ctg.decimalType {
+	case DecimalTypeString:
+		return "string", true
+	case DecimalTypeBigRat:
+		ctg.usesDecimal = true
+		ctg.imports["fmt"] = true
+		ctg.imports["math/big"] = true
+		ctg.imports["strings"] = true
+		return "XSDDecimal", true
+	case DecimalTypeShopspring:
+		ctg.imports["github.com/shopspring/decimal"] = true
+		return "decimal.Decimal", true
+	default:
+		return "", false
+	}
+}
+
+// SetDecimalType sets the Go type used for xs:decimal, one of the
+// DecimalType constants
+func (ctg *ComplexTypeGenerator) SetDecimalType(decimalType string) {
+	ctg.decimalType = decimalType
+}
+
+// GenerateDecimalType generates the XSDDecimal type if any field uses it
+func (ctg *ComplexTypeGenerator) GenerateDecimalType() string {
+	if !ctg.usesDecimal {
+		return ""
+	}
+	return xsdDecimalCode
+}
+
+// SetDecimalType sets the Go type used for xs:decimal, one of the
+// DecimalType constants. The default is DecimalTypeFloat.
+func (g *Generator) SetDecimalType(decimalType string) {
+	g.decimalType = decimalType
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateDeterministic(t *testing.T) {
+	schema := `<xs:complexType name="Zone"><xs:sequence>
+        <xs:element name="name" type="xs:string"/>
+      </xs:sequence></xs:complexType>
+      <xs:complexType name="Area"><xs:sequence>
+        <xs:element name="zone" type="xs:string"/>
+      </xs:sequence></xs:complexType>
+      <xs:element name="Zeta"><xs:complexType/></xs:element>
+      <xs:element name="ZetaResponse"><xs:complexType/></xs:element>
+      <xs:element name="Alpha"><xs:complexType/></xs:element>
+      <xs:element name="AlphaResponse"><xs:complexType/></xs:element>`
+	deterministic := func(g *Generator) { g.SetDeterministic(true) }
+
+	tests := []struct {
+		name      string
+		configure func(g *Generator)
+		file      string
+		want      []string
+		// order lists declarations in the order they must appear
+		order []string
+	}{
+		{
+			name:      "generated code comment",
+			configure: deterministic,
+			file:      "types.go",
+			want:      []string{generatedHeader + "package client\n"},
+		},
+		{
+			name:      "operations in name order",
+			configure: deterministic,
+			file:      "operators.go",
+			order:     []string{"func (c *Client) Alpha()", "func (c *Client) Zeta()"},
+		},
+		{
+			name:      "types in name order",
+			configure: deterministic,
+			file:      "types.go",
+			order:     []string{"type Area struct", "type Zone struct"},
+		},
+		{
+			name:      "document order otherwise",
+			configure: nil,
+			file:      "operators.go",
+			order:     []string{"func (c *Client) Zeta()", "func (c *Client) Alpha()"},
+		},
+		{
+			name: "go:generate directive",
+			configure: func(g *Generator) {
+				g.SetDeterministic(true)
+				g.SetGoGenerate("wsdl2api generate --wsdl ../test.wsdl --output . --deterministic")
+			},
+			file: "doc.go",
+			want: []string{generatedHeader, "package client\n\n//go:generate wsdl2api generate --wsdl ../test.wsdl --output . --deterministic\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, testWSDL(schema, "Zeta", "Alpha"), tt.configure)
+			assertContains(t, files, tt.file, append(tt.want, tt.order...))
+			code, last := files[tt.file], -1
+			for _, decl := range tt.order {
+				i := strings.Index(code, decl)
+				if i < last {
+					t.Errorf("%s out of order, want %v", decl, tt.order)
+				}
+				last = i
+			}
+			if tt.configure == nil && strings.HasPrefix(code, generatedHeader) {
+				t.Errorf("%s starts with the generated code comment without SetDeterministic", tt.file)
+			}
+		})
+	}
+
+	// The output doesn't depend on the order of the declarations. The
+	// example imports the output directory, which differs between renders.
+	a := render(t, testWSDL(schema, "Zeta", "Alpha"), deterministic)
+	b := render(t, testWSDL(schema, "Alpha", "Zeta"), deterministic)
+	for name, code := range a {
+		if name != "example.go" && b[name] != code {
+			t.Errorf("%s differs with the operations declared in another order", name)
+		}
+	}
+}
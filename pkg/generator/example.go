@@ -2,8 +2,6 @@ package generator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/thdev01/wsdl2api/internal/models"
@@ -13,11 +11,18 @@ import (
 func (g *Generator) generateUsageExample(def *models.Definitions) error {
 	var b strings.Builder
 
+	// The import path is known for scaffolded modules and output inside a
+	// module
+	importPath, importComment := g.outputDir, "your-module/"+g.outputDir
+	if path, err := g.importPath(); err == nil {
+		importPath, importComment = path, path
+	}
+
 	b.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
 	b.WriteString(`// This file contains usage examples for the generated SOAP client
 // To use this client in your code:
 //
-// import "your-module/` + g.outputDir + `"
+// import "` + importComment + `"
 //
 // Example usage:
 
@@ -28,7 +33,7 @@ import (
 	"fmt"
 	"log"
 
-	"` + g.outputDir + `"
+	"` + importPath + `"
 )
 
 func main() {
@@ -43,19 +48,16 @@ func main() {
 	// Generate example for first operation
 	if len(def.PortTypes) > 0 && len(def.PortTypes[0].Operations) > 0 {
 		op := def.PortTypes[0].Operations[0]
-		methodName := toPascalCase(op.Name)
-		inputMsg := g.findMessage(def, op.Input.Name)
-
-		if inputMsg != nil && len(inputMsg.Parts) > 0 {
-			// Generate example parameters
-			var exampleParams []string
-			for _, part := range inputMsg.Parts {
-				exampleValue := g.getExampleValue(mapXSDTypeToGo(part.Type))
-				exampleParams = append(exampleParams, exampleValue)
+		methodName := g.operationName(def, op.Name)
+		if def.Message(op.Input.Name) != nil && def.Message(op.Output.Name) != nil {
+			// Generate example arguments
+			var exampleArgs []string
+			for _, param := range g.newOperator(def, op, methodName).params {
+				exampleArgs = append(exampleArgs, g.exampleArg(def, strings.SplitN(param, " ", 2)[1]))
 			}
 
 			b.WriteString(fmt.Sprintf("\t// Example: Call %s operation\n", op.Name))
-			b.WriteString(fmt.Sprintf("\tresult, err := client.%s(%s)\n", methodName, strings.Join(exampleParams, ", ")))
+			b.WriteString(fmt.Sprintf("\tresult, err := client.%s(%s)\n", methodName, strings.Join(exampleArgs, ", ")))
 			b.WriteString("\tif err != nil {\n")
 			b.WriteString(fmt.Sprintf("\t\tlog.Fatalf(\"Failed to call %s: %%v\", err)\n", op.Name))
 			b.WriteString("\t}\n\n")
@@ -69,27 +71,39 @@ func main() {
 	b.WriteString("// Available Operations:\n//\n")
 	for _, portType := range def.PortTypes {
 		for _, op := range portType.Operations {
-			methodName := toPascalCase(op.Name)
-			inputMsg := g.findMessage(def, op.Input.Name)
-
-			if inputMsg != nil {
-				params := g.generateParams(inputMsg)
-				outputMsg := g.findMessage(def, op.Output.Name)
-				outputType := "interface{}"
-				if outputMsg != nil && len(outputMsg.Parts) > 0 {
-					outputType = mapXSDTypeToGo(outputMsg.Parts[0].Type)
-				}
-
-				b.WriteString(fmt.Sprintf("// client.%s(%s) (%s, error)\n", methodName, params, outputType))
+			methodName := g.operationName(def, op.Name)
+			if def.Message(op.Input.Name) != nil && def.Message(op.Output.Name) != nil {
+				operator := g.newOperator(def, op, methodName)
+				b.WriteString(fmt.Sprintf("// client.%s(%s) (%s, error)\n", methodName, operator.signature(false), operator.result))
 				if op.Documentation != "" {
-					b.WriteString(fmt.Sprintf("//   %s\n", op.Documentation))
+					b.WriteString(wrapComment("//   ", op.Documentation))
 				}
 				b.WriteString("//\n")
 			}
 		}
 	}
 
-	return os.WriteFile(filepath.Join(g.outputDir, "example.go"), []byte(b.String()), 0644)
+	return g.writeGoFile("example.go", b.String())
+}
+
+// exampleArg returns an example argument of an operator parameter type:
+// an example value for basic types, or else an empty value
+func (g *Generator) exampleArg(def *models.Definitions, goType string) string {
+	if value := g.getExampleValue(goType); value != "nil" || strings.HasPrefix(goType, "[]") {
+		return value
+	}
+	if strings.HasPrefix(goType, "*") {
+		if g.getExampleValue(goType[1:]) != "nil" {
+			// Optional values are left out
+			return "nil"
+		}
+		return "&" + g.packageName + "." + goType[1:] + "{}"
+	}
+	zero := g.zeroValue(def, goType)
+	if strings.HasSuffix(zero, "{}") {
+		return g.packageName + "." + zero
+	}
+	return zero
 }
 
 // getExampleValue returns an example value for a Go type
@@ -97,7 +111,7 @@ func (g *Generator) getExampleValue(goType string) string {
 	switch goType {
 	case "string":
 		return "\"example\""
-	case "int", "int64", "int32", "int16":
+	case "int", "int64", "int32", "int16", "uint64", "uint32", "uint16", "uint8":
 		return "42"
 	case "float32", "float64":
 		return "3.14"
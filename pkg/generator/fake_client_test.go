@@ -0,0 +1,69 @@
+package generator
+
+import "testing"
+
+func TestGenerateFakeClient(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="GetQuote"><xs:complexType><xs:sequence>
+        <xs:element name="symbol" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetQuoteResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="xs:double"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="Ping"><xs:complexType/></xs:element>
+      <xs:element name="PingResponse"><xs:complexType/></xs:element>`, "GetQuote", "Ping")
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "client interface",
+			file: "operators.go",
+			want: []string{
+				"type ClientInterface interface {",
+				"GetQuote(symbol string) (float64, error)",
+				"GetQuoteContext(ctx context.Context, symbol string) (float64, error)",
+				"Ping() (*PingResponse, error)",
+				"PingContext(ctx context.Context) (*PingResponse, error)",
+				"var _ ClientInterface = (*Client)(nil)",
+			},
+		},
+		{
+			name: "programmable responses",
+			file: "fake_client.go",
+			want: []string{
+				"GetQuoteFunc func(ctx context.Context, symbol string) (float64, error)",
+				"PingFunc     func(ctx context.Context) (*PingResponse, error)",
+				"var _ ClientInterface = (*FakeClient)(nil)",
+				"func NewFakeClient() *FakeClient {",
+				"return f.GetQuoteContext(context.Background(), symbol)",
+				"return f.GetQuoteFunc(ctx, symbol)",
+			},
+		},
+		{
+			name: "unprogrammed operations",
+			file: "fake_client.go",
+			want: []string{
+				"if f.GetQuoteFunc == nil {",
+				`return 0, fmt.Errorf("fake client: GetQuote not programmed")`,
+				`return nil, fmt.Errorf("fake client: Ping not programmed")`,
+			},
+		},
+	}
+
+	files := render(t, wsdl, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, files, tt.file, tt.want)
+		})
+	}
+
+	// The fake client is an artifact of its own
+	files = render(t, wsdl, func(g *Generator) {
+		g.SetOptions(Options{Client: true, Types: true, Operators: true})
+	})
+	if _, ok := files["fake_client.go"]; ok {
+		t.Error("fake_client.go generated without the fake artifact")
+	}
+}
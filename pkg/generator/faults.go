@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// faultTypeName returns the Go type name of a declared fault
+func faultTypeName(fault models.Fault) string {
+	return toPascalCase(fault.Name) + "Error"
+}
+
+// generateFaultTypes generates an error struct per declared wsdl:fault and,
+// for each operation declaring faults, an interface its faults implement
+func (g *Generator) generateFaultTypes(def *models.Definitions, ctg *ComplexTypeGenerator) string {
+	var b strings.Builder
+
+	generated := make(map[string]bool)
+	for _, portType := range def.PortTypes {
+		for _, op := range portType.Operations {
+			for _, fault := range op.Faults {
+				typeName := faultTypeName(fault)
+				if generated[typeName] {
+					continue
+				}
+				msg := def.Message(fault.Message)
+				if msg == nil {
+					continue
+				}
+				generated[typeName] = true
+				ctg.Reserve(typeName)
+
+				b.WriteString(g.generateFaultStruct(def, ctg, typeName, fault, msg))
+			}
+		}
+	}
+
+	for _, portType := range def.PortTypes {
+		for _, op := range portType.Operations {
+			faults := g.declaredFaults(def, op)
+			if len(faults) == 0 {
+				continue
+			}
+
+			methodName := g.operationName(def, op.Name)
+			ctg.Reserve(methodName + "Fault")
+
+			b.WriteString(fmt.Sprintf("// %sFault is implemented by the faults declared by the %s operation\n", methodName, op.Name))
+			b.WriteString(fmt.Sprintf("type %sFault interface {\n", methodName))
+			b.WriteString("\terror\n")
+			b.WriteString(fmt.Sprintf("\tis%sFault()\n", methodName))
+			b.WriteString("}\n\n")
+			for _, fault := range faults {
+				b.WriteString(fmt.Sprintf("func (*%s) is%sFault() {}\n", faultTypeName(fault), methodName))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// generateFaultStruct generates the error struct for a fault. The struct
+// decodes the fault detail, matching its element by local name only.
+func (g *Generator) generateFaultStruct(def *models.Definitions, ctg *ComplexTypeGenerator, typeName string, fault models.Fault, msg *models.Message) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("// %s is the %s fault\n", typeName, fault.Name))
+	b.WriteString(fmt.Sprintf("type %s struct {\n", typeName))
+
+	var t *models.Type
+	if len(msg.Parts) == 1 && msg.Parts[0].Element != "" {
+		t = g.findElementType(def, msg.Parts[0].Element)
+	}
+	if t != nil {
+		b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s\" json:\"-\"`\n", models.LocalName(msg.Parts[0].Element)))
+		b.WriteString(ctg.GenerateFields(typeName, *t, "Fault", "Error"))
+	} else {
+		b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s\" json:\"-\"`\n", fault.Name))
+		fieldNames := partFieldNames(msg, "Fault", "Error")
+		for i, part := range msg.Parts {
+			b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"%s`\n", fieldNames[i], ctg.goType(g.partType(def, part)), part.Name, jsonTag(ctg.jsonName(part.Name), false)))
+		}
+	}
+	b.WriteString("\n\t// Fault is the SOAP fault carrying this detail\n")
+	b.WriteString("\tFault *SOAPFault `xml:\"-\" json:\"-\"`\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Error implements the error interface\n")
+	b.WriteString(fmt.Sprintf("func (e *%s) Error() string {\n", typeName))
+	b.WriteString("\tif e.Fault != nil {\n")
+	b.WriteString("\t\treturn e.Fault.Error()\n")
+	b.WriteString("\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn %q\n", "SOAP fault: "+fault.Name))
+	b.WriteString("}\n\n")
+	if t != nil {
+		b.WriteString(ctg.GenerateAnyUnmarshal(typeName, *t, "Fault", "Error"))
+	}
+
+	return b.String()
+}
+
+// generateFaultDecoder generates the function converting a *SOAPFault
+// returned for op into one of its declared faults
+func (g *Generator) generateFaultDecoder(def *models.Definitions, op models.Operation) string {
+	var b strings.Builder
+
+	methodName := g.operationName(def, op.Name)
+	b.WriteString(fmt.Sprintf("// decode%sFault converts a SOAP fault into the %sFault it carries, if any\n", methodName, methodName))
+	b.WriteString(fmt.Sprintf("func decode%sFault(err error) error {\n", methodName))
+	b.WriteString("\tvar fault *SOAPFault\n")
+	b.WriteString("\tif !errors.As(err, &fault) {\n")
+	b.WriteString("\t\treturn err\n")
+	b.WriteString("\t}\n")
+	for _, fault := range g.declaredFaults(def, op) {
+		b.WriteString(fmt.Sprintf("\tif detail := new(%s); fault.DecodeDetail(detail) == nil {\n", faultTypeName(fault)))
+		b.WriteString("\t\tdetail.Fault = fault\n")
+		b.WriteString("\t\treturn detail\n")
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("\treturn err\n")
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// declaredFaults returns the faults of op whose messages are defined
+func (g *Generator) declaredFaults(def *models.Definitions, op models.Operation) []models.Fault {
+	var faults []models.Fault
+	for _, fault := range op.Faults {
+		if def.Message(fault.Message) != nil {
+			faults = append(faults, fault)
+		}
+	}
+	return faults
+}
@@ -8,12 +8,36 @@ import (
 	"text/template"
 
 	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
 )
 
 // Generator generates Go code from WSDL definitions
 type Generator struct {
-	outputDir   string
-	packageName string
+	outputDir    string
+	packageName  string
+	timeType     string
+	decimalType  string
+	jsonNaming   string
+	typeMapping  *TypeMapping
+	split        bool
+	modulePath   string
+	standalone   bool
+	goGenerate   string
+	soapVersion  string
+	names        *naming.Namer
+	namesDef     *models.Definitions
+	fieldRenames []naming.Rename
+	registry     *Registry
+	options      Options
+
+	// deterministic output is generated from sorted, a sorted copy of
+	// sortedFrom
+	deterministic bool
+	sorted        *models.Definitions
+	sortedFrom    *models.Definitions
+
+	// out holds the files being generated until they are written
+	out *output
 }
 
 // NewGenerator creates a new code generator
@@ -21,54 +45,217 @@ func NewGenerator(outputDir, packageName string) *Generator {
 	return &Generator{
 		outputDir:   outputDir,
 		packageName: packageName,
+		timeType:    TimeTypeString,
+		decimalType: DecimalTypeFloat,
+		jsonNaming:  JSONNamingXML,
+		registry:    DefaultRegistry,
+		options:     DefaultOptions(),
 	}
 }
 
-// Generate generates all code from WSDL definitions
+// newComplexTypeGenerator creates a complex type generator with the
+// generator's type options
+func (g *Generator) newComplexTypeGenerator(targetNS string) *ComplexTypeGenerator {
+	ctg := NewComplexTypeGenerator(targetNS)
+	ctg.SetTimeType(g.timeType)
+	ctg.SetDecimalType(g.decimalType)
+	ctg.SetTypeMapping(g.typeMapping)
+	ctg.SetJSONNaming(g.jsonNaming)
+	return ctg
+}
+
+// goType maps an XSD type to Go for operator parameters and results,
+// matching the types generated in types.go
+func (g *Generator) goType(xsdType string) string {
+	return g.newComplexTypeGenerator("").goType(xsdType)
+}
+
+// operations returns the namer holding the Go identifiers of def's
+// operations
+func (g *Generator) operations(def *models.Definitions) *naming.Namer {
+	if g.namesDef != def {
+		g.names = naming.Operations(def)
+		g.namesDef = def
+	}
+	return g.names
+}
+
+// operationName returns the Go identifier of an operation, unique within
+// the service
+func (g *Generator) operationName(def *models.Definitions, opName string) string {
+	return g.operations(def).Name(opName)
+}
+
+// Renames returns the operations and struct fields of def that had to be
+// renamed to become valid, unique Go identifiers
+func (g *Generator) Renames(def *models.Definitions) []naming.Rename {
+	def = g.ordered(def)
+	renames := append([]naming.Rename(nil), g.operations(def).Renames()...)
+	return append(renames, g.fieldRenames...)
+}
+
+// Generate generates the code selected by the generator's options from
+// WSDL definitions
 func (g *Generator) Generate(def *models.Definitions) error {
+	return g.generate(def, g.options)
+}
+
+// GenerateWithMock generates all code including mock server
+func (g *Generator) GenerateWithMock(def *models.Definitions) error {
+	opts := g.options
+	opts.Mock = true
+	return g.generate(def, opts)
+}
+
+// generate writes the files selected by opts
+func (g *Generator) generate(def *models.Definitions, opts Options) error {
+	if err := g.render(def, opts); err != nil {
+		return err
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
+	if err := g.writeOutput(def, opts); err != nil {
+		return fmt.Errorf("failed to write generated code: %w", err)
+	}
+
+	// Generate registered artifacts
+	if err := g.registry.Run(g.ordered(def), g.outputDir); err != nil {
+		return fmt.Errorf("failed to generate artifact: %w", err)
+	}
+	return nil
+}
+
+// render generates the files selected by opts in memory, in g.out
+func (g *Generator) render(def *models.Definitions, opts Options) error {
+	def = g.ordered(def)
+	g.out = newOutput()
+
+	// Scaffold a standalone module
+	if g.modulePath != "" {
+		if err := g.generateModule(def); err != nil {
+			return fmt.Errorf("failed to generate module: %w", err)
+		}
+	}
+
+	// Generate clients of HTTP bindings; everything else is generated
+	// for the SOAP port types only
+	if opts.Client {
+		if err := g.generateHTTPClients(def); err != nil {
+			return fmt.Errorf("failed to generate HTTP client: %w", err)
+		}
+	}
+	def = soapDefinitions(def)
 
 	// Generate client with WS-Security support
-	if err := g.generateClientWithSecurity(def); err != nil {
-		return fmt.Errorf("failed to generate client: %w", err)
+	if opts.Client {
+		if err := g.generateClientWithSecurity(def); err != nil {
+			return fmt.Errorf("failed to generate client: %w", err)
+		}
 	}
 
 	// Generate improved types
-	if err := g.generateTypesImproved(def); err != nil {
-		return fmt.Errorf("failed to generate types: %w", err)
+	if opts.Types {
+		if err := g.generateTypesImproved(def); err != nil {
+			return fmt.Errorf("failed to generate types: %w", err)
+		}
 	}
 
 	// Generate operator functions
-	if err := g.generateOperatorsImproved(def); err != nil {
-		return fmt.Errorf("failed to generate operators: %w", err)
+	if opts.Operators {
+		if err := g.generateOperatorsImproved(def); err != nil {
+			return fmt.Errorf("failed to generate operators: %w", err)
+		}
 	}
 
-	// Generate usage example
-	if err := g.generateUsageExample(def); err != nil {
-		return fmt.Errorf("failed to generate usage example: %w", err)
+	// Generate the package documentation, indexing the files of split
+	// types and operators
+	if g.split || g.modulePath != "" || g.goGenerate != "" {
+		var files []portTypeFile
+		if g.split && (opts.Types || opts.Operators) {
+			files = portTypeFiles(def)
+		}
+		if err := g.generateDoc(def, files); err != nil {
+			return fmt.Errorf("failed to generate package documentation: %w", err)
+		}
 	}
 
-	return nil
-}
+	// Generate in-memory fake for unit tests
+	if opts.FakeClient {
+		if err := g.generateFakeClient(def); err != nil {
+			return fmt.Errorf("failed to generate fake client: %w", err)
+		}
+	}
 
-// GenerateWithMock generates all code including mock server
-func (g *Generator) GenerateWithMock(def *models.Definitions) error {
-	// Generate all standard code
-	if err := g.Generate(def); err != nil {
-		return err
+	// Generate usage example
+	if opts.Example {
+		if err := g.generateUsageExample(def); err != nil {
+			return fmt.Errorf("failed to generate usage example: %w", err)
+		}
 	}
 
 	// Generate mock server
-	if err := g.generateMockServer(def); err != nil {
-		return fmt.Errorf("failed to generate mock server: %w", err)
+	if opts.Mock || opts.Tests {
+		if err := g.generateMockServer(def); err != nil {
+			return fmt.Errorf("failed to generate mock server: %w", err)
+		}
+	}
+
+	// Generate round-trip tests against the mock server
+	if opts.Tests {
+		if err := g.generateMockTests(def); err != nil {
+			return fmt.Errorf("failed to generate tests: %w", err)
+		}
+	}
+
+	// Generate REST server skeleton
+	if opts.Server {
+		if err := g.generateServerStub(def); err != nil {
+			return fmt.Errorf("failed to generate server: %w", err)
+		}
+	}
+
+	// Copy the runtime packages into standalone output
+	if g.standalone {
+		if err := g.generateRuntime(); err != nil {
+			return fmt.Errorf("failed to generate runtime: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// soapDefinitions returns def without the port types bound only by HTTP
+// bindings, whose operations are called through the clients of
+// http_client.go rather than SOAP envelopes
+func soapDefinitions(def *models.Definitions) *models.Definitions {
+	httpOnly := make(map[string]bool)
+	for _, binding := range def.Bindings {
+		if binding.HTTPVerb != "" {
+			httpOnly[models.LocalName(binding.Type)] = true
+		}
+	}
+	for _, binding := range def.Bindings {
+		if binding.HTTPVerb == "" {
+			delete(httpOnly, models.LocalName(binding.Type))
+		}
+	}
+	if len(httpOnly) == 0 {
+		return def
+	}
+
+	soap := *def
+	soap.PortTypes = nil
+	for _, portType := range def.PortTypes {
+		if !httpOnly[portType.Name] {
+			soap.PortTypes = append(soap.PortTypes, portType)
+		}
+	}
+	return &soap
+}
+
 // generateClient generates the SOAP client code
 //
 //nolint:unused // Legacy function kept for reference
@@ -195,7 +382,7 @@ func (g *Generator) generateTypes(def *models.Definitions) error {
 	}
 
 	// Write to file
-	return os.WriteFile(filepath.Join(g.outputDir, "types.go"), []byte(b.String()), 0644)
+	return g.writeGoFile("types.go", b.String())
 }
 
 // generateOperations generates operation methods
@@ -216,11 +403,11 @@ func (g *Generator) generateOperations(def *models.Definitions) error {
 			outputType := toPascalCase(op.Output.Name)
 
 			// Find SOAP action from bindings
-			soapAction := g.findSoapAction(def, op.Name)
+			soapAction := def.SoapAction(op.Name)
 
 			b.WriteString(fmt.Sprintf("// %s executes %s operation\n", methodName, op.Name))
 			if op.Documentation != "" {
-				b.WriteString(fmt.Sprintf("// %s\n", op.Documentation))
+				b.WriteString(wrapComment("// ", op.Documentation))
 			}
 			b.WriteString(fmt.Sprintf("func (c *Client) %s(req *%s) (*%s, error) {\n", methodName, inputType, outputType))
 			b.WriteString(fmt.Sprintf("\tvar resp %s\n", outputType))
@@ -234,69 +421,40 @@ func (g *Generator) generateOperations(def *models.Definitions) error {
 	}
 
 	// Write to file
-	return os.WriteFile(filepath.Join(g.outputDir, "operations.go"), []byte(b.String()), 0644)
-}
-
-// findSoapAction finds the SOAP action for an operation
-func (g *Generator) findSoapAction(def *models.Definitions, opName string) string {
-	for _, binding := range def.Bindings {
-		for _, op := range binding.Operations {
-			if op.Name == opName {
-				return op.SoapAction
-			}
-		}
-	}
-	return ""
+	return g.writeGoFile("operations.go", b.String())
 }
 
 // Helper functions
 func toPascalCase(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return s
-	}
-
-	// Remove namespace prefix if present
-	if idx := strings.LastIndex(s, ":"); idx != -1 {
-		s = s[idx+1:]
-	}
-
-	// Split by common separators
-	words := strings.FieldsFunc(s, func(r rune) bool {
-		return r == '_' || r == '-' || r == '.' || r == ' '
-	})
-
-	for i, word := range words {
-		if len(word) > 0 {
-			words[i] = strings.ToUpper(string(word[0])) + word[1:]
-		}
-	}
-
-	return strings.Join(words, "")
+	return naming.Pascal(s)
 }
 
 func mapXSDTypeToGo(xsdType string) string {
 	// Remove namespace prefix
-	if idx := strings.LastIndex(xsdType, ":"); idx != -1 {
-		xsdType = xsdType[idx+1:]
-	}
+	xsdType = models.LocalName(xsdType)
 
 	typeMap := map[string]string{
-		"string":    "string",
-		"int":       "int",
-		"integer":   "int",
-		"long":      "int64",
-		"short":     "int16",
-		"byte":      "byte",
-		"boolean":   "bool",
-		"float":     "float32",
-		"double":    "float64",
-		"decimal":   "float64",
-		"dateTime":  "string",
-		"date":      "string",
-		"time":      "string",
-		"base64Binary": "[]byte",
-		"hexBinary": "[]byte",
+		"string":        "string",
+		"int":           "int",
+		"integer":       "int",
+		"long":          "int64",
+		"short":         "int16",
+		"byte":          "byte",
+		"unsignedLong":  "uint64",
+		"unsignedInt":   "uint32",
+		"unsignedShort": "uint16",
+		"unsignedByte":  "uint8",
+		"boolean":       "bool",
+		"float":         "float32",
+		"double":        "float64",
+		"decimal":       "float64",
+		"dateTime":      "string",
+		"date":          "string",
+		"time":          "string",
+		"base64Binary":  "[]byte",
+		"hexBinary":     "[]byte",
+		"anyType":       "string",
+		"anySimpleType": "string",
 	}
 
 	if goType, ok := typeMap[xsdType]; ok {
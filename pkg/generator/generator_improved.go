@@ -2,11 +2,11 @@ package generator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
 )
 
 // generateClientImproved generates an improved SOAP client with proper XML handling
@@ -135,182 +135,646 @@ type SOAPFault struct {
 }
 `)
 
-	return os.WriteFile(filepath.Join(g.outputDir, "client.go"), []byte(b.String()), 0644)
+	return g.writeGoFile("client.go", b.String())
 }
 
 // generateOperatorsImproved generates easy-to-use operator functions
 func (g *Generator) generateOperatorsImproved(def *models.Definitions) error {
 	var b strings.Builder
 
-	b.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
-	b.WriteString("import \"fmt\"\n\n")
-	b.WriteString("// Auto-generated operator functions for easy usage\n\n")
-
-	// Find target namespace
-	targetNS := def.TargetNamespace
-
-	// Generate operators for each operation
+	// Generate the interface implemented by Client and FakeClient
+	b.WriteString("// ClientInterface contains all operations of the service. It is implemented\n")
+	b.WriteString("// by Client and, for unit tests, by FakeClient.\n")
+	b.WriteString("type ClientInterface interface {\n")
 	for _, portType := range def.PortTypes {
 		for _, op := range portType.Operations {
-			methodName := toPascalCase(op.Name)
-			soapAction := g.findSoapAction(def, op.Name)
-
-			// Find input/output message details
-			inputMsg := g.findMessage(def, op.Input.Name)
-			outputMsg := g.findMessage(def, op.Output.Name)
-
+			inputMsg := def.Message(op.Input.Name)
+			outputMsg := def.Message(op.Output.Name)
 			if inputMsg == nil || outputMsg == nil {
 				continue
 			}
 
-			// Generate parameter list
-			params := g.generateParams(inputMsg)
-			inputStruct := g.generateInputStruct(inputMsg, targetNS)
-			outputField := g.generateOutputField(outputMsg)
+			methodName := g.operationName(def, op.Name)
+			operator := g.newOperator(def, op, methodName)
+			b.WriteString(fmt.Sprintf("\t%s(%s) (%s, error)\n", methodName, operator.signature(false), operator.result))
+			b.WriteString(fmt.Sprintf("\t%sContext(%s) (%s, error)\n", methodName, operator.signature(true), operator.result))
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("var _ ClientInterface = (*Client)(nil)\n\n")
 
-			// Generate operator function
-			b.WriteString(fmt.Sprintf("// %s is an easy-to-use operator for the %s operation\n", methodName, op.Name))
-			if op.Documentation != "" {
-				b.WriteString(fmt.Sprintf("// %s\n", op.Documentation))
-			}
-			b.WriteString(fmt.Sprintf("func (c *Client) %s(%s) (%s, error) {\n", methodName, params, outputField))
-			b.WriteString(fmt.Sprintf("\trequest := %s\n", inputStruct))
-			b.WriteString(fmt.Sprintf("\tvar response %sResponse\n\n", methodName))
-			b.WriteString(fmt.Sprintf("\terr := c.Call(\"%s\", request, &response)\n", soapAction))
-			b.WriteString("\tif err != nil {\n")
-			b.WriteString(fmt.Sprintf("\t\treturn %s, fmt.Errorf(\"failed to execute %s: %%w\", err)\n", g.getZeroValue(outputField), op.Name))
-			b.WriteString("\t}\n\n")
-			b.WriteString(fmt.Sprintf("\treturn response.%sResult, nil\n", methodName))
-			b.WriteString("}\n\n")
+	if !g.split {
+		for _, portType := range def.PortTypes {
+			b.WriteString(g.generatePortTypeOperators(def, portType))
+		}
+		return g.writeGoFile("operators.go", g.operatorsFile(b.String()))
+	}
+
+	if err := g.writeGoFile("operators.go", g.operatorsFile(b.String())); err != nil {
+		return err
+	}
+	for _, file := range portTypeFiles(def) {
+		if err := g.writeGoFile(file.stem+"_operators.go", g.operatorsFile(g.generatePortTypeOperators(def, file.portType))); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	return os.WriteFile(filepath.Join(g.outputDir, "operators.go"), []byte(b.String()), 0644)
+// operatorsFile returns the source of a file of operators
+func (g *Generator) operatorsFile(body string) string {
+	header := g.goFileHeader(body, []string{"context", "errors", "fmt"}, nil)
+	return header + "// Auto-generated operator functions for easy usage\n\n" + body
+}
+
+// generatePortTypeOperators generates the operators of a port type's
+// operations
+func (g *Generator) generatePortTypeOperators(def *models.Definitions, portType models.PortType) string {
+	var b strings.Builder
+
+	for _, op := range portType.Operations {
+		methodName := g.operationName(def, op.Name)
+		soapAction := def.SoapAction(op.Name)
+
+		// Find input/output message details
+		inputMsg := def.Message(op.Input.Name)
+		outputMsg := def.Message(op.Output.Name)
+
+		if inputMsg == nil || outputMsg == nil {
+			continue
+		}
+
+		operator := g.newOperator(def, op, methodName)
+
+		// Generate operator function
+		b.WriteString(fmt.Sprintf("// %s is an easy-to-use operator for the %s operation\n", methodName, op.Name))
+		if op.Documentation != "" {
+			b.WriteString(wrapComment("// ", op.Documentation))
+		}
+		b.WriteString(fmt.Sprintf("func (c *Client) %s(%s) (%s, error) {\n", methodName, operator.signature(false), operator.result))
+		b.WriteString(fmt.Sprintf("\treturn c.%sContext(%s)\n", methodName, strings.Join(append([]string{"context.Background()"}, operator.args...), ", ")))
+		b.WriteString("}\n\n")
+
+		// Generate context-aware variant
+		b.WriteString(fmt.Sprintf("// %sContext is like %s but uses ctx for cancellation and timeouts\n", methodName, methodName))
+		b.WriteString(fmt.Sprintf("func (c *Client) %sContext(%s) (%s, error) {\n", methodName, operator.signature(true), operator.result))
+		b.WriteString(operator.prologue)
+		b.WriteString(fmt.Sprintf("\tvar response %sResponse\n\n", methodName))
+		b.WriteString(fmt.Sprintf("\terr := c.CallContext(ctx, \"%s\", request, &response)\n", soapAction))
+		b.WriteString("\tif err != nil {\n")
+		faults := g.declaredFaults(def, op)
+		if len(faults) > 0 {
+			b.WriteString(fmt.Sprintf("\t\treturn %s, fmt.Errorf(\"failed to execute %s: %%w\", decode%sFault(err))\n", operator.zero, op.Name, methodName))
+		} else {
+			b.WriteString(fmt.Sprintf("\t\treturn %s, fmt.Errorf(\"failed to execute %s: %%w\", err)\n", operator.zero, op.Name))
+		}
+		b.WriteString("\t}\n\n")
+		b.WriteString(fmt.Sprintf("\treturn %s, nil\n", operator.response))
+		b.WriteString("}\n\n")
+
+		if len(faults) > 0 {
+			b.WriteString(g.generateFaultDecoder(def, op))
+		}
+	}
+
+	return b.String()
 }
 
 // generateTypesImproved generates improved type definitions with proper XML tags
 func (g *Generator) generateTypesImproved(def *models.Definitions) error {
 	var b strings.Builder
 
-	b.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
-	b.WriteString("import \"encoding/xml\"\n\n")
-	b.WriteString("// Auto-generated types from WSDL\n\n")
-
 	targetNS := def.TargetNamespace
+	ctg := g.newComplexTypeGenerator(targetNS)
+	ctg.SetQualified(len(schemaNamespaces(def)) > 1)
+	ctg.SetElements(def.Elements)
+	ctg.SetTypes(def.Types)
 
 	// Generate request/response types for each operation
-	for _, portType := range def.PortTypes {
-		for _, op := range portType.Operations {
-			methodName := toPascalCase(op.Name)
+	files := portTypeFiles(def)
+	messages := make([]string, len(files))
+	for i, file := range files {
+		messages[i] = g.generatePortTypeMessages(def, ctg, file.portType)
+	}
 
-			// Find messages
-			inputMsg := g.findMessage(def, op.Input.Name)
-			outputMsg := g.findMessage(def, op.Output.Name)
+	// Generate typed faults declared by operations
+	b.WriteString(g.generateFaultTypes(def, ctg))
 
-			if inputMsg == nil || outputMsg == nil {
-				continue
-			}
+	// Generate complex and simple types declared in wsdl:types
+	for _, t := range orderTypes(def.Types) {
+		b.WriteString(ctg.GenerateComplexType(t))
+	}
+	for _, st := range def.SimpleTypes {
+		b.WriteString(ctg.GenerateSimpleType(st))
+	}
+	b.WriteString(ctg.GenerateSubstitutionGroups())
+	b.WriteString(ctg.GenerateHolders())
+	b.WriteString(ctg.GenerateJSONHelpers())
+	b.WriteString(ctg.GenerateAnyElement())
+	b.WriteString(ctg.GenerateTimeTypes())
+	b.WriteString(ctg.GenerateDecimalType())
+	g.fieldRenames = ctg.Renames()
+
+	// The headers go last since the imports depend on the generated types
+	imports := append([]string{"encoding/xml"}, ctg.Imports()...)
+	names := g.typeMapping.packageNames()
+	typesFile := func(body string) string {
+		return g.goFileHeader(body, imports, names) + "// Auto-generated types from WSDL\n\n" + body
+	}
+
+	if !g.split {
+		return g.writeGoFile("types.go", typesFile(strings.Join(messages, "")+b.String()))
+	}
+
+	if err := g.writeGoFile("types.go", typesFile(b.String())); err != nil {
+		return err
+	}
+	for i, file := range files {
+		if err := g.writeGoFile(file.stem+"_types.go", typesFile(messages[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatePortTypeMessages generates the request and response types of a
+// port type's operations
+func (g *Generator) generatePortTypeMessages(def *models.Definitions, ctg *ComplexTypeGenerator, portType models.PortType) string {
+	var b strings.Builder
+	targetNS := def.TargetNamespace
+
+	for _, op := range portType.Operations {
+		methodName := g.operationName(def, op.Name)
 
-			// Generate request type
-			b.WriteString(fmt.Sprintf("// %sRequest represents the request for %s operation\n", methodName, op.Name))
-			b.WriteString(fmt.Sprintf("type %sRequest struct {\n", methodName))
-			b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s %s\"`\n", targetNS, op.Name))
+		// Find messages
+		inputMsg := def.Message(op.Input.Name)
+		outputMsg := def.Message(op.Output.Name)
 
-			for _, part := range inputMsg.Parts {
-				fieldName := toPascalCase(part.Name)
-				fieldType := mapXSDTypeToGo(part.Type)
-				xmlTag := part.Name
-				b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"`\n", fieldName, fieldType, xmlTag))
+		if inputMsg == nil || outputMsg == nil {
+			continue
+		}
+
+		// rpc style wraps parts in an element in the soap:body namespace
+		style, bindOp := g.operationStyle(def, op.Name)
+		inputNS, outputNS := targetNS, targetNS
+		if style == "rpc" && bindOp != nil {
+			if bindOp.Input.Namespace != "" {
+				inputNS = bindOp.Input.Namespace
 			}
-			b.WriteString("}\n\n")
+			if bindOp.Output.Namespace != "" {
+				outputNS = bindOp.Output.Namespace
+			}
+		}
 
-			// Generate response type
-			b.WriteString(fmt.Sprintf("// %sResponse represents the response for %s operation\n", methodName, op.Name))
-			b.WriteString(fmt.Sprintf("type %sResponse struct {\n", methodName))
-			b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s %sResponse\"`\n", targetNS, op.Name))
+		// Generate request type
+		b.WriteString(fmt.Sprintf("// %sRequest represents the request for %s operation\n", methodName, op.Name))
+		b.WriteString(g.generateMessageStruct(def, ctg, methodName+"Request", op.Name, inputNS, style, inputMsg))
+		if style == "rpc" && bindOp != nil && bindOp.Input.Use == "encoded" {
+			b.WriteString(g.generateEncodedMarshaler(def, methodName+"Request", op.Name, inputNS, bindOp.Input.EncodingStyle, inputMsg))
+		}
+		if idempotent(op.Name) {
+			b.WriteString(fmt.Sprintf("// idempotent marks %s as only reading, so that EnableCache caches its\n// responses\n", op.Name))
+			b.WriteString(fmt.Sprintf("func (*%sRequest) idempotent() {}\n\n", methodName))
+		}
+
+		// Generate response type
+		b.WriteString(fmt.Sprintf("// %sResponse represents the response for %s operation\n", methodName, op.Name))
+		b.WriteString(g.generateMessageStruct(def, ctg, methodName+"Response", op.Name+"Response", outputNS, style, outputMsg))
 
-			for _, part := range outputMsg.Parts {
-				fieldName := toPascalCase(part.Name)
-				fieldType := mapXSDTypeToGo(part.Type)
-				xmlTag := part.Name
-				b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"`\n", fieldName, fieldType, xmlTag))
+		ctg.Reserve(methodName + "Request")
+		ctg.Reserve(methodName + "Response")
+	}
+
+	return b.String()
+}
+
+// generateMessageStruct generates the struct for a message. In document
+// style a message with a single element part is the element itself, so the
+// struct takes the element's name and its complex type's fields. Otherwise
+// the parts are wrapped in elementName, as rpc style requires.
+func (g *Generator) generateMessageStruct(def *models.Definitions, ctg *ComplexTypeGenerator, structName, elementName, namespace, style string, msg *models.Message) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("type %s struct {\n", structName))
+
+	if style != "rpc" && len(msg.Parts) == 1 && msg.Parts[0].Element != "" {
+		if t := g.findElementType(def, msg.Parts[0].Element); t != nil {
+			// The element may live in a schema namespace other than the
+			// WSDL target namespace
+			if elem := g.findElement(def, msg.Parts[0].Element); elem.Namespace != "" {
+				namespace = elem.Namespace
 			}
+			b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s %s\" json:\"-\"`\n", namespace, models.LocalName(msg.Parts[0].Element)))
+			b.WriteString(ctg.GenerateFields(structName, *t))
 			b.WriteString("}\n\n")
+			b.WriteString(ctg.GenerateValidate(structName, *t))
+			b.WriteString(ctg.GenerateAnyUnmarshal(structName, *t))
+			return b.String()
 		}
 	}
 
-	return os.WriteFile(filepath.Join(g.outputDir, "types.go"), []byte(b.String()), 0644)
+	b.WriteString(fmt.Sprintf("\tXMLName xml.Name `xml:\"%s %s\" json:\"-\"`\n", namespace, elementName))
+	fieldNames := partFieldNames(msg)
+	for i, part := range msg.Parts {
+		fieldName := fieldNames[i]
+		fieldType := ctg.goType(g.partType(def, part))
+		xmlTag := part.Name
+		b.WriteString(fmt.Sprintf("\t%s %s `xml:\"%s\"%s`\n", fieldName, fieldType, xmlTag, jsonTag(ctg.jsonName(part.Name), false)))
+	}
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// generateEncodedMarshaler generates a MarshalXML method that adds the
+// encodingStyle and xsi:type attributes rpc/encoded services require
+func (g *Generator) generateEncodedMarshaler(def *models.Definitions, structName, elementName, namespace, encodingStyle string, msg *models.Message) string {
+	var b strings.Builder
+
+	if encodingStyle == "" {
+		encodingStyle = "http://schemas.xmlsoap.org/soap/encoding/"
+	}
+
+	b.WriteString(fmt.Sprintf("// MarshalXML encodes %s with the SOAP encoding style and xsi:type attributes\n", structName))
+	b.WriteString(fmt.Sprintf("func (r %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", structName))
+	b.WriteString("\tstart = xml.StartElement{\n")
+	b.WriteString(fmt.Sprintf("\t\tName: xml.Name{Space: %q, Local: %q},\n", namespace, elementName))
+	b.WriteString("\t\tAttr: []xml.Attr{\n")
+	b.WriteString(fmt.Sprintf("\t\t\t{Name: xml.Name{Local: \"soap:encodingStyle\"}, Value: %q},\n", encodingStyle))
+	b.WriteString("\t\t\t{Name: xml.Name{Local: \"xmlns:xsi\"}, Value: \"http://www.w3.org/2001/XMLSchema-instance\"},\n")
+	b.WriteString("\t\t\t{Name: xml.Name{Local: \"xmlns:xsd\"}, Value: \"http://www.w3.org/2001/XMLSchema\"},\n")
+	b.WriteString(fmt.Sprintf("\t\t\t{Name: xml.Name{Local: \"xmlns:tns\"}, Value: %q},\n", def.TargetNamespace))
+	b.WriteString("\t\t},\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tif err := e.EncodeToken(start); err != nil {\n")
+	b.WriteString("\t\treturn err\n")
+	b.WriteString("\t}\n")
+
+	fieldNames := partFieldNames(msg)
+	for i, part := range msg.Parts {
+		fieldName := fieldNames[i]
+		xsiType := xsiTypeName(g.partType(def, part))
+		b.WriteString(fmt.Sprintf("\tif err := e.EncodeElement(r.%s, xml.StartElement{Name: xml.Name{Local: %q}, Attr: []xml.Attr{{Name: xml.Name{Local: \"xsi:type\"}, Value: %q}}}); err != nil {\n", fieldName, part.Name, xsiType))
+		b.WriteString("\t\treturn err\n")
+		b.WriteString("\t}\n")
+	}
+
+	b.WriteString("\treturn e.EncodeToken(start.End())\n")
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// xsiTypeName returns the xsi:type value for an XSD type using the xsd and
+// tns prefixes declared by generateEncodedMarshaler
+func xsiTypeName(xsdType string) string {
+	if IsComplexType(xsdType) {
+		return "tns:" + models.LocalName(xsdType)
+	}
+	return "xsd:" + models.LocalName(xsdType)
+}
+
+// findBindingOperation finds the binding and binding operation for an operation
+func (g *Generator) findBindingOperation(def *models.Definitions, opName string) (*models.Binding, *models.BindingOperation) {
+	for i := range def.Bindings {
+		binding := &def.Bindings[i]
+		if binding.HTTPVerb != "" {
+			continue
+		}
+		for j := range binding.Operations {
+			if binding.Operations[j].Name == opName {
+				return binding, &binding.Operations[j]
+			}
+		}
+	}
+	return nil, nil
 }
 
-// Helper methods
+// operationStyle returns the effective SOAP style ("document" or "rpc") of an
+// operation along with its binding operation
+func (g *Generator) operationStyle(def *models.Definitions, opName string) (string, *models.BindingOperation) {
+	binding, bindOp := g.findBindingOperation(def, opName)
+	if bindOp == nil {
+		return "document", nil
+	}
+	if bindOp.Style != "" {
+		return bindOp.Style, bindOp
+	}
+	if binding.Style != "" {
+		return binding.Style, bindOp
+	}
+	return "document", bindOp
+}
 
-func (g *Generator) findMessage(def *models.Definitions, name string) *models.Message {
-	// Remove namespace prefix
-	if idx := strings.LastIndex(name, ":"); idx != -1 {
-		name = name[idx+1:]
+// schemaNamespaces returns the distinct target namespaces of the schemas
+// declaring types and elements
+func schemaNamespaces(def *models.Definitions) map[string]bool {
+	namespaces := make(map[string]bool)
+	for _, t := range def.Types {
+		if t.Namespace != "" {
+			namespaces[t.Namespace] = true
+		}
 	}
+	for _, elem := range def.Elements {
+		if elem.Namespace != "" {
+			namespaces[elem.Namespace] = true
+		}
+	}
+	return namespaces
+}
 
-	for _, msg := range def.Messages {
-		msgName := msg.Name
-		if idx := strings.LastIndex(msgName, ":"); idx != -1 {
-			msgName = msgName[idx+1:]
+// findElement finds a global schema element by qualified name
+func (g *Generator) findElement(def *models.Definitions, name string) *models.Element {
+	name = models.LocalName(name)
+	for i := range def.Elements {
+		if def.Elements[i].Name == name {
+			return &def.Elements[i]
 		}
-		if msgName == name {
-			return &msg
+	}
+	return nil
+}
+
+// findType finds a schema type by qualified name
+func (g *Generator) findType(def *models.Definitions, name string) *models.Type {
+	name = models.LocalName(name)
+	for i := range def.Types {
+		if def.Types[i].Name == name {
+			return &def.Types[i]
 		}
 	}
 	return nil
 }
 
+// findElementType finds the complex type of a global schema element
+func (g *Generator) findElementType(def *models.Definitions, elementName string) *models.Type {
+	elem := g.findElement(def, elementName)
+	if elem == nil {
+		return nil
+	}
+	return g.findType(def, elem.Type)
+}
+
+// partType returns the XSD type of a message part, resolving element parts
+// to the type of the referenced element
+func (g *Generator) partType(def *models.Definitions, part models.Part) string {
+	if part.Type != "" {
+		return part.Type
+	}
+	if elem := g.findElement(def, part.Element); elem != nil {
+		return elem.Type
+	}
+	return "anyType"
+}
+
 func (g *Generator) findServiceEndpoint(def *models.Definitions) string {
-	for _, svc := range def.Services {
-		for _, port := range svc.Ports {
-			if port.Address != "" {
-				return port.Address
+	if port := g.findSOAPPort(def); port != nil {
+		return port.Address
+	}
+	return "http://localhost:8080/service"
+}
+
+// findSOAPPort returns the first port with an address that isn't bound to
+// an HTTP binding, the endpoint the SOAP client calls by default
+func (g *Generator) findSOAPPort(def *models.Definitions) *models.Port {
+	for i := range def.Services {
+		for j := range def.Services[i].Ports {
+			port := &def.Services[i].Ports[j]
+			if binding := g.findBinding(def, port.Binding); port.Address != "" && (binding == nil || binding.HTTPVerb == "") {
+				return port
 			}
 		}
 	}
-	return "http://localhost:8080/service"
+	return nil
+}
+
+// findSOAPEndpoints returns the distinct addresses of the SOAP ports of
+// the service of the default port whose bindings speak the client's SOAP
+// version, the default port first
+func (g *Generator) findSOAPEndpoints(def *models.Definitions) []string {
+	first := g.findSOAPPort(def)
+	if first == nil {
+		return nil
+	}
+	version := g.findSOAPVersion(def)
+	endpoints := []string{first.Address}
+	seen := map[string]bool{first.Address: true}
+	for i := range def.Services {
+		service := &def.Services[i]
+		if !containsPort(service, first) {
+			continue
+		}
+		for _, port := range service.Ports {
+			binding := g.findBinding(def, port.Binding)
+			if port.Address == "" || seen[port.Address] || binding != nil && (binding.HTTPVerb != "" || binding.SOAPVersion != "" && binding.SOAPVersion != version) {
+				continue
+			}
+			seen[port.Address] = true
+			endpoints = append(endpoints, port.Address)
+		}
+	}
+	return endpoints
+}
+
+// containsPort reports whether port is one of the ports of service
+func containsPort(service *models.Service, port *models.Port) bool {
+	for i := range service.Ports {
+		if &service.Ports[i] == port {
+			return true
+		}
+	}
+	return false
+}
+
+// findBinding finds a binding by qualified name
+func (g *Generator) findBinding(def *models.Definitions, name string) *models.Binding {
+	name = models.LocalName(name)
+	for i := range def.Bindings {
+		if def.Bindings[i].Name == name {
+			return &def.Bindings[i]
+		}
+	}
+	return nil
 }
 
-func (g *Generator) generateParams(msg *models.Message) string {
-	var params []string
-	for _, part := range msg.Parts {
-		fieldName := strings.ToLower(string(part.Name[0])) + part.Name[1:]
-		fieldType := mapXSDTypeToGo(part.Type)
-		params = append(params, fmt.Sprintf("%s %s", fieldName, fieldType))
+// findSOAPVersion returns the SOAP version set with SetSOAPVersion, or else
+// the version of the binding of the endpoint the client calls by default
+func (g *Generator) findSOAPVersion(def *models.Definitions) string {
+	if g.soapVersion != "" {
+		return g.soapVersion
+	}
+	if port := g.findSOAPPort(def); port != nil {
+		if binding := g.findBinding(def, port.Binding); binding != nil && binding.SOAPVersion != "" {
+			return binding.SOAPVersion
+		}
+	}
+	return "1.1"
+}
+
+// operator describes the Go signature of the operator of an operation. It
+// takes the request struct and returns the response struct, unless the
+// operation is wrapped document/literal and its parameters are flattened.
+// readPrefixes are the operation name prefixes of operations that only
+// read, whose responses generated clients may cache
+var readPrefixes = []string{
+	"get", "list", "find", "search", "query", "read", "fetch", "lookup",
+	"check", "count", "consulta", "consult", "is", "has",
+}
+
+// idempotent reports whether an operation name starts with a read prefix
+// followed by a new word, as in getUser or Get_User
+func idempotent(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range readPrefixes {
+		if !strings.HasPrefix(lower, prefix) {
+			continue
+		}
+		rest := []rune(name[len(prefix):])
+		if len(rest) == 0 || !unicode.IsLower(rest[0]) {
+			return true
+		}
 	}
-	return strings.Join(params, ", ")
+	return false
 }
 
-func (g *Generator) generateInputStruct(msg *models.Message, targetNS string) string {
+type operator struct {
+	params []string // parameters after ctx, as "name Type"
+	args   []string // names of the parameters
+	result string   // Go type of the result
+	zero   string   // value of the result returned with errors
+	// prologue declares the request variable sent by the operator, and
+	// response is the result expression built from the response variable
+	prologue string
+	response string
+}
+
+// newOperator returns the operator of op. Wrapped document/literal
+// operations, whose input is an element named after the operation holding
+// a sequence of child elements, take the children as parameters; the
+// result is the only child of the output element when it has one.
+func (g *Generator) newOperator(def *models.Definitions, op models.Operation, methodName string) operator {
+	requestType := methodName + "Request"
+	o := operator{
+		params:   []string{"request *" + requestType},
+		args:     []string{"request"},
+		result:   "*" + methodName + "Response",
+		zero:     "nil",
+		prologue: fmt.Sprintf("\tif request == nil {\n\t\trequest = &%s{}\n\t}\n", requestType),
+		response: "&response",
+	}
+
+	if style, _ := g.operationStyle(def, op.Name); style == "rpc" {
+		return o
+	}
+	input := g.wrapperType(def, op.Input.Name, op.Name)
+	if input == nil {
+		return o
+	}
+
+	// Types from other packages would need imports in operators.go
+	ctg := g.newComplexTypeGenerator("")
+	ctg.SetElements(def.Elements)
+	ctg.SetTypes(def.Types)
+	paramTypes := make([]string, len(input.Elements))
+	for i, elem := range input.Elements {
+		if paramTypes[i] = ctg.getFieldType(*input, elem); strings.Contains(paramTypes[i], ".") {
+			return o
+		}
+	}
+
+	fieldNames, _, _ := ctg.fieldNames(requestType, *input)
+	paramNames := elementParamNames(requestType, input.Elements)
 	var fields []string
-	for _, part := range msg.Parts {
-		fieldName := toPascalCase(part.Name)
-		value := strings.ToLower(string(part.Name[0])) + part.Name[1:]
-		fields = append(fields, fmt.Sprintf("%s: %s", fieldName, value))
+	o.params, o.args = nil, paramNames
+	for i := range input.Elements {
+		o.params = append(o.params, paramNames[i]+" "+paramTypes[i])
+		fields = append(fields, fieldNames[i]+": "+paramNames[i])
 	}
-	return fmt.Sprintf("&%sRequest{%s}", toPascalCase(msg.Name), strings.Join(fields, ", "))
+	o.prologue = fmt.Sprintf("\trequest := &%s{%s}\n", requestType, strings.Join(fields, ", "))
+
+	if output := g.wrapperType(def, op.Output.Name, ""); output != nil && len(output.Elements) == 1 {
+		if result := ctg.getFieldType(*output, output.Elements[0]); !strings.Contains(result, ".") {
+			names, _, _ := ctg.fieldNames(methodName+"Response", *output)
+			o.result = result
+			o.zero = g.zeroValue(def, result)
+			o.response = "response." + names[0]
+		}
+	}
+	return o
 }
 
-func (g *Generator) generateOutputField(msg *models.Message) string {
-	if len(msg.Parts) > 0 {
-		return mapXSDTypeToGo(msg.Parts[0].Type)
+// wrapperType returns the complex type of the element a message wraps its
+// content in: the element of its only part, named elementName unless that
+// is empty, with a plain sequence of child elements. It returns nil for
+// messages in any other form.
+func (g *Generator) wrapperType(def *models.Definitions, messageName, elementName string) *models.Type {
+	msg := def.Message(messageName)
+	if msg == nil || len(msg.Parts) != 1 || msg.Parts[0].Element == "" {
+		return nil
+	}
+	if elementName != "" && models.LocalName(msg.Parts[0].Element) != elementName {
+		return nil
+	}
+	t := g.findElementType(def, msg.Parts[0].Element)
+	if t == nil || len(t.Attributes) > 0 || len(t.Choices) > 0 || t.Any {
+		return nil
 	}
-	return "interface{}"
+	return t
 }
 
-func (g *Generator) getZeroValue(typeName string) string {
-	switch typeName {
+// zeroValue returns the zero value of a Go type used by operators
+func (g *Generator) zeroValue(def *models.Definitions, goType string) string {
+	if strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") {
+		return "nil"
+	}
+	switch goType {
 	case "string":
 		return "\"\""
-	case "int", "int64", "int32", "int16":
-		return "0"
-	case "float32", "float64":
-		return "0.0"
 	case "bool":
 		return "false"
-	default:
-		return "nil"
+	case "int", "int64", "int32", "int16", "byte", "uint64", "uint32", "uint16", "uint8", "float32", "float64":
+		return "0"
+	}
+	// Simple types are named basic types, everything else is a struct
+	for _, st := range def.SimpleTypes {
+		if toPascalCase(st.Name) == goType {
+			return g.zeroValue(def, g.goType(st.Base))
+		}
+	}
+	return goType + "{}"
+}
+
+// signature returns the parameter list of the operator, with a leading ctx
+// parameter for the Context variant
+func (o operator) signature(withContext bool) string {
+	if withContext {
+		return strings.Join(append([]string{"ctx context.Context"}, o.params...), ", ")
+	}
+	return strings.Join(o.params, ", ")
+}
+
+// partFieldNames returns the struct field names of a message's parts,
+// unique within the struct and distinct from the reserved names
+func partFieldNames(msg *models.Message, reserved ...string) []string {
+	namer := naming.NewNamer(msg.Name+" field", naming.Pascal)
+	namer.Reserve("XMLName")
+	namer.Reserve(reserved...)
+
+	names := make([]string, len(msg.Parts))
+	for i, part := range msg.Parts {
+		names[i] = namer.Next(part.Name)
+	}
+	return names
+}
+
+// elementParamNames returns the operator parameter names of a wrapper
+// element's children, distinct from the identifiers operators use
+func elementParamNames(structName string, elements []models.Element) []string {
+	namer := naming.NewNamer(structName+" parameter", naming.Param)
+	namer.Reserve("c", "f", "ctx", "request", "response", "err", "nil", "context", "errors", "fmt")
+
+	names := make([]string, len(elements))
+	for i, elem := range elements {
+		names[i] = namer.Next(elem.Name)
 	}
+	return names
 }
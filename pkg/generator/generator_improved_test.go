@@ -0,0 +1,241 @@
+package generator
+
+import "testing"
+
+// rpcWSDL is an rpc/literal service whose Divide operation has messages
+// of two parts
+const rpcWSDL = `<?xml version="1.0" encoding="utf-8"?>
+<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"
+             xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+             xmlns:xs="http://www.w3.org/2001/XMLSchema"
+             xmlns:tns="urn:test"
+             name="Test" targetNamespace="urn:test">
+  <message name="DivideIn"><part name="a" type="xs:int"/><part name="b" type="xs:int"/></message>
+  <message name="DivideOut"><part name="quotient" type="xs:int"/><part name="remainder" type="xs:int"/></message>
+  <portType name="TestPort">
+    <operation name="Divide"><input message="tns:DivideIn"/><output message="tns:DivideOut"/></operation>
+  </portType>
+  <binding name="TestBinding" type="tns:TestPort">
+    <soap:binding style="rpc" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <operation name="Divide">
+      <soap:operation soapAction="urn:test#Divide"/>
+      <input><soap:body use="literal" namespace="urn:test"/></input>
+      <output><soap:body use="literal" namespace="urn:test"/></output>
+    </operation>
+  </binding>
+  <service name="Test">
+    <port name="TestPort" binding="tns:TestBinding"><soap:address location="http://test.example.com/soap"/></port>
+  </service>
+</definitions>`
+
+func TestGenerateOperators(t *testing.T) {
+	docLiteral := testWSDL(`<xs:complexType name="Money"><xs:sequence>
+        <xs:element name="amount" type="xs:decimal"/>
+      </xs:sequence></xs:complexType>
+      <xs:simpleType name="Status"><xs:restriction base="xs:string">
+        <xs:enumeration value="open"/>
+      </xs:restriction></xs:simpleType>
+      <xs:element name="Stats"><xs:complexType><xs:sequence>
+        <xs:element name="from" type="xs:string"/>
+      </xs:sequence><xs:attribute name="version" type="xs:int"/></xs:complexType></xs:element>
+      <xs:element name="StatsResponse"><xs:complexType><xs:sequence>
+        <xs:element name="count" type="xs:int"/><xs:element name="ok" type="xs:boolean"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetName"><xs:complexType><xs:sequence/></xs:complexType></xs:element>
+      <xs:element name="GetNameResponse"><xs:complexType><xs:sequence>
+        <xs:element name="name" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetPrice"><xs:complexType><xs:sequence/></xs:complexType></xs:element>
+      <xs:element name="GetPriceResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="tns:Money"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetStatus"><xs:complexType><xs:sequence/></xs:complexType></xs:element>
+      <xs:element name="GetStatusResponse"><xs:complexType><xs:sequence>
+        <xs:element name="status" type="tns:Status"/>
+      </xs:sequence></xs:complexType></xs:element>`, "Stats", "GetName", "GetPrice", "GetStatus")
+
+	tests := []struct {
+		name string
+		wsdl string
+		file string
+		want []string
+	}{
+		{
+			name: "multi-part messages",
+			wsdl: rpcWSDL,
+			file: "operators.go",
+			want: []string{
+				"func (c *Client) Divide(request *DivideRequest) (*DivideResponse, error) {",
+				"if request == nil {\n\t\trequest = &DivideRequest{}\n\t}",
+				`return nil, fmt.Errorf("failed to execute Divide: %w", err)`,
+				"return &response, nil",
+			},
+		},
+		{
+			name: "flattened parts",
+			wsdl: rpcWSDL,
+			file: "types.go",
+			want: []string{
+				"type DivideResponse struct {",
+				"Quotient  int      `xml:\"quotient\" json:\"quotient\"`",
+				"Remainder int      `xml:\"remainder\" json:\"remainder\"`",
+			},
+		},
+		{
+			name: "typed request and response structs",
+			wsdl: docLiteral,
+			file: "operators.go",
+			want: []string{
+				"func (c *Client) Stats(request *StatsRequest) (*StatsResponse, error) {",
+				`err := c.CallContext(ctx, "urn:test#Stats", request, &response)`,
+			},
+		},
+		{
+			name: "zero values",
+			wsdl: docLiteral,
+			file: "operators.go",
+			want: []string{
+				"func (c *Client) GetName() (string, error) {",
+				`return "", fmt.Errorf("failed to execute GetName: %w", err)`,
+				"return response.Name, nil",
+				"func (c *Client) GetPrice() (Money, error) {",
+				`return Money{}, fmt.Errorf("failed to execute GetPrice: %w", err)`,
+				"func (c *Client) GetStatus() (Status, error) {",
+				`return "", fmt.Errorf("failed to execute GetStatus: %w", err)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, render(t, tt.wsdl, nil), tt.file, tt.want)
+		})
+	}
+}
+
+func TestWrappedOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		op       string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name: "children as parameters",
+			schema: `<xs:element name="Add"><xs:complexType><xs:sequence>
+        <xs:element name="intA" type="xs:int"/><xs:element name="intB" type="xs:int"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="AddResponse"><xs:complexType><xs:sequence>
+        <xs:element name="AddResult" type="xs:int"/>
+      </xs:sequence></xs:complexType></xs:element>`,
+			op: "Add",
+			want: []string{
+				"Add(intA int, intB int) (int, error)",
+				"AddContext(ctx context.Context, intA int, intB int) (int, error)",
+				"request := &AddRequest{IntA: intA, IntB: intB}",
+				"return response.AddResult, nil",
+			},
+			unwanted: []string{"parameters string"},
+		},
+		{
+			name: "parameters named like the operator's identifiers",
+			schema: `<xs:element name="Find"><xs:complexType><xs:sequence>
+        <xs:element name="request" type="xs:string"/><xs:element name="ctx" type="xs:int"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="FindResponse"><xs:complexType><xs:sequence>
+        <xs:element name="found" type="xs:boolean"/>
+      </xs:sequence></xs:complexType></xs:element>`,
+			op: "Find",
+			want: []string{
+				"Find(request2 string, ctx2 int) (bool, error)",
+				"request := &FindRequest{Request: request2, Ctx: ctx2}",
+			},
+		},
+		{
+			name: "repeated children",
+			schema: `<xs:element name="Tag"><xs:complexType><xs:sequence>
+        <xs:element name="tags" type="xs:string" maxOccurs="unbounded"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="TagResponse"><xs:complexType><xs:sequence>
+        <xs:element name="ids" type="xs:int" maxOccurs="unbounded"/>
+      </xs:sequence></xs:complexType></xs:element>`,
+			op: "Tag",
+			want: []string{
+				"Tag(tags []string) ([]int, error)",
+				`return nil, fmt.Errorf("failed to execute Tag: %w", err)`,
+			},
+		},
+		{
+			name: "wrappers with attributes aren't flattened",
+			schema: `<xs:element name="Get"><xs:complexType><xs:sequence>
+        <xs:element name="id" type="xs:int"/>
+      </xs:sequence><xs:attribute name="version" type="xs:int"/></xs:complexType></xs:element>
+      <xs:element name="GetResponse"><xs:complexType><xs:sequence>
+        <xs:element name="name" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>`,
+			op:       "Get",
+			want:     []string{"Get(request *GetRequest) (*GetResponse, error)"},
+			unwanted: []string{"Get(id int)"},
+		},
+		{
+			name: "choices aren't flattened",
+			schema: `<xs:element name="Pay"><xs:complexType><xs:choice>
+        <xs:element name="card" type="xs:string"/><xs:element name="iban" type="xs:string"/>
+      </xs:choice></xs:complexType></xs:element>
+      <xs:element name="PayResponse"><xs:complexType><xs:sequence>
+        <xs:element name="ok" type="xs:boolean"/>
+      </xs:sequence></xs:complexType></xs:element>`,
+			op:   "Pay",
+			want: []string{"Pay(request *PayRequest) (*PayResponse, error)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, render(t, testWSDL(tt.schema, tt.op), nil), "operators.go", tt.want, tt.unwanted...)
+		})
+	}
+}
+
+func TestGenerateContextVariants(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="GetQuote"><xs:complexType><xs:sequence>
+        <xs:element name="symbol" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetQuoteResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="xs:double"/>
+      </xs:sequence></xs:complexType></xs:element>`, "GetQuote")
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "operators",
+			file: "operators.go",
+			want: []string{
+				"func (c *Client) GetQuote(symbol string) (float64, error) {\n\treturn c.GetQuoteContext(context.Background(), symbol)\n}",
+				"// GetQuoteContext is like GetQuote but uses ctx for cancellation and timeouts",
+				"func (c *Client) GetQuoteContext(ctx context.Context, symbol string) (float64, error) {",
+				`err := c.CallContext(ctx, "urn:test#GetQuote", request, &response)`,
+			},
+		},
+		{
+			name: "client",
+			file: "client.go",
+			want: []string{
+				"return c.CallContext(context.Background(), soapAction, request, response)",
+				"func (c *Client) CallContext(ctx context.Context, soapAction string, request, response interface{}) error {",
+				`http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(requestBody))`,
+			},
+		},
+	}
+
+	files := render(t, wsdl, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, files, tt.file, tt.want)
+		})
+	}
+}
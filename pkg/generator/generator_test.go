@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	wsdlparser "github.com/thdev01/wsdl2api/pkg/parser"
+)
+
+// testWSDL returns a document/literal WSDL in the urn:test namespace with
+// the schema content schema and an operation per name in ops, whose input
+// and output are the elements <op> and <op>Response
+func testWSDL(schema string, ops ...string) string {
+	var messages, operations, bindings strings.Builder
+	for _, op := range ops {
+		fmt.Fprintf(&messages, `<message name="%[1]sIn"><part name="parameters" element="tns:%[1]s"/></message>
+  <message name="%[1]sOut"><part name="parameters" element="tns:%[1]sResponse"/></message>
+  `, op)
+		fmt.Fprintf(&operations, `<operation name="%[1]s"><input message="tns:%[1]sIn"/><output message="tns:%[1]sOut"/></operation>
+    `, op)
+		fmt.Fprintf(&bindings, `<operation name="%[1]s">
+      <soap:operation soapAction="urn:test#%[1]s"/>
+      <input><soap:body use="literal"/></input>
+      <output><soap:body use="literal"/></output>
+    </operation>
+    `, op)
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"
+             xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+             xmlns:xs="http://www.w3.org/2001/XMLSchema"
+             xmlns:tns="urn:test"
+             name="Test" targetNamespace="urn:test">
+  <types>
+    <xs:schema targetNamespace="urn:test" elementFormDefault="qualified">
+      %s
+    </xs:schema>
+  </types>
+  %s
+  <portType name="TestPort">
+    %s
+  </portType>
+  <binding name="TestBinding" type="tns:TestPort">
+    <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    %s
+  </binding>
+  <service name="Test">
+    <port name="TestPort" binding="tns:TestBinding"><soap:address location="http://test.example.com/soap"/></port>
+  </service>
+</definitions>`, schema, messages.String(), operations.String(), bindings.String())
+}
+
+// render generates the code of a WSDL document in memory with the
+// generator configure adjusts, and returns the files by path
+func render(t *testing.T, wsdl string, configure func(g *Generator)) map[string]string {
+	t.Helper()
+	def, err := wsdlparser.NewParser().ParseReader(strings.NewReader(wsdl))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	g := NewGenerator(t.TempDir(), "client")
+	if configure != nil {
+		configure(g)
+	}
+	if err := g.render(def, g.options); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	files := make(map[string]string)
+	for name, data := range g.out.files {
+		files[name] = string(data)
+	}
+	for name, data := range g.out.scaffold {
+		files[name] = string(data)
+	}
+	return files
+}
+
+// assertContains fails the test for each of want missing from the file
+// name of files, and for each of unwanted present in it
+func assertContains(t *testing.T, files map[string]string, name string, want []string, unwanted ...string) {
+	t.Helper()
+	code, ok := files[name]
+	if !ok {
+		t.Fatalf("%s was not generated, only %v", name, sortedKeys(files))
+	}
+	for _, s := range want {
+		if !strings.Contains(code, s) {
+			t.Errorf("%s lacks %q:\n%s", name, s, code)
+		}
+	}
+	for _, s := range unwanted {
+		if strings.Contains(code, s) {
+			t.Errorf("%s contains %q", name, s)
+		}
+	}
+}
+
+// structTags returns the names in the key tags, such as json, of the fields
+// of the struct typeName declared in the Go source code, sorted, leaving
+// out ignored fields
+func structTags(t *testing.T, code, typeName, key string) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "types.go", code, 0)
+	if err != nil {
+		t.Fatalf("generated code doesn't parse: %v", err)
+	}
+	var tags []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != typeName {
+			return true
+		}
+		for _, field := range spec.Type.(*ast.StructType).Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			if value, ok := reflect.StructTag(tag).Lookup(key); ok && value != "-" {
+				tags = append(tags, strings.Split(value, ",")[0])
+			}
+		}
+		return false
+	})
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,285 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// httpClientCode holds the helpers shared by the clients of HTTP bindings
+const httpClientCode = `// callHTTP sends a request of an HTTP binding and decodes the XML response
+// into result. GET requests carry the values in the query string, POST
+// requests in a form body.
+func callHTTP(ctx context.Context, client *http.Client, method, endpoint string, values url.Values, result interface{}) error {
+	var body io.Reader
+	if method == http.MethodGet {
+		if len(values) > 0 {
+			endpoint += "?" + values.Encode()
+		}
+	} else {
+		body = strings.NewReader(values.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := xml.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// formatHTTPValue formats a parameter of an HTTP binding request, using the
+// XSD text form of types that have one
+func formatHTTPValue(v interface{}) string {
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v)
+}
+`
+
+// generateHTTPClients generates a client for each wsdl:binding using
+// http:binding, calling the operations with plain GET or POST requests
+// instead of SOAP envelopes. Nothing is written without HTTP bindings.
+func (g *Generator) generateHTTPClients(def *models.Definitions) error {
+	var b strings.Builder
+
+	// Parameters and results may have mapped types from other packages
+	ctg := g.newComplexTypeGenerator("")
+	for _, binding := range def.Bindings {
+		if binding.HTTPVerb == "" {
+			continue
+		}
+		b.WriteString(g.generateHTTPClient(def, ctg, binding))
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
+	header.WriteString("import (\n")
+	std := map[string]bool{}
+	for _, path := range []string{"context", "encoding", "encoding/xml", "fmt", "io", "net/http", "net/url", "strings"} {
+		std[path] = true
+		header.WriteString(fmt.Sprintf("\t%q\n", path))
+	}
+	for _, path := range ctg.MappedImports() {
+		if !std[path] {
+			header.WriteString(fmt.Sprintf("\t%q\n", path))
+		}
+	}
+	header.WriteString(")\n\n")
+
+	return g.writeGoFile("http_client.go", header.String()+b.String()+"\n"+httpClientCode)
+}
+
+// generateHTTPClient generates the client type of an HTTP binding with a
+// method per bound operation
+func (g *Generator) generateHTTPClient(def *models.Definitions, ctg *ComplexTypeGenerator, binding models.Binding) string {
+	var b strings.Builder
+
+	typeName := toPascalCase(binding.Name) + "Client"
+	address := ""
+	for _, svc := range def.Services {
+		for _, port := range svc.Ports {
+			if address == "" && models.LocalName(port.Binding) == binding.Name {
+				address = port.Address
+			}
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("// %s calls the operations of the %s binding with HTTP %s requests\n", typeName, binding.Name, binding.HTTPVerb))
+	b.WriteString(fmt.Sprintf("type %s struct {\n", typeName))
+	b.WriteString("\tURL        string\n")
+	b.WriteString("\tHTTPClient *http.Client\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString(fmt.Sprintf("// New%s creates a client of the %s binding. An empty url uses the address from the WSDL.\n", typeName, binding.Name))
+	b.WriteString(fmt.Sprintf("func New%s(url string) *%s {\n", typeName, typeName))
+	b.WriteString("\tif url == \"\" {\n")
+	b.WriteString(fmt.Sprintf("\t\turl = %q\n", address))
+	b.WriteString("\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn &%s{URL: strings.TrimSuffix(url, \"/\"), HTTPClient: &http.Client{}}\n", typeName))
+	b.WriteString("}\n\n")
+
+	var portType *models.PortType
+	for i := range def.PortTypes {
+		if def.PortTypes[i].Name == models.LocalName(binding.Type) {
+			portType = &def.PortTypes[i]
+		}
+	}
+
+	methods := naming.NewNamer(binding.Name+" operation", naming.Pascal)
+	methods.Reserve("URL", "HTTPClient")
+	for _, bindOp := range binding.Operations {
+		var op *models.Operation
+		if portType != nil {
+			for i := range portType.Operations {
+				if portType.Operations[i].Name == bindOp.Name {
+					op = &portType.Operations[i]
+				}
+			}
+		}
+		if op == nil {
+			continue
+		}
+		b.WriteString(g.generateHTTPMethod(def, ctg, binding, bindOp, *op, typeName, methods.Next(op.Name)))
+	}
+
+	return b.String()
+}
+
+// generateHTTPMethod generates the method calling an operation of an HTTP
+// binding. Input parts become parameters sent as query or form values, or
+// substituted into the location for urlReplacement; the first output part
+// is decoded from the XML response.
+func (g *Generator) generateHTTPMethod(def *models.Definitions, ctg *ComplexTypeGenerator, binding models.Binding, bindOp models.BindingOperation, op models.Operation, typeName, methodName string) string {
+	var b strings.Builder
+
+	var params, paramNames []string
+	var parts []models.Part
+	if inputMsg := def.Message(op.Input.Name); inputMsg != nil {
+		parts = inputMsg.Parts
+		paramNames = httpParamNames(inputMsg)
+		for i, part := range parts {
+			params = append(params, fmt.Sprintf("%s %s", paramNames[i], ctg.goType(g.partType(def, part))))
+		}
+	}
+
+	// The result is the first output part: complex types are decoded from
+	// the response element, simple ones from its text
+	resultType, complexResult := "", false
+	if outputMsg := def.Message(op.Output.Name); outputMsg != nil && len(outputMsg.Parts) > 0 {
+		xsdType := g.partType(def, outputMsg.Parts[0])
+		resultType = ctg.goType(xsdType)
+		complexResult = g.findType(def, xsdType) != nil
+	}
+
+	replaced := make(map[string]string)
+	if bindOp.Input.Encoding == "urlReplacement" {
+		for i, part := range parts {
+			replaced[part.Name] = paramNames[i]
+		}
+	}
+	endpoint := httpEndpoint(bindOp.Location, replaced)
+
+	b.WriteString(fmt.Sprintf("// %s calls the %s operation with an HTTP %s request\n", methodName, op.Name, binding.HTTPVerb))
+	if op.Documentation != "" {
+		b.WriteString(wrapComment("// ", op.Documentation))
+	}
+	signature := strings.Join(append([]string{"ctx context.Context"}, params...), ", ")
+	if resultType == "" {
+		b.WriteString(fmt.Sprintf("func (c *%s) %s(%s) error {\n", typeName, methodName, signature))
+	} else {
+		b.WriteString(fmt.Sprintf("func (c *%s) %s(%s) (%s, error) {\n", typeName, methodName, signature, resultType))
+	}
+
+	b.WriteString("\trequest := url.Values{}\n")
+	for i, part := range parts {
+		if _, ok := replaced[part.Name]; ok && strings.Contains(bindOp.Location, "("+part.Name+")") {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\trequest.Set(%q, formatHTTPValue(%s))\n", part.Name, paramNames[i]))
+	}
+	b.WriteString("\n")
+
+	call := fmt.Sprintf("callHTTP(ctx, c.HTTPClient, %q, %s, request, %%s)", binding.HTTPVerb, endpoint)
+	switch {
+	case resultType == "":
+		b.WriteString(fmt.Sprintf("\tif err := %s; err != nil {\n", fmt.Sprintf(call, "nil")))
+		b.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to execute %s: %%w\", err)\n", op.Name))
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn nil\n")
+	case complexResult:
+		b.WriteString(fmt.Sprintf("\tvar response %s\n", resultType))
+		b.WriteString(fmt.Sprintf("\tif err := %s; err != nil {\n", fmt.Sprintf(call, "&response")))
+		b.WriteString(fmt.Sprintf("\t\treturn response, fmt.Errorf(\"failed to execute %s: %%w\", err)\n", op.Name))
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn response, nil\n")
+	default:
+		b.WriteString("\tvar response struct {\n")
+		b.WriteString(fmt.Sprintf("\t\tValue %s `xml:\",chardata\"`\n", resultType))
+		b.WriteString("\t}\n")
+		b.WriteString(fmt.Sprintf("\tif err := %s; err != nil {\n", fmt.Sprintf(call, "&response")))
+		b.WriteString(fmt.Sprintf("\t\treturn response.Value, fmt.Errorf(\"failed to execute %s: %%w\", err)\n", op.Name))
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn response.Value, nil\n")
+	}
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// httpEndpoint returns the Go expression of an operation's URL. The
+// (part) placeholders of urlReplacement locations are replaced with the
+// escaped values of the parameters in replaced, keyed by part name.
+func httpEndpoint(location string, replaced map[string]string) string {
+	exprs := []string{"c.URL"}
+	literal := ""
+	for len(location) > 0 {
+		start := strings.Index(location, "(")
+		end := strings.Index(location, ")")
+		if start == -1 || end < start {
+			literal += location
+			break
+		}
+		param, ok := replaced[location[start+1:end]]
+		if !ok {
+			literal += location[:end+1]
+			location = location[end+1:]
+			continue
+		}
+		literal += location[:start]
+		if literal != "" {
+			exprs = append(exprs, fmt.Sprintf("%q", literal))
+			literal = ""
+		}
+		exprs = append(exprs, fmt.Sprintf("url.PathEscape(formatHTTPValue(%s))", param))
+		location = location[end+1:]
+	}
+	if literal != "" {
+		exprs = append(exprs, fmt.Sprintf("%q", literal))
+	}
+	return strings.Join(exprs, " + ")
+}
+
+// httpParamNames returns the Go parameter names of the parts of an HTTP
+// binding request. They don't shadow the receiver, locals and packages used
+// by the generated methods.
+func httpParamNames(msg *models.Message) []string {
+	namer := naming.NewNamer(msg.Name+" parameter", naming.Param)
+	namer.Reserve("c", "ctx", "request", "response", "err", "context", "encoding", "fmt", "http", "url", "strings")
+
+	names := make([]string, len(msg.Parts))
+	for i, part := range msg.Parts {
+		names[i] = namer.Next(part.Name)
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+const (
+	// JSONNamingXML names JSON fields after their elements, like the
+	// properties of the exported OpenAPI spec and the REST proxy's JSON
+	JSONNamingXML = "xml"
+	// JSONNamingCamel names JSON fields in lower camel case
+	JSONNamingCamel = "camel"
+)
+
+// JSON names of the character data of simple and mixed content, the
+// prefix of attributes, the xsi:type of derived types and the name of
+// substitution group members, as in the REST proxy's JSON
+const (
+	jsonText            = "#text"
+	jsonAttributePrefix = "@"
+	jsonXSIType         = "@xsi:type"
+	jsonElement         = "#element"
+)
+
+// jsonPropertyCode is the helper the JSON methods of holders use to add
+// their discriminator
+const jsonPropertyCode = `// withJSONProperty adds a string property to the JSON object in data
+func withJSONProperty(data []byte, name, value string) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	property, _ := json.Marshal(map[string]string{name: value})
+	if len(data) == 2 {
+		return property
+	}
+	return append(append(property[:len(property)-1], ','), data[1:]...)
+}
+
+`
+
+// SetJSONNaming sets the naming of the json tags of generated fields, one
+// of the JSONNaming constants
+func (ctg *ComplexTypeGenerator) SetJSONNaming(jsonNaming string) {
+	ctg.jsonNaming = jsonNaming
+}
+
+// jsonNames returns the JSON names of a type's elements and attributes.
+// encoding/json matches names case-insensitively, so names equal but for
+// case are numbered like Go field names.
+func (ctg *ComplexTypeGenerator) jsonNames(t models.Type) ([]string, []string) {
+	convert := func(name string) string { return name }
+	if ctg.jsonNaming == JSONNamingCamel {
+		convert = naming.Camel
+	}
+
+	elements := naming.NewNamer("JSON name", convert)
+	if contentField(t) != "" {
+		elements.Reserve(jsonText)
+	}
+	elemNames := make([]string, len(t.Elements))
+	for i, elem := range t.Elements {
+		elemNames[i] = elements.Next(elem.Name)
+	}
+
+	attributes := naming.NewNamer("JSON name", convert)
+	attrNames := make([]string, len(t.Attributes))
+	for i, attr := range t.Attributes {
+		attrNames[i] = jsonAttributePrefix + attributes.Next(attr.Name)
+	}
+	return elemNames, attrNames
+}
+
+// jsonName returns the JSON name of a message part
+func (ctg *ComplexTypeGenerator) jsonName(name string) string {
+	if ctg.jsonNaming == JSONNamingCamel {
+		return naming.Camel(name)
+	}
+	return name
+}
+
+// GenerateJSONHelpers generates the helpers of the JSON methods of holders,
+// if any holder is generated. It must be called after GenerateHolders and
+// GenerateSubstitutionGroups.
+func (ctg *ComplexTypeGenerator) GenerateJSONHelpers() string {
+	if !ctg.usesJSONProperty {
+		return ""
+	}
+	return jsonPropertyCode
+}
+
+// jsonTag returns the json tag following the xml tag of a field
+func jsonTag(name string, omitempty bool) string {
+	if omitempty {
+		name += ",omitempty"
+	}
+	return fmt.Sprintf(" json:%q", name)
+}
+
+// SetJSONNaming sets the naming of the json tags of generated fields, one
+// of the JSONNaming constants. The default is JSONNamingXML.
+func (g *Generator) SetJSONNaming(jsonNaming string) {
+	g.jsonNaming = jsonNaming
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+	wsdlparser "github.com/thdev01/wsdl2api/pkg/parser"
+)
+
+// jsonSchema declares types with elements, attributes, simple and mixed
+// content
+const jsonSchema = `
+      <xs:complexType name="Money">
+        <xs:simpleContent><xs:extension base="xs:decimal">
+          <xs:attribute name="CurrencyCode" type="xs:string" use="required"/>
+        </xs:extension></xs:simpleContent>
+      </xs:complexType>
+      <xs:complexType name="Note" mixed="true">
+        <xs:sequence><xs:element name="b" type="xs:string" minOccurs="0"/></xs:sequence>
+      </xs:complexType>
+      <xs:element name="GetQuote">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="symbol" type="xs:string"/>
+            <xs:element name="limit" type="tns:Money" minOccurs="0"/>
+            <xs:element name="note" type="tns:Note" minOccurs="0"/>
+          </xs:sequence>
+          <xs:attribute name="version" type="xs:int"/>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="GetQuoteResponse">
+        <xs:complexType><xs:sequence><xs:element name="price" type="tns:Money"/></xs:sequence></xs:complexType>
+      </xs:element>`
+
+func TestJSONTags(t *testing.T) {
+	wsdl := testWSDL(jsonSchema, "GetQuote")
+	def, err := wsdlparser.NewParser().ParseReader(strings.NewReader(wsdl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := exporter.ConvertWSDLToOpenAPI(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := spec.Paths["/api/GetQuote"].Post.RequestSchema()
+	types := render(t, wsdl, nil)["types.go"]
+
+	// The json tags are the property names of the OpenAPI schemas
+	for typeName, schema := range map[string]*exporter.OpenAPISchema{
+		"GetQuoteRequest": request,
+		"Money":           request.Properties["limit"],
+		"Note":            request.Properties["note"],
+	} {
+		var want []string
+		for name := range schema.Properties {
+			want = append(want, name)
+		}
+		sort.Strings(want)
+		if got := structTags(t, types, typeName, "json"); !reflect.DeepEqual(got, want) {
+			t.Errorf("json tags of %s = %v, want the OpenAPI properties %v", typeName, got, want)
+		}
+	}
+
+	// Camel case naming keeps the prefixes
+	types = render(t, wsdl, func(g *Generator) { g.SetJSONNaming(JSONNamingCamel) })["types.go"]
+	if got, want := structTags(t, types, "Money", "json"), []string{"#text", "@currencyCode"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("camel case json tags of Money = %v, want %v", got, want)
+	}
+	if got, want := structTags(t, types, "GetQuoteRequest", "json"), []string{"@version", "limit", "note", "symbol"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("camel case json tags of GetQuoteRequest = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,312 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// ManifestFile is the file of the output directory recording the last
+// generation: hashes of the WSDL and of the generated files, and the
+// options used
+const ManifestFile = "wsdl2api-manifest.json"
+
+// output holds the files of a generation until they are written
+type output struct {
+	files    map[string][]byte // Tracked files by slash-separated path
+	scaffold map[string][]byte // Files written only when missing
+}
+
+func newOutput() *output {
+	return &output{
+		files:    make(map[string][]byte),
+		scaffold: make(map[string][]byte),
+	}
+}
+
+// add adds a tracked file
+func (o *output) add(name string, data []byte) {
+	o.files[name] = data
+}
+
+// names returns the paths of the tracked files in order
+func (o *output) names() []string {
+	return sortedKeys(o.files)
+}
+
+// manifest is the content of ManifestFile
+type manifest struct {
+	Generator string            `json:"generator,omitempty"` // Version of wsdl2api, if known
+	WSDL      string            `json:"wsdl"`                // Hash of the parsed WSDL
+	Options   manifestOptions   `json:"options"`
+	Files     map[string]string `json:"files"` // Hashes by slash-separated path
+}
+
+// manifestOptions are the settings a generation depends on
+type manifestOptions struct {
+	Package       string       `json:"package"`
+	Artifacts     []string     `json:"artifacts"`
+	TimeType      string       `json:"timeType"`
+	DecimalType   string       `json:"decimalType"`
+	JSONNaming    string       `json:"jsonNaming"`
+	SOAPVersion   string       `json:"soapVersion,omitempty"`
+	TypeMapping   *TypeMapping `json:"typeMapping,omitempty"`
+	Split         bool         `json:"split,omitempty"`
+	Module        string       `json:"module,omitempty"`
+	Standalone    bool         `json:"standalone,omitempty"`
+	Deterministic bool         `json:"deterministic,omitempty"`
+	GoGenerate    string       `json:"goGenerate,omitempty"`
+}
+
+// Drift is a file of the output directory that differs from what Generate
+// would write
+type Drift struct {
+	File   string // Slash-separated path in the output directory
+	Reason string // Such as "missing" or "edited since generation"
+}
+
+func (d Drift) String() string {
+	return d.File + ": " + d.Reason
+}
+
+// Check generates the code selected by the generator's options in memory
+// and compares it with the output directory, to detect checked-in code
+// that is out of date with its WSDL without regenerating it. It returns
+// the files Generate would change, none when the output is up to date. The
+// manifest of the last generation tells files edited by hand from outdated
+// ones and lists the files no longer generated. The files of registered
+// artifact generators and the ones only written when missing, such as
+// go.mod, aren't checked.
+func (g *Generator) Check(def *models.Definitions) ([]Drift, error) {
+	if err := g.render(def, g.options); err != nil {
+		return nil, err
+	}
+	previous, err := g.readManifest()
+	if err != nil {
+		return nil, err
+	}
+	current, err := g.newManifest(def, g.options)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []Drift
+	if previous == nil {
+		drift = append(drift, Drift{File: ManifestFile, Reason: "missing"})
+	}
+	for _, name := range g.out.names() {
+		data, err := os.ReadFile(g.path(name))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			drift = append(drift, Drift{File: name, Reason: "missing"})
+		case err != nil:
+			return nil, err
+		case bytes.Equal(data, g.out.files[name]):
+		case previous != nil && previous.Files[name] != "" && previous.Files[name] != hash(data):
+			drift = append(drift, Drift{File: name, Reason: "edited since generation"})
+		default:
+			drift = append(drift, Drift{File: name, Reason: previous.outdated(current)})
+		}
+	}
+	if previous != nil {
+		for _, name := range sortedKeys(previous.Files) {
+			if _, ok := current.Files[name]; ok {
+				continue
+			}
+			if _, err := os.Stat(g.path(name)); err == nil {
+				drift = append(drift, Drift{File: name, Reason: "no longer generated"})
+			}
+		}
+	}
+	return drift, nil
+}
+
+// writeOutput writes the generated files, skipping the ones that didn't
+// change so that their modification times only change with their content.
+// The files of the previous generation that are no longer generated are
+// removed, unless they were edited since, and the manifest is updated.
+func (g *Generator) writeOutput(def *models.Definitions, opts Options) error {
+	previous, err := g.readManifest()
+	if err != nil {
+		return err
+	}
+	current, err := g.newManifest(def, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range g.out.names() {
+		if err := writeIfChanged(g.path(name), g.out.files[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(g.out.scaffold) {
+		path := g.path(name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := os.WriteFile(path, g.out.scaffold[name], 0644); err != nil {
+			return err
+		}
+	}
+
+	if previous != nil {
+		for _, name := range sortedKeys(previous.Files) {
+			if _, ok := current.Files[name]; ok {
+				continue
+			}
+			if err := g.removeStale(name, previous.Files[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeIfChanged(g.path(ManifestFile), append(data, '\n'))
+}
+
+// removeStale removes a file of the previous generation if it still has
+// the hash recorded, and the directories it leaves empty
+func (g *Generator) removeStale(name, sum string) error {
+	path := g.path(name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if hash(data) != sum {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	root := filepath.Clean(g.outputDir)
+	for dir := filepath.Dir(path); dir != root && dir != "."; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// readManifest reads the manifest of the output directory, nil if there
+// is none
+func (g *Generator) readManifest() (*manifest, error) {
+	data, err := os.ReadFile(g.path(ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	m := new(manifest)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	return m, nil
+}
+
+// newManifest returns the manifest of the files in g.out
+func (g *Generator) newManifest(def *models.Definitions, opts Options) (*manifest, error) {
+	// Hash the definitions as generated, so that deterministic output
+	// doesn't change with the order of the WSDL, and the XML formatting
+	// doesn't count
+	wsdl, err := json.Marshal(g.ordered(def))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{
+		Generator: Version(),
+		WSDL:      hash(wsdl),
+		Options: manifestOptions{
+			Package:       g.packageName,
+			Artifacts:     opts.names(),
+			TimeType:      g.timeType,
+			DecimalType:   g.decimalType,
+			JSONNaming:    g.jsonNaming,
+			SOAPVersion:   g.soapVersion,
+			TypeMapping:   g.typeMapping,
+			Split:         g.split,
+			Module:        g.modulePath,
+			Standalone:    g.standalone,
+			Deterministic: g.deterministic,
+			GoGenerate:    g.goGenerate,
+		},
+		Files: make(map[string]string),
+	}
+	for name, data := range g.out.files {
+		m.Files[name] = hash(data)
+	}
+	return m, nil
+}
+
+// outdated describes why the generated files of m differ from the ones of
+// current
+func (m *manifest) outdated(current *manifest) string {
+	switch {
+	case m == nil:
+		return "out of date"
+	case m.WSDL != current.WSDL:
+		return "out of date: the WSDL changed"
+	case !sameJSON(m.Options, current.Options):
+		return "out of date: the options changed"
+	case m.Generator != current.Generator:
+		return "out of date: generated by another version of wsdl2api"
+	}
+	return "out of date"
+}
+
+// path returns the path of a generated file
+func (g *Generator) path(name string) string {
+	return filepath.Join(g.outputDir, filepath.FromSlash(name))
+}
+
+// writeIfChanged writes a file unless it has the data already
+func writeIfChanged(path string, data []byte) error {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// hash returns the hex SHA-256 digest of data
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sameJSON reports whether a and b encode to the same JSON
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,285 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// mockExamples builds Go literals of example values for the mock server
+// from the schema types, matching the types generated in types.go
+type mockExamples struct {
+	ctg      *ComplexTypeGenerator
+	types    map[string]models.Type
+	simple   map[string]models.SimpleType
+	visiting map[string]bool
+	imports  map[string]bool
+	ptrFunc  string // Generic helper taking the address of a value
+	usesPtr  bool
+}
+
+// newMockExamples creates an example builder for def's types
+func (g *Generator) newMockExamples(def *models.Definitions) *mockExamples {
+	m := &mockExamples{
+		ctg:      g.newComplexTypeGenerator(def.TargetNamespace),
+		types:    make(map[string]models.Type),
+		simple:   make(map[string]models.SimpleType),
+		visiting: make(map[string]bool),
+		imports:  make(map[string]bool),
+		ptrFunc:  "mockPtr",
+	}
+	m.ctg.SetElements(def.Elements)
+	m.ctg.SetTypes(def.Types)
+	// The first declaration of a name wins, as in types.go
+	for _, t := range def.Types {
+		if name := toPascalCase(t.Name); !m.isNamed(name) {
+			m.types[name] = t
+		}
+	}
+	for _, st := range def.SimpleTypes {
+		if name := toPascalCase(st.Name); !m.isNamed(name) {
+			m.simple[name] = st
+		}
+	}
+	return m
+}
+
+// isNamed reports whether a Go type name belongs to a schema type
+func (m *mockExamples) isNamed(name string) bool {
+	_, isType := m.types[name]
+	_, isSimple := m.simple[name]
+	return isType || isSimple
+}
+
+// isStruct reports whether a Go type name is a generated struct, whose
+// literals may be addressed and elided
+func (m *mockExamples) isStruct(name string) bool {
+	_, isType := m.types[name]
+	_, _, isHolder := m.ctg.holder(name)
+	_, isGroup := m.ctg.group(name)
+	return isType || isHolder || isGroup
+}
+
+// mockResponse returns the literal of an operation's example response, or an
+// empty string when the operation has no response type
+func (g *Generator) mockResponse(def *models.Definitions, m *mockExamples, op models.Operation) string {
+	inputMsg := def.Message(op.Input.Name)
+	outputMsg := def.Message(op.Output.Name)
+	if inputMsg == nil || outputMsg == nil {
+		return ""
+	}
+	return g.mockMessage(def, m, op, g.operationName(def, op.Name)+"Response", outputMsg)
+}
+
+// mockRequest returns the literal of an operation's example request, or an
+// empty string when the operation has no request type
+func (g *Generator) mockRequest(def *models.Definitions, m *mockExamples, op models.Operation) string {
+	inputMsg := def.Message(op.Input.Name)
+	outputMsg := def.Message(op.Output.Name)
+	if inputMsg == nil || outputMsg == nil {
+		return ""
+	}
+	return g.mockMessage(def, m, op, g.operationName(def, op.Name)+"Request", inputMsg)
+}
+
+// mockMessage returns the literal of a request or response struct with
+// every part set
+func (g *Generator) mockMessage(def *models.Definitions, m *mockExamples, op models.Operation, structName string, msg *models.Message) string {
+	style, _ := g.operationStyle(def, op.Name)
+	if style != "rpc" && len(msg.Parts) == 1 && msg.Parts[0].Element != "" {
+		if t := g.findElementType(def, msg.Parts[0].Element); t != nil {
+			return "&" + m.structLiteral(structName, *t)
+		}
+	}
+
+	var fields []string
+	for i, name := range partFieldNames(msg) {
+		part := msg.Parts[i]
+		xsdType := g.partType(def, part)
+		if value := m.value(m.ctg.goType(xsdType), xsdType, part.Name); value != "" {
+			fields = append(fields, fmt.Sprintf("%s: %s,\n", name, value))
+		}
+	}
+	return fmt.Sprintf("&%s{\n%s}", structName, strings.Join(fields, ""))
+}
+
+// structLiteral returns the literal of a complex type with its character
+// data and every element and attribute set. Only the first element of each xs:choice is set.
+func (m *mockExamples) structLiteral(typeName string, t models.Type) string {
+	m.visiting[typeName] = true
+	defer delete(m.visiting, typeName)
+
+	elemNames, attrNames, _ := m.ctg.fieldNames(typeName, t)
+
+	var fields []string
+	switch field := contentField(t); {
+	case t.SimpleContent != "":
+		if value := m.value(m.ctg.goType(t.SimpleContent), t.SimpleContent, typeName); value != "" {
+			fields = append(fields, fmt.Sprintf("%s: %s,\n", field, value))
+		}
+	case t.Mixed:
+		fields = append(fields, fmt.Sprintf("%s: %q,\n", field, typeName))
+	}
+	chosen := make(map[int]bool)
+	for i, elem := range t.Elements {
+		if elem.Choice > 0 {
+			if chosen[elem.Choice] {
+				continue
+			}
+			chosen[elem.Choice] = true
+		}
+		if value := m.value(m.ctg.getFieldType(t, elem), elem.Type, elem.Name); value != "" {
+			fields = append(fields, fmt.Sprintf("%s: %s,\n", elemNames[i], value))
+		}
+	}
+	for i, attr := range t.Attributes {
+		if value := m.value(m.ctg.goType(attr.Type), attr.Type, attr.Name); value != "" {
+			fields = append(fields, fmt.Sprintf("%s: %s,\n", attrNames[i], value))
+		}
+	}
+
+	if len(fields) == 0 {
+		return typeName + "{}"
+	}
+	return fmt.Sprintf("%s{\n%s}", typeName, strings.Join(fields, ""))
+}
+
+// value returns the literal of an example value of a Go type, or an empty
+// string when the field is better left unset, such as a recursive type.
+// Slices get one element; name is the XML name used for strings.
+func (m *mockExamples) value(goType, xsdType, name string) string {
+	if strings.HasPrefix(goType, "[]") && goType != "[]byte" {
+		item := m.value(goType[2:], xsdType, name)
+		if item == "" {
+			return ""
+		}
+		// The element type of composite literals can be elided
+		if m.isStruct(goType[2:]) {
+			item = strings.TrimPrefix(item, goType[2:])
+		}
+		return fmt.Sprintf("%s{%s}", goType, item)
+	}
+	if strings.HasPrefix(goType, "*") {
+		base := goType[1:]
+		item := m.value(base, xsdType, name)
+		if item == "" {
+			return ""
+		}
+		if m.isStruct(base) {
+			return "&" + item
+		}
+		m.usesPtr = true
+		return fmt.Sprintf("%s[%s](%s)", m.ptrFunc, base, item)
+	}
+
+	if m.visiting[goType] {
+		return ""
+	}
+	if t, ok := m.types[goType]; ok {
+		return m.structLiteral(goType, t)
+	}
+	// Holders get the first concrete type of the hierarchy, derived types
+	// first so that xsi:type is exercised
+	if base, derived, ok := m.ctg.holder(goType); ok {
+		for _, t := range append(append([]models.Type{}, derived...), base) {
+			if t.Abstract {
+				continue
+			}
+			if value := m.value(toPascalCase(t.Name), t.Name, name); value != "" {
+				return fmt.Sprintf("%s{Value: %s}", goType, value)
+			}
+		}
+		return ""
+	}
+	// Substitution groups get their first member
+	if group, ok := m.ctg.group(goType); ok {
+		member := group.members[0]
+		value := m.value(m.ctg.memberType(member), member.Type, member.Name)
+		if value == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s{Value: %s{Value: %s}}", goType, m.ctg.wrappers[member.Name], value)
+	}
+	if st, ok := m.simple[goType]; ok {
+		baseType := m.ctg.goType(st.Base)
+		if len(st.Enumeration) > 0 && isConstType(baseType) {
+			return enumConstName(goType, st.Enumeration[0], 0)
+		}
+		m.visiting[goType] = true
+		defer delete(m.visiting, goType)
+		base := m.value(baseType, st.Base, name)
+		if base == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s(%s)", goType, base)
+	}
+
+	switch goType {
+	case "XSDDateTime":
+		m.imports["time"] = true
+		return "XSDDateTime{" + timeLiteral(time.RFC3339, sample.DateTime) + "}"
+	case "XSDDate":
+		m.imports["time"] = true
+		return "XSDDate{" + timeLiteral(time.DateOnly, sample.Date) + "}"
+	case "XSDTime":
+		m.imports["time"] = true
+		return "XSDTime{" + timeLiteral(time.TimeOnly, sample.Time) + "}"
+	case "XSDDecimal":
+		m.imports["math/big"] = true
+		r, _ := new(big.Rat).SetString(sample.Decimal)
+		return fmt.Sprintf("XSDDecimal{big.NewRat(%s, %s)}", r.Num(), r.Denom())
+	case "decimal.Decimal":
+		m.imports["github.com/shopspring/decimal"] = true
+		return fmt.Sprintf("decimal.RequireFromString(%q)", sample.Decimal)
+	case "string":
+		switch models.LocalName(xsdType) {
+		case "dateTime":
+			return strconv.Quote(sample.DateTime)
+		case "date":
+			return strconv.Quote(sample.Date)
+		case "time":
+			return strconv.Quote(sample.Time)
+		case "decimal":
+			return strconv.Quote(sample.Decimal)
+		}
+		return fmt.Sprintf("%q", name)
+	case "int", "int64", "int32", "int16", "byte", "uint64", "uint32", "uint16", "uint8":
+		if sample.Builtin(xsdType, "") == int64(sample.NegativeInteger) {
+			return strconv.Itoa(sample.NegativeInteger)
+		}
+		return strconv.Itoa(sample.Integer)
+	case "float32", "float64":
+		if models.LocalName(xsdType) == "decimal" {
+			return sample.Decimal
+		}
+		return strconv.FormatFloat(sample.Float, 'g', -1, 64)
+	case "bool":
+		return "true"
+	case "[]byte":
+		return fmt.Sprintf("[]byte(%q)", name)
+	default:
+		return ""
+	}
+}
+
+// Imports returns the packages the example values use, sorted
+func (m *mockExamples) Imports() []string {
+	imports := make([]string, 0, len(m.imports))
+	for path := range m.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// timeLiteral returns the Go expression of a sample time in the layout
+func timeLiteral(layout, value string) string {
+	t, _ := time.Parse(layout, value)
+	return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, 0, time.UTC)", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
@@ -2,8 +2,6 @@ package generator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/thdev01/wsdl2api/internal/models"
@@ -13,20 +11,14 @@ import (
 func (g *Generator) generateMockServer(def *models.Definitions) error {
 	var b strings.Builder
 
-	b.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
-	b.WriteString(`import (
-	"encoding/xml"
-	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"strings"
-)
-
-// MockServer represents a mock SOAP server for testing
+	b.WriteString(`// MockServer represents a mock SOAP server for testing. Operations without
+// a registered handler answer with an example response.
 type MockServer struct {
 	Port     int
 	handlers map[string]MockHandler
+	examples map[string]interface{}
+	recorder *recorder.Recorder
+	replayer *recorder.Replayer
 }
 
 // MockHandler is a function that handles a SOAP operation
@@ -37,6 +29,7 @@ func NewMockServer(port int) *MockServer {
 	return &MockServer{
 		Port:     port,
 		handlers: make(map[string]MockHandler),
+		examples: defaultExamples(),
 	}
 }
 
@@ -45,6 +38,40 @@ func (m *MockServer) RegisterHandler(operation string, handler MockHandler) {
 	m.handlers[operation] = handler
 }
 
+// SetExample sets the response of an operation that has no registered
+// handler, replacing the generated example. A nil value answers with an
+// empty 202 Accepted, as for one-way operations.
+func (m *MockServer) SetExample(operation string, value interface{}) {
+	m.examples[operation] = value
+}
+
+// Record makes the mock server proxy requests to target, the live SOAP
+// endpoint, saving each request and response in dir for Replay
+func (m *MockServer) Record(target, dir string) {
+	m.recorder = recorder.NewRecorder(target, dir)
+}
+
+// Replay answers requests with the responses recorded in dir. Operations
+// that were never recorded fall back to the handlers and examples.
+func (m *MockServer) Replay(dir string) error {
+	replayer, err := recorder.Load(dir)
+	if err != nil {
+		return err
+	}
+	m.replayer = replayer
+	return nil
+}
+
+// ReplayStrict is Replay, but requests that were not recorded as is get a
+// SOAP fault detailing how they differ from the closest recording
+func (m *MockServer) ReplayStrict(dir string) error {
+	if err := m.Replay(dir); err != nil {
+		return err
+	}
+	m.replayer.SetStrict(true)
+	return nil
+}
+
 // Start starts the mock server
 func (m *MockServer) Start() error {
 	http.HandleFunc("/", m.handleSOAPRequest)
@@ -61,6 +88,11 @@ func (m *MockServer) handleSOAPRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if m.recorder != nil {
+		m.recorder.ServeHTTP(w, r)
+		return
+	}
+
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -68,6 +100,10 @@ func (m *MockServer) handleSOAPRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if m.replayer != nil && m.replayer.Replay(w, body) {
+		return
+	}
+
 	// Parse SOAP envelope to get operation name
 	var envelope struct {
 		XMLName xml.Name
@@ -89,17 +125,24 @@ func (m *MockServer) handleSOAPRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find and execute handler
-	handler, exists := m.handlers[operation]
-	if !exists {
+	// Find and execute handler, falling back to the example response
+	var response interface{}
+	if handler, exists := m.handlers[operation]; exists {
+		// Execute mock handler (simplified - real implementation would unmarshal request)
+		response, err = handler(nil)
+		if err != nil {
+			m.sendSOAPFault(w, "Server", err.Error(), "")
+			return
+		}
+	} else if example, exists := m.examples[operation]; exists {
+		response = example
+	} else {
 		m.sendSOAPFault(w, "Server", fmt.Sprintf("No mock handler for operation: %s", operation), "")
 		return
 	}
 
-	// Execute mock handler (simplified - real implementation would unmarshal request)
-	response, err := handler(nil)
-	if err != nil {
-		m.sendSOAPFault(w, "Server", err.Error(), "")
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
@@ -135,16 +178,21 @@ func (m *MockServer) extractOperation(content string) string {
 
 // sendSOAPResponse sends a SOAP response
 func (m *MockServer) sendSOAPResponse(w http.ResponseWriter, response interface{}) {
+	// The response takes its element name from its XMLName, so it needs
+	// its own field inside the body
 	envelope := struct {
-		XMLName xml.Name    ` + "`xml:\"soap:Envelope\"`" + `
-		Soap    string      ` + "`xml:\"xmlns:soap,attr\"`" + `
-		Body    interface{} ` + "`xml:\"soap:Body\"`" + `
+		XMLName xml.Name ` + "`xml:\"soap:Envelope\"`" + `
+		Soap    string   ` + "`xml:\"xmlns:soap,attr\"`" + `
+		Body    struct {
+			Content interface{}
+		} ` + "`xml:\"soap:Body\"`" + `
 	}{
 		Soap: "http://schemas.xmlsoap.org/soap/envelope/",
-		Body: response,
 	}
+	envelope.Body.Content = response
 
-	xmlData, err := xml.MarshalIndent(envelope, "", "  ")
+	// Indenting would add whitespace to mixed content
+	xmlData, err := xml.Marshal(envelope)
 	if err != nil {
 		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
 		return
@@ -187,21 +235,64 @@ func (m *MockServer) sendSOAPFault(w http.ResponseWriter, code, message, detail
 }
 `)
 
-	// Generate default mock handlers for each operation
+	// Generate example responses and default mock handlers for each operation
+	examples := g.newMockExamples(def)
+	var defaults strings.Builder
+	seen := make(map[string]bool)
 	b.WriteString("\n// Default mock handlers\n\n")
 
 	for _, portType := range def.PortTypes {
 		for _, op := range portType.Operations {
-			methodName := toPascalCase(op.Name)
+			methodName := g.operationName(def, op.Name)
+			response := g.mockResponse(def, examples, op)
+
+			// Examples are keyed by operation name, the first one wins
+			key := ""
+			if !seen[op.Name] {
+				seen[op.Name] = true
+				key = op.Name
+			}
+
+			if response == "" {
+				if key != "" {
+					defaults.WriteString(fmt.Sprintf("\t\t%q: nil,\n", key))
+				}
+				b.WriteString(fmt.Sprintf("// Mock%s is a default mock handler for %s operation\n", methodName, op.Name))
+				b.WriteString(fmt.Sprintf("func Mock%s(request interface{}) (interface{}, error) {\n", methodName))
+				b.WriteString("\treturn nil, nil\n")
+				b.WriteString("}\n\n")
+				continue
+			}
+
+			if key != "" {
+				defaults.WriteString(fmt.Sprintf("\t\t%q: example%sResponse(),\n", key, methodName))
+			}
+			b.WriteString(fmt.Sprintf("// example%sResponse returns an example response for %s operation\n", methodName, op.Name))
+			b.WriteString(fmt.Sprintf("func example%sResponse() *%sResponse {\n", methodName, methodName))
+			b.WriteString(fmt.Sprintf("\treturn %s\n", response))
+			b.WriteString("}\n\n")
 
 			b.WriteString(fmt.Sprintf("// Mock%s is a default mock handler for %s operation\n", methodName, op.Name))
 			b.WriteString(fmt.Sprintf("func Mock%s(request interface{}) (interface{}, error) {\n", methodName))
-			b.WriteString("\t// TODO: Implement mock logic\n")
-			b.WriteString(fmt.Sprintf("\treturn &%sResponse{}, nil\n", methodName))
+			b.WriteString(fmt.Sprintf("\treturn example%sResponse(), nil\n", methodName))
 			b.WriteString("}\n\n")
 		}
 	}
 
+	b.WriteString("// defaultExamples returns the generated example response of each operation\n")
+	b.WriteString("func defaultExamples() map[string]interface{} {\n")
+	b.WriteString("\treturn map[string]interface{}{\n")
+	b.WriteString(defaults.String())
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+
+	if examples.usesPtr {
+		b.WriteString("// mockPtr returns a pointer to v, for optional fields of the examples\n")
+		b.WriteString("func mockPtr[T any](v T) *T {\n")
+		b.WriteString("\treturn &v\n")
+		b.WriteString("}\n\n")
+	}
+
 	// Generate example usage
 	b.WriteString("// Example usage:\n/*\n")
 	b.WriteString("func ExampleMockServer() {\n")
@@ -210,15 +301,36 @@ func (m *MockServer) sendSOAPFault(w http.ResponseWriter, code, message, detail
 	for _, portType := range def.PortTypes {
 		if len(portType.Operations) > 0 {
 			op := portType.Operations[0]
-			methodName := toPascalCase(op.Name)
+			methodName := g.operationName(def, op.Name)
 			b.WriteString(fmt.Sprintf("\t// Register custom handler for %s\n", op.Name))
 			b.WriteString(fmt.Sprintf("\tmock.RegisterHandler(\"%s\", Mock%s)\n", op.Name, methodName))
 			break
 		}
 	}
 
+	b.WriteString("\n\t// Or record the live service once, then replay it offline\n")
+	b.WriteString(fmt.Sprintf("\t// mock.Record(%q, \"testdata/recordings\")\n", g.findServiceEndpoint(def)))
+	b.WriteString("\t// mock.Replay(\"testdata/recordings\")\n")
+	b.WriteString("\t// or, to get a fault detailing how requests differ from the recordings:\n")
+	b.WriteString("\t// mock.ReplayStrict(\"testdata/recordings\")\n")
 	b.WriteString("\n\tlog.Fatal(mock.Start())\n")
 	b.WriteString("}\n*/\n")
 
-	return os.WriteFile(filepath.Join(g.outputDir, "mock_server.go"), []byte(b.String()), 0644)
+	// The header goes last since the imports depend on the examples
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
+	header.WriteString("import (\n")
+	var thirdParty []string
+	for _, path := range append([]string{"encoding/xml", "fmt", "io", "log", "net/http", "strings"}, examples.Imports()...) {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			thirdParty = append(thirdParty, fmt.Sprintf("\t%q\n", path))
+		} else {
+			header.WriteString(fmt.Sprintf("\t%q\n", path))
+		}
+	}
+	thirdParty = append(thirdParty, fmt.Sprintf("\t%q\n", "github.com/thdev01/wsdl2api/pkg/recorder"))
+	header.WriteString("\n" + strings.Join(thirdParty, ""))
+	header.WriteString(")\n\n")
+
+	return g.writeGoFile("mock_server.go", header.String()+b.String())
 }
@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// SetWithTests makes Generate also generate the mock server and
+// mock_server_test.go, round-trip tests of each operation against it
+func (g *Generator) SetWithTests(withTests bool) {
+	g.options.Tests = withTests
+}
+
+// generateMockTests generates a test per operation that sends an example
+// request through the client to the mock server, and checks that the
+// request and the example response both survive the SOAP envelopes
+func (g *Generator) generateMockTests(def *models.Definitions) error {
+	var b strings.Builder
+
+	examples := g.newMockExamples(def)
+	examples.ptrFunc = "testPtr"
+	seen := make(map[string]bool)
+	var concurrent string
+
+	b.WriteString(`// newRoundTripServer serves mock over HTTP, keeping the body of the last
+// request it received
+func newRoundTripServer(t *testing.T, mock *MockServer) (*httptest.Server, *[]byte) {
+	t.Helper()
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		mock.handleSOAPRequest(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &body
+}
+
+`)
+
+	for _, portType := range def.PortTypes {
+		for _, op := range portType.Operations {
+			methodName := g.operationName(def, op.Name)
+			request := g.mockRequest(def, examples, op)
+			if request == "" {
+				continue
+			}
+
+			b.WriteString(fmt.Sprintf("// example%sRequest returns an example request for %s operation\n", methodName, op.Name))
+			b.WriteString(fmt.Sprintf("func example%sRequest() *%sRequest {\n", methodName, methodName))
+			b.WriteString(fmt.Sprintf("\treturn %s\n", request))
+			b.WriteString("}\n\n")
+
+			b.WriteString(fmt.Sprintf("// Test%sRoundTrip sends an example %s request to the mock server and\n", methodName, op.Name))
+			b.WriteString("// checks that both envelopes decode to the values sent\n")
+			b.WriteString(fmt.Sprintf("func Test%sRoundTrip(t *testing.T) {\n", methodName))
+			b.WriteString("\tmock := NewMockServer(0)\n")
+			// The mock looks examples up by the request element, which may not
+			// be the operation name, and the first operation of a name wins
+			element := g.requestElement(def, op)
+			if element != op.Name || seen[op.Name] {
+				b.WriteString(fmt.Sprintf("\tmock.SetExample(%q, example%sResponse())\n", element, methodName))
+			}
+			seen[op.Name] = true
+			b.WriteString("\tserver, body := newRoundTripServer(t, mock)\n\n")
+
+			b.WriteString(fmt.Sprintf("\trequest := example%sRequest()\n", methodName))
+			b.WriteString(fmt.Sprintf("\tvar response %sResponse\n", methodName))
+			b.WriteString(fmt.Sprintf("\tif err := NewClient(server.URL).CallContext(context.Background(), %q, request, &response); err != nil {\n", def.SoapAction(op.Name)))
+			b.WriteString(fmt.Sprintf("\t\tt.Fatalf(\"%s: %%v\", err)\n", methodName))
+			b.WriteString("\t}\n\n")
+
+			b.WriteString("\tvar sent struct {\n")
+			b.WriteString("\t\tBody struct {\n")
+			b.WriteString(fmt.Sprintf("\t\t\tRequest %sRequest `xml:\",any\"`\n", methodName))
+			b.WriteString("\t\t}\n")
+			b.WriteString("\t}\n")
+			b.WriteString("\tif err := xml.Unmarshal(*body, &sent); err != nil {\n")
+			b.WriteString("\t\tt.Fatalf(\"decoding request: %v\", err)\n")
+			b.WriteString("\t}\n")
+			b.WriteString("\trequest.XMLName = sent.Body.Request.XMLName\n")
+			b.WriteString("\tif !reflect.DeepEqual(sent.Body.Request, *request) {\n")
+			b.WriteString("\t\tt.Errorf(\"request decoded as %+v, want %+v\", sent.Body.Request, *request)\n")
+			b.WriteString("\t}\n\n")
+
+			b.WriteString(fmt.Sprintf("\twant := example%sResponse()\n", methodName))
+			b.WriteString("\twant.XMLName = response.XMLName\n")
+			b.WriteString("\tif !reflect.DeepEqual(response, *want) {\n")
+			b.WriteString("\t\tt.Errorf(\"response decoded as %+v, want %+v\", response, *want)\n")
+			b.WriteString("\t}\n")
+			b.WriteString("}\n\n")
+
+			if concurrent == "" {
+				concurrent = g.concurrencyTest(methodName, element, def.SoapAction(op.Name))
+			}
+		}
+	}
+	b.WriteString(concurrent)
+
+	if examples.usesPtr {
+		b.WriteString("// testPtr returns a pointer to v, for optional fields of the examples\n")
+		b.WriteString("func testPtr[T any](v T) *T {\n")
+		b.WriteString("\treturn &v\n")
+		b.WriteString("}\n")
+	}
+
+	// The header goes last since the imports depend on the examples
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
+	header.WriteString("import (\n")
+	var thirdParty []string
+	imports := []string{"bytes", "context", "encoding/xml", "io", "net/http", "net/http/httptest", "reflect", "testing"}
+	if concurrent != "" {
+		imports = append(imports, "fmt", "strconv", "sync")
+		sort.Strings(imports)
+	}
+	for _, path := range append(imports, examples.Imports()...) {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			thirdParty = append(thirdParty, fmt.Sprintf("\t%q\n", path))
+		} else {
+			header.WriteString(fmt.Sprintf("\t%q\n", path))
+		}
+	}
+	if len(thirdParty) > 0 {
+		header.WriteString("\n" + strings.Join(thirdParty, ""))
+	}
+	header.WriteString(")\n\n")
+
+	return g.writeGoFile("mock_server_test.go", header.String()+b.String())
+}
+
+// concurrencyTest returns a test sharing a client between goroutines that
+// call an operation while others change and read the client's settings,
+// for the race detector to check. element is the request element the mock
+// looks the example response up by.
+func (g *Generator) concurrencyTest(methodName, element, soapAction string) string {
+	var b strings.Builder
+	b.WriteString("// TestConcurrentCalls shares a client between goroutines calling it and\n")
+	b.WriteString("// changing its settings. Run it with -race to check the client for data\n")
+	b.WriteString("// races.\n")
+	b.WriteString("func TestConcurrentCalls(t *testing.T) {\n")
+	b.WriteString("\tmock := NewMockServer(0)\n")
+	b.WriteString(fmt.Sprintf("\tmock.SetExample(%q, example%sResponse())\n", element, methodName))
+	b.WriteString("\tserver := httptest.NewServer(http.HandlerFunc(mock.handleSOAPRequest))\n")
+	b.WriteString("\tdefer server.Close()\n\n")
+	b.WriteString("\tclient := NewClient(server.URL, WithHeaders(map[string]string{\"X-Client\": \"test\"}))\n")
+	b.WriteString("\tvar wg sync.WaitGroup\n")
+	b.WriteString("\terrs := make(chan error, 16)\n")
+	b.WriteString("\tfor i := 0; i < 8; i++ {\n")
+	b.WriteString("\t\twg.Add(2)\n")
+	b.WriteString("\t\tgo func() {\n")
+	b.WriteString("\t\t\tdefer wg.Done()\n")
+	b.WriteString(fmt.Sprintf("\t\t\tvar response %sResponse\n", methodName))
+	b.WriteString(fmt.Sprintf("\t\t\tif err := client.CallContext(context.Background(), %q, example%sRequest(), &response); err != nil {\n", soapAction, methodName))
+	b.WriteString("\t\t\t\terrs <- err\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t}()\n")
+	b.WriteString("\t\tgo func(i int) {\n")
+	b.WriteString("\t\t\tdefer wg.Done()\n")
+	b.WriteString("\t\t\tclient.SetHeader(\"X-Request\", strconv.Itoa(i))\n")
+	b.WriteString("\t\t\tclient.SetBasicAuth(\"user\", strconv.Itoa(i))\n")
+	b.WriteString("\t\t\tif client.Headers()[\"X-Client\"] != \"test\" {\n")
+	b.WriteString("\t\t\t\terrs <- fmt.Errorf(\"headers = %v\", client.Headers())\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t}(i)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\twg.Wait()\n")
+	b.WriteString("\tclose(errs)\n")
+	b.WriteString("\tfor err := range errs {\n")
+	b.WriteString("\t\tt.Error(err)\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+// requestElement returns the local name of the element wrapping an
+// operation's request in the SOAP body
+func (g *Generator) requestElement(def *models.Definitions, op models.Operation) string {
+	style, _ := g.operationStyle(def, op.Name)
+	if msg := def.Message(op.Input.Name); msg != nil && style != "rpc" && len(msg.Parts) == 1 && msg.Parts[0].Element != "" {
+		if t := g.findElementType(def, msg.Parts[0].Element); t != nil {
+			return models.LocalName(msg.Parts[0].Element)
+		}
+	}
+	return op.Name
+}
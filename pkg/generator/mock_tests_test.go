@@ -0,0 +1,67 @@
+package generator
+
+import "testing"
+
+func TestGenerateMockTests(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="GetQuote"><xs:complexType><xs:sequence>
+        <xs:element name="symbol" type="xs:string"/>
+        <xs:element name="limit" type="xs:int" minOccurs="0"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetQuoteResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="xs:double"/>
+      </xs:sequence></xs:complexType></xs:element>`, "GetQuote")
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "round trip per operation",
+			file: "mock_server_test.go",
+			want: []string{
+				"func exampleGetQuoteRequest() *GetQuoteRequest {",
+				"func TestGetQuoteRoundTrip(t *testing.T) {",
+				"server, body := newRoundTripServer(t, mock)",
+				`NewClient(server.URL).CallContext(context.Background(), "urn:test#GetQuote", request, &response)`,
+				"if !reflect.DeepEqual(sent.Body.Request, *request) {",
+				"want := exampleGetQuoteResponse()",
+			},
+		},
+		{
+			name: "optional fields",
+			file: "mock_server_test.go",
+			want: []string{
+				`Symbol: "symbol",`,
+				"Limit:  testPtr[int](42),",
+				"func testPtr[T any](v T) *T {",
+			},
+		},
+		{
+			name: "concurrent calls",
+			file: "mock_server_test.go",
+			want: []string{
+				"func TestConcurrentCalls(t *testing.T) {",
+				`mock.SetExample("GetQuote", exampleGetQuoteResponse())`,
+				`client.SetHeader("X-Request", strconv.Itoa(i))`,
+			},
+		},
+		{
+			name: "mock server",
+			file: "mock_server.go",
+			want: []string{"func NewMockServer(port int) *MockServer {", "func exampleGetQuoteResponse() *GetQuoteResponse {"},
+		},
+	}
+
+	files := render(t, wsdl, func(g *Generator) { g.SetWithTests(true) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, files, tt.file, tt.want)
+		})
+	}
+
+	// Tests are only generated on request
+	if _, ok := render(t, wsdl, nil)["mock_server_test.go"]; ok {
+		t.Error("mock_server_test.go generated without SetWithTests")
+	}
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// wsdl2apiModule is the module of the runtime packages generated clients
+// import, such as pkg/security
+const wsdl2apiModule = "github.com/thdev01/wsdl2api"
+
+// runtimeRequirements are the modules the runtime packages import, which
+// standalone output requires instead of wsdl2apiModule: x/crypto provides
+// the MD4 hash of NTLM
+var runtimeRequirements = []string{"golang.org/x/crypto"}
+
+// moduleGoVersion is the go directive of scaffolded modules, the version
+// wsdl2apiModule requires
+const moduleGoVersion = "1.23.0"
+
+// SetModule makes Generate scaffold a standalone Go module with the given
+// path in the output directory: go.mod, a LICENSE stub and the package
+// documentation in doc.go. go.mod and LICENSE are only written when
+// missing, so that regenerating keeps the requirements and the license
+// added since.
+func (g *Generator) SetModule(path string) {
+	g.modulePath = path
+}
+
+// generateModule writes go.mod and the LICENSE stub of the module set by
+// SetModule, unless they exist
+func (g *Generator) generateModule(def *models.Definitions) error {
+	if err := module.CheckImportPath(g.modulePath); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+
+	f := new(modfile.File)
+	if err := f.AddModuleStmt(g.modulePath); err != nil {
+		return err
+	}
+	if err := f.AddGoStmt(moduleGoVersion); err != nil {
+		return err
+	}
+	// Pin the runtime packages to the version that generated the code,
+	// unless they are copied; go mod tidy resolves the other requirements
+	if version := Version(); version != "" && !g.standalone {
+		f.AddNewRequire(wsdl2apiModule, version, false)
+	}
+	if g.standalone {
+		for _, path := range runtimeRequirements {
+			if version := dependencyVersion(path); version != "" {
+				f.AddNewRequire(path, version, false)
+			}
+		}
+	}
+	gomod, err := f.Format()
+	if err != nil {
+		return err
+	}
+	g.writeNewFile("go.mod", gomod)
+
+	source := "a WSDL"
+	if name := serviceName(def); name != "" {
+		source = "the WSDL of the " + name + " service"
+	}
+	year := "<year>"
+	if y := g.year(); y != 0 {
+		year = strconv.Itoa(y)
+	}
+	license := fmt.Sprintf(`Copyright (c) %s <copyright holders>
+
+This module was generated by wsdl2api from %s.
+Replace this file with the license you publish it under.
+`, year, source)
+	g.writeNewFile("LICENSE", []byte(license))
+	return nil
+}
+
+// writeNewFile adds a file to the generated files that is only written
+// when missing. It isn't tracked by the manifest, since it is meant to be
+// edited.
+func (g *Generator) writeNewFile(name string, data []byte) {
+	g.out.scaffold[name] = data
+}
+
+// Version returns the version of wsdl2apiModule this binary was
+// built with, or an empty string for builds without a usable version
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	version := ""
+	if info.Main.Path == wsdl2apiModule {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == wsdl2apiModule && dep.Replace == nil {
+			version = dep.Version
+		}
+	}
+	// Builds of modified checkouts have versions such as v0.0.0-...+dirty
+	if module.Check(wsdl2apiModule, version) != nil || module.CanonicalVersion(version) != version {
+		return ""
+	}
+	return version
+}
+
+// dependencyVersion returns the version of a module this binary was built
+// with, or an empty string when it wasn't
+func dependencyVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
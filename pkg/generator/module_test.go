@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	wsdlparser "github.com/thdev01/wsdl2api/pkg/parser"
+)
+
+func TestGenerateModule(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="Ping"><xs:complexType/></xs:element>
+      <xs:element name="PingResponse"><xs:complexType/></xs:element>`, "Ping")
+
+	tests := []struct {
+		name      string
+		configure func(g *Generator)
+		file      string
+		want      []string
+	}{
+		{
+			name:      "go.mod",
+			configure: func(g *Generator) { g.SetModule("github.com/acme/quotes-client") },
+			file:      "go.mod",
+			want:      []string{"module github.com/acme/quotes-client\n", "go " + moduleGoVersion + "\n"},
+		},
+		{
+			name:      "license stub",
+			configure: func(g *Generator) { g.SetModule("github.com/acme/quotes-client") },
+			file:      "LICENSE",
+			want:      []string{"<copyright holders>", "generated by wsdl2api from the WSDL of the Test service"},
+		},
+		{
+			name: "license year of deterministic output",
+			configure: func(g *Generator) {
+				g.SetModule("github.com/acme/quotes-client")
+				g.SetDeterministic(true)
+			},
+			file: "LICENSE",
+			want: []string{"Copyright (c) <year> <copyright holders>"},
+		},
+		{
+			name:      "package documentation",
+			configure: func(g *Generator) { g.SetModule("github.com/acme/quotes-client") },
+			file:      "doc.go",
+			want:      []string{"// Package client is a generated SOAP client of the Test service.\n", "package client\n"},
+		},
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, render(t, wsdl, tt.configure), tt.file, tt.want)
+		})
+	}
+
+	// Without a module path nothing is scaffolded
+	files := render(t, wsdl, nil)
+	for _, name := range []string{"go.mod", "LICENSE", "doc.go"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("%s generated without SetModule", name)
+		}
+	}
+
+	def, err := wsdlparser.NewParser().ParseReader(strings.NewReader(wsdl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(t.TempDir(), "client")
+	g.SetModule("not a module path")
+	if err := g.render(def, g.options); err == nil || !strings.Contains(err.Error(), "invalid module path") {
+		t.Errorf("render() with an invalid module path = %v, want an error", err)
+	}
+}
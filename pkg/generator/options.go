@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Artifact names accepted by ParseArtifacts
+const (
+	ArtifactClient     = "client"    // client.go and http_client.go
+	ArtifactTypes      = "types"     // types.go
+	ArtifactOperators  = "operators" // operators.go
+	ArtifactFakeClient = "fake"      // fake_client.go
+	ArtifactExample    = "example"   // example.go
+	ArtifactMock       = "mock"      // mock_server.go
+	ArtifactTests      = "tests"     // mock_server_test.go
+	ArtifactServer     = "server"    // server.go
+)
+
+// Artifacts lists the artifact names in the order they are generated
+var Artifacts = []string{
+	ArtifactClient,
+	ArtifactTypes,
+	ArtifactOperators,
+	ArtifactFakeClient,
+	ArtifactExample,
+	ArtifactMock,
+	ArtifactTests,
+	ArtifactServer,
+}
+
+// Options selects the files Generate writes. The other files use the types
+// in types.go, and the operators and fake client also need client.go.
+type Options struct {
+	Client     bool // client.go, and http_client.go for HTTP bindings
+	Types      bool // types.go
+	Operators  bool // operators.go
+	FakeClient bool // fake_client.go
+	Example    bool // example.go
+	Mock       bool // mock_server.go
+	Tests      bool // mock_server_test.go, which implies Mock
+	Server     bool // server.go, the REST server skeleton
+}
+
+// DefaultOptions returns the options used by NewGenerator: everything but
+// the mock server and its tests
+func DefaultOptions() Options {
+	return Options{
+		Client:     true,
+		Types:      true,
+		Operators:  true,
+		FakeClient: true,
+		Example:    true,
+	}
+}
+
+// ParseArtifacts returns options generating only the named artifacts, one
+// of the Artifact constants each
+func ParseArtifacts(names []string) (Options, error) {
+	var opts Options
+	for _, name := range names {
+		if err := opts.set(strings.TrimSpace(name), true); err != nil {
+			return Options{}, err
+		}
+	}
+	return opts, nil
+}
+
+// set enables or disables an artifact by name
+func (o *Options) set(name string, enabled bool) error {
+	field := o.field(name)
+	if field == nil {
+		return fmt.Errorf("unknown artifact %q (want one of %s)", name, strings.Join(Artifacts, ", "))
+	}
+	*field = enabled
+	return nil
+}
+
+// names returns the names of the enabled artifacts
+func (o Options) names() []string {
+	var names []string
+	for _, name := range Artifacts {
+		if *o.field(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// field returns the option of an artifact, nil for unknown names
+func (o *Options) field(name string) *bool {
+	switch name {
+	case ArtifactClient:
+		return &o.Client
+	case ArtifactTypes:
+		return &o.Types
+	case ArtifactOperators:
+		return &o.Operators
+	case ArtifactFakeClient:
+		return &o.FakeClient
+	case ArtifactExample:
+		return &o.Example
+	case ArtifactMock:
+		return &o.Mock
+	case ArtifactTests:
+		return &o.Tests
+	case ArtifactServer:
+		return &o.Server
+	}
+	return nil
+}
+
+// SetOptions selects the files Generate writes. The default is
+// DefaultOptions().
+func (g *Generator) SetOptions(opts Options) {
+	g.options = opts
+}
+
+// Options returns the files Generate writes
+func (g *Generator) Options() Options {
+	return g.options
+}
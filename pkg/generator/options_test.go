@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArtifacts(t *testing.T) {
+	tests := []struct {
+		names   []string
+		want    Options
+		wantErr bool
+	}{
+		{names: nil, want: Options{}},
+		{names: []string{"types"}, want: Options{Types: true}},
+		{names: []string{"client", " types", "fake "}, want: Options{Client: true, Types: true, FakeClient: true}},
+		{names: []string{"mock", "tests", "server"}, want: Options{Mock: true, Tests: true, Server: true}},
+		{names: []string{"types", "docs"}, wantErr: true},
+		{names: []string{""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseArtifacts(tt.names)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseArtifacts(%q) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseArtifacts(%q) = %+v, want %+v", tt.names, got, tt.want)
+		}
+	}
+
+	// Every artifact name selects an option of its own
+	for _, name := range Artifacts {
+		opts, err := ParseArtifacts([]string{name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := opts.names(); !reflect.DeepEqual(got, []string{name}) {
+			t.Errorf("ParseArtifacts(%q) enables %v", name, got)
+		}
+	}
+}
+
+func TestGenerateOptions(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="Ping"><xs:complexType><xs:sequence>
+        <xs:element name="id" type="xs:int"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="PingResponse"><xs:complexType><xs:sequence>
+        <xs:element name="ok" type="xs:boolean"/>
+      </xs:sequence></xs:complexType></xs:element>`, "Ping")
+
+	tests := []struct {
+		name string
+		opts *Options
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{"client.go", "example.go", "fake_client.go", "operators.go", "types.go"},
+		},
+		{
+			name: "no example",
+			opts: &Options{Client: true, Types: true, Operators: true, FakeClient: true},
+			want: []string{"client.go", "fake_client.go", "operators.go", "types.go"},
+		},
+		{
+			name: "types only",
+			opts: &Options{Types: true},
+			want: []string{"types.go"},
+		},
+		{
+			name: "tests imply the mock server",
+			opts: &Options{Client: true, Types: true, Tests: true},
+			want: []string{"client.go", "mock_server.go", "mock_server_test.go", "types.go"},
+		},
+		{
+			name: "server",
+			opts: &Options{Types: true, Server: true},
+			want: []string{"server.go", "types.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, wsdl, func(g *Generator) {
+				if tt.opts != nil {
+					g.SetOptions(*tt.opts)
+				}
+			})
+			if got := sortedKeys(files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generated %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := NewGenerator(t.TempDir(), "client").Options(); got != DefaultOptions() {
+		t.Errorf("Options() = %+v, want DefaultOptions()", got)
+	}
+}
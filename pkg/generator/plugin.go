@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Definitions is the parsed WSDL passed to artifact generators
+type Definitions = models.Definitions
+
+// ArtifactGenerator generates an additional artifact, such as protobuf
+// definitions, SQL DDL or an internal SDK, from the parsed WSDL
+type ArtifactGenerator interface {
+	// Name identifies the generator in errors and logs
+	Name() string
+	// Generate writes the artifact to outputDir
+	Generate(def *Definitions, outputDir string) error
+}
+
+// Registry holds artifact generators that run after the built-in ones
+type Registry struct {
+	mu         sync.Mutex
+	generators []ArtifactGenerator
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry is the registry new generators use. Packages can add
+// their generators to it from init functions with Register.
+var DefaultRegistry = NewRegistry()
+
+// Register adds an artifact generator to DefaultRegistry
+func Register(gen ArtifactGenerator) {
+	DefaultRegistry.Register(gen)
+}
+
+// Register adds an artifact generator to the registry
+func (r *Registry) Register(gen ArtifactGenerator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generators = append(r.generators, gen)
+}
+
+// Generators returns the registered generators in registration order
+func (r *Registry) Generators() []ArtifactGenerator {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ArtifactGenerator(nil), r.generators...)
+}
+
+// Run runs the registered generators in order, stopping at the first error
+func (r *Registry) Run(def *Definitions, outputDir string) error {
+	for _, gen := range r.Generators() {
+		if err := gen.Generate(def, outputDir); err != nil {
+			return fmt.Errorf("%s: %w", gen.Name(), err)
+		}
+	}
+	return nil
+}
+
+// SetRegistry sets the registry whose generators Generate runs. The
+// default is DefaultRegistry.
+func (g *Generator) SetRegistry(registry *Registry) {
+	g.registry = registry
+}
+
+// PluginRequest is written as JSON to the standard input of a plugin
+type PluginRequest struct {
+	OutputDir   string       `json:"outputDir"`
+	PackageName string       `json:"packageName"`
+	Definitions *Definitions `json:"definitions"`
+}
+
+// PluginResponse is read as JSON from the standard output of a plugin
+type PluginResponse struct {
+	Files []PluginFile `json:"files"`
+	Error string       `json:"error,omitempty"`
+}
+
+// PluginFile is a file generated by a plugin, relative to the output
+// directory
+type PluginFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Plugin runs an external command as an artifact generator. The command
+// receives a PluginRequest on stdin and answers with a PluginResponse on
+// stdout; its stderr is passed through.
+type Plugin struct {
+	Command     string
+	Args        []string
+	PackageName string
+}
+
+// NewPlugin creates a plugin from a command line such as
+// "protoc-gen-wsdl --go-package=x". Arguments are split on spaces.
+func NewPlugin(commandLine, packageName string) (*Plugin, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty plugin command")
+	}
+	return &Plugin{Command: fields[0], Args: fields[1:], PackageName: packageName}, nil
+}
+
+// Name returns the plugin command
+func (p *Plugin) Name() string {
+	return "plugin " + p.Command
+}
+
+// Generate runs the plugin and writes the files it returns
+func (p *Plugin) Generate(def *Definitions, outputDir string) error {
+	request, err := json.Marshal(PluginRequest{
+		OutputDir:   outputDir,
+		PackageName: p.PackageName,
+		Definitions: def,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(p.Command, p.Args...)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run: %w", err)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("%s", response.Error)
+	}
+
+	for _, file := range response.Files {
+		// Keep plugins from writing outside the output directory
+		name := filepath.Clean(filepath.FromSlash(file.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("file %q is outside the output directory", file.Name)
+		}
+
+		path := filepath.Join(outputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", file.Name, err)
+		}
+		if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+	}
+	return nil
+}
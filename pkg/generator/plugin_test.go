@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wsdlparser "github.com/thdev01/wsdl2api/pkg/parser"
+)
+
+// artifact is an artifact generator writing the names of the operations
+type artifact struct {
+	name string
+	err  error
+}
+
+func (a artifact) Name() string { return a.name }
+
+func (a artifact) Generate(def *Definitions, outputDir string) error {
+	if a.err != nil {
+		return a.err
+	}
+	var ops []string
+	for _, op := range def.PortTypes[0].Operations {
+		ops = append(ops, op.Name)
+	}
+	return os.WriteFile(filepath.Join(outputDir, a.name+".txt"), []byte(strings.Join(ops, ",")), 0644)
+}
+
+func TestRegistry(t *testing.T) {
+	def, err := wsdlparser.NewParser().ParseReader(strings.NewReader(testWSDL("", "Ping", "Echo")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Artifacts are written next to the generated code
+	dir := t.TempDir()
+	registry := NewRegistry()
+	registry.Register(artifact{name: "ops"})
+	g := NewGenerator(dir, "client")
+	g.SetRegistry(registry)
+	if err := g.Generate(def); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "ops.txt")); err != nil || string(data) != "Ping,Echo" {
+		t.Errorf("ops.txt = %q, %v, want the operations", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "client.go")); err != nil {
+		t.Errorf("the built-in files weren't generated: %v", err)
+	}
+
+	// The first error stops the run and names its generator
+	failed := errors.New("no protoc")
+	registry.Register(artifact{name: "proto", err: failed})
+	registry.Register(artifact{name: "never"})
+	if err := g.Generate(def); !errors.Is(err, failed) || !strings.Contains(err.Error(), "proto: no protoc") {
+		t.Errorf("Generate() = %v, want the error of proto", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "never.txt")); err == nil {
+		t.Error("a generator ran after the failed one")
+	}
+}
+
+// TestPluginProcess is the plugin of TestPlugin when WSDL2API_PLUGIN is
+// set: it answers as the mode in the variable says
+func TestPluginProcess(t *testing.T) {
+	mode := os.Getenv("WSDL2API_PLUGIN")
+	if mode == "" {
+		return
+	}
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	response := PluginResponse{Files: []PluginFile{{
+		Name:    "proto/" + strings.ToLower(request.Definitions.Name) + ".proto",
+		Content: "package " + request.PackageName + ";\n// " + request.OutputDir + "\n",
+	}}}
+	switch mode {
+	case "error":
+		response = PluginResponse{Error: "unsupported schema"}
+	case "escape":
+		response.Files[0].Name = "../escaped.proto"
+	case "invalid":
+		fmt.Print("not JSON")
+		os.Exit(0)
+	case "exit":
+		os.Exit(2)
+	}
+	json.NewEncoder(os.Stdout).Encode(response)
+	os.Exit(0)
+}
+
+func TestPlugin(t *testing.T) {
+	def, err := wsdlparser.NewParser().ParseReader(strings.NewReader(testWSDL("", "Ping")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin, err := NewPlugin(os.Args[0]+" -test.run=^TestPluginProcess$", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plugin.Name() != "plugin "+os.Args[0] || len(plugin.Args) != 1 {
+		t.Errorf("NewPlugin() = %+v, want the command and its argument", plugin)
+	}
+	if _, err := NewPlugin("  ", "client"); err == nil {
+		t.Error("NewPlugin() accepted an empty command")
+	}
+
+	tests := []struct {
+		mode string
+		err  string
+	}{
+		{"files", ""},
+		{"error", "unsupported schema"},
+		{"escape", "outside the output directory"},
+		{"invalid", "invalid response"},
+		{"exit", "failed to run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv("WSDL2API_PLUGIN", tt.mode)
+			dir := filepath.Join(t.TempDir(), "out")
+			err := plugin.Generate(def, dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Generate() = %v, want %q", err, tt.err)
+				}
+				if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escaped.proto")); err == nil {
+					t.Error("the plugin wrote outside the output directory")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "proto", "test.proto"))
+			if want := "package client;\n// " + dir + "\n"; err != nil || string(data) != want {
+				t.Errorf("test.proto = %q, %v, want %q", data, err, want)
+			}
+		})
+	}
+}
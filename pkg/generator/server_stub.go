@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// GenerateServer generates the code selected by the generator's options
+// and a REST server skeleton exposing the same routes as the serve command
+func (g *Generator) GenerateServer(def *models.Definitions) error {
+	opts := g.options
+	opts.Server = true
+	return g.generate(def, opts)
+}
+
+// generateServerStub generates the Service interface and its HTTP server
+func (g *Generator) generateServerStub(def *models.Definitions) error {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
+	b.WriteString(`import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Auto-generated REST server skeleton
+`)
+
+	// Collect operations that have request/response types
+	var ops []models.Operation
+	for _, portType := range def.PortTypes {
+		for _, op := range portType.Operations {
+			if def.Message(op.Input.Name) == nil || def.Message(op.Output.Name) == nil {
+				continue
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	// Generate the interface users implement
+	b.WriteString("\n// Service is implemented by you to serve each operation\n")
+	b.WriteString("type Service interface {\n")
+	for _, op := range ops {
+		methodName := g.operationName(def, op.Name)
+		if op.Documentation != "" {
+			b.WriteString(wrapComment("\t// ", op.Documentation))
+		}
+		b.WriteString(fmt.Sprintf("\t%s(ctx context.Context, request *%sRequest) (*%sResponse, error)\n", methodName, methodName, methodName))
+	}
+	b.WriteString("}\n")
+
+	b.WriteString(fmt.Sprintf(`
+// Server exposes a Service over the same REST routes as wsdl2api serve
+type Server struct {
+	service Service
+	mux     *http.ServeMux
+}
+
+// NewServer creates a server for the given service implementation
+func NewServer(service Service) *Server {
+	s := &Server{
+		service: service,
+		mux:     http.NewServeMux(),
+	}
+	s.setupRoutes()
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts the server on addr
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s)
+}
+
+// setupRoutes configures all API routes
+func (s *Server) setupRoutes() {
+	s.mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status":  "healthy",
+			"service": %q,
+		})
+	})
+`, def.Name))
+
+	for _, op := range ops {
+		methodName := g.operationName(def, op.Name)
+		b.WriteString(fmt.Sprintf("\ts.mux.HandleFunc(\"/api/%s\", s.handle%s)\n", op.Name, methodName))
+	}
+	b.WriteString("}\n")
+
+	// Generate one handler per operation
+	for _, op := range ops {
+		methodName := g.operationName(def, op.Name)
+		b.WriteString(fmt.Sprintf(`
+// handle%s handles POST /api/%s
+func (s *Server) handle%s(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{
+			"error": "Only POST method is allowed",
+		})
+		return
+	}
+
+	var request %sRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, err := s.service.%s(r.Context(), &request)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":     "Operation failed",
+			"operation": %q,
+			"details":   err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"operation": %q,
+		"status":    "success",
+		"response":  response,
+	})
+}
+`, methodName, op.Name, methodName, methodName, methodName, op.Name, op.Name))
+	}
+
+	b.WriteString(`
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+`)
+
+	return g.writeGoFile("server.go", b.String())
+}
@@ -0,0 +1,68 @@
+package generator
+
+import "testing"
+
+func TestGenerateServerStub(t *testing.T) {
+	schema := `<xs:element name="GetQuote"><xs:complexType><xs:sequence>
+        <xs:element name="symbol" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetQuoteResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="xs:double"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="get_status"><xs:complexType/></xs:element>
+      <xs:element name="get_statusResponse"><xs:complexType/></xs:element>`
+
+	tests := []struct {
+		name     string
+		wsdl     string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name: "service interface",
+			wsdl: testWSDL(schema, "GetQuote"),
+			want: []string{
+				"type Service interface {",
+				"GetQuote(ctx context.Context, request *GetQuoteRequest) (*GetQuoteResponse, error)",
+				"func NewServer(service Service) *Server {",
+			},
+		},
+		{
+			name: "routes of serve",
+			wsdl: testWSDL(schema, "GetQuote", "get_status"),
+			want: []string{
+				`s.mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {`,
+				`"service": "Test",`,
+				`s.mux.HandleFunc("/api/GetQuote", s.handleGetQuote)`,
+				`s.mux.HandleFunc("/api/get_status", s.handleGetStatus)`,
+			},
+		},
+		{
+			name: "handlers",
+			wsdl: testWSDL(schema, "GetQuote"),
+			want: []string{
+				"// handleGetQuote handles POST /api/GetQuote",
+				"if r.Method != http.MethodPost {",
+				"var request GetQuoteRequest",
+				"response, err := s.service.GetQuote(r.Context(), &request)",
+				`"operation": "GetQuote",`,
+				"func writeJSON(w http.ResponseWriter, status int, body interface{}) {",
+			},
+			unwanted: []string{"handleGetStatus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, tt.wsdl, func(g *Generator) {
+				g.SetOptions(Options{Types: true, Server: true})
+			})
+			assertContains(t, files, "server.go", tt.want, tt.unwanted...)
+		})
+	}
+
+	// The skeleton is only generated on request
+	if _, ok := render(t, testWSDL(schema, "GetQuote"), nil)["server.go"]; ok {
+		t.Error("server.go generated with the default options")
+	}
+}
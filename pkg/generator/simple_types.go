@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// GenerateSimpleType generates a named Go type for an XSD simple type. For
+// enumerations it also generates typed constants and an IsValid method.
+func (ctg *ComplexTypeGenerator) GenerateSimpleType(st models.SimpleType) string {
+	typeName := toPascalCase(st.Name)
+	if _, ok := ctg.mappedTypes[st.Name]; ok || ctg.generatedTypes[typeName] {
+		return ""
+	}
+	ctg.generatedTypes[typeName] = true
+
+	var b strings.Builder
+	baseType := ctg.goType(st.Base)
+
+	if len(st.Enumeration) == 0 || !isConstType(baseType) {
+		b.WriteString(fmt.Sprintf("// %s represents a simple type from WSDL\n", typeName))
+		b.WriteString(fmt.Sprintf("type %s %s\n\n", typeName, baseType))
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("// %s represents an enumeration from WSDL\n", typeName))
+	b.WriteString(fmt.Sprintf("type %s %s\n\n", typeName, baseType))
+
+	// Generate constants
+	names := make([]string, 0, len(st.Enumeration))
+	seen := make(map[string]bool)
+	b.WriteString(fmt.Sprintf("// Allowed %s values\n", typeName))
+	b.WriteString("const (\n")
+	for i, value := range st.Enumeration {
+		name := enumConstName(typeName, value, i)
+		if seen[name] {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		seen[name] = true
+		names = append(names, name)
+		b.WriteString(fmt.Sprintf("\t%s %s = %s\n", name, typeName, enumLiteral(baseType, value)))
+	}
+	b.WriteString(")\n\n")
+
+	// Generate validation
+	b.WriteString("// IsValid reports whether the value is one of the allowed values\n")
+	b.WriteString(fmt.Sprintf("func (v %s) IsValid() bool {\n", typeName))
+	b.WriteString("\tswitch v {\n")
+	b.WriteString(fmt.Sprintf("\tcase %s:\n", strings.Join(names, ", ")))
+	b.WriteString("\t\treturn true\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn false\n")
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// isConstType reports whether a Go type can be used for constants
+func isConstType(goType string) bool {
+	switch goType {
+	case "string", "int", "int64", "int32", "int16", "byte", "uint64", "uint32", "uint16", "uint8", "float32", "float64", "bool":
+		return true
+	default:
+		return false
+	}
+}
+
+// enumConstName builds a constant name from the type name and value,
+// dropping characters that are not valid in identifiers
+func enumConstName(typeName, value string, index int) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	if b.Len() == 0 {
+		return fmt.Sprintf("%sValue%d", typeName, index)
+	}
+	return typeName + b.String()
+}
+
+// enumLiteral returns the Go literal for an enumeration value
+func enumLiteral(goType, value string) string {
+	if goType == "string" {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package generator
+
+import "testing"
+
+func TestGenerateEnumerations(t *testing.T) {
+	tests := []struct {
+		name       string
+		simpleType string
+		want       []string
+		unwanted   []string
+	}{
+		{
+			name: "string enumeration",
+			simpleType: `<xs:simpleType name="Color"><xs:restriction base="xs:string">
+        <xs:enumeration value="red"/><xs:enumeration value="dark-green"/>
+      </xs:restriction></xs:simpleType>`,
+			want: []string{
+				"type Color string",
+				"ColorRed       Color = \"red\"",
+				"ColorDarkGreen Color = \"dark-green\"",
+				"func (v Color) IsValid() bool {",
+				"case ColorRed, ColorDarkGreen:",
+			},
+		},
+		{
+			name: "int enumeration",
+			simpleType: `<xs:simpleType name="Level"><xs:restriction base="xs:int">
+        <xs:enumeration value="1"/><xs:enumeration value="2"/>
+      </xs:restriction></xs:simpleType>`,
+			want: []string{"type Level int", "Level1 Level = 1", "Level2 Level = 2", "case Level1, Level2:"},
+		},
+		{
+			name: "values without letters or digits",
+			simpleType: `<xs:simpleType name="Sign"><xs:restriction base="xs:string">
+        <xs:enumeration value="+"/><xs:enumeration value="-"/>
+      </xs:restriction></xs:simpleType>`,
+			want: []string{"SignValue0 Sign = \"+\"", "SignValue1 Sign = \"-\""},
+		},
+		{
+			name: "duplicate names",
+			simpleType: `<xs:simpleType name="Mode"><xs:restriction base="xs:string">
+        <xs:enumeration value="on-off"/><xs:enumeration value="on off"/>
+      </xs:restriction></xs:simpleType>`,
+			want: []string{"ModeOnOff  Mode = \"on-off\"", "ModeOnOff1 Mode = \"on off\""},
+		},
+		{
+			name: "typed fields",
+			simpleType: `<xs:simpleType name="Color"><xs:restriction base="xs:string">
+        <xs:enumeration value="red"/>
+      </xs:restriction></xs:simpleType>
+      <xs:complexType name="Paint"><xs:sequence>
+        <xs:element name="color" type="tns:Color"/>
+      </xs:sequence></xs:complexType>`,
+			want: []string{"Color Color `xml:\"color\" json:\"color\"`"},
+		},
+		{
+			name: "restriction without enumeration",
+			simpleType: `<xs:simpleType name="Code"><xs:restriction base="xs:string">
+        <xs:pattern value="[A-Z]{3}"/>
+      </xs:restriction></xs:simpleType>`,
+			want:     []string{"type Code string"},
+			unwanted: []string{"func (v Code) IsValid() bool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, testWSDL(tt.simpleType), func(g *Generator) {
+				g.SetOptions(Options{Types: true})
+			})
+			assertContains(t, files, "types.go", tt.want, tt.unwanted...)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// SetSplit makes Generate write the request and response types and the
+// operators of each port type to files of their own, indexed by doc.go,
+// rather than to types.go and operators.go alone. Large services compile
+// faster and are easier to navigate split.
+func (g *Generator) SetSplit(split bool) {
+	g.split = split
+}
+
+// portTypeFile is a port type whose messages and operators get files of
+// their own when the output is split
+type portTypeFile struct {
+	portType models.PortType
+	stem     string // File name without the _types.go and _operators.go suffixes
+}
+
+// portTypeFiles returns the port types with operations and their file
+// stems. Stems derive from the port type names alone, so that they stay the
+// same when operations are added.
+func portTypeFiles(def *models.Definitions) []portTypeFile {
+	namer := naming.NewNamer("file", fileStem)
+	var files []portTypeFile
+	for _, portType := range def.PortTypes {
+		if len(portType.Operations) == 0 {
+			continue
+		}
+		files = append(files, portTypeFile{portType: portType, stem: namer.Next(portType.Name)})
+	}
+	return files
+}
+
+// fileStem converts a name to snake case, splitting words like Pascal:
+// CalculatorSoap becomes calculator_soap and IHTTPService i_http_service
+func fileStem(name string) string {
+	runes := []rune(naming.Pascal(name))
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// goFileHeader returns the package clause and the imports among candidates
+// that body refers to, standard library packages first. names gives the
+// package names of imports not named after the last element of their path.
+func (g *Generator) goFileHeader(body string, candidates []string, names map[string]string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("package %s\n\n", g.packageName))
+
+	imports := usedImports(body, candidates, names)
+	if len(imports) == 1 {
+		b.WriteString(fmt.Sprintf("import %q\n\n", imports[0]))
+	} else if len(imports) > 1 {
+		var std, thirdParty []string
+		for _, path := range imports {
+			if strings.Contains(strings.Split(path, "/")[0], ".") {
+				thirdParty = append(thirdParty, fmt.Sprintf("\t%q\n", path))
+			} else {
+				std = append(std, fmt.Sprintf("\t%q\n", path))
+			}
+		}
+		b.WriteString("import (\n")
+		b.WriteString(strings.Join(std, ""))
+		if len(thirdParty) > 0 {
+			if len(std) > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(strings.Join(thirdParty, ""))
+		}
+		b.WriteString(")\n\n")
+	}
+	return b.String()
+}
+
+// usedImports returns the sorted imports among candidates whose package
+// body refers to. Identifiers declared in body, such as parameters, don't
+// count, so a parameter named time doesn't import the time package.
+func usedImports(body string, candidates []string, names map[string]string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n\n"+body, 0)
+	if err != nil {
+		// writeGoFile reports the syntax error
+		return candidates
+	}
+
+	qualifiers := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				qualifiers[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var imports []string
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		name, ok := names[path]
+		if !ok {
+			name = path[strings.LastIndex(path, "/")+1:]
+		}
+		if qualifiers[name] && !seen[path] {
+			imports = append(imports, path)
+			seen[path] = true
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// packageNames returns the package names of the mapped types' imports
+func (m *TypeMapping) packageNames() map[string]string {
+	names := make(map[string]string)
+	if m == nil {
+		return names
+	}
+	for _, entries := range []map[string]GoType{m.Types, m.Elements} {
+		for _, goType := range entries {
+			expr := strings.TrimLeft(goType.Type, "*[]")
+			if dot := strings.Index(expr, "."); dot != -1 && goType.Import != "" {
+				names[goType.Import] = expr[:dot]
+			}
+		}
+	}
+	return names
+}
+
+// generateDoc generates doc.go, the package documentation, which indexes
+// the port type files when the output is split and holds the go:generate
+// directive
+func (g *Generator) generateDoc(def *models.Definitions, files []portTypeFile) error {
+	var b strings.Builder
+
+	if name := serviceName(def); name == "" {
+		b.WriteString(fmt.Sprintf("// Package %s is a generated SOAP client.\n", g.packageName))
+	} else {
+		b.WriteString(fmt.Sprintf("// Package %s is a generated SOAP client of the %s service.\n", g.packageName, name))
+	}
+	b.WriteString("//\n")
+	b.WriteString("// Create a Client with NewClient and call the operations of\n")
+	b.WriteString("// ClientInterface, which FakeClient implements for unit tests.\n")
+
+	if len(files) > 0 {
+		b.WriteString("//\n")
+		b.WriteString("// Client and its SOAP envelope are in client.go, ClientInterface is in\n")
+		b.WriteString("// operators.go and the schema types shared by the operations are in\n")
+		b.WriteString("// types.go. Each port type has the request and response types of its\n")
+		b.WriteString("// operations in <port_type>_types.go and their methods in\n")
+		b.WriteString("// <port_type>_operators.go:\n")
+	}
+	for _, file := range files {
+		var methods []string
+		for _, op := range file.portType.Operations {
+			methods = append(methods, g.operationName(def, op.Name))
+		}
+		item := fmt.Sprintf("%s_types.go, %s_operators.go: %s (%s)", file.stem, file.stem, file.portType.Name, strings.Join(methods, ", "))
+		b.WriteString("//\n")
+		b.WriteString("//   - " + strings.TrimPrefix(wrapComment("//     ", item), "//     "))
+	}
+	b.WriteString(fmt.Sprintf("package %s\n", g.packageName))
+	if g.goGenerate != "" {
+		b.WriteString(fmt.Sprintf("\n//go:generate %s\n", g.goGenerate))
+	}
+
+	return g.writeGoFile("doc.go", b.String())
+}
+
+// serviceName returns the name of def's first service, or of def itself
+func serviceName(def *models.Definitions) string {
+	if len(def.Services) > 0 {
+		return def.Services[0].Name
+	}
+	return def.Name
+}
+
+// wrapComment wraps text into comment lines starting with prefix
+func wrapComment(prefix, text string) string {
+	const width = 76
+
+	var b strings.Builder
+	line := prefix
+	for _, word := range strings.Fields(text) {
+		if line != prefix && len(line)+1+len(word) > width {
+			b.WriteString(line + "\n")
+			line = prefix
+		}
+		if line != prefix {
+			line += " "
+		}
+		line += word
+	}
+	if line != prefix {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// splitWSDL has the port types QuoteService, with GetQuote, and
+// IHTTPAccounts, with GetAccount, whose responses share the Money type
+const splitWSDL = `<?xml version="1.0" encoding="utf-8"?>
+<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"
+             xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+             xmlns:xs="http://www.w3.org/2001/XMLSchema"
+             xmlns:tns="urn:test"
+             name="Test" targetNamespace="urn:test">
+  <types>
+    <xs:schema targetNamespace="urn:test" elementFormDefault="qualified">
+      <xs:complexType name="Money"><xs:sequence>
+        <xs:element name="amount" type="xs:decimal"/>
+      </xs:sequence></xs:complexType>
+      <xs:element name="GetQuote"><xs:complexType><xs:sequence>
+        <xs:element name="symbol" type="xs:string"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetQuoteResponse"><xs:complexType><xs:sequence>
+        <xs:element name="price" type="tns:Money"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetAccount"><xs:complexType><xs:sequence>
+        <xs:element name="id" type="xs:int"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetAccountResponse"><xs:complexType><xs:sequence>
+        <xs:element name="balance" type="tns:Money"/>
+      </xs:sequence></xs:complexType></xs:element>
+    </xs:schema>
+  </types>
+  <message name="GetQuoteIn"><part name="parameters" element="tns:GetQuote"/></message>
+  <message name="GetQuoteOut"><part name="parameters" element="tns:GetQuoteResponse"/></message>
+  <message name="GetAccountIn"><part name="parameters" element="tns:GetAccount"/></message>
+  <message name="GetAccountOut"><part name="parameters" element="tns:GetAccountResponse"/></message>
+  <portType name="QuoteService">
+    <operation name="GetQuote"><input message="tns:GetQuoteIn"/><output message="tns:GetQuoteOut"/></operation>
+  </portType>
+  <portType name="IHTTPAccounts">
+    <operation name="GetAccount"><input message="tns:GetAccountIn"/><output message="tns:GetAccountOut"/></operation>
+  </portType>
+  <portType name="Empty"/>
+  <binding name="QuoteBinding" type="tns:QuoteService">
+    <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <operation name="GetQuote">
+      <soap:operation soapAction="urn:test#GetQuote"/>
+      <input><soap:body use="literal"/></input>
+      <output><soap:body use="literal"/></output>
+    </operation>
+  </binding>
+  <binding name="AccountsBinding" type="tns:IHTTPAccounts">
+    <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <operation name="GetAccount">
+      <soap:operation soapAction="urn:test#GetAccount"/>
+      <input><soap:body use="literal"/></input>
+      <output><soap:body use="literal"/></output>
+    </operation>
+  </binding>
+  <service name="Test">
+    <port name="QuotePort" binding="tns:QuoteBinding"><soap:address location="http://test.example.com/soap"/></port>
+  </service>
+</definitions>`
+
+func TestGenerateSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:     "shared types",
+			file:     "types.go",
+			want:     []string{"type Money struct {"},
+			unwanted: []string{"type GetQuoteRequest struct", "type GetAccountRequest struct"},
+		},
+		{
+			name:     "messages per port type",
+			file:     "quote_service_types.go",
+			want:     []string{`import "encoding/xml"`, "type GetQuoteRequest struct {", "type GetQuoteResponse struct {"},
+			unwanted: []string{"GetAccount"},
+		},
+		{
+			name: "stems split like identifiers",
+			file: "ihttp_accounts_types.go",
+			want: []string{"type GetAccountRequest struct {", "type GetAccountResponse struct {"},
+		},
+		{
+			name:     "operators per port type",
+			file:     "quote_service_operators.go",
+			want:     []string{"func (c *Client) GetQuote(symbol string) (Money, error) {", "func (c *Client) GetQuoteContext("},
+			unwanted: []string{"type ClientInterface interface", "GetAccount"},
+		},
+		{
+			name:     "interface of every port type",
+			file:     "operators.go",
+			want:     []string{"type ClientInterface interface {", "GetQuote(symbol string) (Money, error)", "GetAccount(id int) (Money, error)"},
+			unwanted: []string{"func (c *Client)"},
+		},
+		{
+			name: "index",
+			file: "doc.go",
+			want: []string{
+				"// Package client is a generated SOAP client of the Test service.",
+				"//   - quote_service_types.go, quote_service_operators.go: QuoteService\n//     (GetQuote)",
+				"//   - ihttp_accounts_types.go, ihttp_accounts_operators.go: IHTTPAccounts\n//     (GetAccount)",
+				"package client\n",
+			},
+			unwanted: []string{"empty_types.go"},
+		},
+	}
+
+	files := render(t, splitWSDL, func(g *Generator) { g.SetSplit(true) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, files, tt.file, tt.want, tt.unwanted...)
+		})
+	}
+	for name := range files {
+		if strings.HasPrefix(name, "empty_") {
+			t.Errorf("%s generated for a port type without operations", name)
+		}
+	}
+
+	// Without splitting everything is in types.go and operators.go
+	files = render(t, splitWSDL, nil)
+	assertContains(t, files, "types.go", []string{"type GetQuoteRequest struct {", "type GetAccountRequest struct {"})
+	for _, name := range []string{"doc.go", "quote_service_types.go"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("%s generated without SetSplit", name)
+		}
+	}
+}
+
+func TestFileStem(t *testing.T) {
+	tests := map[string]string{
+		"CalculatorSoap": "calculator_soap",
+		"IHTTPService":   "ihttp_service",
+		"quote-service":  "quote_service",
+		"Service2Port":   "service2_port",
+	}
+	for name, want := range tests {
+		if got := fileStem(name); got != want {
+			t.Errorf("fileStem(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/thdev01/wsdl2api"
+)
+
+// runtimePrefix is the import path prefix of the runtime packages
+const runtimePrefix = wsdl2apiModule + "/pkg/"
+
+// SetStandalone makes Generate copy the runtime packages the generated code
+// imports, such as pkg/security, into the internal directory of the output
+// and import them from there, so that the generated client doesn't depend
+// on wsdl2api. The copies are imported by the path of the output
+// directory: the module path set by SetModule, or the path within the
+// module enclosing the directory.
+func (g *Generator) SetStandalone(standalone bool) {
+	g.standalone = standalone
+}
+
+// generateRuntime copies the runtime packages the generated files import
+// into internal/ and rewrites the imports to the copies
+func (g *Generator) generateRuntime() error {
+	importPath, err := g.importPath()
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, name := range g.out.names() {
+		if strings.Contains(name, "/") || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		rewritten, packages, err := rewriteRuntimeImports(name, g.out.files[name], importPath)
+		if err != nil {
+			return err
+		}
+		g.out.files[name] = rewritten
+		pending = append(pending, packages...)
+	}
+
+	// Copy the packages, and the ones they import in turn
+	copied := make(map[string]bool)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if copied[name] {
+			continue
+		}
+		copied[name] = true
+
+		packages, err := g.copyRuntimePackage(name, importPath)
+		if err != nil {
+			return fmt.Errorf("failed to copy runtime package %s: %w", name, err)
+		}
+		pending = append(pending, packages...)
+	}
+	return nil
+}
+
+// copyRuntimePackage copies a runtime package but its tests to
+// internal/<name>, returning the runtime packages it imports
+func (g *Generator) copyRuntimePackage(name, importPath string) ([]string, error) {
+	files, err := fs.Glob(wsdl2api.Runtime, "pkg/"+name+"/*.go")
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no sources embedded")
+	}
+
+	var imports []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := wsdl2api.Runtime.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		out := path.Join("internal", name, path.Base(file))
+		rewritten, packages, err := rewriteRuntimeImports(out, src, importPath)
+		if err != nil {
+			return nil, err
+		}
+		header := fmt.Sprintf("// Code generated by wsdl2api --standalone from %s%s. DO NOT EDIT.\n\n", runtimePrefix, name)
+		g.out.add(out, append([]byte(header), rewritten...))
+		imports = append(imports, packages...)
+	}
+	return imports, nil
+}
+
+// rewriteRuntimeImports replaces the imports of runtime packages in a Go
+// file with imports of their copies under importPath/internal, returning
+// the packages replaced
+func rewriteRuntimeImports(filename string, src []byte, importPath string) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var packages []string
+	for _, spec := range file.Imports {
+		imported, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if name, ok := strings.CutPrefix(imported, runtimePrefix); ok {
+			packages = append(packages, name)
+		}
+	}
+	if len(packages) == 0 {
+		return src, nil, nil
+	}
+	for _, name := range packages {
+		astutil.RewriteImport(fset, file, runtimePrefix+name, importPath+"/internal/"+name)
+	}
+
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, file); err != nil {
+		return nil, nil, err
+	}
+	return b.Bytes(), packages, nil
+}
+
+// importPath returns the import path of the output directory: the module
+// path set by SetModule, or the path within the module enclosing the
+// directory
+func (g *Generator) importPath() (string, error) {
+	if g.modulePath != "" {
+		return g.modulePath, nil
+	}
+
+	dir, err := filepath.Abs(g.outputDir)
+	if err != nil {
+		return "", err
+	}
+	for root := dir; ; root = filepath.Dir(root) {
+		if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			modulePath := modfile.ModulePath(data)
+			if modulePath == "" {
+				return "", fmt.Errorf("%s declares no module path", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(modulePath, filepath.ToSlash(rel)), nil
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("standalone output needs the import path of %s: set a module path or generate inside a Go module", g.outputDir)
+		}
+	}
+}
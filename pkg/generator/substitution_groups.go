@@ -0,0 +1,328 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// substitutionGroup is a global element that other global elements may
+// substitute, and the Go types generated for the elements referencing it
+type substitutionGroup struct {
+	head models.Element
+	// members are the elements that may appear in place of the head, the
+	// head first unless it is abstract
+	members []models.Element
+	used    bool
+
+	holder string // Struct holding any member
+	iface  string // Interface implemented by the members' wrappers
+	method string // Method of iface
+}
+
+// SetElements records the global elements of the schema, for the
+// substitution groups among them. It must be called before SetTypes.
+func (ctg *ComplexTypeGenerator) SetElements(elements []models.Element) {
+	for _, head := range elements {
+		substitutes := substitutesOf(elements, head, map[string]bool{head.Name: true})
+		if len(substitutes) == 0 {
+			continue
+		}
+		group := &substitutionGroup{head: head}
+		for _, member := range append([]models.Element{head}, substitutes...) {
+			if !member.Abstract {
+				group.members = append(group.members, member)
+			}
+		}
+		if len(group.members) > 0 {
+			ctg.groups = append(ctg.groups, group)
+		}
+	}
+}
+
+// substitutesOf returns the elements substituting head, directly or
+// through other substitutes, in declaration order
+func substitutesOf(elements []models.Element, head models.Element, seen map[string]bool) []models.Element {
+	var substitutes []models.Element
+	for _, el := range elements {
+		if el.SubstitutionGroup == "" || models.LocalName(el.SubstitutionGroup) != head.Name || seen[el.Name] {
+			continue
+		}
+		seen[el.Name] = true
+		substitutes = append(substitutes, el)
+		substitutes = append(substitutes, substitutesOf(elements, el, seen)...)
+	}
+	return substitutes
+}
+
+// nameGroups assigns the Go names of the substitution groups' types, which
+// must not clash with the schema types
+func (ctg *ComplexTypeGenerator) nameGroups() {
+	namer := naming.NewNamer("substitution group type", naming.Pascal)
+	for name := range ctg.types {
+		namer.Reserve(toPascalCase(name))
+	}
+	for _, name := range ctg.bases {
+		namer.Reserve(holderName(name))
+	}
+
+	for _, group := range ctg.groups {
+		group.holder = namer.Next(group.head.Name + "Group")
+		group.iface = namer.Next(group.head.Name + "Substitute")
+		group.method = naming.Camel(group.iface)
+	}
+	ctg.wrappers = make(map[string]string)
+	for _, group := range ctg.groups {
+		for _, member := range group.members {
+			if _, ok := ctg.wrappers[member.Name]; !ok {
+				ctg.wrappers[member.Name] = namer.Next(member.Name + "Element")
+			}
+		}
+	}
+}
+
+// substitutionGroup returns the group of the head an element references,
+// or nil when the element is not substitutable
+func (ctg *ComplexTypeGenerator) substitutionGroup(elem models.Element) *substitutionGroup {
+	for _, group := range ctg.groups {
+		if elem.Name == group.head.Name && elem.Namespace == group.head.Namespace {
+			return group
+		}
+	}
+	return nil
+}
+
+// group returns the substitution group of a holder type's Go name
+func (ctg *ComplexTypeGenerator) group(goType string) (*substitutionGroup, bool) {
+	for _, group := range ctg.groups {
+		if group.holder == goType {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
+// memberType returns the Go type of the value of a member's wrapper
+func (ctg *ComplexTypeGenerator) memberType(member models.Element) string {
+	return ctg.getFieldType(models.Type{}, models.Element{Type: member.Type})
+}
+
+// memberName returns the Go literal of the XML name of a member
+func (ctg *ComplexTypeGenerator) memberName(member models.Element) string {
+	if ctg.qualified && member.Namespace != "" {
+		return fmt.Sprintf("xml.Name{Space: %q, Local: %q}", member.Namespace, member.Name)
+	}
+	return fmt.Sprintf("xml.Name{Local: %q}", member.Name)
+}
+
+// GenerateAnyUnmarshal generates an UnmarshalXML method for a type with
+// both xs:any and elements referencing substitution group heads. Its group
+// fields are named after the heads, so the other members are matched by
+// xs:any; the method moves them from Any into their fields. It returns an
+// empty string for other types. reserved are the names given to
+// GenerateFields.
+func (ctg *ComplexTypeGenerator) GenerateAnyUnmarshal(typeName string, t models.Type, reserved ...string) string {
+	if !t.Any {
+		return ""
+	}
+	elemNames, _, _ := ctg.fieldNames(typeName, t, reserved...)
+	var cases strings.Builder
+	matched := make(map[string]bool)
+	for i, elem := range t.Elements {
+		group := ctg.substitutionGroup(elem)
+		if group == nil {
+			continue
+		}
+		var names []string
+		for _, member := range group.members {
+			if member.Name != group.head.Name && !matched[member.Name] {
+				matched[member.Name] = true
+				names = append(names, fmt.Sprintf("%q", member.Name))
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		cases.WriteString(fmt.Sprintf("\t\tcase %s:\n", strings.Join(names, ", ")))
+		cases.WriteString("\t\t\tdata, err := xml.Marshal(a)\n")
+		cases.WriteString("\t\t\tif err != nil {\n\t\t\t\treturn err\n\t\t\t}\n")
+		cases.WriteString(fmt.Sprintf("\t\t\tvar g %s\n", group.holder))
+		cases.WriteString("\t\t\tif err := xml.Unmarshal(data, &g); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n")
+		switch fieldType := ctg.getFieldType(t, elem); {
+		case strings.HasPrefix(fieldType, "[]"):
+			cases.WriteString(fmt.Sprintf("\t\t\tv.%s = append(v.%s, g)\n", elemNames[i], elemNames[i]))
+		case strings.HasPrefix(fieldType, "*"):
+			cases.WriteString(fmt.Sprintf("\t\t\tv.%s = &g\n", elemNames[i]))
+		default:
+			cases.WriteString(fmt.Sprintf("\t\t\tv.%s = g\n", elemNames[i]))
+		}
+	}
+	if cases.Len() == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("// UnmarshalXML reads the members of substitution groups matched by\n")
+	b.WriteString("// xs:any into their fields\n")
+	b.WriteString(fmt.Sprintf("func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", typeName))
+	b.WriteString(fmt.Sprintf("\ttype plain %s\n", typeName))
+	b.WriteString("\tif err := d.DecodeElement((*plain)(v), &start); err != nil {\n\t\treturn err\n\t}\n")
+	b.WriteString("\tvar rest []AnyElement\n")
+	b.WriteString("\tfor _, a := range v.Any {\n")
+	b.WriteString("\t\tswitch a.XMLName.Local {\n")
+	b.WriteString(cases.String())
+	b.WriteString("\t\tdefault:\n")
+	b.WriteString("\t\t\trest = append(rest, a)\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\tv.Any = rest\n")
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+// GenerateSubstitutionGroups generates the types of elements referencing
+// the head of a substitution group. The field holds any member of the group,
+// read and written under the member's own name: an interface implemented by
+// a wrapper type per member, and a holder of the interface. Members whose
+// type others derive from also take their xsi:type into account. It returns
+// an empty string when no element references a head.
+func (ctg *ComplexTypeGenerator) GenerateSubstitutionGroups() string {
+	var b strings.Builder
+
+	memberOf := make(map[string][]*substitutionGroup)
+	var members []models.Element
+	for _, group := range ctg.groups {
+		if !group.used {
+			continue
+		}
+		var names []string
+		for _, member := range group.members {
+			names = append(names, ctg.wrappers[member.Name])
+			if len(memberOf[member.Name]) == 0 {
+				members = append(members, member)
+			}
+			memberOf[member.Name] = append(memberOf[member.Name], group)
+		}
+
+		b.WriteString(fmt.Sprintf("// %s is an element that may appear in place of %s: %s\n", group.iface, group.head.Name, strings.Join(names, ", ")))
+		b.WriteString(fmt.Sprintf("type %s interface {\n", group.iface))
+		b.WriteString(fmt.Sprintf("\t%s() (xml.Name, interface{})\n", group.method))
+		b.WriteString("}\n\n")
+
+		b.WriteString(fmt.Sprintf("// %s holds %s or an element of its substitution group\n", group.holder, group.head.Name))
+		b.WriteString(fmt.Sprintf("type %s struct {\n", group.holder))
+		b.WriteString(fmt.Sprintf("\tValue %s\n", group.iface))
+		b.WriteString("}\n\n")
+
+		b.WriteString("// MarshalXML writes the element held under its own name\n")
+		b.WriteString(fmt.Sprintf("func (g %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", group.holder))
+		b.WriteString("\tif g.Value == nil {\n\t\treturn nil\n\t}\n")
+		b.WriteString(fmt.Sprintf("\tname, value := g.Value.%s()\n", group.method))
+		b.WriteString("\treturn e.EncodeElement(value, xml.StartElement{Name: name})\n")
+		b.WriteString("}\n\n")
+
+		b.WriteString("// UnmarshalXML reads the member of the group named by start, skipping\n")
+		b.WriteString("// other elements\n")
+		b.WriteString(fmt.Sprintf("func (g *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", group.holder))
+		b.WriteString("\tswitch start.Name.Local {\n")
+		for _, member := range group.members {
+			b.WriteString(fmt.Sprintf("\tcase %q:\n", member.Name))
+			b.WriteString(fmt.Sprintf("\t\tvar v %s\n", ctg.wrappers[member.Name]))
+			b.WriteString("\t\terr := d.DecodeElement(&v.Value, &start)\n")
+			b.WriteString("\t\tg.Value = v\n")
+			b.WriteString("\t\treturn err\n")
+		}
+		b.WriteString("\t}\n")
+		b.WriteString("\treturn d.Skip()\n")
+		b.WriteString("}\n\n")
+
+		b.WriteString(ctg.generateGroupJSON(group))
+	}
+
+	for _, member := range members {
+		wrapper := ctg.wrappers[member.Name]
+		b.WriteString(fmt.Sprintf("// %s is the %s element\n", wrapper, member.Name))
+		b.WriteString(fmt.Sprintf("type %s struct {\n", wrapper))
+		b.WriteString(fmt.Sprintf("\tValue %s\n", ctg.memberType(member)))
+		b.WriteString("}\n\n")
+		for _, group := range memberOf[member.Name] {
+			b.WriteString(fmt.Sprintf("// %s implements %s\n", group.method, group.iface))
+			b.WriteString(fmt.Sprintf("func (e %s) %s() (xml.Name, interface{}) {\n", wrapper, group.method))
+			b.WriteString(fmt.Sprintf("\treturn %s, e.Value\n", ctg.memberName(member)))
+			b.WriteString("}\n\n")
+		}
+	}
+
+	return b.String()
+}
+
+// generateGroupJSON generates the JSON methods of a substitution group's
+// holder, which names the member in #element like the OpenAPI spec. Members
+// of simple types keep their value in #text.
+func (ctg *ComplexTypeGenerator) generateGroupJSON(group *substitutionGroup) string {
+	var b strings.Builder
+	ctg.imports["encoding/json"] = true
+	ctg.imports["fmt"] = true
+	ctg.usesJSONProperty = true
+
+	b.WriteString("// MarshalJSON writes the element held, with its name in #element\n")
+	b.WriteString(fmt.Sprintf("func (g %s) MarshalJSON() ([]byte, error) {\n", group.holder))
+	b.WriteString("\tvar name xml.Name\n")
+	b.WriteString("\tvar data []byte\n")
+	b.WriteString("\tvar err error\n")
+	b.WriteString("\tswitch v := g.Value.(type) {\n")
+	b.WriteString("\tcase nil:\n\t\treturn []byte(\"null\"), nil\n")
+	for _, member := range group.members {
+		b.WriteString(fmt.Sprintf("\tcase %s:\n", ctg.wrappers[member.Name]))
+		b.WriteString(fmt.Sprintf("\t\tname, _ = v.%s()\n", group.method))
+		if ctg.isObject(member) {
+			b.WriteString("\t\tdata, err = json.Marshal(v.Value)\n")
+		} else {
+			b.WriteString(fmt.Sprintf("\t\tdata, err = json.Marshal(map[string]interface{}{%q: v.Value})\n", jsonText))
+		}
+	}
+	b.WriteString("\tdefault:\n")
+	b.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s: unexpected value of type %%T\", g.Value)\n", group.holder))
+	b.WriteString("\t}\n")
+	b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn withJSONProperty(data, %q, name.Local), nil\n", jsonElement))
+	b.WriteString("}\n\n")
+
+	b.WriteString("// UnmarshalJSON reads the member of the group named by #element\n")
+	b.WriteString(fmt.Sprintf("func (g *%s) UnmarshalJSON(data []byte) error {\n", group.holder))
+	b.WriteString("\tif string(data) == \"null\" {\n\t\tg.Value = nil\n\t\treturn nil\n\t}\n")
+	b.WriteString("\tvar element struct {\n")
+	b.WriteString(fmt.Sprintf("\t\tName string          `json:%q`\n", jsonElement))
+	b.WriteString(fmt.Sprintf("\t\tText json.RawMessage `json:%q`\n", jsonText))
+	b.WriteString("\t}\n")
+	b.WriteString("\tif err := json.Unmarshal(data, &element); err != nil {\n\t\treturn err\n\t}\n")
+	b.WriteString("\tswitch element.Name {\n")
+	for _, member := range group.members {
+		value := "element.Text"
+		if ctg.isObject(member) {
+			value = "data"
+		}
+		b.WriteString(fmt.Sprintf("\tcase %q:\n", member.Name))
+		b.WriteString(fmt.Sprintf("\t\tvar v %s\n", ctg.wrappers[member.Name]))
+		b.WriteString(fmt.Sprintf("\t\terr := json.Unmarshal(%s, &v.Value)\n", value))
+		b.WriteString("\t\tg.Value = v\n")
+		b.WriteString("\t\treturn err\n")
+	}
+	b.WriteString("\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn fmt.Errorf(\"%s: unknown element %%q\", element.Name)\n", group.holder))
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// isObject reports whether the value of a member is a JSON object: a
+// generated struct or holder rather than a simple or mapped type
+func (ctg *ComplexTypeGenerator) isObject(member models.Element) bool {
+	name := models.LocalName(member.Type)
+	_, complex := ctg.types[name]
+	_, mapped := ctg.mappedTypes[name]
+	return complex && !mapped
+}
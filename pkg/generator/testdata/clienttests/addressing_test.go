@@ -0,0 +1,62 @@
+package quotes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddressing(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[r.Header.Get("SOAPAction")]))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(body, "wsa:") || c.Addressing() != nil {
+		t.Errorf("request without WS-Addressing has addressing headers:\n%s", body)
+	}
+
+	// The headers default to the endpoint and the action of the operation
+	c.EnableAddressing()
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		">" + srv.URL + "</wsa:To>",
+		">urn:quotes#GetQuote</wsa:Action>",
+		">urn:uuid:",
+		"<wsa:Address>http://www.w3.org/2005/08/addressing/anonymous</wsa:Address>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request lacks %s:\n%s", want, body)
+		}
+	}
+
+	c.SetAddressing("urn:quotes", "http://client/replies", "urn:uuid:1")
+	if _, err := c.SetQuote("ACME", 10); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		">urn:quotes</wsa:To>",
+		">urn:quotes#SetQuote</wsa:Action>",
+		"<wsa:Address>http://client/replies</wsa:Address>",
+		">urn:uuid:1</wsa:RelatesTo>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request lacks %s:\n%s", want, body)
+		}
+	}
+	if wsa := c.Addressing(); wsa == nil || wsa.To != "urn:quotes" {
+		t.Errorf("Addressing() = %+v, want the SetAddressing values", wsa)
+	}
+}
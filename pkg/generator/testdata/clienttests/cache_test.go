@@ -0,0 +1,60 @@
+package quotes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var calls, chunked atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "text/xml")
+		if chunked.Load() == 1 {
+			// Flushing before writing sends the response without a length
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(quoteResponses[r.Header.Get("SOAPAction")]))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, WithCache(CacheOptions{TTL: 100 * time.Millisecond}))
+	get := func(symbol string, wantCalls int32) {
+		t.Helper()
+		if price, err := c.GetQuote(symbol); err != nil || price != 9.5 {
+			t.Fatalf("GetQuote(%s) = %v, %v", symbol, price, err)
+		}
+		if n := calls.Load(); n != wantCalls {
+			t.Fatalf("after GetQuote(%s) the service got %d calls, want %d", symbol, n, wantCalls)
+		}
+	}
+
+	// A miss calls the service, a hit doesn't and other requests miss
+	get("ACME", 1)
+	get("ACME", 1)
+	get("INIT", 2)
+
+	// Expired responses are fetched again
+	time.Sleep(150 * time.Millisecond)
+	get("ACME", 3)
+	get("ACME", 3)
+
+	// Chunked responses are cached once read
+	chunked.Store(1)
+	c.ClearCache()
+	get("ACME", 4)
+	get("ACME", 4)
+
+	// Operations that aren't reads aren't cached
+	for i := 0; i < 2; i++ {
+		if ok, err := c.SetQuote("ACME", 10); err != nil || !ok {
+			t.Fatalf("SetQuote() = %v, %v", ok, err)
+		}
+	}
+	if n := calls.Load(); n != 6 {
+		t.Errorf("the service got %d calls, want 6", n)
+	}
+}
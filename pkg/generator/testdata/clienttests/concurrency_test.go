@@ -0,0 +1,83 @@
+package quotes
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSharedClient(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[r.Header.Get("SOAPAction")]))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	// Sequential calls reuse one connection
+	c := NewClient(srv.URL, WithHeaders(map[string]string{"X-Client": "test"}))
+	for i := 0; i < 10; i++ {
+		if _, err := c.GetQuote("ACME"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("10 calls opened %d connections, want 1", n)
+	}
+
+	// Calls share the client with setters and accessors, for the race
+	// detector to check
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := c.GetQuoteContext(context.Background(), "ACME"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			c.SetHeader("X-Request", strconv.Itoa(i))
+			c.SetBasicAuth("user", strconv.Itoa(i))
+			c.Use(func(next CallFunc) CallFunc { return next })
+			c.EnableAddressing()
+			c.SetEndpoints([]string{srv.URL})
+			_ = c.Headers()
+			_ = c.Security()
+			_ = c.URL()
+			_ = c.HTTPClient()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The connections opened meanwhile are kept for the next calls
+	opened := conns.Load()
+	for i := 0; i < 10; i++ {
+		if _, err := c.GetQuote("ACME"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := conns.Load(); n != opened {
+		t.Errorf("10 calls after concurrent ones opened %d connections, want none", n-opened)
+	}
+}
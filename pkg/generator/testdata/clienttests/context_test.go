@@ -0,0 +1,63 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type contextKey struct{}
+
+func TestContext(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[r.Header.Get("SOAPAction")]))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	var value interface{}
+	c := NewClient(srv.URL, WithMiddleware(func(next CallFunc) CallFunc {
+		return func(ctx context.Context, soapAction string, request, response interface{}) error {
+			value = ctx.Value(contextKey{})
+			return next(ctx, soapAction, request, response)
+		}
+	}))
+
+	// A deadline ends a call the service doesn't answer
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), contextKey{}, "trace"), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.GetQuoteContext(ctx, "ACME"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetQuoteContext() past the deadline = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetQuoteContext() returned after %v, want the deadline", elapsed)
+	}
+	if value != "trace" {
+		t.Errorf("the middleware got the context value %v, want trace", value)
+	}
+
+	// A canceled call doesn't reach the service
+	calls.Store(0)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.SetQuoteContext(ctx, "ACME", 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("SetQuoteContext() canceled = %v, want context.Canceled", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("the service got %d calls after the cancellation, want 0", n)
+	}
+}
@@ -0,0 +1,93 @@
+package quotes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDefaultEndpoints(t *testing.T) {
+	c := NewClient("")
+	if c.URL() != DefaultEndpoints[0] {
+		t.Errorf("URL() = %s, want %s", c.URL(), DefaultEndpoints[0])
+	}
+	if got := c.HealthyEndpoints(); !reflect.DeepEqual(got, DefaultEndpoints) {
+		t.Errorf("HealthyEndpoints() = %v, want %v", got, DefaultEndpoints)
+	}
+
+	// A client of one URL calls it alone
+	c = NewClient("http://quotes.example.com")
+	if got := c.HealthyEndpoints(); !reflect.DeepEqual(got, []string{"http://quotes.example.com"}) {
+		t.Errorf("HealthyEndpoints() = %v, want the URL", got)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	down := newQuoteService(t, http.StatusServiceUnavailable)
+	up := newQuoteService(t, http.StatusOK)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	c := NewClient("", WithEndpoints(closed.URL, down.URL, up.URL), WithEndpointCooldown(100*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if price, err := c.GetQuote("ACME"); err != nil || price != 9.5 {
+			t.Fatalf("GetQuote() = %v, %v", price, err)
+		}
+	}
+
+	// The endpoints that failed are passed over during their cooldown
+	if got := c.HealthyEndpoints(); !reflect.DeepEqual(got, []string{up.URL}) {
+		t.Errorf("HealthyEndpoints() = %v, want %s", got, up.URL)
+	}
+	if n, m := down.calls.Load(), up.calls.Load(); n != 1 || m != 3 {
+		t.Errorf("the endpoints got %d and %d calls, want 1 and 3", n, m)
+	}
+
+	// and tried again after it
+	down.status.Store(http.StatusOK)
+	time.Sleep(150 * time.Millisecond)
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if n := down.calls.Load(); n != 2 {
+		t.Errorf("the recovered endpoint got %d calls, want 2", n)
+	}
+	if got := c.HealthyEndpoints(); len(got) != 2 {
+		t.Errorf("HealthyEndpoints() = %v, want the two open endpoints", got)
+	}
+}
+
+func TestFailoverAllDown(t *testing.T) {
+	first := newQuoteService(t, http.StatusServiceUnavailable)
+	second := newQuoteService(t, http.StatusBadGateway)
+
+	// When every endpoint is down the last response is returned
+	c := NewClient(first.URL)
+	c.SetEndpoints([]string{first.URL, second.URL})
+	if _, err := c.GetQuote("ACME"); err == nil {
+		t.Error("GetQuote() succeeded with every endpoint down")
+	}
+	if got := c.HealthyEndpoints(); len(got) != 0 {
+		t.Errorf("HealthyEndpoints() = %v, want none", got)
+	}
+	if n, m := first.calls.Load(), second.calls.Load(); n != 1 || m != 1 {
+		t.Errorf("the endpoints got %d and %d calls, want 1 each", n, m)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	a := newQuoteService(t, http.StatusOK)
+	b := newQuoteService(t, http.StatusOK)
+
+	c := NewClient("", WithEndpoints(a.URL, b.URL), WithBalancing(RoundRobin))
+	for i := 0; i < 4; i++ {
+		if _, err := c.GetQuote("ACME"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, m := a.calls.Load(), b.calls.Load(); n != 2 || m != 2 {
+		t.Errorf("the endpoints got %d and %d calls, want 2 each", n, m)
+	}
+}
@@ -0,0 +1,75 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		signature = r.Header.Get("X-Signature")
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[r.Header.Get("SOAPAction")]))
+	}))
+	defer srv.Close()
+
+	var trace []string
+	named := func(name string) Middleware {
+		return func(next CallFunc) CallFunc {
+			return func(ctx context.Context, soapAction string, request, response interface{}) error {
+				trace = append(trace, name+" "+soapAction)
+				err := next(ctx, soapAction, request, response)
+				trace = append(trace, name+" done")
+				return err
+			}
+		}
+	}
+	sign := func(next CallFunc) CallFunc {
+		return func(ctx context.Context, soapAction string, request, response interface{}) error {
+			return next(WithHeader(ctx, "X-Signature", "signed"), soapAction, request, response)
+		}
+	}
+
+	// Middleware added first runs outermost, and headers added to the
+	// context are sent
+	c := NewClient(srv.URL, WithMiddleware(named("outer")))
+	c.Use(named("inner"), sign)
+	if price, err := c.GetQuote("ACME"); err != nil || price != 9.5 {
+		t.Fatalf("GetQuote() = %v, %v", price, err)
+	}
+	want := "outer urn:quotes#GetQuote,inner urn:quotes#GetQuote,inner done,outer done"
+	if got := strings.Join(trace, ","); got != want {
+		t.Errorf("middleware ran as %s, want %s", got, want)
+	}
+	if signature != "signed" {
+		t.Errorf("X-Signature = %q, want the header of the middleware", signature)
+	}
+
+	// Middleware can answer without calling the service
+	denied := errors.New("denied")
+	c.Use(func(next CallFunc) CallFunc {
+		return func(ctx context.Context, soapAction string, request, response interface{}) error {
+			if soapAction == "urn:quotes#SetQuote" {
+				return denied
+			}
+			return next(ctx, soapAction, request, response)
+		}
+	})
+	calls = 0
+	if _, err := c.SetQuote("ACME", 10); !errors.Is(err, denied) {
+		t.Errorf("SetQuote() = %v, want the error of the middleware", err)
+	}
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("the service got %d calls, want only GetQuote", calls)
+	}
+}
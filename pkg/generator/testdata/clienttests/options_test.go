@@ -0,0 +1,76 @@
+package quotes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	var header http.Header
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		header, body = r.Header, string(data)
+		w.Header().Set("Content-Type", "application/soap+xml")
+		w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>
+  <GetQuoteResponse xmlns="urn:quotes"><price>9.5</price></GetQuoteResponse>
+</env:Body></env:Envelope>`))
+	}))
+	defer srv.Close()
+
+	var wrapped int
+	c := NewClient(srv.URL,
+		WithHeaders(map[string]string{"X-Tenant": "acme"}),
+		WithBasicAuth("user", "secret"),
+		WithSOAPVersion("1.2"),
+		WithMiddleware(func(next CallFunc) CallFunc {
+			return func(ctx context.Context, soapAction string, request, response interface{}) error {
+				wrapped++
+				return next(ctx, soapAction, request, response)
+			}
+		}))
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("X-Tenant") != "acme" || !strings.HasPrefix(header.Get("Content-Type"), "application/soap+xml") {
+		t.Errorf("request headers = %v", header)
+	}
+	if !strings.Contains(body, "UsernameToken") || !strings.Contains(body, ">user<") {
+		t.Errorf("request lacks the UsernameToken:\n%s", body)
+	}
+	if wrapped != 1 {
+		t.Errorf("the middleware wrapped %d calls, want 1", wrapped)
+	}
+
+	// The accessors return the settings of the options
+	if ws := c.Security(); ws == nil || ws.Username != "user" || ws.Password != "secret" {
+		t.Errorf("Security() = %+v", ws)
+	}
+	if c.SOAPVersion() != "1.2" {
+		t.Errorf("SOAPVersion() = %s, want 1.2", c.SOAPVersion())
+	}
+
+	// and copies, which don't change the client
+	c.Headers()["X-Tenant"] = "other"
+	c.Security().Username = "other"
+	if c.Headers()["X-Tenant"] != "acme" || c.Security().Username != "user" {
+		t.Error("changing the copies of the accessors changed the client")
+	}
+
+	// The setters still change the settings
+	c.SetHeader("X-Trace", "1")
+	c.SetBasicAuth("admin", "secret")
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("X-Tenant") != "acme" || header.Get("X-Trace") != "1" || !strings.Contains(body, ">admin<") {
+		t.Errorf("the setters didn't apply: headers %v, body\n%s", header, body)
+	}
+	if got := c.Headers(); len(got) != 2 {
+		t.Errorf("Headers() = %v, want X-Tenant and X-Trace", got)
+	}
+}
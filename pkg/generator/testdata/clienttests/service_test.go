@@ -0,0 +1,39 @@
+package quotes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// quoteResponses are the responses of the operations by SOAP action
+var quoteResponses = map[string]string{
+	`"urn:quotes#GetQuote"`: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <GetQuoteResponse xmlns="urn:quotes"><price>9.5</price></GetQuoteResponse>
+</soap:Body></soap:Envelope>`,
+	`"urn:quotes#SetQuote"`: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <SetQuoteResponse xmlns="urn:quotes"><ok>true</ok></SetQuoteResponse>
+</soap:Body></soap:Envelope>`,
+}
+
+// quoteService is a service answering GetQuote with status, counting its
+// calls
+type quoteService struct {
+	*httptest.Server
+	calls  atomic.Int32
+	status atomic.Int32
+}
+
+func newQuoteService(t *testing.T, status int) *quoteService {
+	s := &quoteService{}
+	s.status.Store(int32(status))
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.calls.Add(1)
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(int(s.status.Load()))
+		w.Write([]byte(quoteResponses[`"urn:quotes#GetQuote"`]))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
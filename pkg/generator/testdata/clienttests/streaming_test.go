@@ -0,0 +1,58 @@
+package quotes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreaming(t *testing.T) {
+	// Chunked responses are of unknown length, so they are streamed
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		if r.Header.Get("SOAPAction") == `"urn:quotes#SetQuote"` {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <soap:Fault><faultcode>soap:Server</faultcode><faultstring>read only</faultstring></soap:Fault>
+</soap:Body></soap:Envelope>`))
+			return
+		}
+		response := quoteResponses[r.Header.Get("SOAPAction")]
+		half := len(response) / 2
+		w.Write([]byte(response[:half]))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(response[half:]))
+	}))
+	defer srv.Close()
+
+	for _, threshold := range []int64{DefaultStreamThreshold, -1} {
+		c := NewClient(srv.URL, WithStreamThreshold(threshold))
+		price, err := c.GetQuote("ACME")
+		if err != nil || price != 9.5 {
+			t.Errorf("GetQuote() with WithStreamThreshold(%d) = %v, %v, want 9.5", threshold, price, err)
+		}
+	}
+
+	// CallStream returns the content of the Body as it is read
+	c := NewClient(srv.URL)
+	body, err := c.CallStream(context.Background(), "urn:quotes#GetQuote", &GetQuoteRequest{Symbol: "ACME"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || !strings.Contains(string(data), `<price>9.5</price>`) || strings.Contains(string(data), "Envelope") {
+		t.Errorf("CallStream() read %q, %v, want the content of the Body", data, err)
+	}
+
+	// Faults are errors rather than content
+	_, err = c.CallStream(context.Background(), "urn:quotes#SetQuote", &SetQuoteRequest{})
+	var fault *SOAPFault
+	if !errors.As(err, &fault) || fault.String != "read only" {
+		t.Errorf("CallStream() of a fault = %v, want the SOAPFault", err)
+	}
+}
@@ -0,0 +1,127 @@
+package quotes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProxy(t *testing.T) {
+	var host string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[`"urn:quotes#GetQuote"`]))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	c := NewClient("http://quotes.invalid/quotes", WithProxy(proxyURL))
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if host != "quotes.invalid" {
+		t.Errorf("the proxy got a request to %q, want quotes.invalid", host)
+	}
+
+	// Without a proxy requests go direct, and by default the environment
+	// chooses
+	if transport := NewClient("", WithProxy(nil)).HTTPClient().Transport.(*http.Transport); transport.Proxy != nil {
+		t.Error("WithProxy(nil) kept a proxy")
+	}
+	if transport := NewClient("", WithDialTimeout(time.Second)).HTTPClient().Transport.(*http.Transport); transport.Proxy == nil {
+		t.Error("the client ignores the proxy environment variables")
+	}
+}
+
+func TestHTTP2(t *testing.T) {
+	var proto int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto = r.ProtoMajor
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[`"urn:quotes#GetQuote"`]))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	for _, tt := range []struct {
+		enabled bool
+		want    int
+	}{{true, 2}, {false, 1}} {
+		c := NewClient(srv.URL, WithHTTP2(tt.enabled))
+		c.HTTPClient().Transport.(*http.Transport).TLSClientConfig = &tls.Config{RootCAs: roots}
+		if _, err := c.GetQuote("ACME"); err != nil {
+			t.Fatal(err)
+		}
+		if proto != tt.want {
+			t.Errorf("WithHTTP2(%v) called the service with HTTP/%d, want HTTP/%d", tt.enabled, proto, tt.want)
+		}
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	c := NewClient("", WithDialTimeout(time.Second), WithTLSHandshakeTimeout(2*time.Second),
+		WithResponseHeaderTimeout(3*time.Second), WithTimeout(4*time.Second))
+	transport := c.HTTPClient().Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second || c.HTTPClient().Timeout != 4*time.Second {
+		t.Errorf("timeouts = %v, %v, %v", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout, c.HTTPClient().Timeout)
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[`"urn:quotes#GetQuote"`]))
+	}))
+	defer slow.Close()
+	for name, opt := range map[string]Option{
+		"WithResponseHeaderTimeout": WithResponseHeaderTimeout(50 * time.Millisecond),
+		"WithTimeout":               WithTimeout(50 * time.Millisecond),
+	} {
+		if _, err := NewClient(slow.URL, opt).GetQuote("ACME"); err == nil {
+			t.Errorf("%s: a slow service didn't time out", name)
+		}
+	}
+	if _, err := NewClient(slow.URL, WithTimeout(time.Second)).GetQuote("ACME"); err != nil {
+		t.Errorf("a call within its timeout failed: %v", err)
+	}
+}
+
+// countingTransport counts the requests it sends
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(quoteResponses[`"urn:quotes#GetQuote"`]))
+	}))
+	defer srv.Close()
+
+	// The client given is used as is, whatever the transport options
+	transport := &countingTransport{}
+	client := &http.Client{Transport: transport}
+	c := NewClient(srv.URL, WithHTTPClient(client), WithTimeout(time.Second))
+	if c.HTTPClient() != client {
+		t.Fatal("HTTPClient() isn't the client of WithHTTPClient")
+	}
+	if _, err := c.GetQuote("ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if n := transport.requests.Load(); n != 1 {
+		t.Errorf("the transport of WithHTTPClient sent %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeTypes(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <GetMeetingResponse xmlns="urn:schedule"><start>2024-03-01T09:30:00+01:00</start><at>17:45:00</at></GetMeetingResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer srv.Close()
+
+	// Dates are sent without a time, and time zones are kept
+	day := XSDDate{time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)}
+	resp, err := NewClient(srv.URL).GetMeeting(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "<day>2024-03-01</day>") {
+		t.Errorf("request = %s, want the date alone", body)
+	}
+	if want := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC); !resp.Start.Equal(want) {
+		t.Errorf("start = %v, want %v", resp.Start, want)
+	}
+	if _, offset := resp.Start.Zone(); offset != 3600 {
+		t.Errorf("start has offset %d, want the +01:00 of the response", offset)
+	}
+	if resp.At == nil || resp.At.Hour() != 17 || resp.At.Minute() != 45 || resp.At.Location() != time.UTC {
+		t.Errorf("at = %v, want 17:45 UTC", resp.At)
+	}
+
+	tests := []struct {
+		name  string
+		value interface{ MarshalText() ([]byte, error) }
+		text  string
+	}{
+		{"dateTime", &XSDDateTime{time.Date(2024, 3, 1, 9, 30, 0, 500, time.FixedZone("", -5*3600))}, "2024-03-01T09:30:00.0000005-05:00"},
+		{"dateTime without a zone", &XSDDateTime{time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)}, "2024-03-01T09:30:00Z"},
+		{"date", &XSDDate{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, "2024-03-01"},
+		{"time", &XSDTime{time.Date(0, 1, 1, 17, 45, 0, 0, time.UTC)}, "17:45:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := tt.value.MarshalText()
+			if err != nil || string(text) != tt.text {
+				t.Errorf("MarshalText() = %s, %v, want %s", text, err, tt.text)
+			}
+			data, err := json.Marshal(tt.value)
+			if err != nil || string(data) != `"`+tt.text+`"` {
+				t.Errorf("json.Marshal() = %s, %v, want the XSD format", data, err)
+			}
+		})
+	}
+
+	// Empty elements are the zero time and invalid ones errors
+	var empty struct {
+		Start XSDDateTime `xml:"start"`
+	}
+	if err := xml.Unmarshal([]byte(`<r><start> </start></r>`), &empty); err != nil || !empty.Start.IsZero() {
+		t.Errorf("empty start = %v, %v, want the zero time", empty.Start, err)
+	}
+	if err := xml.Unmarshal([]byte(`<r><start>March 1st</start></r>`), &empty); err == nil {
+		t.Error("xml.Unmarshal() accepted an invalid dateTime")
+	}
+	var date XSDDate
+	if err := json.Unmarshal([]byte(`"2024-03-01+02:00"`), &date); err != nil || date.Day() != 1 {
+		t.Errorf("json.Unmarshal() of a date with a zone = %v, %v", date, err)
+	}
+}
@@ -0,0 +1,41 @@
+package zoo
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubstitutionGroupWithAny(t *testing.T) {
+	data := `<GetAnimalsResponse xmlns="urn:zoo">
+  <dog>Rex</dog>
+  <cat>Tom</cat>
+  <keeper xmlns="urn:staff">Ann</keeper>
+  <dog>Fido</dog>
+</GetAnimalsResponse>`
+	var resp GetAnimalsResponse
+	if err := xml.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// The members go to their field, in order, and other elements to Any
+	want := []AnimalGroup{{DogElement{"Rex"}}, {CatElement{"Tom"}}, {DogElement{"Fido"}}}
+	if !reflect.DeepEqual(resp.Animal, want) {
+		t.Errorf("Animal = %+v, want %+v", resp.Animal, want)
+	}
+	if len(resp.Any) != 1 || resp.Any[0].XMLName != (xml.Name{Space: "urn:staff", Local: "keeper"}) {
+		t.Errorf("Any = %+v, want the keeper", resp.Any)
+	}
+
+	// and are written back under their own names
+	out, err := xml.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, elem := range []string{"<dog>Rex</dog>", "<cat>Tom</cat>", "<dog>Fido</dog>", ">Ann</keeper>"} {
+		if !strings.Contains(string(out), elem) {
+			t.Errorf("Marshal() = %s, want %s", out, elem)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Go types for xs:dateTime, xs:date and xs:time
+const (
+	// TimeTypeString maps date and time types to plain strings
+	TimeTypeString = "string"
+	// TimeTypeTime maps date and time types to generated wrappers around
+	// time.Time that (un)marshal the XSD lexical formats
+	TimeTypeTime = "time.Time"
+)
+
+// xsdTimeTypes maps XSD date and time types to their generated Go types
+var xsdTimeTypes = map[string]string{
+	"dateTime": "XSDDateTime",
+	"date":     "XSDDate",
+	"time":     "XSDTime",
+}
+
+// xsdTimeTypeCode holds the generated code of each time type
+var xsdTimeTypeCode = map[string]string{
+	"XSDDateTime": `// XSDDateTime is an xs:dateTime. Values without a time zone are read as UTC;
+// values are written in RFC 3339 format with their time zone.
+type XSDDateTime struct {
+	time.Time
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t XSDDateTime) MarshalText() ([]byte, error) {
+	return []byte(t.Format(time.RFC3339Nano)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *XSDDateTime) UnmarshalText(data []byte) error {
+	parsed, err := parseXSDTime(string(data), "2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999")
+	t.Time = parsed
+	return err
+}
+
+// MarshalJSON writes the XSD format rather than the one of time.Time
+func (t XSDDateTime) MarshalJSON() ([]byte, error) {
+	text, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON reads the XSD format
+func (t *XSDDateTime) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(text))
+}
+
+`,
+	"XSDDate": `// XSDDate is an xs:date. Only the date is written; a time zone in the
+// input is kept in the value's location.
+type XSDDate struct {
+	time.Time
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t XSDDate) MarshalText() ([]byte, error) {
+	return []byte(t.Format("2006-01-02")), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *XSDDate) UnmarshalText(data []byte) error {
+	parsed, err := parseXSDTime(string(data), "2006-01-02Z07:00", "2006-01-02")
+	t.Time = parsed
+	return err
+}
+
+// MarshalJSON writes the XSD format rather than the one of time.Time
+func (t XSDDate) MarshalJSON() ([]byte, error) {
+	text, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON reads the XSD format
+func (t *XSDDate) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(text))
+}
+
+`,
+	"XSDTime": `// XSDTime is an xs:time on the zero date. Values without a time zone are
+// read as UTC; values are written with their time zone.
+type XSDTime struct {
+	time.Time
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t XSDTime) MarshalText() ([]byte, error) {
+	return []byte(t.Format("15:04:05.999999999Z07:00")), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *XSDTime) UnmarshalText(data []byte) error {
+	parsed, err := parseXSDTime(string(data), "15:04:05.999999999Z07:00", "15:04:05.999999999")
+	t.Time = parsed
+	return err
+}
+
+// MarshalJSON writes the XSD format rather than the one of time.Time
+func (t XSDTime) MarshalJSON() ([]byte, error) {
+	text, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON reads the XSD format
+func (t *XSDTime) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(text))
+}
+
+`,
+}
+
+// parseXSDTimeCode is the parsing helper shared by the time types
+const parseXSDTimeCode = `// parseXSDTime parses value with the first matching layout. Empty values
+// are the zero time.
+func parseXSDTime(value string, layouts ...string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+`
+
+// timeGoType returns the generated Go type for an XSD date or time type
+// under the time type option, recording the imports and helper type it needs
+func (ctg *ComplexTypeGenerator) timeGoType(xsdType string) (string, bool) {
+	if ctg.timeType != TimeTypeTime {
+		return "", false
+	}
+
+	goType, ok := xsdTimeTypes[models.LocalName(xsdType)]
+	if ok {
+		ctg.timeTypes[goType] = true
+		ctg.imports["encoding/json"] = true
+		ctg.imports["time"] = true
+		ctg.imports["strings"] = true
+	}
+	return goType, ok
+}
+
+// SetTimeType sets the Go type used for xs:dateTime, xs:date and xs:time,
+// either TimeTypeString or TimeTypeTime
+func (ctg *ComplexTypeGenerator) SetTimeType(timeType string) {
+	ctg.timeType = timeType
+}
+
+// GenerateTimeTypes generates the time types used by the generated fields.
+// It returns an empty string when none are used.
+func (ctg *ComplexTypeGenerator) GenerateTimeTypes() string {
+	if len(ctg.timeTypes) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(ctg.timeTypes))
+	for name := range ctg.timeTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(xsdTimeTypeCode[name])
+	}
+	b.WriteString(parseXSDTimeCode)
+
+	return b.String()
+}
+
+// SetTimeType sets the Go type used for xs:dateTime, xs:date and xs:time,
+// either TimeTypeString (the default) or TimeTypeTime
+func (g *Generator) SetTimeType(timeType string) {
+	g.timeType = timeType
+}
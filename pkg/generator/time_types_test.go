@@ -0,0 +1,61 @@
+package generator
+
+import "testing"
+
+func TestGenerateTimeTypes(t *testing.T) {
+	wsdl := testWSDL(`<xs:element name="GetMeeting"><xs:complexType><xs:sequence>
+        <xs:element name="day" type="xs:date"/>
+      </xs:sequence></xs:complexType></xs:element>
+      <xs:element name="GetMeetingResponse"><xs:complexType><xs:sequence>
+        <xs:element name="start" type="xs:dateTime"/>
+      </xs:sequence></xs:complexType></xs:element>`, "GetMeeting")
+
+	tests := []struct {
+		name     string
+		timeType string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:     "strings by default",
+			timeType: "",
+			want: []string{
+				"Day     string   `xml:\"day\" json:\"day\"`",
+				"Start   string   `xml:\"start\" json:\"start\"`",
+			},
+			unwanted: []string{"XSDDate", "parseXSDTime", `"time"`},
+		},
+		{
+			name:     "time wrappers",
+			timeType: TimeTypeTime,
+			want: []string{
+				"Day     XSDDate  `xml:\"day\" json:\"day\"`",
+				"Start   XSDDateTime `xml:\"start\" json:\"start\"`",
+				"type XSDDate struct {\n\ttime.Time\n}",
+				"type XSDDateTime struct {\n\ttime.Time\n}",
+				"func (t *XSDDate) UnmarshalText(data []byte) error {",
+				"func (t XSDDateTime) MarshalJSON() ([]byte, error) {",
+				"func parseXSDTime(value string, layouts ...string) (time.Time, error) {",
+			},
+			// Only the wrappers of the schema's types are generated
+			unwanted: []string{"type XSDTime struct"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, wsdl, func(g *Generator) {
+				if tt.timeType != "" {
+					g.SetTimeType(tt.timeType)
+				}
+			})
+			assertContains(t, files, "types.go", tt.want, tt.unwanted...)
+		})
+	}
+}
+
+// TestGeneratedTimeTypes sends and reads dates and times with the
+// wrappers of --time-type=time.Time
+func TestGeneratedTimeTypes(t *testing.T) {
+	testGenerated(t, "schedule", "scheduletests", func(g *Generator) { g.SetTimeType(TimeTypeTime) }, "time_test.go")
+}
@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// TypeMapping replaces the Go types generated for XSD types and elements
+// with existing ones, such as a money type of an internal package. The
+// mapped types must read and write the XML of the type they replace:
+// xml.Marshaler and xml.Unmarshaler for elements, encoding.TextMarshaler
+// and encoding.TextUnmarshaler for attributes and simple content, unless
+// the standard encoding already fits.
+type TypeMapping struct {
+	// Types maps XSD type names such as tns:MoneyType or xs:decimal.
+	// Types are matched by local name like the generated Go types, so the
+	// prefix is optional. Mapped schema types are not generated.
+	Types map[string]GoType `yaml:"types" json:"types,omitempty"`
+	// Elements maps the elements of a complex type, named Type.element, or
+	// every element with a name. They take precedence over Types.
+	Elements map[string]GoType `yaml:"elements" json:"elements,omitempty"`
+}
+
+// GoType is a Go type and the package that declares it
+type GoType struct {
+	// Type is written as in the generated code, such as *money.Amount
+	Type string `yaml:"type" json:"type"`
+	// Import is the path of the package Type refers to, if any
+	Import string `yaml:"import" json:"import,omitempty"`
+}
+
+// UnmarshalYAML accepts a GoType as a mapping with type and import, or as
+// a string parsed by ParseGoType
+func (t *GoType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		parsed, err := ParseGoType(node.Value)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	}
+
+	type plain GoType
+	return node.Decode((*plain)(t))
+}
+
+// ParseGoType parses a Go type qualified with the import path of its
+// package, such as github.com/acme/money.Amount, *time.Time or []byte. The
+// package name is taken to be the last element of the path; packages named
+// otherwise need a GoType with an explicit Import.
+func ParseGoType(s string) (GoType, error) {
+	s = strings.TrimSpace(s)
+	expr := strings.TrimLeft(s, "*[]")
+	modifiers := s[:len(s)-len(expr)]
+
+	dot := strings.LastIndex(expr, ".")
+	if dot == -1 {
+		if !token.IsIdentifier(expr) {
+			return GoType{}, fmt.Errorf("invalid Go type %q", s)
+		}
+		return GoType{Type: s}, nil
+	}
+	path, name := expr[:dot], expr[dot+1:]
+	pkg := path[strings.LastIndex(path, "/")+1:]
+	if !token.IsIdentifier(pkg) || !token.IsIdentifier(name) {
+		return GoType{}, fmt.Errorf("invalid Go type %q (give type and import for packages not named after their path)", s)
+	}
+	return GoType{Type: modifiers + pkg + "." + name, Import: path}, nil
+}
+
+// LoadTypeMapping reads a YAML type mapping such as
+//
+//	types:
+//	  tns:MoneyType: github.com/acme/internal/money.Amount
+//	  xs:dateTime: "*time.Time"
+//	elements:
+//	  OrderType.id:
+//	    type: uuid.UUID
+//	    import: github.com/google/uuid
+func LoadTypeMapping(path string) (*TypeMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read type mapping: %w", err)
+	}
+
+	var mapping TypeMapping
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse type mapping: %w", err)
+	}
+	for kind, entries := range map[string]map[string]GoType{"type": mapping.Types, "element": mapping.Elements} {
+		for name, goType := range entries {
+			if goType.Type == "" {
+				return nil, fmt.Errorf("%s %s: missing Go type", kind, name)
+			}
+			if goType.Import == "" && strings.Contains(goType.Type, ".") {
+				return nil, fmt.Errorf("%s %s: Go type %s needs an import", kind, name, goType.Type)
+			}
+		}
+	}
+
+	return &mapping, nil
+}
+
+// Check returns an error when a mapping names a type or element that def
+// doesn't declare, which is likely a typo
+func (m *TypeMapping) Check(def *models.Definitions) error {
+	if m == nil {
+		return nil
+	}
+
+	declared := make(map[string]bool)
+	for _, t := range def.Types {
+		declared[t.Name] = true
+	}
+	for _, st := range def.SimpleTypes {
+		declared[st.Name] = true
+	}
+	var unknown []string
+	for name := range m.Types {
+		// Built-in XSD types are always known
+		if !declared[models.LocalName(name)] && mapXSDTypeToGo(name) == toPascalCase(models.LocalName(name)) {
+			unknown = append(unknown, "type "+name)
+		}
+	}
+
+	elements := make(map[string]bool)
+	for _, t := range def.Types {
+		for _, elem := range t.Elements {
+			elements[elem.Name] = true
+			elements[t.Name+"."+elem.Name] = true
+		}
+	}
+	for _, elem := range def.Elements {
+		elements[elem.Name] = true
+	}
+	for name := range m.Elements {
+		if !elements[models.LocalName(name)] {
+			unknown = append(unknown, "element "+name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("type mapping for undeclared %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// SetTypeMapping replaces generated Go types with the mapped ones. Keys are
+// matched by local name.
+func (ctg *ComplexTypeGenerator) SetTypeMapping(mapping *TypeMapping) {
+	ctg.mappedTypes = make(map[string]GoType)
+	ctg.mappedElements = make(map[string]GoType)
+	if mapping == nil {
+		return
+	}
+	for name, goType := range mapping.Types {
+		ctg.mappedTypes[models.LocalName(name)] = goType
+	}
+	for name, goType := range mapping.Elements {
+		ctg.mappedElements[models.LocalName(name)] = goType
+	}
+}
+
+// mappedType returns the Go type an XSD type is mapped to, recording its
+// import
+func (ctg *ComplexTypeGenerator) mappedType(xsdType string) (string, bool) {
+	goType, ok := ctg.mappedTypes[models.LocalName(xsdType)]
+	return ctg.use(goType, ok)
+}
+
+// mappedElement returns the Go type an element of t is mapped to, by its
+// qualified name first, recording its import
+func (ctg *ComplexTypeGenerator) mappedElement(t models.Type, elem models.Element) (string, bool) {
+	goType, ok := ctg.mappedElements[t.Name+"."+elem.Name]
+	if !ok {
+		goType, ok = ctg.mappedElements[elem.Name]
+	}
+	return ctg.use(goType, ok)
+}
+
+// use records the import of a mapped Go type
+func (ctg *ComplexTypeGenerator) use(goType GoType, ok bool) (string, bool) {
+	if !ok {
+		return "", false
+	}
+	if goType.Import != "" {
+		ctg.imports[goType.Import] = true
+		ctg.mappedImports[goType.Import] = true
+	}
+	return goType.Type, true
+}
+
+// MappedImports returns the packages of the mapped types used so far,
+// sorted. Unlike Imports, it leaves out the packages of generated helpers.
+func (ctg *ComplexTypeGenerator) MappedImports() []string {
+	imports := make([]string, 0, len(ctg.mappedImports))
+	for path := range ctg.mappedImports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// SetTypeMapping replaces generated Go types with existing ones. Mapped
+// packages must be resolvable by the module of the output directory.
+func (g *Generator) SetTypeMapping(mapping *TypeMapping) {
+	g.typeMapping = mapping
+}
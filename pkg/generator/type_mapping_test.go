@@ -0,0 +1,92 @@
+package generator
+
+import "testing"
+
+func TestGenerateTypeMapping(t *testing.T) {
+	schema := `<xs:complexType name="MoneyType"><xs:sequence>
+        <xs:element name="amount" type="xs:decimal"/>
+      </xs:sequence></xs:complexType>
+      <xs:complexType name="OrderType"><xs:sequence>
+        <xs:element name="id" type="xs:string"/>
+        <xs:element name="total" type="tns:MoneyType"/>
+        <xs:element name="placed" type="xs:dateTime"/>
+      </xs:sequence></xs:complexType>
+      <xs:complexType name="ItemType"><xs:sequence>
+        <xs:element name="id" type="xs:string"/>
+        <xs:element name="note" type="xs:string"/>
+      </xs:sequence></xs:complexType>`
+
+	tests := []struct {
+		name     string
+		mapping  TypeMapping
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:    "schema type",
+			mapping: TypeMapping{Types: map[string]GoType{"tns:MoneyType": {Type: "money.Amount", Import: "github.com/acme/money"}}},
+			want: []string{
+				`"github.com/acme/money"`,
+				"Total  money.Amount `xml:\"total\" json:\"total\"`",
+			},
+			unwanted: []string{"type MoneyType struct"},
+		},
+		{
+			name:    "built-in type",
+			mapping: TypeMapping{Types: map[string]GoType{"xs:dateTime": {Type: "*time.Time", Import: "time"}}},
+			want:    []string{`"time"`, "Placed *time.Time `xml:\"placed\" json:\"placed\"`"},
+		},
+		{
+			name:    "element of a type",
+			mapping: TypeMapping{Elements: map[string]GoType{"OrderType.id": {Type: "uuid.UUID", Import: "github.com/google/uuid"}}},
+			want: []string{
+				`"github.com/google/uuid"`,
+				"Id     uuid.UUID `xml:\"id\" json:\"id\"`",
+				"Id   string `xml:\"id\" json:\"id\"`",
+			},
+		},
+		{
+			name: "elements of any type",
+			mapping: TypeMapping{Elements: map[string]GoType{
+				"id":           {Type: "uuid.UUID", Import: "github.com/google/uuid"},
+				"OrderType.id": {Type: "int64"},
+			}},
+			want: []string{
+				"Id     int64     `xml:\"id\" json:\"id\"`",
+				"Id   uuid.UUID `xml:\"id\" json:\"id\"`",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := render(t, testWSDL(schema), func(g *Generator) {
+				g.SetOptions(Options{Types: true})
+				g.SetTypeMapping(&tt.mapping)
+			})
+			assertContains(t, files, "types.go", tt.want, tt.unwanted...)
+		})
+	}
+}
+
+func TestParseGoType(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    GoType
+		wantErr bool
+	}{
+		{in: "int64", want: GoType{Type: "int64"}},
+		{in: "github.com/acme/money.Amount", want: GoType{Type: "money.Amount", Import: "github.com/acme/money"}},
+		{in: "*time.Time", want: GoType{Type: "*time.Time", Import: "time"}},
+		{in: "[]github.com/google/uuid.UUID", want: GoType{Type: "[]uuid.UUID", Import: "github.com/google/uuid"}},
+		{in: "gopkg.in/yaml.v3.Node", wantErr: true},
+		{in: "not a type", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseGoType(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseGoType(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
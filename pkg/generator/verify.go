@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// maxVerifyErrors caps the number of compile errors reported by Verify
+const maxVerifyErrors = 10
+
+// writeGoFile formats Go source and adds it to the generated files.
+// Source that doesn't parse is written unformatted for inspection and
+// reported as an error, since it means a template is broken.
+func (g *Generator) writeGoFile(name, src string) error {
+	if g.deterministic {
+		src = generatedHeader + src
+	}
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		path := filepath.Join(g.outputDir, name)
+		if writeErr := os.MkdirAll(g.outputDir, 0755); writeErr != nil {
+			return writeErr
+		}
+		if writeErr := os.WriteFile(path, []byte(src), 0644); writeErr != nil {
+			return writeErr
+		}
+		return fmt.Errorf("generated %s is not valid Go: %w", path, err)
+	}
+
+	g.out.add(name, formatted)
+	return nil
+}
+
+// Verify type-checks the generated package with the go command, so that
+// generated code that doesn't compile fails generation rather than the
+// user's build. The output directory must be inside a Go module that
+// resolves the packages the generated code imports.
+func (g *Generator) Verify() error {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  g.outputDir,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return fmt.Errorf("failed to load generated package: %w", err)
+	}
+
+	var errs []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			errs = append(errs, e.Error())
+		}
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if len(errs) > maxVerifyErrors {
+		errs = append(errs[:maxVerifyErrors], fmt.Sprintf("... and %d more errors", len(errs)-maxVerifyErrors))
+	}
+	return fmt.Errorf("generated code does not compile:\n\t%s", strings.Join(errs, "\n\t"))
+}
@@ -0,0 +1,214 @@
+// Package naming turns WSDL names into identifiers that are valid and unique
+// in the generated Go, OpenAPI and TypeScript code. The generators share it
+// so that an operation gets the same name everywhere.
+package naming
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// goKeywords are the Go keywords, which can't be used as identifiers
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// clientMethods are the methods and fields of the generated Client that
+// operations must not shadow
+var clientMethods = []string{
+	"Call", "CallContext", "CallStream", "Use", "SetHeader", "SetBasicAuth", "SetDigestAuth",
+	"SetNTLMAuth", "SetSPNEGO", "SetX509Signing", "SetSecurityToken", "SetOAuth2",
+	"EnableAddressing", "SetAddressing", "SetSOAPVersion", "SetCompression", "SetDebug",
+	"AddSOAPHeader", "EnableSessions", "ResetSession", "SessionHeader", "SetEndpoints",
+	"EnableCache", "ClearCache",
+	"HealthyEndpoints", "Headers", "Security", "SecurityToken", "Addressing", "SOAPVersion",
+	"Compression", "URL", "HTTPClient", "StreamThreshold", "Balancing", "EndpointCooldown",
+}
+
+// Pascal converts a name to an exported identifier. The namespace prefix is
+// dropped, characters that are not letters or digits separate words, and
+// names that don't start with an upper case letter, such as those starting
+// with a digit, are prefixed with X.
+func Pascal(name string) string {
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	ident := b.String()
+	if r, _ := utf8.DecodeRuneInString(ident); !unicode.IsUpper(r) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// Camel converts a name to an identifier starting with a lower case letter
+func Camel(name string) string {
+	ident := Pascal(name)
+	r, size := utf8.DecodeRuneInString(ident)
+	return string(unicode.ToLower(r)) + ident[size:]
+}
+
+// Param converts a name to a Go parameter name, escaping Go keywords with a
+// trailing underscore
+func Param(name string) string {
+	ident := Camel(name)
+	if goKeywords[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+// IsIdentifier reports whether name has the syntax of a Go or TypeScript
+// identifier. Keywords are not checked.
+func IsIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rename records a name that had to change beyond its case to become a
+// valid or unique identifier
+type Rename struct {
+	Kind     string
+	Original string
+	Name     string
+}
+
+// Namer assigns unique identifiers to names. Identifiers are compared
+// case-insensitively, since TypeScript lower cases the first letter and
+// some file systems and tools ignore case, and collisions are resolved by
+// numbering later names in order: Name, Name2, Name3...
+type Namer struct {
+	kind     string
+	convert  func(string) string
+	suffixes []string
+	used     map[string]bool
+	names    map[string]string
+	renames  []Rename
+}
+
+// NewNamer creates a namer for identifiers of the given kind converted with
+// convert. Every identifier it assigns also claims the identifier plus each
+// suffix, for code that derives names such as FooContext from Foo.
+func NewNamer(kind string, convert func(string) string, suffixes ...string) *Namer {
+	return &Namer{
+		kind:     kind,
+		convert:  convert,
+		suffixes: suffixes,
+		used:     make(map[string]bool),
+		names:    make(map[string]string),
+	}
+}
+
+// Reserve marks identifiers as taken by the surrounding code
+func (n *Namer) Reserve(idents ...string) {
+	for _, ident := range idents {
+		n.used[strings.ToLower(ident)] = true
+	}
+}
+
+// Name returns the identifier of a name, assigning a new one the first time
+// the name is seen
+func (n *Namer) Name(name string) string {
+	if ident, ok := n.names[name]; ok {
+		return ident
+	}
+	ident := n.Next(name)
+	n.names[name] = ident
+	return ident
+}
+
+// Next assigns a new identifier to a name even if it was seen before, for
+// distinct things that share a name such as an element and an attribute
+func (n *Namer) Next(name string) string {
+	base := n.convert(name)
+	ident := base
+	for i := 2; !n.available(ident); i++ {
+		ident = base + strconv.Itoa(i)
+	}
+
+	n.Reserve(ident)
+	for _, suffix := range n.suffixes {
+		n.Reserve(ident + suffix)
+	}
+
+	if fold(name) != fold(ident) {
+		n.renames = append(n.renames, Rename{Kind: n.kind, Original: name, Name: ident})
+	}
+	return ident
+}
+
+// Renames returns the names whose identifiers differ from them by more than
+// case and separators, in the order they were assigned
+func (n *Namer) Renames() []Rename {
+	return n.renames
+}
+
+// available reports whether an identifier and its suffixed forms are free
+func (n *Namer) available(ident string) bool {
+	if n.used[strings.ToLower(ident)] {
+		return false
+	}
+	for _, suffix := range n.suffixes {
+		if n.used[strings.ToLower(ident+suffix)] {
+			return false
+		}
+	}
+	return true
+}
+
+// fold reduces a name to its lower case letters and digits
+func fold(name string) string {
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		name = name[idx+1:]
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// Operations names the operations of def in port type order. The names are
+// the Go method names of the generated client and the OpenAPI operationIds,
+// from which the TypeScript client derives its method names. Operations
+// with the same name in several port types share one identifier.
+func Operations(def *models.Definitions) *Namer {
+	namer := NewNamer("operation", Pascal, "Context", "Func")
+	namer.Reserve(clientMethods...)
+	for _, portType := range def.PortTypes {
+		for _, op := range portType.Operations {
+			namer.Name(op.Name)
+		}
+	}
+	return namer
+}
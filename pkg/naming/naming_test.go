@@ -0,0 +1,96 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestPascal(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"getUser", "GetUser"},
+		{"tns:get_user-info", "GetUserInfo"},
+		{"3DSecure", "X3DSecure"},
+		{"my.field name", "MyFieldName"},
+		{"élan", "Élan"},
+		{"注文", "X注文"},
+		{"", "X"},
+	}
+
+	for _, tt := range tests {
+		if got := Pascal(tt.name); got != tt.want {
+			t.Errorf("Pascal(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParam(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"IntA", "intA"},
+		{"type", "type_"},
+		{"range", "range_"},
+		{"2fa", "x2fa"},
+	}
+
+	for _, tt := range tests {
+		if got := Param(tt.name); got != tt.want {
+			t.Errorf("Param(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	def := &models.Definitions{
+		PortTypes: []models.PortType{
+			{Operations: []models.Operation{{Name: "getUser"}, {Name: "GetUser"}, {Name: "get_user"}, {Name: "Call"}}},
+			{Operations: []models.Operation{{Name: "Find"}, {Name: "FindContext"}, {Name: "getUser"}}},
+		},
+	}
+
+	names := Operations(def)
+	got := map[string]string{}
+	for _, name := range []string{"getUser", "GetUser", "get_user", "Call", "Find", "FindContext"} {
+		got[name] = names.Name(name)
+	}
+	want := map[string]string{
+		"getUser":     "GetUser",
+		"GetUser":     "GetUser2",
+		"get_user":    "GetUser3",
+		"Call":        "Call2",
+		"Find":        "Find",
+		"FindContext": "FindContext2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+
+	wantRenames := []Rename{
+		{Kind: "operation", Original: "GetUser", Name: "GetUser2"},
+		{Kind: "operation", Original: "get_user", Name: "GetUser3"},
+		{Kind: "operation", Original: "Call", Name: "Call2"},
+		{Kind: "operation", Original: "FindContext", Name: "FindContext2"},
+	}
+	if !reflect.DeepEqual(names.Renames(), wantRenames) {
+		t.Errorf("renames = %v, want %v", names.Renames(), wantRenames)
+	}
+}
+
+func TestNextSharedName(t *testing.T) {
+	namer := NewNamer("field", Pascal)
+	namer.Reserve("XMLName")
+
+	var got []string
+	for _, name := range []string{"id", "id", "XMLName"} {
+		got = append(got, namer.Next(name))
+	}
+	if want := []string{"Id", "Id2", "XMLName2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultFetchTimeout is the timeout used when fetching WSDLs over HTTP
+const DefaultFetchTimeout = 60 * time.Second
+
+// DefaultMaxRedirects is the number of redirects followed when fetching WSDLs
+const DefaultMaxRedirects = 10
+
+// FetchOptions configures the HTTP client used to download remote WSDLs
+type FetchOptions struct {
+	Timeout      time.Duration
+	MaxRedirects int
+	CertFile     string // Client certificate for mutual TLS
+	KeyFile      string
+}
+
+// NewHTTPClient builds an HTTP client for fetching WSDLs. Proxies are taken
+// from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func NewHTTPClient(opts FetchOptions) (*http.Client, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultFetchTimeout
+	}
+	if opts.MaxRedirects == 0 {
+		opts.MaxRedirects = DefaultMaxRedirects
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		}
+	}
+
+	maxRedirects := opts.MaxRedirects
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// fetch downloads a WSDL over HTTP(S)
+func (p *Parser) fetch(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
@@ -1,7 +1,17 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
 )
 
 func TestNewParser(t *testing.T) {
@@ -32,4 +42,832 @@ func TestToPascalCase(t *testing.T) {
 	}
 }
 
+func TestParseCalculatorTypes(t *testing.T) {
+	def, err := NewParser().Parse("../../examples/calculator.wsdl")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(def.Elements) != 2 {
+		t.Fatalf("expected 2 global elements, got %d", len(def.Elements))
+	}
+
+	add := findType(def, "Add")
+	if add == nil {
+		t.Fatal("type Add not found")
+	}
+	if len(add.Elements) != 2 || add.Elements[0].Name != "intA" || add.Elements[1].Type != "xsd:int" {
+		t.Errorf("unexpected Add elements: %+v", add.Elements)
+	}
+}
+
+func TestParseNestedComplexTypes(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions xmlns="http://schemas.xmlsoap.org/wsdl/" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:orders" targetNamespace="urn:orders">
+  <types>
+    <xs:schema targetNamespace="urn:orders">
+      <xs:complexType name="Address">
+        <xs:sequence>
+          <xs:element name="street" type="xs:string"/>
+          <xs:element name="zip" type="xs:string" minOccurs="0" nillable="true"/>
+        </xs:sequence>
+        <xs:attribute name="country" type="xs:string" use="required"/>
+      </xs:complexType>
+      <xs:element name="Note" type="xs:string"/>
+      <xs:element name="Order">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="shipTo" type="tns:Address"/>
+            <xs:element name="line" maxOccurs="unbounded">
+              <xs:complexType>
+                <xs:sequence>
+                  <xs:element name="sku" type="xs:string"/>
+                  <xs:element name="qty" type="xs:int"/>
+                </xs:sequence>
+              </xs:complexType>
+            </xs:element>
+            <xs:element ref="tns:Note"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:schema>
+  </types>
+</definitions>`)
+
+	address := findType(def, "Address")
+	if address == nil {
+		t.Fatal("type Address not found")
+	}
+	if len(address.Attributes) != 1 || address.Attributes[0].Use != "required" {
+		t.Errorf("unexpected Address attributes: %+v", address.Attributes)
+	}
+	if zip := address.Elements[1]; zip.MinOccurs != "0" || !zip.Nillable {
+		t.Errorf("unexpected zip element: %+v", zip)
+	}
+
+	order := findType(def, "Order")
+	if order == nil {
+		t.Fatal("type Order not found")
+	}
+	if len(order.Elements) != 3 {
+		t.Fatalf("expected 3 Order elements, got %d", len(order.Elements))
+	}
+	if line := order.Elements[1]; line.Type != "Order_line" || line.MaxOccurs != "unbounded" {
+		t.Errorf("unexpected line element: %+v", line)
+	}
+	if note := order.Elements[2]; note.Name != "Note" || note.Type != "xs:string" {
+		t.Errorf("unexpected ref element: %+v", note)
+	}
+	if findType(def, "Order_line") == nil {
+		t.Error("anonymous type Order_line not found")
+	}
+}
+
+func TestParseWSDL20(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<description xmlns="http://www.w3.org/ns/wsdl" xmlns:wsoap="http://www.w3.org/ns/wsdl/soap"
+             xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:calc" targetNamespace="urn:calc">
+  <types>
+    <xs:schema targetNamespace="urn:calc">
+      <xs:element name="Add">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="a" type="xs:int"/>
+            <xs:element name="b" type="xs:int"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="AddResponse" type="xs:int"/>
+    </xs:schema>
+  </types>
+  <interface name="CalcInterface">
+    <operation name="Add" pattern="http://www.w3.org/ns/wsdl/in-out">
+      <documentation>Adds two integers</documentation>
+      <input element="tns:Add"/>
+      <output element="tns:AddResponse"/>
+    </operation>
+  </interface>
+  <binding name="CalcBinding" interface="tns:CalcInterface" type="http://www.w3.org/ns/wsdl/soap" wsoap:version="1.2">
+    <operation ref="tns:Add" wsoap:action="urn:calc/Add"/>
+  </binding>
+  <service name="CalcService" interface="tns:CalcInterface">
+    <endpoint name="CalcEndpoint" binding="tns:CalcBinding" address="http://example.com/calc"/>
+  </service>
+</description>`)
+
+	if def.TargetNamespace != "urn:calc" {
+		t.Errorf("unexpected target namespace %q", def.TargetNamespace)
+	}
+	if len(def.Services) != 1 || def.Services[0].Ports[0].Address != "http://example.com/calc" {
+		t.Errorf("unexpected services: %+v", def.Services)
+	}
+	if len(def.Bindings) != 1 || def.Bindings[0].Operations[0].SoapAction != "urn:calc/Add" || def.Bindings[0].SOAPVersion != "1.2" {
+		t.Errorf("unexpected bindings: %+v", def.Bindings)
+	}
+	if len(def.PortTypes) != 1 || len(def.PortTypes[0].Operations) != 1 {
+		t.Fatalf("unexpected port types: %+v", def.PortTypes)
+	}
+
+	op := def.PortTypes[0].Operations[0]
+	if op.Documentation != "Adds two integers" {
+		t.Errorf("unexpected documentation %q", op.Documentation)
+	}
+	if len(def.Messages) != 2 {
+		t.Fatalf("expected 2 synthesized messages, got %d", len(def.Messages))
+	}
+	if def.Messages[0].Name != op.Input.Name || def.Messages[0].Parts[0].Element != "tns:Add" {
+		t.Errorf("unexpected input message: %+v", def.Messages[0])
+	}
+	if findType(def, "Add") == nil {
+		t.Error("type Add not found")
+	}
+}
+
+func TestParseRPCEncodedBinding(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:legacy" xmlns:tns="urn:legacy"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <message name="AddIn"><part name="a" type="xsd:int"/></message>
+  <message name="AddOut"><part name="sum" type="xsd:int"/></message>
+  <portType name="LegacyPort">
+    <operation name="Add"><input message="tns:AddIn"/><output message="tns:AddOut"/></operation>
+  </portType>
+  <binding name="LegacyBinding" type="tns:LegacyPort">
+    <soap:binding style="rpc" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <operation name="Add">
+      <soap:operation soapAction="urn:legacy#Add"/>
+      <input><soap:body use="encoded" namespace="urn:legacy:calc" encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"/></input>
+      <output><soap:body use="literal"/></output>
+    </operation>
+  </binding>
+</definitions>`)
+
+	if len(def.Bindings) != 1 || def.Bindings[0].Style != "rpc" {
+		t.Fatalf("unexpected bindings: %+v", def.Bindings)
+	}
+
+	op := def.Bindings[0].Operations[0]
+	if op.Input.Use != "encoded" || op.Input.Namespace != "urn:legacy:calc" {
+		t.Errorf("unexpected input body: %+v", op.Input)
+	}
+	if op.Input.EncodingStyle != "http://schemas.xmlsoap.org/soap/encoding/" {
+		t.Errorf("unexpected encoding style %q", op.Input.EncodingStyle)
+	}
+	if op.Output.Use != "literal" {
+		t.Errorf("unexpected output body: %+v", op.Output)
+	}
+}
+
+func TestParseSOAP12Binding(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:calc" xmlns:tns="urn:calc"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+    xmlns:soap12="http://schemas.xmlsoap.org/wsdl/soap12/"
+    xmlns:http="http://schemas.xmlsoap.org/wsdl/http/"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <message name="AddIn"><part name="a" type="xsd:int"/></message>
+  <message name="AddOut"><part name="sum" type="xsd:int"/></message>
+  <portType name="CalcPort">
+    <operation name="Add"><input message="tns:AddIn"/><output message="tns:AddOut"/></operation>
+  </portType>
+  <binding name="CalcSoap12" type="tns:CalcPort">
+    <soap12:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <operation name="Add">
+      <soap12:operation soapAction="urn:calc/Add"/>
+      <input><soap12:body use="literal"/></input>
+      <output><soap12:body use="literal"/></output>
+    </operation>
+  </binding>
+  <binding name="CalcSoap" type="tns:CalcPort">
+    <soap:binding style="document" transport="http://schemas.xmlsoap.org/soap/http"/>
+    <operation name="Add"><soap:operation soapAction="urn:calc/Add"/></operation>
+  </binding>
+  <binding name="CalcHttpGet" type="tns:CalcPort">
+    <http:binding verb="GET"/>
+  </binding>
+  <service name="Calc">
+    <port name="CalcSoap12" binding="tns:CalcSoap12"><soap12:address location="http://example.com/calc"/></port>
+  </service>
+</definitions>`)
+
+	if len(def.Bindings) != 3 {
+		t.Fatalf("unexpected bindings: %+v", def.Bindings)
+	}
+	soap12 := def.Bindings[0]
+	if soap12.SOAPVersion != "1.2" || soap12.Transport != "http://schemas.xmlsoap.org/soap/http" {
+		t.Errorf("unexpected SOAP 1.2 binding: %+v", soap12)
+	}
+	if soap12.Operations[0].SoapAction != "urn:calc/Add" || soap12.Operations[0].Input.Use != "literal" {
+		t.Errorf("unexpected SOAP 1.2 operation: %+v", soap12.Operations[0])
+	}
+	if version := def.Bindings[1].SOAPVersion; version != "1.1" {
+		t.Errorf("SOAP 1.1 binding has version %q", version)
+	}
+	if version := def.Bindings[2].SOAPVersion; version != "" {
+		t.Errorf("HTTP binding has version %q", version)
+	}
+	if address := def.Services[0].Ports[0].Address; address != "http://example.com/calc" {
+		t.Errorf("unexpected address %q", address)
+	}
+}
+
+func TestParseHTTPBinding(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:calc" xmlns:tns="urn:calc"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns:http="http://schemas.xmlsoap.org/wsdl/http/"
+    xmlns:mime="http://schemas.xmlsoap.org/wsdl/mime/"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <message name="AddIn"><part name="a" type="xsd:int"/></message>
+  <message name="AddOut"><part name="sum" type="xsd:int"/></message>
+  <portType name="CalcPort">
+    <operation name="Add"><input message="tns:AddIn"/><output message="tns:AddOut"/></operation>
+    <operation name="Get"><input message="tns:AddIn"/><output message="tns:AddOut"/></operation>
+  </portType>
+  <binding name="CalcHttpPost" type="tns:CalcPort">
+    <http:binding verb="POST"/>
+    <operation name="Add">
+      <http:operation location="/Add"/>
+      <input><mime:content type="application/x-www-form-urlencoded"/></input>
+      <output><mime:mimeXml part="Body"/></output>
+    </operation>
+    <operation name="Get">
+      <http:operation location="/get/(a)"/>
+      <input><http:urlReplacement/></input>
+      <output><mime:mimeXml part="Body"/></output>
+    </operation>
+  </binding>
+  <binding name="CalcHttpGet" type="tns:CalcPort">
+    <http:binding verb="GET"/>
+    <operation name="Add">
+      <http:operation location="/Add"/>
+      <input><http:urlEncoded/></input>
+    </operation>
+  </binding>
+  <service name="Calc">
+    <port name="CalcHttpPost" binding="tns:CalcHttpPost"><http:address location="http://example.com/calc"/></port>
+  </service>
+</definitions>`)
+
+	if len(def.Bindings) != 2 {
+		t.Fatalf("unexpected bindings: %+v", def.Bindings)
+	}
+	post, get := def.Bindings[0], def.Bindings[1]
+	if post.HTTPVerb != "POST" || get.HTTPVerb != "GET" || post.SOAPVersion != "" {
+		t.Errorf("unexpected bindings: %+v", def.Bindings)
+	}
+	add := post.Operations[0]
+	if add.Location != "/Add" || add.Input.Encoding != "application/x-www-form-urlencoded" || add.Output.Encoding != "text/xml" {
+		t.Errorf("unexpected POST operation: %+v", add)
+	}
+	if op := post.Operations[1]; op.Location != "/get/(a)" || op.Input.Encoding != "urlReplacement" {
+		t.Errorf("unexpected urlReplacement operation: %+v", op)
+	}
+	if op := get.Operations[0]; op.Input.Encoding != "urlEncoded" {
+		t.Errorf("unexpected GET operation: %+v", op)
+	}
+	if address := def.Services[0].Ports[0].Address; address != "http://example.com/calc" {
+		t.Errorf("unexpected address %q", address)
+	}
+}
+
+func TestParseExchangePatterns(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:orders" xmlns:tns="urn:orders"
+    xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <portType name="OrdersPort">
+    <operation name="Get"><input message="tns:In"/><output message="tns:Out"/></operation>
+    <operation name="Submit"><documentation>Queues an order</documentation><input message="tns:In"/></operation>
+    <operation name="Shipped"><output message="tns:Out"/></operation>
+    <operation name="Confirm"><output message="tns:Out"/><input message="tns:In"/><fault name="Rejected" message="tns:Fault"/></operation>
+  </portType>
+  <binding name="OrdersJMS" type="tns:OrdersPort">
+    <soap:binding style="document" transport="http://www.w3.org/2010/soapjms/"/>
+  </binding>
+</definitions>`)
+
+	want := map[string]string{
+		"Get":     models.PatternRequestResponse,
+		"Submit":  models.PatternOneWay,
+		"Shipped": models.PatternNotification,
+		"Confirm": models.PatternSolicitResponse,
+	}
+	for _, op := range def.PortTypes[0].Operations {
+		if op.Pattern != want[op.Name] {
+			t.Errorf("%s pattern = %q, want %q", op.Name, op.Pattern, want[op.Name])
+		}
+	}
+
+	ops := def.PortTypes[0].Operations
+	if ops[1].Documentation != "Queues an order" || ops[1].Input.Name != "tns:In" {
+		t.Errorf("unexpected one-way operation: %+v", ops[1])
+	}
+	if len(ops[3].Faults) != 1 || ops[3].Faults[0].Name != "Rejected" {
+		t.Errorf("unexpected faults: %+v", ops[3].Faults)
+	}
+	if def.Bindings[0].Transport != "http://www.w3.org/2010/soapjms/" {
+		t.Errorf("unexpected transport %q", def.Bindings[0].Transport)
+	}
+}
+
+func TestParseDocumentation(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:orders" xmlns:tns="urn:orders"
+    xmlns:xs="http://www.w3.org/2001/XMLSchema"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <types>
+    <xs:schema targetNamespace="urn:orders">
+      <xs:element name="Order">
+        <xs:annotation><xs:documentation>An order placed online</xs:documentation></xs:annotation>
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="id" type="xs:int">
+              <xs:annotation>
+                <xs:documentation>
+                  Order number,
+                  unique per shop
+                </xs:documentation>
+                <xs:documentation>Assigned on creation</xs:documentation>
+              </xs:annotation>
+            </xs:element>
+            <xs:element ref="tns:Status"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Status" type="tns:StatusCode">
+        <xs:annotation><xs:documentation>Fulfilment status</xs:documentation></xs:annotation>
+      </xs:element>
+      <xs:simpleType name="StatusCode">
+        <xs:annotation><xs:documentation>Status codes</xs:documentation></xs:annotation>
+        <xs:restriction base="xs:string"/>
+      </xs:simpleType>
+    </xs:schema>
+  </types>
+  <portType name="OrdersPort">
+    <documentation>Order management</documentation>
+    <operation name="Get"><documentation>Returns an order</documentation><input message="tns:In"/></operation>
+  </portType>
+  <service name="Orders">
+    <documentation>The order service of the shop</documentation>
+  </service>
+</definitions>`)
+
+	if got := def.Services[0].Documentation; got != "The order service of the shop" {
+		t.Errorf("service documentation = %q", got)
+	}
+	if got := def.PortTypes[0].Documentation; got != "Order management" {
+		t.Errorf("port type documentation = %q", got)
+	}
+	if got := def.PortTypes[0].Operations[0].Documentation; got != "Returns an order" {
+		t.Errorf("operation documentation = %q", got)
+	}
+	if got := def.Elements[0].Documentation; got != "An order placed online" {
+		t.Errorf("element documentation = %q", got)
+	}
+	if got := def.SimpleTypes[0].Documentation; got != "Status codes" {
+		t.Errorf("simple type documentation = %q", got)
+	}
+	var order models.Type
+	for _, typ := range def.Types {
+		if typ.Name == "Order" {
+			order = typ
+		}
+	}
+	if len(order.Elements) != 2 {
+		t.Fatalf("Order elements = %+v", order.Elements)
+	}
+	if got, want := order.Elements[0].Documentation, "Order number,\nunique per shop\n\nAssigned on creation"; got != want {
+		t.Errorf("field documentation = %q, want %q", got, want)
+	}
+	if got := order.Elements[1].Documentation; got != "Fulfilment status" {
+		t.Errorf("referenced element documentation = %q", got)
+	}
+}
+
+func TestParseChoiceAllAny(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:pay" xmlns:tns="urn:pay"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <types>
+    <xsd:schema targetNamespace="urn:pay">
+      <xsd:complexType name="Payment">
+        <xsd:sequence>
+          <xsd:element name="Amount" type="xsd:decimal"/>
+          <xsd:choice>
+            <xsd:element name="Card" type="xsd:string"/>
+            <xsd:element name="Iban" type="xsd:string"/>
+          </xsd:choice>
+          <xsd:choice minOccurs="0" maxOccurs="unbounded">
+            <xsd:element name="Note" type="xsd:string"/>
+          </xsd:choice>
+          <xsd:any namespace="##other" minOccurs="0"/>
+        </xsd:sequence>
+      </xsd:complexType>
+      <xsd:complexType name="Address">
+        <xsd:all>
+          <xsd:element name="City" type="xsd:string"/>
+          <xsd:element name="Street" type="xsd:string" minOccurs="0"/>
+        </xsd:all>
+      </xsd:complexType>
+    </xsd:schema>
+  </types>
+</definitions>`)
+
+	if len(def.Types) != 2 {
+		t.Fatalf("expected 2 types, got %+v", def.Types)
+	}
+
+	payment := def.Types[0]
+	if !payment.Any {
+		t.Error("expected Payment to contain xs:any")
+	}
+	if len(payment.Choices) != 1 || payment.Choices[0].MinOccurs != "" {
+		t.Errorf("unexpected choices: %+v", payment.Choices)
+	}
+
+	want := []models.Element{
+		{Name: "Amount", Type: "xsd:decimal"},
+		{Name: "Card", Type: "xsd:string", MinOccurs: "0", Choice: 1},
+		{Name: "Iban", Type: "xsd:string", MinOccurs: "0", Choice: 1},
+		{Name: "Note", Type: "xsd:string", MinOccurs: "0", MaxOccurs: "unbounded"},
+	}
+	if !reflect.DeepEqual(payment.Elements, want) {
+		t.Errorf("unexpected Payment elements:\n got %+v\nwant %+v", payment.Elements, want)
+	}
+
+	address := def.Types[1]
+	if len(address.Elements) != 2 || address.Elements[0].Name != "City" || address.Elements[1].MinOccurs != "0" {
+		t.Errorf("unexpected Address elements: %+v", address.Elements)
+	}
+}
+
+func TestParseAttributesAndContent(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:fares" xmlns:tns="urn:fares"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <types>
+    <xsd:schema targetNamespace="urn:fares">
+      <xsd:attribute name="Version" type="xsd:decimal"/>
+      <xsd:attributeGroup name="Amount">
+        <xsd:attribute name="Amount" type="xsd:decimal" use="required"/>
+        <xsd:attribute name="Currency">
+          <xsd:simpleType><xsd:restriction base="xsd:string"/></xsd:simpleType>
+        </xsd:attribute>
+      </xsd:attributeGroup>
+      <xsd:complexType name="Price">
+        <xsd:simpleContent>
+          <xsd:extension base="xsd:string">
+            <xsd:attributeGroup ref="tns:Amount"/>
+          </xsd:extension>
+        </xsd:simpleContent>
+      </xsd:complexType>
+      <xsd:complexType name="TaxedPrice">
+        <xsd:simpleContent>
+          <xsd:restriction base="tns:Price">
+            <xsd:attribute name="Currency" use="prohibited"/>
+            <xsd:attribute name="Tax" type="xsd:int"/>
+          </xsd:restriction>
+        </xsd:simpleContent>
+      </xsd:complexType>
+      <xsd:complexType name="Remark" mixed="true">
+        <xsd:sequence><xsd:element name="b" type="xsd:string"/></xsd:sequence>
+        <xsd:attribute ref="tns:Version"/>
+        <xsd:attribute ref="xml:lang"/>
+      </xsd:complexType>
+    </xsd:schema>
+  </types>
+</definitions>`)
+
+	types := make(map[string]models.Type)
+	for _, typ := range def.Types {
+		types[typ.Name] = typ
+	}
+
+	price := types["Price"]
+	wantPrice := []models.Attribute{
+		{Name: "Amount", Type: "xsd:decimal", Use: "required"},
+		{Name: "Currency", Type: "Price_Currency"},
+	}
+	if price.SimpleContent != "xsd:string" || !reflect.DeepEqual(price.Attributes, wantPrice) {
+		t.Errorf("unexpected Price: %+v", price)
+	}
+
+	taxed := types["TaxedPrice"]
+	wantTaxed := []models.Attribute{
+		{Name: "Amount", Type: "xsd:decimal", Use: "required"},
+		{Name: "Tax", Type: "xsd:int"},
+	}
+	if taxed.SimpleContent != "xsd:string" || !reflect.DeepEqual(taxed.Attributes, wantTaxed) {
+		t.Errorf("unexpected TaxedPrice: %+v", taxed)
+	}
+
+	remark := types["Remark"]
+	wantRemark := []models.Attribute{
+		{Name: "Version", Namespace: "urn:fares", Type: "xsd:decimal"},
+		{Name: "lang", Namespace: "http://www.w3.org/XML/1998/namespace", Type: "anySimpleType"},
+	}
+	if !remark.Mixed || remark.SimpleContent != "" || !reflect.DeepEqual(remark.Attributes, wantRemark) {
+		t.Errorf("unexpected Remark: %+v", remark)
+	}
+}
+
+func TestParseComplexContent(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:fleet" xmlns:tns="urn:fleet"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <types>
+    <xsd:schema targetNamespace="urn:fleet">
+      <xsd:complexType name="Car">
+        <xsd:complexContent>
+          <xsd:extension base="tns:Vehicle">
+            <xsd:sequence><xsd:element name="Doors" type="xsd:int"/></xsd:sequence>
+            <xsd:attribute name="Electric" type="xsd:boolean"/>
+          </xsd:extension>
+        </xsd:complexContent>
+      </xsd:complexType>
+      <xsd:complexType name="Vehicle" abstract="true">
+        <xsd:sequence>
+          <xsd:element name="Plate" type="xsd:string"/>
+          <xsd:choice>
+            <xsd:element name="Owner" type="xsd:string"/>
+            <xsd:element name="Lessor" type="xsd:string"/>
+          </xsd:choice>
+        </xsd:sequence>
+        <xsd:attribute name="Id" type="xsd:int"/>
+      </xsd:complexType>
+      <xsd:complexType name="Rental">
+        <xsd:complexContent>
+          <xsd:restriction base="tns:Vehicle">
+            <xsd:sequence><xsd:element name="Plate" type="xsd:string"/></xsd:sequence>
+          </xsd:restriction>
+        </xsd:complexContent>
+      </xsd:complexType>
+    </xsd:schema>
+  </types>
+</definitions>`)
+
+	types := make(map[string]models.Type)
+	for _, typ := range def.Types {
+		types[typ.Name] = typ
+	}
+
+	car := types["Car"]
+	wantElements := []models.Element{
+		{Name: "Plate", Type: "xsd:string"},
+		{Name: "Owner", Type: "xsd:string", MinOccurs: "0", Choice: 1},
+		{Name: "Lessor", Type: "xsd:string", MinOccurs: "0", Choice: 1},
+		{Name: "Doors", Type: "xsd:int"},
+	}
+	wantAttrs := []models.Attribute{
+		{Name: "Id", Type: "xsd:int"},
+		{Name: "Electric", Type: "xsd:boolean"},
+	}
+	if car.Base != "tns:Vehicle" || car.Derivation != models.DerivationExtension ||
+		!reflect.DeepEqual(car.Elements, wantElements) || len(car.Choices) != 1 ||
+		!reflect.DeepEqual(car.Attributes, wantAttrs) {
+		t.Errorf("unexpected Car: %+v", car)
+	}
+	if !types["Vehicle"].Abstract || car.Abstract {
+		t.Error("abstract types not recorded")
+	}
+
+	rental := types["Rental"]
+	if rental.Derivation != models.DerivationRestriction || len(rental.Elements) != 1 ||
+		!reflect.DeepEqual(rental.Attributes, wantAttrs[:1]) {
+		t.Errorf("unexpected Rental: %+v", rental)
+	}
+}
+
+func TestParseSubstitutionGroups(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:shapes" xmlns:tns="urn:shapes"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <types>
+    <xsd:schema targetNamespace="urn:shapes">
+      <xsd:complexType name="ShapeType">
+        <xsd:sequence><xsd:element name="color" type="xsd:string"/></xsd:sequence>
+      </xsd:complexType>
+      <xsd:element name="Shape" type="tns:ShapeType" abstract="true"/>
+      <xsd:element name="Circle" substitutionGroup="tns:Shape"/>
+      <xsd:element name="Drawing">
+        <xsd:complexType><xsd:sequence>
+          <xsd:element ref="tns:Shape" maxOccurs="unbounded"/>
+        </xsd:sequence></xsd:complexType>
+      </xsd:element>
+    </xsd:schema>
+  </types>
+</definitions>`)
+
+	want := []models.Element{
+		{Name: "Shape", Namespace: "urn:shapes", Type: "tns:ShapeType", Abstract: true},
+		{Name: "Circle", Namespace: "urn:shapes", Type: "tns:ShapeType", SubstitutionGroup: "tns:Shape"},
+	}
+	if !reflect.DeepEqual(def.Elements[:2], want) {
+		t.Errorf("unexpected elements:\n got %+v\nwant %+v", def.Elements[:2], want)
+	}
+
+	ref := def.Types[1].Elements[0]
+	if ref.Name != "Shape" || ref.Namespace != "urn:shapes" || ref.Abstract || ref.SubstitutionGroup != "" {
+		t.Errorf("unexpected reference to the head: %+v", ref)
+	}
+}
+
+func TestParseSchemaNamespaces(t *testing.T) {
+	def := parseString(t, `<?xml version="1.0"?>
+<definitions targetNamespace="urn:orders:wsdl" xmlns:com="urn:common" xmlns:ord="urn:orders"
+    xmlns:xsd="http://www.w3.org/2001/XMLSchema"
+    xmlns="http://schemas.xmlsoap.org/wsdl/">
+  <types>
+    <xsd:schema targetNamespace="urn:common" elementFormDefault="qualified">
+      <xsd:complexType name="Address">
+        <xsd:sequence><xsd:element name="City" type="xsd:string"/></xsd:sequence>
+      </xsd:complexType>
+      <xsd:element name="Note" type="xsd:string"/>
+    </xsd:schema>
+    <xsd:schema targetNamespace="urn:orders">
+      <xsd:element name="Note" type="xsd:int"/>
+      <xsd:element name="PlaceOrder">
+        <xsd:complexType><xsd:sequence>
+          <xsd:element name="Item" type="xsd:string"/>
+          <xsd:element name="ShipTo" type="com:Address" form="qualified"/>
+          <xsd:element ref="com:Note"/>
+        </xsd:sequence></xsd:complexType>
+      </xsd:element>
+    </xsd:schema>
+  </types>
+</definitions>`)
+
+	address := def.Types[0]
+	if address.Namespace != "urn:common" || address.Elements[0].Namespace != "urn:common" {
+		t.Errorf("unexpected Address: %+v", address)
+	}
+
+	order := def.Types[1]
+	want := []models.Element{
+		{Name: "Item", Type: "xsd:string"},
+		{Name: "ShipTo", Namespace: "urn:orders", Type: "com:Address"},
+		{Name: "Note", Namespace: "urn:common", Type: "xsd:string"},
+	}
+	if order.Namespace != "urn:orders" || !reflect.DeepEqual(order.Elements, want) {
+		t.Errorf("unexpected PlaceOrder:\n got %+v\nwant %+v", order, want)
+	}
+}
+
+func TestParseFromURLWithAuth(t *testing.T) {
+	wsdl, err := os.ReadFile("../../examples/calculator.wsdl")
+	if err != nil {
+		t.Fatalf("failed to read WSDL: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "secret" || r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(wsdl)
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(FetchOptions{})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+
+	p := NewParserWithHTTPClient(client)
+	if _, err := p.Parse(srv.URL); err == nil {
+		t.Error("expected error without credentials")
+	}
+
+	p.SetBasicAuth("alice", "secret")
+	p.SetHeader("X-Tenant", "acme")
+	def, err := p.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if def.Name != "Calculator" {
+		t.Errorf("unexpected definitions name %q", def.Name)
+	}
+}
+
+func TestParseReaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		wsdl string
+		want string
+	}{
+		{"not a WSDL", `<schema xmlns="http://www.w3.org/2001/XMLSchema"/>`, "expected element type <definitions> but have <schema>"},
+		{"truncated", `<definitions xmlns="http://schemas.xmlsoap.org/wsdl/"><types>`, "unexpected EOF"},
+		{"empty", ``, "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser().ParseReader(strings.NewReader(tt.wsdl))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseReader() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLargeWSDL(t *testing.T) {
+	def, err := NewParser().ParseReader(bytes.NewReader(largeWSDL(50)))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if len(def.PortTypes) != 1 || len(def.PortTypes[0].Operations) != 50 || len(def.Messages) != 100 || len(def.Types) != 100 {
+		t.Fatalf("unexpected model: %d port types, %d messages, %d types", len(def.PortTypes), len(def.Messages), len(def.Types))
+	}
+	req := findType(def, "Operation49")
+	if req == nil || len(req.Elements) != 20 || req.Elements[19].Type != "xs:string" {
+		t.Errorf("unexpected Operation49: %+v", req)
+	}
+}
+
+func BenchmarkParseCalculator(b *testing.B) {
+	wsdl, err := os.ReadFile("../../examples/calculator.wsdl")
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkParse(b, wsdl)
+}
+
+func BenchmarkParseLarge(b *testing.B) {
+	benchmarkParse(b, largeWSDL(1000))
+}
+
+func benchmarkParse(b *testing.B, wsdl []byte) {
+	b.SetBytes(int64(len(wsdl)))
+	b.ReportAllocs()
+	p := NewParser()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.ParseReader(bytes.NewReader(wsdl)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// largeWSDL returns a document-literal WSDL with the given number of
+// operations, whose request and response elements have 20 fields each
+func largeWSDL(operations int) []byte {
+	var types, messages, portType, binding bytes.Buffer
+	for i := 0; i < operations; i++ {
+		for _, suffix := range []string{"", "Response"} {
+			name := fmt.Sprintf("Operation%d%s", i, suffix)
+			fmt.Fprintf(&types, `<xs:element name="%s"><xs:complexType><xs:sequence>`, name)
+			for j := 0; j < 20; j++ {
+				fmt.Fprintf(&types, `<xs:element name="field%d" type="xs:string" minOccurs="0"/>`, j)
+			}
+			types.WriteString(`</xs:sequence></xs:complexType></xs:element>`)
+			fmt.Fprintf(&messages, `<wsdl:message name="%sMessage"><wsdl:part name="parameters" element="tns:%s"/></wsdl:message>`, name, name)
+		}
+		fmt.Fprintf(&portType, `<wsdl:operation name="Operation%d"><wsdl:input message="tns:Operation%dMessage"/><wsdl:output message="tns:Operation%dResponseMessage"/></wsdl:operation>`, i, i, i)
+		fmt.Fprintf(&binding, `<wsdl:operation name="Operation%d"><soap:operation soapAction="urn:large/Operation%d"/><wsdl:input><soap:body use="literal"/></wsdl:input><wsdl:output><soap:body use="literal"/></wsdl:output></wsdl:operation>`, i, i)
+	}
+
+	return []byte(`<?xml version="1.0" encoding="utf-8"?>
+<wsdl:definitions name="Large" targetNamespace="urn:large" xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/"
+  xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:large">
+<wsdl:types><xs:schema targetNamespace="urn:large" elementFormDefault="qualified">` + types.String() + `</xs:schema></wsdl:types>
+` + messages.String() + `
+<wsdl:portType name="LargePortType">` + portType.String() + `</wsdl:portType>
+<wsdl:binding name="LargeBinding" type="tns:LargePortType"><soap:binding transport="http://schemas.xmlsoap.org/soap/http"/>` + binding.String() + `</wsdl:binding>
+<wsdl:service name="Large"><wsdl:port name="LargePort" binding="tns:LargeBinding"><soap:address location="http://localhost/large"/></wsdl:port></wsdl:service>
+</wsdl:definitions>`)
+}
+
+// parseString parses a WSDL document held in a string
+func parseString(t *testing.T, wsdl string) *models.Definitions {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "service.wsdl")
+	if err := os.WriteFile(path, []byte(wsdl), 0644); err != nil {
+		t.Fatalf("failed to write WSDL: %v", err)
+	}
+
+	def, err := NewParser().Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return def
+}
+
+// findType finds a parsed type by name
+func findType(def *models.Definitions, name string) *models.Type {
+	for i := range def.Types {
+		if def.Types[i].Name == name {
+			return &def.Types[i]
+		}
+	}
+	return nil
+}
+
 // TODO: Add more tests with sample WSDL files
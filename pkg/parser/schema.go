@@ -0,0 +1,560 @@
+package parser
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// schemaConverter walks the XSD schemas embedded in wsdl:types and
+// populates the Types and Elements of the internal model
+type schemaConverter struct {
+	def        *models.Definitions
+	schemas    []rawSchema
+	globals    map[string]globalElement
+	attributes map[string]globalAttribute
+	attrGroups map[string]rawAttributeGroup
+	typeSeen   map[string]bool
+	strings    interner
+
+	// Namespace prefixes declared on the WSDL root
+	rootPrefixes map[string]string
+
+	// State of the schema being converted
+	namespace string
+	qualified bool
+	prefixes  map[string]string
+}
+
+// globalElement is a global element declaration with its namespace
+type globalElement struct {
+	rawXSDElement
+	namespace string
+}
+
+// globalAttribute is a global attribute declaration with its namespace
+type globalAttribute struct {
+	rawXSDAttribute
+	namespace string
+}
+
+// xmlNamespace is the namespace bound to the xml prefix, as in xml:lang
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// newSchemaConverter creates a converter for the given schemas, interning
+// the type references of the model with in
+func newSchemaConverter(def *models.Definitions, schemas []rawSchema, in interner) *schemaConverter {
+	sc := &schemaConverter{
+		def:          def,
+		schemas:      schemas,
+		globals:      make(map[string]globalElement),
+		attributes:   make(map[string]globalAttribute),
+		attrGroups:   make(map[string]rawAttributeGroup),
+		typeSeen:     make(map[string]bool),
+		strings:      in,
+		rootPrefixes: make(map[string]string),
+	}
+
+	// Index global elements, attributes and attribute groups so that
+	// ref="tns:Foo" can be resolved. They are keyed by qualified and by
+	// local name, the latter for refs whose prefix can't be resolved.
+	for _, schema := range schemas {
+		for _, el := range schema.Element {
+			global := globalElement{rawXSDElement: el, namespace: schema.TargetNamespace}
+			sc.globals[schema.TargetNamespace+" "+el.Name] = global
+			if _, ok := sc.globals[el.Name]; !ok {
+				sc.globals[el.Name] = global
+			}
+		}
+		for _, attr := range schema.Attribute {
+			global := globalAttribute{rawXSDAttribute: attr, namespace: schema.TargetNamespace}
+			sc.attributes[schema.TargetNamespace+" "+attr.Name] = global
+			if _, ok := sc.attributes[attr.Name]; !ok {
+				sc.attributes[attr.Name] = global
+			}
+		}
+		for _, group := range schema.AttributeGroup {
+			sc.attrGroups[schema.TargetNamespace+" "+group.Name] = group
+			if _, ok := sc.attrGroups[group.Name]; !ok {
+				sc.attrGroups[group.Name] = group
+			}
+		}
+	}
+
+	return sc
+}
+
+// declarePrefixes records the namespace prefixes declared by xmlns
+// attributes on the WSDL root, which schemas inherit
+func (sc *schemaConverter) declarePrefixes(attrs []xml.Attr) {
+	for _, attr := range attrs {
+		if attr.Name.Space == "xmlns" {
+			sc.rootPrefixes[attr.Name.Local] = attr.Value
+		}
+	}
+}
+
+// enter makes schema the one being converted
+func (sc *schemaConverter) enter(schema rawSchema) {
+	sc.namespace = schema.TargetNamespace
+	sc.qualified = schema.ElementFormDefault == "qualified"
+	sc.prefixes = make(map[string]string, len(sc.rootPrefixes))
+	for prefix, ns := range sc.rootPrefixes {
+		sc.prefixes[prefix] = ns
+	}
+	for _, attr := range schema.Attrs {
+		if attr.Name.Space == "xmlns" {
+			sc.prefixes[attr.Name.Local] = attr.Value
+		}
+	}
+}
+
+// lookupKeys returns the keys a qualified name is looked up by: with its
+// namespace when the prefix resolves, then by local name
+func (sc *schemaConverter) lookupKeys(qname string) []string {
+	name := models.LocalName(qname)
+	if idx := strings.LastIndex(qname, ":"); idx != -1 {
+		if ns, ok := sc.prefixes[qname[:idx]]; ok {
+			return []string{ns + " " + name, name}
+		}
+	}
+	return []string{name}
+}
+
+// lookupGlobal finds the global element a qualified name refers to
+func (sc *schemaConverter) lookupGlobal(qname string) (globalElement, bool) {
+	for _, key := range sc.lookupKeys(qname) {
+		if global, ok := sc.globals[key]; ok {
+			return global, true
+		}
+	}
+	return globalElement{}, false
+}
+
+// lookupAttribute finds the global attribute a qualified name refers to
+func (sc *schemaConverter) lookupAttribute(qname string) (globalAttribute, bool) {
+	for _, key := range sc.lookupKeys(qname) {
+		if global, ok := sc.attributes[key]; ok {
+			return global, true
+		}
+	}
+	return globalAttribute{}, false
+}
+
+// lookupAttributeGroup finds the attribute group a qualified name refers
+// to, returning the key it was found by
+func (sc *schemaConverter) lookupAttributeGroup(qname string) (rawAttributeGroup, string, bool) {
+	for _, key := range sc.lookupKeys(qname) {
+		if group, ok := sc.attrGroups[key]; ok {
+			return group, key, true
+		}
+	}
+	return rawAttributeGroup{}, "", false
+}
+
+// convert converts all named complex types and global elements
+func (sc *schemaConverter) convert() {
+	if sc.def.Types == nil {
+		sc.def.Types = make([]models.Type, 0)
+	}
+	if sc.def.SimpleTypes == nil {
+		sc.def.SimpleTypes = make([]models.SimpleType, 0)
+	}
+	if sc.def.Elements == nil {
+		sc.def.Elements = make([]models.Element, 0)
+	}
+
+	// Named simple types, and those declared inline on global attributes
+	for _, schema := range sc.schemas {
+		sc.enter(schema)
+		for _, st := range schema.SimpleType {
+			sc.convertSimpleType(st.Name, st)
+		}
+		for _, attr := range schema.Attribute {
+			if attr.SimpleType != nil {
+				sc.convertSimpleType(attr.Name, *attr.SimpleType)
+			}
+		}
+	}
+
+	// Named complex types first, so anonymous types can avoid their names
+	for _, schema := range sc.schemas {
+		for _, ct := range schema.ComplexType {
+			sc.typeSeen[ct.Name] = true
+		}
+	}
+	for _, schema := range sc.schemas {
+		sc.enter(schema)
+		for _, ct := range schema.ComplexType {
+			sc.convertComplexType(ct.Name, ct)
+		}
+	}
+
+	// Global elements, including their inline complex types
+	for _, schema := range sc.schemas {
+		sc.enter(schema)
+		for _, el := range schema.Element {
+			sc.def.Elements = append(sc.def.Elements, sc.convertElement("", el))
+		}
+	}
+
+	sc.inheritContent()
+}
+
+// convertComplexType converts a complex type and appends it to the model
+func (sc *schemaConverter) convertComplexType(name string, ct rawComplexType) {
+	t := models.Type{
+		Name:          name,
+		Namespace:     sc.namespace,
+		Documentation: ct.Annotation.Documentation.String(),
+		Elements:      make([]models.Element, 0),
+		Attributes:    make([]models.Attribute, 0),
+	}
+	// Most types are a flat sequence of elements
+	if ct.Sequence != nil {
+		t.Elements = make([]models.Element, 0, len(ct.Sequence.Particle))
+	}
+
+	for _, group := range []*rawParticle{ct.Sequence, ct.All, ct.Choice} {
+		if group != nil {
+			sc.convertParticle(name, &t, *group, particleContext{})
+		}
+	}
+
+	t.Mixed = ct.Mixed
+	t.Abstract = ct.Abstract
+	sc.convertAttributes(name, &t, ct.Attribute, ct.AttributeGroup, make(map[string]bool))
+
+	// Derived types keep their own content; inheritContent adds that of
+	// the base once all types are converted
+	if content := ct.ComplexContent; content != nil {
+		if derivation, kind := content.derivation(); derivation != nil {
+			t.Base, t.Derivation = derivation.Base, kind
+			for _, group := range []*rawParticle{derivation.Sequence, derivation.All, derivation.Choice} {
+				if group != nil {
+					sc.convertParticle(name, &t, *group, particleContext{})
+				}
+			}
+			sc.convertAttributes(name, &t, derivation.Attribute, derivation.AttributeGroup, make(map[string]bool))
+		}
+		t.Mixed = t.Mixed || content.Mixed
+	}
+	if content := ct.SimpleContent; content != nil {
+		if derivation, _ := content.derivation(); derivation != nil {
+			t.SimpleContent = derivation.Base
+			sc.convertAttributes(name, &t, derivation.Attribute, derivation.AttributeGroup, make(map[string]bool))
+		}
+		if t.SimpleContent == "" {
+			t.SimpleContent = "string"
+		}
+	}
+
+	sc.def.Types = append(sc.def.Types, t)
+}
+
+// convertAttributes appends attribute declarations and the attributes of
+// referenced attribute groups to the type. Inline simple types are hoisted
+// like those of elements; seen guards against circular group references.
+func (sc *schemaConverter) convertAttributes(parent string, t *models.Type, attrs []rawXSDAttribute, groups []rawAttributeGroup, seen map[string]bool) {
+	for _, attr := range attrs {
+		attribute := models.Attribute{Name: attr.Name, Type: sc.strings.intern(attr.Type), Use: sc.strings.intern(attr.Use)}
+
+		if attr.Ref != "" {
+			attribute.Name = models.LocalName(attr.Ref)
+			if global, ok := sc.lookupAttribute(attr.Ref); ok {
+				attribute.Namespace = global.namespace
+				attribute.Type = global.Type
+				if global.SimpleType != nil {
+					attribute.Type = global.Name
+				}
+			} else if strings.HasPrefix(attr.Ref, "xml:") {
+				attribute.Namespace = xmlNamespace
+			}
+		}
+
+		if attr.SimpleType != nil {
+			typeName := parent + "_" + attr.Name
+			sc.convertSimpleType(typeName, *attr.SimpleType)
+			attribute.Type = typeName
+		}
+
+		// An attribute without a type is xsd:anySimpleType
+		if attribute.Type == "" {
+			attribute.Type = "anySimpleType"
+		}
+		t.Attributes = append(t.Attributes, attribute)
+	}
+
+	for _, ref := range groups {
+		group, key, ok := sc.lookupAttributeGroup(ref.Ref)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		sc.convertAttributes(parent, t, group.Attribute, group.AttributeGroup, seen)
+	}
+}
+
+// inheritContent resolves types derived from other complex types. Simple
+// content takes the character data type of its base and extensions the
+// content of their base before their own. Both inherit the attributes of
+// the base, except those they redeclare or prohibit.
+func (sc *schemaConverter) inheritContent() {
+	types := make(map[string]*models.Type)
+	for i := range sc.def.Types {
+		if _, ok := types[sc.def.Types[i].Name]; !ok {
+			types[sc.def.Types[i].Name] = &sc.def.Types[i]
+		}
+	}
+
+	resolved := make(map[*models.Type]bool)
+	var resolve func(t *models.Type, visiting map[string]bool)
+	resolve = func(t *models.Type, visiting map[string]bool) {
+		if resolved[t] || visiting[t.Name] {
+			return
+		}
+		visiting[t.Name] = true
+		defer func() { resolved[t] = true }()
+
+		baseName := t.Base
+		if t.SimpleContent != "" {
+			baseName = t.SimpleContent
+		}
+		base, ok := types[models.LocalName(baseName)]
+		if !ok || base == t {
+			return
+		}
+		resolve(base, visiting)
+
+		if t.SimpleContent != "" {
+			t.SimpleContent = base.SimpleContent
+			if t.SimpleContent == "" {
+				t.SimpleContent = "string"
+			}
+		}
+		if t.Derivation == models.DerivationExtension {
+			own := t.Elements
+			t.Elements = append(append([]models.Element{}, base.Elements...), own...)
+			for i := len(base.Elements); i < len(t.Elements); i++ {
+				if t.Elements[i].Choice > 0 {
+					t.Elements[i].Choice += len(base.Choices)
+				}
+			}
+			t.Choices = append(append([]models.Choice{}, base.Choices...), t.Choices...)
+			t.Any = t.Any || base.Any
+			t.Mixed = t.Mixed || base.Mixed
+		}
+
+		declared := make(map[string]bool)
+		for _, attr := range t.Attributes {
+			declared[attr.Name] = true
+		}
+		var attrs []models.Attribute
+		for _, attr := range base.Attributes {
+			if !declared[attr.Name] {
+				attrs = append(attrs, attr)
+			}
+		}
+		t.Attributes = append(attrs, t.Attributes...)
+	}
+
+	for i := range sc.def.Types {
+		resolve(&sc.def.Types[i], make(map[string]bool))
+	}
+
+	// Prohibited attributes only served to hide those of the base
+	for i := range sc.def.Types {
+		t := &sc.def.Types[i]
+		attrs := t.Attributes[:0]
+		for _, attr := range t.Attributes {
+			if attr.Use != "prohibited" {
+				attrs = append(attrs, attr)
+			}
+		}
+		t.Attributes = attrs
+	}
+}
+
+// particleContext carries the constraints a content model places on the
+// particles nested inside it
+type particleContext struct {
+	choice   int  // Choice group of direct element children
+	optional bool // Inside an optional group or a choice
+	repeated bool // Inside a group with maxOccurs > 1
+	inChoice bool // Inside another xs:choice
+}
+
+// convertParticle flattens a content model particle into the type's
+// elements. Choice members become optional elements tagged with their
+// choice group; xs:all is treated like a sequence since the order of the
+// fields does not matter when decoding.
+func (sc *schemaConverter) convertParticle(parent string, t *models.Type, p rawParticle, ctx particleContext) {
+	switch p.XMLName.Local {
+	case "element":
+		el := sc.convertElement(parent, p.rawXSDElement)
+		if ctx.optional {
+			el.MinOccurs = "0"
+		}
+		if ctx.repeated {
+			el.MaxOccurs = "unbounded"
+		}
+		el.Choice = ctx.choice
+		t.Elements = append(t.Elements, el)
+
+	case "sequence", "all":
+		ctx.choice = 0
+		ctx.optional = ctx.optional || p.MinOccurs == "0"
+		ctx.repeated = ctx.repeated || isRepeated(p.MaxOccurs)
+		for _, child := range p.Particle {
+			sc.convertParticle(parent, t, *child, ctx)
+		}
+
+	case "choice":
+		ctx.repeated = ctx.repeated || isRepeated(p.MaxOccurs)
+		ctx.choice = 0
+
+		// Only a single choice between plain elements can be validated
+		// by counting the fields that are set
+		if !ctx.repeated && onlyElements(p.Particle) {
+			minOccurs := p.MinOccurs
+			if ctx.optional || ctx.inChoice {
+				minOccurs = "0"
+			}
+			t.Choices = append(t.Choices, models.Choice{MinOccurs: minOccurs})
+			ctx.choice = len(t.Choices)
+		}
+
+		ctx.optional = true
+		ctx.inChoice = true
+		for _, child := range p.Particle {
+			sc.convertParticle(parent, t, *child, ctx)
+		}
+
+	case "any":
+		t.Any = true
+	}
+}
+
+// onlyElements reports whether all particles are element declarations
+func onlyElements(particles []*rawParticle) bool {
+	count := 0
+	for _, p := range particles {
+		switch p.XMLName.Local {
+		case "element":
+			count++
+		case "annotation":
+		default:
+			return false
+		}
+	}
+	return count > 0
+}
+
+// isRepeated reports whether a maxOccurs value allows more than one
+// occurrence
+func isRepeated(maxOccurs string) bool {
+	return maxOccurs == "unbounded" || (maxOccurs != "" && maxOccurs != "0" && maxOccurs != "1")
+}
+
+// convertSimpleType converts a simple type restriction and appends it to
+// the model
+func (sc *schemaConverter) convertSimpleType(name string, st rawSimpleType) {
+	simpleType := models.SimpleType{
+		Name:          name,
+		Namespace:     sc.namespace,
+		Documentation: st.Annotation.Documentation.String(),
+		Base:          "string",
+	}
+
+	if r := st.Restriction; r != nil {
+		if r.Base != "" {
+			simpleType.Base = sc.strings.intern(r.Base)
+		}
+		for _, facet := range r.Enumeration {
+			simpleType.Enumeration = append(simpleType.Enumeration, facet.Value)
+		}
+		if len(r.Pattern) > 0 {
+			simpleType.Pattern = r.Pattern[0].Value
+		}
+	}
+
+	sc.def.SimpleTypes = append(sc.def.SimpleTypes, simpleType)
+}
+
+// convertElement converts an element declaration. Inline complex types are
+// hoisted into named types; nested ones are prefixed with the parent name.
+// Global and qualified local elements take the schema's namespace.
+func (sc *schemaConverter) convertElement(parent string, el rawXSDElement) models.Element {
+	element := models.Element{
+		Name:          el.Name,
+		Documentation: el.Annotation.Documentation.String(),
+		Type:          sc.strings.intern(el.Type),
+		MinOccurs:     sc.strings.intern(el.MinOccurs),
+		MaxOccurs:     sc.strings.intern(el.MaxOccurs),
+		Nillable:      el.Nillable,
+	}
+
+	if parent == "" || el.Form == "qualified" || (el.Form == "" && sc.qualified) {
+		element.Namespace = sc.namespace
+	}
+	if parent == "" {
+		element.SubstitutionGroup = el.Substitutes
+		element.Abstract = el.Abstract
+	}
+
+	// Resolve element references against the global elements
+	if el.Ref != "" {
+		refName := models.LocalName(el.Ref)
+		element.Name = refName
+		if global, ok := sc.lookupGlobal(el.Ref); ok {
+			element.Namespace = global.namespace
+			element.Type = global.Type
+			if global.ComplexType != nil || global.SimpleType != nil {
+				element.Type = refName
+			}
+			if element.Documentation == "" {
+				element.Documentation = global.Annotation.Documentation.String()
+			}
+		}
+	}
+
+	if el.ComplexType != nil {
+		typeName := el.Name
+		if parent != "" {
+			typeName = parent + "_" + el.Name
+		}
+		if parent == "" && sc.typeSeen[typeName] {
+			typeName += "_Element"
+		}
+		sc.typeSeen[typeName] = true
+		sc.convertComplexType(typeName, *el.ComplexType)
+		element.Type = typeName
+	}
+
+	if el.SimpleType != nil {
+		typeName := el.Name
+		if parent != "" {
+			typeName = parent + "_" + el.Name
+		}
+		sc.convertSimpleType(typeName, *el.SimpleType)
+		element.Type = typeName
+	}
+
+	// Substitutes without a type have the type of their head
+	if element.Type == "" && element.SubstitutionGroup != "" {
+		if head, ok := sc.lookupGlobal(element.SubstitutionGroup); ok {
+			element.Type = head.Type
+			if head.ComplexType != nil || head.SimpleType != nil {
+				element.Type = head.Name
+			}
+		}
+	}
+
+	// An element without a type is xsd:anyType
+	if element.Type == "" {
+		element.Type = "anyType"
+	}
+
+	return element
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// soap20BindingType is the type of WSDL 2.0 SOAP bindings
+const soap20BindingType = "http://www.w3.org/ns/wsdl/soap"
+
+// parseWSDL20 parses a WSDL 2.0 description document from its root
+// element on
+func (p *Parser) parseWSDL20(d *xml.Decoder, root xml.StartElement) (*models.Definitions, error) {
+	var raw rawDescription
+	if err := d.DecodeElement(&raw, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode WSDL 2.0 XML: %w", err)
+	}
+
+	return p.convertDescriptionToModel(&raw), nil
+}
+
+// convertDescriptionToModel converts a WSDL 2.0 description to internal
+// models. WSDL 2.0 has no messages, so one message with a single element
+// part is synthesized per operation input and output.
+func (p *Parser) convertDescriptionToModel(raw *rawDescription) *models.Definitions {
+	def := &models.Definitions{
+		Name:            raw.Name,
+		TargetNamespace: raw.TargetNamespace,
+		Services:        make([]models.Service, 0),
+		Bindings:        make([]models.Binding, 0),
+		PortTypes:       make([]models.PortType, 0),
+		Messages:        make([]models.Message, 0),
+	}
+
+	// Convert services and endpoints
+	for _, svc := range raw.Service {
+		service := models.Service{
+			Name:          svc.Name,
+			Documentation: svc.Documentation.String(),
+			Ports:         make([]models.Port, 0),
+		}
+		for _, ep := range svc.Endpoint {
+			service.Ports = append(service.Ports, models.Port{
+				Name:    ep.Name,
+				Binding: ep.Binding,
+				Address: ep.Address,
+			})
+		}
+		def.Services = append(def.Services, service)
+	}
+
+	// Convert bindings; operations reference interface operations by QName
+	for _, bind := range raw.Binding {
+		binding := models.Binding{
+			Name:       bind.Name,
+			Type:       bind.Interface,
+			Transport:  bind.Protocol,
+			Operations: make([]models.BindingOperation, 0),
+		}
+		// SOAP bindings default to SOAP 1.2 unless wsoap:version says otherwise
+		if bind.Type == soap20BindingType {
+			binding.SOAPVersion = bind.Version
+			if binding.SOAPVersion == "" {
+				binding.SOAPVersion = "1.2"
+			}
+		}
+		for _, op := range bind.Operation {
+			binding.Operations = append(binding.Operations, models.BindingOperation{
+				Name:       models.LocalName(op.Ref),
+				SoapAction: op.Action,
+			})
+		}
+		def.Bindings = append(def.Bindings, binding)
+	}
+
+	// Convert interfaces to port types
+	for _, iface := range raw.Interface {
+		portType := models.PortType{
+			Name:          iface.Name,
+			Documentation: iface.Documentation.String(),
+			Operations:    make([]models.Operation, 0),
+		}
+		for _, op := range iface.Operation {
+			inputName := op.Name + "Input"
+			outputName := op.Name + "Output"
+
+			def.Messages = append(def.Messages, synthesizeMessage(inputName, op.Input.Element))
+			if op.Output != nil {
+				def.Messages = append(def.Messages, synthesizeMessage(outputName, op.Output.Element))
+			}
+
+			operation := models.Operation{
+				Name:          op.Name,
+				Documentation: op.Documentation.String(),
+				Pattern:       exchangePattern(op.Pattern),
+				Input: models.Message{
+					Name: inputName,
+				},
+			}
+			if op.Output != nil {
+				operation.Output = models.Message{
+					Name: outputName,
+				}
+			}
+			portType.Operations = append(portType.Operations, operation)
+		}
+		def.PortTypes = append(def.PortTypes, portType)
+	}
+
+	// Convert schema types
+	sc := newSchemaConverter(def, raw.Types.Schema, make(interner))
+	sc.declarePrefixes(raw.Attrs)
+	sc.convert()
+
+	return def
+}
+
+// exchangePattern maps a WSDL 2.0 message exchange pattern URI to the
+// equivalent WSDL 1.1 transmission primitive
+func exchangePattern(pattern string) string {
+	switch pattern[strings.LastIndex(pattern, "/")+1:] {
+	case "in-only", "robust-in-only":
+		return models.PatternOneWay
+	case "out-only", "robust-out-only":
+		return models.PatternNotification
+	case "out-in", "out-opt-in":
+		return models.PatternSolicitResponse
+	default:
+		return models.PatternRequestResponse
+	}
+}
+
+// synthesizeMessage creates a message wrapping a single schema element
+func synthesizeMessage(name, element string) models.Message {
+	message := models.Message{
+		Name:  name,
+		Parts: make([]models.Part, 0),
+	}
+	if element != "" && element != "#none" && element != "#any" {
+		message.Parts = append(message.Parts, models.Part{
+			Name:    "parameters",
+			Element: element,
+		})
+	}
+	return message
+}
+
+// Raw WSDL 2.0 structures for unmarshaling
+type rawDescription struct {
+	XMLName         xml.Name       `xml:"description"`
+	Name            string         `xml:"name,attr"`
+	TargetNamespace string         `xml:"targetNamespace,attr"`
+	Types           rawTypes       `xml:"types"`
+	Interface       []rawInterface `xml:"interface"`
+	Binding         []rawBinding20 `xml:"binding"`
+	Service         []rawService20 `xml:"service"`
+	Attrs           []xml.Attr     `xml:",any,attr"`
+}
+
+type rawInterface struct {
+	Name          string                  `xml:"name,attr"`
+	Documentation rawDocumentation        `xml:"documentation"`
+	Operation     []rawInterfaceOperation `xml:"operation"`
+}
+
+type rawInterfaceOperation struct {
+	Name          string               `xml:"name,attr"`
+	Pattern       string               `xml:"pattern,attr"`
+	Documentation rawDocumentation     `xml:"documentation"`
+	Input         rawMessageReference  `xml:"input"`
+	Output        *rawMessageReference `xml:"output"`
+}
+
+type rawMessageReference struct {
+	Element string `xml:"element,attr"`
+}
+
+type rawBinding20 struct {
+	Name      string                  `xml:"name,attr"`
+	Interface string                  `xml:"interface,attr"`
+	Type      string                  `xml:"type,attr"`
+	Protocol  string                  `xml:"protocol,attr"`
+	Version   string                  `xml:"version,attr"`
+	Operation []rawBindingOperation20 `xml:"operation"`
+}
+
+type rawBindingOperation20 struct {
+	Ref    string `xml:"ref,attr"`
+	Action string `xml:"action,attr"`
+}
+
+type rawService20 struct {
+	Name          string           `xml:"name,attr"`
+	Documentation rawDocumentation `xml:"documentation"`
+	Interface     string           `xml:"interface,attr"`
+	Endpoint      []rawEndpoint    `xml:"endpoint"`
+}
+
+type rawEndpoint struct {
+	Name    string `xml:"name,attr"`
+	Binding string `xml:"binding,attr"`
+	Address string `xml:"address,attr"`
+}
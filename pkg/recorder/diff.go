@@ -0,0 +1,200 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of differences between a request and a recorded one
+const (
+	DiffMissing    = "missing"    // An element or attribute of the recording isn't in the request
+	DiffUnexpected = "unexpected" // An element or attribute of the request isn't in the recording
+	DiffValue      = "value"      // The text or attribute value differs
+	DiffNamespace  = "namespace"  // The element has another namespace
+)
+
+// Difference is a difference between the SOAP body of a request and the
+// one of a recording
+type Difference struct {
+	Path     string `xml:"path,attr" json:"path"` // Such as Add/items/item[2]/@id
+	Kind     string `xml:"kind,attr" json:"kind"`
+	Expected string `xml:"expected,omitempty" json:"expected,omitempty"`
+	Actual   string `xml:"actual,omitempty" json:"actual,omitempty"`
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffMissing:
+		if d.Expected != "" {
+			return fmt.Sprintf("%s: missing, expected %q", d.Path, d.Expected)
+		}
+		return d.Path + ": missing"
+	case DiffUnexpected:
+		if d.Actual != "" {
+			return fmt.Sprintf("%s: unexpected, got %q", d.Path, d.Actual)
+		}
+		return d.Path + ": unexpected"
+	case DiffNamespace:
+		return fmt.Sprintf("%s: expected namespace %q, got %q", d.Path, d.Expected, d.Actual)
+	}
+	return fmt.Sprintf("%s: expected %q, got %q", d.Path, d.Expected, d.Actual)
+}
+
+// Diff compares the SOAP body of a request with the one of a recorded
+// request. Elements are paired by name in order; repeated elements are
+// numbered from 1 in paths, as in XPath. Headers, namespace prefixes,
+// attribute order and whitespace around text don't count, as for Key.
+func Diff(expected, actual []byte) []Difference {
+	var diffs []Difference
+	compareChildren(&diffs, "", &node{children: bodyContent(expected)}, &node{children: bodyContent(actual)})
+	return diffs
+}
+
+// node is an element of a SOAP body
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr // Without namespace declarations
+	text     string     // Trimmed character data of elements without children
+	children []*node
+}
+
+// bodyContent returns the elements of the SOAP body of a request
+func bodyContent(body []byte) []*node {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	root := &node{}
+	stack := []*node{root}
+	var text strings.Builder
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return root.children
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !inBody {
+				inBody = t.Name.Local == "Body"
+				continue
+			}
+			n := &node{name: t.Name}
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "xmlns" && !(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					n.attrs = append(n.attrs, attr)
+				}
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+			text.Reset()
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+			if len(stack) == 1 {
+				// The end of the Body
+				return root.children
+			}
+			n := stack[len(stack)-1]
+			if len(n.children) == 0 {
+				n.text = strings.TrimSpace(text.String())
+			}
+			stack = stack[:len(stack)-1]
+			text.Reset()
+		case xml.CharData:
+			if inBody {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+// compareChildren compares the child elements of the elements at path,
+// pairing them by local name in order
+func compareChildren(diffs *[]Difference, path string, expectedParent, actualParent *node) {
+	var names []string
+	byName := func(nodes []*node) map[string][]*node {
+		m := make(map[string][]*node)
+		for _, n := range nodes {
+			if !slices.Contains(names, n.name.Local) {
+				names = append(names, n.name.Local)
+			}
+			m[n.name.Local] = append(m[n.name.Local], n)
+		}
+		return m
+	}
+	want, got := byName(expectedParent.children), byName(actualParent.children)
+
+	for _, name := range names {
+		w, g := want[name], got[name]
+		repeated := len(w) > 1 || len(g) > 1
+		for i := 0; i < len(w) || i < len(g); i++ {
+			p := joinPath(path, name)
+			if repeated {
+				p = fmt.Sprintf("%s[%d]", p, i+1)
+			}
+			switch {
+			case i >= len(g):
+				*diffs = append(*diffs, Difference{Path: p, Kind: DiffMissing, Expected: w[i].text})
+			case i >= len(w):
+				*diffs = append(*diffs, Difference{Path: p, Kind: DiffUnexpected, Actual: g[i].text})
+			default:
+				compareNodes(diffs, p, expectedParent, actualParent, w[i], g[i])
+			}
+		}
+	}
+}
+
+// compareNodes compares two elements of the same local name. Namespaces
+// the elements inherit from their parents, as default namespaces, differ
+// once for the parents only.
+func compareNodes(diffs *[]Difference, path string, expectedParent, actualParent, expected, actual *node) {
+	inherited := expected.name.Space == expectedParent.name.Space && actual.name.Space == actualParent.name.Space
+	if expected.name.Space != actual.name.Space && !inherited {
+		*diffs = append(*diffs, Difference{Path: path, Kind: DiffNamespace, Expected: expected.name.Space, Actual: actual.name.Space})
+	}
+
+	for _, attr := range expected.attrs {
+		p := path + "/@" + attr.Name.Local
+		value, ok := attrValue(actual.attrs, attr.Name)
+		switch {
+		case !ok:
+			*diffs = append(*diffs, Difference{Path: p, Kind: DiffMissing, Expected: attr.Value})
+		case value != attr.Value:
+			*diffs = append(*diffs, Difference{Path: p, Kind: DiffValue, Expected: attr.Value, Actual: value})
+		}
+	}
+	for _, attr := range actual.attrs {
+		if _, ok := attrValue(expected.attrs, attr.Name); !ok {
+			*diffs = append(*diffs, Difference{Path: path + "/@" + attr.Name.Local, Kind: DiffUnexpected, Actual: attr.Value})
+		}
+	}
+
+	if len(expected.children) == 0 && len(actual.children) == 0 {
+		if expected.text != actual.text {
+			*diffs = append(*diffs, Difference{Path: path, Kind: DiffValue, Expected: expected.text, Actual: actual.text})
+		}
+		return
+	}
+	compareChildren(diffs, path, expected, actual)
+}
+
+// attrValue returns the value of an attribute by name
+func attrValue(attrs []xml.Attr, name xml.Name) (string, bool) {
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// joinPath appends the name of an element to a path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "/" + name
+}
@@ -0,0 +1,53 @@
+package recorder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	const recorded = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Header><Nonce>n1</Nonce></soap:Header>
+  <soap:Body><Order xmlns="urn:shop" id="7"><item>pen</item><item>ink</item><note>gift</note></Order></soap:Body>
+</soap:Envelope>`
+
+	tests := []struct {
+		name    string
+		request string
+		want    []Difference
+	}{
+		{
+			name:    "same body with other prefixes and headers",
+			request: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Header><Nonce>n2</Nonce></s:Header><s:Body><o:Order xmlns:o="urn:shop" id="7"><o:item>pen</o:item> <o:item>ink</o:item><o:note>gift</o:note></o:Order></s:Body></s:Envelope>`,
+		},
+		{
+			name:    "wrong value and missing element",
+			request: `<Envelope><Body><Order xmlns="urn:shop" id="8"><item>pen</item><item>paper</item></Order></Body></Envelope>`,
+			want: []Difference{
+				{Path: "Order/@id", Kind: DiffValue, Expected: "7", Actual: "8"},
+				{Path: "Order/item[2]", Kind: DiffValue, Expected: "ink", Actual: "paper"},
+				{Path: "Order/note", Kind: DiffMissing, Expected: "gift"},
+			},
+		},
+		{
+			name:    "unexpected element and attribute",
+			request: `<Envelope><Body><Order xmlns="urn:shop" id="7" rush="true"><item>pen</item><item>ink</item><item>nib</item><note>gift</note></Order></Body></Envelope>`,
+			want: []Difference{
+				{Path: "Order/@rush", Kind: DiffUnexpected, Actual: "true"},
+				{Path: "Order/item[3]", Kind: DiffUnexpected, Actual: "nib"},
+			},
+		},
+		{
+			name:    "other namespace",
+			request: `<Envelope><Body><Order xmlns="urn:store" id="7"><item>pen</item><item>ink</item><note>gift</note></Order></Body></Envelope>`,
+			want: []Difference{
+				{Path: "Order", Kind: DiffNamespace, Expected: "urn:shop", Actual: "urn:store"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := Diff([]byte(recorded), []byte(tt.request)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Diff() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
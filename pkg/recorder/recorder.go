@@ -0,0 +1,392 @@
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Interaction is a SOAP request and the response recorded for it
+type Interaction struct {
+	Operation   string `json:"operation"`
+	SOAPAction  string `json:"soapAction,omitempty"`
+	Key         string `json:"key"` // Hash of the canonical request body
+	Request     string `json:"request"`
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Response    string `json:"response"`
+}
+
+// Write writes the recorded response
+func (i *Interaction) Write(w http.ResponseWriter) {
+	if i.ContentType != "" {
+		w.Header().Set("Content-Type", i.ContentType)
+	}
+	w.WriteHeader(i.Status)
+	w.Write([]byte(i.Response))
+}
+
+// fileName returns the name of the file an interaction is saved in.
+// Recording the same request again replaces the file.
+func (i *Interaction) fileName() string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, i.Operation)
+	if name == "" {
+		name = "unknown"
+	}
+	return fmt.Sprintf("%s-%s.json", name, i.Key[:12])
+}
+
+// Recorder is an http.Handler that proxies SOAP requests to a live endpoint
+// and saves each request and response in a directory
+type Recorder struct {
+	Target string
+	Dir    string
+	Client *http.Client
+}
+
+// NewRecorder creates a recorder proxying to target and saving to dir
+func NewRecorder(target, dir string) *Recorder {
+	return &Recorder{
+		Target: target,
+		Dir:    dir,
+		Client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ServeHTTP forwards the request to the target and records the exchange.
+// Responses are recorded whatever their status, so faults replay too.
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, rec.Target, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	req.Header = r.Header.Clone()
+
+	resp, err := rec.Client.Do(req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to call %s: %v", rec.Target, err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read response from %s: %v", rec.Target, err), http.StatusBadGateway)
+		return
+	}
+
+	interaction := &Interaction{
+		Operation:   Operation(body),
+		SOAPAction:  SOAPAction(r),
+		Key:         Key(body),
+		Request:     string(body),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Response:    string(respBody),
+	}
+	if err := rec.Save(interaction); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	interaction.Write(w)
+}
+
+// Save writes an interaction to the recorder's directory
+func (rec *Recorder) Save(interaction *Interaction) error {
+	if err := os.MkdirAll(rec.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	// Keep the XML readable in the recordings
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(interaction); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(rec.Dir, interaction.fileName()), data.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save recording: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an http.Handler answering SOAP requests with recorded
+// responses
+type Replayer struct {
+	byKey       map[string]*Interaction
+	byOperation map[string][]*Interaction
+	count       int
+	strict      bool
+}
+
+// Load loads the interactions recorded in dir
+func Load(dir string) (*Replayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	p := &Replayer{
+		byKey:       make(map[string]*Interaction),
+		byOperation: make(map[string][]*Interaction),
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		interaction := &Interaction{}
+		if err := json.Unmarshal(data, interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+		}
+		p.byKey[interaction.Key] = interaction
+		p.byOperation[interaction.Operation] = append(p.byOperation[interaction.Operation], interaction)
+		p.count++
+	}
+	return p, nil
+}
+
+// Len returns the number of recorded interactions
+func (p *Replayer) Len() int {
+	return p.count
+}
+
+// SetStrict makes requests that were not recorded as is get a SOAP fault
+// detailing how they differ from the closest recording of their operation,
+// rather than that recording's response
+func (p *Replayer) SetStrict(strict bool) {
+	p.strict = strict
+}
+
+// Lookup returns the interaction recorded for a request body. A request
+// that was not recorded as is gets the recording of its operation closest
+// to it, the first one of those with the fewest differences, so changing
+// values such as timestamps replay deterministically.
+func (p *Replayer) Lookup(body []byte) (*Interaction, bool) {
+	if interaction, ok := p.byKey[Key(body)]; ok {
+		return interaction, true
+	}
+	if interaction, _ := p.closest(body); interaction != nil {
+		return interaction, true
+	}
+	return nil, false
+}
+
+// closest returns the recording of the operation of a request with the
+// fewest differences to it, and the differences
+func (p *Replayer) closest(body []byte) (*Interaction, []Difference) {
+	var best *Interaction
+	var diffs []Difference
+	for _, interaction := range p.byOperation[Operation(body)] {
+		d := Diff([]byte(interaction.Request), body)
+		if best == nil || len(d) < len(diffs) {
+			best, diffs = interaction, d
+		}
+	}
+	return best, diffs
+}
+
+// Replay answers a request with its recorded response and reports whether
+// its operation was recorded. A request that was not recorded as is gets
+// the closest recording, or a Mismatch fault in strict mode, and its
+// differences to the recording are logged.
+func (p *Replayer) Replay(w http.ResponseWriter, body []byte) bool {
+	if interaction, ok := p.byKey[Key(body)]; ok {
+		interaction.Write(w)
+		return true
+	}
+	interaction, diffs := p.closest(body)
+	if interaction == nil {
+		return false
+	}
+	if len(diffs) == 0 {
+		interaction.Write(w)
+		return true
+	}
+
+	mismatch := &Mismatch{Operation: interaction.Operation, Recording: interaction, Differences: diffs}
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = d.String()
+	}
+	slog.Warn("request differs from the recording", "operation", mismatch.Operation, "recording", interaction.fileName(), "differences", strings.Join(lines, "; "))
+
+	if p.strict {
+		mismatch.Write(w)
+		return true
+	}
+	interaction.Write(w)
+	return true
+}
+
+// ServeHTTP answers with the recorded response, or a SOAP fault when the
+// operation was never recorded
+func (p *Replayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	if !p.Replay(w, body) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>No recording for operation: %s</faultstring></soap:Fault></soap:Body></soap:Envelope>`, xmlEscape(Operation(body)))
+	}
+}
+
+// Mismatch is a request that differs from the closest recording of its
+// operation
+type Mismatch struct {
+	Operation   string
+	Recording   *Interaction
+	Differences []Difference
+}
+
+func (m *Mismatch) Error() string {
+	lines := make([]string, len(m.Differences))
+	for i, d := range m.Differences {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("request differs from the %s recording %s: %s", m.Operation, m.Recording.fileName(), strings.Join(lines, "; "))
+}
+
+// Write writes a SOAP client fault whose detail lists the differences, as
+// in
+//
+//	<mismatch operation="Add" recording="Add-0123456789ab.json">
+//	  <difference path="Add/a" kind="value"><expected>5</expected><actual>6</actual></difference>
+//	</mismatch>
+func (m *Mismatch) Write(w http.ResponseWriter) {
+	detail := struct {
+		XMLName     xml.Name     `xml:"mismatch"`
+		Operation   string       `xml:"operation,attr"`
+		Recording   string       `xml:"recording,attr"`
+		Differences []Difference `xml:"difference"`
+	}{Operation: m.Operation, Recording: m.Recording.fileName(), Differences: m.Differences}
+	data, err := xml.MarshalIndent(detail, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>%s</faultstring><detail>
+%s
+</detail></soap:Fault></soap:Body></soap:Envelope>`, xmlEscape("Request differs from the recording of "+m.Operation), data)
+}
+
+// Operation returns the local name of the first element in the SOAP body
+// of a request, which names the operation in both document and rpc style
+func Operation(body []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if inBody {
+				return start.Name.Local
+			}
+			inBody = start.Name.Local == "Body"
+		}
+	}
+}
+
+// SOAPAction returns the action of a SOAP request: its SOAPAction header
+// for SOAP 1.1, or the action parameter of its Content-Type for SOAP 1.2
+func SOAPAction(r *http.Request) string {
+	if action := r.Header.Get("SOAPAction"); action != "" {
+		return strings.Trim(action, `"`)
+	}
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return params["action"]
+}
+
+// Key returns a hash of the canonical SOAP body of a request. Headers,
+// which hold nonces and timestamps, namespace prefixes, attribute order and
+// whitespace between elements don't change the key.
+func Key(body []byte) string {
+	var canonical strings.Builder
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+				var attrs []string
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" && attr.Name.Space == "" {
+						continue
+					}
+					attrs = append(attrs, fmt.Sprintf("{%s}%s=%q", attr.Name.Space, attr.Name.Local, attr.Value))
+				}
+				sort.Strings(attrs)
+				canonical.WriteString(fmt.Sprintf("<{%s}%s %s>", t.Name.Space, t.Name.Local, strings.Join(attrs, " ")))
+			} else if t.Name.Local == "Body" {
+				depth = 1
+			}
+		case xml.EndElement:
+			if depth > 1 {
+				canonical.WriteString("</>")
+			}
+			if depth > 0 {
+				depth--
+			}
+		case xml.CharData:
+			if depth > 1 {
+				canonical.WriteString(xmlEscape(strings.TrimSpace(string(t))))
+			}
+		}
+	}
+
+	sum := sha256.Sum256([]byte(canonical.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// xmlEscape escapes text for XML character data
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
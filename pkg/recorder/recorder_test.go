@@ -0,0 +1,189 @@
+package recorder
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const addRequest = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Header><Nonce>%s</Nonce></soap:Header>
+  <soap:Body><Add xmlns="urn:calc"><a>%s</a><b>3</b></Add></soap:Body>
+</soap:Envelope>`
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("SOAPAction") != `"urn:calc/Add"` {
+			t.Errorf("SOAPAction = %q, want it forwarded", r.Header.Get("SOAPAction"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/xml")
+		if strings.Contains(string(body), "<a>5</a>") {
+			w.Write([]byte(`<Envelope><Body><AddResponse><result>8</result></AddResponse></Body></Envelope>`))
+			return
+		}
+		w.Write([]byte(`<Envelope><Body><AddResponse><result>4</result></AddResponse></Body></Envelope>`))
+	}))
+	defer backend.Close()
+
+	dir := t.TempDir()
+	proxy := httptest.NewServer(NewRecorder(backend.URL, dir))
+	defer proxy.Close()
+
+	for _, a := range []string{"5", "1"} {
+		req, _ := http.NewRequest(http.MethodPost, proxy.URL, strings.NewReader(fmt.Sprintf(addRequest, "n1", a)))
+		req.Header.Set("SOAPAction", `"urn:calc/Add"`)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if calls != 2 {
+		t.Fatalf("backend called %d times, want 2", calls)
+	}
+
+	replayer, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayer.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", replayer.Len())
+	}
+
+	replay := httptest.NewServer(replayer)
+	defer replay.Close()
+
+	tests := []struct {
+		name    string
+		request string
+		status  int
+		want    string
+	}{
+		{
+			name:    "same body with another header",
+			request: fmt.Sprintf(addRequest, "n2", "5"),
+			status:  http.StatusOK,
+			want:    "<result>8</result>",
+		},
+		{
+			name:    "other prefixes and whitespace",
+			request: `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><c:Add xmlns:c="urn:calc"><c:a>1</c:a> <c:b>3</c:b></c:Add></s:Body></s:Envelope>`,
+			status:  http.StatusOK,
+			want:    "<result>4</result>",
+		},
+		{
+			name:    "unrecorded operation",
+			request: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Sub xmlns="urn:calc"/></soap:Body></soap:Envelope>`,
+			status:  http.StatusInternalServerError,
+			want:    "No recording for operation: Sub",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(replay.URL, "text/xml", strings.NewReader(tt.request))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if !strings.Contains(string(body), tt.want) {
+				t.Errorf("body = %s, want it to contain %s", body, tt.want)
+			}
+		})
+	}
+	if calls != 2 {
+		t.Errorf("backend called %d times during replay", calls-2)
+	}
+}
+
+func TestLookupFallsBackToOperation(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecorder("", dir)
+	body := []byte(`<Envelope><Body><Add><a>5</a></Add></Body></Envelope>`)
+	if err := rec.Save(&Interaction{Operation: Operation(body), Key: Key(body), Status: http.StatusOK, Response: "recorded"}); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interaction, ok := replayer.Lookup([]byte(`<Envelope><Body><Add><a>6</a></Add></Body></Envelope>`))
+	if !ok || interaction.Response != "recorded" {
+		t.Errorf("Lookup() = %v, %v, want the Add recording", interaction, ok)
+	}
+	if _, ok := replayer.Lookup([]byte(`<Envelope><Body><Sub/></Body></Envelope>`)); ok {
+		t.Error("Lookup() found a recording for Sub")
+	}
+}
+
+func TestReplayClosestRecording(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecorder("", dir)
+	for _, a := range []string{"1", "5"} {
+		body := []byte(fmt.Sprintf(addRequest, "n1", a))
+		if err := rec.Save(&Interaction{Operation: Operation(body), Key: Key(body), Request: string(body), Status: http.StatusOK, Response: "sum of " + a}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replayer, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := []byte(`<Envelope><Body><Add xmlns="urn:calc"><a>5</a><b>4</b></Add></Body></Envelope>`)
+	w := httptest.NewRecorder()
+	if !replayer.Replay(w, request) || w.Body.String() != "sum of 5" {
+		t.Errorf("Replay() = %d %s, want the closest recording", w.Code, w.Body)
+	}
+
+	replayer.SetStrict(true)
+	w = httptest.NewRecorder()
+	if !replayer.Replay(w, request) {
+		t.Fatal("Replay() found no recording of Add")
+	}
+	want := `<difference path="Add/b" kind="value">
+    <expected>3</expected>
+    <actual>4</actual>
+  </difference>`
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "<faultcode>soap:Client</faultcode>") || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("strict Replay() = %d %s, want a fault detailing the difference", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	if !replayer.Replay(w, []byte(fmt.Sprintf(addRequest, "n2", "1"))) || w.Body.String() != "sum of 1" {
+		t.Errorf("strict Replay() of a recorded request = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestSOAPAction(t *testing.T) {
+	tests := []struct {
+		soapAction, contentType, want string
+	}{
+		{`"urn:calc/Add"`, "text/xml; charset=utf-8", "urn:calc/Add"},
+		{"", `application/soap+xml; charset=utf-8; action="urn:calc/Add"`, "urn:calc/Add"},
+		{"", "application/soap+xml;action=Add", "Add"},
+		{"", "text/xml", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if tt.soapAction != "" {
+			req.Header.Set("SOAPAction", tt.soapAction)
+		}
+		req.Header.Set("Content-Type", tt.contentType)
+		if got := SOAPAction(req); got != tt.want {
+			t.Errorf("SOAPAction(%q, %q) = %q, want %q", tt.soapAction, tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package routes maps WSDL operations to the REST routes the proxy serves
+// and the exporter documents. Without configuration every operation is
+// POST /{Operation}; heuristics and per-operation overrides make the routes
+// look like a REST API instead.
+package routes
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// methods are the HTTP methods a route may use
+var methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// verbPrefixes are the operation name prefixes the heuristics map to a
+// method other than POST
+var verbPrefixes = []struct {
+	prefix string
+	method string
+}{
+	{"get", "GET"},
+	{"list", "GET"},
+	{"find", "GET"},
+	{"search", "GET"},
+	{"delete", "DELETE"},
+	{"remove", "DELETE"},
+}
+
+// Route is the HTTP method and path of an operation's REST endpoint
+type Route struct {
+	// Method is GET, POST, PUT, PATCH or DELETE
+	Method string `yaml:"method"`
+	// Path is relative to the API base path, with {field} placeholders
+	// taking input fields from the path
+	Path string `yaml:"path"`
+}
+
+// HasBody reports whether the input fields are sent as a JSON body. GET
+// and DELETE requests send them in the query string instead.
+func (r Route) HasBody() bool {
+	return r.Method != "GET" && r.Method != "DELETE"
+}
+
+// PathParams returns the names of the {field} placeholders of the path
+func (r Route) PathParams() []string {
+	var params []string
+	for _, segment := range strings.Split(r.Path, "/") {
+		if name, ok := pathParam(segment); ok {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// GinPath returns the path in gin syntax, with :field placeholders
+func (r Route) GinPath() string {
+	segments := strings.Split(r.Path, "/")
+	for i, segment := range segments {
+		if name, ok := pathParam(segment); ok {
+			segments[i] = ":" + name
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// String formats the route as "METHOD /path"
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// pathParam returns the field name of a {field} path segment
+func pathParam(segment string) (string, bool) {
+	if len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+// Config customizes the routes of operations. A nil Config serves every
+// operation at POST /{Operation}.
+type Config struct {
+	// Heuristics derives the method from the operation name: Get*, List*,
+	// Find* and Search* become GET and Delete* and Remove* become DELETE
+	Heuristics bool `yaml:"heuristics"`
+	// Operations overrides the method, the path or both by operation name
+	Operations map[string]Route `yaml:"operations"`
+}
+
+// Load reads a YAML route config such as
+//
+//	heuristics: true
+//	operations:
+//	  GetUser:
+//	    path: /users/{id}
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a YAML route config, as Load does
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse routes: %w", err)
+	}
+	for name, route := range cfg.Operations {
+		route.Method = strings.ToUpper(route.Method)
+		if route.Method != "" && !validMethod(route.Method) {
+			return nil, fmt.Errorf("operation %s: unsupported method %q (use %s)", name, route.Method, strings.Join(methods, ", "))
+		}
+		if route.Path != "" && !strings.HasPrefix(route.Path, "/") {
+			return nil, fmt.Errorf("operation %s: path %q must start with /", name, route.Path)
+		}
+		cfg.Operations[name] = route
+	}
+
+	return &cfg, nil
+}
+
+// validMethod reports whether a route may use method
+func validMethod(method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Route returns the route of an operation
+func (c *Config) Route(operation string) Route {
+	route := Route{Method: "POST", Path: "/" + operation}
+	if c == nil {
+		return route
+	}
+
+	if c.Heuristics {
+		route.Method = verb(operation)
+	}
+	if override, ok := c.Operations[operation]; ok {
+		if override.Method != "" {
+			route.Method = override.Method
+		}
+		if override.Path != "" {
+			route.Path = override.Path
+		}
+	}
+	return route
+}
+
+// verb returns the method of an operation from its name prefix, followed
+// by a new word as in getUser or Delete_Item
+func verb(operation string) string {
+	lower := strings.ToLower(operation)
+	for _, v := range verbPrefixes {
+		if !strings.HasPrefix(lower, v.prefix) {
+			continue
+		}
+		rest := []rune(operation[len(v.prefix):])
+		if len(rest) == 0 || !unicode.IsLower(rest[0]) {
+			return v.method
+		}
+	}
+	return "POST"
+}
+
+// Check returns an error when an override names an operation none of defs
+// define, a path parameter is not an input field, or two operations of a
+// definition share a route
+func (c *Config) Check(defs ...*models.Definitions) error {
+	if c == nil {
+		return nil
+	}
+
+	defined := make(map[string]bool)
+	for _, def := range defs {
+		seen := make(map[string]string)
+		for _, pt := range def.PortTypes {
+			for _, op := range pt.Operations {
+				if defined[op.Name] {
+					continue
+				}
+				defined[op.Name] = true
+
+				route := c.Route(op.Name)
+				fields := inputFields(def, op)
+				for _, param := range route.PathParams() {
+					if fields != nil && !fields[param] {
+						return fmt.Errorf("operation %s: path parameter {%s} is not an input field", op.Name, param)
+					}
+				}
+
+				// Routes differing only in parameter names still collide
+				key := route.Method + " " + route.GinPath()
+				for _, param := range route.PathParams() {
+					key = strings.Replace(key, ":"+param, ":", 1)
+				}
+				if other, ok := seen[key]; ok {
+					return fmt.Errorf("operations %s and %s share the route %s", other, op.Name, route)
+				}
+				seen[key] = op.Name
+			}
+		}
+	}
+
+	var unknown []string
+	for name := range c.Operations {
+		if !defined[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("routes for undefined operations: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// inputFields returns the names of the input fields of an operation: the
+// children of a single element part, or else the part names. It returns
+// nil when the fields can't be resolved.
+func inputFields(def *models.Definitions, op models.Operation) map[string]bool {
+	name := models.LocalName(op.Input.Name)
+	for _, msg := range def.Messages {
+		if msg.Name != name {
+			continue
+		}
+
+		fields := make(map[string]bool)
+		if len(msg.Parts) == 1 && msg.Parts[0].Element != "" {
+			typeName := ""
+			for _, elem := range def.Elements {
+				if elem.Name == models.LocalName(msg.Parts[0].Element) {
+					typeName = models.LocalName(elem.Type)
+				}
+			}
+			for _, t := range def.Types {
+				if t.Name == typeName {
+					for _, elem := range t.Elements {
+						fields[elem.Name] = true
+					}
+					for _, attr := range t.Attributes {
+						fields[attr.Name] = true
+					}
+					return fields
+				}
+			}
+			return nil
+		}
+		for _, part := range msg.Parts {
+			fields[part.Name] = true
+		}
+		return fields
+	}
+	return nil
+}
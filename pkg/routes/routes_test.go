@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestRoute(t *testing.T) {
+	cfg := &Config{
+		Heuristics: true,
+		Operations: map[string]Route{
+			"GetUser":    {Path: "/users/{id}"},
+			"UpdateUser": {Method: "PUT", Path: "/users/{id}"},
+		},
+	}
+
+	tests := []struct {
+		cfg       *Config
+		operation string
+		want      Route
+	}{
+		{nil, "GetUser", Route{"POST", "/GetUser"}},
+		{&Config{}, "GetUser", Route{"POST", "/GetUser"}},
+		{cfg, "GetUser", Route{"GET", "/users/{id}"}},
+		{cfg, "listOrders", Route{"GET", "/listOrders"}},
+		{cfg, "Delete_Item", Route{"DELETE", "/Delete_Item"}},
+		{cfg, "Getaway", Route{"POST", "/Getaway"}},
+		{cfg, "UpdateUser", Route{"PUT", "/users/{id}"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.Route(tt.operation); got != tt.want {
+			t.Errorf("Route(%q) = %v, want %v", tt.operation, got, tt.want)
+		}
+	}
+}
+
+func TestRoutePaths(t *testing.T) {
+	route := Route{Method: "GET", Path: "/users/{id}/orders/{order-id}"}
+
+	if got := route.PathParams(); !reflect.DeepEqual(got, []string{"id", "order-id"}) {
+		t.Errorf("PathParams() = %v", got)
+	}
+	if got := route.GinPath(); got != "/users/:id/orders/:order-id" {
+		t.Errorf("GinPath() = %q", got)
+	}
+	if route.HasBody() || !(Route{Method: "PATCH"}).HasBody() {
+		t.Error("only GET and DELETE routes should have no body")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "routes.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := Load(write(`heuristics: true
+operations:
+  GetUser:
+    method: get
+    path: /users/{id}
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Heuristics || cfg.Operations["GetUser"] != (Route{"GET", "/users/{id}"}) {
+		t.Errorf("Load() = %+v", cfg)
+	}
+
+	for content, want := range map[string]string{
+		"operations:\n  GetUser:\n    method: HEAD\n":     `unsupported method "HEAD"`,
+		"operations:\n  GetUser:\n    path: users/{id}\n": "must start with /",
+	} {
+		if _, err := Load(write(content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	def := &models.Definitions{
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}},
+			{Name: "DeleteUser", Input: models.Message{Name: "tns:UserIn"}},
+		}}},
+	}
+
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{"nil", nil, ""},
+		{"valid", &Config{Operations: map[string]Route{"GetUser": {Method: "GET", Path: "/users/{id}"}}}, ""},
+		{"undefined operation", &Config{Operations: map[string]Route{"GetOrder": {Method: "GET"}}}, "undefined operations: GetOrder"},
+		{"unknown path parameter", &Config{Operations: map[string]Route{"GetUser": {Path: "/users/{name}"}}}, "{name} is not an input field"},
+		{"shared route", &Config{Operations: map[string]Route{
+			"GetUser":    {Method: "GET", Path: "/users/{id}"},
+			"DeleteUser": {Method: "GET", Path: "/users/{id}"},
+		}}, "share the route GET /users/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Check(def)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Check() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package sample
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// xsiNamespace is the namespace of the xsi:type and xsi:nil attributes
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// Encoder writes JSON values as XML elements, the way the proxy converts
+// responses to JSON in reverse: objects become child elements, arrays
+// repeated elements, "@name" keys attributes and "#text" keys text, null
+// xsi:nil elements, and objects with an "#element" key are elements of that
+// name, as members of substitution groups. The child elements of objects
+// follow the sequence of their complex type in Def when it is known, and
+// name order otherwise.
+type Encoder struct {
+	Def *models.Definitions
+	// Indent puts child elements on lines of their own, indented by their
+	// depth, for documentation; the proxy sends them unindented
+	Indent string
+}
+
+// Element writes a JSON value of an XSD type as an element with attrs
+func (e Encoder) Element(b *strings.Builder, name, attrs, xsdType string, value interface{}) error {
+	return e.element(b, name, attrs, xsdType, value, 0)
+}
+
+// element writes an element whose children are at depth+1
+func (e Encoder) element(b *strings.Builder, name, attrs, xsdType string, value interface{}, depth int) error {
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			if i > 0 {
+				e.newline(b, depth)
+			}
+			if err := e.element(b, name, attrs, xsdType, item, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if member, ok := v["#element"].(string); ok {
+			if !IsXMLName(member) {
+				return fmt.Errorf("invalid element name %q", member)
+			}
+			name, xsdType = member, member
+			if e.Def != nil && e.Def.Element(member) != nil {
+				xsdType = e.Def.ElementType(member)
+			}
+		}
+		if derived, ok := v["@xsi:type"].(string); ok {
+			xsdType = derived
+		}
+		keys, types := e.fields(xsdType, name, v)
+		var content strings.Builder
+		children := false
+		for _, key := range keys {
+			switch {
+			case key == "#element":
+			case key == "@xsi:type":
+				attrs += e.xsiType(fmt.Sprint(v[key]))
+			case strings.HasPrefix(key, "@"):
+				if !IsXMLName(key[1:]) {
+					return fmt.Errorf("invalid attribute name %q", key)
+				}
+				if v[key] != nil {
+					attrs += fmt.Sprintf(` %s="%s"`, key[1:], Escape(fmt.Sprint(v[key])))
+				}
+			case key == "#text":
+				if v[key] != nil {
+					content.WriteString(Escape(fmt.Sprint(v[key])))
+				}
+			case IsXMLName(key):
+				children = true
+				e.newline(&content, depth+1)
+				if err := e.element(&content, key, "", types[key], v[key], depth+1); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid element name %q", key)
+			}
+		}
+		if children {
+			e.newline(&content, depth)
+		}
+		fmt.Fprintf(b, "<%s%s>%s</%s>", name, attrs, content.String(), name)
+		return nil
+	case nil:
+		// null is a nil element, which nillable elements accept
+		if !strings.Contains(attrs, " xmlns:xsi=") {
+			attrs += ` xmlns:xsi="` + xsiNamespace + `"`
+		}
+		fmt.Fprintf(b, `<%s%s xsi:nil="true"/>`, name, attrs)
+		return nil
+	}
+	fmt.Fprintf(b, "<%s%s>%s</%s>", name, attrs, Escape(fmt.Sprint(value)), name)
+	return nil
+}
+
+// newline starts a line indented by depth, when indenting
+func (e Encoder) newline(b *strings.Builder, depth int) {
+	if e.Indent != "" {
+		b.WriteString("\n" + strings.Repeat(e.Indent, depth))
+	}
+}
+
+// fields returns the keys of obj, an element of an XSD type, in the order
+// of the type's sequence followed by the undeclared ones in name order,
+// and the types of the child elements. Anonymous types are named after
+// their element.
+func (e Encoder) fields(xsdType, name string, obj map[string]interface{}) ([]string, map[string]string) {
+	var declared []models.Element
+	if t := e.complexType(xsdType, name); t != nil {
+		declared = t.Elements
+	}
+	return ordered(declared, obj)
+}
+
+// ordered returns the keys of obj in the order of declared followed by the
+// undeclared ones in name order, and the types of the declared ones
+func ordered(declared []models.Element, obj map[string]interface{}) ([]string, map[string]string) {
+	keys := make([]string, 0, len(obj))
+	types := make(map[string]string, len(declared))
+	for _, elem := range declared {
+		if _, ok := obj[elem.Name]; ok && types[elem.Name] == "" {
+			keys = append(keys, elem.Name)
+			types[elem.Name] = elem.Type
+			if elem.Type == "" {
+				types[elem.Name] = elem.Name
+			}
+		}
+	}
+	var rest []string
+	for key := range obj {
+		if _, ok := types[key]; !ok {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...), types
+}
+
+// complexType returns the complex type of an element, named after the
+// element for anonymous types, or nil
+func (e Encoder) complexType(xsdType, name string) *models.Type {
+	if e.Def == nil {
+		return nil
+	}
+	typeName := models.LocalName(xsdType)
+	if typeName == "" {
+		typeName = name
+	}
+	for i := range e.Def.Types {
+		if e.Def.Types[i].Name == typeName {
+			return &e.Def.Types[i]
+		}
+	}
+	return nil
+}
+
+// xsiType returns the attributes of the xsi:type of a derived type,
+// declaring the namespaces it uses. Types named without a prefix are
+// qualified by the namespace of their schema.
+func (e Encoder) xsiType(typeName string) string {
+	attrs := ` xmlns:xsi="` + xsiNamespace + `"`
+	if strings.Contains(typeName, ":") {
+		return attrs + ` xsi:type="` + Escape(typeName) + `"`
+	}
+	var ns string
+	if t := e.complexType(typeName, ""); t != nil {
+		ns = t.Namespace
+	}
+	if ns == "" && e.Def != nil {
+		ns = e.Def.TargetNamespace
+	}
+	if ns == "" {
+		return attrs + ` xsi:type="` + Escape(typeName) + `"`
+	}
+	return attrs + ` xmlns:xt="` + Escape(ns) + `" xsi:type="xt:` + Escape(typeName) + `"`
+}
+
+// Escape escapes text for element content and attribute values
+func Escape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// IsXMLName reports whether name is an unprefixed XML element name
+func IsXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
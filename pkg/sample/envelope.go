@@ -0,0 +1,243 @@
+package sample
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// encodingNamespace is the default encodingStyle of rpc/encoded bodies
+const encodingNamespace = "http://schemas.xmlsoap.org/soap/encoding/"
+
+// TargetNamespace returns the target namespace of def, that of tempuri.org
+// when it has none
+func TargetNamespace(def *models.Definitions) string {
+	if def.TargetNamespace == "" {
+		return "http://tempuri.org/"
+	}
+	return def.TargetNamespace
+}
+
+// Envelope returns the SOAP envelope of a body, with a header when header
+// holds blocks
+func Envelope(targetNS, soapVersion, header, body string) string {
+	prefix, ns := "soap", "http://schemas.xmlsoap.org/soap/envelope/"
+	if soapVersion == "1.2" {
+		prefix, ns = "soap12", "http://www.w3.org/2003/05/soap-envelope"
+	}
+	if header != "" {
+		header = fmt.Sprintf("\n  <%s:Header>%s</%s:Header>", prefix, header, prefix)
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<%[1]s:Envelope xmlns:%[1]s="%[2]s" xmlns:tns="%[3]s">%[4]s
+  <%[1]s:Body>
+    %[5]s
+  </%[1]s:Body>
+</%[1]s:Envelope>`, prefix, ns, targetNS, header, body)
+}
+
+// Body returns the body element of the input of an operation, or of its
+// output, carrying fields. Document style bodies are the message element;
+// rpc bodies wrap the parts in an element named after the operation, in
+// the soap:body namespace. Fields follow the schema sequence, and "@name"
+// fields are attributes of the body element.
+func (e Encoder) Body(operation string, output bool, soapVersion string, fields map[string]interface{}) (string, error) {
+	targetNS := TargetNamespace(e.Def)
+	wrapper := operation
+	if output {
+		wrapper += "Response"
+	}
+
+	var body *models.BindingMessage
+	if bindOp := bindingOperation(e.Def, operation); bindOp != nil {
+		body = &bindOp.Input
+		if output {
+			body = &bindOp.Output
+		}
+	}
+
+	// rpc bindings may place the wrapper in the soap:body namespace
+	bodyNS := targetNS
+	if body != nil && body.Namespace != "" {
+		bodyNS = body.Namespace
+	}
+
+	// Document style bodies are the message element, whose schema may use
+	// a namespace other than the WSDL target namespace
+	var defaultNS string
+	if elem := MessageElement(e.Def, operation, output); elem != nil && (body == nil || body.Namespace == "") {
+		wrapper = elem.Name
+		if elem.Namespace != "" {
+			bodyNS = elem.Namespace
+		}
+		if e.qualifiedChildren(elem) {
+			defaultNS = fmt.Sprintf(" xmlns=%q", bodyNS)
+		}
+	}
+
+	// rpc/encoded services expect encodingStyle and xsi:type annotations
+	var attrs string
+	var partTypes map[string]string
+	if body != nil && body.Use == "encoded" {
+		encodingStyle := body.EncodingStyle
+		if encodingStyle == "" {
+			encodingStyle = encodingNamespace
+		}
+		envPrefix := "soap"
+		if soapVersion == "1.2" {
+			envPrefix = "soap12"
+		}
+		attrs = fmt.Sprintf(` xmlns:tns=%q %s:encodingStyle=%q xmlns:xsi="%s" xmlns:xsd="http://www.w3.org/2001/XMLSchema"`, bodyNS, envPrefix, encodingStyle, xsiNamespace)
+		partTypes = partTypesOf(Message(e.Def, operation, output))
+	} else if bodyNS != targetNS {
+		attrs = fmt.Sprintf(" xmlns:tns=%q", bodyNS)
+	}
+	attrs += defaultNS
+
+	var content strings.Builder
+	types := FieldTypes(e.Def, operation, output)
+	for _, k := range e.fieldOrder(operation, output, fields) {
+		v := fields[k]
+		var fieldAttrs string
+		switch xsdType, encoded := partTypes[k]; {
+		case strings.HasPrefix(k, "@"):
+			if !IsXMLName(k[1:]) {
+				return "", fmt.Errorf("invalid attribute name %q", k)
+			}
+			attrs += fmt.Sprintf(` %s="%s"`, k[1:], Escape(fmt.Sprint(v)))
+			continue
+		case !IsXMLName(k):
+			return "", fmt.Errorf("invalid element name %q", k)
+		case encoded:
+			fieldAttrs = fmt.Sprintf(` xsi:type="xsd:%s"`, models.LocalName(xsdType))
+		}
+		e.newline(&content, 3)
+		if err := e.element(&content, k, fieldAttrs, types[k], v, 3); err != nil {
+			return "", fmt.Errorf("field %s: %w", k, err)
+		}
+	}
+	if content.Len() > 0 {
+		e.newline(&content, 2)
+	}
+	return fmt.Sprintf("<tns:%s%s>%s</tns:%s>", wrapper, attrs, content.String(), wrapper), nil
+}
+
+// Message returns the input message of an operation, or its output
+// message, nil when def doesn't declare it
+func Message(def *models.Definitions, operation string, output bool) *models.Message {
+	for _, pt := range def.PortTypes {
+		for _, op := range pt.Operations {
+			if op.Name != operation {
+				continue
+			}
+			name := op.Input.Name
+			if output {
+				name = op.Output.Name
+			}
+			for i := range def.Messages {
+				if def.Messages[i].Name == models.LocalName(name) {
+					return &def.Messages[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// MessageElement returns the global element a document style operation
+// sends, or returns as output, nil when the message is not a single
+// element part
+func MessageElement(def *models.Definitions, operation string, output bool) *models.Element {
+	msg := Message(def, operation, output)
+	if msg == nil || len(msg.Parts) != 1 || msg.Parts[0].Element == "" {
+		return nil
+	}
+	name := models.LocalName(msg.Parts[0].Element)
+	for i := range def.Elements {
+		if def.Elements[i].Name == name {
+			return &def.Elements[i]
+		}
+	}
+	return nil
+}
+
+// FieldTypes maps the fields of the input of an operation, or of its
+// output, to their XSD types: the child elements and "@name" attributes of
+// a document style message element, or else the parts of the message
+func FieldTypes(def *models.Definitions, operation string, output bool) map[string]string {
+	elem := MessageElement(def, operation, output)
+	if elem == nil {
+		return partTypesOf(Message(def, operation, output))
+	}
+
+	types := make(map[string]string)
+	if t := (Encoder{Def: def}).complexType(elem.Type, elem.Name); t != nil {
+		for _, child := range t.Elements {
+			types[child.Name] = child.Type
+		}
+		for _, attr := range t.Attributes {
+			types["@"+attr.Name] = attr.Type
+		}
+	}
+	return types
+}
+
+// bindingOperation returns the SOAP binding operation of an operation, or
+// nil
+func bindingOperation(def *models.Definitions, operation string) *models.BindingOperation {
+	for i := range def.Bindings {
+		binding := &def.Bindings[i]
+		for j := range binding.Operations {
+			if binding.Operations[j].Name == operation && binding.HTTPVerb == "" {
+				return &binding.Operations[j]
+			}
+		}
+	}
+	return nil
+}
+
+// partTypesOf maps the parts of a message declared by type to their XSD
+// types, for annotating rpc/encoded parts with xsi:type
+func partTypesOf(msg *models.Message) map[string]string {
+	types := make(map[string]string)
+	if msg != nil {
+		for _, part := range msg.Parts {
+			if part.Type != "" {
+				types[part.Name] = part.Type
+			}
+		}
+	}
+	return types
+}
+
+// fieldOrder returns the keys of fields in the order of the message
+// element's sequence, or of the message parts, as sequences require,
+// followed by the undeclared ones in name order
+func (e Encoder) fieldOrder(operation string, output bool, fields map[string]interface{}) []string {
+	if elem := MessageElement(e.Def, operation, output); elem != nil {
+		keys, _ := e.fields(elem.Type, elem.Name, fields)
+		return keys
+	}
+	var parts []models.Element
+	if msg := Message(e.Def, operation, output); msg != nil {
+		for _, part := range msg.Parts {
+			parts = append(parts, models.Element{Name: part.Name, Type: part.Type})
+		}
+	}
+	keys, _ := ordered(parts, fields)
+	return keys
+}
+
+// qualifiedChildren reports whether the child elements of elem are
+// namespace qualified (elementFormDefault="qualified")
+func (e Encoder) qualifiedChildren(elem *models.Element) bool {
+	if t := e.complexType(elem.Type, elem.Name); t != nil {
+		for _, child := range t.Elements {
+			if child.Namespace != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Package sample provides the sample values of XSD types and the SOAP
+// envelopes carrying JSON values. The exported examples, the mock server,
+// the documentation and the proxy's minimal requests share the values, and
+// the documentation shows envelopes built as the proxy builds the ones it
+// sends, so that they don't drift apart.
+package sample
+
+import "github.com/thdev01/wsdl2api/internal/models"
+
+// Sample values of the built-in XSD types. Decimals are text, which keeps
+// their digits whatever the decimal type of the consumer.
+const (
+	Integer = 42
+	// NegativeInteger is the sample of the types without positive values
+	NegativeInteger = -1
+	Float           = 3.14
+	Decimal         = "19.99"
+	DateTime        = "2024-01-15T10:30:00Z"
+	Date            = "2024-01-15"
+	Time            = "10:30:00"
+	Base64Binary    = "AA=="
+	HexBinary       = "00"
+)
+
+// integerTypes are the XSD integer types
+var integerTypes = map[string]bool{
+	"int": true, "integer": true, "long": true, "short": true, "byte": true,
+	"unsignedInt": true, "unsignedLong": true, "unsignedShort": true, "unsignedByte": true,
+	"positiveInteger": true, "nonNegativeInteger": true, "negativeInteger": true, "nonPositiveInteger": true,
+}
+
+// Builtin returns the sample JSON value of a built-in XSD type: an int64
+// for integer types, a float64 for xs:float and xs:double, a bool for
+// xs:boolean and a string for the others. Strings and unknown types are
+// named after their field, name.
+func Builtin(xsdType, name string) interface{} {
+	switch t := models.LocalName(xsdType); {
+	case t == "negativeInteger" || t == "nonPositiveInteger":
+		return int64(NegativeInteger)
+	case integerTypes[t]:
+		return int64(Integer)
+	case t == "float" || t == "double":
+		return Float
+	case t == "decimal":
+		return Decimal
+	case t == "boolean":
+		return true
+	case t == "dateTime":
+		return DateTime
+	case t == "date":
+		return Date
+	case t == "time":
+		return Time
+	case t == "base64Binary":
+		return Base64Binary
+	case t == "hexBinary":
+		return HexBinary
+	}
+	return name
+}
+
+// Value returns the sample JSON value of a simple type of def: the first
+// value of enumerations, or the value of the built-in type it restricts
+func Value(def *models.Definitions, xsdType, name string) interface{} {
+	typeName := models.LocalName(xsdType)
+	for depth := 0; depth < 10; depth++ {
+		st := simpleType(def, typeName)
+		if st == nil {
+			break
+		}
+		if len(st.Enumeration) > 0 {
+			return st.Enumeration[0]
+		}
+		typeName = models.LocalName(st.Base)
+	}
+	return Builtin(typeName, name)
+}
+
+// simpleType returns the simple type of def named name, or nil
+func simpleType(def *models.Definitions, name string) *models.SimpleType {
+	for i := range def.SimpleTypes {
+		if def.SimpleTypes[i].Name == name {
+			return &def.SimpleTypes[i]
+		}
+	}
+	return nil
+}
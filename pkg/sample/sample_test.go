@@ -0,0 +1,132 @@
+package sample
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestValue(t *testing.T) {
+	def := &models.Definitions{SimpleTypes: []models.SimpleType{
+		{Name: "Status", Base: "xs:string", Enumeration: []string{"open", "closed"}},
+		{Name: "Code", Base: "tns:Quantity"},
+		{Name: "Quantity", Base: "xs:positiveInteger"},
+	}}
+	for _, tt := range []struct {
+		xsdType string
+		want    interface{}
+	}{
+		{"tns:Status", "open"},
+		{"tns:Code", int64(Integer)},
+		{"xs:negativeInteger", int64(NegativeInteger)},
+		{"xs:double", Float},
+		{"xs:decimal", Decimal},
+		{"xs:boolean", true},
+		{"xs:date", Date},
+		{"xs:base64Binary", Base64Binary},
+		{"xs:string", "name"},
+		{"tns:Unknown", "name"},
+	} {
+		if got := Value(def, tt.xsdType, "name"); got != tt.want {
+			t.Errorf("Value(%s) = %#v, want %#v", tt.xsdType, got, tt.want)
+		}
+	}
+}
+
+func TestElement(t *testing.T) {
+	def := &models.Definitions{
+		TargetNamespace: "urn:shapes",
+		Types: []models.Type{
+			{Name: "Shape", Elements: []models.Element{{Name: "name"}, {Name: "color"}}},
+			{Name: "Circle", Namespace: "urn:shapes", Elements: []models.Element{{Name: "name"}, {Name: "color"}, {Name: "radius"}}},
+		},
+		Elements: []models.Element{{Name: "circle", Type: "tns:Circle"}},
+	}
+	enc := Encoder{Def: def}
+
+	for _, tt := range []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"sequence order and escaping", map[string]interface{}{"color": "<red>", "name": "a & b", "@id": `"1"`},
+			`<shape id="&#34;1&#34;"><name>a &amp; b</name><color>&lt;red&gt;</color></shape>`},
+		{"repeated elements", []interface{}{"a", nil},
+			`<shape>a</shape><shape xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>`},
+		{"null attribute and text", map[string]interface{}{"@id": nil, "#text": nil, "name": nil},
+			`<shape><name xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/></shape>`},
+		{"substitution member", map[string]interface{}{"#element": "circle", "radius": 2, "name": "c"},
+			`<circle><name>c</name><radius>2</radius></circle>`},
+		{"derived type", map[string]interface{}{"@xsi:type": "Circle", "radius": 2, "name": "c"},
+			`<shape xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xt="urn:shapes" xsi:type="xt:Circle"><name>c</name><radius>2</radius></shape>`},
+	} {
+		var b strings.Builder
+		if err := enc.Element(&b, "shape", "", "tns:Shape", tt.value); err != nil || b.String() != tt.want {
+			t.Errorf("%s: Element() = %s, %v, want %s", tt.name, b.String(), err, tt.want)
+		}
+	}
+
+	var b strings.Builder
+	if err := enc.Element(&b, "shape", "", "tns:Shape", map[string]interface{}{"bad name": 1}); err == nil {
+		t.Error("Element() accepted an invalid element name")
+	}
+
+	b.Reset()
+	indented := Encoder{Def: def, Indent: "  "}
+	if err := indented.Element(&b, "shape", "", "tns:Shape", map[string]interface{}{"name": "s", "color": "red"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<shape>\n  <name>s</name>\n  <color>red</color>\n</shape>"; b.String() != want {
+		t.Errorf("indented Element() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestBody(t *testing.T) {
+	def := &models.Definitions{
+		TargetNamespace: "urn:calc",
+		Bindings: []models.Binding{{Name: "CalcSoap", Operations: []models.BindingOperation{{
+			Name:   "Add",
+			Input:  models.BindingMessage{Use: "encoded", Namespace: "urn:calc:rpc"},
+			Output: models.BindingMessage{Use: "encoded", Namespace: "urn:calc:rpc"},
+		}}}},
+		PortTypes: []models.PortType{{Name: "Calc", Operations: []models.Operation{
+			{Name: "Add", Input: models.Message{Name: "tns:AddIn"}, Output: models.Message{Name: "tns:AddOut"}},
+		}}},
+		Messages: []models.Message{
+			{Name: "AddIn", Parts: []models.Part{{Name: "b", Type: "xsd:int"}, {Name: "a", Type: "xsd:int"}}},
+			{Name: "AddOut", Parts: []models.Part{{Name: "sum", Type: "xsd:int"}}},
+		},
+	}
+	enc := Encoder{Def: def}
+
+	// rpc/encoded parts follow the message and carry their xsi:type
+	body, err := enc.Body("Add", false, "1.2", map[string]interface{}{"a": 1, "b": 2})
+	want := `<tns:Add xmlns:tns="urn:calc:rpc" soap12:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema">` +
+		`<b xsi:type="xsd:int">2</b><a xsi:type="xsd:int">1</a></tns:Add>`
+	if err != nil || body != want {
+		t.Errorf("Body(Add) = %s, %v, want %s", body, err, want)
+	}
+
+	body, err = enc.Body("Add", true, "1.1", map[string]interface{}{"sum": 3})
+	want = `<tns:AddResponse xmlns:tns="urn:calc:rpc" soap:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema">` +
+		`<sum xsi:type="xsd:int">3</sum></tns:AddResponse>`
+	if err != nil || body != want {
+		t.Errorf("Body(Add output) = %s, %v, want %s", body, err, want)
+	}
+
+	if _, err := enc.Body("Add", false, "", map[string]interface{}{"@bad name": 1}); err == nil {
+		t.Error("Body() accepted an invalid attribute name")
+	}
+
+	want = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:tns="urn:calc">
+  <soap:Header><h/></soap:Header>
+  <soap:Body>
+    <tns:Add/>
+  </soap:Body>
+</soap:Envelope>`
+	if got := Envelope(TargetNamespace(def), "1.1", "<h/>", "<tns:Add/>"); got != want {
+		t.Errorf("Envelope() = %s, want %s", got, want)
+	}
+}
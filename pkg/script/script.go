@@ -0,0 +1,343 @@
+// Package script runs Starlark scripts that rewrite the JSON requests and
+// responses of the proxy by operation, for the field-level shimming
+// migrations need beyond what routes and transforms express.
+//
+// A script defines a request function, a response function or both. Each
+// takes the operation name and the JSON body as a dict, and returns the
+// body to use, or None to keep the body it changed in place:
+//
+//	def request(operation, body):
+//	    body["customerId"] = body.pop("id")
+//	    return body
+//
+//	def response(operation, body):
+//	    body["name"] = body.pop("first") + " " + body.pop("last")
+//
+// The json and math modules of Starlark are predeclared.
+package script
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// AllOperations is the operation name of the script applying to every
+// operation, before the script of the operation itself
+const AllOperations = "*"
+
+// maxSteps bounds the computation of a call, so that a script looping
+// forever fails the request instead of holding it
+const maxSteps = 10_000_000
+
+// Script is the source of a script
+type Script struct {
+	Name   string // File name, for error messages
+	Source []byte
+}
+
+// Hooks holds the compiled scripts by operation name
+type Hooks struct {
+	scripts map[string]*program
+}
+
+// program is a compiled script
+type program struct {
+	name     string
+	request  starlark.Callable
+	response starlark.Callable
+}
+
+// LoadConfig reads the scripts of a YAML config file, as ReadConfig
+// does, and compiles them. It returns nil when the file has no scripts.
+func LoadConfig(path string) (*Hooks, error) {
+	scripts, err := ReadConfig(path)
+	if err != nil || len(scripts) == 0 {
+		return nil, err
+	}
+	return Compile(scripts)
+}
+
+// ReadConfig reads the scripts key of a YAML config file, which maps
+// operation names to script files, such as
+//
+//	scripts:
+//	  GetCustomer: scripts/customer.star
+//	  "*": scripts/common.star
+//
+// and reads the scripts. Relative paths are relative to the working
+// directory. It returns nil when the file has no scripts.
+func ReadConfig(path string) (map[string]Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scripts: %w", err)
+	}
+	var file struct {
+		Scripts map[string]string `yaml:"scripts"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse scripts: %w", err)
+	}
+	return Read(file.Scripts)
+}
+
+// Read reads script files by operation name
+func Read(paths map[string]string) (map[string]Script, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	scripts := make(map[string]Script, len(paths))
+	for operation, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script of %s: %w", operation, err)
+		}
+		scripts[operation] = Script{Name: path, Source: source}
+	}
+	return scripts, nil
+}
+
+// Compile compiles scripts by operation name. Their top-level statements
+// run once, here; the values they define are then frozen, as requests call
+// the functions concurrently.
+func Compile(scripts map[string]Script) (*Hooks, error) {
+	h := &Hooks{scripts: make(map[string]*program, len(scripts))}
+	for operation, s := range scripts {
+		thread := &starlark.Thread{Name: s.Name, Print: print}
+		globals, err := starlark.ExecFileOptions(fileOptions, thread, s.Name, s.Source, predeclared)
+		if err != nil {
+			return nil, fmt.Errorf("script of %s: %w", operation, err)
+		}
+		globals.Freeze()
+		p := &program{name: s.Name}
+		for _, fn := range []struct {
+			name string
+			dst  *starlark.Callable
+		}{{"request", &p.request}, {"response", &p.response}} {
+			value, ok := globals[fn.name]
+			if !ok {
+				continue
+			}
+			callable, ok := value.(starlark.Callable)
+			if !ok {
+				return nil, fmt.Errorf("script of %s: %s is a %s, not a function", operation, fn.name, value.Type())
+			}
+			*fn.dst = callable
+		}
+		if p.request == nil && p.response == nil {
+			return nil, fmt.Errorf("script of %s defines neither request nor response", operation)
+		}
+		h.scripts[operation] = p
+	}
+	return h, nil
+}
+
+// fileOptions allow the statements of Python that Starlark leaves out by
+// default, except recursion
+var fileOptions = &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+
+// predeclared are the modules scripts can use without loading them
+var predeclared = starlark.StringDict{
+	"json": json.Module,
+	"math": math.Module,
+}
+
+// print logs the output of print statements at debug level
+func print(thread *starlark.Thread, msg string) {
+	slog.Debug(msg, "script", thread.Name)
+}
+
+// Check reports scripts of operations that none of the definitions declare
+func (h *Hooks) Check(defs ...*models.Definitions) error {
+	known := make(map[string]bool)
+	for _, def := range defs {
+		for _, pt := range def.PortTypes {
+			for _, op := range pt.Operations {
+				known[op.Name] = true
+			}
+		}
+	}
+	var unknown []string
+	for operation := range h.scripts {
+		if operation != AllOperations && !known[operation] {
+			unknown = append(unknown, operation)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("scripts of unknown operations: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Request runs the request functions of the scripts of an operation on the
+// JSON body of a request
+func (h *Hooks) Request(ctx context.Context, operation string, body map[string]interface{}) (map[string]interface{}, error) {
+	return h.run(ctx, operation, "request", body)
+}
+
+// Response runs the response functions of the scripts of an operation on
+// the JSON body of a response
+func (h *Hooks) Response(ctx context.Context, operation string, body map[string]interface{}) (map[string]interface{}, error) {
+	return h.run(ctx, operation, "response", body)
+}
+
+// run calls the function of the script for every operation, then the one
+// of the script of the operation
+func (h *Hooks) run(ctx context.Context, operation, function string, body map[string]interface{}) (map[string]interface{}, error) {
+	for _, p := range []*program{h.scripts[AllOperations], h.scripts[operation]} {
+		if p == nil {
+			continue
+		}
+		fn := p.request
+		if function == "response" {
+			fn = p.response
+		}
+		if fn == nil {
+			continue
+		}
+		var err error
+		if body, err = p.call(ctx, fn, operation, body); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.name, err)
+		}
+	}
+	return body, nil
+}
+
+// call calls a function of the script with a body and returns the body it
+// returns or changed
+func (p *program) call(ctx context.Context, fn starlark.Callable, operation string, body map[string]interface{}) (map[string]interface{}, error) {
+	thread := &starlark.Thread{Name: p.name, Print: print}
+	thread.SetMaxExecutionSteps(maxSteps)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	arg, err := toStarlark(body)
+	if err != nil {
+		return nil, err
+	}
+	result, err := starlark.Call(thread, fn, starlark.Tuple{starlark.String(operation), arg}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result == starlark.None {
+		result = arg
+	}
+	value, err := fromStarlark(result)
+	if err != nil {
+		return nil, fmt.Errorf("%s returned %w", fn.Name(), err)
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s returned a %s, not a dict", fn.Name(), result.Type())
+	}
+	return obj, nil
+}
+
+// toStarlark converts a JSON value to Starlark
+func toStarlark(value interface{}) (starlark.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dict := starlark.NewDict(len(v))
+		for _, key := range keys {
+			item, err := toStarlark(v[key])
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(key), item); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case []interface{}:
+		items := make([]starlark.Value, len(v))
+		for i, item := range v {
+			var err error
+			if items[i], err = toStarlark(item); err != nil {
+				return nil, err
+			}
+		}
+		return starlark.NewList(items), nil
+	}
+	return nil, fmt.Errorf("unsupported JSON value of type %T", value)
+}
+
+// fromStarlark converts a Starlark value to JSON. Integers too large for
+// int64 become floats.
+func fromStarlark(value starlark.Value) (interface{}, error) {
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		if n, ok := v.Int64(); ok {
+			return n, nil
+		}
+		f, _ := new(big.Float).SetInt(v.BigInt()).Float64()
+		return f, nil
+	case starlark.Float:
+		return float64(v), nil
+	case *starlark.Dict:
+		obj := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("a dict with a %s key", item[0].Type())
+			}
+			var err error
+			if obj[string(key)], err = fromStarlark(item[1]); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	case starlark.Indexable: // Lists and tuples
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			var err error
+			if list[i], err = fromStarlark(v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("a %s, which has no JSON value", value.Type())
+}
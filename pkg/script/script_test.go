@@ -0,0 +1,130 @@
+package script
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "customer.star"), []byte(`
+def request(operation, body):
+    body["customerId"] = body.pop("id")
+`), 0644)
+	path := filepath.Join(dir, "wsdl2api.yaml")
+	os.WriteFile(path, []byte("port: 9090\nscripts:\n  GetCustomer: "+filepath.Join(dir, "customer.star")+"\n"), 0644)
+
+	h, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h == nil || h.scripts["GetCustomer"].request == nil || h.scripts["GetCustomer"].response != nil {
+		t.Errorf("LoadConfig() = %+v", h)
+	}
+
+	os.WriteFile(path, []byte("port: 9090\n"), 0644)
+	if h, err := LoadConfig(path); err != nil || h != nil {
+		t.Errorf("LoadConfig() without scripts = %v, %v", h, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, tt := range []struct {
+		source string
+		want   string
+	}{
+		{"def request(operation, body)\n", "got newline"},
+		{"x = 1\n", "defines neither request nor response"},
+		{"request = 1\n", "request is a int, not a function"},
+		{"def response(operation, body):\n    return undefined\n", "undefined: undefined"},
+	} {
+		_, err := Compile(map[string]Script{"Op": {Name: "op.star", Source: []byte(tt.source)}})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Compile(%q) error = %v, want %s", tt.source, err, tt.want)
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	h, err := Compile(map[string]Script{
+		AllOperations: {Name: "all.star", Source: []byte(`
+def request(operation, body):
+    body["operation"] = operation
+`)},
+		"GetCustomer": {Name: "customer.star", Source: []byte(`
+def request(operation, body):
+    return {"customerId": body["id"], "tags": [t.upper() for t in body["tags"]], "operation": body["operation"]}
+
+def response(operation, body):
+    body["name"] = body.pop("first") + " " + body.pop("last")
+    body["total"] = body["amounts"][0] + body["amounts"][1]
+    body["meta"] = json.decode(body["meta"])
+`)},
+		"Loop": {Name: "loop.star", Source: []byte(`
+def request(operation, body):
+    while True:
+        pass
+`)},
+		"Bad": {Name: "bad.star", Source: []byte(`
+def request(operation, body):
+    return [body]
+`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	got, err := h.Request(ctx, "GetCustomer", map[string]interface{}{"id": 7.0, "tags": []interface{}{"vip"}})
+	want := map[string]interface{}{"customerId": 7.0, "tags": []interface{}{"VIP"}, "operation": "GetCustomer"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Request() = %v, %v, want %v", got, err, want)
+	}
+
+	got, err = h.Response(ctx, "GetCustomer", map[string]interface{}{
+		"first": "Ada", "last": "Lovelace", "amounts": []interface{}{int64(2), int64(3)}, "meta": `{"vip": true}`,
+	})
+	want = map[string]interface{}{
+		"name": "Ada Lovelace", "total": int64(5), "amounts": []interface{}{int64(2), int64(3)}, "meta": map[string]interface{}{"vip": true},
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Response() = %v, %v, want %v", got, err, want)
+	}
+
+	// Operations without a script of their own run the one of every operation
+	if got, err := h.Response(ctx, "Other", map[string]interface{}{"a": nil}); err != nil || !reflect.DeepEqual(got, map[string]interface{}{"a": nil}) {
+		t.Errorf("Response() of Other = %v, %v", got, err)
+	}
+
+	if _, err := h.Request(ctx, "Bad", map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "not a dict") {
+		t.Errorf("Request() of Bad error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := h.Request(ctx, "Loop", map[string]interface{}{}); err == nil {
+		t.Error("Request() of Loop didn't fail")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	h, err := Compile(map[string]Script{
+		"GetCustomer": {Name: "a.star", Source: []byte("def request(operation, body):\n    pass\n")},
+		"GetOrder":    {Name: "b.star", Source: []byte("def request(operation, body):\n    pass\n")},
+		AllOperations: {Name: "c.star", Source: []byte("def request(operation, body):\n    pass\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := &models.Definitions{PortTypes: []models.PortType{{Operations: []models.Operation{{Name: "GetCustomer"}}}}}
+	if err := h.Check(def); err == nil || !strings.Contains(err.Error(), "unknown operations: GetOrder") {
+		t.Errorf("Check() error = %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package security
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlNamespace is the namespace bound to the reserved xml prefix
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// canonicalize returns the Exclusive XML Canonicalization (without
+// comments) of an element. inherited maps the prefixes declared by its
+// ancestors to their namespaces; "" is the default namespace. Declarations
+// are rendered on the outermost element that uses their prefix, so the
+// result doesn't depend on where the element is embedded.
+func canonicalize(element []byte, inherited map[string]string) ([]byte, error) {
+	type frame struct {
+		scope    map[string]string // prefixes in scope
+		rendered map[string]string // prefixes declared by output ancestors
+	}
+
+	stack := []frame{{scope: inherited, rendered: map[string]string{}}}
+	var out bytes.Buffer
+	dec := xml.NewDecoder(bytes.NewReader(element))
+
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to canonicalize: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			parent := stack[len(stack)-1]
+			scope := parent.scope
+			var attrs []xml.Attr
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "xmlns":
+					scope = with(scope, a.Name.Local, a.Value)
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					scope = with(scope, "", a.Value)
+				default:
+					attrs = append(attrs, a)
+				}
+			}
+
+			// Render the namespaces the element and its attributes use
+			// unless an output ancestor already declared them
+			rendered := parent.rendered
+			used := []string{t.Name.Space}
+			for _, a := range attrs {
+				if a.Name.Space != "" {
+					used = append(used, a.Name.Space)
+				}
+			}
+			var decls []string
+			for _, prefix := range used {
+				if prefix == "xml" {
+					continue
+				}
+				uri := scope[prefix]
+				if current, ok := rendered[prefix]; ok && current == uri || !ok && prefix == "" && uri == "" {
+					continue
+				}
+				rendered = with(rendered, prefix, uri)
+				decls = append(decls, prefix)
+			}
+			sort.Strings(decls)
+
+			out.WriteString("<" + qualified(t.Name))
+			for _, prefix := range decls {
+				if prefix == "" {
+					fmt.Fprintf(&out, ` xmlns="%s"`, escapeAttr(rendered[prefix]))
+				} else {
+					fmt.Fprintf(&out, ` xmlns:%s="%s"`, prefix, escapeAttr(rendered[prefix]))
+				}
+			}
+
+			// Attributes are ordered by namespace, then local name
+			namespace := func(a xml.Attr) string {
+				switch a.Name.Space {
+				case "":
+					return ""
+				case "xml":
+					return xmlNamespace
+				}
+				return scope[a.Name.Space]
+			}
+			sort.SliceStable(attrs, func(i, j int) bool {
+				if ni, nj := namespace(attrs[i]), namespace(attrs[j]); ni != nj {
+					return ni < nj
+				}
+				return attrs[i].Name.Local < attrs[j].Name.Local
+			})
+			for _, a := range attrs {
+				fmt.Fprintf(&out, ` %s="%s"`, qualified(a.Name), escapeAttr(a.Value))
+			}
+			out.WriteString(">")
+
+			stack = append(stack, frame{scope: scope, rendered: rendered})
+		case xml.EndElement:
+			out.WriteString("</" + qualified(t.Name) + ">")
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 1 {
+				out.WriteString(escapeText(string(t)))
+			}
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// with returns a copy of m with key set to value
+func with(m map[string]string, key, value string) map[string]string {
+	c := make(map[string]string, len(m)+1)
+	for k, v := range m {
+		c[k] = v
+	}
+	c[key] = value
+	return c
+}
+
+// qualified formats a raw name as prefix:local
+func qualified(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// escapeText escapes character data as canonical XML requires
+var escapeText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;").Replace
+
+// escapeAttr escapes attribute values as canonical XML requires
+var escapeAttr = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;").Replace
@@ -0,0 +1,290 @@
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// NTLM negotiate flags (MS-NLMP 2.2.2.5)
+const (
+	ntlmUnicode          = 0x00000001
+	ntlmRequestTarget    = 0x00000004
+	ntlmNTLM             = 0x00000200
+	ntlmAlwaysSign       = 0x00008000
+	ntlmExtendedSecurity = 0x00080000
+	ntlmTargetInfo       = 0x00800000
+	ntlm128              = 0x20000000
+	ntlm56               = 0x80000000
+
+	ntlmFlags = ntlmUnicode | ntlmRequestTarget | ntlmNTLM | ntlmAlwaysSign |
+		ntlmExtendedSecurity | ntlmTargetInfo | ntlm128 | ntlm56
+)
+
+// ntlmSignature starts every NTLM message
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// msvAvTimestamp is the AV_PAIR id of the server time in the target info
+const msvAvTimestamp = 7
+
+// NTLMTransport authenticates requests with NTLMv2, as IIS and WCF services
+// with Windows authentication require. Each request goes through the
+// negotiate, challenge and authenticate handshake; the body is replayed,
+// so it is buffered unless the request has GetBody.
+type NTLMTransport struct {
+	// Username may be given as DOMAIN\user when Domain is empty
+	Username string
+	Password string
+	Domain   string
+	// Base makes the requests, http.DefaultTransport when nil. It must
+	// keep connections alive, since NTLM authenticates the connection the
+	// handshake runs on.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *NTLMTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	body, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "NTLM"
+	resp, challenge, err := ntlmStep(base, req, body, scheme, ntlmNegotiate())
+	if err == nil && challenge == nil && resp.StatusCode == http.StatusUnauthorized && offers(resp, "Negotiate") {
+		// Servers offering only Negotiate accept NTLM tokens under that name
+		drain(resp)
+		scheme = "Negotiate"
+		resp, challenge, err = ntlmStep(base, req, body, scheme, ntlmNegotiate())
+	}
+	if err != nil || challenge == nil {
+		return resp, err
+	}
+	drain(resp)
+
+	username, domain := t.Username, t.Domain
+	if i := strings.Index(username, `\`); i != -1 && domain == "" {
+		domain, username = username[:i], username[i+1:]
+	}
+	authenticate, err := ntlmAuthenticate(challenge, username, t.Password, domain, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	resp, _, err = ntlmStep(base, req, body, scheme, authenticate)
+	return resp, err
+}
+
+// ntlmStep sends req with an NTLM message and returns the response with the
+// server's challenge message, if it sent one
+func ntlmStep(base http.RoundTripper, req *http.Request, body func() (io.ReadCloser, error), scheme string, message []byte) (*http.Response, []byte, error) {
+	r := req.Clone(req.Context())
+	if body != nil {
+		b, err := body()
+		if err != nil {
+			return nil, nil, err
+		}
+		r.Body = b
+	}
+	r.Header.Set("Authorization", scheme+" "+base64.StdEncoding.EncodeToString(message))
+
+	resp, err := base.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil, err
+	}
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		if token, ok := strings.CutPrefix(value, scheme+" "); ok {
+			challenge, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token))
+			if err != nil {
+				drain(resp)
+				return nil, nil, fmt.Errorf("invalid NTLM challenge: %w", err)
+			}
+			return resp, challenge, nil
+		}
+	}
+	return resp, nil, nil
+}
+
+// replayableBody returns a function opening the body of req anew for each
+// request of the handshake, or nil when req has no body
+func replayableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		req.Body.Close()
+		return req.GetBody, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}, nil
+}
+
+// offers reports whether a 401 response offers the authentication scheme
+func offers(resp *http.Response, scheme string) bool {
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		if fields := strings.Fields(value); len(fields) > 0 && strings.EqualFold(fields[0], scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// drain reads and closes a response body so its connection is reused for
+// the next step of the handshake
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+}
+
+// ntlmNegotiate returns the NEGOTIATE_MESSAGE starting a handshake
+func ntlmNegotiate() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmFlags)
+	return msg
+}
+
+// ntlmAuthenticate answers a CHALLENGE_MESSAGE with an AUTHENTICATE_MESSAGE
+// carrying the NTLMv2 and LMv2 responses
+func ntlmAuthenticate(challenge []byte, username, password, domain string, now time.Time) ([]byte, error) {
+	if len(challenge) < 48 || !bytes.Equal(challenge[:8], ntlmSignature) || binary.LittleEndian.Uint32(challenge[8:]) != 2 {
+		return nil, errors.New("invalid NTLM challenge message")
+	}
+	flags := binary.LittleEndian.Uint32(challenge[20:])
+	serverChallenge := challenge[24:32]
+	targetInfo, err := securityBuffer(challenge, 40)
+	if err != nil {
+		return nil, err
+	}
+	if flags&ntlmUnicode == 0 {
+		return nil, errors.New("NTLM server doesn't support Unicode")
+	}
+
+	// The server's clock is preferred for the response timestamp
+	timestamp := make([]byte, 8)
+	binary.LittleEndian.PutUint64(timestamp, fileTime(now))
+	if ts := avPair(targetInfo, msvAvTimestamp); len(ts) == 8 {
+		copy(timestamp, ts)
+	}
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+
+	key := ntowfv2(username, password, domain)
+	nt := ntlmv2Response(key, serverChallenge, clientChallenge, timestamp, targetInfo)
+	lm := lmv2Response(key, serverChallenge, clientChallenge)
+
+	// Header with six security buffers and the flags, then the payload
+	fields := [][]byte{lm, nt, utf16le(domain), utf16le(username), nil, nil}
+	msg := make([]byte, 64)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	for i, field := range fields {
+		at := 12 + 8*i
+		binary.LittleEndian.PutUint16(msg[at:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[at+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[at+4:], uint32(len(msg)))
+		msg = append(msg, field...)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], flags&ntlmFlags)
+	return msg, nil
+}
+
+// ntowfv2 returns the NTLMv2 key of a user: HMAC-MD5 keyed with the MD4
+// hash of the password over the upper-case user name and the domain
+func ntowfv2(username, password, domain string) []byte {
+	h := md4.New()
+	h.Write(utf16le(password))
+	return hmacMD5(h.Sum(nil), utf16le(strings.ToUpper(username)+domain))
+}
+
+// ntlmv2Response returns the NTProofStr followed by the client blob it
+// proves
+func ntlmv2Response(key, serverChallenge, clientChallenge, timestamp, targetInfo []byte) []byte {
+	blob := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	blob = append(blob, timestamp...)
+	blob = append(blob, clientChallenge...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, targetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+	proof := hmacMD5(key, append(append([]byte{}, serverChallenge...), blob...))
+	return append(proof, blob...)
+}
+
+// lmv2Response returns the LMv2 response to the server challenge
+func lmv2Response(key, serverChallenge, clientChallenge []byte) []byte {
+	proof := hmacMD5(key, append(append([]byte{}, serverChallenge...), clientChallenge...))
+	return append(proof, clientChallenge...)
+}
+
+// securityBuffer returns the payload that the security buffer field (length,
+// allocated length and offset) at offset at of an NTLM message points to
+func securityBuffer(msg []byte, at int) ([]byte, error) {
+	length := int(binary.LittleEndian.Uint16(msg[at:]))
+	offset := int(binary.LittleEndian.Uint32(msg[at+4:]))
+	if offset > len(msg) || length > len(msg)-offset {
+		return nil, errors.New("invalid NTLM challenge message")
+	}
+	return msg[offset : offset+length], nil
+}
+
+// avPair returns the value of an AV_PAIR of the target info, or nil
+func avPair(targetInfo []byte, id uint16) []byte {
+	for len(targetInfo) >= 4 {
+		avID := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if avID == 0 || len(targetInfo) < 4+length {
+			return nil
+		}
+		if avID == id {
+			return targetInfo[4 : 4+length]
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil
+}
+
+// fileTime returns t as a Windows FILETIME: 100ns intervals since 1601
+func fileTime(t time.Time) uint64 {
+	return uint64(t.UnixNano()/100) + 116444736000000000
+}
+
+// utf16le encodes s as UTF-16 little endian
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
+
+// hmacMD5 returns the HMAC-MD5 of data
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MS-NLMP 4.2.4 NTLMv2 authentication test values
+var (
+	nlmpServerChallenge = []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	nlmpClientChallenge = bytes.Repeat([]byte{0xaa}, 8)
+	nlmpTargetInfo, _   = hex.DecodeString("02000c0044006f006d00610069006e0001000c0053006500720076006500720000000000")
+)
+
+func TestNTLMv2(t *testing.T) {
+	key := ntowfv2("User", "Password", "Domain")
+	if got := hex.EncodeToString(key); got != "0c868a403bfd7a93a3001ef22ef02e3f" {
+		t.Errorf("ntowfv2() = %s", got)
+	}
+	if got := hex.EncodeToString(lmv2Response(key, nlmpServerChallenge, nlmpClientChallenge)); got != "86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa" {
+		t.Errorf("lmv2Response() = %s", got)
+	}
+	nt := ntlmv2Response(key, nlmpServerChallenge, nlmpClientChallenge, make([]byte, 8), nlmpTargetInfo)
+	if got := hex.EncodeToString(nt[:16]); got != "68cd0ab851e51c96aabc927bebef6a1c" {
+		t.Errorf("ntlmv2Response() proof = %s", got)
+	}
+}
+
+func TestNTLMTransport(t *testing.T) {
+	var steps []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "<Envelope/>" {
+			t.Errorf("request body = %q", body)
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "NTLM ")
+		msg, _ := base64.StdEncoding.DecodeString(token)
+		if !ok || len(msg) < 12 {
+			steps = append(steps, "anonymous")
+			w.Header().Set("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch binary.LittleEndian.Uint32(msg[8:]) {
+		case 1:
+			steps = append(steps, "negotiate")
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(nlmpChallenge()))
+			w.WriteHeader(http.StatusUnauthorized)
+		case 3:
+			steps = append(steps, "authenticate")
+			user, _ := securityBuffer(msg, 36)
+			domain, _ := securityBuffer(msg, 28)
+			nt, _ := securityBuffer(msg, 20)
+			key := ntowfv2("User", "Password", "Domain")
+			proof := hmacMD5(key, append(append([]byte{}, nlmpServerChallenge...), nt[16:]...))
+			if !bytes.Equal(user, utf16le("User")) || !bytes.Equal(domain, utf16le("Domain")) || !bytes.Equal(proof, nt[:16]) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer backend.Close()
+
+	client := &http.Client{Transport: &NTLMTransport{Username: `Domain\User`, Password: "Password"}}
+	resp, err := client.Post(backend.URL, "text/xml", strings.NewReader("<Envelope/>"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if got := strings.Join(steps, ", "); got != "negotiate, authenticate" {
+		t.Errorf("handshake = %s", got)
+	}
+
+	if _, err := ntlmAuthenticate([]byte("NTLMSSP\x00"), "User", "Password", "", time.Now()); err == nil {
+		t.Error("ntlmAuthenticate() accepted a truncated challenge")
+	}
+}
+
+// nlmpChallenge returns a CHALLENGE_MESSAGE with the MS-NLMP test values
+func nlmpChallenge() []byte {
+	msg := make([]byte, 48)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], ntlmFlags)
+	copy(msg[24:], nlmpServerChallenge)
+	binary.LittleEndian.PutUint16(msg[40:], uint16(len(nlmpTargetInfo)))
+	binary.LittleEndian.PutUint16(msg[42:], uint16(len(nlmpTargetInfo)))
+	binary.LittleEndian.PutUint32(msg[44:], 48)
+	return append(msg, nlmpTargetInfo...)
+}
@@ -0,0 +1,170 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryLeeway is how long before its expiry a cached token is replaced, so
+// it doesn't expire while a call is in flight
+const expiryLeeway = 30 * time.Second
+
+// OAuth2Config configures the OAuth2 client credentials flow for services
+// that take a bearer token instead of, or next to, WS-Security
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// CredentialsInBody sends the client ID and secret as form values
+	// instead of HTTP Basic authentication, for providers that require it
+	CredentialsInBody bool
+}
+
+// TokenSource acquires access tokens with the client credentials flow and
+// caches them until shortly before they expire. Expired tokens are renewed
+// with the refresh token when the provider issued one. It is safe for
+// concurrent use.
+type TokenSource struct {
+	config OAuth2Config
+	client *http.Client
+	now    func() time.Time
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiry       time.Time // zero when the token doesn't expire
+}
+
+// tokenResponse is the JSON body of a token endpoint response
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewTokenSource creates a token source for config. Tokens are requested
+// with client, or a client with a 30 second timeout when it is nil.
+func NewTokenSource(config OAuth2Config, client *http.Client) (*TokenSource, error) {
+	if config.TokenURL == "" || config.ClientID == "" {
+		return nil, errors.New("OAuth2 needs a token URL and a client ID")
+	}
+	if u, err := url.Parse(config.TokenURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid OAuth2 token URL %q", config.TokenURL)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &TokenSource{config: config, client: client, now: time.Now}, nil
+}
+
+// Token returns a valid access token, requesting a new one when none is
+// cached or the cached one is about to expire
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && (s.expiry.IsZero() || s.now().Before(s.expiry.Add(-expiryLeeway))) {
+		return s.accessToken, nil
+	}
+
+	if s.refreshToken != "" {
+		err := s.fetch(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {s.refreshToken},
+		})
+		if err == nil {
+			return s.accessToken, nil
+		}
+		// The refresh token may be expired or revoked; start over
+		s.refreshToken = ""
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if err := s.fetch(ctx, form); err != nil {
+		return "", err
+	}
+	return s.accessToken, nil
+}
+
+// Invalidate drops token from the cache after the service rejected it with
+// 401 Unauthorized, so the next call requests a new one. A token cached in
+// the meantime by another call is kept.
+func (s *TokenSource) Invalidate(token string) {
+	s.mu.Lock()
+	if s.accessToken == token {
+		s.accessToken = ""
+	}
+	s.mu.Unlock()
+}
+
+// fetch requests a token from the token endpoint and caches it. The caller
+// holds s.mu.
+func (s *TokenSource) fetch(ctx context.Context, form url.Values) error {
+	if s.config.CredentialsInBody {
+		form.Set("client_id", s.config.ClientID)
+		form.Set("client_secret", s.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.config.CredentialsInBody {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request OAuth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read OAuth2 token: %w", err)
+	}
+
+	var token tokenResponse
+	jsonErr := json.Unmarshal(body, &token)
+	switch {
+	case token.Error != "" && token.ErrorDescription != "":
+		return fmt.Errorf("OAuth2 token request failed: %s: %s", token.Error, token.ErrorDescription)
+	case token.Error != "":
+		return fmt.Errorf("OAuth2 token request failed: %s", token.Error)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("OAuth2 token request failed: HTTP %d", resp.StatusCode)
+	case jsonErr != nil:
+		return fmt.Errorf("failed to parse OAuth2 token: %w", jsonErr)
+	case token.AccessToken == "":
+		return errors.New("OAuth2 token response has no access_token")
+	case token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer"):
+		return fmt.Errorf("unsupported OAuth2 token type %q", token.TokenType)
+	}
+
+	s.accessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	s.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		s.expiry = s.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenSource(t *testing.T) {
+	var grants []string
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if id, secret, _ := r.BasicAuth(); id != "client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+			return
+		}
+		grant := r.Form.Get("grant_type")
+		grants = append(grants, grant+" "+r.Form.Get("scope")+r.Form.Get("refresh_token"))
+		switch grant {
+		case "client_credentials":
+			w.Write([]byte(`{"access_token":"access-1","token_type":"bearer","expires_in":300,"refresh_token":"refresh-1"}`))
+		case "refresh_token":
+			w.Write([]byte(`{"access_token":"access-2","token_type":"Bearer","expires_in":300}`))
+		}
+	}))
+	defer idp.Close()
+
+	source, err := NewTokenSource(OAuth2Config{TokenURL: idp.URL, ClientID: "client", ClientSecret: "s3cret", Scopes: []string{"soap.read", "soap.write"}}, nil)
+	if err != nil {
+		t.Fatalf("NewTokenSource() error = %v", err)
+	}
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	token := func(want string) {
+		t.Helper()
+		if got, err := source.Token(context.Background()); err != nil || got != want {
+			t.Fatalf("Token() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	token("access-1")
+	token("access-1")
+	now = now.Add(271 * time.Second) // within the leeway of the expiry
+	token("access-2")
+	source.Invalidate("access-1")
+	token("access-2")
+	source.Invalidate("access-2")
+	token("access-2")
+
+	want := []string{"client_credentials soap.read soap.write", "refresh_token refresh-1", "refresh_token refresh-1"}
+	if strings.Join(grants, ", ") != strings.Join(want, ", ") {
+		t.Errorf("grants = %q, want %q", grants, want)
+	}
+
+	source, _ = NewTokenSource(OAuth2Config{TokenURL: idp.URL, ClientID: "client", ClientSecret: "wrong"}, nil)
+	if _, err := source.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid_client: bad credentials") {
+		t.Errorf("Token() error = %v, want invalid_client", err)
+	}
+
+	if _, err := NewTokenSource(OAuth2Config{TokenURL: "idp.example.com/token", ClientID: "client"}, nil); err == nil {
+		t.Error("NewTokenSource() accepted a token URL without a scheme")
+	}
+}
@@ -0,0 +1,77 @@
+package security
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// Redacted replaces the content of the elements Redact hides
+const Redacted = "***"
+
+// secretElements are the local names of elements holding credentials:
+// UsernameToken passwords, binary tokens such as Kerberos tickets, and
+// SAML assertions, which are bearer tokens
+var secretElements = map[string]bool{
+	"Password":            true,
+	"BinarySecurityToken": true,
+	"Assertion":           true,
+}
+
+// Redact returns a SOAP envelope with the content of the elements holding
+// credentials replaced with Redacted, for logging it: WS-Security
+// passwords and tokens, SAML assertions, and elements whose names contain
+// "password" or "secret" in any case. The rest of the envelope is left as
+// it is. Content after malformed XML is left out.
+func Redact(envelope []byte) []byte {
+	d := xml.NewDecoder(bytes.NewReader(envelope))
+	var b bytes.Buffer
+	var last int64
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || !isSecret(start.Name.Local) {
+			continue
+		}
+
+		from, to := d.InputOffset(), int64(-1)
+		for depth := 1; depth > 0; {
+			offset := d.InputOffset()
+			tok, err := d.RawToken()
+			if err != nil {
+				b.Write(envelope[last:from])
+				b.WriteString(Redacted)
+				return b.Bytes()
+			}
+			switch tok.(type) {
+			case xml.StartElement:
+				depth++
+			case xml.EndElement:
+				depth--
+				to = offset
+			}
+		}
+		if to > from {
+			// Not an empty element
+			b.Write(envelope[last:from])
+			b.WriteString(Redacted)
+			last = to
+		}
+	}
+	if d.InputOffset() < int64(len(envelope)) {
+		// Malformed XML
+		b.Write(envelope[last:d.InputOffset()])
+		return b.Bytes()
+	}
+	b.Write(envelope[last:])
+	return b.Bytes()
+}
+
+// isSecret reports whether an element holds credentials
+func isSecret(name string) bool {
+	lower := strings.ToLower(name)
+	return secretElements[name] || strings.Contains(lower, "password") || strings.Contains(lower, "secret")
+}
@@ -0,0 +1,46 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name, envelope, want string
+	}{
+		{
+			name: "username token",
+			envelope: `<s:Envelope xmlns:s="urn:s"><s:Header><wsse:Security xmlns:wsse="urn:wsse"><wsse:UsernameToken>` +
+				`<wsse:Username>ada</wsse:Username><wsse:Password Type="#PasswordText">s3cr&amp;t</wsse:Password>` +
+				`</wsse:UsernameToken></wsse:Security></s:Header><s:Body><Add><a>1</a></Add></s:Body></s:Envelope>`,
+			want: `<s:Envelope xmlns:s="urn:s"><s:Header><wsse:Security xmlns:wsse="urn:wsse"><wsse:UsernameToken>` +
+				`<wsse:Username>ada</wsse:Username><wsse:Password Type="#PasswordText">***</wsse:Password>` +
+				`</wsse:UsernameToken></wsse:Security></s:Header><s:Body><Add><a>1</a></Add></s:Body></s:Envelope>`,
+		},
+		{
+			name:     "nested assertion and body fields",
+			envelope: `<Envelope><saml:Assertion ID="a"><saml:Subject>ada</saml:Subject></saml:Assertion><Body><Login><newPassword>x</newPassword><ClientSecret/></Login></Body></Envelope>`,
+			want:     `<Envelope><saml:Assertion ID="a">***</saml:Assertion><Body><Login><newPassword>***</newPassword><ClientSecret/></Login></Body></Envelope>`,
+		},
+		{
+			name:     "nothing to redact",
+			envelope: `<?xml version="1.0"?>` + "\n<Envelope><Body>ok</Body></Envelope>",
+			want:     `<?xml version="1.0"?>` + "\n<Envelope><Body>ok</Body></Envelope>",
+		},
+		{
+			name:     "truncated",
+			envelope: `<Envelope><Password>secret`,
+			want:     `<Envelope><Password>***`,
+		},
+	}
+	for _, tt := range tests {
+		if got := string(Redact([]byte(tt.envelope))); got != tt.want {
+			t.Errorf("%s: Redact() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if got := string(Redact([]byte(`<a><b>ok</c><Password>secret</Password></a>`))); strings.Contains(got, "secret") {
+		t.Errorf("Redact() of malformed XML = %s, leaking the password", got)
+	}
+}
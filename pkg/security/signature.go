@@ -0,0 +1,327 @@
+package security
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Namespaces and algorithm URIs of WS-Security X.509 signatures
+const (
+	wsseNamespace = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	wsuNamespace  = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	dsNamespace   = "http://www.w3.org/2000/09/xmldsig#"
+
+	base64Encoding = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+	x509ValueType  = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-x509-token-profile-1.0#X509v3"
+
+	excC14N     = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	sha256URI   = "http://www.w3.org/2001/04/xmlenc#sha256"
+	rsaSHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	ecdsaSHA256 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+)
+
+// X509Signer signs SOAP envelopes with an X.509 certificate. The signed
+// envelope carries the certificate in a wsse:BinarySecurityToken and an XML
+// signature over the Body, the Timestamp and the UsernameToken when there
+// is one (a signed supporting token), all in exclusive canonical form.
+type X509Signer struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer // RSA or ECDSA private key of the certificate
+	// TTL is how long the Timestamp added to unsigned envelopes is valid,
+	// 5 minutes by default
+	TTL time.Duration
+}
+
+// LoadX509Signer loads a signer from PEM certificate and key files
+func LoadX509Signer(certFile, keyFile string) (*X509Signer, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signing key can't sign")
+	}
+	return &X509Signer{Certificate: cert, Key: key}, nil
+}
+
+// signedPart is an element of the envelope covered by the signature
+type signedPart struct {
+	start, end int               // offsets of the element in the envelope
+	idEnd      int               // offset to insert a wsu:Id, -1 when it has one
+	wsuInScope bool              // the wsu prefix is bound on the element
+	id         string            // value of its wsu:Id
+	scope      map[string]string // namespaces declared by its ancestors
+}
+
+// envelopeLayout holds the offsets of the elements Sign edits
+type envelopeLayout struct {
+	envPrefix   string
+	body        *signedPart
+	timestamp   *signedPart
+	username    *signedPart
+	headerStart int // offset after the Header start tag, -1 without Header
+	secStart    int // offset after the Security start tag, -1 without Security
+	secEnd      int // offset of the Security end tag
+}
+
+// Sign signs a SOAP 1.1 or 1.2 envelope. The signature is added to the
+// wsse:Security header, which is created along with a Timestamp when the
+// envelope has none.
+func (s *X509Signer) Sign(envelope []byte) ([]byte, error) {
+	if s.Certificate == nil || s.Key == nil {
+		return nil, errors.New("signer needs a certificate and a key")
+	}
+	signatureMethod := rsaSHA256
+	if _, ok := s.Key.Public().(*ecdsa.PublicKey); ok {
+		signatureMethod = ecdsaSHA256
+	}
+
+	// Give the signed elements ids and add the missing header elements.
+	// Edits go from the end of the envelope so offsets stay valid.
+	layout, err := scanEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if layout.body == nil {
+		return nil, errors.New("envelope has no Body")
+	}
+
+	tokenID := newID("X509")
+	var edits []edit
+	for _, part := range []*signedPart{layout.body, layout.timestamp, layout.username} {
+		if part != nil && part.idEnd >= 0 {
+			part.id = newID("id")
+			attr := fmt.Sprintf(` wsu:Id="%s"`, part.id)
+			if !part.wsuInScope {
+				attr = fmt.Sprintf(` xmlns:wsu="%s"`, wsuNamespace) + attr
+			}
+			edits = append(edits, edit{part.idEnd, attr})
+		}
+	}
+
+	// Inserted elements declare the prefixes they use, so they can go into
+	// a Security header using other prefixes
+	placeholder := "<!--" + newID("signature") + "-->"
+	content := fmt.Sprintf(`<wsse:BinarySecurityToken xmlns:wsse="%s" xmlns:wsu="%s" EncodingType="%s" ValueType="%s" wsu:Id="%s">%s</wsse:BinarySecurityToken>`,
+		wsseNamespace, wsuNamespace, base64Encoding, x509ValueType, tokenID, base64.StdEncoding.EncodeToString(s.Certificate.Raw)) + placeholder
+	timestamp := ""
+	if layout.timestamp == nil {
+		ttl := s.TTL
+		if ttl <= 0 {
+			ttl = 5 * time.Minute
+		}
+		now := time.Now().UTC()
+		timestamp = fmt.Sprintf(`<wsu:Timestamp xmlns:wsu="%s" wsu:Id="%s"><wsu:Created>%s</wsu:Created><wsu:Expires>%s</wsu:Expires></wsu:Timestamp>`,
+			wsuNamespace, newID("TS"), now.Format(time.RFC3339), now.Add(ttl).Format(time.RFC3339))
+	}
+
+	switch {
+	case layout.secStart >= 0:
+		edits = append(edits, edit{layout.secEnd, content}, edit{layout.secStart, timestamp})
+	case layout.headerStart >= 0:
+		edits = append(edits, edit{layout.headerStart, securityElement(layout.envPrefix, timestamp+content)})
+	default:
+		header := fmt.Sprintf("<%[1]s:Header>%[2]s</%[1]s:Header>", layout.envPrefix, securityElement(layout.envPrefix, timestamp+content))
+		edits = append(edits, edit{layout.body.start, header})
+	}
+	doc := applyEdits(envelope, edits)
+
+	// Digest the signed elements as they appear in the edited envelope
+	layout, err = scanEnvelope(doc)
+	if err != nil {
+		return nil, err
+	}
+	var refs strings.Builder
+	for _, part := range []*signedPart{layout.body, layout.timestamp, layout.username} {
+		if part == nil {
+			continue
+		}
+		c14n, err := canonicalize(doc[part.start:part.end], part.scope)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(c14n)
+		fmt.Fprintf(&refs, `<ds:Reference URI="#%s"><ds:Transforms><ds:Transform Algorithm="%s"/></ds:Transforms><ds:DigestMethod Algorithm="%s"/><ds:DigestValue>%s</ds:DigestValue></ds:Reference>`,
+			part.id, excC14N, sha256URI, base64.StdEncoding.EncodeToString(digest[:]))
+	}
+
+	signedInfo := fmt.Sprintf(`<ds:SignedInfo><ds:CanonicalizationMethod Algorithm="%s"/><ds:SignatureMethod Algorithm="%s"/>%s</ds:SignedInfo>`,
+		excC14N, signatureMethod, refs.String())
+	c14n, err := canonicalize([]byte(signedInfo), map[string]string{"ds": dsNamespace})
+	if err != nil {
+		return nil, err
+	}
+	signatureValue, err := s.sign(c14n)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := fmt.Sprintf(`<ds:Signature xmlns:ds="%s" xmlns:wsse="%s">%s<ds:SignatureValue>%s</ds:SignatureValue><ds:KeyInfo><wsse:SecurityTokenReference><wsse:Reference URI="#%s" ValueType="%s"/></wsse:SecurityTokenReference></ds:KeyInfo></ds:Signature>`,
+		dsNamespace, wsseNamespace, signedInfo, base64.StdEncoding.EncodeToString(signatureValue), tokenID, x509ValueType)
+	return bytes.Replace(doc, []byte(placeholder), []byte(signature), 1), nil
+}
+
+// securityElement wraps content in a wsse:Security header the recipient
+// must understand
+func securityElement(envPrefix, content string) string {
+	return fmt.Sprintf(`<wsse:Security xmlns:wsse="%s" %s:mustUnderstand="1">%s</wsse:Security>`, wsseNamespace, envPrefix, content)
+}
+
+// sign signs canonical SignedInfo with SHA-256. ECDSA signatures are the
+// concatenated r and s values XML signatures use, not ASN.1.
+func (s *X509Signer) sign(signedInfo []byte) ([]byte, error) {
+	digest := sha256.Sum256(signedInfo)
+	sig, err := s.Key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	pub, ok := s.Key.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return sig, nil
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: %w", err)
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	out := make([]byte, 2*size)
+	rs.R.FillBytes(out[:size])
+	rs.S.FillBytes(out[size:])
+	return out, nil
+}
+
+// edit inserts text at an offset of a document
+type edit struct {
+	offset int
+	text   string
+}
+
+// applyEdits returns doc with the insertions made
+func applyEdits(doc []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	out := append([]byte(nil), doc...)
+	for _, e := range edits {
+		out = append(out[:e.offset], append([]byte(e.text), out[e.offset:]...)...)
+	}
+	return out
+}
+
+// scanEnvelope finds the elements of a SOAP envelope Sign edits and signs
+func scanEnvelope(doc []byte) (*envelopeLayout, error) {
+	layout := &envelopeLayout{headerStart: -1, secStart: -1}
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+
+	type open struct {
+		name  xml.Name // resolved namespace and local name
+		scope map[string]string
+		part  *signedPart
+	}
+	stack := []open{{scope: map[string]string{"xml": xmlNamespace}}}
+
+	for {
+		start := int(dec.InputOffset())
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse envelope: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			parent := stack[len(stack)-1]
+			scope := parent.scope
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					scope = with(scope, a.Name.Local, a.Value)
+				} else if a.Name.Space == "" && a.Name.Local == "xmlns" {
+					scope = with(scope, "", a.Value)
+				}
+			}
+			name := xml.Name{Space: scope[t.Name.Space], Local: t.Name.Local}
+			end := int(dec.InputOffset())
+			selfClosing := bytes.HasSuffix(doc[start:end], []byte("/>"))
+
+			var part *signedPart
+			depth := len(stack) - 1
+			switch {
+			case depth == 0 && name.Local == "Envelope":
+				layout.envPrefix = t.Name.Space
+			case depth == 1 && name.Local == "Header":
+				layout.headerStart = end
+			case depth == 1 && name.Local == "Body":
+				layout.body = &signedPart{}
+				part = layout.body
+			case depth == 2 && name.Space == wsseNamespace && name.Local == "Security" && layout.secStart < 0:
+				layout.secStart = end
+			case depth == 3 && name.Space == wsuNamespace && name.Local == "Timestamp" && layout.secStart >= 0 && layout.timestamp == nil:
+				layout.timestamp = &signedPart{}
+				part = layout.timestamp
+			case depth == 3 && name.Space == wsseNamespace && name.Local == "UsernameToken" && layout.secStart >= 0 && layout.username == nil:
+				layout.username = &signedPart{}
+				part = layout.username
+			}
+			if part != nil {
+				part.start = start
+				part.scope = parent.scope
+				part.wsuInScope = scope["wsu"] == wsuNamespace
+				part.idEnd = end - 1
+				if selfClosing {
+					part.idEnd = end - 2
+				}
+				for _, a := range t.Attr {
+					if a.Name.Local == "Id" && scope[a.Name.Space] == wsuNamespace {
+						part.id = a.Value
+						part.idEnd = -1
+					}
+				}
+			}
+			// Self-closing elements are followed by an EndElement too
+			stack = append(stack, open{name: name, scope: scope, part: part})
+		case xml.EndElement:
+			closed := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if closed.part != nil {
+				closed.part.end = int(dec.InputOffset())
+			}
+			if len(stack) == 3 && closed.name.Space == wsseNamespace && closed.name.Local == "Security" && layout.secEnd == 0 {
+				layout.secEnd = start
+			}
+		}
+	}
+
+	if layout.envPrefix == "" {
+		return nil, errors.New("envelope element must use a namespace prefix")
+	}
+	return layout, nil
+}
+
+// newID returns a random wsu:Id value with a prefix
+func newID(prefix string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // crypto/rand.Read always succeeds or panics
+	return prefix + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,160 @@
+package security
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalize(t *testing.T) {
+	element := `<soap:Body  b="2"   a:x="1" xmlns:wsu="urn:wsu" wsu:Id="b1">
+  <Add xmlns="http://tempuri.org/" xmlns:unused="urn:unused"><intA>1 &amp; &lt;2&gt;</intA><x:y xmlns:x="urn:x" x:z="q&quot;&#9;" z="a"/><e/><a:n>t</a:n><inner xmlns=""><d/></inner></Add>
+</soap:Body>`
+	inherited := map[string]string{"soap": "http://schemas.xmlsoap.org/soap/envelope/", "a": "urn:a", "": "urn:default"}
+
+	want := `<soap:Body xmlns:a="urn:a" xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:wsu="urn:wsu" b="2" a:x="1" wsu:Id="b1">
+  <Add xmlns="http://tempuri.org/"><intA>1 &amp; &lt;2&gt;</intA><x:y xmlns:x="urn:x" z="a" x:z="q&quot;&#x9;"></x:y><e></e><a:n>t</a:n><inner xmlns=""><d></d></inner></Add>
+</soap:Body>`
+
+	got, err := canonicalize([]byte(element), inherited)
+	if err != nil {
+		t.Fatalf("canonicalize() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("canonicalize() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestX509SignerSign(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := xml.Marshal(NewSecurityHeader(&WSSecurity{Username: "alice", Password: "secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `<soap:Body><Add xmlns="http://tempuri.org/"><intA>1</intA><intB>2</intB></Add></soap:Body>`
+	envelopes := map[string]string{
+		"no header":      `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">` + body + `</soap:Envelope>`,
+		"empty header":   `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Header></soap:Header>` + body + `</soap:Envelope>`,
+		"username token": `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Header>` + string(header) + `</soap:Header>` + body + `</soap:Envelope>`,
+	}
+
+	for _, key := range []crypto.Signer{rsaKey, ecKey} {
+		signer := &X509Signer{Certificate: selfSigned(t, key), Key: key}
+		for name, envelope := range envelopes {
+			signed, err := signer.Sign([]byte(envelope))
+			if err != nil {
+				t.Fatalf("%s: Sign() error = %v", name, err)
+			}
+			wantRefs := 2
+			if name == "username token" {
+				wantRefs = 3
+			}
+			verify(t, signed, key.Public(), wantRefs)
+		}
+	}
+}
+
+// verify checks the digests and the signature value of a signed envelope
+func verify(t *testing.T, signed []byte, pub crypto.PublicKey, wantRefs int) {
+	t.Helper()
+
+	if err := xml.Unmarshal(signed, new(struct{})); err != nil {
+		t.Fatalf("signed envelope is not well-formed: %v\n%s", err, signed)
+	}
+	layout, err := scanEnvelope(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digests := regexp.MustCompile(`<ds:Reference URI="#([^"]+)">.*?<ds:DigestValue>([^<]+)</ds:DigestValue>`).FindAllSubmatch(signed, -1)
+	if len(digests) != wantRefs {
+		t.Fatalf("%d references, want %d:\n%s", len(digests), wantRefs, signed)
+	}
+	for _, ref := range digests {
+		var part *signedPart
+		for _, p := range []*signedPart{layout.body, layout.timestamp, layout.username} {
+			if p != nil && p.id == string(ref[1]) {
+				part = p
+			}
+		}
+		if part == nil {
+			t.Fatalf("reference #%s has no element:\n%s", ref[1], signed)
+		}
+		c14n, err := canonicalize(signed[part.start:part.end], part.scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha256.Sum256(c14n)
+		if base64.StdEncoding.EncodeToString(digest[:]) != string(ref[2]) {
+			t.Errorf("digest of #%s doesn't match", ref[1])
+		}
+	}
+
+	start := bytes.Index(signed, []byte("<ds:SignedInfo>"))
+	end := bytes.Index(signed, []byte("</ds:SignedInfo>")) + len("</ds:SignedInfo>")
+	c14n, err := canonicalize(signed[start:end], map[string]string{"ds": dsNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(c14n)
+	value := regexp.MustCompile(`<ds:SignatureValue>([^<]+)<`).FindSubmatch(signed)
+	sig, _ := base64.StdEncoding.DecodeString(string(value[1]))
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("RSA signature doesn't verify: %v", err)
+		}
+	case *ecdsa.PublicKey:
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			t.Error("ECDSA signature doesn't verify")
+		}
+	}
+
+	if strings.Count(string(signed), "wsse:BinarySecurityToken ") != 1 || strings.Count(string(signed), "<wsu:Timestamp") != 1 {
+		t.Errorf("want one token and one timestamp:\n%s", signed)
+	}
+}
+
+// selfSigned returns a self-signed certificate of key
+func selfSigned(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -0,0 +1,53 @@
+package security
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+)
+
+// SPNEGOProvider creates the tokens of SPNEGO (HTTP Negotiate)
+// authentication. Kerberos needs a ticket for the service, which comes
+// from a Kerberos library logged in with a keytab or from the credentials
+// of the Windows user through SSPI, so the provider plugs one in.
+type SPNEGOProvider interface {
+	// Token returns the initial SPNEGO token for a service principal
+	// name such as HTTP/soap.example.com
+	Token(spn string) ([]byte, error)
+}
+
+// SPNEGOTransport authenticates requests with SPNEGO, as WCF services with
+// Kerberos authentication require. Kerberos needs a single round trip, so
+// every request carries a token.
+type SPNEGOTransport struct {
+	Provider SPNEGOProvider
+	// SPN is the service principal name, HTTP/ and the request's host
+	// when empty
+	SPN string
+	// Base makes the requests, http.DefaultTransport when nil
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *SPNEGOTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	spn := t.SPN
+	if spn == "" {
+		spn = "HTTP/" + req.URL.Hostname()
+	}
+	token, err := t.Provider.Token(spn)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("failed to create SPNEGO token for %s: %w", spn, err)
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Negotiate "+base64.StdEncoding.EncodeToString(token))
+	return base.RoundTrip(r)
+}
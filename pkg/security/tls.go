@@ -0,0 +1,90 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// tlsVersions maps the accepted MinVersion values to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSOptions configures the TLS connections to a SOAP service. The zero
+// value uses the system roots and Go's defaults.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of the CAs trusted instead of the system roots
+	CAFile string
+	// CertFile and KeyFile are the client certificate for mutual TLS
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify accepts any server certificate. It makes the
+	// connection open to man-in-the-middle attacks; use it only for testing.
+	InsecureSkipVerify bool
+	// MinVersion is the minimum TLS version: "1.0", "1.1", "1.2" or "1.3"
+	MinVersion string
+}
+
+// IsZero reports whether the options keep the default TLS settings
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+// Config builds the tls.Config of the options
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+
+	if o.MinVersion != "" {
+		version, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			versions := make([]string, 0, len(tlsVersions))
+			for v := range tlsVersions {
+				versions = append(versions, v)
+			}
+			sort.Strings(versions)
+			return nil, fmt.Errorf("unsupported TLS version %q (use %s)", o.MinVersion, strings.Join(versions, ", "))
+		}
+		cfg.MinVersion = version
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Transport returns a clone of http.DefaultTransport using the options
+func (o TLSOptions) Transport() (*http.Transport, error) {
+	cfg, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	return transport, nil
+}
@@ -0,0 +1,101 @@
+package security
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Token is a security token embedded verbatim in the wsse:Security header,
+// such as a SAML assertion or a vendor-specific token. It is a single
+// element that declares every namespace prefix it uses, since it is copied
+// into the header as is.
+type Token []byte
+
+// LoadToken reads a token from a file, such as a SAML assertion issued by
+// an identity provider
+func LoadToken(path string) (Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read security token: %w", err)
+	}
+	token := Token(strings.TrimSpace(string(data)))
+	if err := token.Validate(); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Validate returns an error unless the token is a single well-formed
+// element whose prefixes are all declared in it. An XML declaration before
+// the element is not allowed, as the token ends up inside the envelope.
+func (t Token) Validate() error {
+	dec := xml.NewDecoder(strings.NewReader(string(t)))
+	scopes := []map[string]string{{"xml": xmlNamespace}}
+	var open []xml.Name
+	roots := 0
+
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid security token: %w", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if len(scopes) == 1 {
+				roots++
+			}
+			scope := scopes[len(scopes)-1]
+			for _, a := range tok.Attr {
+				if a.Name.Space == "xmlns" {
+					scope = with(scope, a.Name.Local, a.Value)
+				}
+			}
+			names := []xml.Name{tok.Name}
+			for _, a := range tok.Attr {
+				if a.Name.Space != "xmlns" {
+					names = append(names, a.Name)
+				}
+			}
+			for _, name := range names {
+				if _, ok := scope[name.Space]; name.Space != "" && !ok {
+					return fmt.Errorf("invalid security token: prefix %q of <%s> is not declared", name.Space, tok.Name.Local)
+				}
+			}
+			scopes = append(scopes, scope)
+			open = append(open, tok.Name)
+		case xml.EndElement:
+			// RawToken doesn't match end elements to start elements
+			if len(open) == 0 || open[len(open)-1] != tok.Name {
+				return fmt.Errorf("invalid security token: unexpected </%s>", tok.Name.Local)
+			}
+			scopes = scopes[:len(scopes)-1]
+			open = open[:len(open)-1]
+		case xml.CharData:
+			if len(scopes) == 1 && strings.TrimSpace(string(tok)) != "" {
+				return errors.New("invalid security token: text outside the element")
+			}
+		case xml.ProcInst:
+			if tok.Target == "xml" {
+				return errors.New("invalid security token: must not have an XML declaration")
+			}
+		case xml.Directive:
+			return errors.New("invalid security token: must not have a DTD")
+		}
+	}
+
+	if len(open) > 0 {
+		return fmt.Errorf("invalid security token: <%s> is not closed", open[len(open)-1].Local)
+	}
+	if roots != 1 {
+		return fmt.Errorf("invalid security token: want one element, got %d", roots)
+	}
+	return nil
+}
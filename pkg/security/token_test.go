@@ -0,0 +1,58 @@
+package security
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const samlAssertion = `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a1" Version="2.0">
+  <saml:Issuer>https://idp.example.com</saml:Issuer>
+</saml:Assertion>`
+
+func TestTokenValidate(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{samlAssertion, ""},
+		{`<Token xmlns="urn:vendor"/>`, ""},
+		{`<saml:Assertion/>`, `prefix "saml" of <Assertion> is not declared`},
+		{`<a xmlns:v="urn:v" v:id="1"><b v:x="2"/></a>`, ""},
+		{`<a><b w:x="2"/></a>`, `prefix "w" of <b> is not declared`},
+		{`<a/><b/>`, "want one element, got 2"},
+		{`token`, "text outside the element"},
+		{`<a><b></a>`, "unexpected </a>"},
+		{`<a>`, "<a> is not closed"},
+		{`<?xml version="1.0"?><a/>`, "XML declaration"},
+		{``, "want one element, got 0"},
+	}
+
+	for _, tt := range tests {
+		err := Token(tt.token).Validate()
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("Validate(%q) error = %v", tt.token, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%q) error = %v, want %q", tt.token, err, tt.want)
+		}
+	}
+}
+
+func TestSecurityHeaderAddToken(t *testing.T) {
+	header := NewSecurityHeader(&WSSecurity{Username: "ada", Password: "secret"})
+	header.AddToken(Token(samlAssertion))
+
+	data, err := xml.Marshal(header)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got := string(data)
+	token := strings.Index(got, "<saml:Assertion")
+	if token == -1 || token < strings.Index(got, "</wsse:UsernameToken>") || !strings.HasSuffix(got, "</saml:Assertion></wsse:Security>") {
+		t.Errorf("Marshal() = %s, want the assertion after the UsernameToken", got)
+	}
+}
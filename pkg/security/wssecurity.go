@@ -22,6 +22,8 @@ type SecurityHeader struct {
 	WSU       string          `xml:"xmlns:wsu,attr"`
 	Timestamp *Timestamp      `xml:"wsu:Timestamp,omitempty"`
 	UsernameToken *UsernameToken `xml:"wsse:UsernameToken,omitempty"`
+	// Tokens holds the custom tokens added with AddToken, written verbatim
+	Tokens []byte `xml:",innerxml"`
 }
 
 // Timestamp represents WS-Security timestamp
@@ -76,6 +78,12 @@ func NewSecurityHeader(ws *WSSecurity) *SecurityHeader {
 	return header
 }
 
+// AddToken embeds a custom security token, such as a SAML assertion, in
+// the header after the UsernameToken
+func (h *SecurityHeader) AddToken(token Token) {
+	h.Tokens = append(h.Tokens, token...)
+}
+
 // createTimestamp creates a timestamp element
 func createTimestamp() *Timestamp {
 	now := time.Now().UTC()
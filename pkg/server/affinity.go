@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+)
+
+// Affinity configures backend sessions for stateful services that issue
+// session cookies or session IDs in headers. Each client, told apart by the
+// value of KeyHeader in its requests such as an API key, gets a session of
+// its own: the cookies the backend sets and the SessionHeaders it returns
+// are sent back on the next calls of that client only. Requests without
+// KeyHeader get no session.
+type Affinity struct {
+	KeyHeader      string        // Inbound header identifying the client, such as X-API-Key
+	SessionHeaders []string      // Backend response headers carrying a session ID
+	IdleTimeout    time.Duration // Time an unused session is kept (default 30 minutes)
+}
+
+// sessions holds the backend sessions of an Affinity config. It is shared
+// by all services of a multi-WSDL server, like the breaker.
+type sessions struct {
+	config    Affinity
+	now       func() time.Time
+	mu        sync.Mutex
+	clients   map[[sha256.Size]byte]*backendSession
+	lastSweep time.Time
+}
+
+// backendSession is the backend session of a client
+type backendSession struct {
+	jar      *cookiejar.Jar
+	names    []string // Session headers to capture
+	mu       sync.Mutex
+	headers  http.Header
+	lastUsed time.Time
+}
+
+// sessionKey is the context key of the backend session of a request
+type sessionKey struct{}
+
+// SetAffinity keeps a backend session per client, as config describes
+func (s *Server) SetAffinity(config Affinity) error {
+	if config.KeyHeader == "" {
+		return fmt.Errorf("session affinity requires a client key header")
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	s.sessions = &sessions{
+		config:  config,
+		now:     time.Now,
+		clients: make(map[[sha256.Size]byte]*backendSession),
+	}
+	return nil
+}
+
+// withSession returns a context carrying the backend session of the
+// client of r, when affinity is enabled and r names its client
+func (s *sessions) withSession(ctx context.Context, r *http.Request) context.Context {
+	if s == nil {
+		return ctx
+	}
+	key := r.Header.Get(s.config.KeyHeader)
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionKey{}, s.session(key))
+}
+
+// session returns the session of a client key, creating it on first use.
+// Keys are stored hashed, as they are often credentials.
+func (s *sessions) session(key string) *backendSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > s.config.IdleTimeout {
+		for k, sess := range s.clients {
+			sess.mu.Lock()
+			idle := now.Sub(sess.lastUsed) > s.config.IdleTimeout
+			sess.mu.Unlock()
+			if idle {
+				delete(s.clients, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	sess, ok := s.clients[hash]
+	if !ok {
+		// cookiejar.New fails only for invalid options
+		jar, _ := cookiejar.New(nil)
+		sess = &backendSession{jar: jar, names: s.config.SessionHeaders, headers: make(http.Header)}
+		s.clients[hash] = sess
+	}
+	sess.mu.Lock()
+	sess.lastUsed = now
+	sess.mu.Unlock()
+	return sess
+}
+
+// sessionFromContext returns the backend session of a request, or nil
+func sessionFromContext(ctx context.Context) *backendSession {
+	sess, _ := ctx.Value(sessionKey{}).(*backendSession)
+	return sess
+}
+
+// apply adds the cookies and session headers of the session to a backend
+// request
+func (b *backendSession) apply(req *http.Request) {
+	if b == nil {
+		return
+	}
+	for _, cookie := range b.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for name, values := range b.headers {
+		req.Header[name] = values
+	}
+}
+
+// capture keeps the cookies and the session headers of a backend response
+func (b *backendSession) capture(req *http.Request, resp *http.Response) {
+	if b == nil {
+		return
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		b.jar.SetCookies(req.URL, cookies)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range b.names {
+		if value := resp.Header.Get(name); value != "" {
+			b.headers.Set(name, value)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestAffinity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The backend opens a session on the first call of each client and
+	// expects the cookie and header back
+	var mu sync.Mutex
+	var opened int
+	var seen []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		cookie, err := r.Cookie("JSESSIONID")
+		if err != nil {
+			opened++
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: fmt.Sprint(opened), Path: "/"})
+			w.Header().Set("X-Session-Id", fmt.Sprintf("s%d", opened))
+			seen = append(seen, "new")
+		} else {
+			seen = append(seen, cookie.Value+"/"+r.Header.Get("X-Session-Id"))
+		}
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <PingResponse xmlns="urn:ping"><ok>true</ok></PingResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Ping",
+		TargetNamespace: "urn:ping",
+		Messages: []models.Message{
+			{Name: "PingIn"},
+			{Name: "PingOut", Parts: []models.Part{{Name: "ok", Type: "xs:boolean"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "Ping", Input: models.Message{Name: "tns:PingIn"}, Output: models.Message{Name: "tns:PingOut"}},
+		}}},
+	}
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	if err := s.SetAffinity(Affinity{}); err == nil {
+		t.Error("SetAffinity() accepted a config without a key header")
+	}
+	if err := s.SetAffinity(Affinity{KeyHeader: "X-API-Key", SessionHeaders: []string{"X-Session-Id"}, IdleTimeout: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	s.sessions.now = func() time.Time { return now }
+	s.setupRoutes()
+
+	call := func(key string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`))
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST /api/Ping = %d %s", w.Code, w.Body)
+		}
+	}
+	call("alice")
+	call("bob")
+	call("alice")
+	call("bob")
+	call("") // no session
+	call("")
+	now = now.Add(2 * time.Minute)
+	call("alice") // the idle session expired
+
+	want := []string{"new", "new", "1/s1", "2/s2", "new", "new", "new"}
+	if strings.Join(seen, " ") != strings.Join(want, " ") {
+		t.Errorf("backend saw sessions %v, want %v", seen, want)
+	}
+	if len(s.sessions.clients) != 1 {
+		t.Errorf("%d sessions kept, want 1 after the others expired", len(s.sessions.clients))
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thdev01/wsdl2api/pkg/compression"
+	"github.com/thdev01/wsdl2api/pkg/security"
+)
+
+// Pool tunes the HTTP connections of backend SOAP calls. All requests share
+// one pool, so connections to the backend are kept alive and reused instead
+// of being opened per call.
+type Pool struct {
+	MaxIdleConnsPerHost int           // Idle keep-alive connections kept per backend host
+	MaxConnsPerHost     int           // Connections per backend host, 0 for unlimited
+	IdleConnTimeout     time.Duration // How long idle connections are kept
+	DialTimeout         time.Duration // Timeout for establishing a connection
+	Timeout             time.Duration // Timeout for a whole call, 0 for none
+	DisableKeepAlives   bool          // Close the connection after each call
+}
+
+// DefaultPool returns the connection pool settings used by NewServer
+func DefaultPool() Pool {
+	return Pool{
+		MaxIdleConnsPerHost: 64,
+		IdleConnTimeout:     90 * time.Second,
+		DialTimeout:         10 * time.Second,
+		Timeout:             60 * time.Second,
+	}
+}
+
+// SetPool tunes the connection pool of backend SOAP calls
+func (s *Server) SetPool(pool Pool) error {
+	return s.setBackend(pool, s.backendTLS)
+}
+
+// SetBackendTLS configures the TLS connections to the SOAP backend: custom
+// CAs, a client certificate for mutual TLS and the minimum TLS version
+func (s *Server) SetBackendTLS(opts security.TLSOptions) error {
+	if err := s.setBackend(s.pool, opts); err != nil {
+		return err
+	}
+	if opts.InsecureSkipVerify {
+		s.log().Warn("TLS certificate verification of the SOAP backend is disabled; calls can be intercepted")
+	}
+	return nil
+}
+
+// SetOAuth2 authenticates backend calls with a bearer token acquired with
+// the OAuth2 client credentials flow, cached until shortly before it
+// expires. Token requests use their own connections, not the backend pool.
+func (s *Server) SetOAuth2(config security.OAuth2Config) error {
+	source, err := security.NewTokenSource(config, nil)
+	if err != nil {
+		return err
+	}
+	s.oauth2 = source
+	return nil
+}
+
+// SetSPNEGO authenticates backend calls with Kerberos through SPNEGO,
+// taking the tokens from provider. An empty spn uses HTTP/ and the host of
+// the SOAP endpoint.
+func (s *Server) SetSPNEGO(provider security.SPNEGOProvider, spn string) {
+	s.spnego = &security.SPNEGOTransport{Provider: provider, SPN: spn}
+}
+
+// SetCompression compresses backend requests with encoding, "gzip" or
+// "deflate", and accepts responses compressed with either; "" turns
+// compression off
+func (s *Server) SetCompression(encoding string) error {
+	if err := compression.Check(encoding); err != nil {
+		return err
+	}
+	s.compression = encoding
+	return nil
+}
+
+// backendClient returns the HTTP client of a backend call: the shared one,
+// wrapped to authenticate at the transport level with NTLM credentials or
+// SPNEGO. The wrappers use the shared connection pool.
+func (s *Server) backendClient(creds *Credentials) *http.Client {
+	var transport http.RoundTripper
+	switch {
+	case creds != nil && creds.Mode == CredentialsNTLM:
+		transport = &security.NTLMTransport{
+			Username: creds.Username,
+			Password: creds.Password,
+			Base:     s.httpClient.Transport,
+		}
+	case s.spnego != nil:
+		spnego := *s.spnego
+		spnego.Base = s.httpClient.Transport
+		transport = &spnego
+	default:
+		return s.httpClient
+	}
+
+	client := *s.httpClient
+	client.Transport = transport
+	return &client
+}
+
+// setBackend replaces the HTTP client of backend calls
+func (s *Server) setBackend(pool Pool, opts security.TLSOptions) error {
+	client, err := newBackendClient(pool, opts)
+	if err != nil {
+		return err
+	}
+	s.pool = pool
+	s.backendTLS = opts
+	s.httpClient = client
+	return nil
+}
+
+// newBackendClient builds the HTTP client shared by backend SOAP calls
+func newBackendClient(pool Pool, opts security.TLSOptions) (*http.Client, error) {
+	transport, err := opts.Transport()
+	if err != nil {
+		return nil, err
+	}
+
+	transport.DialContext = (&net.Dialer{
+		Timeout:   pool.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	if pool.MaxIdleConnsPerHost > transport.MaxIdleConns {
+		transport.MaxIdleConns = pool.MaxIdleConnsPerHost
+	}
+	transport.MaxIdleConnsPerHost = pool.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = pool.MaxConnsPerHost
+	transport.IdleConnTimeout = pool.IdleConnTimeout
+	transport.DisableKeepAlives = pool.DisableKeepAlives
+
+	return &http.Client{Transport: transport, Timeout: pool.Timeout}, nil
+}
+
+// soapContentType returns the Content-Type of SOAP requests of a version.
+// SOAP 1.2 has no SOAPAction header: the action is the action parameter of
+// its Content-Type.
+func soapContentType(version, action string) string {
+	if version != "1.2" {
+		return "text/xml; charset=utf-8"
+	}
+	if action == "" {
+		return "application/soap+xml; charset=utf-8"
+	}
+	return `application/soap+xml; charset=utf-8; action="` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(action) + `"`
+}
+
+// xmlContentType reports whether a response Content-Type may carry a SOAP
+// envelope: text/xml, application/soap+xml or another XML type, with any
+// parameters. Missing and text/plain types, which some legacy services
+// send, are accepted too.
+func xmlContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// Tolerate malformed parameters such as unquoted actions
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	switch mediaType {
+	case "text/xml", "application/xml", "application/soap+xml", "text/plain":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+xml")
+}
@@ -0,0 +1,451 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/compression"
+	"github.com/thdev01/wsdl2api/pkg/security"
+)
+
+// pingDefinitions describes a service with a single Ping operation
+var pingDefinitions = &models.Definitions{
+	Name:      "Ping",
+	Messages:  []models.Message{{Name: "PingIn"}, {Name: "PingOut", Parts: []models.Part{{Name: "ok", Type: "xs:boolean"}}}},
+	PortTypes: []models.PortType{{Operations: []models.Operation{{Name: "Ping", Input: models.Message{Name: "tns:PingIn"}, Output: models.Message{Name: "tns:PingOut"}}}}},
+}
+
+// pingResponse is the SOAP response of Ping
+const pingResponse = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <PingResponse xmlns="urn:ping"><ok>true</ok></PingResponse>
+</soap:Body></soap:Envelope>`
+
+func TestPool(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var conns int32
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pingResponse))
+	}))
+	backend.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	backend.Start()
+	defer backend.Close()
+
+	for _, keepAlive := range []bool{true, false} {
+		atomic.StoreInt32(&conns, 0)
+
+		s := NewServer(pingDefinitions, "localhost", 0)
+		s.SetSOAPEndpoint(backend.URL)
+		pool := DefaultPool()
+		pool.DisableKeepAlives = !keepAlive
+		if err := s.SetPool(pool); err != nil {
+			t.Fatalf("SetPool() error = %v", err)
+		}
+		s.setupRoutes()
+
+		for i := 0; i < 5; i++ {
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+		}
+
+		want := int32(1)
+		if !keepAlive {
+			want = 5
+		}
+		if got := atomic.LoadInt32(&conns); got != want {
+			t.Errorf("keep-alive %v: %d connections, want %d", keepAlive, got, want)
+		}
+	}
+}
+
+func TestBackendTLS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   *security.TLSOptions
+		status int
+	}{
+		{"system roots", nil, http.StatusInternalServerError},
+		{"custom CA", &security.TLSOptions{CAFile: caFile, MinVersion: "1.2"}, http.StatusOK},
+		{"insecure", &security.TLSOptions{InsecureSkipVerify: true}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(pingDefinitions, "localhost", 0)
+			s.SetSOAPEndpoint(backend.URL)
+			if tt.opts != nil {
+				if err := s.SetBackendTLS(*tt.opts); err != nil {
+					t.Fatalf("SetBackendTLS() error = %v", err)
+				}
+			}
+			s.setupRoutes()
+
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+
+	s := NewServer(pingDefinitions, "localhost", 0)
+	if err := s.SetBackendTLS(security.TLSOptions{MinVersion: "1.4"}); err == nil {
+		t.Error("SetBackendTLS() accepted TLS 1.4")
+	}
+}
+
+func TestOAuth2(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var issued int32
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token-` + string(rune('0'+n)) + `","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer idp.Close()
+
+	var authorization atomic.Value
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	s := NewServer(pingDefinitions, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	if err := s.SetOAuth2(security.OAuth2Config{TokenURL: idp.URL, ClientID: "proxy", ClientSecret: "secret"}); err != nil {
+		t.Fatalf("SetOAuth2() error = %v", err)
+	}
+	s.setupRoutes()
+
+	// A rejected token is replaced, the next one is cached
+	for i, want := range []string{"token-1", "token-2", "token-2"} {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+		if got := authorization.Load(); got != "Bearer "+want {
+			t.Errorf("call %d: Authorization = %v, want Bearer %s", i, got, want)
+		}
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls int
+	var authorization, body string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		calls++
+		authorization, body = r.Header.Get("Authorization"), string(data)
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	basic := func(user, pass string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	}
+
+	tests := []struct {
+		name          string
+		creds         *Credentials
+		inbound       string // Authorization header of the proxy request
+		status        int
+		authorization string
+		want          []string
+		unwanted      []string
+	}{
+		{
+			name:     "unauthenticated",
+			inbound:  basic("ada", "hunter2"),
+			status:   http.StatusOK,
+			unwanted: []string{"wsse:Security"},
+		},
+		{
+			name:          "static basic",
+			creds:         &Credentials{Mode: CredentialsBasic, Username: "svc", Password: "secret"},
+			inbound:       basic("ada", "hunter2"),
+			status:        http.StatusOK,
+			authorization: basic("svc", "secret"),
+			unwanted:      []string{"wsse:Security"},
+		},
+		{
+			name:          "forwarded basic",
+			creds:         &Credentials{Mode: CredentialsBasic},
+			inbound:       basic("ada", "hunter2"),
+			status:        http.StatusOK,
+			authorization: basic("ada", "hunter2"),
+		},
+		{
+			name:    "static username token",
+			creds:   &Credentials{Mode: CredentialsWSSecurity, Username: "svc", Password: "secret"},
+			inbound: basic("ada", "hunter2"),
+			status:  http.StatusOK,
+			want: []string{
+				"<wsse:Username>svc</wsse:Username>",
+				`#PasswordText">secret</wsse:Password>`,
+			},
+			unwanted: []string{"ada"},
+		},
+		{
+			name:    "forwarded username token digest",
+			creds:   &Credentials{Mode: CredentialsWSSecurityDigest},
+			inbound: basic("ada", "hunter2"),
+			status:  http.StatusOK,
+			want: []string{
+				"<wsse:Username>ada</wsse:Username>",
+				`#PasswordDigest">`,
+				"<wsse:Nonce ",
+			},
+			unwanted: []string{"hunter2"},
+		},
+		{
+			name:   "forwarded without credentials",
+			creds:  &Credentials{Mode: CredentialsWSSecurity},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, authorization, body = 0, "", ""
+			s := NewServer(pingDefinitions, "localhost", 0)
+			s.SetSOAPEndpoint(backend.URL)
+			s.SetCredentials(tt.creds)
+			s.setupRoutes()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`))
+			if tt.inbound != "" {
+				req.Header.Set("Authorization", tt.inbound)
+			}
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("POST /api/Ping = %d %s, want %d", w.Code, w.Body, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized {
+				if calls != 0 || w.Header().Get("WWW-Authenticate") == "" {
+					t.Errorf("the backend got %d calls and the challenge is %q, want none and a Basic challenge", calls, w.Header().Get("WWW-Authenticate"))
+				}
+				return
+			}
+			if authorization != tt.authorization {
+				t.Errorf("backend Authorization = %q, want %q", authorization, tt.authorization)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("backend request = %s, want %s", body, want)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("backend request = %s, want no %s", body, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestBackendNTLM(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The backend answers the negotiate message with a bare challenge and
+	// accepts any authenticate message
+	var steps []uint32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "NTLM ")
+		msg, _ := base64.StdEncoding.DecodeString(token)
+		if len(msg) < 12 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		steps = append(steps, binary.LittleEndian.Uint32(msg[8:]))
+		if steps[len(steps)-1] == 1 {
+			challenge := make([]byte, 48)
+			copy(challenge, "NTLMSSP\x00")
+			challenge[8], challenge[20] = 2, 1
+			binary.LittleEndian.PutUint32(challenge[44:], 48)
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challenge))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	s := NewServer(pingDefinitions, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetCredentials(&Credentials{Mode: CredentialsNTLM, Username: `CORP\alice`, Password: "secret"})
+	s.setupRoutes()
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d: %s", w.Code, w.Body)
+	}
+	if len(steps) != 2 || steps[0] != 1 || steps[1] != 3 {
+		t.Errorf("NTLM messages = %v, want [1 3]", steps)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, encoding := range []string{compression.Gzip, compression.Deflate} {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Content-Encoding"); got != encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, encoding)
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if strings.Contains(string(body), "Envelope") {
+				t.Errorf("request body not compressed: %s", body)
+			}
+			compressed, err := compression.Compress(encoding, []byte(pingResponse))
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Header().Set("Content-Encoding", encoding)
+			w.Write(compressed)
+		}))
+
+		s := NewServer(pingDefinitions, "localhost", 0)
+		s.SetSOAPEndpoint(backend.URL)
+		if err := s.SetCompression(encoding); err != nil {
+			t.Fatalf("SetCompression(%q) error = %v", encoding, err)
+		}
+		s.setupRoutes()
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"ok":true`) {
+			t.Errorf("%s: status = %d: %s", encoding, w.Code, w.Body)
+		}
+		backend.Close()
+	}
+
+	if err := NewServer(pingDefinitions, "localhost", 0).SetCompression("br"); err == nil {
+		t.Error("SetCompression() accepted br")
+	}
+}
+
+func TestContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	def := *pingDefinitions
+	def.Bindings = []models.Binding{{Operations: []models.BindingOperation{{Name: "Ping", SoapAction: "urn:ping/Ping"}}}}
+
+	var contentType, soapAction, response string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, soapAction = r.Header.Get("Content-Type"), r.Header.Get("SOAPAction")
+		w.Header().Set("Content-Type", response)
+		if strings.HasPrefix(response, "text/html") {
+			w.Write([]byte("<html><body>Sign in</body></html>"))
+			return
+		}
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		version, response string
+		contentType       string
+		soapAction        string
+		status            int
+	}{
+		{"1.1", "text/xml; charset=utf-8", "text/xml; charset=utf-8", `"urn:ping/Ping"`, http.StatusOK},
+		{"1.2", `application/soap+xml;charset=UTF-8;action="urn:ping/PingResponse"`, `application/soap+xml; charset=utf-8; action="urn:ping/Ping"`, "", http.StatusOK},
+		{"1.2", "application/soap+xml; action=urn:ping/PingResponse", `application/soap+xml; charset=utf-8; action="urn:ping/Ping"`, "", http.StatusOK},
+		{"1.1", "", "text/xml; charset=utf-8", `"urn:ping/Ping"`, http.StatusOK},
+		{"1.1", "text/html; charset=utf-8", "text/xml; charset=utf-8", `"urn:ping/Ping"`, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		response = tt.response
+		s := NewServer(&def, "localhost", 0)
+		s.SetSOAPEndpoint(backend.URL)
+		s.SetSOAPVersion(tt.version)
+		s.setupRoutes()
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+		if w.Code != tt.status {
+			t.Errorf("SOAP %s, response %q: status = %d, want %d: %s", tt.version, tt.response, w.Code, tt.status, w.Body)
+		}
+		if contentType != tt.contentType || soapAction != tt.soapAction {
+			t.Errorf("SOAP %s: Content-Type = %q, SOAPAction = %q, want %q, %q", tt.version, contentType, soapAction, tt.contentType, tt.soapAction)
+		}
+		if tt.status != http.StatusOK && !strings.Contains(w.Body.String(), "text/html") {
+			t.Errorf("SOAP %s, response %q: error doesn't name the Content-Type: %s", tt.version, tt.response, w.Body)
+		}
+	}
+}
+
+func TestCharset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	def := &models.Definitions{
+		Name:      "Cities",
+		Messages:  []models.Message{{Name: "CityIn"}, {Name: "CityOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}}}},
+		PortTypes: []models.PortType{{Operations: []models.Operation{{Name: "GetCity", Input: models.Message{Name: "tns:CityIn"}, Output: models.Message{Name: "tns:CityOut"}}}}},
+	}
+	tests := []struct {
+		contentType, body string
+	}{
+		{"text/xml; charset=ISO-8859-1", "<soap:Envelope xmlns:soap=\"http://schemas.xmlsoap.org/soap/envelope/\"><soap:Body><GetCityResponse><name>S\xe3o Paulo</name></GetCityResponse></soap:Body></soap:Envelope>"},
+		{"text/xml", "<?xml version=\"1.0\" encoding=\"windows-1252\"?><soap:Envelope xmlns:soap=\"http://schemas.xmlsoap.org/soap/envelope/\"><soap:Body><GetCityResponse><name>S\xe3o Paulo</name></GetCityResponse></soap:Body></soap:Envelope>"},
+	}
+	for _, tt := range tests {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			w.Write([]byte(tt.body))
+		}))
+		s := NewServer(def, "localhost", 0)
+		s.SetSOAPEndpoint(backend.URL)
+		s.setupRoutes()
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetCity", strings.NewReader(`{}`)))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"São Paulo"`) {
+			t.Errorf("%s: status = %d: %s", tt.contentType, w.Code, w.Body)
+		}
+		backend.Close()
+	}
+}
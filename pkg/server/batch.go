@@ -0,0 +1,115 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchPath is the route of the batch endpoint, relative to the API path
+const batchPath = "/_batch"
+
+// Batch configures the batch endpoint
+type Batch struct {
+	Concurrency int // Items called on the backend at once (default 4)
+	MaxItems    int // Items accepted per request (default 100)
+}
+
+// batchItem is an operation call of a batch request
+type batchItem struct {
+	Operation string                 `json:"operation"`
+	Body      map[string]interface{} `json:"body"`
+}
+
+// batchResult is the outcome of a batch item. Status is the HTTP status the
+// call would have had on its own route.
+type batchResult struct {
+	Operation string      `json:"operation"`
+	Status    int         `json:"status"`
+	Response  interface{} `json:"response,omitempty"`
+	Error     interface{} `json:"error,omitempty"`
+}
+
+// SetBatch configures the concurrency and size of batch requests
+func (s *Server) SetBatch(batch Batch) {
+	s.batch = batch
+}
+
+// handleBatch calls the operations of a JSON array of {operation, body}
+// items, with at most Batch.Concurrency backend calls at once. Items fail
+// independently; the response lists their results in request order.
+func (s *Server) handleBatch(operations map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		concurrency, maxItems := s.batch.Concurrency, s.batch.MaxItems
+		if concurrency <= 0 {
+			concurrency = 4
+		}
+		if maxItems <= 0 {
+			maxItems = 100
+		}
+
+		var items []batchItem
+		if err := c.ShouldBindJSON(&items); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		if len(items) > maxItems {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Batch too large",
+				"details": fmt.Sprintf("%d items exceed the limit of %d", len(items), maxItems),
+			})
+			return
+		}
+
+		creds, err := s.resolveCredentials(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Basic realm="wsdl2api"`)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		results := make([]batchResult, len(items))
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, item := range items {
+			if !operations[item.Operation] {
+				results[i] = batchResult{
+					Operation: item.Operation,
+					Status:    http.StatusNotFound,
+					Error:     gin.H{"error": "Unknown operation"},
+				}
+				continue
+			}
+			if item.Body == nil {
+				item.Body = make(map[string]interface{})
+			}
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, item batchItem) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				response, err := s.invokeOperation(s.requestContext(c.Request), item.Operation, item.Body, creds)
+				if err != nil {
+					status, body := operationError(item.Operation, err)
+					results[i] = batchResult{Operation: item.Operation, Status: status, Error: body}
+					return
+				}
+				results[i] = batchResult{Operation: item.Operation, Status: http.StatusOK, Response: response}
+			}(i, item)
+		}
+		wg.Wait()
+
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	}
+}
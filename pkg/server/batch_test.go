@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var inFlight, maxInFlight int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <EchoResponse xmlns="urn:echo"><value>ok</value></EchoResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name: "Echo",
+		Messages: []models.Message{
+			{Name: "EchoIn", Parts: []models.Part{{Name: "value", Type: "xs:int"}}},
+			{Name: "EchoOut", Parts: []models.Part{{Name: "value", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "Echo", Input: models.Message{Name: "tns:EchoIn"}, Output: models.Message{Name: "tns:EchoOut"}},
+		}}},
+	}
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetBatch(Batch{Concurrency: 2, MaxItems: 8})
+	s.setupRoutes()
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/_batch", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`[
+		{"operation": "Echo", "body": {"value": 1}},
+		{"operation": "Echo", "body": {"value": "one"}},
+		{"operation": "Missing"},
+		{"operation": "Echo", "body": {"value": 2}},
+		{"operation": "Echo", "body": {"value": 3}},
+		{"operation": "Echo", "body": {"value": 4}}
+	]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	var result struct {
+		Results []batchResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	var statuses []int
+	for _, r := range result.Results {
+		statuses = append(statuses, r.Status)
+	}
+	want := []int{200, 422, 404, 200, 200, 200}
+	if len(statuses) != len(want) {
+		t.Fatalf("statuses = %v, want %v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", statuses, want)
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("%d concurrent backend calls, want at most 2", got)
+	}
+
+	if w := post(`[` + strings.Repeat(`{"operation": "Echo"},`, 8) + `{"operation": "Echo"}]`); w.Code != http.StatusBadRequest {
+		t.Errorf("oversized batch: status = %d", w.Code)
+	}
+	if w := post(`{"operation": "Echo"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("non-array batch: status = %d", w.Code)
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Breaker configures the circuit breaker of backend SOAP calls. When the
+// share of failed calls reaches Threshold, the circuit opens and calls fail
+// fast with 503 Service Unavailable. After Cooldown a single trial call is
+// let through (half-open): its success closes the circuit, its failure
+// opens it again. Failures are transport errors, such as refused
+// connections and timeouts, and 5xx responses that aren't SOAP faults.
+type Breaker struct {
+	Threshold    float64       // Failure ratio opening the circuit, between 0 and 1
+	MinRequests  int           // Calls in a window before the ratio counts (default 10)
+	Window       time.Duration // Period calls are counted over (default 1 minute)
+	Cooldown     time.Duration // Time the circuit stays open (default 30 seconds)
+	PerOperation bool          // One circuit per operation instead of per endpoint
+}
+
+// Circuit states
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitOpenError is returned for calls rejected by an open circuit
+type circuitOpenError struct {
+	key        string
+	retryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("circuit for %s is open after repeated backend failures", e.key)
+}
+
+// breaker holds the circuits of a Breaker config. It is shared by all
+// services of a multi-WSDL server, like the throttle.
+type breaker struct {
+	config   Breaker
+	now      func() time.Time
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit tracks the calls of one endpoint or operation
+type circuit struct {
+	state       int
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	trial       bool // the half-open trial call is in flight
+}
+
+// SetBreaker enables the circuit breaker of backend SOAP calls
+func (s *Server) SetBreaker(config Breaker) {
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	s.breaker = &breaker{
+		config:   config,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// key returns the circuit of a call to operation at endpoint
+func (b *breaker) key(endpoint, operation string) string {
+	if b.config.PerOperation {
+		return operation
+	}
+	return endpoint
+}
+
+// allow reports whether a call may be made, returning a circuitOpenError
+// when the circuit is open or its trial call is in flight
+func (b *breaker) allow(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{windowStart: b.now()}
+		b.circuits[key] = c
+	}
+
+	switch c.state {
+	case circuitOpen:
+		wait := c.openedAt.Add(b.config.Cooldown).Sub(b.now())
+		if wait > 0 {
+			return &circuitOpenError{key: key, retryAfter: wait}
+		}
+		c.state = circuitHalfOpen
+		c.trial = true
+	case circuitHalfOpen:
+		if c.trial {
+			return &circuitOpenError{key: key, retryAfter: time.Second}
+		}
+		c.trial = true
+	}
+	return nil
+}
+
+// done records the outcome of a call allowed by allow. It reports the state
+// the circuit moved to, or -1 when it didn't change.
+func (b *breaker) done(key string, failed bool) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[key]
+	now := b.now()
+
+	if c.state == circuitHalfOpen {
+		c.trial = false
+		if failed {
+			c.state = circuitOpen
+			c.openedAt = now
+			return circuitOpen
+		}
+		*c = circuit{windowStart: now}
+		return circuitClosed
+	}
+	if c.state == circuitOpen {
+		return -1
+	}
+
+	if now.Sub(c.windowStart) >= b.config.Window {
+		c.windowStart = now
+		c.requests = 0
+		c.failures = 0
+	}
+	c.requests++
+	if failed {
+		c.failures++
+	}
+	if c.failures > 0 && c.requests >= b.config.MinRequests && float64(c.failures) >= b.config.Threshold*float64(c.requests) {
+		c.state = circuitOpen
+		c.openedAt = now
+		return circuitOpen
+	}
+	return -1
+}
+
+// release gives up the trial of a call that ended without an outcome, such
+// as one canceled by the client
+func (b *breaker) release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c := b.circuits[key]; c != nil && c.state == circuitHalfOpen {
+		c.trial = false
+	}
+}
+
+// recordCall records the outcome of a backend call in the circuit breaker,
+// logging when the circuit opens or closes
+func (s *Server) recordCall(ctx context.Context, key string, failed bool) {
+	if s.breaker == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		s.breaker.release(key)
+		return
+	}
+	switch s.breaker.done(key, failed) {
+	case circuitOpen:
+		s.log().WarnContext(ctx, "backend circuit opened", "circuit", key, "cooldown", s.breaker.config.Cooldown)
+	case circuitClosed:
+		s.log().InfoContext(ctx, "backend circuit closed", "circuit", key)
+	}
+}
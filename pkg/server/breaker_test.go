@@ -0,0 +1,100 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBreakerStates(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := &Server{}
+	s.SetBreaker(Breaker{Threshold: 0.5, MinRequests: 4, Window: time.Minute, Cooldown: 10 * time.Second})
+	b := s.breaker
+	b.now = func() time.Time { return now }
+
+	call := func(failed bool) error {
+		if err := b.allow("backend"); err != nil {
+			return err
+		}
+		b.done("backend", failed)
+		return nil
+	}
+
+	// Below MinRequests the circuit stays closed whatever the ratio
+	for _, failed := range []bool{true, true, false} {
+		if err := call(failed); err != nil {
+			t.Fatalf("closed circuit rejected a call: %v", err)
+		}
+	}
+	call(true)
+	if err := b.allow("backend"); err == nil {
+		t.Fatal("circuit didn't open at 3 failures of 4 calls")
+	}
+
+	// After the cooldown one trial call is let through
+	now = now.Add(10 * time.Second)
+	if err := b.allow("backend"); err != nil {
+		t.Fatalf("half-open circuit rejected the trial call: %v", err)
+	}
+	if err := b.allow("backend"); err == nil {
+		t.Error("half-open circuit allowed a second call during the trial")
+	}
+	b.done("backend", true)
+	if err := b.allow("backend"); err == nil {
+		t.Error("circuit didn't reopen after a failed trial")
+	}
+
+	now = now.Add(10 * time.Second)
+	if err := call(false); err != nil {
+		t.Fatalf("half-open circuit rejected the trial call: %v", err)
+	}
+	if err := call(true); err != nil {
+		t.Errorf("circuit didn't close after a successful trial: %v", err)
+	}
+
+	// Other circuits are independent
+	if err := b.allow("other"); err != nil {
+		t.Errorf("unrelated circuit rejected a call: %v", err)
+	}
+}
+
+func TestBreakerFailsFast(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "backend down", http.StatusBadGateway)
+	}))
+	defer backend.Close()
+
+	s := NewServer(pingDefinitions, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetBreaker(Breaker{Threshold: 1, MinRequests: 2})
+	s.setupRoutes()
+
+	var codes []int
+	for i := 0; i < 4; i++ {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+		codes = append(codes, w.Code)
+		if w.Code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "30" {
+			t.Errorf("Retry-After = %q, want 30", w.Header().Get("Retry-After"))
+		}
+	}
+
+	want := []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("status codes %v, want %v", codes, want)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("backend called %d times, want 2", calls)
+	}
+}
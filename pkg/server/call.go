@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"fmt"
+)
+
+// Exchange is a backend SOAP call made by Call
+type Exchange struct {
+	Operation string
+	Endpoint  string
+	Request   string                 // SOAP envelope sent, before compression
+	Status    int                    // HTTP status of the response, 0 when none was received
+	Response  []byte                 // SOAP envelope received, decompressed
+	Result    map[string]interface{} // Response as the REST route returns it, nil on errors
+}
+
+// exchangeKey is the context key of the Exchange callSOAP records into
+type exchangeKey struct{}
+
+// exchangeFromContext returns the Exchange stored by Call, or nil
+func exchangeFromContext(ctx context.Context) *Exchange {
+	ex, _ := ctx.Value(exchangeKey{}).(*Exchange)
+	return ex
+}
+
+// Call calls an operation on the backend without serving it, for tools
+// invoking operations directly. The input fields are the ones of the JSON
+// body of the operation's REST route, and are validated the same way. The
+// exchange is returned with errors too, with the envelopes exchanged before
+// the failure; SOAP faults are returned as *Fault.
+func (s *Server) Call(ctx context.Context, operation string, params map[string]interface{}) (*Exchange, error) {
+	known := false
+	for _, pt := range s.definitions.PortTypes {
+		for _, op := range pt.Operations {
+			known = known || op.Name == operation
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown operation: %s", operation)
+	}
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+
+	// Without an inbound request, only configured credentials apply
+	var creds *Credentials
+	if s.credentials != nil && s.credentials.Mode != CredentialsNone {
+		if s.credentials.Username == "" {
+			return nil, fmt.Errorf("backend %s authentication requires a username", s.credentials.Mode)
+		}
+		creds = s.credentials
+	}
+
+	ex := &Exchange{Operation: operation, Endpoint: s.soapEndpoint}
+	result, err := s.invokeOperation(context.WithValue(ctx, exchangeKey{}, ex), operation, params, creds)
+	ex.Result = result
+	return ex, err
+}
+
+// ParseValue converts the text of an input field to the JSON value of its
+// XSD type, as query string parameters are converted
+func (s *Server) ParseValue(xsdType, text string) (interface{}, error) {
+	return parseParam(xsdType, text, s.decimalStrings)
+}
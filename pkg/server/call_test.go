@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestCall(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <UserResponse xmlns="urn:users"><name>Ada</name></UserResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		TargetNamespace: "urn:users",
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "UserOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+		}}},
+	}
+	s := NewServer(def, "", 0)
+	s.SetSOAPEndpoint(backend.URL)
+
+	id, err := s.ParseValue("xs:int", "7")
+	if err != nil {
+		t.Fatalf("ParseValue() error = %v", err)
+	}
+	ex, err := s.Call(context.Background(), "GetUser", map[string]interface{}{"id": id})
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if !strings.Contains(ex.Request, "<id>7</id>") || ex.Status != http.StatusOK || !strings.Contains(string(ex.Response), "<name>Ada</name>") {
+		t.Errorf("Call() exchange = %+v", ex)
+	}
+	if ex.Endpoint != backend.URL || ex.Result["name"] != "Ada" {
+		t.Errorf("Call() result = %v from %s", ex.Result, ex.Endpoint)
+	}
+
+	if _, err := s.Call(context.Background(), "GetUser", map[string]interface{}{"id": "seven"}); err == nil || !strings.Contains(err.Error(), "id: ") {
+		t.Errorf("Call() with an invalid id: error = %v, want the field", err)
+	}
+	if _, err := s.Call(context.Background(), "DeleteUser", nil); err == nil {
+		t.Error("Call() of an undefined operation succeeded")
+	}
+}
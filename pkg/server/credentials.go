@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/thdev01/wsdl2api/pkg/security"
+)
+
+// CredentialMode selects how credentials are sent to the SOAP backend
+type CredentialMode string
+
+const (
+	// CredentialsNone makes unauthenticated backend calls
+	CredentialsNone CredentialMode = ""
+	// CredentialsBasic sends HTTP Basic authentication
+	CredentialsBasic CredentialMode = "basic"
+	// CredentialsWSSecurity sends a WS-Security UsernameToken with a plain password
+	CredentialsWSSecurity CredentialMode = "wssecurity"
+	// CredentialsWSSecurityDigest sends a WS-Security UsernameToken with a password digest
+	CredentialsWSSecurityDigest CredentialMode = "wssecurity-digest"
+	// CredentialsNTLM authenticates with NTLMv2 at the HTTP level. The
+	// username may be given as DOMAIN\user.
+	CredentialsNTLM CredentialMode = "ntlm"
+)
+
+// Credentials configures how the proxy authenticates to the SOAP backend.
+// When Username is empty, the Basic credentials of each inbound request are
+// forwarded instead.
+type Credentials struct {
+	Mode     CredentialMode
+	Username string
+	Password string
+}
+
+// errMissingCredentials is returned when forwarding is enabled but the
+// inbound request carries no Basic credentials
+var errMissingCredentials = errors.New("missing Basic Authorization header")
+
+// SetCredentials enables authentication on backend SOAP calls
+func (s *Server) SetCredentials(creds *Credentials) {
+	s.credentials = creds
+}
+
+// resolveCredentials returns the credentials to use for a backend call made
+// on behalf of r, or nil when backend calls are unauthenticated
+func (s *Server) resolveCredentials(r *http.Request) (*Credentials, error) {
+	if s.credentials == nil || s.credentials.Mode == CredentialsNone {
+		return nil, nil
+	}
+	if s.credentials.Username != "" {
+		return s.credentials, nil
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, errMissingCredentials
+	}
+	return &Credentials{Mode: s.credentials.Mode, Username: username, Password: password}, nil
+}
+
+// securityHeader marshals the WS-Security header for creds, or returns an
+// empty string when creds don't use WS-Security
+func securityHeader(creds *Credentials) (string, error) {
+	if creds == nil || (creds.Mode != CredentialsWSSecurity && creds.Mode != CredentialsWSSecurityDigest) {
+		return "", nil
+	}
+
+	header := security.NewSecurityHeader(&security.WSSecurity{
+		Username:  creds.Username,
+		Password:  creds.Password,
+		UseDigest: creds.Mode == CredentialsWSSecurityDigest,
+	})
+	out, err := xml.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
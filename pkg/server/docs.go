@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+)
+
+// swaggerUIVersion is the swagger-ui-dist release served by /docs
+const swaggerUIVersion = "5.17.14"
+
+//go:generate sh -c "curl -sSfL https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-5.17.14.tgz | tar -xzf - -C swaggerui --strip-components=1 package/swagger-ui.css package/swagger-ui-bundle.js"
+
+// swaggerUI holds the Swagger UI page served at /docs and the
+// swagger-ui-dist assets it loads, fetched by go generate
+//
+//go:embed swaggerui
+var swaggerUI embed.FS
+
+// docsPage is the Swagger UI page, escaping the service name
+var docsPage = template.Must(template.ParseFS(swaggerUI, "swaggerui/index.html"))
+
+// swaggerUIAssets returns where the page loads Swagger UI from: /docs
+// itself when the assets are embedded, unpkg.com otherwise
+func swaggerUIAssets() string {
+	if _, err := fs.Stat(swaggerUI, "swaggerui/swagger-ui-bundle.js"); err != nil {
+		return "https://unpkg.com/swagger-ui-dist@" + swaggerUIVersion + "/"
+	}
+	return "/docs/"
+}
+
+// openAPISpec builds the OpenAPI spec of the REST facade served by s, with
+// paths under each service's prefix and the proxy itself as server
+func (s *Server) openAPISpec() (*exporter.OpenAPISpec, error) {
+	if len(s.services) == 0 {
+		spec, err := exporter.ConvertWSDLToOpenAPIWithRoutes(s.definitions, s.routes)
+		if err != nil {
+			return nil, err
+		}
+		spec.Servers = []exporter.OpenAPIServer{{URL: "/", Description: "wsdl2api REST proxy"}}
+		if s.decimalStrings {
+			spec.DecimalsAsStrings()
+		}
+		return spec, nil
+	}
+
+	combined := &exporter.OpenAPISpec{
+		OpenAPI: "3.0.0",
+		Info: exporter.OpenAPIInfo{
+			Title:       s.definitions.Name,
+			Description: "API converted from multiple WSDLs",
+			Version:     "1.0.0",
+		},
+		Servers: []exporter.OpenAPIServer{{URL: "/", Description: "wsdl2api REST proxy"}},
+		Paths:   make(map[string]exporter.OpenAPIPath),
+		Components: &exporter.OpenAPIComponents{
+			Schemas: make(map[string]*exporter.OpenAPISchema),
+		},
+	}
+	for _, svc := range s.services {
+		spec, err := exporter.ConvertWSDLToOpenAPIWithRoutes(svc.definitions, s.routes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", svc.definitions.Name, err)
+		}
+
+		// Schemas are named after their service, as services may declare
+		// types of the same name
+		prefix := strings.TrimPrefix(svc.apiPath, "/api/")
+		spec.PrefixSchemas(prefix)
+		for path, item := range spec.Paths {
+			for _, m := range item.Operations() {
+				m.Operation.OperationID = prefix + "_" + m.Operation.OperationID
+			}
+			combined.Paths[svc.apiPath+strings.TrimPrefix(path, "/api")] = item
+		}
+		for name, schema := range spec.Components.Schemas {
+			combined.Components.Schemas[name] = schema
+		}
+	}
+	if s.decimalStrings {
+		combined.DecimalsAsStrings()
+	}
+
+	return combined, nil
+}
+
+// handleOpenAPI serves the OpenAPI spec as JSON
+func (s *Server) handleOpenAPI(c *gin.Context) {
+	spec, err := s.openAPISpec()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to build OpenAPI spec",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// handleDocs serves Swagger UI for the OpenAPI spec
+func (s *Server) handleDocs(c *gin.Context) {
+	var page bytes.Buffer
+	err := docsPage.Execute(&page, struct{ Title, Assets string }{s.definitions.Name, swaggerUIAssets()})
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// handleDocsAsset serves the embedded Swagger UI assets
+func (s *Server) handleDocsAsset(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("asset"), "/")
+	if name == "" || name == "index.html" {
+		c.Redirect(http.StatusMovedPermanently, "/docs")
+		return
+	}
+	c.FileFromFS("swaggerui/"+name, http.FS(swaggerUI))
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// docsDefinitions is a service with one operation, named to check escaping
+var docsDefinitions = &models.Definitions{
+	Name:            `Orders</title><script>alert(1)</script>`,
+	TargetNamespace: "urn:orders",
+	Types: []models.Type{{Name: "GetOrderType", Elements: []models.Element{
+		{Name: "id", Type: "xs:int"},
+	}}},
+	Elements: []models.Element{{Name: "GetOrder", Type: "tns:GetOrderType"}},
+	Messages: []models.Message{{Name: "GetOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:GetOrder"}}}},
+	PortTypes: []models.PortType{{Operations: []models.Operation{
+		{Name: "GetOrder", Input: models.Message{Name: "tns:GetOrderIn"}},
+	}}},
+}
+
+func TestDocs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewServer(docsDefinitions, "localhost", 0).Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	page := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("GET /docs = %d %s, want the Swagger UI page", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	// The service name is escaped
+	if strings.Contains(page, "<script>alert") || !strings.Contains(page, "Orders&lt;/title&gt;&lt;script&gt;") {
+		t.Errorf("GET /docs didn't escape the service name:\n%s", page)
+	}
+	if !strings.Contains(page, `url: "/openapi.json"`) {
+		t.Errorf("GET /docs doesn't load /openapi.json:\n%s", page)
+	}
+
+	// Embedded assets are served by the proxy instead of a CDN
+	if _, err := fs.Stat(swaggerUI, "swaggerui/swagger-ui-bundle.js"); err == nil {
+		if strings.Contains(page, "unpkg.com") {
+			t.Errorf("GET /docs loads Swagger UI from unpkg.com with the assets embedded:\n%s", page)
+		}
+		for _, asset := range []string{"/docs/swagger-ui.css", "/docs/swagger-ui-bundle.js"} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, asset, nil))
+			if w.Code != http.StatusOK || w.Body.Len() == 0 {
+				t.Errorf("GET %s = %d, want the asset", asset, w.Code)
+			}
+		}
+	}
+
+	// Nothing else is served from the embedded files
+	for _, path := range []string{"/docs/missing.js", "/docs/../server.go"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code == http.StatusOK {
+			t.Errorf("GET %s = %d %.100s, want an error", path, w.Code, w.Body)
+		}
+	}
+}
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := NewServer(docsDefinitions, "localhost", 0)
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Servers []struct{ URL string }     `json:"servers"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d %s, %v", w.Code, w.Body, err)
+	}
+	if spec.OpenAPI == "" || len(spec.Servers) != 1 || spec.Servers[0].URL != "/" {
+		t.Errorf("GET /openapi.json = %s %v, want the spec served by the proxy", spec.OpenAPI, spec.Servers)
+	}
+	if _, ok := spec.Paths["/api/GetOrder"]; !ok {
+		t.Errorf("GET /openapi.json paths = %v, want /api/GetOrder", spec.Paths)
+	}
+}
+
+// faultService is a service named name whose Get operation declares a
+// ServiceFault with a detail element of its own
+func faultService(name, detail string) *models.Definitions {
+	return &models.Definitions{
+		Name:            name,
+		TargetNamespace: "urn:" + name,
+		Types: []models.Type{
+			{Name: "GetType", Elements: []models.Element{{Name: "id", Type: "xs:int"}}},
+			{Name: "ServiceFault", Elements: []models.Element{{Name: detail, Type: "xs:string"}}},
+		},
+		Elements: []models.Element{
+			{Name: "Get", Type: "tns:GetType"},
+			{Name: "ServiceFault", Type: "tns:ServiceFault"},
+		},
+		Messages: []models.Message{
+			{Name: "GetIn", Parts: []models.Part{{Name: "parameters", Element: "tns:Get"}}},
+			{Name: "ServiceFaultMsg", Parts: []models.Part{{Name: "fault", Element: "tns:ServiceFault"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{{
+			Name:   "Get",
+			Input:  models.Message{Name: "tns:GetIn"},
+			Faults: []models.Fault{{Name: "ServiceFault", Message: "tns:ServiceFaultMsg"}},
+		}}}},
+	}
+}
+
+func TestMultiServerOpenAPI(t *testing.T) {
+	s, err := NewMultiServer([]*models.Definitions{
+		faultService("orders", "orderId"), faultService("billing", "invoiceId"),
+	}, "localhost", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := s.openAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Schemas of the same name are kept apart by the name of their service,
+	// and referenced by it
+	for prefix, detail := range map[string]string{"orders": "orderId", "billing": "invoiceId"} {
+		schema := spec.Components.Schemas[prefix+"_ServiceFault"]
+		if schema == nil || schema.Properties["ServiceFault"] == nil || schema.Properties["ServiceFault"].Properties[detail] == nil {
+			t.Errorf("schema %s_ServiceFault = %+v, want the %s fault", prefix, schema, detail)
+		}
+	}
+	if len(spec.Components.Schemas) != 2 {
+		t.Errorf("schemas = %v, want orders_ServiceFault and billing_ServiceFault", spec.Components.Schemas)
+	}
+	data, err := spec.ExportToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"#/components/schemas/orders_ServiceFault"`, `"#/components/schemas/billing_ServiceFault"`} {
+		if !strings.Contains(data, want) {
+			t.Errorf("spec doesn't reference %s", want)
+		}
+	}
+	if strings.Contains(data, `"#/components/schemas/ServiceFault"`) {
+		t.Error("spec references the unprefixed ServiceFault")
+	}
+}
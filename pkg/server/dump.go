@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/thdev01/wsdl2api/pkg/security"
+)
+
+// soapDump writes the envelopes of backend calls to files of a directory
+type soapDump struct {
+	dir string
+	seq atomic.Uint64
+}
+
+// SetSOAPDump writes the SOAP envelope of every backend call, and of its
+// response, to a file of dir, with passwords and tokens redacted, to debug
+// calls. The files are named after the time, a sequence number and the
+// operation, such as 20060102T150405.000-000001-Add-request.xml. An empty
+// dir turns dumping off.
+func (s *Server) SetSOAPDump(dir string) error {
+	if dir == "" {
+		s.dump = nil
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the SOAP dump directory: %w", err)
+	}
+	s.dump = &soapDump{dir: dir}
+	return nil
+}
+
+// next returns the file name prefix of the envelopes of a new call
+func (d *soapDump) next(operation string) string {
+	return fmt.Sprintf("%s-%06d-%s", time.Now().UTC().Format("20060102T150405.000"), d.seq.Add(1), operation)
+}
+
+// dumpEnvelope writes an envelope to a file of the dump directory. Failing
+// to is logged rather than failing the call.
+func (s *Server) dumpEnvelope(ctx context.Context, name string, envelope []byte) {
+	path := filepath.Join(s.dump.dir, name)
+	if err := os.WriteFile(path, security.Redact(envelope), 0644); err != nil {
+		s.log().WarnContext(ctx, "failed to dump SOAP envelope", "file", path, "error", err)
+	}
+}
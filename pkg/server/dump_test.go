@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestSOAPDump(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><PingResponse/></soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Messages: []models.Message{{Name: "PingIn", Parts: []models.Part{{Name: "text", Type: "xs:string"}}}},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "Ping", Input: models.Message{Name: "tns:PingIn"}},
+		}}},
+	}
+	s := NewServer(def, "", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetCredentials(&Credentials{Mode: CredentialsWSSecurity, Username: "ada", Password: "hunter2"})
+	dir := filepath.Join(t.TempDir(), "dumps")
+	if err := s.SetSOAPDump(dir); err != nil {
+		t.Fatalf("SetSOAPDump() error = %v", err)
+	}
+
+	if _, err := s.Call(context.Background(), "Ping", map[string]interface{}{"text": "hi"}); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("dumped %v, want a request and a response", files)
+	}
+	sort.Strings(files)
+	request, _ := os.ReadFile(files[0])
+	response, _ := os.ReadFile(files[1])
+	if !strings.HasSuffix(files[0], "-000001-Ping-request.xml") || !strings.HasSuffix(files[1], "-000001-Ping-response.xml") {
+		t.Errorf("dump files = %v", files)
+	}
+	if !strings.Contains(string(request), "<text>hi</text>") || strings.Contains(string(request), "hunter2") {
+		t.Errorf("dumped request = %s, want it with the password redacted", request)
+	}
+	if !strings.Contains(string(response), "<PingResponse/>") {
+		t.Errorf("dumped response = %s", response)
+	}
+}
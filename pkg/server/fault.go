@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Fault is a SOAP fault returned by the backend service. It is rendered as
+// the JSON error body of the REST response, with the detail converted like
+// response bodies.
+type Fault struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Detail  interface{} `json:"detail,omitempty"`
+}
+
+// Error implements the error interface
+func (f *Fault) Error() string {
+	return fmt.Sprintf("SOAP fault %s: %s", f.Code, f.Message)
+}
+
+// HTTPStatus maps the fault code to an HTTP status. Faults caused by the
+// request (SOAP 1.1 Client, SOAP 1.2 Sender) map to 400 Bad Request, all
+// others to 502 Bad Gateway.
+func (f *Fault) HTTPStatus() int {
+	code := f.Code
+	if idx := strings.LastIndex(code, ":"); idx != -1 {
+		code = code[idx+1:]
+	}
+	// SOAP 1.1 allows dotted subcodes such as Client.Authentication
+	if idx := strings.Index(code, "."); idx != -1 {
+		code = code[:idx]
+	}
+
+	switch code {
+	case "Client", "Sender":
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// parseFault extracts a SOAP 1.1 or 1.2 fault from a response envelope. It
+// returns nil when the body contains no fault.
+func parseFault(xmlData []byte, hints *typeHints) *Fault {
+	var envelope struct {
+		Body struct {
+			Fault *struct {
+				// SOAP 1.1
+				FaultCode   string `xml:"faultcode"`
+				FaultString string `xml:"faultstring"`
+				Detail      struct {
+					Content []byte `xml:",innerxml"`
+				} `xml:"detail"`
+
+				// SOAP 1.2
+				Code struct {
+					Value string `xml:"Value"`
+				} `xml:"Code"`
+				Reason struct {
+					Text []string `xml:"Text"`
+				} `xml:"Reason"`
+				Detail12 struct {
+					Content []byte `xml:",innerxml"`
+				} `xml:"Detail"`
+			} `xml:"Fault"`
+		} `xml:"Body"`
+	}
+
+	if err := xml.Unmarshal(xmlData, &envelope); err != nil || envelope.Body.Fault == nil {
+		return nil
+	}
+
+	raw := envelope.Body.Fault
+	if raw.FaultCode != "" || raw.FaultString != "" {
+		return &Fault{
+			Code:    strings.TrimSpace(raw.FaultCode),
+			Message: strings.TrimSpace(raw.FaultString),
+			Detail:  hints.detailToJSON(raw.Detail.Content),
+		}
+	}
+
+	fault := &Fault{
+		Code:   strings.TrimSpace(raw.Code.Value),
+		Detail: hints.detailToJSON(raw.Detail12.Content),
+	}
+	if len(raw.Reason.Text) > 0 {
+		fault.Message = strings.TrimSpace(raw.Reason.Text[0])
+	}
+	return fault
+}
+
+// detailToJSON converts the content of a fault detail to an object keyed by
+// element name. Text-only details are returned as a string.
+func (h *typeHints) detailToJSON(content []byte) interface{} {
+	nodes, err := decodeXML(content)
+	if err != nil || len(nodes) == 0 {
+		if text := strings.TrimSpace(string(content)); text != "" {
+			return text
+		}
+		return nil
+	}
+
+	detail := make(map[string]interface{})
+	for _, node := range nodes {
+		detail[node.name] = h.toJSON(node)
+	}
+	return detail
+}
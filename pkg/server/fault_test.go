@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestParseFault(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   Fault
+		status int
+	}{
+		{
+			name: "SOAP 1.1 client fault",
+			body: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client.Validation</faultcode>
+      <faultstring>Invalid CEP</faultstring>
+      <detail><ValidationFault><reason>format</reason><line>3</line></ValidationFault></detail>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`,
+			want: Fault{
+				Code:    "soap:Client.Validation",
+				Message: "Invalid CEP",
+				Detail: map[string]interface{}{
+					"ValidationFault": map[string]interface{}{"reason": "format", "line": int64(3)},
+				},
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "SOAP 1.2 receiver fault",
+			body: `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+  <env:Body>
+    <env:Fault>
+      <env:Code><env:Value>env:Receiver</env:Value></env:Code>
+      <env:Reason><env:Text xml:lang="en">Database unavailable</env:Text></env:Reason>
+    </env:Fault>
+  </env:Body>
+</env:Envelope>`,
+			want:   Fault{Code: "env:Receiver", Message: "Database unavailable"},
+			status: http.StatusBadGateway,
+		},
+	}
+
+	hints := newTypeHints(&models.Definitions{
+		Types: []models.Type{{Name: "ValidationFault", Elements: []models.Element{
+			{Name: "reason", Type: "xsd:string"},
+			{Name: "line", Type: "xsd:int"},
+		}}},
+	}, nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fault := parseFault([]byte(tt.body), hints)
+			if fault == nil {
+				t.Fatal("parseFault() returned nil")
+			}
+			if !reflect.DeepEqual(*fault, tt.want) {
+				t.Errorf("parseFault() = %+v, want %+v", *fault, tt.want)
+			}
+			if got := fault.HTTPStatus(); got != tt.status {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.status)
+			}
+		})
+	}
+
+	ok := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><AddResponse/></soap:Body></soap:Envelope>`
+	if fault := parseFault([]byte(ok), hints); fault != nil {
+		t.Errorf("unexpected fault %+v", fault)
+	}
+}
@@ -0,0 +1,415 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+)
+
+// credentialsKey is the context key of the backend credentials of a
+// GraphQL request
+type credentialsKey struct{}
+
+// graphQLRequest is the body of a GraphQL POST request
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// SetGraphQL enables the /graphql endpoint, which serves the operations as
+// GraphQL queries and mutations
+func (s *Server) SetGraphQL(enabled bool) {
+	s.graphql = enabled
+}
+
+// registerGraphQL mounts the GraphQL endpoint. Every service of a
+// multi-WSDL server shares it, with field names prefixed by the service.
+func (s *Server) registerGraphQL() error {
+	spec, err := s.openAPISpec()
+	if err != nil {
+		return err
+	}
+
+	schema, err := newGraphQLSchema(exporter.ConvertOpenAPIToGraphQL(spec), s.graphQLTargets())
+	if err != nil {
+		return fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+
+	handler := s.handleGraphQL(schema)
+	if s.throttle != nil {
+		s.router.POST("/graphql", s.throttle.middleware(), handler)
+		s.router.GET("/graphql", s.throttle.middleware(), handler)
+	} else {
+		s.router.POST("/graphql", handler)
+		s.router.GET("/graphql", handler)
+	}
+	return nil
+}
+
+// graphQLTarget is the backend operation a GraphQL root field calls
+type graphQLTarget struct {
+	server    *Server
+	operation string
+}
+
+// graphQLTargets maps the REST route of every operation, as "METHOD path",
+// to its backend
+func (s *Server) graphQLTargets() map[string]graphQLTarget {
+	services := s.services
+	if len(services) == 0 {
+		services = []*Server{s}
+	}
+
+	targets := make(map[string]graphQLTarget)
+	for _, svc := range services {
+		for _, portType := range svc.definitions.PortTypes {
+			for _, op := range portType.Operations {
+				route := s.routes.Route(op.Name)
+				targets[route.Method+" "+svc.apiPath+route.Path] = graphQLTarget{server: svc, operation: op.Name}
+			}
+		}
+	}
+	return targets
+}
+
+// resolve calls the backend operation with a REST request body
+func (t graphQLTarget) resolve(ctx context.Context, body map[string]interface{}) (interface{}, error) {
+	creds, _ := ctx.Value(credentialsKey{}).(*Credentials)
+	response, err := t.server.callSOAP(ctx, t.operation, t.server.definitions.SoapAction(t.operation), body, creds)
+	if err != nil {
+		var fault *Fault
+		if errors.As(err, &fault) {
+			return nil, graphQLFault{fault}
+		}
+		return nil, err
+	}
+	// The schema has the names of the WSDL, so fields are not renamed
+	return t.server.transforms.Redact(t.operation, response), nil
+}
+
+// graphQLFault reports a SOAP fault as a GraphQL error, with the fault code
+// and detail as extensions
+type graphQLFault struct {
+	*Fault
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (f graphQLFault) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": f.Code}
+	if f.Detail != nil {
+		extensions["detail"] = f.Detail
+	}
+	return extensions
+}
+
+// handleGraphQL executes GraphQL requests sent as JSON POST bodies or as
+// GET query parameters
+func (s *Server) handleGraphQL(schema graphql.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req graphQLRequest
+		if c.Request.Method == http.MethodGet {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+		} else if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		creds, err := s.resolveCredentials(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Basic realm="wsdl2api"`)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        context.WithValue(c.Request.Context(), credentialsKey{}, creds),
+		})
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// graphQLBuilder builds the executable schema from the exporter's model
+type graphQLBuilder struct {
+	model   *exporter.GraphQLSchema
+	targets map[string]graphQLTarget
+	decls   map[string]exporter.GraphQLType
+	types   map[string]graphql.Type
+}
+
+// newGraphQLSchema builds an executable schema whose root fields call the
+// backend operations of their REST paths
+func newGraphQLSchema(model *exporter.GraphQLSchema, targets map[string]graphQLTarget) (graphql.Schema, error) {
+	b := &graphQLBuilder{
+		model:   model,
+		targets: targets,
+		decls:   make(map[string]exporter.GraphQLType),
+		types: map[string]graphql.Type{
+			"String":             graphql.String,
+			"Int":                graphql.Int,
+			"Float":              graphql.Float,
+			"Boolean":            graphql.Boolean,
+			exporter.GraphQLLong: longScalar,
+			exporter.GraphQLJSON: jsonScalar,
+		},
+	}
+	for _, t := range model.Types {
+		b.decls[t.Name] = t
+	}
+
+	config := graphql.SchemaConfig{Query: b.root("Query", model.Queries)}
+	if len(model.Mutations) > 0 {
+		config.Mutation = b.root("Mutation", model.Mutations)
+	}
+	return graphql.NewSchema(config)
+}
+
+// root builds the Query or Mutation type
+func (b *graphQLBuilder) root(name string, fields []exporter.GraphQLField) *graphql.Object {
+	rootFields := graphql.Fields{}
+	for _, field := range fields {
+		field := field
+		config := &graphql.Field{
+			Type:        b.typeOf(field.Type),
+			Description: field.Description,
+		}
+
+		target, ok := b.targets[field.Method+" "+field.Path]
+		if !ok {
+			// Placeholder query of services without read-style operations
+			config.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+				return b.model.Name, nil
+			}
+			rootFields[field.Name] = config
+			continue
+		}
+
+		config.Args = graphql.FieldConfigArgument{}
+		for _, arg := range field.Args {
+			config.Args[arg.Name] = &graphql.ArgumentConfig{Type: b.typeOf(arg.Type)}
+		}
+		config.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+			body := map[string]interface{}{}
+			if len(field.Args) > 0 {
+				if input, ok := b.toJSON(p.Args["input"], field.Args[0].Type.Name).(map[string]interface{}); ok {
+					body = input
+				}
+			}
+			return target.resolve(p.Context, body)
+		}
+		rootFields[field.Name] = config
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{Name: name, Fields: rootFields})
+}
+
+// typeOf returns the GraphQL type of a reference, building the declared
+// types on first use
+func (b *graphQLBuilder) typeOf(ref exporter.GraphQLTypeRef) graphql.Type {
+	t, ok := b.types[ref.Name]
+	if !ok {
+		t = b.declare(b.decls[ref.Name])
+		b.types[ref.Name] = t
+	}
+
+	if ref.List {
+		if ref.ItemNonNull {
+			t = graphql.NewNonNull(t)
+		}
+		t = graphql.NewList(t)
+	}
+	if ref.NonNull {
+		t = graphql.NewNonNull(t)
+	}
+	return t
+}
+
+// declare builds an object, input, union or enum type
+func (b *graphQLBuilder) declare(decl exporter.GraphQLType) graphql.Type {
+	if len(decl.Values) > 0 {
+		values := graphql.EnumValueConfigMap{}
+		for _, value := range decl.Values {
+			values[value.Name] = &graphql.EnumValueConfig{Value: value.Value}
+		}
+		return graphql.NewEnum(graphql.EnumConfig{Name: decl.Name, Values: values})
+	}
+	if len(decl.Members) > 0 {
+		return b.union(decl)
+	}
+	if decl.Input {
+		fields := graphql.InputObjectConfigFieldMap{}
+		for _, field := range decl.Fields {
+			fields[field.Name] = &graphql.InputObjectFieldConfig{Type: b.typeOf(field.Type)}
+		}
+		return graphql.NewInputObject(graphql.InputObjectConfig{Name: decl.Name, Fields: fields})
+	}
+
+	fields := graphql.Fields{}
+	for _, field := range decl.Fields {
+		field := field
+		fields[field.Name] = &graphql.Field{
+			Type: b.typeOf(field.Type),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				source, ok := p.Source.(map[string]interface{})
+				if !ok {
+					return nil, nil
+				}
+				value := source[field.JSONName]
+				// A repeated element that occurs once converts to a single value
+				if _, isList := value.([]interface{}); field.Type.List && value != nil && !isList {
+					value = []interface{}{value}
+				}
+				return value, nil
+			},
+		}
+	}
+	return graphql.NewObject(graphql.ObjectConfig{Name: decl.Name, Fields: fields})
+}
+
+// union builds a union type, resolving values to the member of their
+// discriminator value
+func (b *graphQLBuilder) union(decl exporter.GraphQLType) *graphql.Union {
+	members := make([]*graphql.Object, len(decl.Members))
+	byValue := make(map[string]*graphql.Object)
+	for i, member := range decl.Members {
+		members[i], _ = b.typeOf(exporter.GraphQLTypeRef{Name: member.Type}).(*graphql.Object)
+		if member.Value != "" {
+			byValue[member.Value] = members[i]
+		}
+	}
+	return graphql.NewUnion(graphql.UnionConfig{
+		Name:  decl.Name,
+		Types: members,
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+			if source, ok := p.Value.(map[string]interface{}); ok {
+				if member, ok := byValue[fmt.Sprint(source[decl.Discriminator])]; ok {
+					return member
+				}
+			}
+			return members[0]
+		},
+	})
+}
+
+// toJSON converts an input value keyed by GraphQL field names to the REST
+// body keyed by property names
+func (b *graphQLBuilder) toJSON(value interface{}, typeName string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		decl, ok := b.decls[typeName]
+		if !ok {
+			return v
+		}
+		body := make(map[string]interface{}, len(v))
+		for _, field := range decl.Fields {
+			if fieldValue, ok := v[field.Name]; ok {
+				body[field.JSONName] = b.toJSON(fieldValue, field.Type.Name)
+			}
+		}
+		return body
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = b.toJSON(item, typeName)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// jsonScalar passes values without a known structure through as JSON
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        exporter.GraphQLJSON,
+	Description: "Arbitrary JSON value",
+	Serialize:   func(value interface{}) interface{} { return value },
+	ParseValue:  func(value interface{}) interface{} { return value },
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return literalValue(valueAST)
+	},
+})
+
+// longScalar holds 64-bit integers, which GraphQL's Int can't represent
+var longScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        exporter.GraphQLLong,
+	Description: "64-bit integer",
+	Serialize:   toInt64,
+	ParseValue:  toInt64,
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if v, ok := valueAST.(*ast.IntValue); ok {
+			return toInt64(v.Value)
+		}
+		return nil
+	},
+})
+
+// toInt64 converts a JSON number or numeric string to an int64, or nil
+func toInt64(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// literalValue converts a literal in a query to its JSON value
+func literalValue(valueAST ast.Value) interface{} {
+	switch v := valueAST.(type) {
+	case *ast.ObjectValue:
+		object := make(map[string]interface{}, len(v.Fields))
+		for _, field := range v.Fields {
+			object[field.Name.Value] = literalValue(field.Value)
+		}
+		return object
+	case *ast.ListValue:
+		list := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			list[i] = literalValue(item)
+		}
+		return list
+	case *ast.IntValue:
+		if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			return n
+		}
+		return v.Value
+	case *ast.FloatValue:
+		if f, err := strconv.ParseFloat(v.Value, 64); err == nil {
+			return f
+		}
+		return v.Value
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.StringValue:
+		return v.Value
+	case *ast.EnumValue:
+		return v.Value
+	default:
+		return nil
+	}
+}
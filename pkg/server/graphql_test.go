@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestGraphQLEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var soapRequest string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		soapRequest = string(body)
+		if strings.Contains(soapRequest, "<b>0</b>") {
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <soap:Fault><faultcode>soap:Client</faultcode><faultstring>division by zero</faultstring></soap:Fault>
+</soap:Body></soap:Envelope>`))
+			return
+		}
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <GetQuotientResponse xmlns="urn:calc"><result>4</result></GetQuotientResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Calc",
+		TargetNamespace: "urn:calc",
+		Types: []models.Type{
+			{Name: "GetQuotient", Elements: []models.Element{{Name: "a", Type: "xs:int"}, {Name: "b", Type: "xs:int"}}},
+			{Name: "GetQuotientResponse", Elements: []models.Element{{Name: "result", Type: "xs:int"}}},
+		},
+		Elements: []models.Element{
+			{Name: "GetQuotient", Type: "tns:GetQuotient"},
+			{Name: "GetQuotientResponse", Type: "tns:GetQuotientResponse"},
+		},
+		Messages: []models.Message{
+			{Name: "GetQuotientIn", Parts: []models.Part{{Name: "parameters", Element: "tns:GetQuotient"}}},
+			{Name: "GetQuotientOut", Parts: []models.Part{{Name: "parameters", Element: "tns:GetQuotientResponse"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{{
+			Name:   "GetQuotient",
+			Input:  models.Message{Name: "tns:GetQuotientIn"},
+			Output: models.Message{Name: "tns:GetQuotientOut"},
+		}}}},
+	}
+
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetGraphQL(true)
+	s.setupRoutes()
+
+	query := func(q string) map[string]interface{} {
+		body, _ := json.Marshal(graphQLRequest{Query: q})
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("invalid response %s: %v", w.Body, err)
+		}
+		return result
+	}
+
+	result := query(`{ getQuotient(input: {a: 8, b: 2}) { result } }`)
+	data, _ := json.Marshal(result["data"])
+	if string(data) != `{"getQuotient":{"result":4}}` {
+		t.Errorf("data = %s, errors %v", data, result["errors"])
+	}
+	if !strings.Contains(soapRequest, "<a>8</a>") {
+		t.Errorf("unexpected SOAP request: %s", soapRequest)
+	}
+
+	result = query(`{ getQuotient(input: {a: 8, b: 0}) { result } }`)
+	errs, _ := json.Marshal(result["errors"])
+	if !strings.Contains(string(errs), `"code":"soap:Client"`) {
+		t.Errorf("expected fault code extension, got %s", errs)
+	}
+}
+
+func TestGraphQLUnionsAndEnums(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var soapRequest string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		soapRequest = string(body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><soap:Body>
+  <GetVehicleResponse xmlns="urn:fleet" xmlns:f="urn:fleet">
+    <vehicle xsi:type="f:Car"><plate>AB-12</plate><status>in-repair</status><doors>4</doors></vehicle>
+  </GetVehicleResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Fleet",
+		TargetNamespace: "urn:fleet",
+		Types: []models.Type{
+			{Name: "Vehicle", Elements: []models.Element{{Name: "plate", Type: "xs:string"}, {Name: "status", Type: "tns:Status"}}},
+			{Name: "Car", Base: "tns:Vehicle", Derivation: models.DerivationExtension, Elements: []models.Element{
+				{Name: "plate", Type: "xs:string"}, {Name: "status", Type: "tns:Status"}, {Name: "doors", Type: "xs:int"},
+			}},
+			{Name: "GetVehicleResponse", Elements: []models.Element{{Name: "vehicle", Type: "tns:Vehicle"}}},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"active", "in-repair"}}},
+		Elements:    []models.Element{{Name: "GetVehicleResponse", Type: "tns:GetVehicleResponse"}},
+		Messages: []models.Message{
+			{Name: "GetVehicleIn", Parts: []models.Part{{Name: "status", Type: "tns:Status"}}},
+			{Name: "GetVehicleOut", Parts: []models.Part{{Name: "parameters", Element: "tns:GetVehicleResponse"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{{
+			Name:   "GetVehicle",
+			Input:  models.Message{Name: "tns:GetVehicleIn"},
+			Output: models.Message{Name: "tns:GetVehicleOut"},
+		}}}},
+	}
+
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetGraphQL(true)
+	s.setupRoutes()
+
+	// Enum arguments are sent as their WSDL values, and the union member is
+	// chosen by xsi:type
+	body, _ := json.Marshal(graphQLRequest{Query: `{ getVehicle(input: {status: in_repair}) {
+  vehicle { __typename ... on GetVehicleResultVehicleCar { doors status } }
+} }`})
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	want := `{"data":{"getVehicle":{"vehicle":{"__typename":"GetVehicleResultVehicleCar","doors":4,"status":"in_repair"}}}}`
+	if w.Body.String() != want {
+		t.Errorf("response = %s, want %s", w.Body, want)
+	}
+	if !strings.Contains(soapRequest, "<status>in-repair</status>") {
+		t.Errorf("unexpected SOAP request: %s", soapRequest)
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// soapHeaderPrefix starts the names of the HTTP headers sent as SOAP
+// header blocks
+const soapHeaderPrefix = "X-Soap-Header-"
+
+// headersField is the reserved JSON field of SOAP header blocks
+const headersField = "_headers"
+
+// headerBlock is a SOAP header block of a request: an element of the
+// target namespace, or of the namespace of its {namespace}name key
+type headerBlock struct {
+	name  string
+	value interface{}
+}
+
+// headerBlocksKey is the context key of the header blocks of the inbound
+// HTTP request
+type headerBlocksKey struct{}
+
+// withHeaderBlocks returns a context carrying the header blocks of the
+// X-Soap-Header-* headers of r. The words of the header name make the
+// element name: X-Soap-Header-Session-Token is sent as SessionToken.
+func withHeaderBlocks(ctx context.Context, r *http.Request) context.Context {
+	var blocks []headerBlock
+	for key, values := range r.Header {
+		if !strings.HasPrefix(key, soapHeaderPrefix) || len(key) == len(soapHeaderPrefix) {
+			continue
+		}
+		name := strings.ReplaceAll(key[len(soapHeaderPrefix):], "-", "")
+		for _, value := range values {
+			blocks = append(blocks, headerBlock{name: name, value: value})
+		}
+	}
+	if len(blocks) == 0 {
+		return ctx
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].name < blocks[j].name })
+	return context.WithValue(ctx, headerBlocksKey{}, blocks)
+}
+
+// headerBlocks returns the header blocks of ctx and of the _headers field
+// of a request body, and the body without the field
+func headerBlocks(ctx context.Context, request map[string]interface{}) ([]headerBlock, map[string]interface{}, error) {
+	blocks, _ := ctx.Value(headerBlocksKey{}).([]headerBlock)
+	body := request
+	if field, ok := request[headersField]; ok {
+		headers, ok := field.(map[string]interface{})
+		if !ok {
+			return nil, nil, &validationError{fields: []FieldError{{Field: headersField, Message: "must be an object of header blocks"}}}
+		}
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		blocks = append([]headerBlock(nil), blocks...)
+		for _, name := range names {
+			blocks = append(blocks, headerBlock{name: name, value: headers[name]})
+		}
+
+		body = make(map[string]interface{}, len(request)-1)
+		for key, value := range request {
+			if key != headersField {
+				body[key] = value
+			}
+		}
+	}
+
+	var invalid []FieldError
+	for _, block := range blocks {
+		if _, _, ok := block.qualifiedName(); !ok {
+			invalid = append(invalid, FieldError{Field: headersField, Message: fmt.Sprintf("%q is not an XML element name", block.name)})
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, nil, &validationError{fields: invalid}
+	}
+	return blocks, body, nil
+}
+
+// qualifiedName returns the namespace of the {namespace}name key of a
+// block, empty for the target namespace, and its element name
+func (b headerBlock) qualifiedName() (namespace, name string, ok bool) {
+	name = b.name
+	if strings.HasPrefix(name, "{") {
+		end := strings.Index(name, "}")
+		if end < 0 {
+			return "", "", false
+		}
+		namespace, name = name[1:end], name[end+1:]
+	}
+	return namespace, name, sample.IsXMLName(name)
+}
+
+// encodeHeaderBlocks writes header blocks as XML elements qualified by
+// namespace, or by the namespace of their {namespace}name keys. Objects
+// become child elements, arrays repeated elements, "@name" keys attributes
+// and "#text" keys text, as responses are converted to JSON.
+func encodeHeaderBlocks(blocks []headerBlock, namespace string) (string, error) {
+	var b strings.Builder
+	for _, block := range blocks {
+		ns, name, ok := block.qualifiedName()
+		if !ok {
+			return "", fmt.Errorf("invalid SOAP header name %q", block.name)
+		}
+		if ns == "" {
+			ns = namespace
+		}
+		if err := (sample.Encoder{}).Element(&b, name, ` xmlns="`+sample.Escape(ns)+`"`, "", block.value); err != nil {
+			return "", fmt.Errorf("SOAP header %s: %w", name, err)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// Limits configures throttling of operation requests. Zero values disable
+// the corresponding limit.
+type Limits struct {
+	GlobalRate  float64 // Requests per second across all operations
+	GlobalBurst int     // Token bucket size for GlobalRate (default: the rate)
+	RouteRate   float64 // Requests per second per operation
+	RouteBurst  int     // Token bucket size for RouteRate (default: the rate)
+	MaxInFlight int     // Concurrent backend SOAP calls
+}
+
+// throttle enforces Limits. It is shared by all services of a multi-WSDL
+// server so the global limits apply across them.
+type throttle struct {
+	limits   Limits
+	global   *rate.Limiter
+	inFlight chan struct{}
+}
+
+// SetLimits enables rate limiting and concurrency caps. Requests over a limit
+// get 429 Too Many Requests with a Retry-After header.
+func (s *Server) SetLimits(limits Limits) {
+	t := &throttle{limits: limits}
+	if limits.GlobalRate > 0 {
+		t.global = newLimiter(limits.GlobalRate, limits.GlobalBurst)
+	}
+	if limits.MaxInFlight > 0 {
+		t.inFlight = make(chan struct{}, limits.MaxInFlight)
+	}
+	s.throttle = t
+}
+
+// newLimiter creates a token bucket limiter, defaulting the burst to the rate
+func newLimiter(rps float64, burst int) *rate.Limiter {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rps)))
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// middleware returns a handler enforcing the limits for a single route
+func (t *throttle) middleware() gin.HandlerFunc {
+	var route *rate.Limiter
+	if t.limits.RouteRate > 0 {
+		route = newLimiter(t.limits.RouteRate, t.limits.RouteBurst)
+	}
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		var reservations []*rate.Reservation
+		for _, limiter := range []*rate.Limiter{t.global, route} {
+			if limiter != nil {
+				reservations = append(reservations, limiter.ReserveN(now, 1))
+			}
+		}
+
+		// Reject when any bucket is empty, returning the other tokens
+		var wait time.Duration
+		for _, r := range reservations {
+			if !r.OK() {
+				wait = time.Second
+			} else if d := r.DelayFrom(now); d > wait {
+				wait = d
+			}
+		}
+		if wait > 0 {
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			tooManyRequests(c, wait)
+			return
+		}
+
+		if t.inFlight != nil {
+			select {
+			case t.inFlight <- struct{}{}:
+				defer func() { <-t.inFlight }()
+			default:
+				tooManyRequests(c, time.Second)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// tooManyRequests aborts the request with 429 and a Retry-After in seconds
+func tooManyRequests(c *gin.Context, wait time.Duration) {
+	setRetryAfter(c, wait)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":   "Too many requests",
+		"details": "rate limit exceeded, retry later",
+	})
+}
+
+// setRetryAfter sets the Retry-After header to wait, rounded up to seconds
+func setRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
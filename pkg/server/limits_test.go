@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestThrottleRouteRate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{}
+	s.SetLimits(Limits{RouteRate: 1, RouteBurst: 2})
+
+	router := gin.New()
+	router.POST("/api/Add", s.throttle.middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	codes := make([]int, 3)
+	var retryAfter string
+	for i := range codes {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Add", nil))
+		codes[i] = w.Code
+		retryAfter = w.Header().Get("Retry-After")
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("unexpected status codes %v", codes)
+	}
+	if retryAfter != "1" {
+		t.Errorf("Retry-After = %q, want \"1\"", retryAfter)
+	}
+}
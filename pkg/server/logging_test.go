@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var backendID string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backendID = r.Header.Get(RequestIDHeader)
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	var logs bytes.Buffer
+	s := NewServer(pingDefinitions, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	s.setupRoutes()
+
+	tests := []struct {
+		name    string
+		path    string
+		inbound string // X-Request-ID of the request
+		status  int
+		level   string
+	}{
+		{"inbound ID", "/api/Ping", "trace-1", http.StatusOK, "INFO"},
+		{"generated ID", "/api/Ping", "", http.StatusOK, "INFO"},
+		{"client error", "/api/Missing", "trace-2", http.StatusNotFound, "WARN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			backendID = ""
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{}`))
+			if tt.inbound != "" {
+				req.Header.Set(RequestIDHeader, tt.inbound)
+			}
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("POST %s = %d %s, want %d", tt.path, w.Code, w.Body, tt.status)
+			}
+
+			// The response and the backend call carry the ID of the request
+			id := w.Header().Get(RequestIDHeader)
+			if tt.inbound != "" && id != tt.inbound || tt.inbound == "" && !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(id) {
+				t.Errorf("%s = %q, want the inbound or a new ID", RequestIDHeader, id)
+			}
+			if tt.status == http.StatusOK && backendID != id {
+				t.Errorf("the backend got %s %q, want %q", RequestIDHeader, backendID, id)
+			}
+
+			// and so do the log records, one per line
+			var messages []string
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				var record map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("log line %q isn't JSON: %v", line, err)
+				}
+				if record["request_id"] != id {
+					t.Errorf("log record %s has another request ID than %s", line, id)
+				}
+				if record["msg"] == "request" && (record["level"] != tt.level || record["status"] != float64(tt.status) || record["path"] != tt.path) {
+					t.Errorf("request log record = %s, want level %s and status %d", line, tt.level, tt.status)
+				}
+				messages = append(messages, record["msg"].(string))
+			}
+			want := []string{"request"}
+			if tt.status == http.StatusOK {
+				want = []string{"SOAP call", "request"}
+			}
+			if strings.Join(messages, ",") != strings.Join(want, ",") {
+				t.Errorf("log messages = %v, want %v", messages, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// MinimalRequest returns the smallest valid input of an operation, as the
+// JSON body of its REST route: the required fields only, each with a sample
+// value of its type, the first member of required choices and the first
+// value of enumerations. It returns nil when the operation's input can't be
+// resolved.
+func (s *Server) MinimalRequest(operation string) map[string]interface{} {
+	m := &minimalBuilder{v: &requestValidator{def: s.definitions}, visiting: make(map[string]bool)}
+
+	if elem := s.inputElement(operation); elem != nil {
+		t := m.v.complexType(models.LocalName(elem.Type))
+		if t == nil {
+			return nil
+		}
+		return m.object(t)
+	}
+
+	msg := s.inputMessage(operation)
+	if msg == nil {
+		return nil
+	}
+	request := make(map[string]interface{})
+	for _, part := range msg.Parts {
+		xsdType := part.Type
+		if part.Element != "" {
+			xsdType = m.v.def.ElementType(part.Element)
+		}
+		request[part.Name] = m.value(part.Name, xsdType)
+	}
+	return request
+}
+
+// minimalBuilder builds minimal values, resolving types like the validator
+// they must pass
+type minimalBuilder struct {
+	v        *requestValidator
+	visiting map[string]bool // Complex types being built, to stop recursion
+}
+
+// object returns the required fields of a complex type
+func (m *minimalBuilder) object(t *models.Type) map[string]interface{} {
+	m.visiting[t.Name] = true
+	defer delete(m.visiting, t.Name)
+
+	obj := make(map[string]interface{})
+	chosen := make(map[int]bool)
+	for _, elem := range t.Elements {
+		if elem.Choice > 0 {
+			// The first member of a required choice
+			if chosen[elem.Choice] || t.Choices[elem.Choice-1].MinOccurs == "0" {
+				continue
+			}
+			chosen[elem.Choice] = true
+		} else if elem.MinOccurs == "0" {
+			continue
+		}
+		value := m.value(elem.Name, elem.Type)
+		if repeated(elem) {
+			value = []interface{}{value}
+		}
+		obj[elem.Name] = value
+	}
+	for _, attr := range t.Attributes {
+		if attr.Use == "required" {
+			obj["@"+attr.Name] = m.value(attr.Name, attr.Type)
+		}
+	}
+	if t.SimpleContent != "" {
+		obj["#text"] = m.value(t.Name, t.SimpleContent)
+	}
+	return obj
+}
+
+// value returns a sample value of an XSD type; strings are named after the
+// field. Recursive types are left empty.
+func (m *minimalBuilder) value(name, xsdType string) interface{} {
+	typeName := models.LocalName(xsdType)
+	if t := m.v.complexType(typeName); t != nil {
+		if m.visiting[typeName] {
+			return map[string]interface{}{}
+		}
+		return m.object(t)
+	}
+
+	return sample.Value(m.v.def, xsdType, name)
+}
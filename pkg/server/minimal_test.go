@@ -0,0 +1,77 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestMinimalRequest(t *testing.T) {
+	def := &models.Definitions{
+		Messages: []models.Message{
+			{Name: "AddIn", Parts: []models.Part{{Name: "intA", Type: "xs:int"}, {Name: "note", Type: "xs:string"}}},
+			{Name: "OrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrder"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "Add", Input: models.Message{Name: "tns:AddIn"}},
+			{Name: "PlaceOrder", Input: models.Message{Name: "tns:OrderIn"}},
+		}}},
+		Elements: []models.Element{{Name: "PlaceOrder", Type: "tns:PlaceOrder"}},
+		Types: []models.Type{
+			{
+				Name: "PlaceOrder",
+				Elements: []models.Element{
+					{Name: "status", Type: "tns:Status"},
+					{Name: "items", Type: "tns:Item", MaxOccurs: "unbounded"},
+					{Name: "comment", Type: "xs:string", MinOccurs: "0"},
+					{Name: "card", Type: "xs:string", Choice: 1},
+					{Name: "iban", Type: "xs:string", Choice: 1},
+				},
+				Attributes: []models.Attribute{
+					{Name: "priority", Type: "xs:boolean"},
+					{Name: "placed", Type: "xs:dateTime", Use: "required"},
+				},
+				Choices: []models.Choice{{}},
+			},
+			{
+				Name: "Item",
+				Elements: []models.Element{
+					{Name: "sku", Type: "xs:string"},
+					{Name: "price", Type: "xs:decimal"},
+					{Name: "parent", Type: "tns:Item"},
+				},
+			},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"NEW", "PAID"}}},
+	}
+	s := NewServer(def, "localhost", 0)
+
+	tests := []struct {
+		operation string
+		want      map[string]interface{}
+	}{
+		{"Add", map[string]interface{}{"intA": int64(42), "note": "note"}},
+		{"PlaceOrder", map[string]interface{}{
+			"status": "NEW",
+			"items": []interface{}{map[string]interface{}{
+				"sku":    "sku",
+				"price":  "19.99",
+				"parent": map[string]interface{}{},
+			}},
+			"card":    "card",
+			"@placed": "2024-01-15T10:30:00Z",
+		}},
+		{"Ping", nil},
+	}
+	for _, tt := range tests {
+		got := s.MinimalRequest(tt.operation)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MinimalRequest(%s) = %v, want %v", tt.operation, got, tt.want)
+		}
+	}
+
+	if errs := s.validateRequest("Add", s.MinimalRequest("Add")); len(errs) > 0 {
+		t.Errorf("MinimalRequest(Add) is invalid: %v", errs)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// bindParams returns the input fields a request carries outside the JSON
+// body: the path parameters of route and, for GET and DELETE routes, the
+// query string. Values are converted to the XSD types of the fields, and
+// repeated query parameters become lists like JSON arrays.
+func (s *Server) bindParams(operation string, route routes.Route, c *gin.Context) (map[string]interface{}, error) {
+	values := make(map[string][]string)
+	if !route.HasBody() {
+		for name, v := range c.Request.URL.Query() {
+			values[name] = v
+		}
+	}
+	for _, name := range route.PathParams() {
+		values[name] = []string{c.Param(name)}
+	}
+
+	types := sample.FieldTypes(s.definitions, operation, false)
+	params := make(map[string]interface{}, len(values))
+	for name, texts := range values {
+		// Parameters may name attributes without their @
+		if _, ok := types[name]; !ok {
+			if _, ok := types["@"+name]; ok {
+				name = "@" + name
+			}
+		}
+		list := make([]interface{}, len(texts))
+		for i, text := range texts {
+			v, err := parseParam(types[name], text, s.decimalStrings)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", name, err)
+			}
+			list[i] = v
+		}
+		if len(list) == 1 {
+			params[name] = list[0]
+		} else {
+			params[name] = list
+		}
+	}
+	return params, nil
+}
+
+// parseParam converts the text of a parameter to the Go value of its XSD
+// type. Unlike response values, parameters that don't parse are rejected
+// rather than forwarded to the SOAP service.
+func parseParam(xsdType, text string, decimalStrings bool) (interface{}, error) {
+	switch t := models.LocalName(xsdType); {
+	case integerTypes[t]:
+		v, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", text, t)
+		}
+		return v, nil
+	case t == "decimal" || t == "float" || t == "double":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", text, t)
+		}
+		if t == "decimal" && decimalStrings {
+			return text, nil
+		}
+		return v, nil
+	case t == "boolean":
+		v, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid boolean", text)
+		}
+		return v, nil
+	}
+	return text, nil
+}
@@ -0,0 +1,34 @@
+package server
+
+import "testing"
+
+func TestParseParam(t *testing.T) {
+	tests := []struct {
+		xsdType, text  string
+		decimalStrings bool
+		want           interface{}
+		wantErr        bool
+	}{
+		{"xs:int", "42", false, int64(42), false},
+		{"xsd:unsignedShort", "-1", false, int64(-1), false},
+		{"xs:long", "4x", false, nil, true},
+		{"xs:double", "1.5", false, 1.5, false},
+		{"xs:decimal", "10.10", true, "10.10", false},
+		{"xs:decimal", "ten", true, nil, true},
+		{"xs:boolean", "TRUE", false, true, false},
+		{"xs:boolean", "yes", false, nil, true},
+		{"xs:string", "007", false, "007", false},
+		{"", "free text", false, "free text", false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseParam(tt.xsdType, tt.text, tt.decimalStrings)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseParam(%q, %q) error = %v, wantErr %v", tt.xsdType, tt.text, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseParam(%q, %q) = %#v, want %#v", tt.xsdType, tt.text, got, tt.want)
+		}
+	}
+}
@@ -2,14 +2,26 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/addressing"
+	"github.com/thdev01/wsdl2api/pkg/charset"
+	"github.com/thdev01/wsdl2api/pkg/compression"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/sample"
+	"github.com/thdev01/wsdl2api/pkg/security"
+	"github.com/thdev01/wsdl2api/pkg/transform"
 )
 
 // Server represents the REST API server
@@ -20,24 +32,170 @@ type Server struct {
 	router       *gin.Engine
 	soapEndpoint string
 	soapVersion  string
+	addressing   *addressing.WSAddressing
+	credentials  *Credentials
+	throttle     *throttle
+	breaker      *breaker
+	batch        Batch
+	logger       *slog.Logger
+	tlsCert      string
+	tlsKey       string
+	apiPath      string
+
+	// httpClient makes the backend SOAP calls over connections pooled
+	// with the pool and backendTLS settings
+	httpClient *http.Client
+	pool       Pool
+	backendTLS security.TLSOptions
+
+	// oauth2 provides the bearer tokens of backend calls when set
+	oauth2 *security.TokenSource
+
+	// spnego authenticates backend calls with Kerberos when set; its Base
+	// is replaced with the transport of httpClient
+	spnego *security.SPNEGOTransport
+
+	// compression is the content coding of backend requests, none when
+	// empty
+	compression string
+
+	// dump writes the envelopes of backend calls to files when set
+	dump *soapDump
+
+	// routes maps operations to REST methods and paths, POST /{Operation}
+	// when nil
+	routes *routes.Config
+
+	// transforms rewrite the JSON responses of operations
+	transforms transform.Config
+
+	// requestHook and responseHook rewrite the JSON requests and responses
+	// of operations when set
+	requestHook  Hook
+	responseHook Hook
+
+	// sessions keep a backend session per client when set
+	sessions *sessions
+
+	// decimalStrings returns xs:decimal values as JSON strings
+	decimalStrings bool
+
+	// skipValidation forwards requests without checking them against the
+	// input message schema
+	skipValidation bool
+
+	// graphql serves the operations at /graphql
+	graphql bool
+
+	// services are the WSDL services mounted by NewMultiServer
+	services []*Server
+
+	// routesOnce sets up the routes for Start or Handler
+	routesOnce sync.Once
 }
 
 // NewServer creates a new REST API server
 func NewServer(def *models.Definitions, host string, port int) *Server {
 	// Extract SOAP endpoint from definitions
 	soapEndpoint := ""
-	if len(def.Services) > 0 && len(def.Services[0].Ports) > 0 {
-		soapEndpoint = def.Services[0].Ports[0].Address
+	if port := soapPort(def); port != nil {
+		soapEndpoint = port.Address
 	}
 
-	return &Server{
+	s := &Server{
 		definitions:  def,
 		host:         host,
 		port:         port,
-		router:       gin.Default(),
+		router:       gin.New(),
 		soapEndpoint: soapEndpoint,
-		soapVersion:  "1.1", // Default to SOAP 1.1
+		soapVersion:  bindingSOAPVersion(def),
+		apiPath:      "/api",
 	}
+	s.router.Use(s.requestLogger(), gin.Recovery())
+	// The default settings always build a client
+	_ = s.setBackend(DefaultPool(), security.TLSOptions{})
+
+	return s
+}
+
+// NewMultiServer creates a REST API server fronting several WSDL services.
+// Operations of each service are served under /api/{service}/{operation}.
+func NewMultiServer(defs []*models.Definitions, host string, port int) (*Server, error) {
+	s := NewServer(&models.Definitions{Name: "wsdl2api"}, host, port)
+	// Each service uses the SOAP version of its binding unless SetSOAPVersion
+	// overrides it
+	s.soapVersion = ""
+
+	seen := make(map[string]bool)
+	for i, def := range defs {
+		name := serviceName(def, i)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate service name %q", name)
+		}
+		seen[name] = true
+
+		svc := &Server{
+			definitions: def,
+			host:        host,
+			port:        port,
+			soapVersion: bindingSOAPVersion(def),
+			apiPath:     "/api/" + name,
+		}
+		if port := soapPort(def); port != nil {
+			svc.soapEndpoint = port.Address
+		}
+		s.services = append(s.services, svc)
+	}
+
+	return s, nil
+}
+
+// soapPort returns the port backend calls go to: the first port with an
+// address that isn't bound to an HTTP GET or POST binding, as the ASMX
+// services that list those first have
+func soapPort(def *models.Definitions) *models.Port {
+	for i := range def.Services {
+		for j := range def.Services[i].Ports {
+			port := &def.Services[i].Ports[j]
+			if binding := findBinding(def, port.Binding); port.Address != "" && (binding == nil || binding.HTTPVerb == "") {
+				return port
+			}
+		}
+	}
+	return nil
+}
+
+// findBinding finds a binding by qualified name
+func findBinding(def *models.Definitions, name string) *models.Binding {
+	name = models.LocalName(name)
+	for i := range def.Bindings {
+		if def.Bindings[i].Name == name {
+			return &def.Bindings[i]
+		}
+	}
+	return nil
+}
+
+// bindingSOAPVersion returns the SOAP version of the binding of the port
+// backend calls go to, defaulting to 1.1
+func bindingSOAPVersion(def *models.Definitions) string {
+	if port := soapPort(def); port != nil {
+		if binding := findBinding(def, port.Binding); binding != nil && binding.SOAPVersion != "" {
+			return binding.SOAPVersion
+		}
+	}
+	return "1.1"
+}
+
+// serviceName returns the path segment a service is mounted under
+func serviceName(def *models.Definitions, index int) string {
+	if def.Name != "" {
+		return def.Name
+	}
+	if len(def.Services) > 0 && def.Services[0].Name != "" {
+		return def.Services[0].Name
+	}
+	return fmt.Sprintf("service%d", index+1)
 }
 
 // SetSOAPEndpoint sets a custom SOAP endpoint
@@ -45,50 +203,218 @@ func (s *Server) SetSOAPEndpoint(endpoint string) {
 	s.soapEndpoint = endpoint
 }
 
-// SetSOAPVersion sets the SOAP version (1.1 or 1.2)
+// SetRoutes sets the REST method and path of each operation. It fails when
+// the config doesn't match the operations of the WSDLs.
+func (s *Server) SetRoutes(cfg *routes.Config) error {
+	defs := []*models.Definitions{s.definitions}
+	if len(s.services) > 0 {
+		defs = defs[:0]
+		for _, svc := range s.services {
+			defs = append(defs, svc.definitions)
+		}
+	}
+	if err := cfg.Check(defs...); err != nil {
+		return err
+	}
+	s.routes = cfg
+	return nil
+}
+
+// SetTransforms rewrites the JSON responses of operations by the rules of
+// cfg: fields are dropped, masked and renamed. It fails for rules of
+// operations the WSDL doesn't declare.
+func (s *Server) SetTransforms(cfg transform.Config) error {
+	defs := []*models.Definitions{s.definitions}
+	if len(s.services) > 0 {
+		defs = defs[:0]
+		for _, svc := range s.services {
+			defs = append(defs, svc.definitions)
+		}
+	}
+	if err := cfg.Check(defs...); err != nil {
+		return err
+	}
+	s.transforms = cfg
+	return nil
+}
+
+// Hook rewrites the JSON request or response body of an operation, such
+// as the functions of scripts do. It returns the body to use.
+type Hook func(ctx context.Context, operation string, body map[string]interface{}) (map[string]interface{}, error)
+
+// SetHooks rewrites the JSON requests of operations with request, before
+// they are checked and converted to SOAP, and their responses with
+// response, after transforms. Either may be nil. GraphQL queries, whose
+// shape is fixed by the schema, don't run the hooks.
+func (s *Server) SetHooks(request, response Hook) {
+	s.requestHook = request
+	s.responseHook = response
+}
+
+// SetSOAPVersion sets the SOAP version (1.1 or 1.2), overriding the version
+// of the WSDL binding
 func (s *Server) SetSOAPVersion(version string) {
 	s.soapVersion = version
 }
 
+// SetDecimalsAsStrings returns xs:decimal values as JSON strings instead of
+// numbers, so that clients don't lose precision
+func (s *Server) SetDecimalsAsStrings(enabled bool) {
+	s.decimalStrings = enabled
+}
+
+// SetAddressing enables WS-Addressing headers on backend SOAP calls
+func (s *Server) SetAddressing(wsa *addressing.WSAddressing) {
+	s.addressing = wsa
+}
+
+// SetTLS serves HTTPS using the given certificate and key files
+func (s *Server) SetTLS(certFile, keyFile string) {
+	s.tlsCert = certFile
+	s.tlsKey = keyFile
+}
+
 // Start starts the REST API server
 func (s *Server) Start() error {
 	// Setup routes
-	s.setupRoutes()
+	s.routesOnce.Do(s.setupRoutes)
 
 	// Start server
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+	if s.tlsCert != "" {
+		return s.router.RunTLS(addr, s.tlsCert, s.tlsKey)
+	}
 	return s.router.Run(addr)
 }
 
+// Handler returns the routes of the server as an http.Handler, to mount
+// the proxy in another server instead of calling Start. The routes are set
+// up on the first call, so the server must be configured before.
+func (s *Server) Handler() http.Handler {
+	s.routesOnce.Do(s.setupRoutes)
+	return s.router
+}
+
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes() {
 	// Health check
 	s.router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
+			"status":  "healthy",
 			"service": s.definitions.Name,
 		})
 	})
 
-	// Service info
-	s.router.GET("/info", s.handleServiceInfo)
+	// OpenAPI spec and interactive docs
+	s.router.GET("/openapi.json", s.handleOpenAPI)
+	s.router.GET("/docs", s.handleDocs)
+	s.router.GET("/docs/*asset", s.handleDocsAsset)
+
+	if len(s.services) > 0 {
+		// Combined service info
+		s.router.GET("/info", s.handleMultiServiceInfo)
+
+		// Mount each service under its own prefix, sharing backend settings
+		for _, svc := range s.services {
+			if s.soapVersion != "" {
+				svc.soapVersion = s.soapVersion
+			}
+			svc.addressing = s.addressing
+			svc.credentials = s.credentials
+			svc.httpClient = s.httpClient
+			svc.oauth2 = s.oauth2
+			svc.spnego = s.spnego
+			svc.compression = s.compression
+			svc.dump = s.dump
+			svc.throttle = s.throttle
+			svc.breaker = s.breaker
+			svc.sessions = s.sessions
+			svc.batch = s.batch
+			svc.logger = s.logger
+			svc.decimalStrings = s.decimalStrings
+			svc.skipValidation = s.skipValidation
+			svc.routes = s.routes
+			svc.transforms = s.transforms
+			svc.requestHook = s.requestHook
+			svc.responseHook = s.responseHook
+			svc.registerOperations(s.router.Group(svc.apiPath))
+		}
+	} else {
+		// Service info
+		s.router.GET("/info", s.handleServiceInfo)
 
-	// API routes group
-	api := s.router.Group("/api")
+		// API routes group
+		s.registerOperations(s.router.Group(s.apiPath))
+	}
+
+	if s.graphql {
+		if err := s.registerGraphQL(); err != nil {
+			s.log().Error("GraphQL endpoint disabled", "error", err)
+		}
+	}
+}
 
-	// Generate routes for each operation in each port type
+// registerOperations registers the REST endpoints of every operation
+func (s *Server) registerOperations(api *gin.RouterGroup) {
+	// Generate routes for each operation in each port type. Operations
+	// repeated by other port types, such as the HttpGet and HttpPost port
+	// types of ASP.NET services, share the route of the first.
+	registered := make(map[string]bool)
+	taken := make(map[string]bool)
 	for _, portType := range s.definitions.PortTypes {
 		for _, op := range portType.Operations {
+			if registered[op.Name] {
+				continue
+			}
+			registered[op.Name] = true
+
 			// Create REST endpoint for SOAP operation
-			path := fmt.Sprintf("/%s", op.Name)
-			api.POST(path, s.createOperationHandler(op))
-			api.GET(path+"/info", s.createOperationInfoHandler(op))
+			route := s.routes.Route(op.Name)
+			taken[route.Method+" "+route.GinPath()] = true
+			if s.throttle != nil {
+				api.Handle(route.Method, route.GinPath(), s.throttle.middleware(), s.createOperationHandler(op, route))
+			} else {
+				api.Handle(route.Method, route.GinPath(), s.createOperationHandler(op, route))
+			}
+			api.GET("/"+op.Name+"/info", s.createOperationInfoHandler(op))
+		}
+	}
+
+	// The batch endpoint yields to an operation served at the same route
+	if !taken[http.MethodPost+" "+batchPath] {
+		if s.throttle != nil {
+			api.POST(batchPath, s.throttle.middleware(), s.handleBatch(registered))
+		} else {
+			api.POST(batchPath, s.handleBatch(registered))
 		}
 	}
 }
 
 // handleServiceInfo returns service information
 func (s *Server) handleServiceInfo(c *gin.Context) {
+	c.JSON(http.StatusOK, s.serviceInfo())
+}
+
+// handleMultiServiceInfo returns information about every mounted service
+func (s *Server) handleMultiServiceInfo(c *gin.Context) {
+	services := make([]gin.H, 0, len(s.services))
+	totalOperations := 0
+	for _, svc := range s.services {
+		info := svc.serviceInfo()
+		info["basePath"] = svc.apiPath
+		totalOperations += info["totalOperations"].(int)
+		services = append(services, info)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"services":        services,
+		"totalServices":   len(services),
+		"totalOperations": totalOperations,
+	})
+}
+
+// serviceInfo describes the service and its operations
+func (s *Server) serviceInfo() gin.H {
 	services := make([]gin.H, 0)
 	for _, svc := range s.definitions.Services {
 		ports := make([]gin.H, 0)
@@ -108,56 +434,77 @@ func (s *Server) handleServiceInfo(c *gin.Context) {
 	operations := make([]gin.H, 0)
 	for _, portType := range s.definitions.PortTypes {
 		for _, op := range portType.Operations {
+			route := s.routes.Route(op.Name)
 			operations = append(operations, gin.H{
 				"name":          op.Name,
 				"documentation": op.Documentation,
-				"endpoint":      fmt.Sprintf("/api/%s", op.Name),
-				"method":        "POST",
+				"endpoint":      s.apiPath + route.Path,
+				"method":        route.Method,
 			})
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	return gin.H{
 		"name":            s.definitions.Name,
 		"targetNamespace": s.definitions.TargetNamespace,
 		"services":        services,
 		"operations":      operations,
 		"totalOperations": len(operations),
-	})
+	}
 }
 
-// createOperationHandler creates a handler for a SOAP operation
-func (s *Server) createOperationHandler(op models.Operation) gin.HandlerFunc {
+// createOperationHandler creates a handler for a SOAP operation. The input
+// fields come from the JSON body, or the query string of GET and DELETE
+// routes, plus the path parameters of the route.
+func (s *Server) createOperationHandler(op models.Operation, route routes.Route) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Parse request body
 		var requestBody map[string]interface{}
-		if err := c.ShouldBindJSON(&requestBody); err != nil {
+		if route.HasBody() {
+			if err := c.ShouldBindJSON(&requestBody); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid request body",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+		if requestBody == nil {
+			requestBody = make(map[string]interface{})
+		}
+
+		// Query string and path parameters are text, typed by the input message
+		params, err := s.bindParams(op.Name, route, c)
+		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request body",
+				"error":   "Invalid parameter",
 				"details": err.Error(),
 			})
 			return
 		}
+		for name, value := range params {
+			requestBody[name] = value
+		}
 
-		// Find SOAP action for this operation
-		soapAction := ""
-		for _, binding := range s.definitions.Bindings {
-			for _, bindOp := range binding.Operations {
-				if bindOp.Name == op.Name {
-					soapAction = bindOp.SoapAction
-					break
-				}
-			}
+		// Resolve backend credentials from configuration or the inbound request
+		creds, err := s.resolveCredentials(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Basic realm="wsdl2api"`)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthorized",
+				"details": err.Error(),
+			})
+			return
 		}
 
 		// Make actual SOAP call
-		response, err := s.callSOAP(op.Name, soapAction, requestBody)
+		response, err := s.invokeOperation(s.requestContext(c.Request), op.Name, requestBody, creds)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":     "SOAP call failed",
-				"operation": op.Name,
-				"details":   err.Error(),
-			})
+			var open *circuitOpenError
+			if errors.As(err, &open) {
+				setRetryAfter(c, open.retryAfter)
+			}
+			c.JSON(operationError(op.Name, err))
 			return
 		}
 
@@ -170,26 +517,137 @@ func (s *Server) createOperationHandler(op models.Operation) gin.HandlerFunc {
 	}
 }
 
+// validationError is returned for requests that don't match the input
+// message of the operation
+type validationError struct {
+	fields []FieldError
+}
+
+// Error implements the error interface
+func (e *validationError) Error() string {
+	messages := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		messages[i] = f.Field + ": " + f.Message
+	}
+	return fmt.Sprintf("%d invalid fields: %s", len(e.fields), strings.Join(messages, "; "))
+}
+
+// requestContext returns the context of the operation calls of an inbound
+// request, carrying its SOAP header blocks and backend session
+func (s *Server) requestContext(r *http.Request) context.Context {
+	return s.sessions.withSession(withHeaderBlocks(r.Context(), r), r)
+}
+
+// hookError is returned when a request or response hook fails
+type hookError struct {
+	request bool
+	err     error
+}
+
+// Error implements the error interface
+func (e *hookError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the hook
+func (e *hookError) Unwrap() error {
+	return e.err
+}
+
+// invokeOperation runs the request hook, takes the SOAP header blocks out
+// of the request, checks it against the input message, calls the operation
+// on the backend and transforms its response
+func (s *Server) invokeOperation(ctx context.Context, operation string, request map[string]interface{}, creds *Credentials) (map[string]interface{}, error) {
+	if s.requestHook != nil {
+		var err error
+		if request, err = s.requestHook(ctx, operation, request); err != nil {
+			return nil, &hookError{request: true, err: err}
+		}
+	}
+	blocks, request, err := headerBlocks(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, headerBlocksKey{}, blocks)
+
+	// Reject requests that would serialize to XML the backend can't accept
+	if !s.skipValidation {
+		if errs := s.validateRequest(operation, request); len(errs) > 0 {
+			return nil, &validationError{fields: errs}
+		}
+	}
+	response, err := s.callSOAP(ctx, operation, s.definitions.SoapAction(operation), request, creds)
+	if err != nil {
+		return response, err
+	}
+	response = s.transforms.Apply(operation, response)
+	if s.responseHook != nil {
+		if response, err = s.responseHook(ctx, operation, response); err != nil {
+			return nil, &hookError{err: err}
+		}
+	}
+	return response, nil
+}
+
+// operationError returns the HTTP status and JSON body of a failed operation
+// call: the status of SOAP faults, 422 for invalid requests, 400 for
+// requests a hook rejects, 503 while the circuit breaker is open and 500
+// otherwise
+func operationError(operation string, err error) (int, interface{}) {
+	var hook *hookError
+	if errors.As(err, &hook) {
+		if hook.request {
+			return http.StatusBadRequest, gin.H{
+				"error":     "Request rejected",
+				"operation": operation,
+				"details":   err.Error(),
+			}
+		}
+		return http.StatusInternalServerError, gin.H{
+			"error":     "Response hook failed",
+			"operation": operation,
+			"details":   err.Error(),
+		}
+	}
+	var invalid *validationError
+	if errors.As(err, &invalid) {
+		return http.StatusUnprocessableEntity, gin.H{
+			"error":  "Invalid request",
+			"fields": invalid.fields,
+		}
+	}
+	var fault *Fault
+	if errors.As(err, &fault) {
+		return fault.HTTPStatus(), fault
+	}
+	var open *circuitOpenError
+	if errors.As(err, &open) {
+		return http.StatusServiceUnavailable, gin.H{
+			"error":     "Backend unavailable",
+			"operation": operation,
+			"details":   err.Error(),
+		}
+	}
+	return http.StatusInternalServerError, gin.H{
+		"error":     "SOAP call failed",
+		"operation": operation,
+		"details":   err.Error(),
+	}
+}
+
 // createOperationInfoHandler creates an info handler for an operation
 func (s *Server) createOperationInfoHandler(op models.Operation) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Find SOAP action
-		soapAction := ""
-		for _, binding := range s.definitions.Bindings {
-			for _, bindOp := range binding.Operations {
-				if bindOp.Name == op.Name {
-					soapAction = bindOp.SoapAction
-					break
-				}
-			}
-		}
+		// Find SOAP action and REST route
+		soapAction := s.definitions.SoapAction(op.Name)
+		route := s.routes.Route(op.Name)
 
 		// Find message details
 		inputParts := make([]gin.H, 0)
 		outputParts := make([]gin.H, 0)
 
 		for _, msg := range s.definitions.Messages {
-			if msg.Name == op.Input.Name {
+			if msg.Name == models.LocalName(op.Input.Name) {
 				for _, part := range msg.Parts {
 					inputParts = append(inputParts, gin.H{
 						"name":    part.Name,
@@ -198,7 +656,7 @@ func (s *Server) createOperationInfoHandler(op models.Operation) gin.HandlerFunc
 					})
 				}
 			}
-			if msg.Name == op.Output.Name {
+			if msg.Name == models.LocalName(op.Output.Name) {
 				for _, part := range msg.Parts {
 					outputParts = append(outputParts, gin.H{
 						"name":    part.Name,
@@ -209,12 +667,19 @@ func (s *Server) createOperationInfoHandler(op models.Operation) gin.HandlerFunc
 			}
 		}
 
+		curl := fmt.Sprintf(`curl -X %s http://%s:%d%s%s \
+  -H "Content-Type: application/json" \
+  -d '{"param": "value"}'`, route.Method, s.host, s.port, s.apiPath, route.Path)
+		if !route.HasBody() {
+			curl = fmt.Sprintf(`curl -X %s 'http://%s:%d%s%s?param=value'`, route.Method, s.host, s.port, s.apiPath, route.Path)
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"operation":     op.Name,
 			"documentation": op.Documentation,
 			"soapAction":    soapAction,
-			"endpoint":      fmt.Sprintf("/api/%s", op.Name),
-			"method":        "POST",
+			"endpoint":      s.apiPath + route.Path,
+			"method":        route.Method,
 			"input": gin.H{
 				"message": op.Input.Name,
 				"parts":   inputParts,
@@ -224,55 +689,139 @@ func (s *Server) createOperationInfoHandler(op models.Operation) gin.HandlerFunc
 				"parts":   outputParts,
 			},
 			"example": gin.H{
-				"curl": fmt.Sprintf(`curl -X POST http://%s:%d/api/%s \
-  -H "Content-Type: application/json" \
-  -d '{"param": "value"}'`, s.host, s.port, op.Name),
+				"curl": curl,
 			},
 		})
 	}
 }
 
 // callSOAP makes an actual SOAP call to the backend service
-func (s *Server) callSOAP(operation, soapAction string, requestParams map[string]interface{}) (map[string]interface{}, error) {
+func (s *Server) callSOAP(ctx context.Context, operation, soapAction string, requestParams map[string]interface{}, creds *Credentials) (map[string]interface{}, error) {
 	if s.soapEndpoint == "" {
 		return nil, fmt.Errorf("SOAP endpoint not configured")
 	}
 
 	// Build SOAP envelope (returns XML string)
-	xmlData := s.buildSOAPEnvelope(operation, requestParams)
+	blocks, _ := ctx.Value(headerBlocksKey{}).([]headerBlock)
+	xmlData, err := s.buildSOAPEnvelope(operation, soapAction, requestParams, blocks, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SOAP envelope: %w", err)
+	}
+	ex := exchangeFromContext(ctx)
+	if ex != nil {
+		ex.Request = xmlData
+	}
+	var dumpName string
+	if s.dump != nil {
+		dumpName = s.dump.next(operation)
+		s.dumpEnvelope(ctx, dumpName+"-request.xml", []byte(xmlData))
+	}
+
+	body := []byte(xmlData)
+	if s.compression != "" {
+		if body, err = compression.Compress(s.compression, body); err != nil {
+			return nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", s.soapEndpoint, bytes.NewBuffer([]byte(xmlData)))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.soapEndpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	if s.compression != "" {
+		req.Header.Set("Content-Encoding", s.compression)
+		req.Header.Set("Accept-Encoding", compression.AcceptEncoding)
+	}
 
 	// Set headers based on SOAP version
-	if s.soapVersion == "1.2" {
-		req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
-	} else {
-		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
-		if soapAction != "" {
-			req.Header.Set("SOAPAction", fmt.Sprintf(`"%s"`, soapAction))
+	req.Header.Set("Content-Type", soapContentType(s.soapVersion, soapAction))
+	if s.soapVersion != "1.2" && soapAction != "" {
+		req.Header.Set("SOAPAction", fmt.Sprintf(`"%s"`, soapAction))
+	}
+	if creds != nil && creds.Mode == CredentialsBasic {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	requestID := requestIDFromContext(ctx)
+	if requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	var bearer string
+	if s.oauth2 != nil {
+		if bearer, err = s.oauth2.Token(ctx); err != nil {
+			return nil, fmt.Errorf("failed to authenticate to the backend: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	sess := sessionFromContext(ctx)
+	sess.apply(req)
+
+	// Fail fast while the circuit of the backend is open
+	var circuitKey string
+	if s.breaker != nil {
+		circuitKey = s.breaker.key(s.soapEndpoint, operation)
+		if err := s.breaker.allow(circuitKey); err != nil {
+			return nil, err
 		}
 	}
 
 	// Make the call
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := s.backendClient(creds).Do(req)
 	if err != nil {
+		s.recordCall(ctx, circuitKey, true)
+		s.log().ErrorContext(ctx, "SOAP call failed",
+			"request_id", requestID, "operation", operation, "error", err)
 		return nil, fmt.Errorf("SOAP call failed: %w", err)
 	}
 	defer resp.Body.Close()
+	if s.oauth2 != nil && resp.StatusCode == http.StatusUnauthorized {
+		s.oauth2.Invalidate(bearer)
+	}
+	sess.capture(req, resp)
+
+	s.log().DebugContext(ctx, "SOAP call",
+		"request_id", requestID, "operation", operation, "endpoint", s.soapEndpoint,
+		"status", resp.StatusCode, "duration", time.Since(start))
 
 	// Read response
-	body, err := io.ReadAll(resp.Body)
+	if err := compression.Decompress(resp); err != nil {
+		s.recordCall(ctx, circuitKey, true)
+		return nil, err
+	}
+	if err := charset.Decode(resp); err != nil {
+		s.recordCall(ctx, circuitKey, true)
+		return nil, err
+	}
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
+		s.recordCall(ctx, circuitKey, true)
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	if ex != nil {
+		ex.Status, ex.Response = resp.StatusCode, body
+	}
+	if s.dump != nil {
+		s.dumpEnvelope(ctx, dumpName+"-response.xml", body)
+	}
+
+	// Surface SOAP faults so the handler can map them to HTTP statuses.
+	// Faults are answers of a working backend, so only other 5xx responses
+	// count as failures for the circuit breaker.
+	faultHints := newTypeHints(s.definitions, nil)
+	faultHints.decimalStrings = s.decimalStrings
+	fault := parseFault(body, faultHints)
+	s.recordCall(ctx, circuitKey, fault == nil && resp.StatusCode >= http.StatusInternalServerError)
+	if fault != nil {
+		return nil, fault
+	}
+	if contentType := resp.Header.Get("Content-Type"); !xmlContentType(contentType) {
+		return nil, fmt.Errorf("backend answered with status %d and Content-Type %s instead of a SOAP envelope", resp.StatusCode, contentType)
+	}
 
 	// Parse SOAP response
-	result, err := s.parseSOAPResponse(body)
+	result, err := s.parseSOAPResponse(operation, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse SOAP response: %w", err)
 	}
@@ -281,43 +830,52 @@ func (s *Server) callSOAP(operation, soapAction string, requestParams map[string
 }
 
 // buildSOAPEnvelope builds a SOAP envelope for the request
-func (s *Server) buildSOAPEnvelope(operation string, params map[string]interface{}) string {
-	// Build parameter XML elements
-	var paramsXML strings.Builder
-	for k, v := range params {
-		paramsXML.WriteString(fmt.Sprintf("<%s>%v</%s>", k, v, k))
+func (s *Server) buildSOAPEnvelope(operation, soapAction string, params map[string]interface{}, blocks []headerBlock, creds *Credentials) (string, error) {
+	targetNS := sample.TargetNamespace(s.definitions)
+
+	// Objects become child elements and lists repeated elements, as for
+	// header blocks, in the order of the schema sequence
+	body, err := sample.Encoder{Def: s.definitions}.Body(operation, false, s.soapVersion, params)
+	if err != nil {
+		return "", err
 	}
 
-	// Get target namespace from definitions
-	targetNS := s.definitions.TargetNamespace
-	if targetNS == "" {
-		targetNS = "http://tempuri.org/"
+	// Build WS-Addressing header blocks if enabled
+	headerXML, err := addressing.NewAddressingHeader(s.addressing, s.soapEndpoint, soapAction).Marshal()
+	if err != nil {
+		return "", err
 	}
 
-	if s.soapVersion == "1.2" {
-		return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
-<soap12:Envelope xmlns:soap12="http://www.w3.org/2003/05/soap-envelope" xmlns:tns="%s">
-  <soap12:Body>
-    <tns:%s>%s</tns:%s>
-  </soap12:Body>
-</soap12:Envelope>`, targetNS, operation, paramsXML.String(), operation)
+	// Add the WS-Security header when credentials use it
+	securityXML, err := securityHeader(creds)
+	if err != nil {
+		return "", err
+	}
+	headerXML = securityXML + headerXML
+
+	// Add the header blocks of the request
+	blocksXML, err := encodeHeaderBlocks(blocks, targetNS)
+	if err != nil {
+		return "", err
 	}
+	headerXML += blocksXML
 
-	// SOAP 1.1
-	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
-<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:tns="%s">
-  <soap:Body>
-    <tns:%s>%s</tns:%s>
-  </soap:Body>
-</soap:Envelope>`, targetNS, operation, paramsXML.String(), operation)
+	return sample.Envelope(targetNS, s.soapVersion, headerXML, body), nil
 }
 
-// parseSOAPResponse parses a SOAP response and extracts the result
-func (s *Server) parseSOAPResponse(xmlData []byte) (map[string]interface{}, error) {
+// inputElement returns the global element a document style operation
+// sends, or nil when the input message is not a single element part
+func (s *Server) inputElement(operation string) *models.Element {
+	return sample.MessageElement(s.definitions, operation, false)
+}
+
+// parseSOAPResponse parses a SOAP response and converts the body to JSON.
+// The content of a single response wrapper element is returned directly.
+func (s *Server) parseSOAPResponse(operation string, xmlData []byte) (map[string]interface{}, error) {
 	// Generic SOAP envelope structure
 	var envelope struct {
 		Body struct {
-			Content string `xml:",innerxml"`
+			Content []byte `xml:",innerxml"`
 		} `xml:"Body"`
 	}
 
@@ -325,18 +883,27 @@ func (s *Server) parseSOAPResponse(xmlData []byte) (map[string]interface{}, erro
 		return nil, fmt.Errorf("failed to unmarshal SOAP envelope: %w", err)
 	}
 
-	// Try to parse the body content as JSON-friendly map
-	result := make(map[string]interface{})
-
-	// Simple XML to map conversion (can be enhanced)
-	bodyContent := strings.TrimSpace(envelope.Body.Content)
-	if bodyContent != "" {
-		// For now, return the raw XML in the response
-		result["xml"] = bodyContent
+	nodes, err := decodeXML(envelope.Body.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode SOAP body: %w", err)
+	}
 
-		// Try to extract values (basic implementation)
-		result["raw"] = bodyContent
+	hints := newTypeHints(s.definitions, s.outputMessage(operation))
+	hints.decimalStrings = s.decimalStrings
+	result := make(map[string]interface{})
+	if len(nodes) == 1 {
+		if obj, ok := hints.toJSON(nodes[0]).(map[string]interface{}); ok {
+			return obj, nil
+		}
+	}
+	for _, node := range nodes {
+		result[node.name] = hints.toJSON(node)
 	}
 
 	return result, nil
 }
+
+// outputMessage finds the output message of an operation
+func (s *Server) outputMessage(operation string) *models.Message {
+	return sample.Message(s.definitions, operation, true)
+}
@@ -0,0 +1,516 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/addressing"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+)
+
+func TestOperationRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var soapRequest string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		soapRequest = string(body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <UserResponse xmlns="urn:users"><name>Ada</name></UserResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Users",
+		TargetNamespace: "urn:users",
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}, {Name: "fields", Type: "xs:string"}}},
+			{Name: "UserOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+			{Name: "UpdateUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+		}}},
+	}
+
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	if err := s.SetRoutes(&routes.Config{Heuristics: true, Operations: map[string]routes.Route{
+		"GetUser":    {Path: "/users/{id}"},
+		"UpdateUser": {Method: "PUT", Path: "/users/{id}"},
+	}}); err != nil {
+		t.Fatalf("SetRoutes() error = %v", err)
+	}
+	s.setupRoutes()
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		want                 []string
+	}{
+		{http.MethodGet, "/api/users/7?fields=name", "", http.StatusOK, []string{"<tns:GetUser>", "<id>7</id>", "<fields>name</fields>"}},
+		{http.MethodPut, "/api/users/8", `{"fields": "all"}`, http.StatusOK, []string{"<tns:UpdateUser>", "<id>8</id>", "<fields>all</fields>"}},
+		{http.MethodPost, "/api/GetUser", `{}`, http.StatusNotFound, nil},
+		{http.MethodGet, "/api/users/seven", "", http.StatusBadRequest, nil},
+		{http.MethodPut, "/api/users/8", `{"fields": {"name": true}}`, http.StatusUnprocessableEntity, nil},
+	}
+
+	for _, tt := range tests {
+		soapRequest = ""
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, body %s", tt.method, tt.target, w.Code, w.Body)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(soapRequest, want) {
+				t.Errorf("%s %s: SOAP request missing %s: %s", tt.method, tt.target, want, soapRequest)
+			}
+		}
+	}
+
+	if err := s.SetRoutes(&routes.Config{Operations: map[string]routes.Route{"GetOrder": {Method: "GET"}}}); err == nil {
+		t.Error("SetRoutes() accepted a route for an undefined operation")
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <UserResponse xmlns="urn:users"><name>Ada</name><card>4111111111111111</card><ssn>123-45-6789</ssn></UserResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Users",
+		TargetNamespace: "urn:users",
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "UserOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}, {Name: "card", Type: "xs:string"}, {Name: "ssn", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+		}}},
+	}
+
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	cfg, err := transform.Parse([]byte(`
+GetUser:
+  drop: [ssn]
+  mask: [{field: card, pattern: '^\d{12}'}]
+  rename: {name: fullName}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTransforms(cfg); err != nil {
+		t.Fatalf("SetTransforms() error = %v", err)
+	}
+	s.setupRoutes()
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{"id": 7}`)))
+	if want := `"response":{"card":"************1111","fullName":"Ada"}`; w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("POST /api/GetUser = %d %s, want %s", w.Code, w.Body, want)
+	}
+
+	if err := s.SetTransforms(transform.Config{"GetOrder": {}}); err == nil {
+		t.Error("SetTransforms() accepted rules for an undefined operation")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <UserResponse xmlns="urn:users"><name>Ada</name></UserResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Users",
+		TargetNamespace: "urn:users",
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "UserOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+		}}},
+	}
+
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.SetHooks(func(ctx context.Context, operation string, body map[string]interface{}) (map[string]interface{}, error) {
+		if body["userId"] == nil {
+			return nil, errors.New("userId is required")
+		}
+		return map[string]interface{}{"id": body["userId"]}, nil
+	}, func(ctx context.Context, operation string, body map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"displayName": body["name"], "operation": operation}, nil
+	})
+	s.setupRoutes()
+
+	// The request hook runs before validation, so userId needn't be a part
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{"userId": 7}`)))
+	if want := `"response":{"displayName":"Ada","operation":"GetUser"}`; w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+		t.Errorf("POST /api/GetUser = %d %s, want %s", w.Code, w.Body, want)
+	}
+	if !strings.Contains(got, ">7</") {
+		t.Errorf("backend request lacks the id of the hook: %s", got)
+	}
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{"id": 7}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "userId is required") {
+		t.Errorf("POST /api/GetUser without userId = %d %s, want 400", w.Code, w.Body)
+	}
+}
+
+func TestEnvelopeFieldOrder(t *testing.T) {
+	def := &models.Definitions{
+		TargetNamespace: "urn:orders",
+		Types: []models.Type{{Name: "PlaceOrderType", Elements: []models.Element{
+			{Name: "customer", Type: "xs:string"}, {Name: "item", Type: "xs:string"}, {Name: "quantity", Type: "xs:int"}, {Name: "note", Type: "xs:string"},
+		}}},
+		Elements: []models.Element{{Name: "PlaceOrder", Type: "tns:PlaceOrderType"}},
+		Messages: []models.Message{
+			{Name: "PlaceOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrder"}}},
+			{Name: "RateIn", Parts: []models.Part{{Name: "to", Type: "xs:string"}, {Name: "from", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "PlaceOrder", Input: models.Message{Name: "tns:PlaceOrderIn"}},
+			{Name: "Rate", Input: models.Message{Name: "tns:RateIn"}},
+		}}},
+	}
+	s := NewServer(def, "localhost", 0)
+
+	// Sequences are ordered by the schema whatever the order of the map,
+	// fields it doesn't declare last
+	for i := 0; i < 10; i++ {
+		envelope, err := s.buildSOAPEnvelope("PlaceOrder", "", map[string]interface{}{
+			"note": "gift", "quantity": 2, "extra": "x", "item": "book", "customer": "ada",
+		}, nil, nil)
+		want := "<customer>ada</customer><item>book</item><quantity>2</quantity><note>gift</note><extra>x</extra>"
+		if err != nil || !strings.Contains(envelope, want) {
+			t.Fatalf("buildSOAPEnvelope(PlaceOrder) = %s, %v, want %s", envelope, err, want)
+		}
+
+		envelope, err = s.buildSOAPEnvelope("Rate", "", map[string]interface{}{"from": "EUR", "to": "USD"}, nil, nil)
+		if want := "<to>USD</to><from>EUR</from>"; err != nil || !strings.Contains(envelope, want) {
+			t.Fatalf("buildSOAPEnvelope(Rate) = %s, %v, want %s", envelope, err, want)
+		}
+	}
+}
+
+func TestEnvelopeValues(t *testing.T) {
+	def := &models.Definitions{
+		TargetNamespace: "urn:orders",
+		Types: []models.Type{
+			{Name: "PlaceOrderType", Elements: []models.Element{
+				{Name: "customer", Type: "tns:Customer"}, {Name: "item", Type: "xs:string", MaxOccurs: "unbounded"}, {Name: "note", Type: "xs:string"},
+			}},
+			{Name: "Customer", Elements: []models.Element{{Name: "name", Type: "xs:string"}, {Name: "email", Type: "xs:string"}}},
+		},
+		Elements: []models.Element{{Name: "PlaceOrder", Type: "tns:PlaceOrderType"}},
+		Messages: []models.Message{
+			{Name: "PlaceOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrder"}}},
+			{Name: "AddIn", Parts: []models.Part{{Name: "a", Type: "xs:int"}, {Name: "b", Type: "xs:int"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "PlaceOrder", Input: models.Message{Name: "tns:PlaceOrderIn"}},
+			{Name: "Add", Input: models.Message{Name: "tns:AddIn"}},
+		}}},
+		Bindings: []models.Binding{{Style: "rpc", Operations: []models.BindingOperation{
+			{Name: "Add", Input: models.BindingMessage{Use: "encoded", Namespace: "urn:calc"}},
+		}}},
+	}
+	s := NewServer(def, "localhost", 0)
+
+	// Values are escaped, objects become child elements in the order of
+	// their type and lists repeated elements
+	envelope, err := s.buildSOAPEnvelope("PlaceOrder", "", map[string]interface{}{
+		"note":     "x<y & z",
+		"item":     []interface{}{"book", "pen"},
+		"customer": map[string]interface{}{"email": "ada@example.com", "name": "Ada", "@id": 7},
+	}, nil, nil)
+	want := `<customer id="7"><name>Ada</name><email>ada@example.com</email></customer>` +
+		"<item>book</item><item>pen</item><note>x&lt;y &amp; z</note>"
+	if err != nil || !strings.Contains(envelope, want) {
+		t.Fatalf("buildSOAPEnvelope(PlaceOrder) = %s, %v, want %s", envelope, err, want)
+	}
+
+	// null is sent as a nil element rather than an empty one
+	envelope, err = s.buildSOAPEnvelope("PlaceOrder", "", map[string]interface{}{"customer": nil, "note": nil}, nil, nil)
+	want = `<customer xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>` +
+		`<note xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>`
+	if err != nil || !strings.Contains(envelope, want) {
+		t.Fatalf("buildSOAPEnvelope(PlaceOrder) = %s, %v, want %s", envelope, err, want)
+	}
+
+	// rpc/encoded parts keep their xsi:type
+	envelope, err = s.buildSOAPEnvelope("Add", "", map[string]interface{}{"a": 1, "b": 2}, nil, nil)
+	want = `<a xsi:type="xsd:int">1</a><b xsi:type="xsd:int">2</b>`
+	if err != nil || !strings.Contains(envelope, want) {
+		t.Fatalf("buildSOAPEnvelope(Add) = %s, %v, want %s", envelope, err, want)
+	}
+
+	if _, err := s.buildSOAPEnvelope("PlaceOrder", "", map[string]interface{}{"bad name": "x"}, nil, nil); err == nil {
+		t.Error("buildSOAPEnvelope() accepted an invalid element name")
+	}
+}
+
+func TestSOAPHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <UserResponse xmlns="urn:users"><name>Ada</name></UserResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Users",
+		TargetNamespace: "urn:users",
+		Messages: []models.Message{
+			{Name: "UserIn", Parts: []models.Part{{Name: "id", Type: "xs:int"}}},
+			{Name: "UserOut", Parts: []models.Part{{Name: "name", Type: "xs:string"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}, Output: models.Message{Name: "tns:UserOut"}},
+		}}},
+	}
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.setupRoutes()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{
+  "id": 7,
+  "_headers": {
+    "{urn:routing}Route": {"@priority": "high", "region": ["eu", "us"]},
+    "Locale": "en & fr"
+  }
+}`))
+	req.Header.Set("X-Soap-Header-Session-Token", "abc")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /api/GetUser = %d %s", w.Code, w.Body)
+	}
+	want := `<soap:Header><SessionToken xmlns="urn:users">abc</SessionToken><Locale xmlns="urn:users">en &amp; fr</Locale>` +
+		`<Route xmlns="urn:routing" priority="high"><region>eu</region><region>us</region></Route></soap:Header>`
+	if !strings.Contains(got, want) || !strings.Contains(got, "<id>7</id>") || strings.Contains(got, "_headers") {
+		t.Errorf("backend request = %s, want %s", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{"id": 7, "_headers": {"1st": "x"}}`)))
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "not an XML element name") {
+		t.Errorf("POST /api/GetUser with an invalid header = %d %s, want 422", w.Code, w.Body)
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <GetUserResponse xmlns="urn:users"><name>Ada</name></GetUserResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer backend.Close()
+
+	def := &models.Definitions{
+		Name:            "Users",
+		TargetNamespace: "urn:users",
+		Messages:        []models.Message{{Name: "UserIn", Parts: []models.Part{{Name: "parameters", Element: "tns:GetUser"}}}},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "GetUser", Input: models.Message{Name: "tns:UserIn"}},
+		}}},
+		Elements: []models.Element{{Name: "GetUser", Type: "tns:GetUser"}},
+		Types: []models.Type{
+			{
+				Name:       "GetUser",
+				Elements:   []models.Element{{Name: "id", Type: "xs:int"}, {Name: "limit", Type: "tns:Limit", MinOccurs: "0"}},
+				Attributes: []models.Attribute{{Name: "version", Type: "xs:int"}},
+			},
+			{Name: "Limit", SimpleContent: "xs:int", Attributes: []models.Attribute{{Name: "unit", Type: "xs:string"}}},
+		},
+	}
+	s := NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	if err := s.SetRoutes(&routes.Config{Operations: map[string]routes.Route{"GetUser": {Method: "GET", Path: "/users/{id}"}}}); err != nil {
+		t.Fatal(err)
+	}
+	s.setupRoutes()
+
+	tests := []struct {
+		target string
+		status int
+		want   string
+	}{
+		// Attributes are "@name" query parameters, or named without their @
+		{"/api/users/7?@version=3", http.StatusOK, `<tns:GetUser version="3">`},
+		{"/api/users/7?version=3", http.StatusOK, `<tns:GetUser version="3">`},
+		{"/api/users/7?@version=three", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != tt.status || !strings.Contains(got, tt.want) {
+			t.Errorf("GET %s = %d %s, backend request %s, want %s", tt.target, w.Code, w.Body, got, tt.want)
+		}
+	}
+
+	// In JSON bodies attributes are "@name" fields and simple content "#text"
+	s = NewServer(def, "localhost", 0)
+	s.SetSOAPEndpoint(backend.URL)
+	s.setupRoutes()
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{"@version": 3, "id": 7, "limit": {"@unit": "rows", "#text": 10}}`)))
+	want := `<tns:GetUser version="3">`
+	if w.Code != http.StatusOK || !strings.Contains(got, want) || !strings.Contains(got, `<limit unit="rows">10</limit>`) {
+		t.Errorf("POST /api/GetUser = %d %s, backend request %s, want %s", w.Code, w.Body, got, want)
+	}
+
+	got = ""
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/GetUser", strings.NewReader(`{"version": 3, "id": 7}`)))
+	if w.Code != http.StatusUnprocessableEntity || got != "" {
+		t.Errorf("POST /api/GetUser with a bare attribute name = %d %s, want 422", w.Code, w.Body)
+	}
+}
+
+func TestDefaultPort(t *testing.T) {
+	// ASMX services list their HTTP GET and POST ports before the SOAP ones
+	def := &models.Definitions{
+		Bindings: []models.Binding{
+			{Name: "CalcHttpGet", HTTPVerb: "GET"},
+			{Name: "CalcSoap12", SOAPVersion: "1.2"},
+			{Name: "CalcSoap", SOAPVersion: "1.1"},
+		},
+		Services: []models.Service{{Name: "Calc", Ports: []models.Port{
+			{Name: "CalcHttpGet", Binding: "tns:CalcHttpGet", Address: "http://calc/get"},
+			{Name: "CalcSoap12", Binding: "tns:CalcSoap12", Address: "http://calc/soap12"},
+			{Name: "CalcSoap", Binding: "tns:CalcSoap", Address: "http://calc/soap"},
+		}}},
+	}
+	s := NewServer(def, "", 0)
+	if s.soapEndpoint != "http://calc/soap12" || s.soapVersion != "1.2" {
+		t.Errorf("endpoint = %s, version %s, want the SOAP 1.2 port", s.soapEndpoint, s.soapVersion)
+	}
+}
+
+func TestAddressing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var bodies []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Write([]byte(pingResponse))
+	}))
+	defer backend.Close()
+
+	def := *pingDefinitions
+	def.Bindings = []models.Binding{{Operations: []models.BindingOperation{{Name: "Ping", SoapAction: "urn:ping#Ping"}}}}
+	wsa := `xmlns:wsa="http://www.w3.org/2005/08/addressing"`
+
+	tests := []struct {
+		name       string
+		addressing *addressing.WSAddressing
+		want       []string
+		unwanted   []string
+	}{
+		{
+			name:     "off",
+			unwanted: []string{"wsa:"},
+		},
+		{
+			name:       "defaults",
+			addressing: &addressing.WSAddressing{},
+			want: []string{
+				`<wsa:To ` + wsa + `>` + backend.URL + `</wsa:To>`,
+				`<wsa:Action ` + wsa + `>urn:ping#Ping</wsa:Action>`,
+				`<wsa:MessageID ` + wsa + `>urn:uuid:`,
+				`<wsa:ReplyTo ` + wsa + `><wsa:Address>http://www.w3.org/2005/08/addressing/anonymous</wsa:Address></wsa:ReplyTo>`,
+			},
+			unwanted: []string{"wsa:RelatesTo"},
+		},
+		{
+			name:       "configured",
+			addressing: &addressing.WSAddressing{To: "urn:ping", ReplyTo: "http://client/replies", RelatesTo: "urn:uuid:1"},
+			want: []string{
+				`<wsa:To ` + wsa + `>urn:ping</wsa:To>`,
+				`<wsa:Address>http://client/replies</wsa:Address>`,
+				`<wsa:RelatesTo ` + wsa + `>urn:uuid:1</wsa:RelatesTo>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			s := NewServer(&def, "localhost", 0)
+			s.SetSOAPEndpoint(backend.URL)
+			s.SetAddressing(tt.addressing)
+			s.setupRoutes()
+			for i := 0; i < 2; i++ {
+				w := httptest.NewRecorder()
+				s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Ping", strings.NewReader(`{}`)))
+				if w.Code != http.StatusOK {
+					t.Fatalf("POST /api/Ping = %d %s", w.Code, w.Body)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(bodies[0], want) {
+					t.Errorf("backend request = %s, want %s", bodies[0], want)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(bodies[0], unwanted) {
+					t.Errorf("backend request = %s, want no %s", bodies[0], unwanted)
+				}
+			}
+			// Every message gets an ID of its own
+			if tt.addressing != nil && messageID(bodies[0]) == messageID(bodies[1]) {
+				t.Errorf("two requests share the MessageID %s", messageID(bodies[0]))
+			}
+		})
+	}
+}
+
+// messageID returns the wsa:MessageID of an envelope
+func messageID(envelope string) string {
+	_, id, _ := strings.Cut(envelope, "<wsa:MessageID ")
+	id, _, _ = strings.Cut(id, "</wsa:MessageID>")
+	return id
+}
@@ -0,0 +1,340 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/sample"
+)
+
+// FieldError is a field of a REST request that doesn't match the input
+// message of the operation. Field is a path such as items[0].sku.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SetRequestValidation enables or disables checking requests against the
+// input message schema before calling the backend. It is enabled by default.
+func (s *Server) SetRequestValidation(enabled bool) {
+	s.skipValidation = !enabled
+}
+
+// validateRequest checks the input fields of an operation against its input
+// message: required fields, XSD types, enumerations and unknown fields. It
+// returns nil when the request is valid or the message can't be resolved.
+func (s *Server) validateRequest(operation string, body map[string]interface{}) []FieldError {
+	v := &requestValidator{def: s.definitions}
+
+	if elem := s.inputElement(operation); elem != nil {
+		t := v.complexType(models.LocalName(elem.Type))
+		if t == nil {
+			return nil
+		}
+		v.object("", t, body)
+		return v.errors
+	}
+
+	msg := s.inputMessage(operation)
+	if msg == nil {
+		return nil
+	}
+	known := make(map[string]bool)
+	for _, part := range msg.Parts {
+		known[part.Name] = true
+		value, ok := body[part.Name]
+		if !ok {
+			v.fail(part.Name, "is required")
+			continue
+		}
+		xsdType := part.Type
+		if part.Element != "" {
+			xsdType = v.def.ElementType(part.Element)
+		}
+		v.value(part.Name, xsdType, value, false)
+	}
+	v.unknown("", body, known)
+	return v.errors
+}
+
+// inputMessage returns the input message of an operation
+func (s *Server) inputMessage(operation string) *models.Message {
+	return sample.Message(s.definitions, operation, false)
+}
+
+// requestValidator collects the errors of a request, walking its fields
+// alongside the schema types
+type requestValidator struct {
+	def    *models.Definitions
+	errors []FieldError
+}
+
+// fail records an error of the field at path
+func (v *requestValidator) fail(path, message string) {
+	v.errors = append(v.errors, FieldError{Field: path, Message: message})
+}
+
+// object checks the fields of a JSON object against a complex type
+func (v *requestValidator) object(path string, t *models.Type, obj map[string]interface{}) {
+	known := make(map[string]bool)
+	members := make(map[int][]string)
+	chosen := make(map[int]int)
+
+	for _, elem := range t.Elements {
+		known[elem.Name] = true
+		value, ok := obj[elem.Name]
+		if elem.Choice > 0 {
+			members[elem.Choice] = append(members[elem.Choice], elem.Name)
+			if ok {
+				chosen[elem.Choice]++
+			}
+		} else if !ok && elem.MinOccurs != "0" {
+			v.fail(joinPath(path, elem.Name), "is required")
+		}
+		if ok {
+			v.element(joinPath(path, elem.Name), elem, value)
+		}
+	}
+
+	for i := 1; i <= len(t.Choices); i++ {
+		names := members[i]
+		if len(names) == 0 {
+			continue
+		}
+		field := joinPath(path, strings.Join(names, "|"))
+		switch {
+		case chosen[i] > 1:
+			v.fail(field, fmt.Sprintf("only one of %s may be set", strings.Join(names, ", ")))
+		case chosen[i] == 0 && t.Choices[i-1].MinOccurs != "0":
+			v.fail(field, fmt.Sprintf("one of %s is required", strings.Join(names, ", ")))
+		}
+	}
+
+	// Attributes are "@name" fields and simple content the "#text" field,
+	// as the request encoder writes them
+	for _, attr := range t.Attributes {
+		name := "@" + attr.Name
+		known[name] = true
+		value, ok := obj[name]
+		if !ok {
+			if attr.Use == "required" {
+				v.fail(joinPath(path, name), "is required")
+			}
+			continue
+		}
+		v.value(joinPath(path, name), attr.Type, value, false)
+	}
+	if t.SimpleContent != "" || t.Mixed {
+		known["#text"] = true
+		if value, ok := obj["#text"]; ok && t.SimpleContent != "" {
+			v.value(joinPath(path, "#text"), t.SimpleContent, value, false)
+		}
+	}
+
+	if !t.Any {
+		v.unknown(path, obj, known)
+	}
+}
+
+// unknown reports the fields of obj that are not in known
+func (v *requestValidator) unknown(path string, obj map[string]interface{}, known map[string]bool) {
+	var names []string
+	for name := range obj {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v.fail(joinPath(path, name), "is not an input field")
+	}
+}
+
+// element checks the value of an element, which is a list when the element
+// may occur more than once
+func (v *requestValidator) element(path string, elem models.Element, value interface{}) {
+	items, ok := value.([]interface{})
+	if !ok {
+		v.value(path, elem.Type, value, elem.Nillable)
+		return
+	}
+	if !repeated(elem) {
+		v.fail(path, "does not take a list")
+		return
+	}
+	for i, item := range items {
+		v.value(fmt.Sprintf("%s[%d]", path, i), elem.Type, item, elem.Nillable)
+	}
+}
+
+// value checks a single value against an XSD type. Types that can't be
+// resolved accept any scalar.
+func (v *requestValidator) value(path, xsdType string, value interface{}, nillable bool) {
+	if value == nil {
+		if !nillable {
+			v.fail(path, "must not be null")
+		}
+		return
+	}
+
+	name := models.LocalName(xsdType)
+	if t := v.complexType(name); t != nil {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(path, "must be an object")
+			return
+		}
+		v.object(path, t, obj)
+		return
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		v.fail(path, "must be a single value")
+		return
+	}
+
+	// Follow restrictions down to the built-in type, checking the
+	// enumeration of the most derived type that has one
+	checkedEnum := false
+	for depth := 0; depth < 10; depth++ {
+		st := v.simpleType(name)
+		if st == nil {
+			break
+		}
+		if !checkedEnum && len(st.Enumeration) > 0 {
+			checkedEnum = true
+			if !slices.Contains(st.Enumeration, fmt.Sprint(value)) {
+				v.fail(path, fmt.Sprintf("must be one of %s", strings.Join(st.Enumeration, ", ")))
+				return
+			}
+		}
+		name = models.LocalName(st.Base)
+	}
+
+	switch {
+	case integerTypes[name]:
+		if !isInteger(value) {
+			v.fail(path, "must be an integer")
+		}
+	case name == "decimal" || name == "float" || name == "double":
+		if !isNumber(value) {
+			v.fail(path, "must be a number")
+		}
+	case name == "boolean":
+		if !isBoolean(value) {
+			v.fail(path, "must be a boolean")
+		}
+	case dateLayouts[name] != nil:
+		if !isDate(name, value) {
+			v.fail(path, "must be a "+dateNames[name])
+		}
+	}
+}
+
+// complexType returns the complex type named name, or nil
+func (v *requestValidator) complexType(name string) *models.Type {
+	for i := range v.def.Types {
+		if v.def.Types[i].Name == name {
+			return &v.def.Types[i]
+		}
+	}
+	return nil
+}
+
+// simpleType returns the simple type named name, or nil
+func (v *requestValidator) simpleType(name string) *models.SimpleType {
+	for i := range v.def.SimpleTypes {
+		if v.def.SimpleTypes[i].Name == name {
+			return &v.def.SimpleTypes[i]
+		}
+	}
+	return nil
+}
+
+// repeated reports whether an element may occur more than once
+func repeated(elem models.Element) bool {
+	if elem.MaxOccurs == "unbounded" {
+		return true
+	}
+	n, err := strconv.Atoi(elem.MaxOccurs)
+	return err == nil && n > 1
+}
+
+// isInteger reports whether a JSON or parameter value is an integer
+func isInteger(value interface{}) bool {
+	switch x := value.(type) {
+	case int64:
+		return true
+	case float64:
+		return x == math.Trunc(x) && !math.IsInf(x, 0)
+	case string:
+		_, err := strconv.ParseInt(x, 10, 64)
+		return err == nil
+	}
+	return false
+}
+
+// isNumber reports whether a JSON or parameter value is a number. Strings
+// are accepted for xs:decimal values kept as strings.
+func isNumber(value interface{}) bool {
+	switch x := value.(type) {
+	case int64, float64:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(x, 64)
+		return err == nil
+	}
+	return false
+}
+
+// isBoolean reports whether a JSON or parameter value is an XSD boolean
+func isBoolean(value interface{}) bool {
+	switch x := value.(type) {
+	case bool:
+		return true
+	case string:
+		return x == "true" || x == "false" || x == "1" || x == "0"
+	}
+	return false
+}
+
+// dateLayouts are the layouts of the XSD date and time types, with and
+// without a time zone
+var dateLayouts = map[string][]string{
+	"dateTime": {"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05"},
+	"date":     {"2006-01-02Z07:00", "2006-01-02"},
+	"time":     {"15:04:05Z07:00", "15:04:05"},
+}
+
+// dateNames name the date and time types in validation messages
+var dateNames = map[string]string{"dateTime": "date-time", "date": "date", "time": "time"}
+
+// isDate reports whether a JSON or parameter value is a value of the XSD
+// date or time type t
+func isDate(t string, value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, layout := range dateLayouts[t] {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// joinPath appends a field name to the path of its parent
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
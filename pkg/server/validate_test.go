@@ -0,0 +1,106 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestValidateRequest(t *testing.T) {
+	def := &models.Definitions{
+		Messages: []models.Message{
+			{Name: "AddIn", Parts: []models.Part{{Name: "intA", Type: "xs:int"}, {Name: "intB", Type: "xs:int"}}},
+			{Name: "BookIn", Parts: []models.Part{{Name: "day", Type: "xs:date"}, {Name: "at", Type: "xs:time"}, {Name: "since", Type: "xs:dateTime"}}},
+			{Name: "OrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:PlaceOrder"}}},
+		},
+		PortTypes: []models.PortType{{Operations: []models.Operation{
+			{Name: "Add", Input: models.Message{Name: "tns:AddIn"}},
+			{Name: "Book", Input: models.Message{Name: "tns:BookIn"}},
+			{Name: "PlaceOrder", Input: models.Message{Name: "tns:OrderIn"}},
+		}}},
+		Elements: []models.Element{{Name: "PlaceOrder", Type: "tns:PlaceOrder"}},
+		Types: []models.Type{
+			{
+				Name: "PlaceOrder",
+				Elements: []models.Element{
+					{Name: "status", Type: "tns:Status"},
+					{Name: "items", Type: "tns:Item", MaxOccurs: "unbounded"},
+					{Name: "card", Type: "xs:string", Choice: 1},
+					{Name: "iban", Type: "xs:string", Choice: 1},
+				},
+				Attributes: []models.Attribute{{Name: "priority", Type: "xs:boolean"}},
+				Choices:    []models.Choice{{}},
+			},
+			{
+				Name: "Item",
+				Elements: []models.Element{
+					{Name: "sku", Type: "xs:string"},
+					{Name: "quantity", Type: "xs:positiveInteger", MinOccurs: "0"},
+				},
+			},
+		},
+		SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"NEW", "PAID"}}},
+	}
+	s := NewServer(def, "localhost", 0)
+
+	tests := []struct {
+		name      string
+		operation string
+		body      map[string]interface{}
+		want      []FieldError
+	}{
+		{"valid parts", "Add", map[string]interface{}{"intA": 5.0, "intB": int64(3)}, nil},
+		{"invalid parts", "Add", map[string]interface{}{"intA": 1.5, "intC": 1.0}, []FieldError{
+			{"intA", "must be an integer"},
+			{"intB", "is required"},
+			{"intC", "is not an input field"},
+		}},
+		{"valid element", "PlaceOrder", map[string]interface{}{
+			"status":    "NEW",
+			"items":     []interface{}{map[string]interface{}{"sku": "A1", "quantity": 2.0}},
+			"card":      "4111",
+			"@priority": "true",
+		}, nil},
+		{"attributes", "PlaceOrder", map[string]interface{}{
+			"status":    "NEW",
+			"items":     []interface{}{},
+			"iban":      "DE00",
+			"@priority": "soon",
+			"priority":  true,
+		}, []FieldError{
+			{"@priority", "must be a boolean"},
+			{"priority", "is not an input field"},
+		}},
+		{"invalid element", "PlaceOrder", map[string]interface{}{
+			"status": "SHIPPED",
+			"items":  []interface{}{map[string]interface{}{"quantity": "two"}, "B2"},
+			"card":   "4111",
+			"iban":   "DE00",
+		}, []FieldError{
+			{"status", "must be one of NEW, PAID"},
+			{"items[0].sku", "is required"},
+			{"items[0].quantity", "must be an integer"},
+			{"items[1]", "must be an object"},
+			{"card|iban", "only one of card, iban may be set"},
+		}},
+		{"missing choice", "PlaceOrder", map[string]interface{}{"status": "PAID", "items": map[string]interface{}{"sku": "A1"}}, []FieldError{
+			{"card|iban", "one of card, iban is required"},
+		}},
+		{"valid dates", "Book", map[string]interface{}{"day": "2024-01-15", "at": "10:30:00.5+02:00", "since": "2024-01-15T10:30:00Z"}, nil},
+		{"invalid dates", "Book", map[string]interface{}{"day": "15/01/2024", "at": 1030.0, "since": "2024-01-15"}, []FieldError{
+			{"day", "must be a date"},
+			{"at", "must be a time"},
+			{"since", "must be a date-time"},
+		}},
+		{"unknown operation", "Ping", map[string]interface{}{"anything": 1.0}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.validateRequest(tt.operation, tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
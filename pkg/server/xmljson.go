@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// xmlNode is an element of a decoded XML document
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+}
+
+// integerTypes are the XSD types whose values are JSON integers
+var integerTypes = map[string]bool{
+	"int": true, "integer": true, "long": true, "short": true, "byte": true,
+	"unsignedInt": true, "unsignedLong": true, "unsignedShort": true, "unsignedByte": true,
+	"positiveInteger": true, "nonNegativeInteger": true, "negativeInteger": true, "nonPositiveInteger": true,
+}
+
+// typeHints holds the schema metadata used to shape converted JSON values.
+// Child elements and attributes are typed by the complex type of their
+// parent, so elements of the same name may have different types in
+// different parents.
+type typeHints struct {
+	types   map[string]string       // global element and output part local name -> XSD type
+	arrays  map[string]bool         // global elements declared with maxOccurs > 1
+	complex map[string]*models.Type // complex types by name
+	bases   map[string]string       // simple types -> the type they restrict
+	derived map[string]bool         // complex types derived from another one
+
+	// Substitution groups: the head each global element substitutes and
+	// the heads with substitutes
+	heads  map[string]string
+	groups map[string]bool
+
+	// decimalStrings keeps xs:decimal values as strings
+	decimalStrings bool
+}
+
+// newTypeHints collects the types of the schema, of its global elements
+// and of the parts of the output message, which take precedence
+func newTypeHints(def *models.Definitions, outputMsg *models.Message) *typeHints {
+	h := &typeHints{
+		types:   make(map[string]string),
+		arrays:  make(map[string]bool),
+		complex: make(map[string]*models.Type),
+		bases:   make(map[string]string),
+		derived: make(map[string]bool),
+
+		heads:  make(map[string]string),
+		groups: make(map[string]bool),
+	}
+
+	for i, t := range def.Types {
+		h.complex[t.Name] = &def.Types[i]
+		if t.Base != "" {
+			h.derived[t.Name] = true
+		}
+	}
+	for _, st := range def.SimpleTypes {
+		h.bases[st.Name] = st.Base
+	}
+	for _, elem := range def.Elements {
+		h.types[elem.Name] = elem.TypeName()
+		if repeated(elem) {
+			h.arrays[elem.Name] = true
+		}
+		if elem.SubstitutionGroup != "" {
+			h.heads[elem.Name] = models.LocalName(elem.SubstitutionGroup)
+			h.groups[models.LocalName(elem.SubstitutionGroup)] = true
+		}
+	}
+	if outputMsg != nil {
+		for _, part := range outputMsg.Parts {
+			if part.Type != "" {
+				h.types[part.Name] = part.Type
+			}
+		}
+	}
+
+	return h
+}
+
+// declared returns the declaration of a child element in a complex type
+func declared(t *models.Type, name string) (models.Element, bool) {
+	if t != nil {
+		for _, elem := range t.Elements {
+			if elem.Name == name {
+				return elem, true
+			}
+		}
+	}
+	return models.Element{}, false
+}
+
+// attributeType returns the type of an attribute of a complex type
+func attributeType(t *models.Type, name string) string {
+	if t != nil {
+		for _, attr := range t.Attributes {
+			if attr.Name == name {
+				return attr.Type
+			}
+		}
+	}
+	return ""
+}
+
+// decodeXML parses an XML fragment into its top-level elements
+func decodeXML(data []byte) ([]*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var roots []*xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) == 0 {
+				roots = append(roots, node)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	return roots, nil
+}
+
+// toJSON converts a node into a JSON-friendly value. Leaf elements become
+// values coerced by their XSD type, other elements become objects keyed by
+// child name, with repeated children collected into arrays. Attributes are
+// kept as "@name" keys and mixed text as "#text", also coerced by their
+// type. The xsi:type of derived types is kept as "@xsi:type" with the
+// local type name, and elements of a substitution group are keyed by the
+// head with their name in "#element".
+func (h *typeHints) toJSON(n *xmlNode) interface{} {
+	xsdType := h.types[n.name]
+	if xsdType == "" {
+		xsdType = n.name // Elements of anonymous types, such as rpc wrappers
+	}
+	return h.value(n, xsdType)
+}
+
+// value converts a node of type xsdType
+func (h *typeHints) value(n *xmlNode, xsdType string) interface{} {
+	var attrs []xml.Attr
+	var xsiType string
+	for _, attr := range n.attrs {
+		// The body is decoded without the envelope, so the xsi prefix may be
+		// left unresolved
+		if attr.Name.Space == xsiNamespace || attr.Name.Space == "xsi" {
+			if attr.Name.Local == "nil" && (attr.Value == "true" || attr.Value == "1") {
+				return nil
+			}
+			if attr.Name.Local == "type" && h.derived[models.LocalName(attr.Value)] {
+				xsiType = models.LocalName(attr.Value)
+				xsdType = xsiType
+			}
+			continue
+		}
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+
+	t := h.complex[models.LocalName(xsdType)]
+	textType := xsdType
+	if t != nil {
+		textType = t.SimpleContent
+	}
+
+	text := strings.TrimSpace(n.text.String())
+	if len(n.children) == 0 && len(attrs) == 0 && xsiType == "" {
+		return h.coerce(textType, text)
+	}
+
+	obj := make(map[string]interface{})
+	if xsiType != "" {
+		obj["@xsi:type"] = xsiType
+	}
+	for _, attr := range attrs {
+		obj["@"+attr.Name.Local] = h.coerce(attributeType(t, attr.Name.Local), attr.Value)
+	}
+	for _, child := range n.children {
+		// Children the parent doesn't declare are typed as global elements
+		key := child.name
+		head, substitute := h.substituted(t, child.name)
+		if substitute {
+			key = head
+		}
+		childType, array := h.types[child.name], h.arrays[key]
+		if elem, ok := declared(t, key); ok {
+			array = repeated(elem)
+			if !substitute {
+				childType = elem.TypeName()
+			}
+		}
+
+		value := h.value(child, childType)
+		if substitute {
+			value = withElement(value, child.name)
+		}
+		switch existing := obj[key].(type) {
+		case nil:
+			if _, ok := obj[key]; ok {
+				obj[key] = []interface{}{nil, value}
+			} else if array {
+				obj[key] = []interface{}{value}
+			} else {
+				obj[key] = value
+			}
+		case []interface{}:
+			obj[key] = append(existing, value)
+		default:
+			obj[key] = []interface{}{existing, value}
+		}
+	}
+	if text != "" {
+		obj["#text"] = h.coerce(textType, text)
+	}
+
+	return obj
+}
+
+// substituted returns the head of the substitution group that a child
+// element stands for in its parent of type t, when t declares the head
+// rather than the child itself
+func (h *typeHints) substituted(t *models.Type, child string) (string, bool) {
+	seen := make(map[string]bool)
+	for name := child; name != "" && !seen[name]; name = h.heads[name] {
+		seen[name] = true
+		if _, ok := declared(t, name); ok {
+			return name, name != child || h.groups[name]
+		}
+	}
+	return "", false
+}
+
+// withElement tags the JSON value of an element substituting a head with
+// the element name, as "#element". Values of simple types move to "#text".
+func withElement(value interface{}, name string) interface{} {
+	if obj, ok := value.(map[string]interface{}); ok {
+		obj["#element"] = name
+		return obj
+	}
+	return map[string]interface{}{"#element": name, "#text": value}
+}
+
+// coerce converts the text of an element or attribute according to its XSD
+// type, following simple types down to the built-in type they restrict.
+// Values that don't parse are returned as strings.
+func (h *typeHints) coerce(xsdType, text string) interface{} {
+	t := models.LocalName(xsdType)
+	for depth := 0; depth < 10 && h.bases[t] != ""; depth++ {
+		t = models.LocalName(h.bases[t])
+	}
+	switch {
+	case integerTypes[t]:
+		if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return v
+		}
+	case t == "decimal":
+		if h.decimalStrings {
+			return text
+		}
+		if v, err := strconv.ParseFloat(text, 64); err == nil {
+			return v
+		}
+	case t == "float" || t == "double":
+		if v, err := strconv.ParseFloat(text, 64); err == nil {
+			return v
+		}
+	case t == "boolean":
+		if v, err := strconv.ParseBool(text); err == nil {
+			return v
+		}
+	}
+	return text
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestParseSOAPResponse(t *testing.T) {
+	def := &models.Definitions{
+		Types: []models.Type{
+			{Name: "Address", Elements: []models.Element{
+				{Name: "cep", Type: "xsd:string"},
+				{Name: "number", Type: "xsd:int"},
+				{Name: "tag", Type: "xsd:string", MaxOccurs: "unbounded"},
+			}},
+			{Name: "LookupResponse", Elements: []models.Element{
+				{Name: "address", Type: "tns:Address", MaxOccurs: "unbounded"},
+				{Name: "exact", Type: "xsd:boolean"},
+				{Name: "score", Type: "xsd:double"},
+			}},
+		},
+		Messages: []models.Message{
+			{Name: "LookupOut", Parts: []models.Part{{Name: "parameters", Element: "tns:LookupResponse"}}},
+		},
+		PortTypes: []models.PortType{
+			{Operations: []models.Operation{{Name: "Lookup", Output: models.Message{Name: "LookupOut"}}}},
+		},
+	}
+	s := &Server{definitions: def}
+
+	body := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"
+    xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <soap:Body>
+    <LookupResponse xmlns="urn:cep">
+      <address id="a1"><cep>01310-100</cep><number>42</number><tag>main</tag></address>
+      <exact>true</exact>
+      <score>0.5</score>
+      <note xsi:nil="true"/>
+    </LookupResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+	got, err := s.parseSOAPResponse("Lookup", []byte(body))
+	if err != nil {
+		t.Fatalf("parseSOAPResponse() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"address": []interface{}{
+			map[string]interface{}{
+				"@id":    "a1",
+				"cep":    "01310-100",
+				"number": int64(42),
+				"tag":    []interface{}{"main"},
+			},
+		},
+		"exact": true,
+		"score": 0.5,
+		"note":  nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSOAPResponse() = %#v, want %#v", got, want)
+	}
+}
+
+func TestCoerceDecimal(t *testing.T) {
+	hints := newTypeHints(&models.Definitions{
+		SimpleTypes: []models.SimpleType{{Name: "Amount", Base: "xsd:decimal"}},
+	}, nil)
+
+	if got := hints.coerce("tns:Amount", "0.10"); got != 0.1 {
+		t.Errorf("coerce() = %#v, want 0.1", got)
+	}
+
+	hints.decimalStrings = true
+	if got := hints.coerce("xsd:decimal", "0.10"); got != "0.10" {
+		t.Errorf("coerce() with decimal strings = %#v, want \"0.10\"", got)
+	}
+}
+
+func TestParentTypeHints(t *testing.T) {
+	hints := newTypeHints(&models.Definitions{
+		Types: []models.Type{
+			{Name: "Catalog", Elements: []models.Element{
+				{Name: "product", Type: "tns:Product", MaxOccurs: "unbounded"},
+				{Name: "code", Type: "xsd:string"},
+			}, Attributes: []models.Attribute{{Name: "version", Type: "xsd:int"}}},
+			{Name: "Product", Elements: []models.Element{
+				{Name: "code", Type: "xsd:int"},
+				{Name: "price", Type: "tns:Price"},
+			}},
+			{Name: "Price", SimpleContent: "xsd:decimal", Attributes: []models.Attribute{{Name: "taxed", Type: "xsd:boolean"}}},
+		},
+		Elements: []models.Element{{Name: "Catalog", Type: "tns:Catalog"}},
+	}, nil)
+
+	roots, err := decodeXML([]byte(`<Catalog version="2">
+  <code>0042</code>
+  <product><code>0042</code><price taxed="true">9.5</price></product>
+</Catalog>`))
+	if err != nil {
+		t.Fatalf("decodeXML() error = %v", err)
+	}
+
+	// code is a string in catalogs and an integer in products
+	want := map[string]interface{}{
+		"@version": int64(2),
+		"code":     "0042",
+		"product": []interface{}{map[string]interface{}{
+			"code":  int64(42),
+			"price": map[string]interface{}{"@taxed": true, "#text": 9.5},
+		}},
+	}
+	if got := hints.toJSON(roots[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("toJSON() = %#v, want %#v", got, want)
+	}
+}
+
+func TestDerivedTypeXSIType(t *testing.T) {
+	hints := newTypeHints(&models.Definitions{
+		Types: []models.Type{
+			{Name: "Vehicle", Elements: []models.Element{{Name: "plate", Type: "xsd:string"}}},
+			{Name: "Car", Base: "tns:Vehicle", Derivation: models.DerivationExtension, Elements: []models.Element{
+				{Name: "plate", Type: "xsd:string"},
+				{Name: "doors", Type: "xsd:int"},
+			}},
+		},
+	}, nil)
+
+	roots, err := decodeXML([]byte(`<Fleet xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:f="urn:fleet">
+  <vehicle xsi:type="f:Car"><plate>AB-12</plate><doors>4</doors></vehicle>
+  <count xsi:type="xsd:int">1</count>
+</Fleet>`))
+	if err != nil {
+		t.Fatalf("decodeXML() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"vehicle": map[string]interface{}{"@xsi:type": "Car", "plate": "AB-12", "doors": int64(4)},
+		"count":   "1",
+	}
+	if got := hints.toJSON(roots[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("toJSON() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSubstitutionGroupElement(t *testing.T) {
+	hints := newTypeHints(&models.Definitions{
+		Types: []models.Type{
+			{Name: "Drawing", Elements: []models.Element{
+				{Name: "Shape", Type: "tns:Shape", Namespace: "urn:draw", MaxOccurs: "unbounded"},
+				{Name: "title", Type: "xsd:string"},
+			}},
+			{Name: "Shape", Elements: []models.Element{{Name: "color", Type: "xsd:string"}}},
+		},
+		Elements: []models.Element{
+			{Name: "Drawing", Type: "tns:Drawing"},
+			{Name: "Shape", Type: "tns:Shape", Abstract: true},
+			{Name: "Circle", Type: "tns:Shape", SubstitutionGroup: "tns:Shape"},
+			{Name: "Label", Type: "xsd:string", SubstitutionGroup: "tns:Shape"},
+		},
+	}, nil)
+
+	roots, err := decodeXML([]byte(`<Drawing>
+  <title>Sketch</title>
+  <Circle><color>red</color></Circle>
+  <Label>hello</Label>
+</Drawing>`))
+	if err != nil {
+		t.Fatalf("decodeXML() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"title": "Sketch",
+		"Shape": []interface{}{
+			map[string]interface{}{"#element": "Circle", "color": "red"},
+			map[string]interface{}{"#element": "Label", "#text": "hello"},
+		},
+	}
+	if got := hints.toJSON(roots[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("toJSON() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,300 @@
+// Package transform rewrites the JSON responses of the proxy by operation:
+// it renames fields, drops fields and masks values, so that data legacy
+// services return, such as card numbers, doesn't leave the gateway.
+package transform
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// AllOperations is the operation name of rules applying to every
+// operation, before the rules of the operation itself
+const AllOperations = "*"
+
+// Config holds the response rules by operation name. A nil Config leaves
+// responses as they are.
+type Config map[string]*Rules
+
+// Rules transform the response of an operation. Fields are named by paths
+// of field names separated by dots, such as customer.card.number; a *
+// segment matches any field, and arrays are traversed, so that the path
+// applies to each of their items. Paths name the fields of the response
+// as the backend returns it: fields are dropped, then masked, then renamed.
+type Rules struct {
+	// Drop removes fields
+	Drop []string `yaml:"drop,omitempty"`
+	// Mask replaces text in string and number values
+	Mask []Mask `yaml:"mask,omitempty"`
+	// Rename gives fields new names, by path
+	Rename map[string]string `yaml:"rename,omitempty"`
+}
+
+// Mask replaces the text matching a regular expression
+type Mask struct {
+	// Field is the path of the values masked, which may be objects or
+	// arrays of values; empty for every value of the response
+	Field string `yaml:"field,omitempty"`
+	// Pattern is the regular expression, in the syntax of Go's regexp
+	Pattern string `yaml:"pattern"`
+	// Replace replaces each match, with $1 for the text of the first
+	// group; each character of the match is replaced by * when empty
+	Replace string `yaml:"replace,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Load reads the transforms key of a YAML config file, such as
+//
+//	transforms:
+//	  GetCustomer:
+//	    drop: [ssn]
+//	    mask:
+//	      - field: card.number
+//	        pattern: '^\d{12}'
+//	    rename:
+//	      custName: name
+//
+// It returns nil when the file has no transforms.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transforms: %w", err)
+	}
+	var file struct {
+		Transforms Config `yaml:"transforms"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse transforms: %w", err)
+	}
+	if err := file.Transforms.compile(); err != nil {
+		return nil, err
+	}
+	return file.Transforms, nil
+}
+
+// Parse parses YAML rules by operation name, the value of the transforms
+// key Load reads
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse transforms: %w", err)
+	}
+	if err := cfg.compile(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// compile checks the paths and compiles the patterns of the rules
+func (c Config) compile() error {
+	for operation, rules := range c {
+		if rules == nil {
+			continue
+		}
+		var paths []string
+		paths = append(paths, rules.Drop...)
+		for path, name := range rules.Rename {
+			if name == "" || strings.ContainsAny(name, ".*") {
+				return fmt.Errorf("operation %s: invalid new name %q of %s", operation, name, path)
+			}
+			if path == "*" || strings.HasSuffix(path, ".*") {
+				return fmt.Errorf("operation %s: %s names several fields to rename to %s", operation, path, name)
+			}
+			paths = append(paths, path)
+		}
+		for i := range rules.Mask {
+			m := &rules.Mask[i]
+			if m.Pattern == "" {
+				return fmt.Errorf("operation %s: mask of %q has no pattern", operation, m.Field)
+			}
+			re, err := regexp.Compile(m.Pattern)
+			if err != nil {
+				return fmt.Errorf("operation %s: invalid mask pattern: %w", operation, err)
+			}
+			m.re = re
+			if m.Field != "" {
+				paths = append(paths, m.Field)
+			}
+		}
+		for _, path := range paths {
+			for _, segment := range strings.Split(path, ".") {
+				if segment == "" {
+					return fmt.Errorf("operation %s: invalid field path %q", operation, path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Check reports rules of operations that none of the definitions declare
+func (c Config) Check(defs ...*models.Definitions) error {
+	known := make(map[string]bool)
+	for _, def := range defs {
+		for _, pt := range def.PortTypes {
+			for _, op := range pt.Operations {
+				known[op.Name] = true
+			}
+		}
+	}
+	var unknown []string
+	for operation := range c {
+		if operation != AllOperations && !known[operation] {
+			unknown = append(unknown, operation)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("transforms of unknown operations: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Apply transforms the response of an operation in place and returns it
+func (c Config) Apply(operation string, response map[string]interface{}) map[string]interface{} {
+	for _, rules := range []*Rules{c[AllOperations], c[operation]} {
+		if rules != nil {
+			rules.drop(response)
+			rules.mask(response)
+			rules.rename(response)
+		}
+	}
+	return response
+}
+
+// Redact drops and masks the fields of the response of an operation, as
+// Apply does, but keeps their names, for responses whose shape is fixed
+// by a schema such as GraphQL's
+func (c Config) Redact(operation string, response map[string]interface{}) map[string]interface{} {
+	for _, rules := range []*Rules{c[AllOperations], c[operation]} {
+		if rules != nil {
+			rules.drop(response)
+			rules.mask(response)
+		}
+	}
+	return response
+}
+
+func (r *Rules) drop(response map[string]interface{}) {
+	for _, path := range r.Drop {
+		visit(response, strings.Split(path, "."), func(obj map[string]interface{}, key string) {
+			delete(obj, key)
+		})
+	}
+}
+
+func (r *Rules) mask(response map[string]interface{}) {
+	for _, m := range r.Mask {
+		if m.Field == "" {
+			for key, value := range response {
+				response[key] = m.apply(value)
+			}
+			continue
+		}
+		visit(response, strings.Split(m.Field, "."), func(obj map[string]interface{}, key string) {
+			obj[key] = m.apply(obj[key])
+		})
+	}
+}
+
+// rename renames the deepest fields first, so that the paths of fields
+// inside renamed objects still apply
+func (r *Rules) rename(response map[string]interface{}) {
+	paths := make([]string, 0, len(r.Rename))
+	for path := range r.Rename {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "."), strings.Count(paths[j], ".")
+		if di != dj {
+			return di > dj
+		}
+		return paths[i] < paths[j]
+	})
+	for _, path := range paths {
+		name := r.Rename[path]
+		visit(response, strings.Split(path, "."), func(obj map[string]interface{}, key string) {
+			if key == name {
+				return
+			}
+			obj[name] = obj[key]
+			delete(obj, key)
+		})
+	}
+}
+
+// apply masks the strings and numbers of a value. Masked numbers become
+// strings.
+func (m Mask) apply(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return m.replace(v)
+	case float64:
+		text := strconv.FormatFloat(v, 'f', -1, 64)
+		if masked := m.replace(text); masked != text {
+			return masked
+		}
+	case int64:
+		text := strconv.FormatInt(v, 10)
+		if masked := m.replace(text); masked != text {
+			return masked
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = m.apply(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = m.apply(item)
+		}
+	}
+	return value
+}
+
+// replace masks the matches of the pattern in text
+func (m Mask) replace(text string) string {
+	if m.Replace != "" {
+		return m.re.ReplaceAllString(text, m.Replace)
+	}
+	return m.re.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(match))
+	})
+}
+
+// visit calls fn with the object and the key of each field at path in
+// value, traversing arrays
+func visit(value interface{}, path []string, fn func(obj map[string]interface{}, key string)) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			visit(item, path, fn)
+		}
+	case map[string]interface{}:
+		var keys []string
+		if path[0] == "*" {
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+		} else if _, ok := v[path[0]]; ok {
+			keys = []string{path[0]}
+		}
+		for _, key := range keys {
+			if len(path) == 1 {
+				fn(v, key)
+			} else {
+				visit(v[key], path[1:], fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wsdl2api.yaml")
+	os.WriteFile(path, []byte(`
+port: 9090
+transforms:
+  GetCustomer:
+    drop: [ssn, accounts.pin]
+    mask:
+      - field: card.number
+        pattern: '^\d{12}'
+    rename:
+      custName: name
+  "*":
+    mask:
+      - pattern: '\b\d{3}-\d{2}-\d{4}\b'
+        replace: '***-**-****'
+`), 0644)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg) != 2 || cfg["GetCustomer"].Rename["custName"] != "name" || cfg[AllOperations].Mask[0].re == nil {
+		t.Errorf("Load() = %+v", cfg)
+	}
+
+	os.WriteFile(path, []byte("port: 9090\n"), 0644)
+	if cfg, err := Load(path); err != nil || cfg != nil {
+		t.Errorf("Load() without transforms = %v, %v", cfg, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		yaml string
+		want string
+	}{
+		{"Op: {mask: [{field: a, pattern: '('}]}", "invalid mask pattern"},
+		{"Op: {mask: [{field: a}]}", "has no pattern"},
+		{"Op: {drop: [a..b]}", `invalid field path "a..b"`},
+		{"Op: {rename: {a: b.c}}", "invalid new name"},
+		{"Op: {rename: {a.*: b}}", "names several fields"},
+	} {
+		if _, err := Parse([]byte(tt.yaml)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%s) error = %v, want %s", tt.yaml, err, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	cfg, err := Parse([]byte(`
+GetCustomer:
+  drop: [ssn, accounts.pin]
+  mask:
+    - field: card.number
+      pattern: '^\d{12}'
+    - field: accounts
+      pattern: '^\d+(\d{2})$'
+      replace: 'xx$1'
+  rename:
+    custName: name
+    card: paymentCard
+    card.number: pan
+    accounts.id: accountId
+"*":
+  drop: [internal]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"custName": "Ann",
+		"ssn": "123-45-6789",
+		"internal": {"node": 3},
+		"card": {"number": "4111111111111111", "expiry": "12/27"},
+		"accounts": [
+			{"id": "1", "pin": "0000", "number": "123456"},
+			{"id": "2", "pin": "1111", "number": 98765}
+		]
+	}`), &response)
+	// Numbers of the proxy are int64 when integral
+	response["accounts"].([]interface{})[1].(map[string]interface{})["number"] = int64(98765)
+
+	got := cfg.Apply("GetCustomer", response)
+	want := map[string]interface{}{
+		"name":        "Ann",
+		"paymentCard": map[string]interface{}{"pan": "************1111", "expiry": "12/27"},
+		"accounts": []interface{}{
+			map[string]interface{}{"accountId": "1", "number": "xx56"},
+			map[string]interface{}{"accountId": "2", "number": "xx65"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		t.Errorf("Apply() = %s", gotJSON)
+	}
+
+	other := map[string]interface{}{"internal": true, "custName": "Bob"}
+	if got := cfg.Apply("Other", other); !reflect.DeepEqual(got, map[string]interface{}{"custName": "Bob"}) {
+		t.Errorf("Apply() of another operation = %v", got)
+	}
+
+	redacted := cfg.Redact("GetCustomer", map[string]interface{}{"custName": "Ann", "ssn": "1", "card": map[string]interface{}{"number": "4111111111111111"}})
+	if !reflect.DeepEqual(redacted, map[string]interface{}{"custName": "Ann", "card": map[string]interface{}{"number": "************1111"}}) {
+		t.Errorf("Redact() = %v", redacted)
+	}
+
+	var none Config
+	if got := none.Apply("GetCustomer", map[string]interface{}{"ssn": "1"}); got["ssn"] != "1" {
+		t.Errorf("Apply() of nil config = %v", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	def := &models.Definitions{PortTypes: []models.PortType{{Operations: []models.Operation{{Name: "GetCustomer"}}}}}
+	cfg := Config{"GetCustomer": {}, AllOperations: {}}
+	if err := cfg.Check(def); err != nil {
+		t.Errorf("Check() = %v", err)
+	}
+	cfg["GetCustomers"] = &Rules{}
+	if err := cfg.Check(def); err == nil || !strings.Contains(err.Error(), "GetCustomers") {
+		t.Errorf("Check() = %v, want unknown GetCustomers", err)
+	}
+}
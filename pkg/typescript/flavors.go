@@ -0,0 +1,214 @@
+package typescript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// Client flavors, selecting the HTTP library and framework bindings of the
+// generated client
+const (
+	// FlavorFetch generates a dependency-free client using fetch
+	FlavorFetch = "fetch"
+	// FlavorAxios generates a client using axios
+	FlavorAxios = "axios"
+	// FlavorReactQuery generates the fetch client plus React Query hooks
+	FlavorReactQuery = "react-query"
+)
+
+// SetFlavor sets the client flavor, one of the Flavor constants. The default
+// is FlavorFetch.
+func (g *Generator) SetFlavor(flavor string) {
+	g.flavor = flavor
+}
+
+// fetchClientBody is the constructor and request method of the fetch
+// client, formatted with the default base URL
+const fetchClientBody = `
+  constructor(config: ClientConfig = {}) {
+    this.baseURL = config.baseURL || '%s';
+    this.headers = config.headers || {};
+    this.timeout = config.timeout || 30000;
+  }
+
+  private async request<T>(
+    path: string,
+    options: RequestInit = {}
+  ): Promise<T> {
+    const url = this.baseURL + path;
+    const controller = new AbortController();
+    const timeoutId = setTimeout(() => controller.abort(), this.timeout);
+
+    try {
+      const response = await fetch(url, {
+        ...options,
+        headers: {
+          'Content-Type': 'application/json',
+          ...this.headers,
+          ...options.headers,
+        },
+        signal: controller.signal,
+      });
+
+      clearTimeout(timeoutId);
+
+      if (!response.ok) {
+        const error: Types.APIError = {
+          message: response.statusText,
+          status: response.status,
+        };
+
+        try {
+          const fault = await response.json();
+          error.fault = fault;
+        } catch {
+          // No JSON body
+        }
+
+        throw error;
+      }
+
+      return await response.json();
+    } catch (err) {
+      clearTimeout(timeoutId);
+
+      if (err instanceof Error && err.name === 'AbortError') {
+        throw new Error('Request timeout');
+      }
+
+      throw err;
+    }
+  }
+
+`
+
+// axiosClientBody is the constructor and request method of the axios
+// client, formatted with the default base URL
+const axiosClientBody = `  private http: AxiosInstance;
+
+  constructor(config: ClientConfig = {}) {
+    this.baseURL = config.baseURL || '%s';
+    this.headers = config.headers || {};
+    this.timeout = config.timeout || 30000;
+    this.http = axios.create({
+      baseURL: this.baseURL,
+      headers: { 'Content-Type': 'application/json', ...this.headers },
+      timeout: this.timeout,
+    });
+  }
+
+  private async request<T>(path: string, body: unknown, method = 'post'): Promise<T> {
+    try {
+      const response = await this.http.request<T>({ method, url: path, data: body });
+      return response.data;
+    } catch (err) {
+      if (axios.isAxiosError(err)) {
+        if (err.code === 'ECONNABORTED') {
+          throw new Error('Request timeout');
+        }
+        if (err.response) {
+          const error: Types.APIError = {
+            message: err.response.statusText || err.message,
+            status: err.response.status,
+            fault: err.response.data,
+          };
+          throw error;
+        }
+      }
+
+      throw err;
+    }
+  }
+
+`
+
+// queryHelper builds the query string of GET and DELETE requests
+const queryHelper = `  private query(request: object, exclude: string[] = []): string {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(request)) {
+      if (value === undefined || value === null || exclude.includes(key)) {
+        continue;
+      }
+      for (const item of Array.isArray(value) ? value : [value]) {
+        params.append(key, String(item));
+      }
+    }
+    const query = params.toString();
+    return query ? '?' + query : '';
+  }
+
+`
+
+// dependencies returns the package.json dependencies of the flavor and
+// options
+func (g *Generator) dependencies() string {
+	var deps []string
+	switch g.flavor {
+	case FlavorAxios:
+		deps = append(deps, `"axios": "^1.6.0"`)
+	case FlavorReactQuery:
+		deps = append(deps, `"@tanstack/react-query": "^5.0.0"`)
+	}
+	if g.zod {
+		deps = append(deps, `"zod": "^3.22.0"`)
+	}
+	if len(deps) == 0 {
+		return ""
+	}
+
+	out := "\n  \"dependencies\": {\n    " + strings.Join(deps, ",\n    ") + "\n  },"
+	if g.flavor == FlavorReactQuery {
+		out += "\n  \"peerDependencies\": {\n    \"react\": \">=18\"\n  },"
+	}
+	return out
+}
+
+// generateHooks generates React Query hooks wrapping the client methods.
+// Every operation gets a query hook, keyed by the operation and request,
+// and a mutation hook.
+func (g *Generator) generateHooks() error {
+	var b strings.Builder
+
+	b.WriteString(`// Auto-generated React Query hooks from OpenAPI specification
+
+import { useMutation, useQuery } from '@tanstack/react-query';
+import type { UseMutationOptions, UseQueryOptions } from '@tanstack/react-query';
+import { APIClient, apiClient } from './client';
+import type * as Types from './types';
+
+`)
+
+	for _, op := range g.operations() {
+		hook := naming.Pascal(op.methodName)
+
+		b.WriteString(fmt.Sprintf("/** Query hook for %s */\n", op.Summary))
+		b.WriteString(fmt.Sprintf("export function use%sQuery(\n", hook))
+		b.WriteString(fmt.Sprintf("  request: Types.%s,\n", op.requestType))
+		b.WriteString(fmt.Sprintf("  options: Omit<UseQueryOptions<Types.%s, Types.APIError>, 'queryKey' | 'queryFn'> = {},\n", op.responseType))
+		b.WriteString("  client: APIClient = apiClient\n")
+		b.WriteString(") {\n")
+		b.WriteString("  return useQuery({\n")
+		b.WriteString(fmt.Sprintf("    queryKey: ['%s', request],\n", op.methodName))
+		b.WriteString(fmt.Sprintf("    queryFn: () => client.%s(request),\n", op.methodName))
+		b.WriteString("    ...options,\n")
+		b.WriteString("  });\n")
+		b.WriteString("}\n\n")
+
+		b.WriteString(fmt.Sprintf("/** Mutation hook for %s */\n", op.Summary))
+		b.WriteString(fmt.Sprintf("export function use%sMutation(\n", hook))
+		b.WriteString(fmt.Sprintf("  options: Omit<UseMutationOptions<Types.%s, Types.APIError, Types.%s>, 'mutationFn'> = {},\n", op.responseType, op.requestType))
+		b.WriteString("  client: APIClient = apiClient\n")
+		b.WriteString(") {\n")
+		b.WriteString("  return useMutation({\n")
+		b.WriteString(fmt.Sprintf("    mutationFn: (request: Types.%s) => client.%s(request),\n", op.requestType, op.methodName))
+		b.WriteString("    ...options,\n")
+		b.WriteString("  });\n")
+		b.WriteString("}\n\n")
+	}
+
+	return os.WriteFile(filepath.Join(g.outputDir, "hooks.ts"), []byte(b.String()), 0644)
+}
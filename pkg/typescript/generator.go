@@ -4,15 +4,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/naming"
 )
 
 // Generator generates TypeScript client code from OpenAPI spec
 type Generator struct {
 	outputDir string
 	spec      *exporter.OpenAPISpec
+	flavor    string
+
+	// zod emits zod schemas and validates responses with them
+	zod bool
+
+	// Names assigned by operations, the types waiting to be declared and
+	// those declared, with the names of nested objects
+	types    *naming.Namer
+	refs     map[string]string
+	ops      []tsOperation
+	pending  []tsDeclaration
+	declared []tsDeclaration
+	nested   map[*exporter.OpenAPISchema]string
 }
 
 // NewGenerator creates a new TypeScript generator
@@ -20,6 +36,7 @@ func NewGenerator(outputDir string, spec *exporter.OpenAPISpec) *Generator {
 	return &Generator{
 		outputDir: outputDir,
 		spec:      spec,
+		flavor:    FlavorFetch,
 	}
 }
 
@@ -35,11 +52,25 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("failed to generate types: %w", err)
 	}
 
+	// Generate zod schemas of the types
+	if g.zod {
+		if err := g.generateSchemas(); err != nil {
+			return fmt.Errorf("failed to generate zod schemas: %w", err)
+		}
+	}
+
 	// Generate API client
 	if err := g.generateClient(); err != nil {
 		return fmt.Errorf("failed to generate client: %w", err)
 	}
 
+	// Generate React Query hooks
+	if g.flavor == FlavorReactQuery {
+		if err := g.generateHooks(); err != nil {
+			return fmt.Errorf("failed to generate hooks: %w", err)
+		}
+	}
+
 	// Generate index file
 	if err := g.generateIndex(); err != nil {
 		return fmt.Errorf("failed to generate index: %w", err)
@@ -69,23 +100,28 @@ func (g *Generator) generateTypes() error {
 
 	b.WriteString("// Auto-generated TypeScript types from OpenAPI specification\n\n")
 
+	// Name the interfaces of components and operations before declaring any
+	// nested ones, so that their names don't depend on nested type names
+	ops := g.operations()
+
+	// Generate one interface per component schema
+	if g.spec.Components != nil {
+		for _, name := range sortedKeys(g.spec.Components.Schemas) {
+			g.declare(&b, g.refs[componentRef(name)], g.spec.Components.Schemas[name])
+		}
+	}
+
 	// Generate request/response types from paths
-	for _, pathItem := range g.spec.Paths {
-		if pathItem.Post != nil {
-			op := pathItem.Post
-
-			// Generate request type
-			if op.RequestBody != nil {
-				typeName := toPascalCase(op.OperationID) + "Request"
-				b.WriteString(g.generateTypeFromSchema(typeName, op.RequestBody.Content["application/json"].Schema))
-			}
+	for _, op := range ops {
+		// Generate request type
+		if request := op.RequestSchema(); request != nil {
+			g.declare(&b, op.requestType, request)
+		}
 
-			// Generate response type
-			if resp, ok := op.Responses["200"]; ok {
-				if content, ok := resp.Content["application/json"]; ok {
-					typeName := toPascalCase(op.OperationID) + "Response"
-					b.WriteString(g.generateTypeFromSchema(typeName, content.Schema))
-				}
+		// Generate response type
+		if resp, ok := op.Responses["200"]; ok {
+			if content, ok := resp.Content["application/json"]; ok {
+				g.declare(&b, op.responseType, content.Schema)
 			}
 		}
 	}
@@ -107,21 +143,42 @@ func (g *Generator) generateTypes() error {
 	return os.WriteFile(filepath.Join(g.outputDir, "types.ts"), []byte(b.String()), 0644)
 }
 
-// generateTypeFromSchema generates a TypeScript type from OpenAPI schema
+// tsDeclaration is a named type waiting to be declared
+type tsDeclaration struct {
+	name   string
+	schema *exporter.OpenAPISchema
+}
+
+// declare writes the declaration of a named type followed by the nested
+// object types it introduces, breadth first
+func (g *Generator) declare(b *strings.Builder, name string, schema *exporter.OpenAPISchema) {
+	if schema == nil {
+		return
+	}
+
+	g.pending = append(g.pending, tsDeclaration{name: name, schema: schema})
+	for len(g.pending) > 0 {
+		decl := g.pending[0]
+		g.pending = g.pending[1:]
+		g.declared = append(g.declared, decl)
+		b.WriteString(g.generateTypeFromSchema(decl.name, decl.schema))
+	}
+}
+
+// generateTypeFromSchema generates a TypeScript type from OpenAPI schema.
+// Objects become interfaces and other schemas type aliases.
 func (g *Generator) generateTypeFromSchema(name string, schema *exporter.OpenAPISchema) string {
 	var b strings.Builder
 
-	if schema == nil {
-		return ""
+	if schema.Type != "object" && schema.Type != "" || schema.Ref != "" || len(schema.OneOf) > 0 || len(schema.AllOf) > 0 {
+		b.WriteString(fmt.Sprintf("export type %s = %s;\n\n", name, g.openAPITypeToTS(name, schema)))
+		return b.String()
 	}
 
 	b.WriteString(fmt.Sprintf("export interface %s {\n", name))
 
-	if schema.Properties != nil {
-		for propName, propSchema := range schema.Properties {
-			tsType := g.openAPITypeToTS(propSchema)
-			b.WriteString(fmt.Sprintf("  %s: %s;\n", propName, tsType))
-		}
+	for _, propName := range sortedKeys(schema.Properties) {
+		b.WriteString(fmt.Sprintf("  %s;\n", g.tsProperty(name, propName, schema)))
 	}
 
 	b.WriteString("}\n\n")
@@ -129,16 +186,86 @@ func (g *Generator) generateTypeFromSchema(name string, schema *exporter.OpenAPI
 	return b.String()
 }
 
-// openAPITypeToTS converts OpenAPI type to TypeScript type
-func (g *Generator) openAPITypeToTS(schema *exporter.OpenAPISchema) string {
+// tsProperty declares a property of an object schema. Properties that are
+// not required are optional and nullable ones accept null. Nested objects
+// are named after the parent type and the property.
+func (g *Generator) tsProperty(parent, name string, object *exporter.OpenAPISchema) string {
+	prop := object.Properties[name]
+	tsType := g.openAPITypeToTS(parent+naming.Pascal(name), prop)
+
+	// Element names such as my-field are only valid quoted
+	key := name
+	if !naming.IsIdentifier(name) {
+		key = fmt.Sprintf("'%s'", name)
+	}
+	if prop != nil && prop.Nullable {
+		tsType += " | null"
+	}
+
+	if isRequired(object, name) {
+		return fmt.Sprintf("%s: %s", key, tsType)
+	}
+	return fmt.Sprintf("%s?: %s", key, tsType)
+}
+
+// sortedKeys returns the property names of a schema in a stable order
+func sortedKeys(properties map[string]*exporter.OpenAPISchema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// componentRef returns the $ref of a component schema
+func componentRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+// openAPITypeToTS converts OpenAPI type to TypeScript type. Objects with
+// properties are declared as interfaces named name, after the current one.
+func (g *Generator) openAPITypeToTS(name string, schema *exporter.OpenAPISchema) string {
 	if schema == nil {
 		return "any"
 	}
 
 	if schema.Ref != "" {
-		// Extract type name from $ref
-		parts := strings.Split(schema.Ref, "/")
-		return parts[len(parts)-1]
+		if ident, ok := g.refs[schema.Ref]; ok {
+			return ident
+		}
+		return "any"
+	}
+
+	if len(schema.OneOf) > 0 {
+		var alternatives []string
+		for i, alt := range schema.OneOf {
+			alternatives = append(alternatives, g.openAPITypeToTS(fmt.Sprintf("%s%d", name, i+1), alt))
+		}
+		return strings.Join(alternatives, " | ")
+	}
+
+	// Derived types are intersections of their base type and their own
+	// properties
+	if len(schema.AllOf) > 0 {
+		var parts []string
+		for i, part := range schema.AllOf {
+			parts = append(parts, g.openAPITypeToTS(fmt.Sprintf("%sPart%d", name, i+1), part))
+		}
+		return strings.Join(parts, " & ")
+	}
+
+	// Enumerations become union types of their literals
+	if len(schema.Enum) > 0 {
+		var literals []string
+		for _, value := range schema.Enum {
+			if s, ok := value.(string); ok {
+				literals = append(literals, fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "\\'")))
+			} else {
+				literals = append(literals, fmt.Sprintf("%v", value))
+			}
+		}
+		return strings.Join(literals, " | ")
 	}
 
 	switch schema.Type {
@@ -153,19 +280,23 @@ func (g *Generator) openAPITypeToTS(schema *exporter.OpenAPISchema) string {
 		return "boolean"
 	case "array":
 		if schema.Items != nil {
-			itemType := g.openAPITypeToTS(schema.Items)
+			itemType := g.openAPITypeToTS(name, schema.Items)
+			if schema.Items.Nullable || len(schema.Items.Enum) > 1 || len(schema.Items.OneOf) > 1 || len(schema.Items.AllOf) > 1 {
+				itemType = fmt.Sprintf("(%s)", itemType)
+				if schema.Items.Nullable {
+					itemType = strings.TrimSuffix(itemType, ")") + " | null)"
+				}
+			}
 			return fmt.Sprintf("%s[]", itemType)
 		}
 		return "any[]"
 	case "object":
-		if schema.Properties != nil {
-			// Inline object
-			var props []string
-			for name, prop := range schema.Properties {
-				propType := g.openAPITypeToTS(prop)
-				props = append(props, fmt.Sprintf("%s: %s", name, propType))
-			}
-			return fmt.Sprintf("{ %s }", strings.Join(props, "; "))
+		if len(schema.Properties) > 0 {
+			// Nested object, declared after the current type
+			ident := g.types.Next(name)
+			g.nested[schema] = ident
+			g.pending = append(g.pending, tsDeclaration{name: ident, schema: schema})
+			return ident
 		}
 		return "Record<string, any>"
 	default:
@@ -177,10 +308,16 @@ func (g *Generator) openAPITypeToTS(schema *exporter.OpenAPISchema) string {
 func (g *Generator) generateClient() error {
 	var b strings.Builder
 
-	b.WriteString(`// Auto-generated API client from OpenAPI specification
-
-import type * as Types from './types';
-
+	b.WriteString("// Auto-generated API client from OpenAPI specification\n\n")
+	if g.flavor == FlavorAxios {
+		b.WriteString("import axios from 'axios';\n")
+		b.WriteString("import type { AxiosInstance } from 'axios';\n")
+	}
+	b.WriteString("import type * as Types from './types';\n")
+	if g.zod {
+		b.WriteString("import * as Schemas from './schemas';\n")
+	}
+	b.WriteString(`
 export interface ClientConfig {
   baseURL?: string;
   headers?: Record<string, string>;
@@ -191,85 +328,60 @@ export class APIClient {
   private baseURL: string;
   private headers: Record<string, string>;
   private timeout: number;
-
-  constructor(config: ClientConfig = {}) {
-    this.baseURL = config.baseURL || '` + g.getDefaultBaseURL() + `';
-    this.headers = config.headers || {};
-    this.timeout = config.timeout || 30000;
-  }
-
-  private async request<T>(
-    path: string,
-    options: RequestInit = {}
-  ): Promise<T> {
-    const url = this.baseURL + path;
-    const controller = new AbortController();
-    const timeoutId = setTimeout(() => controller.abort(), this.timeout);
-
-    try {
-      const response = await fetch(url, {
-        ...options,
-        headers: {
-          'Content-Type': 'application/json',
-          ...this.headers,
-          ...options.headers,
-        },
-        signal: controller.signal,
-      });
-
-      clearTimeout(timeoutId);
-
-      if (!response.ok) {
-        const error: Types.APIError = {
-          message: response.statusText,
-          status: response.status,
-        };
-
-        try {
-          const fault = await response.json();
-          error.fault = fault;
-        } catch {
-          // No JSON body
-        }
-
-        throw error;
-      }
-
-      return await response.json();
-    } catch (err) {
-      clearTimeout(timeoutId);
-
-      if (err instanceof Error && err.name === 'AbortError') {
-        throw new Error('Request timeout');
-      }
-
-      throw err;
-    }
-  }
-
 `)
+	if g.flavor == FlavorAxios {
+		b.WriteString(fmt.Sprintf(axiosClientBody, g.getDefaultBaseURL()))
+	} else {
+		b.WriteString(fmt.Sprintf(fetchClientBody, g.getDefaultBaseURL()))
+	}
+
+	for _, op := range g.operations() {
+		if op.hasQuery() {
+			b.WriteString(queryHelper)
+			break
+		}
+	}
 
 	// Generate methods for each operation
-	for path, pathItem := range g.spec.Paths {
-		if pathItem.Post != nil {
-			op := pathItem.Post
-			methodName := toCamelCase(op.OperationID)
-			requestType := toPascalCase(op.OperationID) + "Request"
-			responseType := toPascalCase(op.OperationID) + "Response"
-
-			b.WriteString(fmt.Sprintf("  /**\n   * %s\n", op.Summary))
-			if op.Description != "" {
-				b.WriteString(fmt.Sprintf("   * %s\n", op.Description))
-			}
-			b.WriteString("   */\n")
-			b.WriteString(fmt.Sprintf("  async %s(request: Types.%s): Promise<Types.%s> {\n",
-				methodName, requestType, responseType))
-			b.WriteString(fmt.Sprintf("    return this.request<Types.%s>('%s', {\n", responseType, path))
-			b.WriteString("      method: 'POST',\n")
+	for _, op := range g.operations() {
+		path := op.pathExpr()
+		methodName := op.methodName
+		requestType := op.requestType
+		responseType := op.responseType
+
+		b.WriteString(fmt.Sprintf("  /**\n   * %s\n", op.Summary))
+		if op.Description != "" {
+			b.WriteString(fmt.Sprintf("   * %s\n", op.Description))
+		}
+		b.WriteString("   */\n")
+		b.WriteString(fmt.Sprintf("  async %s(request: Types.%s): Promise<Types.%s> {\n",
+			methodName, requestType, responseType))
+		result := "return"
+		if g.zod {
+			// Validate the response before handing it out as the declared type
+			result = "const response = await"
+		}
+		switch {
+		case g.flavor == FlavorAxios && op.hasQuery():
+			b.WriteString(fmt.Sprintf("    %s this.request<Types.%s>(%s, undefined, '%s');\n", result, responseType, path, strings.ToLower(op.method)))
+		case g.flavor == FlavorAxios && op.method == "POST":
+			b.WriteString(fmt.Sprintf("    %s this.request<Types.%s>(%s, request);\n", result, responseType, path))
+		case g.flavor == FlavorAxios:
+			b.WriteString(fmt.Sprintf("    %s this.request<Types.%s>(%s, request, '%s');\n", result, responseType, path, strings.ToLower(op.method)))
+		case op.hasQuery():
+			b.WriteString(fmt.Sprintf("    %s this.request<Types.%s>(%s, {\n", result, responseType, path))
+			b.WriteString(fmt.Sprintf("      method: '%s',\n", op.method))
+			b.WriteString("    });\n")
+		default:
+			b.WriteString(fmt.Sprintf("    %s this.request<Types.%s>(%s, {\n", result, responseType, path))
+			b.WriteString(fmt.Sprintf("      method: '%s',\n", op.method))
 			b.WriteString("      body: JSON.stringify(request),\n")
 			b.WriteString("    });\n")
-			b.WriteString("  }\n\n")
 		}
+		if g.zod {
+			b.WriteString(fmt.Sprintf("    return Schemas.%s.parse(response);\n", schemaName(responseType)))
+		}
+		b.WriteString("  }\n\n")
 	}
 
 	b.WriteString("}\n\n")
@@ -286,6 +398,12 @@ func (g *Generator) generateIndex() error {
 export * from './types';
 export * from './client';
 `
+	if g.zod {
+		content += "export * from './schemas';\n"
+	}
+	if g.flavor == FlavorReactQuery {
+		content += "export * from './hooks';\n"
+	}
 
 	return os.WriteFile(filepath.Join(g.outputDir, "index.ts"), []byte(content), 0644)
 }
@@ -304,12 +422,12 @@ func (g *Generator) generatePackageJSON() error {
   },
   "keywords": ["api", "client", "typescript", "soap", "wsdl"],
   "author": "wsdl2api",
-  "license": "MIT",
+  "license": "MIT",%s
   "devDependencies": {
     "typescript": "^5.0.0"
   }
 }
-`, strings.ToLower(strings.ReplaceAll(g.spec.Info.Title, " ", "-")), g.spec.Info.Title)
+`, strings.ToLower(strings.ReplaceAll(g.spec.Info.Title, " ", "-")), g.spec.Info.Title, g.dependencies())
 
 	return os.WriteFile(filepath.Join(g.outputDir, "package.json"), []byte(content), 0644)
 }
@@ -322,33 +440,119 @@ func (g *Generator) getDefaultBaseURL() string {
 	return "http://localhost:8080"
 }
 
-// Helper functions
-func toPascalCase(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return s
+// tsOperation is an operation of the spec with its TypeScript names
+type tsOperation struct {
+	*exporter.OpenAPIOperation
+	method       string
+	path         string
+	requestType  string
+	responseType string
+	methodName   string
+}
+
+// hasQuery reports whether the operation sends its input in the query
+// string rather than a JSON body
+func (op tsOperation) hasQuery() bool {
+	return op.method == "GET" || op.method == "DELETE"
+}
+
+// pathExpr returns the TypeScript expression of the request path: a string
+// literal, or a template literal substituting the path parameters, followed
+// by the query string of the other fields for GET and DELETE
+func (op tsOperation) pathExpr() string {
+	var pathParams []string
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			pathParams = append(pathParams, param.Name)
+		}
+	}
+
+	expr := "'" + op.path + "'"
+	if len(pathParams) > 0 {
+		path := op.path
+		for _, name := range pathParams {
+			path = strings.ReplaceAll(path, "{"+name+"}", fmt.Sprintf("${encodeURIComponent(String(%s))}", requestField(name)))
+		}
+		expr = "`" + path + "`"
+	}
+	if op.hasQuery() {
+		exclude := make([]string, len(pathParams))
+		for i, name := range pathParams {
+			exclude[i] = "'" + name + "'"
+		}
+		if len(exclude) > 0 {
+			expr += fmt.Sprintf(" + this.query(request, [%s])", strings.Join(exclude, ", "))
+		} else {
+			expr += " + this.query(request)"
+		}
+	}
+	return expr
+}
+
+// requestField returns the TypeScript expression reading a request field
+func requestField(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return fmt.Sprintf("request['%s']", name)
+		}
 	}
+	return "request." + name
+}
 
-	// Split by common separators
-	words := strings.FieldsFunc(s, func(r rune) bool {
-		return r == '_' || r == '-' || r == '.' || r == ' '
-	})
+// operations returns the operations of the spec in path order, naming the
+// types of the spec on first use. Type and method names derive from the
+// component names and operationIds and are unique; method names don't
+// clash with the members of APIClient.
+func (g *Generator) operations() []tsOperation {
+	if g.ops != nil {
+		return g.ops
+	}
 
-	for i, word := range words {
-		if len(word) > 0 {
-			words[i] = strings.ToUpper(string(word[0])) + strings.ToLower(word[1:])
+	g.types = naming.NewNamer("type", naming.Pascal)
+	g.types.Reserve("SOAPFault", "APIError", "ClientConfig", "APIClient")
+	g.refs = make(map[string]string)
+	g.nested = make(map[*exporter.OpenAPISchema]string)
+	if g.spec.Components != nil {
+		for _, name := range sortedKeys(g.spec.Components.Schemas) {
+			g.refs[componentRef(name)] = g.types.Name(name)
 		}
 	}
 
-	return strings.Join(words, "")
+	paths := make([]string, 0, len(g.spec.Paths))
+	for path := range g.spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	methods := naming.NewNamer("method", naming.Camel)
+	methods.Reserve("constructor", "request", "query", "baseURL", "headers", "timeout", "http")
+
+	g.ops = make([]tsOperation, 0, len(paths))
+	for _, path := range paths {
+		for _, m := range g.spec.Paths[path].Operations() {
+			op := m.Operation
+			g.ops = append(g.ops, tsOperation{
+				OpenAPIOperation: op,
+				method:           m.Method,
+				path:             path,
+				requestType:      g.types.Next(op.OperationID + "Request"),
+				responseType:     g.types.Next(op.OperationID + "Response"),
+				methodName:       methods.Name(op.OperationID),
+			})
+		}
+	}
+	return g.ops
 }
 
-func toCamelCase(s string) string {
-	pascal := toPascalCase(s)
-	if len(pascal) > 0 {
-		return strings.ToLower(string(pascal[0])) + pascal[1:]
+// MethodNames returns the method names of the client generated for spec,
+// by operationId
+func MethodNames(spec *exporter.OpenAPISpec) map[string]string {
+	g := NewGenerator("", spec)
+	names := make(map[string]string)
+	for _, op := range g.operations() {
+		names[op.OperationID] = op.methodName
 	}
-	return pascal
+	return names
 }
 
 // generateTSConfig generates tsconfig.json
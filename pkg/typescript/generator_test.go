@@ -0,0 +1,262 @@
+package typescript
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+)
+
+// ordersDefinitions is a service whose GetOrder operation returns nested,
+// repeated, optional and enumerated elements and declares a fault
+var ordersDefinitions = &models.Definitions{
+	Name:            "Orders",
+	TargetNamespace: "urn:orders",
+	Types: []models.Type{
+		{Name: "Address", Elements: []models.Element{{Name: "street", Type: "xs:string"}, {Name: "city", Type: "xs:string", MinOccurs: "0"}}},
+		{Name: "Item", Elements: []models.Element{{Name: "sku", Type: "xs:string"}, {Name: "quantity", Type: "xs:int"}}},
+		{Name: "GetOrderType", Elements: []models.Element{{Name: "id", Type: "xs:int"}}},
+		{Name: "OrderType", Elements: []models.Element{
+			{Name: "shipTo", Type: "tns:Address"},
+			{Name: "item", Type: "tns:Item", MaxOccurs: "unbounded"},
+			{Name: "status", Type: "tns:Status"},
+			{Name: "note", Type: "xs:string", MinOccurs: "0"},
+		}},
+		{Name: "OrderFault", Elements: []models.Element{{Name: "reason", Type: "xs:string"}}},
+	},
+	SimpleTypes: []models.SimpleType{{Name: "Status", Base: "xs:string", Enumeration: []string{"open", "closed"}}},
+	Elements: []models.Element{
+		{Name: "GetOrder", Type: "tns:GetOrderType"},
+		{Name: "GetOrderResponse", Type: "tns:OrderType"},
+		{Name: "OrderFault", Type: "tns:OrderFault"},
+	},
+	Messages: []models.Message{
+		{Name: "GetOrderIn", Parts: []models.Part{{Name: "parameters", Element: "tns:GetOrder"}}},
+		{Name: "GetOrderOut", Parts: []models.Part{{Name: "parameters", Element: "tns:GetOrderResponse"}}},
+		{Name: "OrderFaultMsg", Parts: []models.Part{{Name: "fault", Element: "tns:OrderFault"}}},
+	},
+	PortTypes: []models.PortType{{Operations: []models.Operation{{
+		Name:   "GetOrder",
+		Input:  models.Message{Name: "tns:GetOrderIn"},
+		Output: models.Message{Name: "tns:GetOrderOut"},
+		Faults: []models.Fault{{Name: "OrderFault", Message: "tns:OrderFaultMsg"}},
+	}}}},
+}
+
+// ordersSpec returns the OpenAPI spec of ordersDefinitions
+func ordersSpec(t *testing.T) *exporter.OpenAPISpec {
+	t.Helper()
+	spec, err := exporter.ConvertWSDLToOpenAPI(ordersDefinitions)
+	if err != nil {
+		t.Fatalf("ConvertWSDLToOpenAPI() error = %v", err)
+	}
+	return spec
+}
+
+// generate generates the client of spec, configured by configure, and
+// returns its files by name
+func generate(t *testing.T, spec *exporter.OpenAPISpec, configure func(g *Generator)) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	g := NewGenerator(dir, spec)
+	if configure != nil {
+		configure(g)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(data)
+	}
+	return files
+}
+
+// assertContains fails the test for each of want missing from the file
+// name of files, and for each of unwanted present in it
+func assertContains(t *testing.T, files map[string]string, name string, want []string, unwanted ...string) {
+	t.Helper()
+	code, ok := files[name]
+	if !ok {
+		t.Fatalf("%s was not generated", name)
+	}
+	for _, w := range want {
+		if !strings.Contains(code, w) {
+			t.Errorf("%s lacks %q:\n%s", name, w, code)
+		}
+	}
+	for _, u := range unwanted {
+		if strings.Contains(code, u) {
+			t.Errorf("%s contains %q:\n%s", name, u, code)
+		}
+	}
+}
+
+func TestGenerateTypes(t *testing.T) {
+	// The shipping address refers to a component instead of nesting it
+	spec := ordersSpec(t)
+	response := spec.Paths["/api/GetOrder"].Post.Responses["200"].Content["application/json"].Schema
+	spec.Components.Schemas["Address"] = response.Properties["shipTo"]
+	response.Properties["billTo"] = &exporter.OpenAPISchema{Ref: "#/components/schemas/Address"}
+
+	tests := []struct {
+		name     string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name: "components",
+			want: []string{
+				"export interface Address {\n  city?: string;\n  street: string;\n}",
+				"export interface OrderFault {\n  OrderFault?: OrderFaultOrderFault;\n}",
+				"billTo?: Address;",
+			},
+		},
+		{
+			name: "operations",
+			want: []string{
+				"export interface GetOrderRequest {\n  id: number;\n}",
+				"export interface GetOrderResponse {\n",
+			},
+		},
+		{
+			name: "nested objects",
+			want: []string{
+				"item: GetOrderResponseItem[];",
+				"export interface GetOrderResponseItem {\n  quantity: number;\n  sku: string;\n}",
+				"export interface OrderFaultOrderFault {\n  reason: string;\n}",
+			},
+			unwanted: []string{"{ ", "Record<string, any>"},
+		},
+		{
+			name: "optional and enumerated properties",
+			want: []string{
+				"note?: string;",
+				"status: 'open' | 'closed';",
+			},
+		},
+	}
+
+	files := generate(t, spec, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContains(t, files, "types.ts", tt.want, tt.unwanted...)
+		})
+	}
+}
+
+func TestFlavors(t *testing.T) {
+	tests := []struct {
+		flavor   string
+		client   []string
+		unwanted []string
+		deps     []string
+		hooks    bool
+	}{
+		{
+			flavor:   "",
+			client:   []string{"const response = await fetch(url, {", "method: 'POST',\n      body: JSON.stringify(request),"},
+			unwanted: []string{"axios"},
+		},
+		{
+			flavor: FlavorAxios,
+			client: []string{
+				"import axios from 'axios';",
+				"private http: AxiosInstance;",
+				"await this.http.request<T>({ method, url: path, data: body });",
+				"return this.request<Types.GetOrderResponse>('/api/GetOrder', request);",
+			},
+			unwanted: []string{"fetch("},
+			deps:     []string{`"axios": "^1.6.0"`},
+		},
+		{
+			flavor:   FlavorReactQuery,
+			client:   []string{"const response = await fetch(url, {"},
+			unwanted: []string{"axios"},
+			deps:     []string{`"@tanstack/react-query": "^5.0.0"`, "\"peerDependencies\": {\n    \"react\": \">=18\"\n  }"},
+			hooks:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flavor, func(t *testing.T) {
+			files := generate(t, ordersSpec(t), func(g *Generator) {
+				if tt.flavor != "" {
+					g.SetFlavor(tt.flavor)
+				}
+			})
+			assertContains(t, files, "client.ts", tt.client, tt.unwanted...)
+
+			if len(tt.deps) == 0 {
+				assertContains(t, files, "package.json", nil, "dependencies")
+			} else {
+				assertContains(t, files, "package.json", tt.deps)
+			}
+
+			_, ok := files["hooks.ts"]
+			if ok != tt.hooks {
+				t.Fatalf("hooks.ts generated = %v, want %v", ok, tt.hooks)
+			}
+			if !tt.hooks {
+				assertContains(t, files, "index.ts", nil, "./hooks")
+				return
+			}
+			assertContains(t, files, "index.ts", []string{"export * from './hooks';"})
+			assertContains(t, files, "hooks.ts", []string{
+				"import { useMutation, useQuery } from '@tanstack/react-query';",
+				"export function useGetOrderQuery(\n  request: Types.GetOrderRequest,",
+				"queryKey: ['getOrder', request],\n    queryFn: () => client.getOrder(request),",
+				"export function useGetOrderMutation(",
+				"mutationFn: (request: Types.GetOrderRequest) => client.getOrder(request),",
+			})
+		})
+	}
+}
+
+func TestZod(t *testing.T) {
+	// Without zod nothing is validated at runtime
+	files := generate(t, ordersSpec(t), nil)
+	if _, ok := files["schemas.ts"]; ok {
+		t.Error("schemas.ts generated without zod")
+	}
+	assertContains(t, files, "client.ts", nil, "Schemas", ".parse(")
+	assertContains(t, files, "index.ts", nil, "./schemas")
+
+	spec := ordersSpec(t)
+	response := spec.Paths["/api/GetOrder"].Post.Responses["200"].Content["application/json"].Schema
+	spec.Components.Schemas["Address"] = response.Properties["shipTo"]
+	response.Properties["billTo"] = &exporter.OpenAPISchema{Ref: "#/components/schemas/Address"}
+	files = generate(t, spec, func(g *Generator) {
+		g.SetFlavor(FlavorAxios)
+		g.SetZod(true)
+	})
+	assertContains(t, files, "schemas.ts", []string{
+		"import { z } from 'zod';",
+		"export const GetOrderResponseSchema: z.ZodType<Types.GetOrderResponse> = z.object({\n",
+		"item: z.array(z.lazy(() => GetOrderResponseItemSchema)),",
+		"note: z.string().optional(),",
+		"shipTo: z.lazy(() => GetOrderResponseShipToSchema),",
+		"status: z.union([z.literal('open'), z.literal('closed')]),",
+		"id: z.number().int(),",
+		"billTo: z.lazy(() => AddressSchema).optional(),",
+		"export const AddressSchema: z.ZodType<Types.Address> = z.object({",
+		"}).passthrough();",
+	})
+	assertContains(t, files, "client.ts", []string{
+		"import * as Schemas from './schemas';",
+		"const response = await this.request<Types.GetOrderResponse>('/api/GetOrder', request);\n    return Schemas.GetOrderResponseSchema.parse(response);",
+	})
+	assertContains(t, files, "index.ts", []string{"export * from './schemas';"})
+	assertContains(t, files, "package.json", []string{"\"axios\": \"^1.6.0\",\n    \"zod\": \"^3.22.0\""})
+}
@@ -0,0 +1,155 @@
+package typescript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/pkg/exporter"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+)
+
+// SetZod emits zod schemas of the types in schemas.ts and makes the client
+// validate responses with them
+func (g *Generator) SetZod(enabled bool) {
+	g.zod = enabled
+}
+
+// schemaName returns the name of the zod schema of a type
+func schemaName(typeName string) string {
+	return typeName + "Schema"
+}
+
+// generateSchemas generates a zod schema for every type declared in
+// types.ts. Named types are referenced lazily, so the schemas can be
+// declared in the same order as the types.
+func (g *Generator) generateSchemas() error {
+	var b strings.Builder
+
+	b.WriteString(`// Auto-generated zod schemas from OpenAPI specification
+
+import { z } from 'zod';
+import type * as Types from './types';
+
+`)
+
+	for _, decl := range g.declared {
+		b.WriteString(fmt.Sprintf("export const %s: z.ZodType<Types.%s> = %s;\n\n",
+			schemaName(decl.name), decl.name, g.zodDeclaration(decl.schema)))
+	}
+
+	return os.WriteFile(filepath.Join(g.outputDir, "schemas.ts"), []byte(b.String()), 0644)
+}
+
+// zodDeclaration returns the schema of a declared type. Objects keep
+// unknown keys, such as attributes, which the interfaces don't list.
+func (g *Generator) zodDeclaration(schema *exporter.OpenAPISchema) string {
+	if schema.Type != "object" && schema.Type != "" || schema.Ref != "" || len(schema.OneOf) > 0 || len(schema.AllOf) > 0 {
+		return g.zodType(schema)
+	}
+
+	var props []string
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+
+		key := name
+		if !naming.IsIdentifier(name) {
+			key = fmt.Sprintf("'%s'", name)
+		}
+		zodType := g.zodType(prop)
+		if prop != nil && prop.Nullable {
+			zodType += ".nullable()"
+		}
+		if !isRequired(schema, name) {
+			zodType += ".optional()"
+		}
+		props = append(props, fmt.Sprintf("  %s: %s,\n", key, zodType))
+	}
+	return fmt.Sprintf("z.object({\n%s}).passthrough()", strings.Join(props, ""))
+}
+
+// zodType returns the zod schema of a schema, following openAPITypeToTS
+func (g *Generator) zodType(schema *exporter.OpenAPISchema) string {
+	if schema == nil {
+		return "z.any()"
+	}
+
+	if schema.Ref != "" {
+		if ident, ok := g.refs[schema.Ref]; ok {
+			return fmt.Sprintf("z.lazy(() => %s)", schemaName(ident))
+		}
+		return "z.any()"
+	}
+
+	if len(schema.OneOf) > 0 {
+		var alternatives []string
+		for _, alt := range schema.OneOf {
+			alternatives = append(alternatives, g.zodType(alt))
+		}
+		if len(alternatives) == 1 {
+			return alternatives[0]
+		}
+		return fmt.Sprintf("z.union([%s])", strings.Join(alternatives, ", "))
+	}
+
+	if len(schema.AllOf) > 0 {
+		intersection := g.zodType(schema.AllOf[0])
+		for _, part := range schema.AllOf[1:] {
+			intersection += fmt.Sprintf(".and(%s)", g.zodType(part))
+		}
+		return intersection
+	}
+
+	if len(schema.Enum) > 0 {
+		var literals []string
+		for _, value := range schema.Enum {
+			if s, ok := value.(string); ok {
+				literals = append(literals, fmt.Sprintf("z.literal('%s')", strings.ReplaceAll(s, "'", "\\'")))
+			} else {
+				literals = append(literals, fmt.Sprintf("z.literal(%v)", value))
+			}
+		}
+		if len(literals) == 1 {
+			return literals[0]
+		}
+		return fmt.Sprintf("z.union([%s])", strings.Join(literals, ", "))
+	}
+
+	switch schema.Type {
+	case "string":
+		return "z.string()"
+	case "integer":
+		return "z.number().int()"
+	case "number":
+		return "z.number()"
+	case "boolean":
+		return "z.boolean()"
+	case "array":
+		if schema.Items == nil {
+			return "z.array(z.any())"
+		}
+		item := g.zodType(schema.Items)
+		if schema.Items.Nullable {
+			item += ".nullable()"
+		}
+		return fmt.Sprintf("z.array(%s)", item)
+	case "object":
+		if ident, ok := g.nested[schema]; ok {
+			return fmt.Sprintf("z.lazy(() => %s)", schemaName(ident))
+		}
+		return "z.record(z.any())"
+	default:
+		return "z.any()"
+	}
+}
+
+// isRequired reports whether a property of an object schema is required
+func isRequired(object *exporter.OpenAPISchema, name string) bool {
+	for _, required := range object.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
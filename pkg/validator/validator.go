@@ -0,0 +1,302 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// Issue severities
+const (
+	// SeverityError marks definitions that cannot be used as they are
+	SeverityError = "error"
+	// SeverityWarning marks definitions that work but are likely mistakes
+	// or lose information
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in WSDL definitions
+type Issue struct {
+	Severity string
+	Location string // Path to the definition, such as binding "X" operation "Y"
+	Message  string
+}
+
+// String formats the issue as "severity: location: message"
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Location, i.Message)
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// xsdBuiltins lists the XSD built-in types that type references may use
+var xsdBuiltins = map[string]bool{
+	"anyType": true, "anySimpleType": true, "anyURI": true,
+	"string": true, "normalizedString": true, "token": true, "language": true,
+	"Name": true, "NCName": true, "NMTOKEN": true, "NMTOKENS": true,
+	"ID": true, "IDREF": true, "IDREFS": true, "ENTITY": true, "ENTITIES": true, "QName": true, "NOTATION": true,
+	"boolean": true, "decimal": true, "float": true, "double": true,
+	"integer": true, "nonPositiveInteger": true, "negativeInteger": true,
+	"nonNegativeInteger": true, "positiveInteger": true,
+	"long": true, "int": true, "short": true, "byte": true,
+	"unsignedLong": true, "unsignedInt": true, "unsignedShort": true, "unsignedByte": true,
+	"dateTime": true, "date": true, "time": true, "duration": true,
+	"gYear": true, "gYearMonth": true, "gMonth": true, "gMonthDay": true, "gDay": true,
+	"base64Binary": true, "hexBinary": true,
+}
+
+// validator holds the lookups used by the checks
+type validator struct {
+	def         *models.Definitions
+	messages    map[string]*models.Message
+	portTypes   map[string]*models.PortType
+	bindings    map[string]bool
+	elements    map[string]bool
+	types       map[string]*models.Type
+	simpleTypes map[string]bool
+	issues      []Issue
+}
+
+// Validate checks WSDL definitions for unresolved references between
+// services, bindings, port types, messages and schema types, recursive
+// types, unsupported binding styles and missing soapAction values
+func Validate(def *models.Definitions) []Issue {
+	v := &validator{
+		def:         def,
+		messages:    make(map[string]*models.Message),
+		portTypes:   make(map[string]*models.PortType),
+		bindings:    make(map[string]bool),
+		elements:    make(map[string]bool),
+		types:       make(map[string]*models.Type),
+		simpleTypes: make(map[string]bool),
+	}
+	for i := range def.Messages {
+		v.messages[def.Messages[i].Name] = &def.Messages[i]
+	}
+	for i := range def.PortTypes {
+		v.portTypes[def.PortTypes[i].Name] = &def.PortTypes[i]
+	}
+	for _, b := range def.Bindings {
+		v.bindings[b.Name] = true
+	}
+	for _, e := range def.Elements {
+		v.elements[e.Name] = true
+	}
+	for i := range def.Types {
+		v.types[def.Types[i].Name] = &def.Types[i]
+	}
+	for _, st := range def.SimpleTypes {
+		v.simpleTypes[st.Name] = true
+	}
+
+	v.checkServices()
+	v.checkBindings()
+	v.checkPortTypes()
+	v.checkMessages()
+	v.checkTypes()
+
+	return v.issues
+}
+
+// report records an issue
+func (v *validator) report(severity, location, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{
+		Severity: severity,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkServices checks that ports reference existing bindings
+func (v *validator) checkServices() {
+	if len(v.def.Services) == 0 {
+		v.report(SeverityWarning, "definitions", "no service is defined, so there is no endpoint to call")
+	}
+	for _, svc := range v.def.Services {
+		for _, port := range svc.Ports {
+			location := fmt.Sprintf("service %q port %q", svc.Name, port.Name)
+			if !v.bindings[models.LocalName(port.Binding)] {
+				v.report(SeverityError, location, "binding %s is not defined", port.Binding)
+			}
+			if port.Address == "" {
+				v.report(SeverityWarning, location, "no address is set")
+			}
+		}
+	}
+}
+
+// checkBindings checks binding styles, soapAction values or HTTP locations
+// and that bindings match their port types
+func (v *validator) checkBindings() {
+	for _, b := range v.def.Bindings {
+		location := fmt.Sprintf("binding %q", b.Name)
+		if !supportedStyle(b.Style) {
+			v.report(SeverityError, location, "style %q is not supported (use document or rpc)", b.Style)
+		}
+
+		portType, ok := v.portTypes[models.LocalName(b.Type)]
+		if !ok {
+			v.report(SeverityError, location, "portType %s is not defined", b.Type)
+		}
+
+		bound := make(map[string]bool)
+		for _, op := range b.Operations {
+			bound[op.Name] = true
+			opLocation := fmt.Sprintf("%s operation %q", location, op.Name)
+
+			if !supportedStyle(op.Style) {
+				v.report(SeverityError, opLocation, "style %q is not supported (use document or rpc)", op.Style)
+			}
+			style := op.Style
+			if style == "" {
+				style = b.Style
+			}
+			for _, msg := range []models.BindingMessage{op.Input, op.Output} {
+				if msg.Use == "encoded" && style != "rpc" {
+					v.report(SeverityWarning, opLocation, "use encoded is only supported with rpc style")
+					break
+				}
+			}
+			switch {
+			case b.HTTPVerb != "" && op.Location == "":
+				v.report(SeverityWarning, opLocation, "http:operation location is missing, so calls go to the port address")
+			case b.HTTPVerb == "" && op.SoapAction == "":
+				v.report(SeverityWarning, opLocation, "soapAction is missing, so calls send an empty SOAPAction header")
+			}
+			if ok && findOperation(portType, op.Name) == nil {
+				v.report(SeverityError, opLocation, "operation is not defined in portType %s", portType.Name)
+			}
+		}
+
+		if ok {
+			for _, op := range portType.Operations {
+				if !bound[op.Name] {
+					v.report(SeverityWarning, location, "operation %s of portType %s is not bound", op.Name, portType.Name)
+				}
+			}
+		}
+	}
+}
+
+// checkPortTypes checks that operations reference existing messages
+func (v *validator) checkPortTypes() {
+	for _, pt := range v.def.PortTypes {
+		for _, op := range pt.Operations {
+			location := fmt.Sprintf("portType %q operation %q", pt.Name, op.Name)
+			if op.Input.Name == "" && op.Output.Name == "" {
+				v.report(SeverityError, location, "operation has neither input nor output")
+			}
+			for _, ref := range []struct{ kind, name string }{{"input", op.Input.Name}, {"output", op.Output.Name}} {
+				if ref.name != "" && v.messages[models.LocalName(ref.name)] == nil {
+					v.report(SeverityError, location, "%s message %s is not defined", ref.kind, ref.name)
+				}
+			}
+			for _, fault := range op.Faults {
+				if v.messages[models.LocalName(fault.Message)] == nil {
+					v.report(SeverityError, location, "fault %s message %s is not defined", fault.Name, fault.Message)
+				}
+			}
+		}
+	}
+}
+
+// checkMessages checks that parts reference existing elements and types
+func (v *validator) checkMessages() {
+	for _, msg := range v.def.Messages {
+		for _, part := range msg.Parts {
+			location := fmt.Sprintf("message %q part %q", msg.Name, part.Name)
+			switch {
+			case part.Element != "":
+				if !v.elements[models.LocalName(part.Element)] {
+					v.report(SeverityError, location, "element %s is not defined", part.Element)
+				}
+			case part.Type != "":
+				if !v.typeDefined(part.Type) {
+					v.report(SeverityError, location, "type %s is not defined", part.Type)
+				}
+			default:
+				v.report(SeverityError, location, "part has neither element nor type")
+			}
+		}
+	}
+}
+
+// checkTypes checks that element and attribute types exist and reports
+// recursive types
+func (v *validator) checkTypes() {
+	for _, e := range v.def.Elements {
+		if e.Type != "" && !v.typeDefined(e.Type) {
+			v.report(SeverityError, fmt.Sprintf("element %q", e.Name), "type %s is not defined", e.Type)
+		}
+	}
+
+	for _, t := range v.def.Types {
+		location := fmt.Sprintf("complexType %q", t.Name)
+		for _, e := range t.Elements {
+			if e.Type != "" && !v.typeDefined(e.Type) {
+				v.report(SeverityError, fmt.Sprintf("%s element %q", location, e.Name), "type %s is not defined", e.Type)
+			}
+		}
+		for _, a := range t.Attributes {
+			if a.Type != "" && !v.typeDefined(a.Type) {
+				v.report(SeverityError, fmt.Sprintf("%s attribute %q", location, a.Name), "type %s is not defined", a.Type)
+			}
+		}
+		if cycle := v.cycle(t.Name, t.Name, map[string]bool{}); cycle != nil {
+			v.report(SeverityWarning, location, "type is recursive (%s)", strings.Join(append([]string{t.Name}, cycle...), " -> "))
+		}
+	}
+}
+
+// cycle returns the path of types from name back to target, or nil when
+// target can't be reached from name
+func (v *validator) cycle(name, target string, seen map[string]bool) []string {
+	t := v.types[name]
+	if t == nil || seen[name] {
+		return nil
+	}
+	seen[name] = true
+
+	for _, e := range t.Elements {
+		next := models.LocalName(e.Type)
+		if next == target {
+			return []string{next}
+		}
+		if path := v.cycle(next, target, seen); path != nil {
+			return append([]string{next}, path...)
+		}
+	}
+	return nil
+}
+
+// typeDefined reports whether a type reference resolves to a built-in or
+// declared type
+func (v *validator) typeDefined(qname string) bool {
+	name := models.LocalName(qname)
+	return xsdBuiltins[name] || v.types[name] != nil || v.simpleTypes[name]
+}
+
+// supportedStyle reports whether a binding style is supported. An empty
+// style defaults to document.
+func supportedStyle(style string) bool {
+	return style == "" || style == "document" || style == "rpc"
+}
+
+// findOperation finds an operation of a port type by name
+func findOperation(pt *models.PortType, name string) *models.Operation {
+	for i := range pt.Operations {
+		if pt.Operations[i].Name == name {
+			return &pt.Operations[i]
+		}
+	}
+	return nil
+}
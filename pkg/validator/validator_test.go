@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+)
+
+// validDefinitions returns definitions without issues
+func validDefinitions() *models.Definitions {
+	return &models.Definitions{
+		Name: "Calc",
+		Services: []models.Service{{Name: "CalcService", Ports: []models.Port{
+			{Name: "CalcPort", Binding: "tns:CalcBinding", Address: "http://localhost/calc"},
+		}}},
+		Bindings: []models.Binding{{
+			Name:  "CalcBinding",
+			Type:  "tns:CalcPortType",
+			Style: "document",
+			Operations: []models.BindingOperation{
+				{Name: "Add", SoapAction: "urn:calc/Add"},
+			},
+		}},
+		PortTypes: []models.PortType{{Name: "CalcPortType", Operations: []models.Operation{{
+			Name:   "Add",
+			Input:  models.Message{Name: "tns:AddIn"},
+			Output: models.Message{Name: "tns:AddOut"},
+		}}}},
+		Messages: []models.Message{
+			{Name: "AddIn", Parts: []models.Part{{Name: "parameters", Element: "tns:Add"}}},
+			{Name: "AddOut", Parts: []models.Part{{Name: "result", Type: "xs:int"}}},
+		},
+		Elements: []models.Element{{Name: "Add", Type: "tns:Add"}},
+		Types: []models.Type{{Name: "Add", Elements: []models.Element{
+			{Name: "a", Type: "xs:int"},
+			{Name: "b", Type: "xs:int"},
+		}}},
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	if issues := Validate(validDefinitions()); len(issues) != 0 {
+		t.Errorf("Validate() = %v, want no issues", issues)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(def *models.Definitions)
+		severity string
+		want     string
+	}{
+		{
+			name:     "unresolved input message",
+			modify:   func(def *models.Definitions) { def.PortTypes[0].Operations[0].Input.Name = "tns:Missing" },
+			severity: SeverityError,
+			want:     `portType "CalcPortType" operation "Add": input message tns:Missing is not defined`,
+		},
+		{
+			name:     "binding to missing portType",
+			modify:   func(def *models.Definitions) { def.Bindings[0].Type = "tns:Other" },
+			severity: SeverityError,
+			want:     `binding "CalcBinding": portType tns:Other is not defined`,
+		},
+		{
+			name:     "port to missing binding",
+			modify:   func(def *models.Definitions) { def.Services[0].Ports[0].Binding = "tns:Other" },
+			severity: SeverityError,
+			want:     `service "CalcService" port "CalcPort": binding tns:Other is not defined`,
+		},
+		{
+			name:     "bound operation missing from portType",
+			modify:   func(def *models.Definitions) { def.Bindings[0].Operations[0].Name = "Sub" },
+			severity: SeverityError,
+			want:     `binding "CalcBinding" operation "Sub": operation is not defined in portType CalcPortType`,
+		},
+		{
+			name:     "unsupported style",
+			modify:   func(def *models.Definitions) { def.Bindings[0].Style = "message" },
+			severity: SeverityError,
+			want:     `binding "CalcBinding": style "message" is not supported`,
+		},
+		{
+			name:     "missing soapAction",
+			modify:   func(def *models.Definitions) { def.Bindings[0].Operations[0].SoapAction = "" },
+			severity: SeverityWarning,
+			want:     `binding "CalcBinding" operation "Add": soapAction is missing`,
+		},
+		{
+			name: "HTTP binding without location",
+			modify: func(def *models.Definitions) {
+				def.Bindings[0].HTTPVerb = "GET"
+				def.Bindings[0].Operations[0].SoapAction = ""
+			},
+			severity: SeverityWarning,
+			want:     `binding "CalcBinding" operation "Add": http:operation location is missing`,
+		},
+		{
+			name:     "undefined element",
+			modify:   func(def *models.Definitions) { def.Messages[0].Parts[0].Element = "tns:Sum" },
+			severity: SeverityError,
+			want:     `message "AddIn" part "parameters": element tns:Sum is not defined`,
+		},
+		{
+			name:     "undefined field type",
+			modify:   func(def *models.Definitions) { def.Types[0].Elements[1].Type = "tns:Number" },
+			severity: SeverityError,
+			want:     `complexType "Add" element "b": type tns:Number is not defined`,
+		},
+		{
+			name: "recursive type",
+			modify: func(def *models.Definitions) {
+				def.Types[0].Elements = append(def.Types[0].Elements, models.Element{Name: "next", Type: "tns:Node"})
+				def.Types = append(def.Types, models.Type{Name: "Node", Elements: []models.Element{{Name: "add", Type: "tns:Add"}}})
+			},
+			severity: SeverityWarning,
+			want:     `complexType "Add": type is recursive (Add -> Node -> Add)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := validDefinitions()
+			tt.modify(def)
+			issues := Validate(def)
+
+			found := false
+			for _, issue := range issues {
+				if strings.HasPrefix(issue.String(), tt.severity+": "+tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Validate() = %v, want %s: %s", issues, tt.severity, tt.want)
+			}
+			if HasErrors(issues) != (tt.severity == SeverityError) {
+				t.Errorf("HasErrors() = %v, want %v", HasErrors(issues), tt.severity == SeverityError)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Package wsdl2api is the programmatic API of wsdl2api, for embedding the
+// WSDL parser, the Go client generator and the REST proxy in build systems
+// and services instead of running the CLI. It wraps pkg/parser,
+// pkg/generator and pkg/server with the defaults of the CLI; those packages
+// remain available for settings the facade doesn't cover.
+package wsdl2api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/thdev01/wsdl2api/internal/models"
+	"github.com/thdev01/wsdl2api/pkg/generator"
+	"github.com/thdev01/wsdl2api/pkg/naming"
+	"github.com/thdev01/wsdl2api/pkg/parser"
+	"github.com/thdev01/wsdl2api/pkg/routes"
+	"github.com/thdev01/wsdl2api/pkg/script"
+	"github.com/thdev01/wsdl2api/pkg/security"
+	"github.com/thdev01/wsdl2api/pkg/server"
+	"github.com/thdev01/wsdl2api/pkg/transform"
+)
+
+// Definitions is a parsed WSDL 1.1 or 2.0 document
+type Definitions = models.Definitions
+
+// Parse parses a WSDL 1.1 or 2.0 document
+func Parse(r io.Reader) (*Definitions, error) {
+	return parser.NewParser().ParseReader(r)
+}
+
+// ParseFile parses a WSDL file, or fetches and parses the WSDL of an http
+// or https URL
+func ParseFile(path string) (*Definitions, error) {
+	return parser.NewParser().Parse(path)
+}
+
+// GenerateOptions configures Generate. The zero value generates the files
+// of the CLI's generate command with its defaults.
+type GenerateOptions struct {
+	OutputDir string // Default "./generated"
+	Package   string // Default "client"
+
+	// Files selects the files to generate, generator.DefaultOptions() when
+	// nil
+	Files *generator.Options
+
+	TimeType    string // A generator.TimeType constant, default string
+	DecimalType string // A generator.DecimalType constant, default float64
+	JSONNaming  string // A generator.JSONNaming constant, default xml
+	SOAPVersion string // "1.1" or "1.2", default from the WSDL binding
+
+	// TypeMapping replaces generated types with existing ones
+	TypeMapping *generator.TypeMapping
+
+	Split      bool   // One types and operators file per port type
+	Module     string // Module path of a go.mod, LICENSE and doc.go to scaffold
+	Standalone bool   // Copy the runtime packages instead of importing them
+	Server     bool   // Also generate the REST server skeleton, server.go
+	Verify     bool   // Type-check the generated package
+}
+
+// Generate generates a Go client of def. It returns the operations and
+// fields that had to be renamed to become valid, unique Go identifiers.
+func Generate(def *Definitions, opts GenerateOptions) ([]naming.Rename, error) {
+	g, err := opts.generator(def)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Generate(def); err != nil {
+		return nil, fmt.Errorf("failed to generate code: %w", err)
+	}
+	if opts.Verify {
+		if err := g.Verify(); err != nil {
+			return nil, err
+		}
+	}
+	return g.Renames(def), nil
+}
+
+// Check compares the output directory with the code Generate would write,
+// without writing it. It returns the files that are out of date, none when
+// the generated code is up to date with def.
+func Check(def *Definitions, opts GenerateOptions) ([]generator.Drift, error) {
+	g, err := opts.generator(def)
+	if err != nil {
+		return nil, err
+	}
+	return g.Check(def)
+}
+
+// generator returns a generator configured by the options
+func (opts GenerateOptions) generator(def *Definitions) (*generator.Generator, error) {
+	if opts.OutputDir == "" {
+		opts.OutputDir = "./generated"
+	}
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+	if opts.TimeType == "" {
+		opts.TimeType = generator.TimeTypeString
+	}
+	if opts.DecimalType == "" {
+		opts.DecimalType = generator.DecimalTypeFloat
+	}
+	if opts.JSONNaming == "" {
+		opts.JSONNaming = generator.JSONNamingXML
+	}
+	if err := opts.validate(def); err != nil {
+		return nil, err
+	}
+
+	g := generator.NewGenerator(opts.OutputDir, opts.Package)
+	g.SetTimeType(opts.TimeType)
+	g.SetDecimalType(opts.DecimalType)
+	g.SetJSONNaming(opts.JSONNaming)
+	g.SetTypeMapping(opts.TypeMapping)
+	g.SetSplit(opts.Split)
+	g.SetModule(opts.Module)
+	g.SetStandalone(opts.Standalone)
+	g.SetSOAPVersion(opts.SOAPVersion)
+	files := generator.DefaultOptions()
+	if opts.Files != nil {
+		files = *opts.Files
+	}
+	files.Server = files.Server || opts.Server
+	g.SetOptions(files)
+	return g, nil
+}
+
+// validate checks the options with fixed sets of values
+func (opts GenerateOptions) validate(def *Definitions) error {
+	switch opts.TimeType {
+	case generator.TimeTypeString, generator.TimeTypeTime:
+	default:
+		return fmt.Errorf("unsupported time type: %s", opts.TimeType)
+	}
+	switch opts.DecimalType {
+	case generator.DecimalTypeFloat, generator.DecimalTypeString, generator.DecimalTypeBigRat, generator.DecimalTypeShopspring:
+	default:
+		return fmt.Errorf("unsupported decimal type: %s", opts.DecimalType)
+	}
+	switch opts.JSONNaming {
+	case generator.JSONNamingXML, generator.JSONNamingCamel:
+	default:
+		return fmt.Errorf("unsupported JSON naming: %s", opts.JSONNaming)
+	}
+	if opts.SOAPVersion != "" && opts.SOAPVersion != "1.1" && opts.SOAPVersion != "1.2" {
+		return fmt.Errorf("unsupported SOAP version: %s", opts.SOAPVersion)
+	}
+	return opts.TypeMapping.Check(def)
+}
+
+// ProxyOptions configures NewProxy. The zero value proxies the endpoint of
+// the WSDL with the defaults of the CLI's serve command.
+type ProxyOptions struct {
+	SOAPEndpoint string // Default: the address of the WSDL's first port
+	SOAPVersion  string // "1.1" or "1.2", default from the WSDL binding
+
+	// Routes maps operations to REST methods and paths, POST
+	// /api/{Operation} when nil
+	Routes *routes.Config
+	// Transforms rename, drop and mask fields of the responses
+	Transforms transform.Config
+	// Scripts rewrite the requests and responses of operations, after
+	// Transforms for responses
+	Scripts *script.Hooks
+
+	// Credentials authenticate the backend SOAP calls
+	Credentials *server.Credentials
+	BackendTLS  security.TLSOptions
+	Compression string // Content coding of backend requests, gzip or deflate
+	SOAPDump    string // Directory to write the envelopes of backend calls to
+
+	Limits   *server.Limits   // Throttling, none when nil
+	Affinity *server.Affinity // Backend sessions per client, none when nil
+	Logger   *slog.Logger     // Default slog.Default()
+
+	DecimalsAsStrings bool // Return xs:decimal values as JSON strings
+	SkipValidation    bool // Forward requests without checking the schema
+	GraphQL           bool // Also serve the operations at /graphql
+}
+
+// NewProxy returns a handler serving the operations of def as a REST API
+// that calls the SOAP service, as the serve command does. Mount it in
+// another server, or serve it with http.ListenAndServe.
+func NewProxy(def *Definitions, opts ProxyOptions) (http.Handler, error) {
+	if opts.SOAPVersion != "" && opts.SOAPVersion != "1.1" && opts.SOAPVersion != "1.2" {
+		return nil, fmt.Errorf("unsupported SOAP version: %s", opts.SOAPVersion)
+	}
+
+	srv := server.NewServer(def, "", 0)
+	if opts.Logger != nil {
+		srv.SetLogger(opts.Logger)
+	}
+	if opts.SOAPEndpoint != "" {
+		srv.SetSOAPEndpoint(opts.SOAPEndpoint)
+	}
+	if opts.SOAPVersion != "" {
+		srv.SetSOAPVersion(opts.SOAPVersion)
+	}
+	if opts.Routes != nil {
+		if err := srv.SetRoutes(opts.Routes); err != nil {
+			return nil, err
+		}
+	}
+	if err := srv.SetTransforms(opts.Transforms); err != nil {
+		return nil, err
+	}
+	if opts.Scripts != nil {
+		if err := opts.Scripts.Check(def); err != nil {
+			return nil, err
+		}
+		srv.SetHooks(opts.Scripts.Request, opts.Scripts.Response)
+	}
+	if opts.Credentials != nil {
+		srv.SetCredentials(opts.Credentials)
+	}
+	if err := srv.SetBackendTLS(opts.BackendTLS); err != nil {
+		return nil, err
+	}
+	if err := srv.SetCompression(opts.Compression); err != nil {
+		return nil, err
+	}
+	if err := srv.SetSOAPDump(opts.SOAPDump); err != nil {
+		return nil, err
+	}
+	if opts.Limits != nil {
+		srv.SetLimits(*opts.Limits)
+	}
+	if opts.Affinity != nil {
+		if err := srv.SetAffinity(*opts.Affinity); err != nil {
+			return nil, err
+		}
+	}
+	srv.SetDecimalsAsStrings(opts.DecimalsAsStrings)
+	srv.SetRequestValidation(!opts.SkipValidation)
+	srv.SetGraphQL(opts.GraphQL)
+
+	return srv.Handler(), nil
+}
@@ -0,0 +1,131 @@
+package wsdl2api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thdev01/wsdl2api/pkg/generator"
+)
+
+// addResponse is the SOAP response of the calculator's Add operation
+const addResponse = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+  <AddResponse xmlns="http://tempuri.org/"><AddResult>3</AddResult></AddResponse>
+</soap:Body></soap:Envelope>`
+
+func parseCalculator(t *testing.T) *Definitions {
+	t.Helper()
+	f, err := os.Open("../../examples/calculator.wsdl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	def, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return def
+}
+
+func TestGenerate(t *testing.T) {
+	def := parseCalculator(t)
+
+	dir := t.TempDir()
+	files := generator.Options{Client: true, Types: true, Operators: true}
+	if _, err := Generate(def, GenerateOptions{OutputDir: dir, Package: "calc", Files: &files}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, name := range []string{"client.go", "types.go", "operators.go"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not generated: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.go")); err == nil {
+		t.Error("example.go generated though not selected")
+	}
+
+	if _, err := Generate(def, GenerateOptions{OutputDir: dir, DecimalType: "int"}); err == nil {
+		t.Error("Generate() accepted an unknown decimal type")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	def := parseCalculator(t)
+
+	dir := t.TempDir()
+	opts := GenerateOptions{OutputDir: dir, Package: "calc"}
+	if _, err := Generate(def, opts); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	drift, err := Check(def, opts)
+	if err != nil || len(drift) != 0 {
+		t.Fatalf("Check() = %v, %v after Generate()", drift, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte("package calc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "client.go")); err != nil {
+		t.Fatal(err)
+	}
+	opts.Package = "calculator"
+	drift, err = Check(def, opts)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, d := range drift {
+		reasons[d.File] = d.Reason
+	}
+	for file, reason := range map[string]string{
+		"client.go":    "missing",
+		"types.go":     "edited since generation",
+		"operators.go": "out of date: the options changed",
+	} {
+		if reasons[file] != reason {
+			t.Errorf("Check() reason for %s = %q, want %q", file, reasons[file], reason)
+		}
+	}
+
+	// Generating again replaces the edited file and removes the files no
+	// longer generated
+	files := generator.Options{Client: true, Types: true}
+	opts.Files = &files
+	if _, err := Generate(def, opts); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "operators.go")); err == nil {
+		t.Error("operators.go kept though no longer generated")
+	}
+	if drift, err := Check(def, opts); err != nil || len(drift) != 0 {
+		t.Errorf("Check() = %v, %v after Generate()", drift, err)
+	}
+}
+
+func TestNewProxy(t *testing.T) {
+	def := parseCalculator(t)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "<intA>1</intA>") {
+			t.Errorf("SOAP request = %s", body)
+		}
+		w.Write([]byte(addResponse))
+	}))
+	defer backend.Close()
+
+	handler, err := NewProxy(def, ProxyOptions{SOAPEndpoint: backend.URL})
+	if err != nil {
+		t.Fatalf("NewProxy() error = %v", err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/Add", strings.NewReader(`{"intA": 1, "intB": 2}`)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"AddResult":3`) {
+		t.Errorf("POST /api/Add = %d %s", w.Code, w.Body)
+	}
+}
@@ -1,7 +1,9 @@
 module github.com/thdev01/wsdl2api/tests/integration
 
-go 1.22.2
+go 1.23.0
 
 replace github.com/thdev01/wsdl2api => ../..
 
 require github.com/thdev01/wsdl2api v0.0.0-00010101000000-000000000000
+
+require golang.org/x/crypto v0.39.0 // indirect
@@ -0,0 +1,11 @@
+// Package wsdl2api embeds the sources of the runtime packages generated
+// clients import, which the generator copies into standalone output. The
+// programmatic API of the tools is pkg/wsdl2api.
+package wsdl2api
+
+import "embed"
+
+// Runtime holds the Go files of the runtime packages, test files included
+//
+//go:embed pkg/addressing/*.go pkg/charset/*.go pkg/compression/*.go pkg/recorder/*.go pkg/security/*.go
+var Runtime embed.FS